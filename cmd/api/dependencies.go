@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -13,14 +14,35 @@ import (
 	chatrepo "github.com/FACorreiaa/loci-connect-api/internal/domain/chat/repository"
 	chatservice "github.com/FACorreiaa/loci-connect-api/internal/domain/chat/service"
 	cityrepo "github.com/FACorreiaa/loci-connect-api/internal/domain/city"
+	cityhandler "github.com/FACorreiaa/loci-connect-api/internal/domain/city/handler"
 	discoverdomain "github.com/FACorreiaa/loci-connect-api/internal/domain/discover"
 	interestrepo "github.com/FACorreiaa/loci-connect-api/internal/domain/interests"
+	interesthandler "github.com/FACorreiaa/loci-connect-api/internal/domain/interests/handler"
+	itinerarylist "github.com/FACorreiaa/loci-connect-api/internal/domain/list"
+	listhandler "github.com/FACorreiaa/loci-connect-api/internal/domain/list/handler"
 	poirepo "github.com/FACorreiaa/loci-connect-api/internal/domain/poi"
+	poihandler "github.com/FACorreiaa/loci-connect-api/internal/domain/poi/handler"
 	profiles "github.com/FACorreiaa/loci-connect-api/internal/domain/profiles"
 	profilehandler "github.com/FACorreiaa/loci-connect-api/internal/domain/profiles/handler"
+	recentsdomain "github.com/FACorreiaa/loci-connect-api/internal/domain/recents"
+	recentshandler "github.com/FACorreiaa/loci-connect-api/internal/domain/recents/handler"
+	reviewdomain "github.com/FACorreiaa/loci-connect-api/internal/domain/review"
+	reviewhandler "github.com/FACorreiaa/loci-connect-api/internal/domain/review/handler"
+	statisticsdomain "github.com/FACorreiaa/loci-connect-api/internal/domain/statistics"
+	statisticshandler "github.com/FACorreiaa/loci-connect-api/internal/domain/statistics/handler"
 	tagrepo "github.com/FACorreiaa/loci-connect-api/internal/domain/tags"
+	usagedomain "github.com/FACorreiaa/loci-connect-api/internal/domain/usage"
+	usagehandler "github.com/FACorreiaa/loci-connect-api/internal/domain/usage/handler"
+	userdomain "github.com/FACorreiaa/loci-connect-api/internal/domain/user"
+	userhandler "github.com/FACorreiaa/loci-connect-api/internal/domain/user/handler"
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
 	"github.com/FACorreiaa/loci-connect-api/pkg/config"
 	"github.com/FACorreiaa/loci-connect-api/pkg/db"
+	"github.com/FACorreiaa/loci-connect-api/pkg/email"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
+	"github.com/FACorreiaa/loci-connect-api/pkg/ratelimit"
 )
 
 // Dependencies holds all application dependencies
@@ -40,6 +62,20 @@ type Dependencies struct {
 	CityRepo     cityrepo.Repository
 	ChatRepo     chatrepo.Repository
 	DiscoverRepo discoverdomain.Repository
+	ListRepo     itinerarylist.Repository
+	ReviewRepo   reviewdomain.Repository
+	StatsRepo    statisticsdomain.Repository
+	RecentsRepo  recentsdomain.Repository
+	UserRepo     userdomain.UserRepo
+	UsageRepo    usagedomain.Repository
+
+	// JWTKeys signs and verifies access and refresh tokens.
+	JWTKeys *jwtkeys.KeySet
+	// Revocations mirrors revoked access tokens for the auth interceptor.
+	Revocations *service.RevocationList
+	// EmailOutbox queues transactional email; EmailWorker delivers it until Cleanup.
+	EmailOutbox *email.Outbox
+	EmailWorker *email.Worker
 
 	// Services
 	TokenManager service.TokenManager
@@ -47,12 +83,43 @@ type Dependencies struct {
 	ChatService  chatservice.LlmInteractiontService
 	ProfileSvc   profiles.Service
 	DiscoverSvc  discoverdomain.Service
+	POISvc       poirepo.Service
+	ListSvc      itinerarylist.Service
+	ReviewSvc    reviewdomain.Service
+	StatsSvc     statisticsdomain.Service
+	RecentsSvc   recentsdomain.Service
+	CitySvc      cityrepo.Service
+	InterestSvc  interestrepo.Service
+	UserSvc      userdomain.UserService
+	UsageSvc     usagedomain.Service
+
+	// RateLimitStore holds the per-caller token buckets; nil disables rate limiting.
+	RateLimitStore ratelimit.Store
+	// TrustedProxies may name the client IP in forwarding headers.
+	TrustedProxies interceptors.TrustedProxies
+
+	// InteractionRecorder batches POI interaction writes; flushed on Cleanup.
+	InteractionRecorder *recentsdomain.InteractionRecorder
 
 	// Handlers
-	AuthHandler     *handler.AuthHandler
+	AuthHandler    *handler.AuthHandler
+	SessionHandler *handler.SessionHandler
+	AccountHandler *handler.AccountHandler
+	AuditHandler   *handler.AuditHandler
+	// OAuthHandler is nil when no OAuth provider is configured.
+	OAuthHandler    *handler.OAuthHandler
 	ChatHandler     *chathandler.ChatHandler
 	ProfileHandler  *profilehandler.ProfileHandler
 	DiscoverHandler *discoverdomain.Handler
+	POIHandler      *poihandler.POIHandler
+	ListHandler     *listhandler.ListHandler
+	ReviewHandler   *reviewhandler.ReviewHandler
+	StatsHandler    *statisticshandler.StatisticsHandler
+	RecentsHandler  *recentshandler.RecentsHandler
+	CityHandler     *cityhandler.CityHandler
+	InterestHandler *interesthandler.InterestHandler
+	UserHandler     *userhandler.UserHandler
+	UsageHandler    *usagehandler.UsageHandler
 }
 
 // InitDependencies initializes all application dependencies
@@ -130,6 +197,12 @@ func (d *Dependencies) initRepositories() error {
 	d.CityRepo = cityrepo.NewCityRepository(d.DB.Pool, d.Logger)
 	d.ChatRepo = chatrepo.NewRepositoryImpl(d.DB.Pool, d.Logger)
 	d.DiscoverRepo = discoverdomain.NewRepositoryImpl(d.DB.Pool, d.Logger)
+	d.ListRepo = itinerarylist.NewRepository(d.DB.Pool, d.Logger)
+	d.ReviewRepo = reviewdomain.NewRepository(d.DB.Pool, d.Logger)
+	d.StatsRepo = statisticsdomain.NewRepository(d.Logger, d.DB.Pool)
+	d.RecentsRepo = recentsdomain.NewRepository(d.DB.Pool, d.Logger)
+	d.UserRepo = userdomain.NewPostgresUserRepo(d.DB.Pool, d.Logger)
+	d.UsageRepo = usagedomain.NewRepository(d.DB.Pool, d.Logger)
 
 	d.Logger.Info("repositories initialized")
 	return nil
@@ -137,26 +210,90 @@ func (d *Dependencies) initRepositories() error {
 
 // initServices initializes all service layer dependencies
 func (d *Dependencies) initServices() error {
-	jwtSecret := []byte(d.Config.Auth.JWTSecret)
-	if len(jwtSecret) == 0 {
-		return fmt.Errorf("jwt secret is required")
+	keys, err := jwtkeys.Load(jwtkeys.Options{
+		SigningKeyFile:       d.Config.Auth.JWTSigningKeyFile,
+		VerificationKeyFiles: d.Config.Auth.JWTVerificationKeyFiles,
+		HMACSecret:           []byte(d.Config.Auth.JWTSecret),
+		AcceptHMAC:           d.Config.Auth.JWTAcceptHS256,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load jwt keys: %w", err)
 	}
+	d.JWTKeys = keys
 
 	accessTokenTTL := 15 * time.Minute
 	refreshTokenTTL := 30 * 24 * time.Hour
 
-	d.TokenManager = service.NewTokenManager(jwtSecret, jwtSecret, accessTokenTTL, refreshTokenTTL)
-	emailService := service.NewEmailService()
+	d.TokenManager = service.NewTokenManager(keys, keys, accessTokenTTL, refreshTokenTTL)
+	d.Revocations = service.NewRevocationList(d.AuthRepo, d.Logger, 0)
+	if err := d.Revocations.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to load token revocations: %w", err)
+	}
+
+	if err := d.initEmail(); err != nil {
+		return err
+	}
+
+	emailService := service.NewEmailService(d.EmailOutbox, d.Config.Email.FrontendURL)
 	d.AuthService = service.NewAuthService(
 		d.AuthRepo,
 		d.TokenManager,
 		emailService,
+		d.Revocations,
 		d.Logger,
 		refreshTokenTTL,
 	)
 
 	d.ProfileSvc = profiles.NewUserProfilesService(d.ProfileRepo, d.InterestRepo, d.TagRepo, d.Logger)
-	d.ChatService = chatservice.NewLlmInteractiontService(
+	premium := usagedomain.PlanQuota{
+		DailyTokens:   d.Config.Usage.Premium.DailyTokens,
+		MonthlyTokens: d.Config.Usage.Premium.MonthlyTokens,
+	}
+	usageSvc := usagedomain.NewService(d.UsageRepo, usagedomain.Quotas{
+		locitypes.PlanFree: {
+			DailyTokens:   d.Config.Usage.Free.DailyTokens,
+			MonthlyTokens: d.Config.Usage.Free.MonthlyTokens,
+		},
+		locitypes.PlanPremiumMonthly: premium,
+		locitypes.PlanPremiumAnnual:  premium,
+	}, d.Logger)
+	d.UsageSvc = usageSvc
+
+	switch d.Config.RateLimit.Store {
+	case "postgres":
+		d.RateLimitStore = ratelimit.NewPostgresStore(d.DB.Pool, d.Logger, time.Minute)
+	case "memory", "":
+		d.RateLimitStore = ratelimit.NewMemoryStore()
+	case "none":
+	default:
+		return fmt.Errorf("unknown rate limit store %q", d.Config.RateLimit.Store)
+	}
+	if d.TrustedProxies, err = interceptors.ParseTrustedProxies(d.Config.RateLimit.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_TRUSTED_PROXIES: %w", err)
+	}
+
+	ctx := context.Background()
+	chatClient, err := llm.NewChatClientFromConfig(ctx, d.Config.LLM, d.Logger, chatrepo.NewLLMAttemptRecorder(d.DB.Pool, d.Logger))
+	if err != nil {
+		return fmt.Errorf("failed to create %s chat client: %w", d.Config.LLM.Provider, err)
+	}
+	embeddingClient, err := llm.NewEmbeddingClientFromConfig(ctx, d.Config.LLM, d.Logger)
+	if err != nil {
+		// Semantic search degrades to trigram matching when embeddings are unavailable.
+		d.Logger.Warn("embedding client unavailable", "provider", d.Config.LLM.EmbeddingProvider, "error", err)
+		embeddingClient = nil
+	}
+	// Every call made on behalf of a user is written to their usage ledger.
+	meteredClient := llm.NewMeteredChatClient(chatClient, usageSvc)
+	d.Logger.Info("llm clients initialized",
+		"mode", d.Config.LLM.Mode,
+		"provider", llm.ProviderName(ctx, chatClient),
+		"fallbacks", d.Config.LLM.Fallbacks,
+		"model", chatClient.Model(),
+		"embedding_provider", d.Config.LLM.EmbeddingProvider)
+
+	d.ListSvc = itinerarylist.NewServiceImpl(d.ListRepo, d.POIRepo, d.Logger)
+	chatService := chatservice.NewLlmInteractiontService(
 		d.InterestRepo,
 		d.ProfileRepo,
 		d.ProfileSvc,
@@ -164,9 +301,39 @@ func (d *Dependencies) initServices() error {
 		d.ChatRepo,
 		d.CityRepo,
 		d.POIRepo,
+		meteredClient,
+		embeddingClient,
 		d.Logger,
 	)
+	chatService.WithMemory(chatservice.MemoryOptions{
+		RecentMessages: d.Config.LLM.Memory.RecentMessages,
+		TokenBudget:    d.Config.LLM.Memory.TokenBudget,
+		RecallLimit:    d.Config.LLM.Memory.RecallLimit,
+	})
+	if d.Config.LLM.Agent.Enabled {
+		chatService.WithAgent(d.ListSvc, d.Config.LLM.Agent.MaxSteps)
+		d.Logger.Info("chat agent enabled", "max_steps", d.Config.LLM.Agent.MaxSteps)
+	}
+	if d.Config.Gazetteer.Enabled {
+		// Without the gazetteer every message still gets its city from the LLM.
+		gazetteer, err := cityrepo.LoadGazetteer(ctx, d.CityRepo, d.Config.Gazetteer.GeoNamesFile, d.Config.Gazetteer.MinPopulation)
+		if err != nil {
+			d.Logger.Warn("city gazetteer not loaded, cities will be extracted by the LLM", "error", err)
+		} else {
+			chatService.WithGazetteer(gazetteer)
+			d.Logger.Info("city gazetteer loaded", "cities", gazetteer.Len(), "geonames_file", d.Config.Gazetteer.GeoNamesFile)
+		}
+	}
+	d.ChatService = chatService
 	d.DiscoverSvc = discoverdomain.NewServiceImpl(d.DiscoverRepo, d.Logger)
+	d.POISvc = poirepo.NewServiceImpl(d.POIRepo, meteredClient, embeddingClient, d.CityRepo, d.DiscoverRepo, d.Logger)
+	d.ReviewSvc = reviewdomain.NewServiceImpl(d.ReviewRepo, d.Logger)
+	d.StatsSvc = statisticsdomain.NewService(d.StatsRepo, d.Logger)
+	d.InteractionRecorder = recentsdomain.NewInteractionRecorder(d.RecentsRepo, d.Logger, 0, 0)
+	d.RecentsSvc = recentsdomain.NewService(d.RecentsRepo, d.InteractionRecorder, d.Logger)
+	d.CitySvc = cityrepo.NewCityService(d.CityRepo, embeddingClient, d.Logger)
+	d.InterestSvc = interestrepo.NewinterestsService(d.InterestRepo, d.Logger)
+	d.UserSvc = userdomain.NewUserService(d.UserRepo, d.Logger)
 
 	d.Logger.Info("services initialized")
 	return nil
@@ -174,16 +341,108 @@ func (d *Dependencies) initServices() error {
 
 // initHandlers initializes all handler dependencies
 func (d *Dependencies) initHandlers() error {
-	d.AuthHandler = handler.NewAuthHandler(d.AuthService)
+	// Login throttling keys on the client IP, which behind a proxy is only known from
+	// the headers the rate limiter already trusts.
+	d.AuthHandler = handler.NewAuthHandler(d.AuthService).WithTrustedProxies(d.TrustedProxies)
+	d.SessionHandler = handler.NewSessionHandler(d.AuthService, d.Logger)
+	d.AccountHandler = handler.NewAccountHandler(d.AuthService, d.Logger)
+	d.AuditHandler = handler.NewAuditHandler(d.AuthService, d.Logger)
+
+	oauthConfig := service.LoadOAuthConfigFromEnv()
+	providers, oauthStore, err := service.InitOAuth(oauthConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize oauth: %w", err)
+	}
+	if len(providers) > 0 {
+		d.OAuthHandler = handler.NewOAuthHandler(d.AuthService, providers, oauthStore, oauthConfig.AllowedRedirectURIs, d.Logger).
+			WithTrustedProxies(d.TrustedProxies)
+	}
 	d.ChatHandler = chathandler.NewChatHandler(d.ChatService, d.Logger)
 	d.ProfileHandler = profilehandler.NewProfileHandler(d.ProfileSvc)
 	d.DiscoverHandler = discoverdomain.NewHandler(d.DiscoverSvc, d.Logger)
+	d.POIHandler = poihandler.NewPOIHandler(d.POISvc, d.Logger)
+	d.ListHandler = listhandler.NewListHandler(d.ListSvc, d.Logger)
+	d.ReviewHandler = reviewhandler.NewReviewHandler(d.ReviewSvc, d.Logger)
+	d.StatsHandler = statisticshandler.NewStatisticsHandler(d.StatsSvc, d.Logger)
+	d.RecentsHandler = recentshandler.NewRecentsHandler(d.RecentsSvc, d.Logger)
+	d.CityHandler = cityhandler.NewCityHandler(d.CitySvc, d.Logger)
+	d.InterestHandler = interesthandler.NewInterestHandler(d.InterestSvc, d.Logger)
+	d.UserHandler = userhandler.NewUserHandler(d.UserSvc, d.Logger)
+	d.UsageHandler = usagehandler.NewUsageHandler(d.UsageSvc, d.Logger)
 	d.Logger.Info("handlers initialized")
 	return nil
 }
 
+// initEmail sets up the email outbox and starts the worker that delivers it.
+func (d *Dependencies) initEmail() error {
+	cfg := d.Config.Email
+
+	renderer, err := email.NewRenderer()
+	if err != nil {
+		return fmt.Errorf("failed to load email templates: %w", err)
+	}
+
+	var store email.Store
+	switch cfg.Store {
+	case "postgres", "":
+		store = email.NewPostgresStore(d.DB.Pool)
+	case "memory":
+		store = email.NewMemoryStore()
+	default:
+		return fmt.Errorf("unknown email store %q", cfg.Store)
+	}
+
+	from := email.Address{Email: cfg.FromEmail, Name: cfg.FromName}
+	var transport email.Transport
+	switch cfg.Transport {
+	case "smtp":
+		transport = email.NewSMTPTransport(email.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     from,
+		})
+	case "file", "log", "":
+		dir := ""
+		if cfg.Transport == "file" {
+			dir = cfg.FileDir
+		}
+		transport, err = email.NewFileTransport(dir, from, d.Logger)
+		if err != nil {
+			return err
+		}
+	case "memory":
+		transport = email.NewMemoryTransport()
+	default:
+		return fmt.Errorf("unknown email transport %q", cfg.Transport)
+	}
+
+	d.EmailOutbox = email.NewOutbox(store, renderer)
+	d.EmailWorker = email.NewWorker(d.EmailOutbox, transport, d.Logger, email.WorkerOptions{
+		Interval:    time.Duration(cfg.PollIntervalSeconds) * time.Second,
+		MaxAttempts: cfg.MaxAttempts,
+	})
+	d.EmailWorker.Start()
+	d.Logger.Info("email outbox initialized",
+		slog.String("store", cfg.Store), slog.String("transport", cfg.Transport))
+	return nil
+}
+
 // Cleanup closes all resources
 func (d *Dependencies) Cleanup() {
+	if d.Revocations != nil {
+		d.Revocations.Close()
+	}
+	if d.EmailWorker != nil {
+		d.EmailWorker.Close()
+	}
+	if d.InteractionRecorder != nil {
+		d.InteractionRecorder.Close()
+	}
+	if store, ok := d.RateLimitStore.(*ratelimit.PostgresStore); ok {
+		store.Close()
+	}
 	if d.DB != nil {
 		d.DB.Close()
 	}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+
+	listv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1/listv1connect"
+	recentsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1/recentsv1connect"
+	reviewv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1/reviewv1connect"
+	statisticsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1/statisticsv1connect"
+	authconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth/authconnect"
+	chatconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/chat/chatconnect"
+	cityconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city/cityconnect"
+	discoverconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/discover/discoverconnect"
+	interestconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest/interestconnect"
+	poiconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi/poiconnect"
+	profileconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/profile/profileconnect"
+	userconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user/userconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// procedurePolicies decides who may call every Connect procedure. SetupRouter refuses
+// to start when a registered procedure is missing here. Ownership of the resources a
+// call touches is checked by the handlers on top of this.
+var procedurePolicies = interceptors.Policies{
+	authconnect.AuthServiceRegisterProcedure:        interceptors.Public(),
+	authconnect.AuthServiceLoginProcedure:           interceptors.Public(),
+	authconnect.AuthServiceRefreshTokenProcedure:    interceptors.Public(),
+	authconnect.AuthServiceValidateSessionProcedure: interceptors.Public(),
+	authconnect.AuthServiceChangePasswordProcedure:  interceptors.Authenticated(),
+	authconnect.AuthServiceChangeEmailProcedure:     interceptors.Authenticated(),
+	authconnect.AuthServiceLogoutProcedure:          interceptors.Authenticated(),
+
+	chatconnect.ChatServiceStartChatProcedure:             interceptors.Authenticated(),
+	chatconnect.ChatServiceContinueChatProcedure:          interceptors.Authenticated(),
+	chatconnect.ChatServiceStreamChatProcedure:            interceptors.Authenticated(),
+	chatconnect.ChatServiceGetChatSessionProcedure:        interceptors.Authenticated(),
+	chatconnect.ChatServiceGetChatSessionsProcedure:       interceptors.Authenticated(),
+	chatconnect.ChatServiceGetRecentInteractionsProcedure: interceptors.Authenticated(),
+	chatconnect.ChatServiceEndSessionProcedure:            interceptors.Authenticated(),
+
+	discoverconnect.DiscoverServiceGetDiscoverPageProcedure:      interceptors.Authenticated(),
+	discoverconnect.DiscoverServiceGetTrendingProcedure:          interceptors.Authenticated(),
+	discoverconnect.DiscoverServiceGetFeaturedProcedure:          interceptors.Authenticated(),
+	discoverconnect.DiscoverServiceGetRecentDiscoveriesProcedure: interceptors.Authenticated(),
+	discoverconnect.DiscoverServiceGetCategoryResultsProcedure:   interceptors.Authenticated(),
+
+	poiconnect.POIServiceSearchPOIProcedure: interceptors.Authenticated(),
+	poiconnect.POIServiceGetPOIProcedure:    interceptors.Authenticated(),
+
+	listv1connect.ListServiceCreateListProcedure:         interceptors.Authenticated(),
+	listv1connect.ListServiceGetListsProcedure:           interceptors.Authenticated(),
+	listv1connect.ListServiceGetListProcedure:            interceptors.Authenticated(),
+	listv1connect.ListServiceUpdateListProcedure:         interceptors.Authenticated(),
+	listv1connect.ListServiceDeleteListProcedure:         interceptors.Authenticated(),
+	listv1connect.ListServiceCreateItineraryProcedure:    interceptors.Authenticated(),
+	listv1connect.ListServiceAddListItemProcedure:        interceptors.Authenticated(),
+	listv1connect.ListServiceUpdateListItemProcedure:     interceptors.Authenticated(),
+	listv1connect.ListServiceRemoveListItemProcedure:     interceptors.Authenticated(),
+	listv1connect.ListServiceGetListItemsProcedure:       interceptors.Authenticated(),
+	listv1connect.ListServiceGetListRestaurantsProcedure: interceptors.Authenticated(),
+	listv1connect.ListServiceGetListHotelsProcedure:      interceptors.Authenticated(),
+	listv1connect.ListServiceGetListItinerariesProcedure: interceptors.Authenticated(),
+	listv1connect.ListServiceSavePublicListProcedure:     interceptors.Authenticated(),
+	listv1connect.ListServiceUnsaveListProcedure:         interceptors.Authenticated(),
+	listv1connect.ListServiceGetSavedListsProcedure:      interceptors.Authenticated(),
+	listv1connect.ListServiceSearchPublicListsProcedure:  interceptors.Authenticated(),
+
+	// Reviews can be browsed anonymously; the caller is still resolved when a token is sent.
+	reviewv1connect.ReviewServiceGetPOIReviewsProcedure:       interceptors.Public(),
+	reviewv1connect.ReviewServiceGetReviewProcedure:           interceptors.Public(),
+	reviewv1connect.ReviewServiceGetUserReviewsProcedure:      interceptors.Public(),
+	reviewv1connect.ReviewServiceGetReviewStatisticsProcedure: interceptors.Public(),
+	reviewv1connect.ReviewServiceCreateReviewProcedure:        interceptors.Authenticated(),
+	reviewv1connect.ReviewServiceUpdateReviewProcedure:        interceptors.Authenticated(),
+	reviewv1connect.ReviewServiceDeleteReviewProcedure:        interceptors.Authenticated(),
+	reviewv1connect.ReviewServiceLikeReviewProcedure:          interceptors.Authenticated(),
+	reviewv1connect.ReviewServiceReportReviewProcedure:        interceptors.Authenticated(),
+
+	recentsv1connect.RecentsServiceGetRecentInteractionsProcedure: interceptors.Authenticated(),
+	recentsv1connect.RecentsServiceGetCityInteractionsProcedure:   interceptors.Authenticated(),
+	recentsv1connect.RecentsServiceRecordInteractionProcedure:     interceptors.Authenticated(),
+	recentsv1connect.RecentsServiceGetInteractionHistoryProcedure: interceptors.Authenticated(),
+	recentsv1connect.RecentsServiceGetFrequentPlacesProcedure:     interceptors.Authenticated(),
+
+	// The landing page shows platform statistics before sign-in.
+	statisticsv1connect.StatisticsServiceGetMainPageStatisticsProcedure:    interceptors.Public(),
+	statisticsv1connect.StatisticsServiceStreamMainPageStatisticsProcedure: interceptors.Public(),
+	statisticsv1connect.StatisticsServiceGetLandingPageStatisticsProcedure: interceptors.Authenticated(),
+	statisticsv1connect.StatisticsServiceGetDetailedPOIStatisticsProcedure: interceptors.Authenticated(),
+	statisticsv1connect.StatisticsServiceGetUserActivityAnalyticsProcedure: interceptors.Authenticated(),
+	statisticsv1connect.StatisticsServiceGetSystemAnalyticsProcedure:       interceptors.RequireRole(interceptors.RoleAdmin),
+
+	profileconnect.ProfileServiceGetUserPreferenceProfilesProcedure:   interceptors.Authenticated(),
+	profileconnect.ProfileServiceCreateUserPreferenceProfileProcedure: interceptors.Authenticated(),
+	profileconnect.ProfileServiceUpdateUserPreferenceProfileProcedure: interceptors.Authenticated(),
+
+	// Onboarding lets visitors pick a city and browse interests before signing up.
+	cityconnect.CityServiceGetCityProcedure:              interceptors.Public(),
+	cityconnect.CityServiceSearchCitiesProcedure:         interceptors.Public(),
+	interestconnect.InterestServiceGetInterestsProcedure: interceptors.Public(),
+
+	interestconnect.InterestServiceGetUserInterestsProcedure:      interceptors.Authenticated(),
+	interestconnect.InterestServiceAddInterestToUserProcedure:     interceptors.Authenticated(),
+	interestconnect.InterestServiceUpdatePreferenceLevelProcedure: interceptors.Authenticated(),
+
+	// The interest catalogue is shared by every user; only moderators edit it.
+	interestconnect.InterestServiceCreateInterestProcedure: interceptors.RequireRole(interceptors.RoleModerator),
+	interestconnect.InterestServiceUpdateInterestProcedure: interceptors.RequireRole(interceptors.RoleModerator),
+
+	userconnect.UserServiceGetUserProfileProcedure:    interceptors.Authenticated(),
+	userconnect.UserServiceUpdateUserProfileProcedure: interceptors.Authenticated(),
+}
+
+// verifyPolicyCoverage checks that every procedure of the services mounted at paths
+// has a policy.
+func verifyPolicyCoverage(policies interceptors.Policies, paths []string) error {
+	var procedures []string
+	var errs []error
+	for _, path := range paths {
+		p, err := interceptors.ServiceProcedures(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		procedures = append(procedures, p...)
+	}
+	errs = append(errs, policies.Verify(procedures))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	listv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1/listv1connect"
+	recentsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1/recentsv1connect"
+	reviewv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1/reviewv1connect"
+	statisticsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1/statisticsv1connect"
+	authconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth/authconnect"
+	chatconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/chat/chatconnect"
+	cityconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city/cityconnect"
+	discoverconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/discover/discoverconnect"
+	interestconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest/interestconnect"
+	poiconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi/poiconnect"
+	profileconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/profile/profileconnect"
+	userconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user/userconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+func TestProcedurePolicies_CoverEveryService(t *testing.T) {
+	var paths []string
+	for _, service := range []string{
+		authconnect.AuthServiceName,
+		chatconnect.ChatServiceName,
+		discoverconnect.DiscoverServiceName,
+		poiconnect.POIServiceName,
+		listv1connect.ListServiceName,
+		reviewv1connect.ReviewServiceName,
+		recentsv1connect.RecentsServiceName,
+		statisticsv1connect.StatisticsServiceName,
+		profileconnect.ProfileServiceName,
+		cityconnect.CityServiceName,
+		interestconnect.InterestServiceName,
+		userconnect.UserServiceName,
+	} {
+		paths = append(paths, "/"+service+"/")
+	}
+	if err := verifyPolicyCoverage(procedurePolicies, paths); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every policy must name a real procedure, so renamed RPCs do not leave stale entries.
+	known := map[string]bool{}
+	for _, path := range paths {
+		procedures, _ := interceptors.ServiceProcedures(path)
+		for _, procedure := range procedures {
+			known[procedure] = true
+		}
+	}
+	for procedure := range procedurePolicies {
+		if !known[procedure] {
+			t.Errorf("policy for unknown procedure %s", procedure)
+		}
+	}
+
+	if got := procedurePolicies[statisticsv1connect.StatisticsServiceGetSystemAnalyticsProcedure]; got.String() != "role:admin" {
+		t.Errorf("system analytics must be admin only, got %s", got)
+	}
+	for _, procedure := range procedurePolicies.PublicProcedures() {
+		if strings.HasPrefix(procedure, "/"+userconnect.UserServiceName+"/") {
+			t.Errorf("user procedure %s must not be public", procedure)
+		}
+	}
+}
+
+func TestProcedurePolicies_InterestCatalogueNeedsModerator(t *testing.T) {
+	authz := interceptors.NewAuthzInterceptor(procedurePolicies, slog.New(slog.DiscardHandler))
+	authorize := func(procedure, role string) connect.Code {
+		ctx := interceptors.ContextWithClaims(context.Background(), &interceptors.Claims{UserID: "user-1", Role: role})
+		if err := authz.Authorize(ctx, procedurePolicies[procedure]); err != nil {
+			return err.Code()
+		}
+		return 0
+	}
+
+	for _, procedure := range []string{
+		interestconnect.InterestServiceCreateInterestProcedure,
+		interestconnect.InterestServiceUpdateInterestProcedure,
+	} {
+		if got := authorize(procedure, "user"); got != connect.CodePermissionDenied {
+			t.Errorf("%s: user got %v, want permission_denied", procedure, got)
+		}
+		for _, role := range []string{"moderator", "admin"} {
+			if got := authorize(procedure, role); got != 0 {
+				t.Errorf("%s: %s got %v, want allowed", procedure, role, got)
+			}
+		}
+	}
+	if got := authorize(interestconnect.InterestServiceAddInterestToUserProcedure, "user"); got != 0 {
+		t.Errorf("users must still pick their own interests, got %v", got)
+	}
+}
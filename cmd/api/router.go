@@ -4,79 +4,145 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
-	"os"
+	"time"
 
 	"connectrpc.com/connect"
 	c "connectrpc.com/cors"
 
 	"connectrpc.com/validate"
+	listv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1/listv1connect"
+	recentsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1/recentsv1connect"
+	reviewv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1/reviewv1connect"
+	statisticsv1connect "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1/statisticsv1connect"
 	authconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth/authconnect"
 	chatconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/chat/chatconnect"
+	cityconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city/cityconnect"
 	discoverconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/discover/discoverconnect"
+	interestconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest/interestconnect"
+	poiconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi/poiconnect"
 	profileconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/profile/profileconnect"
+	userconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user/userconnect"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"go.opentelemetry.io/otel"
-	"golang.org/x/time/rate"
 
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/config"
 	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
 	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+	"github.com/FACorreiaa/loci-connect-api/pkg/ratelimit"
 )
 
-// SetupRouter configures all routes and returns the HTTP service
-func SetupRouter(deps *Dependencies) http.Handler {
+// SetupRouter configures all routes and returns the HTTP service. It fails when a
+// registered procedure has no authorization policy.
+func SetupRouter(deps *Dependencies) (http.Handler, error) {
 	mux := http.NewServeMux()
 
-	jwtSecret := []byte(deps.Config.Auth.JWTSecret)
-	if len(jwtSecret) == 0 {
-		deps.Logger.Warn("JWT secret is empty; authentication interceptor will reject requests")
-	}
-
-	publicProcedures := []string{
-		authconnect.AuthServiceRegisterProcedure,
-		authconnect.AuthServiceLoginProcedure,
-		authconnect.AuthServiceRefreshTokenProcedure,
-		authconnect.AuthServiceValidateSessionProcedure,
-	}
-
 	tracer := otel.GetTracerProvider().Tracer("loci/api")
 
+	// rateLimiter is a connect.Interceptor so that a nil one drops out of the chain.
 	var rateLimiter connect.Interceptor
-	if deps.Config.Server.RateLimitPerSecond > 0 && deps.Config.Server.RateLimitBurst > 0 {
-		limiter := rate.NewLimiter(
-			rate.Limit(float64(deps.Config.Server.RateLimitPerSecond)),
-			deps.Config.Server.RateLimitBurst,
-		)
-		rateLimiter = interceptors.NewRateLimitInterceptor(limiter)
+	var httpLimiter *interceptors.RateLimitInterceptor
+	if deps.RateLimitStore != nil {
+		httpLimiter = newRateLimitInterceptor(deps)
+		rateLimiter = httpLimiter
+	}
+	// limitHTTP puts plain HTTP endpoints, outside the interceptor chain, behind the
+	// same store.
+	limitHTTP := func(limit interceptors.HTTPRateLimit, h http.HandlerFunc) http.Handler {
+		if httpLimiter == nil {
+			return h
+		}
+		return httpLimiter.HTTPMiddleware(limit, h)
 	}
 
 	requestIDInterceptor := interceptors.NewRequestIDInterceptor("X-Request-ID")
 	tracingInterceptor := interceptors.NewTracingInterceptor(tracer)
 	validationInterceptor := validate.NewInterceptor()
 
+	authInterceptor := interceptors.NewAuthInterceptor(deps.JWTKeys, procedurePolicies.PublicProcedures()...).WithRevocations(deps.Revocations)
+	authzInterceptor := interceptors.NewAuthzInterceptor(procedurePolicies, deps.Logger)
+
+	// Token quotas are checked before the chat procedures call the LLM.
+	var quotaInterceptor connect.Interceptor
+	if deps.Config.Usage.QuotasEnabled && deps.UsageSvc != nil {
+		quotaInterceptor = interceptors.NewQuotaInterceptor(deps.UsageSvc, deps.Logger,
+			chatconnect.ChatServiceStartChatProcedure,
+			chatconnect.ChatServiceContinueChatProcedure,
+			chatconnect.ChatServiceStreamChatProcedure,
+		)
+	}
+
 	// Setup interceptor chain
 	interceptorChain := connect.WithInterceptors(
 		requestIDInterceptor,
 		tracingInterceptor,
 		validationInterceptor,
-		rateLimiter,
 		interceptors.NewRecoveryInterceptor(deps.Logger),
 		interceptors.NewLoggingInterceptor(deps.Logger),
-		interceptors.NewAuthInterceptor(jwtSecret, publicProcedures...),
+		authInterceptor,
+		authzInterceptor,
+		rateLimiter,
+		quotaInterceptor,
 		observability.NewMetricsInterceptor(),
 	)
 
 	// Register Connect RPC routes
-	registerConnectRoutes(mux, deps, interceptorChain)
+	servicePaths := registerConnectRoutes(mux, deps, interceptorChain)
+	if err := verifyPolicyCoverage(procedurePolicies, servicePaths); err != nil {
+		return nil, err
+	}
 
 	// Register health and metrics routes
 	registerUtilityRoutes(mux, deps)
 
+	if deps.UsageHandler != nil {
+		mux.Handle("/v1/usage", authInterceptor.HTTPMiddleware(deps.UsageHandler))
+		deps.Logger.Info("registered usage endpoint", "path", "/v1/usage")
+	}
+
+	if deps.SessionHandler != nil {
+		mux.Handle("GET /v1/auth/sessions", authInterceptor.HTTPMiddleware(http.HandlerFunc(deps.SessionHandler.ListSessions)))
+		mux.Handle("DELETE /v1/auth/sessions", authInterceptor.HTTPMiddleware(http.HandlerFunc(deps.SessionHandler.RevokeAllSessions)))
+		mux.Handle("DELETE /v1/auth/sessions/{id}", authInterceptor.HTTPMiddleware(http.HandlerFunc(deps.SessionHandler.RevokeSession)))
+		deps.Logger.Info("registered session endpoints", "path", "/v1/auth/sessions")
+	}
+
+	if deps.AuditHandler != nil {
+		mux.Handle("GET /v1/admin/auth/audit-events", authInterceptor.HTTPMiddleware(
+			authzInterceptor.HTTPMiddleware(interceptors.RequireRole(interceptors.RoleAdmin), http.HandlerFunc(deps.AuditHandler.ListAuditEvents))))
+		deps.Logger.Info("registered auth audit endpoint", "path", "/v1/admin/auth/audit-events")
+	}
+
+	if deps.ReviewHandler != nil {
+		mux.Handle("POST /v1/moderation/reviews/{id}", authInterceptor.HTTPMiddleware(
+			authzInterceptor.HTTPMiddleware(interceptors.RequireRole(interceptors.RoleModerator), http.HandlerFunc(deps.ReviewHandler.ModerateReview))))
+		deps.Logger.Info("registered review moderation endpoint", "path", "/v1/moderation/reviews")
+	}
+
+	if deps.AccountHandler != nil {
+		mux.Handle("POST /v1/auth/password/forgot", limitHTTP(emailSendLimit("password_forgot"), deps.AccountHandler.ForgotPassword))
+		mux.Handle("POST /v1/auth/password/reset", limitHTTP(tokenLimit("password_reset"), deps.AccountHandler.ResetPassword))
+		mux.Handle("POST /v1/auth/email/verify", limitHTTP(tokenLimit("email_verify"), deps.AccountHandler.VerifyEmail))
+		mux.Handle("POST /v1/auth/email/resend-verification", limitHTTP(emailSendLimit("email_resend_verification"), deps.AccountHandler.ResendVerification))
+		mux.Handle("POST /v1/auth/email/confirm-change", limitHTTP(tokenLimit("email_confirm_change"), deps.AccountHandler.ConfirmEmailChange))
+		deps.Logger.Info("registered account endpoints", "path", "/v1/auth")
+	}
+
+	if deps.OAuthHandler != nil {
+		mux.HandleFunc("GET /auth/{provider}", deps.OAuthHandler.Begin)
+		mux.HandleFunc("GET /auth/{provider}/callback", deps.OAuthHandler.Callback)
+		mux.HandleFunc("POST /auth/{provider}/callback", deps.OAuthHandler.Callback)
+		mux.Handle("POST /auth/exchange", limitHTTP(tokenLimit("auth_exchange"), deps.OAuthHandler.Exchange))
+		deps.Logger.Info("registered oauth endpoints", "path", "/auth")
+	}
+
 	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},                               // For testing ONLY—narrow to specifics like "http://localhost:3000" once working. Avoid in prod.
-		AllowedMethods:   c.AllowedMethods(),                          // ["GET", "POST", "OPTIONS"]
-		AllowedHeaders:   append(c.AllowedHeaders(), "Authorization"), // Adds "Authorization" for safety; full list: ["Accept-Encoding", "Content-Encoding", "Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent", "Authorization"]
-		ExposedHeaders:   c.ExposedHeaders(),                          // ["Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"]
+		AllowedOrigins:   []string{"*"},                                 // For testing ONLY—narrow to specifics like "http://localhost:3000" once working. Avoid in prod.
+		AllowedMethods:   append(c.AllowedMethods(), http.MethodDelete), // ["GET", "POST", "OPTIONS"] plus DELETE for /v1/auth/sessions
+		AllowedHeaders:   append(c.AllowedHeaders(), "Authorization"),   // Adds "Authorization" for safety; full list: ["Accept-Encoding", "Content-Encoding", "Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent", "Authorization"]
+		ExposedHeaders:   c.ExposedHeaders(),                            // ["Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"]
 		AllowCredentials: true,
 		MaxAge:           7200, // Cache preflights for 2 hours
 	})
@@ -107,37 +173,177 @@ func SetupRouter(deps *Dependencies) http.Handler {
 	//	AllowCredentials: true,
 	//})
 
-	return corsHandler.Handler(mux)
+	return corsHandler.Handler(mux), nil
+}
+
+// accountRequestBytes bounds the request bodies read for the email of a request.
+const accountRequestBytes = 4 << 10
+
+// emailSendLimit limits an endpoint that sends email, per client IP and per address so
+// that no inbox can be flooded from many IPs.
+func emailSendLimit(name string) interceptors.HTTPRateLimit {
+	return interceptors.HTTPRateLimit{
+		Name:      name,
+		PerIP:     ratelimit.Limit{Rate: 10.0 / 3600, Burst: 10},
+		PerTarget: ratelimit.Limit{Rate: 3.0 / 3600, Burst: 3},
+		Target:    interceptors.JSONBodyField("email", accountRequestBytes),
+	}
+}
+
+// tokenLimit limits an endpoint taking an emailed token or a one-time code, per client
+// IP, against guessing.
+func tokenLimit(name string) interceptors.HTTPRateLimit {
+	return interceptors.HTTPRateLimit{
+		Name:  name,
+		PerIP: ratelimit.Limit{Rate: 30.0 / 3600, Burst: 10},
+	}
+}
+
+// newRateLimitInterceptor limits callers by plan, weighting procedures by how much
+// work they trigger: the chat procedures call the LLM, search runs embeddings.
+func newRateLimitInterceptor(deps *Dependencies) *interceptors.RateLimitInterceptor {
+	cfg := deps.Config.RateLimit
+	tier := func(t config.RateLimitTier) ratelimit.Limit {
+		return ratelimit.Limit{Rate: t.PerSecond, Burst: float64(t.Burst)}
+	}
+	premium := tier(cfg.Premium)
+
+	var resolver interceptors.PlanResolver
+	if deps.UsageSvc != nil {
+		resolver = deps.UsageSvc
+	}
+
+	return interceptors.NewRateLimitInterceptor(interceptors.RateLimitOptions{
+		Store:     deps.RateLimitStore,
+		Anonymous: tier(cfg.Anonymous),
+		Plans: map[string]ratelimit.Limit{
+			locitypes.PlanFree:           tier(cfg.Free),
+			locitypes.PlanPremiumMonthly: premium,
+			locitypes.PlanPremiumAnnual:  premium,
+		},
+		DefaultPlan: locitypes.PlanFree,
+		Resolver:    resolver,
+		Weights: map[string]float64{
+			chatconnect.ChatServiceStreamChatProcedure:   10,
+			chatconnect.ChatServiceStartChatProcedure:    10,
+			chatconnect.ChatServiceContinueChatProcedure: 5,
+			poiconnect.POIServiceSearchPOIProcedure:      3,
+			authconnect.AuthServiceLoginProcedure:        5,
+			authconnect.AuthServiceRegisterProcedure:     5,
+		},
+		TrustedProxies: deps.TrustedProxies,
+		Logger:         deps.Logger,
+	})
 }
 
-// registerConnectRoutes registers all Connect RPC service service
-func registerConnectRoutes(mux *http.ServeMux, deps *Dependencies, opts connect.HandlerOption) {
+// registerConnectRoutes registers all Connect RPC services and returns the paths they
+// are mounted at.
+func registerConnectRoutes(mux *http.ServeMux, deps *Dependencies, opts connect.HandlerOption) []string {
+	var paths []string
 	authServicePath, authServiceHandler := authconnect.NewAuthServiceHandler(
 		deps.AuthHandler,
 		opts,
 	)
+	paths = append(paths, authServicePath)
 	mux.Handle(authServicePath, authServiceHandler)
 	deps.Logger.Info("registered Connect RPC service", "path", authServicePath)
 
 	if deps.ChatHandler != nil {
 		chatPath, chatHandler := chatconnect.NewChatServiceHandler(deps.ChatHandler, opts)
+		paths = append(paths, chatPath)
 		mux.Handle(chatPath, chatHandler)
 		deps.Logger.Info("registered Connect RPC service", "path", chatPath)
 	}
 
 	if deps.DiscoverHandler != nil {
 		discoverPath, discoverHandler := discoverconnect.NewDiscoverServiceHandler(deps.DiscoverHandler, opts)
+		paths = append(paths, discoverPath)
 		mux.Handle(discoverPath, discoverHandler)
 		deps.Logger.Info("registered Connect RPC service", "path", discoverPath)
 	}
 
+	if deps.POIHandler != nil {
+		poiPath, poiHandler := poiconnect.NewPOIServiceHandler(deps.POIHandler, opts)
+		paths = append(paths, poiPath)
+		mux.Handle(poiPath, poiHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", poiPath)
+	}
+
+	if deps.ListHandler != nil {
+		listPath, listHandler := listv1connect.NewListServiceHandler(deps.ListHandler, opts)
+		paths = append(paths, listPath)
+		mux.Handle(listPath, listHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", listPath)
+	}
+
+	if deps.ReviewHandler != nil {
+		reviewPath, reviewHandler := reviewv1connect.NewReviewServiceHandler(deps.ReviewHandler, opts)
+		paths = append(paths, reviewPath)
+		mux.Handle(reviewPath, reviewHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", reviewPath)
+	}
+
+	if deps.RecentsHandler != nil {
+		recentsPath, recentsHandler := recentsv1connect.NewRecentsServiceHandler(deps.RecentsHandler, opts)
+		paths = append(paths, recentsPath)
+		mux.Handle(recentsPath, recentsHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", recentsPath)
+	}
+
+	if deps.StatsHandler != nil {
+		statsPath, statsHandler := statisticsv1connect.NewStatisticsServiceHandler(deps.StatsHandler, opts)
+		paths = append(paths, statsPath)
+		mux.Handle(statsPath, withoutWriteDeadline(statsHandler,
+			statisticsv1connect.StatisticsServiceStreamMainPageStatisticsProcedure,
+		))
+		deps.Logger.Info("registered Connect RPC service", "path", statsPath)
+	}
+
 	if deps.ProfileHandler != nil {
 		profilePath, profileHandler := profileconnect.NewProfileServiceHandler(deps.ProfileHandler, opts)
+		paths = append(paths, profilePath)
 		mux.Handle(profilePath, profileHandler)
 		deps.Logger.Info("registered Connect RPC service", "path", profilePath)
 	}
 
+	if deps.CityHandler != nil {
+		cityPath, cityHandler := cityconnect.NewCityServiceHandler(deps.CityHandler, opts)
+		paths = append(paths, cityPath)
+		mux.Handle(cityPath, cityHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", cityPath)
+	}
+
+	if deps.InterestHandler != nil {
+		interestPath, interestHandler := interestconnect.NewInterestServiceHandler(deps.InterestHandler, opts)
+		paths = append(paths, interestPath)
+		mux.Handle(interestPath, interestHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", interestPath)
+	}
+
+	if deps.UserHandler != nil {
+		userPath, userHandler := userconnect.NewUserServiceHandler(deps.UserHandler, opts)
+		paths = append(paths, userPath)
+		mux.Handle(userPath, userHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", userPath)
+	}
+
 	deps.Logger.Info("Connect RPC routes configured")
+	return paths
+}
+
+// withoutWriteDeadline lifts the server WriteTimeout for long-lived server streams,
+// which would otherwise be cut off mid-stream.
+func withoutWriteDeadline(next http.Handler, procedures ...string) http.Handler {
+	streaming := make(map[string]bool, len(procedures))
+	for _, p := range procedures {
+		streaming[p] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if streaming[r.URL.Path] {
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // registerUtilityRoutes registers health check, metrics, and other utility routes
@@ -175,7 +381,9 @@ func registerUtilityRoutes(mux *http.ServeMux, deps *Dependencies) {
 			result["ready"] = status{Status: "fail", Detail: "db unavailable"}
 		}
 
-		if os.Getenv("GEMINI_API_KEY") == "" {
+		provider, _ := llm.NormalizeProvider(deps.Config.LLM.Provider)
+		mode, _ := llm.NormalizeMode(deps.Config.LLM.Mode)
+		if provider == llm.ProviderGoogle && mode != llm.ModeReplay && deps.Config.LLM.GeminiAPIKey == "" {
 			result["env"] = status{Status: "warn", Detail: "GEMINI_API_KEY missing"}
 		}
 
@@ -205,6 +413,16 @@ func registerUtilityRoutes(mux *http.ServeMux, deps *Dependencies) {
 	})
 	deps.Logger.Info("registered readiness check", "path", "/ready")
 
+	// Public signing keys, so other services can verify our tokens
+	mux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(deps.JWTKeys.JWKS()); err != nil {
+			deps.Logger.Error("failed to encode jwks", slog.Any("error", err))
+		}
+	})
+	deps.Logger.Info("registered jwks endpoint", "path", "/.well-known/jwks.json")
+
 	// Metrics endpoint (Prometheus)
 	if deps.Config.Observability.MetricsEnabled {
 		mux.Handle("/metrics", promhttp.Handler())
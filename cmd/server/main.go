@@ -16,6 +16,7 @@ import (
 
 	"github.com/FACorreiaa/loci-connect-api/cmd/api"
 	"github.com/FACorreiaa/loci-connect-api/pkg/config"
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
 )
 
 func main() {
@@ -24,24 +25,54 @@ func main() {
 		log.Fatal(err)
 	}
 	// Initialize logger
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(observability.NewTraceHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
-	}))
+	})))
+	slog.SetDefault(logger)
 
 	logger.Info("starting loci API")
 
+	if err := run(logger); err != nil {
+		logger.Error("loci API stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run starts the API and blocks until it stops. It returns instead of exiting so that
+// deferred cleanup, such as flushing traces, also runs when startup fails.
+func run(logger *slog.Logger) error {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Error("failed to load config", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Initialize tracing
+	if cfg.Observability.OTLPEndpoint != "" {
+		shutdownTracing, err := observability.SetupTracing(context.Background(), observability.TracingOptions{
+			ServiceName:    cfg.Observability.ServiceName,
+			ServiceVersion: cfg.Observability.ServiceVersion,
+			Environment:    cfg.Observability.Environment,
+			Endpoint:       cfg.Observability.OTLPEndpoint,
+			SampleRatio:    cfg.Observability.TraceSampleRatio,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize tracing: %w", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				logger.Error("failed to flush traces", "error", err)
+			}
+		}()
+		logger.Info("tracing enabled", "endpoint", cfg.Observability.OTLPEndpoint)
 	}
 
 	// Initialize dependencies
 	deps, err := api.InitDependencies(cfg, logger)
 	if err != nil {
-		logger.Error("failed to initialize dependencies", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize dependencies: %w", err)
 	}
 	defer deps.Cleanup()
 
@@ -51,13 +82,13 @@ func main() {
 	}
 
 	// Setup router
-	handler := api.SetupRouter(deps)
+	handler, err := api.SetupRouter(deps)
+	if err != nil {
+		return fmt.Errorf("failed to set up router: %w", err)
+	}
 
 	// Start HTTP server
-	if err := runServer(cfg, logger, handler); err != nil {
-		logger.Error("server error", "error", err)
-		os.Exit(1)
-	}
+	return runServer(cfg, logger, handler)
 }
 
 // startPprofServer starts the pprof profiling server on a separate port
@@ -9,4 +9,5 @@ var (
 	ErrInvalidToken       = errors.New("invalid or expired token")
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrInvalidCredentials = errors.New("invalid or expired credentials")
+	ErrTokenReused        = errors.New("refresh token was already used")
 )
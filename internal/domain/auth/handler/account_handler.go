@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+)
+
+// maxAccountRequestBytes bounds the JSON bodies of the account endpoints.
+const maxAccountRequestBytes = 4 << 10
+
+// AccountHandler serves the emailed-link flows: password reset, email verification and
+// email change confirmation. The AuthService proto has no procedures for them, so they
+// are plain HTTP endpoints. They are public; the emailed token is the credential.
+type AccountHandler struct {
+	service *service.AuthService
+	logger  *slog.Logger
+}
+
+// NewAccountHandler constructs a new account handler.
+func NewAccountHandler(svc *service.AuthService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+type emailRequest struct {
+	Email string `json:"email"`
+}
+
+type tokenRequest struct {
+	Token string `json:"token"`
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ForgotPassword handles POST /v1/auth/password/forgot. It answers 202 whether or not
+// the email belongs to an account.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req emailRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to request password reset", slog.Any("error", err))
+		http.Error(w, "failed to request password reset", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword handles POST /v1/auth/password/reset.
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		http.Error(w, "token and new_password are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		h.writeError(w, r, "failed to reset password", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail handles POST /v1/auth/email/verify.
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.VerifyEmail(r.Context(), req.Token); err != nil {
+		h.writeError(w, r, "failed to verify email", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification handles POST /v1/auth/email/resend-verification. Like
+// ForgotPassword, it does not reveal whether the email belongs to an account.
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req emailRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.ResendVerificationEmail(r.Context(), req.Email); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to resend verification email", slog.Any("error", err))
+		http.Error(w, "failed to resend verification email", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmEmailChange handles POST /v1/auth/email/confirm-change.
+func (h *AccountHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.ConfirmEmailChange(r.Context(), req.Token); err != nil {
+		h.writeError(w, r, "failed to change email", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AccountHandler) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAccountRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *AccountHandler) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	switch {
+	case errors.Is(err, common.ErrInvalidToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, common.ErrUserAlreadyExists):
+		http.Error(w, "email is already in use", http.StatusConflict)
+	case errors.Is(err, service.ErrPasswordTooShort),
+		errors.Is(err, service.ErrPasswordNoDigit),
+		errors.Is(err, service.ErrPasswordNoLowercase),
+		errors.Is(err, service.ErrPasswordNoUppercase),
+		errors.Is(err, service.ErrPasswordNoSpecial):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), msg, slog.Any("error", err))
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
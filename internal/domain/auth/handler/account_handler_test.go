@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+)
+
+func TestAccountHandler_PasswordReset(t *testing.T) {
+	svc, repo, _, emails := servicetest.NewTestAuthService()
+	h := NewAccountHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	user := servicetest.AddUser(repo, t, "forgot@example.com", true, servicetest.MustHash(t, "OldPass!1"))
+
+	rec := httptest.NewRecorder()
+	h.ForgotPassword(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/password/forgot", strings.NewReader(`{"email":"forgot@example.com"}`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	if !emails.ResetSent() {
+		t.Fatalf("expected the email to be queued")
+	}
+
+	// Unknown emails get the same answer.
+	rec = httptest.NewRecorder()
+	h.ForgotPassword(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/password/forgot", strings.NewReader(`{"email":"nobody@example.com"}`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 for an unknown email, got %d", rec.Code)
+	}
+
+	repo.Tokens[hashTestToken("reset-token")] = &repository.UserToken{
+		TokenHash: hashTestToken("reset-token"),
+		UserID:    user.ID,
+		Type:      "password_reset",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	rec = httptest.NewRecorder()
+	h.ResetPassword(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/password/reset", strings.NewReader(`{"token":"reset-token","new_password":"weak"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a weak password, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ResetPassword(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/password/reset", strings.NewReader(`{"token":"reset-token","new_password":"NewPass!2"}`)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+	if !service.ComparePassword(repo.Users[user.Email].HashedPassword, "NewPass!2") {
+		t.Fatalf("password not updated")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ResetPassword(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/password/reset", strings.NewReader(`{"token":"reset-token","new_password":"NewPass!3"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a used token, got %d", rec.Code)
+	}
+}
+
+func TestAccountHandler_EmailFlows(t *testing.T) {
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	h := NewAccountHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	user := servicetest.AddUser(repo, t, "current@example.com", true, "hashed")
+	servicetest.AddUser(repo, t, "taken@example.com", true, "hashed")
+
+	repo.Tokens[hashTestToken("verify-token")] = &repository.UserToken{
+		TokenHash: hashTestToken("verify-token"),
+		UserID:    user.ID,
+		Type:      "email_verification",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	rec := httptest.NewRecorder()
+	h.VerifyEmail(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/email/verify", strings.NewReader(`{"token":"verify-token"}`)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if repo.Users[user.Email].EmailVerifiedAt == nil {
+		t.Fatalf("email not verified")
+	}
+
+	taken := "taken@example.com"
+	repo.Tokens[hashTestToken("change-token")] = &repository.UserToken{
+		TokenHash: hashTestToken("change-token"),
+		UserID:    user.ID,
+		Type:      repository.TokenTypeEmailChange,
+		NewEmail:  &taken,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	rec = httptest.NewRecorder()
+	h.ConfirmEmailChange(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/email/confirm-change", strings.NewReader(`{"token":"change-token"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when the address was taken meanwhile, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.VerifyEmail(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/email/verify", strings.NewReader(`not json`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed body, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// AuditHandler lets administrators query the auth audit log. Like the session
+// endpoints it is plain HTTP behind AuthInterceptor.HTTPMiddleware, as the AuthService
+// proto has no procedure for it.
+type AuditHandler struct {
+	service *service.AuthService
+	logger  *slog.Logger
+}
+
+// NewAuditHandler constructs a new audit handler.
+func NewAuditHandler(svc *service.AuthService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// AuditEvent is the JSON view of an AuthAuditEvent.
+type AuditEvent struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	EventType string    `json:"event_type"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListAuditEvents handles GET /v1/admin/auth/audit-events. The optional query
+// parameters user_id, email, event_type, client_ip, since and until (RFC 3339) filter
+// the events; limit and before_id page through them, newest first.
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if _, err := interceptors.GetClaimsFromContext(r.Context()); err != nil {
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !interceptors.HasRole(r.Context(), interceptors.RoleAdmin) {
+		http.Error(w, "admin role required", http.StatusForbidden)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.ListAuditEvents(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list audit events", slog.Any("error", err))
+		http.Error(w, "failed to list audit events", http.StatusInternalServerError)
+		return
+	}
+
+	out := struct {
+		Events []AuditEvent `json:"events"`
+		// NextBeforeID is the before_id of the next page; it is omitted on the last page.
+		NextBeforeID int64 `json:"next_before_id,omitempty"`
+	}{Events: make([]AuditEvent, 0, len(page.Events)), NextBeforeID: page.NextBeforeID}
+	for _, e := range page.Events {
+		event := AuditEvent{
+			ID:        e.ID,
+			EventType: e.EventType,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.UserID != nil {
+			event.UserID = e.UserID.String()
+		}
+		if e.Email != nil {
+			event.Email = *e.Email
+		}
+		if e.ClientIP != nil {
+			event.ClientIP = *e.ClientIP
+		}
+		if e.UserAgent != nil {
+			event.UserAgent = *e.UserAgent
+		}
+		out.Events = append(out.Events, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode audit events", slog.Any("error", err))
+	}
+}
+
+func parseAuditFilter(r *http.Request) (repository.AuthAuditFilter, error) {
+	query := r.URL.Query()
+	filter := repository.AuthAuditFilter{
+		Email:     query.Get("email"),
+		EventType: query.Get("event_type"),
+		ClientIP:  query.Get("client_ip"),
+	}
+
+	if v := query.Get("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errInvalidParam("user_id")
+		}
+		filter.UserID = &userID
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errInvalidParam(name)
+			}
+			*dst = t
+		}
+	}
+	if v := query.Get("before_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, errInvalidParam("before_id")
+		}
+		filter.BeforeID = id
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, errInvalidParam("limit")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+type errInvalidParam string
+
+func (e errInvalidParam) Error() string {
+	return "invalid " + string(e)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
+)
+
+func TestAuditHandler_ListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	h := NewAuditHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	user := servicetest.AddUser(repo, t, "audited@example.com", true, servicetest.MustHash(t, "Str0ng!Pass"))
+	_, _ = svc.Login(ctx, service.LoginParams{Email: user.Email, Password: "Wrong!Pass1",
+		Metadata: service.SessionMetadata{ClientIP: "203.0.113.9", UserAgent: "curl"}})
+	_, _ = svc.Login(ctx, service.LoginParams{Email: user.Email, Password: "Str0ng!Pass"})
+
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, _ := jwtkeys.NewKeySet(hmacKey)
+	tokenFor := func(role string) string {
+		token, err := keys.Sign(&interceptors.Claims{
+			UserID:   user.ID.String(),
+			Role:     role,
+			TokenUse: jwtkeys.TokenUseAccess,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/admin/auth/audit-events",
+		interceptors.NewAuthInterceptor(keys).HTTPMiddleware(http.HandlerFunc(h.ListAuditEvents)))
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/v1/admin/auth/audit-events", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", rec.Code)
+	}
+	if rec := get("/v1/admin/auth/audit-events", tokenFor("member")); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for members, got %d", rec.Code)
+	}
+	if rec := get("/v1/admin/auth/audit-events?since=yesterday", tokenFor("admin")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad timestamp, got %d", rec.Code)
+	}
+
+	rec := get("/v1/admin/auth/audit-events?event_type="+repository.AuditEventLoginFailed+"&user_id="+user.ID.String(), tokenFor("admin"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var out struct {
+		Events []AuditEvent `json:"events"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Events) != 1 {
+		t.Fatalf("expected the failed login only, got %+v", out.Events)
+	}
+	if e := out.Events[0]; e.Email != user.Email || e.ClientIP != "203.0.113.9" || e.UserAgent != "curl" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
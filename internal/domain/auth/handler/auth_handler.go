@@ -3,21 +3,28 @@ package handler
 import (
 	"context"
 	"errors"
+	"math"
+	"net/http"
+	"strconv"
 
 	"connectrpc.com/connect"
 	auth "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth"
 	authconnect "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth/authconnect"
 	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+	"github.com/google/uuid"
 
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/presenter"
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
 )
 
 // AuthHandler implements the AuthService Connect handlers.
 type AuthHandler struct {
 	authconnect.UnimplementedAuthServiceHandler
 	service *service.AuthService
+	// trustedProxies may name the client IP in X-Forwarded-For; see interceptors.ClientIP.
+	trustedProxies interceptors.TrustedProxies
 }
 
 // NewAuthHandler constructs a new handler.
@@ -27,6 +34,13 @@ func NewAuthHandler(svc *service.AuthService) *AuthHandler {
 	}
 }
 
+// WithTrustedProxies makes the handler read client IPs, which login throttling and the
+// audit log key on, from the forwarding headers set by proxies.
+func (h *AuthHandler) WithTrustedProxies(proxies interceptors.TrustedProxies) *AuthHandler {
+	h.trustedProxies = proxies
+	return h
+}
+
 // Register handles user registration RPCs.
 func (h *AuthHandler) Register(
 	ctx context.Context,
@@ -41,7 +55,7 @@ func (h *AuthHandler) Register(
 		Username:    req.Msg.Username,
 		Password:    string(req.Msg.Password),
 		DisplayName: req.Msg.Username,
-		Metadata:    metadataFromRequest(req),
+		Metadata:    h.metadata(req.Header(), req.Peer()),
 	})
 	if err != nil {
 		return nil, h.toConnectError(err)
@@ -59,7 +73,7 @@ func (h *AuthHandler) Login(ctx context.Context, req *connect.Request[auth.Login
 	result, err := h.service.Login(ctx, service.LoginParams{
 		Email:    req.Msg.Email,
 		Password: req.Msg.Password,
-		Metadata: metadataFromRequest(req),
+		Metadata: h.metadata(req.Header(), req.Peer()),
 	})
 	if err != nil {
 		return nil, h.toConnectError(err)
@@ -76,7 +90,7 @@ func (h *AuthHandler) RefreshToken(ctx context.Context, req *connect.Request[aut
 
 	tokens, err := h.service.RefreshTokens(ctx, service.RefreshTokenParams{
 		RefreshToken: req.Msg.RefreshToken,
-		Metadata:     metadataFromRequest(req),
+		Metadata:     h.metadata(req.Header(), req.Peer()),
 	})
 	if err != nil {
 		return nil, h.toConnectError(err)
@@ -99,20 +113,63 @@ func (h *AuthHandler) ValidateSession(ctx context.Context, req *connect.Request[
 	return connect.NewResponse(presenter.ValidateSessionResponse(claims)), nil
 }
 
-// ChangePassword is not yet implemented.
-func (h *AuthHandler) ChangePassword(_ context.Context, _ *connect.Request[auth.ChangePasswordRequest]) (*connect.Response[commonpb.Response], error) {
-	return nil, connect.NewError(
-		connect.CodeUnimplemented,
-		errors.New("change password is not implemented"),
-	)
+// ChangePassword changes the caller's password and signs out their other sessions.
+func (h *AuthHandler) ChangePassword(ctx context.Context, req *connect.Request[auth.ChangePasswordRequest]) (*connect.Response[commonpb.Response], error) {
+	if req.Msg.OldPassword == "" || req.Msg.NewPassword == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("old and new password are required"))
+	}
+
+	userID, ok := interceptors.GetUserIDFromContext(ctx)
+	if !ok || userID == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+	var currentTokenID string
+	if claims, err := interceptors.GetClaimsFromContext(ctx); err == nil {
+		currentTokenID = claims.ID
+	}
+
+	if err := h.service.ChangePassword(ctx, service.ChangePasswordParams{
+		UserID:          userID,
+		CurrentPassword: req.Msg.OldPassword,
+		NewPassword:     req.Msg.NewPassword,
+		CurrentTokenID:  currentTokenID,
+		Metadata:        h.metadata(req.Header(), req.Peer()),
+	}); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	msg := "Password changed successfully"
+	return connect.NewResponse(&commonpb.Response{
+		Success: true,
+		Message: &msg,
+	}), nil
 }
 
-// ChangeEmail is not yet implemented.
-func (h *AuthHandler) ChangeEmail(_ context.Context, _ *connect.Request[auth.ChangeEmailRequest]) (*connect.Response[commonpb.Response], error) {
-	return nil, connect.NewError(
-		connect.CodeUnimplemented,
-		errors.New("change email is not implemented"),
-	)
+// ChangeEmail sends a confirmation link to the new address. The email changes once the
+// link is followed.
+func (h *AuthHandler) ChangeEmail(ctx context.Context, req *connect.Request[auth.ChangeEmailRequest]) (*connect.Response[commonpb.Response], error) {
+	if req.Msg.Password == "" || req.Msg.NewEmail == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("password and new email are required"))
+	}
+
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid user id"))
+	}
+
+	if err := h.service.RequestEmailChange(ctx, userID, req.Msg.Password, req.Msg.NewEmail); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	msg := "Check your new email address to confirm the change"
+	return connect.NewResponse(&commonpb.Response{
+		Success: true,
+		Message: &msg,
+	}), nil
 }
 
 // Logout deletes the refresh token session.
@@ -121,7 +178,7 @@ func (h *AuthHandler) Logout(ctx context.Context, req *connect.Request[auth.Logo
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("refresh token is required"))
 	}
 
-	if err := h.service.Logout(ctx, req.Msg.RefreshToken); err != nil {
+	if err := h.service.Logout(ctx, req.Msg.RefreshToken, h.metadata(req.Header(), req.Peer())); err != nil {
 		return nil, h.toConnectError(err)
 	}
 
@@ -132,25 +189,33 @@ func (h *AuthHandler) Logout(ctx context.Context, req *connect.Request[auth.Logo
 	}), nil
 }
 
-func metadataFromRequest[T any](req *connect.Request[T]) service.SessionMetadata {
+func (h *AuthHandler) metadata(header http.Header, peer connect.Peer) service.SessionMetadata {
 	return service.SessionMetadata{
-		UserAgent: req.Header().Get("User-Agent"),
-		ClientIP:  req.Peer().Addr,
+		UserAgent: header.Get("User-Agent"),
+		ClientIP:  interceptors.ClientIP(header, peer, h.trustedProxies),
 	}
 }
 
 func (h *AuthHandler) toConnectError(err error) error {
+	var lockedOut *service.LockedOutError
 	switch {
+	case errors.As(err, &lockedOut):
+		connectErr := connect.NewError(connect.CodeResourceExhausted, service.ErrTooManyAttempts)
+		retryAfter := int(math.Ceil(lockedOut.RetryAfter.Seconds()))
+		connectErr.Meta().Set(interceptors.RetryAfterHeader, strconv.Itoa(max(retryAfter, 1)))
+		return connectErr
 	case errors.Is(err, common.ErrUserAlreadyExists):
 		return connect.NewError(connect.CodeAlreadyExists, err)
 	case errors.Is(err, common.ErrInvalidCredentials):
 		return connect.NewError(connect.CodeUnauthenticated, err)
-	case errors.Is(err, common.ErrInvalidToken), errors.Is(err, common.ErrSessionNotFound):
+	case errors.Is(err, common.ErrInvalidToken), errors.Is(err, common.ErrSessionNotFound), errors.Is(err, common.ErrTokenReused):
 		return connect.NewError(connect.CodeUnauthenticated, err)
 	case errors.Is(err, common.ErrUserNotFound):
 		return connect.NewError(connect.CodeNotFound, err)
 	case errors.Is(err, service.ErrAccountInactive):
 		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, service.ErrInvalidEmail), errors.Is(err, service.ErrEmailUnchanged):
+		return connect.NewError(connect.CodeInvalidArgument, err)
 	case errors.Is(err, service.ErrPasswordTooShort),
 		errors.Is(err, service.ErrPasswordNoDigit),
 		errors.Is(err, service.ErrPasswordNoLowercase),
@@ -5,15 +5,19 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"io"
+	"log/slog"
 	"testing"
 	"time"
 
 	"connectrpc.com/connect"
 	auth "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/auth"
+	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
 )
 
 func TestAuthHandler_Register_Success(t *testing.T) {
@@ -48,7 +52,9 @@ func TestAuthHandler_Register_Success(t *testing.T) {
 	if _, err := repo.GetUserByEmail(ctx, "rpc-register@example.com"); err != nil {
 		t.Fatalf("user not stored: %v", err)
 	}
-	servicetest.WaitFor(t, func() bool { return emails.VerificationSent() })
+	if !emails.VerificationSent() {
+		t.Fatalf("expected the email to be queued")
+	}
 	if len(repo.Sessions) != 1 {
 		t.Fatalf("expected one session, got %d", len(repo.Sessions))
 	}
@@ -140,7 +146,13 @@ func TestAuthHandler_RefreshToken_Success(t *testing.T) {
 	repo.Users[user.Email] = user
 
 	oldRefresh := "old-refresh"
-	if _, err := repo.CreateUserSession(ctx, user.ID, hashTestToken(oldRefresh), "agent", "ip", time.Now().Add(time.Hour)); err != nil {
+	if _, err := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID:             user.ID,
+		HashedRefreshToken: hashTestToken(oldRefresh),
+		UserAgent:          "agent",
+		ClientIP:           "ip",
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}); err != nil {
 		t.Fatalf("CreateUserSession: %v", err)
 	}
 
@@ -167,11 +179,12 @@ func TestAuthHandler_RefreshToken_Success(t *testing.T) {
 	if resp.Msg.RefreshToken != "new-refresh" {
 		t.Fatalf("unexpected refresh token %s", resp.Msg.RefreshToken)
 	}
-	if len(repo.Sessions) != 1 {
-		t.Fatalf("expected one session after refresh, got %d", len(repo.Sessions))
+	active, _ := repo.ListActiveUserSessions(ctx, user.ID)
+	if len(active) != 1 {
+		t.Fatalf("expected one active session after refresh, got %d", len(active))
 	}
-	if _, ok := repo.Sessions[hashTestToken(oldRefresh)]; ok {
-		t.Fatalf("old session should be removed")
+	if old := repo.Sessions[hashTestToken(oldRefresh)]; old == nil || old.RotatedAt == nil {
+		t.Fatalf("old session should be marked rotated")
 	}
 	if _, ok := repo.Sessions[hashTestToken("new-refresh")]; !ok {
 		t.Fatalf("new session should be stored")
@@ -213,6 +226,29 @@ func TestAuthHandler_ValidateSession(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_ValidateSession_RevokedToken(t *testing.T) {
+	ctx := context.Background()
+	repo := servicetest.NewMockAuthRepo()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	revocations := service.NewRevocationList(repo, logger, time.Hour)
+	tokens := &servicetest.MockTokenManager{AccessFunc: func(string) (*service.Claims, error) {
+		return &service.Claims{UserID: "user-id", RegisteredClaims: jwt.RegisteredClaims{ID: "signed-out"}}, nil
+	}}
+	handler := NewAuthHandler(service.NewAuthService(repo, tokens, nil, revocations, logger, time.Hour))
+
+	revocations.Add(repository.RevokedToken{JTI: "signed-out", ExpiresAt: time.Now().Add(time.Minute)})
+
+	resp, err := handler.ValidateSession(ctx, connect.NewRequest(&auth.ValidateSessionRequest{
+		SessionId: "access-token",
+	}))
+	if err != nil {
+		t.Fatalf("ValidateSession: %v", err)
+	}
+	if resp.Msg.Valid {
+		t.Fatalf("expected a revoked token to be invalid")
+	}
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	ctx := context.Background()
 	svc, repo, _, _ := servicetest.NewTestAuthService()
@@ -239,6 +275,70 @@ func TestAuthHandler_Logout(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_ChangePassword(t *testing.T) {
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	handler := NewAuthHandler(svc)
+	user := servicetest.AddUser(repo, t, "change-rpc@example.com", true, servicetest.MustHash(t, "Str0ng!Pass"))
+	ctx := context.WithValue(context.Background(), interceptors.UserIDKey, user.ID.String())
+
+	_, err := handler.ChangePassword(ctx, connect.NewRequest(&auth.ChangePasswordRequest{
+		OldPassword: "Wr0ng!Pass",
+		NewPassword: "NewPass!2",
+	}))
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected unauthenticated for a wrong password, got %v", err)
+	}
+
+	resp, err := handler.ChangePassword(ctx, connect.NewRequest(&auth.ChangePasswordRequest{
+		OldPassword: "Str0ng!Pass",
+		NewPassword: "NewPass!2",
+	}))
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if resp.Msg == nil || !resp.Msg.Success {
+		t.Fatalf("unexpected response %#v", resp.Msg)
+	}
+	if !service.ComparePassword(repo.Users[user.Email].HashedPassword, "NewPass!2") {
+		t.Fatalf("password not updated")
+	}
+
+	if _, err := handler.ChangePassword(context.Background(), connect.NewRequest(&auth.ChangePasswordRequest{
+		OldPassword: "NewPass!2",
+		NewPassword: "Other!Pass3",
+	})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected unauthenticated without a user, got %v", err)
+	}
+}
+
+func TestAuthHandler_ChangeEmail(t *testing.T) {
+	svc, repo, _, emails := servicetest.NewTestAuthService()
+	handler := NewAuthHandler(svc)
+	user := servicetest.AddUser(repo, t, "before@example.com", true, servicetest.MustHash(t, "Str0ng!Pass"))
+	ctx := context.WithValue(context.Background(), interceptors.UserIDKey, user.ID.String())
+
+	_, err := handler.ChangeEmail(ctx, connect.NewRequest(&auth.ChangeEmailRequest{
+		Password: "Str0ng!Pass",
+		NewEmail: "before@example.com",
+	}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("expected invalid argument for an unchanged email, got %v", err)
+	}
+
+	if _, err := handler.ChangeEmail(ctx, connect.NewRequest(&auth.ChangeEmailRequest{
+		Password: "Str0ng!Pass",
+		NewEmail: "after@example.com",
+	})); err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if emails.EmailChangeToken() == "" {
+		t.Fatalf("expected the email to be queued")
+	}
+	if repo.Users[user.Email] == nil {
+		t.Fatalf("email must not change before confirmation")
+	}
+}
+
 func TestAuthHandler_ErrorMappings(t *testing.T) {
 	ctx := context.Background()
 	svc, repo, _, _ := servicetest.NewTestAuthService()
@@ -265,6 +365,35 @@ func TestAuthHandler_ErrorMappings(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_LockedOut(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	handler := NewAuthHandler(svc)
+	servicetest.AddUser(repo, t, "throttled@example.com", true, servicetest.MustHash(t, "Str0ng!Pass"))
+
+	login := func(password string) error {
+		_, err := handler.Login(ctx, connect.NewRequest(&auth.LoginRequest{
+			Email:    "throttled@example.com",
+			Password: password,
+		}))
+		return err
+	}
+	for range 5 {
+		if err := login("Wr0ng!Pass"); connect.CodeOf(err) != connect.CodeUnauthenticated {
+			t.Fatalf("expected unauthenticated, got %v", err)
+		}
+	}
+
+	err := login("Str0ng!Pass")
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("expected resource exhausted, got %v", err)
+	}
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Meta().Get("Retry-After") == "" {
+		t.Fatalf("expected a Retry-After header, got %v", err)
+	}
+}
+
 func hashTestToken(token string) string {
 	sum := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(sum[:])
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	// oauthSessionName is the cookie holding login state until the provider calls back.
+	oauthSessionName = "loci_oauth"
+	oauthSessionTTL  = 10 * 60 // seconds
+
+	pkceMethodS256 = "S256"
+	// pkceChallengeLen is the length of a base64url encoded SHA-256 digest.
+	pkceChallengeLen = 43
+)
+
+// OAuthHandler runs provider logins for native apps. The app opens
+// /auth/{provider} with a redirect_uri and a PKCE code_challenge; after the provider
+// calls back, the app's redirect_uri receives a one-time code that it exchanges for loci
+// tokens at /auth/exchange with its code_verifier.
+type OAuthHandler struct {
+	service   *service.AuthService
+	providers map[string]goth.Provider
+	store     sessions.Store
+	redirects []string
+	logger    *slog.Logger
+	// trustedProxies may name the client IP in X-Forwarded-For; see interceptors.ClientIP.
+	trustedProxies interceptors.TrustedProxies
+}
+
+// NewOAuthHandler constructs a new OAuth handler. allowedRedirects lists the exact
+// redirect URIs apps may ask for.
+func NewOAuthHandler(
+	svc *service.AuthService,
+	providers []goth.Provider,
+	store sessions.Store,
+	allowedRedirects []string,
+	logger *slog.Logger,
+) *OAuthHandler {
+	byName := make(map[string]goth.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &OAuthHandler{
+		service:   svc,
+		providers: byName,
+		store:     store,
+		redirects: allowedRedirects,
+		logger:    logger,
+	}
+}
+
+// Begin handles GET /auth/{provider} by redirecting to the provider's consent page.
+func (h *OAuthHandler) Begin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if !slices.Contains(h.redirects, redirectURI) {
+		http.Error(w, "redirect_uri is not allowed", http.StatusBadRequest)
+		return
+	}
+	challenge := query.Get("code_challenge")
+	if query.Get("code_challenge_method") != pkceMethodS256 || len(challenge) != pkceChallengeLen {
+		http.Error(w, "an S256 code_challenge is required", http.StatusBadRequest)
+		return
+	}
+
+	stateBytes := make([]byte, 32)
+	if _, err := rand.Read(stateBytes); err != nil {
+		http.Error(w, "failed to start login", http.StatusInternalServerError)
+		return
+	}
+	state := base64.RawURLEncoding.EncodeToString(stateBytes)
+
+	authSession, err := provider.BeginAuth(state)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to begin oauth login", slog.String("provider", provider.Name()), slog.Any("error", err))
+		http.Error(w, "failed to start login", http.StatusBadGateway)
+		return
+	}
+	authURL, err := authSession.GetAuthURL()
+	if err != nil {
+		http.Error(w, "failed to start login", http.StatusBadGateway)
+		return
+	}
+
+	// A stale or tampered cookie only yields a fresh session, which is what we want.
+	session, _ := h.store.New(r, oauthSessionName)
+	session.Options.MaxAge = oauthSessionTTL
+	session.Values["provider"] = provider.Name()
+	session.Values["state"] = state
+	session.Values["auth"] = authSession.Marshal()
+	session.Values["redirect_uri"] = redirectURI
+	session.Values["code_challenge"] = challenge
+	if err := session.Save(r, w); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to save oauth session", slog.Any("error", err))
+		http.Error(w, "failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles GET and POST /auth/{provider}/callback. Apple posts the callback as
+// a form; other providers redirect with query parameters.
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	session, err := h.store.Get(r, oauthSessionName)
+	if err != nil || session.IsNew {
+		http.Error(w, "login session expired", http.StatusBadRequest)
+		return
+	}
+	values := make(map[string]string, len(session.Values))
+	for k, v := range session.Values {
+		if key, ok := k.(string); ok {
+			values[key], _ = v.(string)
+		}
+	}
+	// The login state is single use.
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to clear oauth session", slog.Any("error", err))
+	}
+	if values["provider"] != provider.Name() || values["redirect_uri"] == "" {
+		http.Error(w, "login session expired", http.StatusBadRequest)
+		return
+	}
+	redirectURI := values["redirect_uri"]
+
+	params := r.URL.Query()
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid callback", http.StatusBadRequest)
+			return
+		}
+		params = r.PostForm
+	}
+	if subtle.ConstantTimeCompare([]byte(params.Get("state")), []byte(values["state"])) != 1 {
+		http.Error(w, "state mismatch", http.StatusBadRequest)
+		return
+	}
+	if params.Get("error") != "" {
+		h.redirectError(w, r, redirectURI, "access_denied")
+		return
+	}
+
+	authSession, err := provider.UnmarshalSession(values["auth"])
+	if err != nil {
+		h.redirectError(w, r, redirectURI, "server_error")
+		return
+	}
+	if _, err := authSession.Authorize(provider, params); err != nil {
+		h.logger.WarnContext(r.Context(), "oauth code exchange failed", slog.String("provider", provider.Name()), slog.Any("error", err))
+		h.redirectError(w, r, redirectURI, "access_denied")
+		return
+	}
+	gothUser, err := provider.FetchUser(authSession)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "failed to fetch oauth user", slog.String("provider", provider.Name()), slog.Any("error", err))
+		h.redirectError(w, r, redirectURI, "server_error")
+		return
+	}
+
+	user, err := h.service.LoginWithOAuth(r.Context(), service.NewOAuthProfile(gothUser, authSession))
+	switch {
+	case errors.Is(err, service.ErrOAuthEmailUnverified):
+		h.redirectError(w, r, redirectURI, "email_unverified")
+		return
+	case errors.Is(err, service.ErrAccountInactive):
+		h.redirectError(w, r, redirectURI, "account_disabled")
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "oauth login failed", slog.String("provider", provider.Name()), slog.Any("error", err))
+		h.redirectError(w, r, redirectURI, "server_error")
+		return
+	}
+
+	code, err := h.service.CreateOAuthLoginCode(r.Context(), user.ID, values["code_challenge"])
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to create oauth login code", slog.Any("error", err))
+		h.redirectError(w, r, redirectURI, "server_error")
+		return
+	}
+	h.redirect(w, r, redirectURI, url.Values{"code": {code}})
+}
+
+type exchangeRequest struct {
+	Code         string `json:"code"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type exchangeResponse struct {
+	*service.TokenPair
+	UserID string `json:"user_id"`
+}
+
+// WithTrustedProxies makes Exchange record the client IP of the new session from the
+// forwarding headers set by proxies.
+func (h *OAuthHandler) WithTrustedProxies(proxies interceptors.TrustedProxies) *OAuthHandler {
+	h.trustedProxies = proxies
+	return h
+}
+
+// Exchange handles POST /auth/exchange, redeeming a one-time login code.
+func (h *OAuthHandler) Exchange(w http.ResponseWriter, r *http.Request) {
+	var req exchangeRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxAccountRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.ExchangeOAuthLoginCode(r.Context(), req.Code, req.CodeVerifier, service.SessionMetadata{
+		UserAgent: r.UserAgent(),
+		ClientIP:  interceptors.RequestClientIP(r, h.trustedProxies),
+	})
+	switch {
+	case errors.Is(err, common.ErrInvalidToken):
+		http.Error(w, "invalid or expired code", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrAccountInactive):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to exchange oauth login code", slog.Any("error", err))
+		http.Error(w, "failed to exchange code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(exchangeResponse{TokenPair: result.Tokens, UserID: result.User.ID.String()}); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode tokens", slog.Any("error", err))
+	}
+}
+
+func (h *OAuthHandler) redirectError(w http.ResponseWriter, r *http.Request, redirectURI, code string) {
+	h.redirect(w, r, redirectURI, url.Values{"error": {code}})
+}
+
+func (h *OAuthHandler) redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusInternalServerError)
+		return
+	}
+	query := target.Query()
+	for k, v := range params {
+		query[k] = v
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
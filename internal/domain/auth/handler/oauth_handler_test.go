@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/openidConnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	testRedirectURI  = "loci://oauth"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r-wW1gFWFOEjXk"
+)
+
+// fakeOIDCProvider is a minimal OpenID Connect provider: discovery, a token endpoint
+// that answers any code with an ID token for its configured user, and no userinfo.
+type fakeOIDCProvider struct {
+	server        *httptest.Server
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	f := &fakeOIDCProvider{subject: "fake-subject", email: "oauth@example.com", emailVerified: true}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.server.URL,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "provider-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     f.idToken(),
+		})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// idToken returns an unsigned JWT; goth reads the ID token claims without verifying
+// the signature because they come straight from the token endpoint.
+func (f *fakeOIDCProvider) idToken() string {
+	claims, _ := json.Marshal(map[string]any{
+		"iss":            f.server.URL,
+		"aud":            "client-id",
+		"sub":            f.subject,
+		"email":          f.email,
+		"email_verified": f.emailVerified,
+		"name":           "OAuth User",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"none"}`)) + "." + enc(claims) + ".sig"
+}
+
+type oauthTestEnv struct {
+	mux     *http.ServeMux
+	handler *OAuthHandler
+	repo    *servicetest.MockAuthRepo
+	oidc    *fakeOIDCProvider
+}
+
+func newOAuthTestEnv(t *testing.T) *oauthTestEnv {
+	t.Helper()
+	oidc := newFakeOIDCProvider(t)
+	provider, err := openidConnect.New("client-id", "client-secret", "http://api.test/auth/fake/callback",
+		oidc.server.URL+"/.well-known/openid-configuration", "openid", "email")
+	if err != nil {
+		t.Fatalf("new oidc provider: %v", err)
+	}
+	provider.SetName("fake")
+
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	store := sessions.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	h := NewOAuthHandler(svc, []goth.Provider{provider}, store, []string{testRedirectURI},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/{provider}", h.Begin)
+	mux.HandleFunc("GET /auth/{provider}/callback", h.Callback)
+	mux.HandleFunc("POST /auth/{provider}/callback", h.Callback)
+	mux.HandleFunc("POST /auth/exchange", h.Exchange)
+	return &oauthTestEnv{mux: mux, handler: h, repo: repo, oidc: oidc}
+}
+
+// login runs the browser part of the flow and returns the app redirect.
+func (e *oauthTestEnv) login(t *testing.T) *url.URL {
+	t.Helper()
+	begin := httptest.NewRecorder()
+	e.mux.ServeHTTP(begin, httptest.NewRequest(http.MethodGet, "/auth/fake?"+url.Values{
+		"redirect_uri":          {testRedirectURI},
+		"code_challenge":        {service.PKCEChallenge(testCodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}.Encode(), nil))
+	if begin.Code != http.StatusFound {
+		t.Fatalf("begin: expected 302, got %d: %s", begin.Code, begin.Body)
+	}
+	authURL, _ := url.Parse(begin.Header().Get("Location"))
+	if !strings.HasPrefix(authURL.String(), e.oidc.server.URL+"/authorize") {
+		t.Fatalf("expected redirect to the provider, got %s", authURL)
+	}
+
+	callback := httptest.NewRequest(http.MethodGet, "/auth/fake/callback?"+url.Values{
+		"code":  {"provider-code"},
+		"state": {authURL.Query().Get("state")},
+	}.Encode(), nil)
+	for _, c := range begin.Result().Cookies() {
+		callback.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	e.mux.ServeHTTP(rec, callback)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("callback: expected 302, got %d: %s", rec.Code, rec.Body)
+	}
+	location, _ := url.Parse(rec.Header().Get("Location"))
+	if !strings.HasPrefix(location.String(), testRedirectURI) {
+		t.Fatalf("expected redirect to the app, got %s", location)
+	}
+	return location
+}
+
+func (e *oauthTestEnv) exchange(code, verifier string) *httptest.ResponseRecorder {
+	return e.exchangeFrom(code, verifier, "")
+}
+
+// exchangeFrom redeems code through a proxy that forwards for forwardedFor, if set.
+func (e *oauthTestEnv) exchangeFrom(code, verifier, forwardedFor string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"code": code, "code_verifier": verifier})
+	req := httptest.NewRequest(http.MethodPost, "/auth/exchange", strings.NewReader(string(body)))
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	e.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOAuthHandler_CreatesUserAndExchangesCode(t *testing.T) {
+	env := newOAuthTestEnv(t)
+
+	code := env.login(t).Query().Get("code")
+	if code == "" {
+		t.Fatalf("expected a one-time code")
+	}
+
+	if rec := env.exchange(code, "wrong-verifier-wrong-verifier-wrong-verifier"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a wrong verifier to be rejected, got %d", rec.Code)
+	}
+	// A failed attempt burns the code.
+	if rec := env.exchange(code, testCodeVerifier); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected the code to be single use, got %d", rec.Code)
+	}
+
+	code = env.login(t).Query().Get("code")
+	rec := env.exchange(code, testCodeVerifier)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("exchange: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		UserID       string `json:"user_id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&tokens); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("expected a token pair, got %+v", tokens)
+	}
+
+	user, ok := env.repo.Users["oauth@example.com"]
+	if !ok || user.ID.String() != tokens.UserID {
+		t.Fatalf("expected a user for the verified email, got %+v", env.repo.Users)
+	}
+	if user.EmailVerifiedAt == nil || user.DisplayName != "OAuth User" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	if env.repo.Identities["fake/fake-subject"] != user.ID {
+		t.Fatalf("provider identity not stored")
+	}
+	if len(env.repo.Users) != 1 {
+		t.Fatalf("logging in twice must not create a second user")
+	}
+}
+
+func TestOAuthHandler_ExchangeRecordsForwardedClientIP(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	// httptest requests come from 192.0.2.1.
+	proxies, err := interceptors.ParseTrustedProxies([]string{"192.0.2.0/24"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	env.handler.WithTrustedProxies(proxies)
+
+	code := env.login(t).Query().Get("code")
+	if rec := env.exchangeFrom(code, testCodeVerifier, "203.0.113.7"); rec.Code != http.StatusOK {
+		t.Fatalf("exchange: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(env.repo.Sessions) != 1 {
+		t.Fatalf("expected one session, got %d", len(env.repo.Sessions))
+	}
+	for _, session := range env.repo.Sessions {
+		if session.ClientIP == nil || *session.ClientIP != "203.0.113.7" {
+			t.Fatalf("expected the forwarded client IP stored, got %v", session.ClientIP)
+		}
+	}
+}
+
+func TestOAuthHandler_LinksExistingUserByVerifiedEmail(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	existing := servicetest.AddUser(env.repo, t, "oauth@example.com", true, "hashed")
+
+	env.login(t)
+	if env.repo.Identities["fake/fake-subject"] != existing.ID {
+		t.Fatalf("expected the identity to be linked to the existing user")
+	}
+}
+
+func TestOAuthHandler_RejectsUnverifiedEmail(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	servicetest.AddUser(env.repo, t, "oauth@example.com", true, "hashed")
+	env.oidc.emailVerified = false
+
+	location := env.login(t)
+	if got := location.Query().Get("error"); got != "email_unverified" {
+		t.Fatalf("expected email_unverified, got %q", got)
+	}
+	if len(env.repo.Identities) != 0 {
+		t.Fatalf("an unverified email must not be linked")
+	}
+}
+
+func TestOAuthHandler_ValidatesBegin(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	challenge := service.PKCEChallenge(testCodeVerifier)
+
+	cases := map[string]struct {
+		path string
+		want int
+	}{
+		"unknown provider":    {"/auth/github?redirect_uri=loci://oauth&code_challenge_method=S256&code_challenge=" + challenge, http.StatusNotFound},
+		"unlisted redirect":   {"/auth/fake?redirect_uri=https://evil.test&code_challenge_method=S256&code_challenge=" + challenge, http.StatusBadRequest},
+		"missing challenge":   {"/auth/fake?redirect_uri=loci://oauth", http.StatusBadRequest},
+		"plain pkce rejected": {"/auth/fake?redirect_uri=loci://oauth&code_challenge_method=plain&code_challenge=" + challenge, http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			env.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rec.Code)
+			}
+		})
+	}
+
+	// A callback without the login cookie is rejected.
+	rec := httptest.NewRecorder()
+	env.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/fake/callback?code=provider-code&state=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a login session, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// SessionHandler lets users see and sign out their devices. The AuthService proto has
+// no session procedures yet, so these are plain HTTP endpoints behind
+// AuthInterceptor.HTTPMiddleware.
+type SessionHandler struct {
+	service *service.AuthService
+	logger  *slog.Logger
+}
+
+// NewSessionHandler constructs a new session handler.
+func NewSessionHandler(svc *service.AuthService, logger *slog.Logger) *SessionHandler {
+	return &SessionHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// Session is the JSON view of an active UserSession.
+type Session struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// ListSessions handles GET /v1/auth/sessions.
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.ListSessions(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list sessions", slog.String("user_id", userID.String()), slog.Any("error", err))
+		http.Error(w, "failed to list sessions", http.StatusInternalServerError)
+		return
+	}
+
+	var currentJTI string
+	if claims, err := interceptors.GetClaimsFromContext(r.Context()); err == nil {
+		currentJTI = claims.ID
+	}
+
+	out := struct {
+		Sessions []Session `json:"sessions"`
+	}{Sessions: make([]Session, 0, len(sessions))}
+	for _, s := range sessions {
+		session := Session{
+			ID:        s.ID.String(),
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+			Current:   currentJTI != "" && s.AccessTokenID != nil && *s.AccessTokenID == currentJTI,
+		}
+		if s.UserAgent != nil {
+			session.UserAgent = *s.UserAgent
+		}
+		if s.ClientIP != nil {
+			session.ClientIP = *s.ClientIP
+		}
+		out.Sessions = append(out.Sessions, session)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode sessions", slog.Any("error", err))
+	}
+}
+
+// RevokeSession handles DELETE /v1/auth/sessions/{id}.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, common.ErrSessionNotFound) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to revoke session", slog.String("session_id", sessionID.String()), slog.Any("error", err))
+		http.Error(w, "failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions handles DELETE /v1/auth/sessions, signing the user out everywhere
+// including the calling device.
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeAllSessions(r.Context(), userID); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to revoke sessions", slog.String("user_id", userID.String()), slog.Any("error", err))
+		http.Error(w, "failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SessionHandler) userID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr, ok := interceptors.GetUserIDFromContext(r.Context())
+	if !ok || userIDStr == "" {
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
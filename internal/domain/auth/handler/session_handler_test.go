@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
+)
+
+func TestSessionHandler_ListAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	h := NewSessionHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	user, err := repo.CreateUser(ctx, "devices@example.com", "devices", "hashed", "Devices")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	current, _ := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID: user.ID, HashedRefreshToken: "current", AccessTokenID: "current-jti",
+		AccessExpiresAt: time.Now().Add(time.Minute), UserAgent: "laptop", ClientIP: "10.0.0.1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	other, _ := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID: user.ID, HashedRefreshToken: "other", UserAgent: "phone", ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, _ := jwtkeys.NewKeySet(hmacKey)
+	token, err := keys.Sign(&interceptors.Claims{
+		UserID:   user.ID.String(),
+		TokenUse: jwtkeys.TokenUseAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "current-jti",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	auth := interceptors.NewAuthInterceptor(keys)
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/auth/sessions", auth.HTTPMiddleware(http.HandlerFunc(h.ListSessions)))
+	mux.Handle("DELETE /v1/auth/sessions/{id}", auth.HTTPMiddleware(http.HandlerFunc(h.RevokeSession)))
+	authed := func(r *http.Request) *http.Request {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, authed(httptest.NewRequest(http.MethodGet, "/v1/auth/sessions", nil)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body struct {
+		Sessions []Session `json:"sessions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Sessions) != 2 {
+		t.Fatalf("expected two sessions, got %+v", body.Sessions)
+	}
+	for _, s := range body.Sessions {
+		if s.Current != (s.ID == current.ID.String()) {
+			t.Fatalf("only the calling session should be current: %+v", s)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, authed(httptest.NewRequest(http.MethodDelete, "/v1/auth/sessions/"+other.ID.String(), nil)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if _, ok := repo.Sessions["other"]; ok {
+		t.Fatalf("session should be revoked")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, authed(httptest.NewRequest(http.MethodDelete, "/v1/auth/sessions/"+other.ID.String(), nil)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an already revoked session, got %d", rec.Code)
+	}
+}
+
+func TestSessionHandler_RequiresUser(t *testing.T) {
+	svc, _, _, _ := servicetest.NewTestAuthService()
+	h := NewSessionHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	h.ListSessions(rec, httptest.NewRequest(http.MethodGet, "/v1/auth/sessions", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
 )
@@ -58,7 +61,7 @@ func (r *PostgresAuthRepository) GetUserByEmail(ctx context.Context, email strin
 	user := &User{}
 	query := `
 		SELECT id, email, username, hashed_password, display_name, avatar_url, role,
-		       is_active, email_verified_at, created_at, updated_at, last_login_at
+		       is_active, email_verified_at, created_at, updated_at, last_login_at, COALESCE(language, '')
 		FROM users
 		WHERE email = $1
 	`
@@ -66,7 +69,7 @@ func (r *PostgresAuthRepository) GetUserByEmail(ctx context.Context, email strin
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.Username, &user.HashedPassword, &user.DisplayName,
 		&user.AvatarURL, &user.Role, &user.IsActive, &user.EmailVerifiedAt,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.Language,
 	)
 
 	if err == sql.ErrNoRows {
@@ -84,7 +87,7 @@ func (r *PostgresAuthRepository) GetUserByID(ctx context.Context, userID uuid.UU
 	user := &User{}
 	query := `
 		SELECT id, email, username, hashed_password, display_name, avatar_url, role,
-		       is_active, email_verified_at, created_at, updated_at, last_login_at
+		       is_active, email_verified_at, created_at, updated_at, last_login_at, COALESCE(language, '')
 		FROM users
 		WHERE id = $1
 	`
@@ -92,7 +95,7 @@ func (r *PostgresAuthRepository) GetUserByID(ctx context.Context, userID uuid.UU
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&user.ID, &user.Email, &user.Username, &user.HashedPassword, &user.DisplayName,
 		&user.AvatarURL, &user.Role, &user.IsActive, &user.EmailVerifiedAt,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.Language,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -112,27 +115,60 @@ func (r *PostgresAuthRepository) UpdateLastLogin(ctx context.Context, userID uui
 	return err
 }
 
-// CreateUserSession creates a new refresh token session
-func (r *PostgresAuthRepository) CreateUserSession(ctx context.Context, userID uuid.UUID, hashedRefreshToken, userAgent, clientIP string, expiresAt time.Time) (*UserSession, error) {
+// sessionColumns is the column list scanned by scanSession.
+const sessionColumns = `id, user_id, family_id, hashed_refresh_token, access_token_id, access_expires_at,
+		       user_agent, client_ip, expires_at, created_at, rotated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (*UserSession, error) {
+	session := &UserSession{}
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.FamilyID, &session.HashedRefreshToken,
+		&session.AccessTokenID, &session.AccessExpiresAt, &session.UserAgent, &session.ClientIP,
+		&session.ExpiresAt, &session.CreatedAt, &session.RotatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CreateUserSession creates a new refresh token session. A zero FamilyID starts a new
+// family.
+func (r *PostgresAuthRepository) CreateUserSession(ctx context.Context, params CreateSessionParams) (*UserSession, error) {
+	familyID := params.FamilyID
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+	}
 	session := &UserSession{
 		ID:                 uuid.New(),
-		UserID:             userID,
-		HashedRefreshToken: hashedRefreshToken,
-		UserAgent:          &userAgent,
-		ClientIP:           &clientIP,
-		ExpiresAt:          expiresAt,
+		UserID:             params.UserID,
+		FamilyID:           familyID,
+		HashedRefreshToken: params.HashedRefreshToken,
+		UserAgent:          &params.UserAgent,
+		ClientIP:           &params.ClientIP,
+		ExpiresAt:          params.ExpiresAt,
 		CreatedAt:          time.Now(),
 	}
+	if params.AccessTokenID != "" {
+		session.AccessTokenID = &params.AccessTokenID
+		session.AccessExpiresAt = &params.AccessExpiresAt
+	}
 
 	query := `
-		INSERT INTO user_sessions (id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO user_sessions (id, user_id, family_id, hashed_refresh_token, access_token_id, access_expires_at,
+		                           user_agent, client_ip, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
 	`
 
 	err := r.db.QueryRowContext(
 		ctx, query,
-		session.ID, session.UserID, session.HashedRefreshToken,
+		session.ID, session.UserID, session.FamilyID, session.HashedRefreshToken,
+		session.AccessTokenID, session.AccessExpiresAt,
 		session.UserAgent, session.ClientIP, session.ExpiresAt, session.CreatedAt,
 	).Scan(&session.ID, &session.CreatedAt)
 	if err != nil {
@@ -142,21 +178,36 @@ func (r *PostgresAuthRepository) CreateUserSession(ctx context.Context, userID u
 	return session, nil
 }
 
-// GetUserSessionByToken retrieves a session by hashed refresh token
+// GetUserSessionByToken retrieves an unexpired session by hashed refresh token,
+// including sessions that were already rotated.
 func (r *PostgresAuthRepository) GetUserSessionByToken(ctx context.Context, hashedToken string) (*UserSession, error) {
-	session := &UserSession{}
 	query := `
-		SELECT id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at
+		SELECT ` + sessionColumns + `
 		FROM user_sessions
 		WHERE hashed_refresh_token = $1 AND expires_at > $2
 	`
 
-	err := r.db.QueryRowContext(ctx, query, hashedToken, time.Now()).Scan(
-		&session.ID, &session.UserID, &session.HashedRefreshToken,
-		&session.UserAgent, &session.ClientIP, &session.ExpiresAt, &session.CreatedAt,
-	)
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, hashedToken, time.Now()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, common.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	if err == sql.ErrNoRows {
+	return session, nil
+}
+
+// GetUserSessionByID retrieves an unexpired session by ID
+func (r *PostgresAuthRepository) GetUserSessionByID(ctx context.Context, sessionID uuid.UUID) (*UserSession, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE id = $1 AND expires_at > $2
+	`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, sessionID, time.Now()))
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, common.ErrSessionNotFound
 	}
 	if err != nil {
@@ -166,6 +217,47 @@ func (r *PostgresAuthRepository) GetUserSessionByToken(ctx context.Context, hash
 	return session, nil
 }
 
+// ListActiveUserSessions lists the user's current, unrotated sessions, newest first
+func (r *PostgresAuthRepository) ListActiveUserSessions(ctx context.Context, userID uuid.UUID) ([]UserSession, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND rotated_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []UserSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// MarkUserSessionRotated marks a session's refresh token as used. It reports false
+// when the session was already rotated, i.e. the token was presented twice.
+func (r *PostgresAuthRepository) MarkUserSessionRotated(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	query := `UPDATE user_sessions SET rotated_at = $1 WHERE id = $2 AND rotated_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, time.Now(), sessionID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
 // DeleteUserSession deletes a session
 func (r *PostgresAuthRepository) DeleteUserSession(ctx context.Context, hashedToken string) error {
 	query := `DELETE FROM user_sessions WHERE hashed_refresh_token = $1`
@@ -173,10 +265,69 @@ func (r *PostgresAuthRepository) DeleteUserSession(ctx context.Context, hashedTo
 	return err
 }
 
-// DeleteAllUserSessions deletes all sessions for a user
-func (r *PostgresAuthRepository) DeleteAllUserSessions(ctx context.Context, userID uuid.UUID) error {
-	query := `DELETE FROM user_sessions WHERE user_id = $1`
-	_, err := r.db.ExecContext(ctx, query, userID)
+// RevokeSessionFamily deletes every session of a family and revokes their unexpired
+// access tokens
+func (r *PostgresAuthRepository) RevokeSessionFamily(ctx context.Context, familyID uuid.UUID) ([]RevokedToken, error) {
+	return r.revokeSessions(ctx, `family_id = $1`, familyID)
+}
+
+// RevokeAllUserSessions deletes all sessions for a user and revokes their unexpired
+// access tokens
+func (r *PostgresAuthRepository) RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) ([]RevokedToken, error) {
+	return r.revokeSessions(ctx, `user_id = $1`, userID)
+}
+
+func (r *PostgresAuthRepository) revokeSessions(ctx context.Context, where string, arg any) ([]RevokedToken, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM user_sessions
+			WHERE ` + where + `
+			RETURNING user_id, access_token_id, access_expires_at
+		)
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+		SELECT access_token_id, user_id, access_expires_at
+		FROM deleted
+		WHERE access_token_id IS NOT NULL AND access_expires_at > NOW()
+		ON CONFLICT (jti) DO NOTHING
+		RETURNING jti, user_id, expires_at, revoked_at
+	`
+	return r.queryRevokedTokens(ctx, query, arg)
+}
+
+// ListRevokedTokens lists unexpired revocations made after since
+func (r *PostgresAuthRepository) ListRevokedTokens(ctx context.Context, since time.Time) ([]RevokedToken, error) {
+	query := `
+		SELECT jti, user_id, expires_at, revoked_at
+		FROM revoked_access_tokens
+		WHERE revoked_at > $1 AND expires_at > NOW()
+	`
+	return r.queryRevokedTokens(ctx, query, since)
+}
+
+func (r *PostgresAuthRepository) queryRevokedTokens(ctx context.Context, query string, args ...any) ([]RevokedToken, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []RevokedToken
+	for rows.Next() {
+		var t RevokedToken
+		if err := rows.Scan(&t.JTI, &t.UserID, &t.ExpiresAt, &t.RevokedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteExpiredRevocations sweeps revocations and sessions that have expired
+func (r *PostgresAuthRepository) DeleteExpiredRevocations(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM revoked_access_tokens WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE expires_at <= NOW()`)
 	return err
 }
 
@@ -191,17 +342,28 @@ func (r *PostgresAuthRepository) CreateUserToken(ctx context.Context, userID uui
 	return err
 }
 
+// CreateEmailChangeToken creates a token confirming a change to newEmail
+func (r *PostgresAuthRepository) CreateEmailChangeToken(ctx context.Context, userID uuid.UUID, tokenHash, newEmail string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO user_tokens (token_hash, user_id, type, new_email, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, tokenHash, userID, TokenTypeEmailChange, newEmail, expiresAt, time.Now())
+	return err
+}
+
 // GetUserTokenByHash retrieves a token by hash
 func (r *PostgresAuthRepository) GetUserTokenByHash(ctx context.Context, tokenHash, tokenType string) (*UserToken, error) {
 	token := &UserToken{}
 	query := `
-		SELECT token_hash, user_id, type, expires_at, created_at
+		SELECT token_hash, user_id, type, new_email, expires_at, created_at
 		FROM user_tokens
 		WHERE token_hash = $1 AND type = $2 AND expires_at > $3
 	`
 
 	err := r.db.QueryRowContext(ctx, query, tokenHash, tokenType, time.Now()).Scan(
-		&token.TokenHash, &token.UserID, &token.Type, &token.ExpiresAt, &token.CreatedAt,
+		&token.TokenHash, &token.UserID, &token.Type, &token.NewEmail, &token.ExpiresAt, &token.CreatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -221,6 +383,13 @@ func (r *PostgresAuthRepository) DeleteUserToken(ctx context.Context, tokenHash
 	return err
 }
 
+// DeleteUserTokensByType deletes all of a user's tokens of one type
+func (r *PostgresAuthRepository) DeleteUserTokensByType(ctx context.Context, userID uuid.UUID, tokenType string) error {
+	query := `DELETE FROM user_tokens WHERE user_id = $1 AND type = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, tokenType)
+	return err
+}
+
 // VerifyEmail marks a user's email as verified
 func (r *PostgresAuthRepository) VerifyEmail(ctx context.Context, userID uuid.UUID) error {
 	query := `UPDATE users SET email_verified_at = $1 WHERE id = $2`
@@ -228,6 +397,24 @@ func (r *PostgresAuthRepository) VerifyEmail(ctx context.Context, userID uuid.UU
 	return err
 }
 
+// UpdateEmail changes a user's email. The caller has proven ownership of the new
+// address, so it is marked verified at the same time.
+func (r *PostgresAuthRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `UPDATE users SET email = $1, email_verified_at = $2, updated_at = $2 WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, email, time.Now(), userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation on email
+			return common.ErrUserAlreadyExists
+		}
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return common.ErrUserNotFound
+	}
+	return nil
+}
+
 // UpdatePassword updates a user's password
 func (r *PostgresAuthRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
 	query := `UPDATE users SET hashed_password = $1, updated_at = $2 WHERE id = $3`
@@ -257,7 +444,7 @@ func (r *PostgresAuthRepository) GetUserByOAuthIdentity(ctx context.Context, pro
 	user := &User{}
 	query := `
 		SELECT u.id, u.email, u.username, u.hashed_password, u.display_name, u.avatar_url, u.role,
-		       u.is_active, u.email_verified_at, u.created_at, u.updated_at, u.last_login_at
+		       u.is_active, u.email_verified_at, u.created_at, u.updated_at, u.last_login_at, COALESCE(u.language, '')
 		FROM users u
 		INNER JOIN user_oauth_identities o ON u.id = o.user_id
 		WHERE o.provider_name = $1 AND o.provider_user_id = $2
@@ -266,7 +453,7 @@ func (r *PostgresAuthRepository) GetUserByOAuthIdentity(ctx context.Context, pro
 	err := r.db.QueryRowContext(ctx, query, providerName, providerUserID).Scan(
 		&user.ID, &user.Email, &user.Username, &user.HashedPassword, &user.DisplayName,
 		&user.AvatarURL, &user.Role, &user.IsActive, &user.EmailVerifiedAt,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.Language,
 	)
 
 	if err == sql.ErrNoRows {
@@ -278,3 +465,179 @@ func (r *PostgresAuthRepository) GetUserByOAuthIdentity(ctx context.Context, pro
 
 	return user, nil
 }
+
+// CreateOAuthLoginCode stores a one-time OAuth login code
+func (r *PostgresAuthRepository) CreateOAuthLoginCode(ctx context.Context, code OAuthLoginCode) error {
+	query := `
+		INSERT INTO oauth_login_codes (code_hash, user_id, code_challenge, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, code.CodeHash, code.UserID, code.CodeChallenge, code.ExpiresAt, time.Now())
+	return err
+}
+
+// ConsumeOAuthLoginCode deletes and returns an unexpired login code, so each code can
+// be redeemed at most once
+func (r *PostgresAuthRepository) ConsumeOAuthLoginCode(ctx context.Context, codeHash string) (*OAuthLoginCode, error) {
+	code := &OAuthLoginCode{}
+	query := `
+		DELETE FROM oauth_login_codes
+		WHERE code_hash = $1
+		RETURNING code_hash, user_id, code_challenge, expires_at, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, codeHash).Scan(
+		&code.CodeHash, &code.UserID, &code.CodeChallenge, &code.ExpiresAt, &code.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, common.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !code.ExpiresAt.After(time.Now()) {
+		return nil, common.ErrInvalidToken
+	}
+
+	return code, nil
+}
+
+// RecordLoginFailure counts a failed login for key. Failures before windowStart are
+// forgotten, so the count restarts after a quiet period
+func (r *PostgresAuthRepository) RecordLoginFailure(ctx context.Context, key string, windowStart time.Time) (*LoginAttempts, error) {
+	attempts := &LoginAttempts{}
+	query := `
+		INSERT INTO auth_login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN auth_login_attempts.last_failure_at < $3 THEN 1
+				ELSE auth_login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+
+	err := r.db.QueryRowContext(ctx, query, key, time.Now(), windowStart).Scan(
+		&attempts.Key, &attempts.Failures, &attempts.LastFailureAt, &attempts.LockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// LockLogin rejects logins for key until the given time
+func (r *PostgresAuthRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE auth_login_attempts SET locked_until = $1 WHERE key = $2`
+	_, err := r.db.ExecContext(ctx, query, until, key)
+	return err
+}
+
+// GetLoginLockout returns the latest lockout among keys, or nil when none is locked
+func (r *PostgresAuthRepository) GetLoginLockout(ctx context.Context, keys []string) (*time.Time, error) {
+	var lockedUntil sql.NullTime
+	query := `SELECT MAX(locked_until) FROM auth_login_attempts WHERE key = ANY($1) AND locked_until > $2`
+
+	if err := r.db.QueryRowContext(ctx, query, keys, time.Now()).Scan(&lockedUntil); err != nil {
+		return nil, err
+	}
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+// ClearLoginFailures forgets the failed logins of key
+func (r *PostgresAuthRepository) ClearLoginFailures(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM auth_login_attempts WHERE key = $1`, key)
+	return err
+}
+
+// CreateAuthAuditEvent appends an event to the auth audit trail
+func (r *PostgresAuthRepository) CreateAuthAuditEvent(ctx context.Context, event AuthAuditEvent) error {
+	query := `
+		INSERT INTO auth_audit_events (user_id, email, event_type, client_ip, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.UserID, event.Email, event.EventType, event.ClientIP, event.UserAgent, time.Now(),
+	)
+	return err
+}
+
+// ListAuthAuditEvents lists the audit events matching filter, newest first
+func (r *PostgresAuthRepository) ListAuthAuditEvents(ctx context.Context, filter AuthAuditFilter) ([]AuthAuditEvent, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		where = append(where, strings.Replace(clause, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+	if filter.UserID != nil {
+		add("user_id = ?", *filter.UserID)
+	}
+	if filter.Email != "" {
+		add("lower(email) = lower(?)", filter.Email)
+	}
+	if filter.EventType != "" {
+		add("event_type = ?", filter.EventType)
+	}
+	if filter.ClientIP != "" {
+		add("client_ip = ?", filter.ClientIP)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("created_at < ?", filter.Until)
+	}
+	if filter.BeforeID > 0 {
+		add("id < ?", filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, user_id, email, event_type, client_ip, user_agent, created_at
+		FROM auth_audit_events`
+	if len(where) > 0 {
+		query += `
+		WHERE ` + strings.Join(where, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += `
+		ORDER BY id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []AuthAuditEvent
+	for rows.Next() {
+		var e AuthAuditEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Email, &e.EventType, &e.ClientIP, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// HasLoginFromUserAgent reports whether the user has logged in before at all, and
+// whether any of those logins came from userAgent
+func (r *PostgresAuthRepository) HasLoginFromUserAgent(ctx context.Context, userID uuid.UUID, userAgent string) (hasLogins, known bool, err error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM auth_audit_events WHERE user_id = $1 AND event_type = $2),
+			EXISTS (SELECT 1 FROM auth_audit_events WHERE user_id = $1 AND event_type = $2 AND user_agent = $3)
+	`
+
+	err = r.db.QueryRowContext(ctx, query, userID, AuditEventLoginSucceeded, userAgent).Scan(&hasLogins, &known)
+	return hasLogins, known, err
+}
@@ -75,11 +75,15 @@ func TestPostgresAuthRepository_GetUserSessionByToken_NotFound(t *testing.T) {
 		sendRowDescription(
 			field("id", oidUUID),
 			field("user_id", oidUUID),
+			field("family_id", oidUUID),
 			field("hashed_refresh_token", oidText),
+			field("access_token_id", oidText),
+			field("access_expires_at", oidTimestamptz),
 			field("user_agent", oidText),
 			field("client_ip", oidText),
 			field("expires_at", oidTimestamptz),
 			field("created_at", oidTimestamptz),
+			field("rotated_at", oidTimestamptz),
 		),
 		sendCommandComplete("SELECT 0"),
 		sendReady(),
@@ -101,6 +105,7 @@ func TestPostgresAuthRepository_GetUserTokenByHash_NotFound(t *testing.T) {
 			field("token_hash", oidText),
 			field("user_id", oidUUID),
 			field("type", oidText),
+			field("new_email", oidText),
 			field("expires_at", oidTimestamptz),
 			field("created_at", oidTimestamptz),
 		),
@@ -132,6 +137,45 @@ func TestPostgresAuthRepository_VerifyEmail(t *testing.T) {
 	}
 }
 
+func TestPostgresAuthRepository_UpdateEmail_NotFound(t *testing.T) {
+	script := acceptScript(
+		expectQuery(updateEmailQuery),
+		sendCommandComplete("UPDATE 0"),
+		sendReady(),
+	)
+	db, cleanup := startMockDB(t, script)
+	defer cleanup()
+
+	repo := NewPostgresAuthRepository(db)
+	err := repo.UpdateEmail(context.Background(), uuid.New(), "new@example.com")
+	if !errors.Is(err, common.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestPostgresAuthRepository_ConsumeOAuthLoginCode_NotFound(t *testing.T) {
+	script := acceptScript(
+		expectQuery(consumeOAuthLoginCodeQuery),
+		sendRowDescription(
+			field("code_hash", oidText),
+			field("user_id", oidUUID),
+			field("code_challenge", oidText),
+			field("expires_at", oidTimestamptz),
+			field("created_at", oidTimestamptz),
+		),
+		sendCommandComplete("DELETE 0"),
+		sendReady(),
+	)
+	db, cleanup := startMockDB(t, script)
+	defer cleanup()
+
+	repo := NewPostgresAuthRepository(db)
+	_, err := repo.ConsumeOAuthLoginCode(context.Background(), "hash")
+	if !errors.Is(err, common.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
 func TestPostgresAuthRepository_CreateUserSession(t *testing.T) {
 	sessionID := uuid.New()
 	now := time.Now().UTC().Truncate(time.Microsecond)
@@ -146,7 +190,15 @@ func TestPostgresAuthRepository_CreateUserSession(t *testing.T) {
 	defer cleanup()
 
 	repo := NewPostgresAuthRepository(db)
-	session, err := repo.CreateUserSession(context.Background(), uuid.New(), "hash", "ua", "ip", now.Add(time.Hour))
+	session, err := repo.CreateUserSession(context.Background(), CreateSessionParams{
+		UserID:             uuid.New(),
+		HashedRefreshToken: "hash",
+		AccessTokenID:      "jti",
+		AccessExpiresAt:    now.Add(15 * time.Minute),
+		UserAgent:          "ua",
+		ClientIP:           "ip",
+		ExpiresAt:          now.Add(time.Hour),
+	})
 	if err != nil {
 		t.Fatalf("CreateUserSession: %v", err)
 	}
@@ -156,6 +208,9 @@ func TestPostgresAuthRepository_CreateUserSession(t *testing.T) {
 	if session.UserAgent == nil || *session.UserAgent != "ua" {
 		t.Fatalf("user agent not stored")
 	}
+	if session.FamilyID == uuid.Nil {
+		t.Fatalf("expected a new session family")
+	}
 }
 
 func TestPostgresAuthRepository_GetUserByOAuthIdentity_NotFound(t *testing.T) {
@@ -175,11 +230,60 @@ func TestPostgresAuthRepository_GetUserByOAuthIdentity_NotFound(t *testing.T) {
 	}
 }
 
+func TestPostgresAuthRepository_RecordLoginFailure(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	script := acceptScript(
+		expectQuery(recordLoginFailureQuery),
+		sendRowDescription(
+			field("key", oidText),
+			field("failures", oidInt4),
+			field("last_failure_at", oidTimestamptz),
+			field("locked_until", oidTimestamptz),
+		),
+		sendDataRow("account:repo@example.com", "3", formatTime(now), ""),
+		sendCommandComplete("INSERT 0 1"),
+		sendReady(),
+	)
+	db, cleanup := startMockDB(t, script)
+	defer cleanup()
+
+	repo := NewPostgresAuthRepository(db)
+	attempts, err := repo.RecordLoginFailure(context.Background(), "account:repo@example.com", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("RecordLoginFailure: %v", err)
+	}
+	if attempts.Failures != 3 || attempts.LockedUntil != nil {
+		t.Fatalf("unexpected attempts %+v", attempts)
+	}
+}
+
+func TestPostgresAuthRepository_GetLoginLockout_NotLocked(t *testing.T) {
+	script := acceptScript(
+		expectQuery(getLoginLockoutQuery),
+		sendRowDescription(field("max", oidTimestamptz)),
+		sendDataRow(""),
+		sendCommandComplete("SELECT 1"),
+		sendReady(),
+	)
+	db, cleanup := startMockDB(t, script)
+	defer cleanup()
+
+	repo := NewPostgresAuthRepository(db)
+	lockedUntil, err := repo.GetLoginLockout(context.Background(), []string{"account:repo@example.com", "ip:192.0.2.1"})
+	if err != nil {
+		t.Fatalf("GetLoginLockout: %v", err)
+	}
+	if lockedUntil != nil {
+		t.Fatalf("expected no lockout, got %s", lockedUntil)
+	}
+}
+
 // --- Helpers ---
 
 const (
 	oidUUID        = 2950
 	oidText        = 25
+	oidInt4        = 23
 	oidBool        = 16
 	oidTimestamptz = 1184
 )
@@ -192,29 +296,49 @@ var (
 	`
 	getUserByEmailQuery = `
 		SELECT id, email, username, hashed_password, display_name, avatar_url, role,
-		       is_active, email_verified_at, created_at, updated_at, last_login_at
+		       is_active, email_verified_at, created_at, updated_at, last_login_at, COALESCE(language, '')
 		FROM users
 		WHERE email = $1
 	`
 	getUserSessionQuery = `
-		SELECT id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at
+		SELECT id, user_id, family_id, hashed_refresh_token, access_token_id, access_expires_at,
+		       user_agent, client_ip, expires_at, created_at, rotated_at
 		FROM user_sessions
 		WHERE hashed_refresh_token = $1 AND expires_at > $2
 	`
 	getUserTokenQuery = `
-		SELECT token_hash, user_id, type, expires_at, created_at
+		SELECT token_hash, user_id, type, new_email, expires_at, created_at
 		FROM user_tokens
 		WHERE token_hash = $1 AND type = $2 AND expires_at > $3
 	`
-	verifyEmailQuery   = `UPDATE users SET email_verified_at = $1 WHERE id = $2`
+	verifyEmailQuery           = `UPDATE users SET email_verified_at = $1 WHERE id = $2`
+	updateEmailQuery           = `UPDATE users SET email = $1, email_verified_at = $2, updated_at = $2 WHERE id = $3`
+	consumeOAuthLoginCodeQuery = `
+		DELETE FROM oauth_login_codes
+		WHERE code_hash = $1
+		RETURNING code_hash, user_id, code_challenge, expires_at, created_at
+	`
 	createSessionQuery = `
-		INSERT INTO user_sessions (id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO user_sessions (id, user_id, family_id, hashed_refresh_token, access_token_id, access_expires_at,
+		                           user_agent, client_ip, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
 	`
-	getUserByOAuthQuery = `
+	recordLoginFailureQuery = `
+		INSERT INTO auth_login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN auth_login_attempts.last_failure_at < $3 THEN 1
+				ELSE auth_login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+	getLoginLockoutQuery = `SELECT MAX(locked_until) FROM auth_login_attempts WHERE key = ANY($1) AND locked_until > $2`
+	getUserByOAuthQuery  = `
 		SELECT u.id, u.email, u.username, u.hashed_password, u.display_name, u.avatar_url, u.role,
-		       u.is_active, u.email_verified_at, u.created_at, u.updated_at, u.last_login_at
+		       u.is_active, u.email_verified_at, u.created_at, u.updated_at, u.last_login_at, COALESCE(u.language, '')
 		FROM users u
 		INNER JOIN user_oauth_identities o ON u.id = o.user_id
 		WHERE o.provider_name = $1 AND o.provider_user_id = $2
@@ -291,6 +415,7 @@ func userFieldsRowDesc() []pgproto3.FieldDescription {
 		field("created_at", oidTimestamptz),
 		field("updated_at", oidTimestamptz),
 		field("last_login_at", oidTimestamptz),
+		field("language", oidText),
 	}
 }
 
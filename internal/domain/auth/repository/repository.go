@@ -20,22 +20,55 @@ type User struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	LastLoginAt     *time.Time
+	// Language is the user's preferred locale, e.g. "pt-BR"; empty when unset.
+	Language string
 }
 
 type UserSession struct {
 	ID                 uuid.UUID
 	UserID             uuid.UUID
+	FamilyID           uuid.UUID
 	HashedRefreshToken string
+	AccessTokenID      *string
+	AccessExpiresAt    *time.Time
 	UserAgent          *string
 	ClientIP           *string
 	ExpiresAt          time.Time
 	CreatedAt          time.Time
+	// RotatedAt is set once the refresh token has been exchanged for a new one.
+	RotatedAt *time.Time
 }
 
+// CreateSessionParams describes a refresh session. Sessions created by a refresh
+// carry over the FamilyID of the session they replace.
+type CreateSessionParams struct {
+	UserID             uuid.UUID
+	FamilyID           uuid.UUID
+	HashedRefreshToken string
+	AccessTokenID      string
+	AccessExpiresAt    time.Time
+	UserAgent          string
+	ClientIP           string
+	ExpiresAt          time.Time
+}
+
+// RevokedToken is an access token rejected until it expires.
+type RevokedToken struct {
+	JTI       string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// TokenTypeEmailChange is the user_tokens type of email change confirmations.
+const TokenTypeEmailChange = "email_change"
+
 type UserToken struct {
 	TokenHash string
 	UserID    uuid.UUID
 	Type      string
+	// NewEmail is the requested address of an email change token.
+	NewEmail  *string
 	ExpiresAt time.Time
 	CreatedAt time.Time
 }
@@ -50,24 +83,99 @@ type OAuthIdentity struct {
 	UpdatedAt            time.Time
 }
 
+// OAuthLoginCode is a one-time code redeemable for tokens by the holder of the PKCE
+// verifier for CodeChallenge.
+type OAuthLoginCode struct {
+	CodeHash      string
+	UserID        uuid.UUID
+	CodeChallenge string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
+// Auth audit event types.
+const (
+	AuditEventLoginSucceeded = "login_succeeded"
+	AuditEventLoginFailed    = "login_failed"
+	AuditEventLoginLocked    = "login_locked"
+	AuditEventTokenRefreshed = "token_refreshed"
+	AuditEventLogout         = "logout"
+	AuditEventPasswordChange = "password_changed"
+)
+
+// AuthAuditEvent is an entry of the auth audit trail. UserID is nil for failed logins
+// of unknown emails.
+type AuthAuditEvent struct {
+	ID        int64
+	UserID    *uuid.UUID
+	Email     *string
+	EventType string
+	ClientIP  *string
+	UserAgent *string
+	CreatedAt time.Time
+}
+
+// AuthAuditFilter narrows ListAuthAuditEvents. Zero fields match everything; results
+// are newest first and BeforeID pages past the last event of the previous page.
+type AuthAuditFilter struct {
+	UserID    *uuid.UUID
+	Email     string
+	EventType string
+	ClientIP  string
+	Since     time.Time
+	Until     time.Time
+	BeforeID  int64
+	Limit     int
+}
+
+// LoginAttempts counts the recent failed logins of a throttling key.
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
 type AuthRepository interface {
 	CreateUser(ctx context.Context, email, username, hashedPassword, displayName string) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error)
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
 
-	CreateUserSession(ctx context.Context, userID uuid.UUID, hashedRefreshToken, userAgent, clientIP string, expiresAt time.Time) (*UserSession, error)
+	CreateUserSession(ctx context.Context, params CreateSessionParams) (*UserSession, error)
 	GetUserSessionByToken(ctx context.Context, hashedToken string) (*UserSession, error)
+	GetUserSessionByID(ctx context.Context, sessionID uuid.UUID) (*UserSession, error)
+	ListActiveUserSessions(ctx context.Context, userID uuid.UUID) ([]UserSession, error)
+	MarkUserSessionRotated(ctx context.Context, sessionID uuid.UUID) (bool, error)
 	DeleteUserSession(ctx context.Context, hashedToken string) error
-	DeleteAllUserSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeSessionFamily(ctx context.Context, familyID uuid.UUID) ([]RevokedToken, error)
+	RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) ([]RevokedToken, error)
+
+	ListRevokedTokens(ctx context.Context, since time.Time) ([]RevokedToken, error)
+	DeleteExpiredRevocations(ctx context.Context) error
 
 	CreateUserToken(ctx context.Context, userID uuid.UUID, tokenHash, tokenType string, expiresAt time.Time) error
+	CreateEmailChangeToken(ctx context.Context, userID uuid.UUID, tokenHash, newEmail string, expiresAt time.Time) error
 	GetUserTokenByHash(ctx context.Context, tokenHash, tokenType string) (*UserToken, error)
 	DeleteUserToken(ctx context.Context, tokenHash string) error
+	DeleteUserTokensByType(ctx context.Context, userID uuid.UUID, tokenType string) error
 
 	VerifyEmail(ctx context.Context, userID uuid.UUID) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
 	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
 
 	CreateOrUpdateOAuthIdentity(ctx context.Context, providerName, providerUserID string, userID uuid.UUID, accessToken, refreshToken *string) error
 	GetUserByOAuthIdentity(ctx context.Context, providerName, providerUserID string) (*User, error)
+
+	CreateOAuthLoginCode(ctx context.Context, code OAuthLoginCode) error
+	ConsumeOAuthLoginCode(ctx context.Context, codeHash string) (*OAuthLoginCode, error)
+
+	RecordLoginFailure(ctx context.Context, key string, windowStart time.Time) (*LoginAttempts, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	GetLoginLockout(ctx context.Context, keys []string) (*time.Time, error)
+	ClearLoginFailures(ctx context.Context, key string) error
+
+	CreateAuthAuditEvent(ctx context.Context, event AuthAuditEvent) error
+	ListAuthAuditEvents(ctx context.Context, filter AuthAuditFilter) ([]AuthAuditEvent, error)
+	HasLoginFromUserAgent(ctx context.Context, userID uuid.UUID, userAgent string) (hasLogins, known bool, err error)
 }
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,8 +24,14 @@ const (
 	defaultSessionTTL = 30 * 24 * time.Hour
 )
 
-// ErrAccountInactive is returned when a user has been disabled.
-var ErrAccountInactive = errors.New("account is deactivated")
+var (
+	// ErrAccountInactive is returned when a user has been disabled.
+	ErrAccountInactive = errors.New("account is deactivated")
+	// ErrInvalidEmail is returned for an email address that cannot be parsed.
+	ErrInvalidEmail = errors.New("invalid email address")
+	// ErrEmailUnchanged is returned when an email change targets the current address.
+	ErrEmailUnchanged = errors.New("new email matches the current email")
+)
 
 // SessionMetadata captures client information useful for audit trails.
 type SessionMetadata struct {
@@ -66,6 +74,16 @@ type RefreshTokenParams struct {
 	Metadata     SessionMetadata
 }
 
+// ChangePasswordParams contains the data needed to change a password.
+type ChangePasswordParams struct {
+	UserID          string
+	CurrentPassword string
+	NewPassword     string
+	// CurrentTokenID is the jti of the caller's access token.
+	CurrentTokenID string
+	Metadata       SessionMetadata
+}
+
 // ResendVerificationResult communicates whether the user was already verified.
 type ResendVerificationResult struct {
 	AlreadyVerified bool
@@ -76,7 +94,9 @@ type AuthService struct {
 	repo         repository.AuthRepository
 	tokenManager TokenManager
 	emailService EmailSender
+	revocations  *RevocationList
 	sessionTTL   time.Duration
+	lockout      LockoutPolicy
 	logger       *slog.Logger
 }
 
@@ -85,6 +105,7 @@ func NewAuthService(
 	repo repository.AuthRepository,
 	tokenManager TokenManager,
 	emailService EmailSender,
+	revocations *RevocationList,
 	logger *slog.Logger,
 	sessionTTL time.Duration,
 ) *AuthService {
@@ -96,7 +117,9 @@ func NewAuthService(
 		repo:         repo,
 		tokenManager: tokenManager,
 		emailService: emailService,
+		revocations:  revocations,
 		sessionTTL:   sessionTTL,
+		lockout:      DefaultLockoutPolicy(),
 		logger:       logger,
 	}
 }
@@ -128,7 +151,7 @@ func (s *AuthService) RegisterUser(ctx context.Context, params RegisterParams) (
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	if err := s.createSession(ctx, user.ID, uuid.Nil, tokens, params.Metadata); err != nil {
 		return nil, err
 	}
 
@@ -143,18 +166,31 @@ func (s *AuthService) RegisterUser(ctx context.Context, params RegisterParams) (
 	}, nil
 }
 
-// Login authenticates a user against stored credentials.
+// Login authenticates a user against stored credentials. Failed attempts count towards
+// the lockout of the account and of the client IP, and every attempt is audited.
 func (s *AuthService) Login(ctx context.Context, params LoginParams) (*LoginResult, error) {
+	if err := s.checkLoginLockout(ctx, params.Email, params.Metadata); err != nil {
+		if errors.Is(err, ErrTooManyAttempts) {
+			s.audit(ctx, repository.AuditEventLoginLocked, uuid.Nil, params.Email, params.Metadata)
+		}
+		return nil, err
+	}
+
 	user, err := s.repo.GetUserByEmail(ctx, params.Email)
 	if err != nil {
+		if errors.Is(err, common.ErrUserNotFound) {
+			s.loginFailed(ctx, uuid.Nil, params)
+		}
 		return nil, err
 	}
 
 	if !user.IsActive {
+		s.audit(ctx, repository.AuditEventLoginFailed, user.ID, user.Email, params.Metadata)
 		return nil, ErrAccountInactive
 	}
 
 	if !ComparePassword(user.HashedPassword, params.Password) {
+		s.loginFailed(ctx, user.ID, params)
 		return nil, common.ErrInvalidCredentials
 	}
 
@@ -163,7 +199,7 @@ func (s *AuthService) Login(ctx context.Context, params LoginParams) (*LoginResu
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	if err := s.createSession(ctx, user.ID, uuid.Nil, tokens, params.Metadata); err != nil {
 		return nil, err
 	}
 
@@ -171,22 +207,41 @@ func (s *AuthService) Login(ctx context.Context, params LoginParams) (*LoginResu
 		s.logger.Warn("failed to update last login", "error", err)
 	}
 
+	s.clearAccountFailures(ctx, params.Email)
+	s.notifyNewDevice(ctx, user, params.Metadata)
+	s.audit(ctx, repository.AuditEventLoginSucceeded, user.ID, user.Email, params.Metadata)
+
 	return &LoginResult{
 		User:   user,
 		Tokens: tokens,
 	}, nil
 }
 
-// Logout removes the refresh token session.
-func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+// loginFailed records a failed login attempt and audits it, plus the lockout it caused.
+func (s *AuthService) loginFailed(ctx context.Context, userID uuid.UUID, params LoginParams) {
+	s.audit(ctx, repository.AuditEventLoginFailed, userID, params.Email, params.Metadata)
+	if s.recordLoginFailure(ctx, params.Email, params.Metadata) {
+		s.audit(ctx, repository.AuditEventLoginLocked, userID, params.Email, params.Metadata)
+	}
+}
+
+// Logout ends the session of the refresh token and revokes its access token.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string, meta SessionMetadata) error {
 	if refreshToken == "" {
 		return fmt.Errorf("refresh token required")
 	}
 
-	hashedToken := hashToken(refreshToken)
-	if err := s.repo.DeleteUserSession(ctx, hashedToken); err != nil && !errors.Is(err, common.ErrSessionNotFound) {
+	session, err := s.repo.GetUserSessionByToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, common.ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
+	if err := s.revoke(s.repo.RevokeSessionFamily(ctx, session.FamilyID)); err != nil {
+		return err
+	}
+	s.audit(ctx, repository.AuditEventLogout, session.UserID, "", meta)
 	return nil
 }
 
@@ -197,10 +252,13 @@ func (s *AuthService) RefreshTokens(ctx context.Context, params RefreshTokenPara
 		return nil, err
 	}
 
-	hashedToken := hashToken(params.RefreshToken)
-	if _, err := s.repo.GetUserSessionByToken(ctx, hashedToken); err != nil {
+	session, err := s.repo.GetUserSessionByToken(ctx, hashToken(params.RefreshToken))
+	if err != nil {
 		return nil, err
 	}
+	if session.RotatedAt != nil {
+		return nil, s.handleTokenReuse(ctx, session)
+	}
 
 	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
@@ -216,26 +274,42 @@ func (s *AuthService) RefreshTokens(ctx context.Context, params RefreshTokenPara
 		return nil, ErrAccountInactive
 	}
 
-	_ = s.repo.DeleteUserSession(ctx, hashedToken)
+	// Only one caller can rotate a session; a concurrent loser presented a used token.
+	rotated, err := s.repo.MarkUserSessionRotated(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.handleTokenReuse(ctx, session)
+	}
 
 	tokens, err := s.tokenManager.GenerateTokenPair(user.ID.String(), user.Email, user.Username, user.Role)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	if err := s.createSession(ctx, user.ID, session.FamilyID, tokens, params.Metadata); err != nil {
 		return nil, err
 	}
 
+	s.audit(ctx, repository.AuditEventTokenRefreshed, user.ID, user.Email, params.Metadata)
 	return tokens, nil
 }
 
-// ValidateAccessToken validates an access token and returns its claims.
+// ValidateAccessToken validates an access token and returns its claims. Tokens
+// revoked before they expire are rejected like the auth interceptor rejects them.
 func (s *AuthService) ValidateAccessToken(_ context.Context, accessToken string) (*Claims, error) {
 	if accessToken == "" {
 		return nil, fmt.Errorf("access token required")
 	}
-	return s.tokenManager.ValidateAccessToken(accessToken)
+	claims, err := s.tokenManager.ValidateAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if s.revocations != nil && s.revocations.IsRevoked(claims.ID) {
+		return nil, common.ErrInvalidToken
+	}
+	return claims, nil
 }
 
 // RequestPasswordReset kicks off the reset workflow.
@@ -262,11 +336,9 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) er
 	}
 
 	if s.emailService != nil {
-		go func(ctx context.Context, email, name, token string) {
-			if err := s.emailService.SendPasswordResetEmail(email, name, token); err != nil {
-				s.logger.WarnContext(ctx, "failed to send password reset email", slog.Any("error", err))
-			}
-		}(ctx, user.Email, user.DisplayName, resetToken)
+		if err := s.emailService.SendPasswordResetEmail(ctx, recipientOf(user), resetToken); err != nil {
+			s.logger.WarnContext(ctx, "failed to queue password reset email", slog.Any("error", err))
+		}
 	}
 
 	return nil
@@ -294,18 +366,22 @@ func (s *AuthService) ResetPassword(ctx context.Context, resetToken, newPassword
 	}
 
 	_ = s.repo.DeleteUserToken(ctx, hashedToken)
-	_ = s.repo.DeleteAllUserSessions(ctx, userToken.UserID)
+	if err := s.revoke(s.repo.RevokeAllUserSessions(ctx, userToken.UserID)); err != nil {
+		s.logger.WarnContext(ctx, "failed to revoke sessions after password reset", slog.Any("error", err))
+	}
 
+	s.audit(ctx, repository.AuditEventPasswordChange, userToken.UserID, "", SessionMetadata{})
 	return nil
 }
 
-// ChangePassword changes the password for an authenticated user.
-func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
-	if userID == "" {
+// ChangePassword changes the password for an authenticated user and signs out every
+// other session. The session of params.CurrentTokenID stays signed in.
+func (s *AuthService) ChangePassword(ctx context.Context, params ChangePasswordParams) error {
+	if params.UserID == "" {
 		return fmt.Errorf("user id required")
 	}
 
-	userUUID, err := uuid.Parse(userID)
+	userUUID, err := uuid.Parse(params.UserID)
 	if err != nil {
 		return err
 	}
@@ -315,15 +391,15 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPasswor
 		return err
 	}
 
-	if !ComparePassword(user.HashedPassword, currentPassword) {
+	if !ComparePassword(user.HashedPassword, params.CurrentPassword) {
 		return common.ErrInvalidCredentials
 	}
 
-	if err := ValidatePassword(newPassword); err != nil {
+	if err := ValidatePassword(params.NewPassword); err != nil {
 		return err
 	}
 
-	hashedPassword, err := HashPassword(newPassword)
+	hashedPassword, err := HashPassword(params.NewPassword)
 	if err != nil {
 		return err
 	}
@@ -332,10 +408,94 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPasswor
 		return err
 	}
 
-	_ = s.repo.DeleteAllUserSessions(ctx, userUUID)
+	if err := s.revokeOtherSessions(ctx, userUUID, params.CurrentTokenID); err != nil {
+		s.logger.WarnContext(ctx, "failed to revoke sessions after password change", slog.Any("error", err))
+	}
+	s.audit(ctx, repository.AuditEventPasswordChange, user.ID, user.Email, params.Metadata)
+	return nil
+}
+
+// RequestEmailChange starts moving the account to newEmail. A confirmation link is sent
+// to the new address and the current address is notified; the email only changes once
+// ConfirmEmailChange is called with the link's token.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID uuid.UUID, password, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return ErrInvalidEmail
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !ComparePassword(user.HashedPassword, password) {
+		return common.ErrInvalidCredentials
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+
+	if _, err := s.repo.GetUserByEmail(ctx, newEmail); err == nil {
+		return common.ErrUserAlreadyExists
+	} else if !errors.Is(err, common.ErrUserNotFound) {
+		return err
+	}
+
+	token, err := GenerateVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	// Only the latest request can be confirmed.
+	if err := s.repo.DeleteUserTokensByType(ctx, user.ID, repository.TokenTypeEmailChange); err != nil {
+		return err
+	}
+	if err := s.repo.CreateEmailChangeToken(ctx, user.ID, hashToken(token), newEmail, time.Now().Add(24*time.Hour)); err != nil {
+		return err
+	}
+
+	if s.emailService != nil {
+		to := recipientOf(user)
+		confirmTo := to
+		confirmTo.Email = newEmail
+		if err := s.emailService.SendEmailChangeConfirmation(ctx, confirmTo, token); err != nil {
+			s.logger.WarnContext(ctx, "failed to queue email change confirmation", slog.Any("error", err))
+		}
+		if err := s.emailService.SendEmailChangeNotice(ctx, to, newEmail); err != nil {
+			s.logger.WarnContext(ctx, "failed to queue email change notice", slog.Any("error", err))
+		}
+	}
+
 	return nil
 }
 
+// ConfirmEmailChange swaps the user's email for the address the token was sent to. The
+// new address is verified by the confirmation itself.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, fmt.Errorf("confirmation token required")
+	}
+
+	hashedToken := hashToken(token)
+	userToken, err := s.repo.GetUserTokenByHash(ctx, hashedToken, repository.TokenTypeEmailChange)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if userToken.NewEmail == nil {
+		return uuid.Nil, common.ErrInvalidToken
+	}
+
+	if err := s.repo.UpdateEmail(ctx, userToken.UserID, *userToken.NewEmail); err != nil {
+		return uuid.Nil, err
+	}
+
+	_ = s.repo.DeleteUserToken(ctx, hashedToken)
+
+	return userToken.UserID, nil
+}
+
 // VerifyEmail validates the verification token.
 func (s *AuthService) VerifyEmail(ctx context.Context, verificationToken string) (uuid.UUID, error) {
 	if verificationToken == "" {
@@ -356,11 +516,9 @@ func (s *AuthService) VerifyEmail(ctx context.Context, verificationToken string)
 
 	if s.emailService != nil {
 		if user, err := s.repo.GetUserByID(ctx, userToken.UserID); err == nil {
-			go func(ctx context.Context, email, name string) {
-				if err := s.emailService.SendWelcomeEmail(email, name); err != nil {
-					s.logger.WarnContext(ctx, "failed to send welcome email", slog.Any("error", err))
-				}
-			}(ctx, user.Email, user.DisplayName)
+			if err := s.emailService.SendWelcomeEmail(ctx, recipientOf(user)); err != nil {
+				s.logger.WarnContext(ctx, "failed to queue welcome email", slog.Any("error", err))
+			}
 		}
 	}
 
@@ -392,7 +550,87 @@ func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string)
 	return &ResendVerificationResult{}, nil
 }
 
-func (s *AuthService) createSession(ctx context.Context, userID uuid.UUID, refreshToken string, meta SessionMetadata) error {
+// ListSessions returns the user's active sessions, newest first.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]repository.UserSession, error) {
+	return s.repo.ListActiveUserSessions(ctx, userID)
+}
+
+// RevokeSession signs out one of the user's sessions, revoking its refresh and access
+// tokens.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.repo.GetUserSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Other users' sessions are reported as missing rather than forbidden.
+	if session.UserID != userID {
+		return common.ErrSessionNotFound
+	}
+	return s.revoke(s.repo.RevokeSessionFamily(ctx, session.FamilyID))
+}
+
+// RevokeAllSessions signs the user out everywhere.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.revoke(s.repo.RevokeAllUserSessions(ctx, userID))
+}
+
+// revokeOtherSessions signs the user out of every session except the one that issued
+// the access token currentTokenID. Without a matching session everything is revoked.
+func (s *AuthService) revokeOtherSessions(ctx context.Context, userID uuid.UUID, currentTokenID string) error {
+	sessions, err := s.repo.ListActiveUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	current := uuid.Nil
+	for _, session := range sessions {
+		if currentTokenID != "" && session.AccessTokenID != nil && *session.AccessTokenID == currentTokenID {
+			current = session.FamilyID
+			break
+		}
+	}
+	if current == uuid.Nil {
+		return s.revoke(s.repo.RevokeAllUserSessions(ctx, userID))
+	}
+
+	revoked := map[uuid.UUID]bool{current: true}
+	for _, session := range sessions {
+		if revoked[session.FamilyID] {
+			continue
+		}
+		revoked[session.FamilyID] = true
+		if err := s.revoke(s.repo.RevokeSessionFamily(ctx, session.FamilyID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleTokenReuse revokes the whole session family when a rotated refresh token is
+// presented again: either the legitimate client or an attacker holds a copy, and the
+// server cannot tell which.
+func (s *AuthService) handleTokenReuse(ctx context.Context, session *repository.UserSession) error {
+	s.logger.WarnContext(ctx, "refresh token reuse detected, revoking session family",
+		slog.String("user_id", session.UserID.String()),
+		slog.String("family_id", session.FamilyID.String()))
+	if err := s.revoke(s.repo.RevokeSessionFamily(ctx, session.FamilyID)); err != nil {
+		return err
+	}
+	return common.ErrTokenReused
+}
+
+// revoke applies revocations returned by the repository to the in-memory list.
+func (s *AuthService) revoke(tokens []repository.RevokedToken, err error) error {
+	if err != nil {
+		return err
+	}
+	if s.revocations != nil {
+		s.revocations.Add(tokens...)
+	}
+	return nil
+}
+
+func (s *AuthService) createSession(ctx context.Context, userID, familyID uuid.UUID, tokens *TokenPair, meta SessionMetadata) error {
 	userAgent := meta.UserAgent
 	if userAgent == "" {
 		userAgent = "unknown"
@@ -402,7 +640,16 @@ func (s *AuthService) createSession(ctx context.Context, userID uuid.UUID, refre
 		clientIP = "unknown"
 	}
 
-	_, err := s.repo.CreateUserSession(ctx, userID, hashToken(refreshToken), userAgent, clientIP, time.Now().Add(s.sessionTTL))
+	_, err := s.repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID:             userID,
+		FamilyID:           familyID,
+		HashedRefreshToken: hashToken(tokens.RefreshToken),
+		AccessTokenID:      tokens.AccessTokenID,
+		AccessExpiresAt:    tokens.ExpiresAt,
+		UserAgent:          userAgent,
+		ClientIP:           clientIP,
+		ExpiresAt:          time.Now().Add(s.sessionTTL),
+	})
 	return err
 }
 
@@ -416,12 +663,12 @@ func (s *AuthService) sendEmailVerification(ctx context.Context, user *repositor
 		return err
 	}
 
+	// A failure to queue is logged rather than returned: the account exists either way
+	// and the user can ask for the email again.
 	if s.emailService != nil {
-		go func(ctx context.Context, email, name, verificationToken string) {
-			if err := s.emailService.SendVerificationEmail(email, name, verificationToken); err != nil {
-				s.logger.WarnContext(ctx, "failed to send verification email", slog.Any("error", err))
-			}
-		}(ctx, user.Email, user.DisplayName, token)
+		if err := s.emailService.SendVerificationEmail(ctx, recipientOf(user), token); err != nil {
+			s.logger.WarnContext(ctx, "failed to queue verification email", slog.Any("error", err))
+		}
 	}
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -54,7 +55,9 @@ func TestAuthService_RegisterUser_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("user persisted not found: %v", err)
 	}
-	servicetest.WaitFor(t, func() bool { return email.VerificationSent() })
+	if !email.VerificationSent() {
+		t.Fatalf("expected the email to be queued")
+	}
 	if user.HashedPassword == "" {
 		t.Fatalf("expected hashed password to be stored")
 	}
@@ -173,7 +176,7 @@ func TestAuthService_Logout_RemovesSession(t *testing.T) {
 		ExpiresAt: time.Now().Add(time.Hour),
 	}
 
-	if err := svc.Logout(ctx, "refresh-token"); err != nil {
+	if err := svc.Logout(ctx, "refresh-token", service.SessionMetadata{}); err != nil {
 		t.Fatalf("Logout: %v", err)
 	}
 	if _, ok := repo.Sessions[hashed]; ok {
@@ -209,7 +212,11 @@ func TestAuthService_ChangePassword_Success(t *testing.T) {
 		ExpiresAt: time.Now().Add(time.Hour),
 	}
 
-	if err := svc.ChangePassword(ctx, user.ID.String(), current, "NewPass!2"); err != nil {
+	if err := svc.ChangePassword(ctx, service.ChangePasswordParams{
+		UserID:          user.ID.String(),
+		CurrentPassword: current,
+		NewPassword:     "NewPass!2",
+	}); err != nil {
 		t.Fatalf("ChangePassword: %v", err)
 	}
 	if repo.Users[user.Email].HashedPassword == hashed {
@@ -220,6 +227,85 @@ func TestAuthService_ChangePassword_Success(t *testing.T) {
 	}
 }
 
+func TestAuthService_ChangePassword_KeepsCurrentSession(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	current := "Str0ng!Pass"
+	user := servicetest.AddUser(repo, t, "keepsession@example.com", true, servicetest.MustHash(t, current))
+	currentJTI := "current-jti"
+	repo.Sessions["current"] = &repository.UserSession{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		FamilyID:      uuid.New(),
+		AccessTokenID: &currentJTI,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+	repo.Sessions["other"] = &repository.UserSession{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  uuid.New(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if err := svc.ChangePassword(ctx, service.ChangePasswordParams{
+		UserID:          user.ID.String(),
+		CurrentPassword: current,
+		NewPassword:     "NewPass!2",
+		CurrentTokenID:  currentJTI,
+	}); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if _, ok := repo.Sessions["current"]; !ok {
+		t.Fatalf("the calling session should stay signed in")
+	}
+	if _, ok := repo.Sessions["other"]; ok {
+		t.Fatalf("other sessions should be revoked")
+	}
+}
+
+func TestAuthService_EmailChange(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, email := servicetest.NewTestAuthService()
+	password := "Str0ng!Pass"
+	user := servicetest.AddUser(repo, t, "old@example.com", true, servicetest.MustHash(t, password))
+	servicetest.AddUser(repo, t, "taken@example.com", true, servicetest.MustHash(t, password))
+
+	if err := svc.RequestEmailChange(ctx, user.ID, "wrong", "new@example.com"); !errors.Is(err, common.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if err := svc.RequestEmailChange(ctx, user.ID, password, "not an email"); !errors.Is(err, service.ErrInvalidEmail) {
+		t.Fatalf("expected invalid email, got %v", err)
+	}
+	if err := svc.RequestEmailChange(ctx, user.ID, password, "taken@example.com"); !errors.Is(err, common.ErrUserAlreadyExists) {
+		t.Fatalf("expected email in use, got %v", err)
+	}
+
+	if err := svc.RequestEmailChange(ctx, user.ID, password, "new@example.com"); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	if email.EmailChangeToken() == "" || !email.EmailChangeNoticeSent() {
+		t.Fatalf("expected the email to be queued")
+	}
+	if _, ok := repo.Users["old@example.com"]; !ok {
+		t.Fatalf("email must not change before confirmation")
+	}
+
+	userID, err := svc.ConfirmEmailChange(ctx, email.EmailChangeToken())
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if userID != user.ID {
+		t.Fatalf("unexpected user id")
+	}
+	changed, ok := repo.Users["new@example.com"]
+	if !ok || changed.EmailVerifiedAt == nil {
+		t.Fatalf("expected verified new email, got %+v", changed)
+	}
+	if _, err := svc.ConfirmEmailChange(ctx, email.EmailChangeToken()); !errors.Is(err, common.ErrInvalidToken) {
+		t.Fatalf("expected the token to be single use, got %v", err)
+	}
+}
+
 func TestAuthService_ResetPassword_Success(t *testing.T) {
 	ctx := context.Background()
 	svc, repo, _, _ := servicetest.NewTestAuthService()
@@ -279,7 +365,9 @@ func TestAuthService_VerifyEmail_Success(t *testing.T) {
 	if _, ok := repo.Tokens[hash]; ok {
 		t.Fatalf("token should be deleted")
 	}
-	servicetest.WaitFor(t, func() bool { return email.WelcomeSent() })
+	if !email.WelcomeSent() {
+		t.Fatalf("expected the email to be queued")
+	}
 }
 
 func TestAuthService_ResendVerificationEmail(t *testing.T) {
@@ -294,7 +382,9 @@ func TestAuthService_ResendVerificationEmail(t *testing.T) {
 	if result == nil || result.AlreadyVerified {
 		t.Fatalf("expected resend to proceed")
 	}
-	servicetest.WaitFor(t, func() bool { return email.VerificationSent() })
+	if !email.VerificationSent() {
+		t.Fatalf("expected the email to be queued")
+	}
 	if len(repo.Tokens) == 0 {
 		t.Fatalf("verification token not stored")
 	}
@@ -328,6 +418,7 @@ func TestAuthService_RefreshTokens_Success(t *testing.T) {
 	session := &repository.UserSession{
 		ID:                 uuid.New(),
 		UserID:             user.ID,
+		FamilyID:           uuid.New(),
 		HashedRefreshToken: hashTestToken("refresh-token"),
 		ExpiresAt:          time.Now().Add(time.Hour),
 	}
@@ -356,12 +447,105 @@ func TestAuthService_RefreshTokens_Success(t *testing.T) {
 	if res.AccessToken != "access-new" {
 		t.Fatalf("unexpected access token %s", res.AccessToken)
 	}
-	if _, ok := repo.Sessions[hashTestToken("refresh-token")]; ok {
-		t.Fatalf("old session should be deleted")
+	if old := repo.Sessions[hashTestToken("refresh-token")]; old == nil || old.RotatedAt == nil {
+		t.Fatalf("old session should be kept as rotated")
 	}
-	if _, ok := repo.Sessions[hashTestToken("refresh-new")]; !ok {
+	newSession, ok := repo.Sessions[hashTestToken("refresh-new")]
+	if !ok {
 		t.Fatalf("new session should be created")
 	}
+	if newSession.FamilyID != session.FamilyID {
+		t.Fatalf("new session should stay in the old session's family")
+	}
+}
+
+func TestAuthService_RefreshTokens_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, tokens, _ := servicetest.NewTestAuthService()
+	user := servicetest.AddUser(repo, t, "reuse@example.com", true, "Hashed!Pass1")
+	tokens.RefreshFunc = func(_ string) (*service.Claims, error) {
+		return &service.Claims{UserID: user.ID.String()}, nil
+	}
+	generation := 0
+	tokens.GenerateFunc = func(_, _, _, _ string) (*service.TokenPair, error) {
+		generation++
+		return &service.TokenPair{
+			AccessToken:   fmt.Sprintf("access-%d", generation),
+			RefreshToken:  fmt.Sprintf("refresh-%d", generation),
+			ExpiresAt:     time.Now().Add(15 * time.Minute),
+			AccessTokenID: fmt.Sprintf("jti-%d", generation),
+		}, nil
+	}
+
+	if _, err := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID:             user.ID,
+		HashedRefreshToken: hashTestToken("refresh-0"),
+		AccessTokenID:      "jti-0",
+		AccessExpiresAt:    time.Now().Add(15 * time.Minute),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("CreateUserSession: %v", err)
+	}
+	// An unrelated session on another device must survive.
+	if _, err := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID:             user.ID,
+		HashedRefreshToken: hashTestToken("other-device"),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("CreateUserSession: %v", err)
+	}
+
+	if _, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: "refresh-0"}); err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	_, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: "refresh-0"})
+	if !errors.Is(err, common.ErrTokenReused) {
+		t.Fatalf("expected reuse to be detected, got %v", err)
+	}
+
+	if _, ok := repo.Sessions[hashTestToken("refresh-1")]; ok {
+		t.Fatalf("the session issued from the reused token should be revoked")
+	}
+	if _, ok := repo.Sessions[hashTestToken("other-device")]; !ok {
+		t.Fatalf("sessions outside the family should be kept")
+	}
+	for _, jti := range []string{"jti-0", "jti-1"} {
+		if _, ok := repo.Revoked[jti]; !ok {
+			t.Fatalf("expected access token %s to be revoked", jti)
+		}
+	}
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	user := servicetest.AddUser(repo, t, "sessions@example.com", true, "Hashed!Pass1")
+	other := servicetest.AddUser(repo, t, "other@example.com", true, "Hashed!Pass1")
+
+	mine, _ := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID: user.ID, HashedRefreshToken: "mine", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	theirs, _ := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+		UserID: other.ID, HashedRefreshToken: "theirs", ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	sessions, err := svc.ListSessions(ctx, user.ID)
+	if err != nil || len(sessions) != 1 || sessions[0].ID != mine.ID {
+		t.Fatalf("expected only the user's session, got %+v (%v)", sessions, err)
+	}
+
+	if err := svc.RevokeSession(ctx, user.ID, theirs.ID); !errors.Is(err, common.ErrSessionNotFound) {
+		t.Fatalf("expected another user's session to be hidden, got %v", err)
+	}
+	if err := svc.RevokeSession(ctx, user.ID, mine.ID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if _, ok := repo.Sessions["mine"]; ok {
+		t.Fatalf("session should be revoked")
+	}
+	if _, ok := repo.Sessions["theirs"]; !ok {
+		t.Fatalf("other user's session should be kept")
+	}
 }
 
 func TestAuthService_RequestPasswordReset(t *testing.T) {
@@ -380,7 +564,9 @@ func TestAuthService_RequestPasswordReset(t *testing.T) {
 	if err := svc.RequestPasswordReset(ctx, "jane@example.com"); err != nil {
 		t.Fatalf("RequestPasswordReset: %v", err)
 	}
-	servicetest.WaitFor(t, func() bool { return email.ResetSent() })
+	if !email.ResetSent() {
+		t.Fatalf("expected the email to be queued")
+	}
 	if len(repo.Tokens) == 0 {
 		t.Fatalf("expected token to be stored")
 	}
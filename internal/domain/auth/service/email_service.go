@@ -1,160 +1,92 @@
 package service
 
 import (
-	"fmt"
-	"net/smtp"
-	"os"
+	"context"
+	"net/url"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/pkg/email"
 )
 
-// EmailSender defines the behavior required for sending auth emails.
-type EmailSender interface {
-	SendVerificationEmail(toEmail, toName, token string) error
-	SendPasswordResetEmail(toEmail, toName, token string) error
-	SendWelcomeEmail(toEmail, toName string) error
+// Recipient addresses an auth email. Language selects the template locale and falls
+// back to English when empty.
+type Recipient struct {
+	Email    string
+	Name     string
+	Language string
 }
 
-type smtpEmailService struct {
-	smtpHost     string
-	smtpPort     string
-	smtpUsername string
-	smtpPassword string
-	fromEmail    string
-	fromName     string
-	frontendURL  string
+func recipientOf(user *repository.User) Recipient {
+	return Recipient{Email: user.Email, Name: user.DisplayName, Language: user.Language}
 }
 
-// NewEmailService creates a new email service
-func NewEmailService() EmailSender {
-	return &smtpEmailService{
-		smtpHost:     os.Getenv("SMTP_HOST"),
-		smtpPort:     os.Getenv("SMTP_PORT"),
-		smtpUsername: os.Getenv("SMTP_USERNAME"),
-		smtpPassword: os.Getenv("SMTP_PASSWORD"),
-		fromEmail:    os.Getenv("FROM_EMAIL"),
-		fromName:     os.Getenv("FROM_NAME"),
-		frontendURL:  os.Getenv("FRONTEND_URL"),
-	}
+// EmailSender defines the behavior required for sending auth emails. Implementations
+// queue the email and return without waiting for delivery.
+type EmailSender interface {
+	SendVerificationEmail(ctx context.Context, to Recipient, token string) error
+	SendPasswordResetEmail(ctx context.Context, to Recipient, token string) error
+	SendWelcomeEmail(ctx context.Context, to Recipient) error
+	SendEmailChangeConfirmation(ctx context.Context, to Recipient, token string) error
+	SendEmailChangeNotice(ctx context.Context, to Recipient, newEmail string) error
+	SendNewDeviceLoginEmail(ctx context.Context, to Recipient, userAgent, clientIP string) error
 }
 
-// SendVerificationEmail sends an email verification link
-func (s *smtpEmailService) SendVerificationEmail(toEmail, toName, token string) error {
-	subject := "Verify Your Email - loci"
-	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", s.frontendURL, token)
+type outboxEmailService struct {
+	outbox      *email.Outbox
+	frontendURL string
+}
 
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <div style="background-color: #f8f9fa; border-radius: 10px; padding: 30px;">
-        <h1 style="color: #4a5568; margin-bottom: 20px;">Welcome to loci!</h1>
-        <p>Hi %s,</p>
-        <p>Thank you for registering with loci. Please verify your email address by clicking the button below:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="%s" style="background-color: #4f46e5; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Verify Email</a>
-        </div>
-        <p style="color: #6b7280; font-size: 14px;">Or copy and paste this link into your browser:</p>
-        <p style="word-break: break-all; color: #6b7280; font-size: 12px;">%s</p>
-        <p style="margin-top: 30px; color: #6b7280; font-size: 14px;">This link will expire in 24 hours.</p>
-        <p style="margin-top: 30px; color: #6b7280; font-size: 12px;">If you didn't create an account, please ignore this email.</p>
-    </div>
-</body>
-</html>
-	`, toName, verificationLink, verificationLink)
+// NewEmailService creates an email service that queues auth emails in outbox. Links
+// in the emails point at frontendURL.
+func NewEmailService(outbox *email.Outbox, frontendURL string) EmailSender {
+	return &outboxEmailService{outbox: outbox, frontendURL: frontendURL}
+}
 
-	return s.sendEmail(toEmail, subject, body)
+func (s *outboxEmailService) send(ctx context.Context, to Recipient, template string, data map[string]any) error {
+	return s.outbox.Send(ctx, email.Address{Email: to.Email, Name: to.Name}, to.Language, template, data)
 }
 
-// SendPasswordResetEmail sends a password reset link
-func (s *smtpEmailService) SendPasswordResetEmail(toEmail, toName, token string) error {
-	subject := "Reset Your Password - loci"
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.frontendURL, token)
+func (s *outboxEmailService) link(path, token string) string {
+	link := s.frontendURL + path
+	if token != "" {
+		link += "?token=" + url.QueryEscape(token)
+	}
+	return link
+}
 
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <div style="background-color: #f8f9fa; border-radius: 10px; padding: 30px;">
-        <h1 style="color: #4a5568; margin-bottom: 20px;">Password Reset Request</h1>
-        <p>Hi %s,</p>
-        <p>We received a request to reset your password. Click the button below to create a new password:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="%s" style="background-color: #dc2626; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Reset Password</a>
-        </div>
-        <p style="color: #6b7280; font-size: 14px;">Or copy and paste this link into your browser:</p>
-        <p style="word-break: break-all; color: #6b7280; font-size: 12px;">%s</p>
-        <p style="margin-top: 30px; color: #6b7280; font-size: 14px;">This link will expire in 1 hour.</p>
-        <p style="margin-top: 30px; color: #dc2626; font-size: 12px; font-weight: bold;">If you didn't request a password reset, please ignore this email or contact support if you have concerns.</p>
-    </div>
-</body>
-</html>
-	`, toName, resetLink, resetLink)
+// SendVerificationEmail sends an email verification link
+func (s *outboxEmailService) SendVerificationEmail(ctx context.Context, to Recipient, token string) error {
+	return s.send(ctx, to, "verification", map[string]any{"Link": s.link("/verify-email", token)})
+}
 
-	return s.sendEmail(toEmail, subject, body)
+// SendPasswordResetEmail sends a password reset link
+func (s *outboxEmailService) SendPasswordResetEmail(ctx context.Context, to Recipient, token string) error {
+	return s.send(ctx, to, "password_reset", map[string]any{"Link": s.link("/reset-password", token)})
 }
 
 // SendWelcomeEmail sends a welcome email after successful registration
-func (s *smtpEmailService) SendWelcomeEmail(toEmail, toName string) error {
-	subject := "Welcome to loci!"
-
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <div style="background-color: #f8f9fa; border-radius: 10px; padding: 30px;">
-        <h1 style="color: #4a5568; margin-bottom: 20px;">🎉 Welcome to loci!</h1>
-        <p>Hi %s,</p>
-        <p>Your email has been verified successfully! You can now:</p>
-        <ul style="margin: 20px 0;">
-            <li>Create your skill profile</li>
-            <li>Match with other users</li>
-            <li>Schedule skill exchange sessions</li>
-            <li>Start learning and teaching</li>
-        </ul>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="%s/dashboard" style="background-color: #10b981; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Get Started</a>
-        </div>
-        <p style="margin-top: 30px;">Happy learning!</p>
-        <p>The loci Team</p>
-    </div>
-</body>
-</html>
-	`, toName, s.frontendURL)
-
-	return s.sendEmail(toEmail, subject, body)
+func (s *outboxEmailService) SendWelcomeEmail(ctx context.Context, to Recipient) error {
+	return s.send(ctx, to, "welcome", map[string]any{"AppURL": s.link("/dashboard", "")})
 }
 
-// sendEmail is a helper function to send emails via SMTP
-func (s *smtpEmailService) sendEmail(to, subject, body string) error {
-	// If SMTP is not configured, log and skip (for development)
-	if s.smtpHost == "" || s.smtpPort == "" {
-		fmt.Printf("Email would be sent to %s with subject: %s\n", to, subject)
-		return nil
-	}
-
-	auth := smtp.PlainAuth("", s.smtpUsername, s.smtpPassword, s.smtpHost)
-
-	from := fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail)
+// SendEmailChangeConfirmation sends the link confirming a new email address
+func (s *outboxEmailService) SendEmailChangeConfirmation(ctx context.Context, to Recipient, token string) error {
+	return s.send(ctx, to, "email_change_confirmation", map[string]any{
+		"Link":     s.link("/confirm-email-change", token),
+		"NewEmail": to.Email,
+	})
+}
 
-	message := []byte(fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/html; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s\r\n", from, to, subject, body))
+// SendEmailChangeNotice tells the current address that a change to newEmail was requested
+func (s *outboxEmailService) SendEmailChangeNotice(ctx context.Context, to Recipient, newEmail string) error {
+	return s.send(ctx, to, "email_change_notice", map[string]any{"NewEmail": newEmail})
+}
 
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
-	return smtp.SendMail(addr, auth, s.fromEmail, []string{to}, message)
+// SendNewDeviceLoginEmail alerts the user to a login from a device they have not used before
+func (s *outboxEmailService) SendNewDeviceLoginEmail(ctx context.Context, to Recipient, userAgent, clientIP string) error {
+	return s.send(ctx, to, "new_device_login", map[string]any{
+		"UserAgent": userAgent,
+		"ClientIP":  clientIP,
+		"ResetLink": s.link("/forgot-password", ""),
+	})
 }
@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/email"
+)
+
+func TestEmailService_QueuesLocalizedEmail(t *testing.T) {
+	ctx := context.Background()
+	renderer, err := email.NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	outbox := email.NewOutbox(email.NewMemoryStore(), renderer)
+	sender := service.NewEmailService(outbox, "https://loci.test")
+
+	to := service.Recipient{Email: "ana@example.com", Name: "Ana", Language: "pt-BR"}
+	if err := sender.SendPasswordResetEmail(ctx, to, "tok/en+1"); err != nil {
+		t.Fatalf("SendPasswordResetEmail: %v", err)
+	}
+
+	transport := email.NewMemoryTransport()
+	worker := email.NewWorker(outbox, transport, slog.New(slog.NewTextHandler(io.Discard, nil)), email.WorkerOptions{})
+	if sent, err := worker.RunOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("RunOnce: sent %d, err %v", sent, err)
+	}
+
+	msg := transport.Messages()[0]
+	if msg.To.Email != "ana@example.com" || msg.Subject != "Redefinir a sua palavra-passe - loci" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if !strings.Contains(msg.Text, "https://loci.test/reset-password?token=tok%2Fen%2B1") {
+		t.Fatalf("reset link missing from:\n%s", msg.Text)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+)
+
+const (
+	maxAuditEventsPage     = 200
+	defaultAuditEventsPage = 50
+)
+
+// ErrTooManyAttempts is returned for logins to an account or from an address that is
+// locked out after repeated failures.
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// LockedOutError is the ErrTooManyAttempts returned while a lockout lasts.
+type LockedOutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedOutError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// LockoutPolicy throttles failed logins per account and per client IP. A key is locked
+// for BaseLockout once its failures reach the threshold, and every further failure
+// doubles the lockout up to MaxLockout. Failures are forgotten after FailureWindow
+// without a new one.
+type LockoutPolicy struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseLockout      time.Duration
+	MaxLockout       time.Duration
+	FailureWindow    time.Duration
+}
+
+// DefaultLockoutPolicy locks an account after 5 failures and an address after 20.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		AccountThreshold: 5,
+		IPThreshold:      20,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+		FailureWindow:    24 * time.Hour,
+	}
+}
+
+// lockoutFor returns how long to lock a key after its failures-th failure, or zero
+// below the threshold.
+func (p LockoutPolicy) lockoutFor(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	exp := failures - threshold
+	if exp > 30 {
+		exp = 30
+	}
+	lockout := time.Duration(float64(p.BaseLockout) * math.Pow(2, float64(exp)))
+	if p.MaxLockout > 0 && lockout > p.MaxLockout {
+		lockout = p.MaxLockout
+	}
+	return lockout
+}
+
+// WithLockoutPolicy replaces the default login lockout policy.
+func (s *AuthService) WithLockoutPolicy(policy LockoutPolicy) *AuthService {
+	s.lockout = policy
+	return s
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(meta SessionMetadata) string {
+	if meta.ClientIP == "" || meta.ClientIP == "unknown" {
+		return ""
+	}
+	return "ip:" + meta.ClientIP
+}
+
+// checkLoginLockout returns a LockedOutError while the account or client IP is locked.
+func (s *AuthService) checkLoginLockout(ctx context.Context, email string, meta SessionMetadata) error {
+	keys := []string{accountThrottleKey(email)}
+	if key := ipThrottleKey(meta); key != "" {
+		keys = append(keys, key)
+	}
+
+	lockedUntil, err := s.repo.GetLoginLockout(ctx, keys)
+	if err != nil {
+		return err
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+	return &LockedOutError{RetryAfter: time.Until(*lockedUntil)}
+}
+
+// recordLoginFailure counts a failed login against the account and the client IP and
+// locks whichever crossed its threshold. It reports whether a lockout was applied.
+func (s *AuthService) recordLoginFailure(ctx context.Context, email string, meta SessionMetadata) bool {
+	windowStart := time.Now().Add(-s.lockout.FailureWindow)
+	locked := false
+
+	record := func(key string, threshold int) {
+		attempts, err := s.repo.RecordLoginFailure(ctx, key, windowStart)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to record login failure", slog.String("key", key), slog.Any("error", err))
+			return
+		}
+		lockout := s.lockout.lockoutFor(attempts.Failures, threshold)
+		if lockout == 0 {
+			return
+		}
+		if err := s.repo.LockLogin(ctx, key, time.Now().Add(lockout)); err != nil {
+			s.logger.WarnContext(ctx, "failed to lock login", slog.String("key", key), slog.Any("error", err))
+			return
+		}
+		locked = true
+	}
+
+	record(accountThrottleKey(email), s.lockout.AccountThreshold)
+	if key := ipThrottleKey(meta); key != "" {
+		record(key, s.lockout.IPThreshold)
+	}
+	return locked
+}
+
+// clearAccountFailures resets the account's failure count after a successful login.
+// The client IP keeps its count, so one valid account does not reset the throttle of
+// an address trying many others.
+func (s *AuthService) clearAccountFailures(ctx context.Context, email string) {
+	if err := s.repo.ClearLoginFailures(ctx, accountThrottleKey(email)); err != nil {
+		s.logger.WarnContext(ctx, "failed to clear login failures", slog.Any("error", err))
+	}
+}
+
+// audit appends an event to the auth audit trail. A failed write is logged rather than
+// failing the request it describes.
+func (s *AuthService) audit(ctx context.Context, eventType string, userID uuid.UUID, email string, meta SessionMetadata) {
+	event := repository.AuthAuditEvent{
+		EventType: eventType,
+		Email:     optionalString(email),
+		ClientIP:  optionalString(meta.ClientIP),
+		UserAgent: optionalString(meta.UserAgent),
+	}
+	if userID != uuid.Nil {
+		event.UserID = &userID
+	}
+	if err := s.repo.CreateAuthAuditEvent(ctx, event); err != nil {
+		s.logger.WarnContext(ctx, "failed to write auth audit event",
+			slog.String("event_type", eventType), slog.Any("error", err))
+	}
+}
+
+// notifyNewDevice emails the user when they log in from a user agent none of their
+// earlier logins used. It must run before the login itself is audited. Users without
+// any recorded login are not notified, so the first login after registering is quiet.
+func (s *AuthService) notifyNewDevice(ctx context.Context, user *repository.User, meta SessionMetadata) {
+	if s.emailService == nil || meta.UserAgent == "" {
+		return
+	}
+	hasLogins, known, err := s.repo.HasLoginFromUserAgent(ctx, user.ID, meta.UserAgent)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to check login devices", slog.Any("error", err))
+		return
+	}
+	if !hasLogins || known {
+		return
+	}
+
+	if err := s.emailService.SendNewDeviceLoginEmail(ctx, recipientOf(user), meta.UserAgent, meta.ClientIP); err != nil {
+		s.logger.WarnContext(ctx, "failed to queue new device login email", slog.Any("error", err))
+	}
+}
+
+// AuditEventsPage is a page of audit events. NextBeforeID continues the listing and
+// is zero on the last page.
+type AuditEventsPage struct {
+	Events       []repository.AuthAuditEvent
+	NextBeforeID int64
+}
+
+// ListAuditEvents returns auth audit events for administrators, newest first.
+func (s *AuthService) ListAuditEvents(ctx context.Context, filter repository.AuthAuditFilter) (*AuditEventsPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditEventsPage
+	}
+	if filter.Limit > maxAuditEventsPage {
+		filter.Limit = maxAuditEventsPage
+	}
+
+	events, err := s.repo.ListAuthAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &AuditEventsPage{Events: events}
+	if len(events) == filter.Limit {
+		page.NextBeforeID = events[len(events)-1].ID
+	}
+	return page, nil
+}
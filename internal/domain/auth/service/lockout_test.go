@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+)
+
+func TestAuthService_Login_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	password := "Str0ng!Pass"
+	servicetest.AddUser(repo, t, "locked@example.com", true, servicetest.MustHash(t, password))
+	meta := service.SessionMetadata{ClientIP: "203.0.113.7", UserAgent: "test"}
+
+	for i := range 5 {
+		_, err := svc.Login(ctx, service.LoginParams{Email: "locked@example.com", Password: "Wrong!Pass1", Metadata: meta})
+		if !errors.Is(err, common.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: expected invalid credentials, got %v", i+1, err)
+		}
+	}
+
+	// The right password is rejected while the lockout lasts, without another failure.
+	_, err := svc.Login(ctx, service.LoginParams{Email: "Locked@example.com", Password: password, Metadata: meta})
+	var lockedOut *service.LockedOutError
+	if !errors.As(err, &lockedOut) || !errors.Is(err, service.ErrTooManyAttempts) {
+		t.Fatalf("expected a lockout, got %v", err)
+	}
+	if lockedOut.RetryAfter <= 0 || lockedOut.RetryAfter > time.Minute {
+		t.Fatalf("expected the first lockout to last a minute, got %s", lockedOut.RetryAfter)
+	}
+
+	attempts := repo.Attempts["account:locked@example.com"]
+	if attempts.Failures != 5 {
+		t.Fatalf("expected 5 recorded failures, got %d", attempts.Failures)
+	}
+	events := repo.AuditEvents()
+	if got := countEvents(events, repository.AuditEventLoginFailed); got != 5 {
+		t.Fatalf("expected 5 failed logins audited, got %d (%v)", got, events)
+	}
+	if got := countEvents(events, repository.AuditEventLoginLocked); got != 2 {
+		t.Fatalf("expected the lockout and the rejected attempt audited, got %d (%v)", got, events)
+	}
+
+	// Each failure after the lockout expires doubles it.
+	expired := time.Now().Add(-time.Second)
+	attempts.LockedUntil = &expired
+	if _, err := svc.Login(ctx, service.LoginParams{Email: "locked@example.com", Password: "Wrong!Pass1", Metadata: meta}); !errors.Is(err, common.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if lockout := time.Until(*attempts.LockedUntil); lockout <= time.Minute || lockout > 2*time.Minute {
+		t.Fatalf("expected a two minute lockout, got %s", lockout)
+	}
+
+	// A successful login clears the account's failures.
+	attempts.LockedUntil = &expired
+	if _, err := svc.Login(ctx, service.LoginParams{Email: "locked@example.com", Password: password, Metadata: meta}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, ok := repo.Attempts["account:locked@example.com"]; ok {
+		t.Fatalf("expected the account failures to be cleared")
+	}
+	if _, ok := repo.Attempts["ip:203.0.113.7"]; !ok {
+		t.Fatalf("a successful login must not reset the address's failures")
+	}
+}
+
+func TestAuthService_Login_LocksClientIPAcrossAccounts(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	svc.WithLockoutPolicy(service.LockoutPolicy{
+		AccountThreshold: 10,
+		IPThreshold:      3,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+		FailureWindow:    time.Hour,
+	})
+	password := "Str0ng!Pass"
+	servicetest.AddUser(repo, t, "victim@example.com", true, servicetest.MustHash(t, password))
+	attacker := service.SessionMetadata{ClientIP: "198.51.100.1"}
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := svc.Login(ctx, service.LoginParams{Email: email, Password: "Guess!Pass1", Metadata: attacker}); !errors.Is(err, common.ErrUserNotFound) {
+			t.Fatalf("expected unknown user, got %v", err)
+		}
+	}
+
+	if _, err := svc.Login(ctx, service.LoginParams{Email: "victim@example.com", Password: password, Metadata: attacker}); !errors.Is(err, service.ErrTooManyAttempts) {
+		t.Fatalf("expected the address to be locked, got %v", err)
+	}
+	other := service.SessionMetadata{ClientIP: "198.51.100.2"}
+	if _, err := svc.Login(ctx, service.LoginParams{Email: "victim@example.com", Password: password, Metadata: other}); err != nil {
+		t.Fatalf("other addresses should not be locked: %v", err)
+	}
+}
+
+func TestAuthService_Login_NotifiesNewDevice(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, email := servicetest.NewTestAuthService()
+	password := "Str0ng!Pass"
+	servicetest.AddUser(repo, t, "devices@example.com", true, servicetest.MustHash(t, password))
+	login := func(userAgent string) {
+		t.Helper()
+		if _, err := svc.Login(ctx, service.LoginParams{
+			Email:    "devices@example.com",
+			Password: password,
+			Metadata: service.SessionMetadata{UserAgent: userAgent, ClientIP: "192.0.2.1"},
+		}); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+	}
+
+	login("phone")
+	login("phone")
+	login("laptop")
+	if got := email.NewDeviceEmails(); got != 1 {
+		t.Fatalf("expected the new laptop to be reported, got %d emails", got)
+	}
+
+	login("laptop")
+	if got := email.NewDeviceEmails(); got != 1 {
+		t.Fatalf("expected only the first laptop login to be reported, got %d emails", got)
+	}
+}
+
+func TestAuthService_ListAuditEvents_Pages(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := servicetest.NewTestAuthService()
+	for range 3 {
+		_, _ = svc.Login(ctx, service.LoginParams{Email: "nobody@example.com", Password: "Wrong!Pass1"})
+	}
+
+	page, err := svc.ListAuditEvents(ctx, repository.AuthAuditFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	if len(page.Events) != 2 || page.Events[0].ID != 3 || page.NextBeforeID != 2 {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+
+	page, err = svc.ListAuditEvents(ctx, repository.AuthAuditFilter{Limit: 2, BeforeID: page.NextBeforeID})
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	if len(page.Events) != 1 || page.NextBeforeID != 0 {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+	if len(repo.Audit) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(repo.Audit))
+	}
+}
+
+func countEvents(events []string, eventType string) int {
+	count := 0
+	for _, e := range events {
+		if e == eventType {
+			count++
+		}
+	}
+	return count
+}
@@ -1,15 +1,36 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
 	"github.com/markbates/goth"
-	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/apple"
 	"github.com/markbates/goth/providers/google"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/common"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
 )
 
+const oauthLoginCodeTTL = time.Minute
+
+// ErrOAuthEmailUnverified is returned when a provider does not vouch for the email of
+// an identity that is not linked yet; such an email cannot be trusted to pick an
+// account.
+var ErrOAuthEmailUnverified = errors.New("oauth provider did not verify the email")
+
 // OAuthConfig holds OAuth configuration
 type OAuthConfig struct {
 	GoogleClientID     string
@@ -20,16 +41,14 @@ type OAuthConfig struct {
 	AppleKeyID         string
 	CallbackURL        string
 	SessionSecret      string
+	// AllowedRedirectURIs are the app URIs a finished login may redirect to with its
+	// one-time code. Anything else is rejected so codes cannot be sent to other sites.
+	AllowedRedirectURIs []string
 }
 
-// InitOAuth initializes OAuth providers (Google and Apple only)
-func InitOAuth(config OAuthConfig) error {
-	// Set up session store for gothic
-	store := sessions.NewCookieStore([]byte(config.SessionSecret))
-	store.Options.HttpOnly = true
-	store.Options.Secure = true // Set to true in production with HTTPS
-	gothic.Store = store
-
+// InitOAuth initializes OAuth providers (Google and Apple only) and the cookie store that
+// carries login state between the redirect to the provider and its callback.
+func InitOAuth(config OAuthConfig) ([]goth.Provider, sessions.Store, error) {
 	// Initialize providers
 	providers := []goth.Provider{}
 
@@ -61,21 +80,243 @@ func InitOAuth(config OAuthConfig) error {
 		)
 	}
 
-	goth.UseProviders(providers...)
+	if len(providers) == 0 {
+		return nil, nil, nil
+	}
+	if config.SessionSecret == "" {
+		return nil, nil, errors.New("SESSION_SECRET is required when OAuth providers are configured")
+	}
+
+	// Apple posts its callback from appleid.apple.com, so the cookie must be sent on
+	// cross-site requests.
+	store := sessions.NewCookieStore([]byte(config.SessionSecret))
+	store.Options.HttpOnly = true
+	store.Options.Secure = true
+	store.Options.SameSite = http.SameSiteNoneMode
 
-	return nil
+	return providers, store, nil
 }
 
 // LoadOAuthConfigFromEnv loads OAuth config from environment variables
 func LoadOAuthConfigFromEnv() OAuthConfig {
+	var redirects []string
+	for _, uri := range strings.Split(os.Getenv("OAUTH_ALLOWED_REDIRECT_URIS"), ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			redirects = append(redirects, uri)
+		}
+	}
+
 	return OAuthConfig{
-		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		AppleClientID:      os.Getenv("APPLE_CLIENT_ID"),
-		AppleSecret:        os.Getenv("APPLE_SECRET"),
-		AppleTeamID:        os.Getenv("APPLE_TEAM_ID"),
-		AppleKeyID:         os.Getenv("APPLE_KEY_ID"),
-		CallbackURL:        os.Getenv("OAUTH_CALLBACK_URL"),
-		SessionSecret:      os.Getenv("SESSION_SECRET"),
+		GoogleClientID:      os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret:  os.Getenv("GOOGLE_CLIENT_SECRET"),
+		AppleClientID:       os.Getenv("APPLE_CLIENT_ID"),
+		AppleSecret:         os.Getenv("APPLE_SECRET"),
+		AppleTeamID:         os.Getenv("APPLE_TEAM_ID"),
+		AppleKeyID:          os.Getenv("APPLE_KEY_ID"),
+		CallbackURL:         os.Getenv("OAUTH_CALLBACK_URL"),
+		SessionSecret:       os.Getenv("SESSION_SECRET"),
+		AllowedRedirectURIs: redirects,
+	}
+}
+
+// OAuthProfile is the identity a provider returned at the end of a login.
+type OAuthProfile struct {
+	Provider       string
+	ProviderUserID string
+	Email          string
+	EmailVerified  bool
+	Name           string
+	AccessToken    string
+	RefreshToken   string
+}
+
+// NewOAuthProfile extracts the profile from a goth user. Providers report whether the
+// email is verified in different places: Apple in its ID token, Google's userinfo as
+// verified_email and OpenID Connect providers as the email_verified claim.
+func NewOAuthProfile(user goth.User, session goth.Session) OAuthProfile {
+	profile := OAuthProfile{
+		Provider:       user.Provider,
+		ProviderUserID: user.UserID,
+		Email:          strings.TrimSpace(user.Email),
+		Name:           user.Name,
+		AccessToken:    user.AccessToken,
+		RefreshToken:   user.RefreshToken,
+	}
+	if profile.Name == "" {
+		profile.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+
+	if s, ok := session.(*apple.Session); ok {
+		profile.EmailVerified = s.EmailVerified
+	}
+	for _, claim := range []string{"email_verified", "verified_email"} {
+		switch v := user.RawData[claim].(type) {
+		case bool:
+			profile.EmailVerified = profile.EmailVerified || v
+		case string:
+			profile.EmailVerified = profile.EmailVerified || v == "true"
+		}
+	}
+	return profile
+}
+
+// LoginWithOAuth resolves the loci user for a provider identity. A known identity logs
+// in its linked user; otherwise the identity is linked to the account with the same
+// verified email, or a new account is created for it.
+func (s *AuthService) LoginWithOAuth(ctx context.Context, profile OAuthProfile) (*repository.User, error) {
+	if profile.Provider == "" || profile.ProviderUserID == "" {
+		return nil, fmt.Errorf("oauth profile is missing the provider identity")
+	}
+
+	user, err := s.repo.GetUserByOAuthIdentity(ctx, profile.Provider, profile.ProviderUserID)
+	switch {
+	case err == nil:
+	case errors.Is(err, common.ErrUserNotFound):
+		user, err = s.linkOAuthUser(ctx, profile)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+
+	if err := s.repo.CreateOrUpdateOAuthIdentity(ctx, profile.Provider, profile.ProviderUserID, user.ID,
+		optionalString(profile.AccessToken), optionalString(profile.RefreshToken)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AuthService) linkOAuthUser(ctx context.Context, profile OAuthProfile) (*repository.User, error) {
+	if profile.Email == "" || !profile.EmailVerified {
+		return nil, ErrOAuthEmailUnverified
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, profile.Email)
+	if err == nil {
+		if user.EmailVerifiedAt == nil {
+			// The provider proved ownership of the address.
+			if err := s.repo.VerifyEmail(ctx, user.ID); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+	if !errors.Is(err, common.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// OAuth accounts start without a usable password; one can be set through the
+	// password reset flow.
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := randomToken(3)
+	if err != nil {
+		return nil, err
+	}
+	localPart, _, _ := strings.Cut(profile.Email, "@")
+	username := localPart + "-" + suffix
+	displayName := profile.Name
+	if displayName == "" {
+		displayName = localPart
+	}
+
+	user, err = s.repo.CreateUser(ctx, profile.Email, username, hashedPassword, displayName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.VerifyEmail(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// CreateOAuthLoginCode issues a short-lived one-time code for userID. It can only be
+// redeemed with the PKCE verifier whose S256 hash is codeChallenge.
+func (s *AuthService) CreateOAuthLoginCode(ctx context.Context, userID uuid.UUID, codeChallenge string) (string, error) {
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreateOAuthLoginCode(ctx, repository.OAuthLoginCode{
+		CodeHash:      hashToken(code),
+		UserID:        userID,
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     time.Now().Add(oauthLoginCodeTTL),
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ExchangeOAuthLoginCode redeems a login code for a new session. The code is consumed
+// even when the verifier is wrong, so it cannot be brute forced.
+func (s *AuthService) ExchangeOAuthLoginCode(ctx context.Context, code, codeVerifier string, meta SessionMetadata) (*LoginResult, error) {
+	if code == "" || codeVerifier == "" {
+		return nil, common.ErrInvalidToken
+	}
+
+	loginCode, err := s.repo.ConsumeOAuthLoginCode(ctx, hashToken(code))
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(PKCEChallenge(codeVerifier)), []byte(loginCode.CodeChallenge)) != 1 {
+		return nil, common.ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, loginCode.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+
+	tokens, err := s.tokenManager.GenerateTokenPair(user.ID.String(), user.Email, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createSession(ctx, user.ID, uuid.Nil, tokens, meta); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateLastLogin(ctx, user.ID); err != nil {
+		s.logger.WarnContext(ctx, "failed to update last login", "error", err)
+	}
+	s.notifyNewDevice(ctx, user, meta)
+	s.audit(ctx, repository.AuditEventLoginSucceeded, user.ID, user.Email, meta)
+
+	return &LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// PKCEChallenge returns the S256 code challenge for a verifier (RFC 7636).
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
 }
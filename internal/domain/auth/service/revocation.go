@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+var _ interceptors.RevocationChecker = (*RevocationList)(nil)
+
+const (
+	defaultRevocationSyncInterval = 5 * time.Second
+	// revocationSyncOverlap re-reads recent revocations on every sync so a row whose
+	// transaction committed after a concurrent sync read is not missed.
+	revocationSyncOverlap = time.Minute
+	// revocationSweepEvery syncs between sweeps of expired rows.
+	revocationSweepEvery = 120
+)
+
+// RevocationList mirrors the unexpired access token revocations in memory, so the
+// auth interceptor can check every request without a database round trip.
+// Revocations made by this replica apply immediately; those made by other replicas
+// apply within one sync interval.
+type RevocationList struct {
+	repo     repository.AuthRepository
+	logger   *slog.Logger
+	interval time.Duration
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time // jti -> token expiry
+	since   time.Time
+	syncs   int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRevocationList creates a revocation list that syncs from repo every interval.
+func NewRevocationList(repo repository.AuthRepository, logger *slog.Logger, interval time.Duration) *RevocationList {
+	if interval <= 0 {
+		interval = defaultRevocationSyncInterval
+	}
+	return &RevocationList{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		revoked:  make(map[string]time.Time),
+	}
+}
+
+// Start loads the current revocations and keeps syncing in the background until
+// Close is called.
+func (l *RevocationList) Start(ctx context.Context) error {
+	if err := l.Sync(ctx); err != nil {
+		return err
+	}
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go l.run()
+	return nil
+}
+
+func (l *RevocationList) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.interval)
+			if err := l.Sync(ctx); err != nil {
+				l.logger.Warn("failed to sync token revocations", slog.Any("error", err))
+			}
+			cancel()
+		}
+	}
+}
+
+// Close stops background syncing.
+func (l *RevocationList) Close() {
+	if l.stop == nil {
+		return
+	}
+	close(l.stop)
+	<-l.done
+	l.stop = nil
+}
+
+// Sync loads revocations made since the last sync and drops expired ones.
+func (l *RevocationList) Sync(ctx context.Context) error {
+	l.mu.RLock()
+	since := l.since
+	sweep := l.syncs%revocationSweepEvery == 0
+	l.mu.RUnlock()
+
+	if sweep {
+		if err := l.repo.DeleteExpiredRevocations(ctx); err != nil {
+			l.logger.WarnContext(ctx, "failed to sweep expired revocations", slog.Any("error", err))
+		}
+	}
+
+	tokens, err := l.repo.ListRevokedTokens(ctx, since.Add(-revocationSyncOverlap))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.syncs++
+	for _, t := range tokens {
+		l.revoked[t.JTI] = t.ExpiresAt
+		if t.RevokedAt.After(l.since) {
+			l.since = t.RevokedAt
+		}
+	}
+	for jti, expiresAt := range l.revoked {
+		if !expiresAt.After(now) {
+			delete(l.revoked, jti)
+		}
+	}
+	return nil
+}
+
+// Add applies revocations made by this replica without waiting for a sync.
+func (l *RevocationList) Add(tokens ...repository.RevokedToken) {
+	if len(tokens) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, t := range tokens {
+		l.revoked[t.JTI] = t.ExpiresAt
+	}
+}
+
+// IsRevoked reports whether the access token with the given jti was revoked.
+func (l *RevocationList) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	l.mu.RLock()
+	expiresAt, ok := l.revoked[jti]
+	l.mu.RUnlock()
+	return ok && expiresAt.After(time.Now())
+}
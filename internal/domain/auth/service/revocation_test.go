@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/servicetest"
+)
+
+func TestRevocationList_SyncAndAdd(t *testing.T) {
+	ctx := context.Background()
+	repo := servicetest.NewMockAuthRepo()
+	now := time.Now()
+	repo.Revoked["from-other-replica"] = repository.RevokedToken{
+		JTI: "from-other-replica", UserID: uuid.New(), ExpiresAt: now.Add(time.Minute), RevokedAt: now,
+	}
+
+	list := service.NewRevocationList(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour)
+	if list.IsRevoked("from-other-replica") {
+		t.Fatalf("revocation should not be known before the first sync")
+	}
+	if err := list.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !list.IsRevoked("from-other-replica") {
+		t.Fatalf("expected synced revocation")
+	}
+
+	list.Add(
+		repository.RevokedToken{JTI: "local", ExpiresAt: now.Add(time.Minute)},
+		repository.RevokedToken{JTI: "expired", ExpiresAt: now.Add(-time.Second)},
+	)
+	if !list.IsRevoked("local") {
+		t.Fatalf("expected local revocation to apply immediately")
+	}
+	if list.IsRevoked("expired") || list.IsRevoked("") || list.IsRevoked("unknown") {
+		t.Fatalf("expired, empty and unknown ids must not be reported revoked")
+	}
+}
+
+func TestAuthService_RevokeAllSessions_RevokesAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	repo := servicetest.NewMockAuthRepo()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	list := service.NewRevocationList(repo, logger, time.Hour)
+	svc := service.NewAuthService(repo, &servicetest.MockTokenManager{}, nil, list, logger, time.Hour)
+
+	user := servicetest.AddUser(repo, t, "everywhere@example.com", true, "Hashed!Pass1")
+	for _, jti := range []string{"phone", "laptop"} {
+		if _, err := repo.CreateUserSession(ctx, repository.CreateSessionParams{
+			UserID:             user.ID,
+			HashedRefreshToken: jti + "-refresh",
+			AccessTokenID:      jti,
+			AccessExpiresAt:    time.Now().Add(15 * time.Minute),
+			ExpiresAt:          time.Now().Add(time.Hour),
+		}); err != nil {
+			t.Fatalf("CreateUserSession: %v", err)
+		}
+	}
+
+	if err := svc.RevokeAllSessions(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeAllSessions: %v", err)
+	}
+	if len(repo.Sessions) != 0 {
+		t.Fatalf("expected all sessions removed, got %d", len(repo.Sessions))
+	}
+	if !list.IsRevoked("phone") || !list.IsRevoked("laptop") {
+		t.Fatalf("expected both access tokens to be revoked without waiting for a sync")
+	}
+}
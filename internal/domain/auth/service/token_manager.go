@@ -4,10 +4,13 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
 )
 
 // TokenManager defines the behavior required for token operations.
@@ -18,10 +21,10 @@ type TokenManager interface {
 }
 
 type jwtTokenManager struct {
-	accessTokenSecret  []byte
-	refreshTokenSecret []byte
-	accessTokenTTL     time.Duration
-	refreshTokenTTL    time.Duration
+	accessKeys      *jwtkeys.KeySet
+	refreshKeys     *jwtkeys.KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
 // TokenPair represents access and refresh tokens
@@ -30,6 +33,9 @@ type TokenPair struct {
 	RefreshToken string    `json:"refresh_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	TokenType    string    `json:"token_type"`
+	// AccessTokenID is the jti of the access token, recorded on the session so the
+	// token can be revoked with it.
+	AccessTokenID string `json:"-"`
 }
 
 // Claims represents JWT claims
@@ -38,16 +44,19 @@ type Claims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// TokenUse is jwtkeys.TokenUseAccess or jwtkeys.TokenUseRefresh.
+	TokenUse string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
-// NewTokenManager creates a new token manager
-func NewTokenManager(accessSecret, refreshSecret []byte, accessTTL, refreshTTL time.Duration) TokenManager {
+// NewTokenManager creates a new token manager. Tokens are signed with each key set's
+// signing key and verified against all of its keys.
+func NewTokenManager(accessKeys, refreshKeys *jwtkeys.KeySet, accessTTL, refreshTTL time.Duration) TokenManager {
 	return &jwtTokenManager{
-		accessTokenSecret:  accessSecret,
-		refreshTokenSecret: refreshSecret,
-		accessTokenTTL:     accessTTL,
-		refreshTokenTTL:    refreshTTL,
+		accessKeys:      accessKeys,
+		refreshKeys:     refreshKeys,
+		accessTokenTTL:  accessTTL,
+		refreshTokenTTL: refreshTTL,
 	}
 }
 
@@ -63,6 +72,7 @@ func (tm *jwtTokenManager) GenerateTokenPair(userID, email, username, role strin
 		Email:    email,
 		Username: username,
 		Role:     role,
+		TokenUse: jwtkeys.TokenUseAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(accessExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -71,8 +81,7 @@ func (tm *jwtTokenManager) GenerateTokenPair(userID, email, username, role strin
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString(tm.accessTokenSecret)
+	accessTokenString, err := tm.accessKeys.Sign(accessClaims)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +92,7 @@ func (tm *jwtTokenManager) GenerateTokenPair(userID, email, username, role strin
 		Email:    email,
 		Username: username,
 		Role:     role,
+		TokenUse: jwtkeys.TokenUseRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -91,39 +101,35 @@ func (tm *jwtTokenManager) GenerateTokenPair(userID, email, username, role strin
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(tm.refreshTokenSecret)
+	refreshTokenString, err := tm.refreshKeys.Sign(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		ExpiresAt:    accessExpiresAt,
-		TokenType:    "Bearer",
+		AccessToken:   accessTokenString,
+		RefreshToken:  refreshTokenString,
+		ExpiresAt:     accessExpiresAt,
+		TokenType:     "Bearer",
+		AccessTokenID: accessClaims.ID,
 	}, nil
 }
 
 // ValidateAccessToken validates an access token and returns claims
 func (tm *jwtTokenManager) ValidateAccessToken(tokenString string) (*Claims, error) {
-	return tm.validateToken(tokenString, tm.accessTokenSecret)
+	return tm.validateToken(tokenString, tm.accessKeys, jwtkeys.TokenUseAccess)
 }
 
 // ValidateRefreshToken validates a refresh token and returns claims
 func (tm *jwtTokenManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
-	return tm.validateToken(tokenString, tm.refreshTokenSecret)
+	return tm.validateToken(tokenString, tm.refreshKeys, jwtkeys.TokenUseRefresh)
 }
 
-// validateToken is a helper function to validate tokens
-func (tm *jwtTokenManager) validateToken(tokenString string, secret []byte) (*Claims, error) {
+// validateToken verifies a token signed by keys and issued for use, so a refresh
+// token is never accepted as an access token or the other way around.
+func (tm *jwtTokenManager) validateToken(tokenString string, keys *jwtkeys.KeySet, use string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return secret, nil
-	})
+	token, err := keys.Parse(tokenString, claims)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +137,9 @@ func (tm *jwtTokenManager) validateToken(tokenString string, secret []byte) (*Cl
 	if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
+	if claims.TokenUse != use {
+		return nil, fmt.Errorf("token_use is %q, want %q", claims.TokenUse, use)
+	}
 
 	return claims, nil
 }
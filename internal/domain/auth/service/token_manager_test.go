@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/auth/service"
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
+)
+
+func TestTokenManager_TokensOnlyValidForTheirUse(t *testing.T) {
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, err := jwtkeys.NewKeySet(hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	// Both kinds of token are signed by the same keys, as in production.
+	tm := service.NewTokenManager(keys, keys, time.Minute, time.Hour)
+
+	pair, err := tm.GenerateTokenPair("user-1", "jane@example.com", "jane", "user")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	if claims, err := tm.ValidateAccessToken(pair.AccessToken); err != nil || claims.UserID != "user-1" {
+		t.Fatalf("expected access token to validate, got %v", err)
+	}
+	if claims, err := tm.ValidateRefreshToken(pair.RefreshToken); err != nil || claims.UserID != "user-1" {
+		t.Fatalf("expected refresh token to validate, got %v", err)
+	}
+	if _, err := tm.ValidateAccessToken(pair.RefreshToken); err == nil {
+		t.Fatalf("refresh token must not validate as an access token")
+	}
+	if _, err := tm.ValidateRefreshToken(pair.AccessToken); err == nil {
+		t.Fatalf("access token must not validate as a refresh token")
+	}
+}
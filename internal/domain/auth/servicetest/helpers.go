@@ -45,26 +45,44 @@ func (m *MockTokenManager) ValidateRefreshToken(tokenString string) (*service.Cl
 
 // MockEmailSender captures sent emails for assertions.
 type MockEmailSender struct {
-	verificationSent atomic.Bool
-	resetSent        atomic.Bool
-	welcomeSent      atomic.Bool
+	verificationSent  atomic.Bool
+	resetSent         atomic.Bool
+	welcomeSent       atomic.Bool
+	emailChangeNotice atomic.Bool
+	emailChangeToken  atomic.Pointer[string]
+	newDeviceSent     atomic.Int32
 }
 
-func (m *MockEmailSender) SendVerificationEmail(_, _, _ string) error {
+func (m *MockEmailSender) SendVerificationEmail(_ context.Context, _ service.Recipient, _ string) error {
 	m.verificationSent.Store(true)
 	return nil
 }
 
-func (m *MockEmailSender) SendPasswordResetEmail(_, _, _ string) error {
+func (m *MockEmailSender) SendPasswordResetEmail(_ context.Context, _ service.Recipient, _ string) error {
 	m.resetSent.Store(true)
 	return nil
 }
 
-func (m *MockEmailSender) SendWelcomeEmail(_, _ string) error {
+func (m *MockEmailSender) SendWelcomeEmail(_ context.Context, _ service.Recipient) error {
 	m.welcomeSent.Store(true)
 	return nil
 }
 
+func (m *MockEmailSender) SendEmailChangeConfirmation(_ context.Context, _ service.Recipient, token string) error {
+	m.emailChangeToken.Store(&token)
+	return nil
+}
+
+func (m *MockEmailSender) SendEmailChangeNotice(_ context.Context, _ service.Recipient, _ string) error {
+	m.emailChangeNotice.Store(true)
+	return nil
+}
+
+func (m *MockEmailSender) SendNewDeviceLoginEmail(_ context.Context, _ service.Recipient, _, _ string) error {
+	m.newDeviceSent.Add(1)
+	return nil
+}
+
 func (m *MockEmailSender) VerificationSent() bool {
 	return m.verificationSent.Load()
 }
@@ -77,10 +95,30 @@ func (m *MockEmailSender) WelcomeSent() bool {
 	return m.welcomeSent.Load()
 }
 
+// EmailChangeToken returns the token of the last email change confirmation sent.
+func (m *MockEmailSender) EmailChangeToken() string {
+	if token := m.emailChangeToken.Load(); token != nil {
+		return *token
+	}
+	return ""
+}
+
+func (m *MockEmailSender) EmailChangeNoticeSent() bool {
+	return m.emailChangeNotice.Load()
+}
+
+// NewDeviceEmails returns how many new device login alerts were sent.
+func (m *MockEmailSender) NewDeviceEmails() int {
+	return int(m.newDeviceSent.Load())
+}
+
 func (m *MockEmailSender) ResetFlags() {
 	m.verificationSent.Store(false)
 	m.resetSent.Store(false)
 	m.welcomeSent.Store(false)
+	m.emailChangeNotice.Store(false)
+	m.emailChangeToken.Store(nil)
+	m.newDeviceSent.Store(0)
 }
 
 // MockAuthRepo is an in-memory AuthRepository.
@@ -88,13 +126,23 @@ type MockAuthRepo struct {
 	Users    map[string]*repository.User
 	Sessions map[string]*repository.UserSession
 	Tokens   map[string]*repository.UserToken
+	Revoked  map[string]repository.RevokedToken
+	// Identities maps "provider/provider user id" to a user id.
+	Identities map[string]uuid.UUID
+	LoginCodes map[string]repository.OAuthLoginCode
+	Attempts   map[string]*repository.LoginAttempts
+	Audit      []repository.AuthAuditEvent
 }
 
 func NewMockAuthRepo() *MockAuthRepo {
 	return &MockAuthRepo{
-		Users:    make(map[string]*repository.User),
-		Sessions: make(map[string]*repository.UserSession),
-		Tokens:   make(map[string]*repository.UserToken),
+		Users:      make(map[string]*repository.User),
+		Sessions:   make(map[string]*repository.UserSession),
+		Tokens:     make(map[string]*repository.UserToken),
+		Revoked:    make(map[string]repository.RevokedToken),
+		Identities: make(map[string]uuid.UUID),
+		LoginCodes: make(map[string]repository.OAuthLoginCode),
+		Attempts:   make(map[string]*repository.LoginAttempts),
 	}
 }
 
@@ -145,17 +193,26 @@ func (m *MockAuthRepo) UpdateLastLogin(_ context.Context, userID uuid.UUID) erro
 	return common.ErrUserNotFound
 }
 
-func (m *MockAuthRepo) CreateUserSession(_ context.Context, userID uuid.UUID, hashedRefreshToken, userAgent, clientIP string, expiresAt time.Time) (*repository.UserSession, error) {
+func (m *MockAuthRepo) CreateUserSession(_ context.Context, params repository.CreateSessionParams) (*repository.UserSession, error) {
+	familyID := params.FamilyID
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+	}
 	session := &repository.UserSession{
 		ID:                 uuid.New(),
-		UserID:             userID,
-		HashedRefreshToken: hashedRefreshToken,
-		UserAgent:          &userAgent,
-		ClientIP:           &clientIP,
-		ExpiresAt:          expiresAt,
+		UserID:             params.UserID,
+		FamilyID:           familyID,
+		HashedRefreshToken: params.HashedRefreshToken,
+		UserAgent:          &params.UserAgent,
+		ClientIP:           &params.ClientIP,
+		ExpiresAt:          params.ExpiresAt,
 		CreatedAt:          time.Now(),
 	}
-	m.Sessions[hashedRefreshToken] = session
+	if params.AccessTokenID != "" {
+		session.AccessTokenID = &params.AccessTokenID
+		session.AccessExpiresAt = &params.AccessExpiresAt
+	}
+	m.Sessions[params.HashedRefreshToken] = session
 	return session, nil
 }
 
@@ -167,15 +224,89 @@ func (m *MockAuthRepo) GetUserSessionByToken(_ context.Context, hashedToken stri
 	return session, nil
 }
 
+func (m *MockAuthRepo) GetUserSessionByID(_ context.Context, sessionID uuid.UUID) (*repository.UserSession, error) {
+	for _, session := range m.Sessions {
+		if session.ID == sessionID && session.ExpiresAt.After(time.Now()) {
+			return session, nil
+		}
+	}
+	return nil, common.ErrSessionNotFound
+}
+
+func (m *MockAuthRepo) ListActiveUserSessions(_ context.Context, userID uuid.UUID) ([]repository.UserSession, error) {
+	var sessions []repository.UserSession
+	for _, session := range m.Sessions {
+		if session.UserID == userID && session.RotatedAt == nil && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockAuthRepo) MarkUserSessionRotated(_ context.Context, sessionID uuid.UUID) (bool, error) {
+	for _, session := range m.Sessions {
+		if session.ID == sessionID {
+			if session.RotatedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			session.RotatedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (m *MockAuthRepo) DeleteUserSession(_ context.Context, hashedToken string) error {
 	delete(m.Sessions, hashedToken)
 	return nil
 }
 
-func (m *MockAuthRepo) DeleteAllUserSessions(_ context.Context, userID uuid.UUID) error {
+func (m *MockAuthRepo) RevokeSessionFamily(_ context.Context, familyID uuid.UUID) ([]repository.RevokedToken, error) {
+	return m.revokeSessions(func(s *repository.UserSession) bool { return s.FamilyID == familyID }), nil
+}
+
+func (m *MockAuthRepo) RevokeAllUserSessions(_ context.Context, userID uuid.UUID) ([]repository.RevokedToken, error) {
+	return m.revokeSessions(func(s *repository.UserSession) bool { return s.UserID == userID }), nil
+}
+
+func (m *MockAuthRepo) revokeSessions(match func(*repository.UserSession) bool) []repository.RevokedToken {
+	var revoked []repository.RevokedToken
+	now := time.Now()
 	for token, session := range m.Sessions {
-		if session.UserID == userID {
-			delete(m.Sessions, token)
+		if !match(session) {
+			continue
+		}
+		delete(m.Sessions, token)
+		if session.AccessTokenID == nil || !session.AccessExpiresAt.After(now) {
+			continue
+		}
+		r := repository.RevokedToken{
+			JTI:       *session.AccessTokenID,
+			UserID:    session.UserID,
+			ExpiresAt: *session.AccessExpiresAt,
+			RevokedAt: now,
+		}
+		m.Revoked[r.JTI] = r
+		revoked = append(revoked, r)
+	}
+	return revoked
+}
+
+func (m *MockAuthRepo) ListRevokedTokens(_ context.Context, since time.Time) ([]repository.RevokedToken, error) {
+	var tokens []repository.RevokedToken
+	for _, r := range m.Revoked {
+		if r.RevokedAt.After(since) && r.ExpiresAt.After(time.Now()) {
+			tokens = append(tokens, r)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *MockAuthRepo) DeleteExpiredRevocations(_ context.Context) error {
+	for jti, r := range m.Revoked {
+		if !r.ExpiresAt.After(time.Now()) {
+			delete(m.Revoked, jti)
 		}
 	}
 	return nil
@@ -192,6 +323,18 @@ func (m *MockAuthRepo) CreateUserToken(_ context.Context, userID uuid.UUID, toke
 	return nil
 }
 
+func (m *MockAuthRepo) CreateEmailChangeToken(_ context.Context, userID uuid.UUID, tokenHash, newEmail string, expiresAt time.Time) error {
+	m.Tokens[tokenHash] = &repository.UserToken{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		Type:      repository.TokenTypeEmailChange,
+		NewEmail:  &newEmail,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
+
 func (m *MockAuthRepo) GetUserTokenByHash(_ context.Context, tokenHash, tokenType string) (*repository.UserToken, error) {
 	token, ok := m.Tokens[tokenHash]
 	if !ok || token.Type != tokenType || token.ExpiresAt.Before(time.Now()) {
@@ -205,6 +348,15 @@ func (m *MockAuthRepo) DeleteUserToken(_ context.Context, tokenHash string) erro
 	return nil
 }
 
+func (m *MockAuthRepo) DeleteUserTokensByType(_ context.Context, userID uuid.UUID, tokenType string) error {
+	for hash, token := range m.Tokens {
+		if token.UserID == userID && token.Type == tokenType {
+			delete(m.Tokens, hash)
+		}
+	}
+	return nil
+}
+
 func (m *MockAuthRepo) VerifyEmail(_ context.Context, userID uuid.UUID) error {
 	for _, user := range m.Users {
 		if user.ID == userID {
@@ -216,6 +368,23 @@ func (m *MockAuthRepo) VerifyEmail(_ context.Context, userID uuid.UUID) error {
 	return common.ErrUserNotFound
 }
 
+func (m *MockAuthRepo) UpdateEmail(_ context.Context, userID uuid.UUID, email string) error {
+	if _, taken := m.Users[email]; taken {
+		return common.ErrUserAlreadyExists
+	}
+	for oldEmail, user := range m.Users {
+		if user.ID == userID {
+			now := time.Now()
+			user.Email = email
+			user.EmailVerifiedAt = &now
+			delete(m.Users, oldEmail)
+			m.Users[email] = user
+			return nil
+		}
+	}
+	return common.ErrUserNotFound
+}
+
 func (m *MockAuthRepo) UpdatePassword(_ context.Context, userID uuid.UUID, hashedPassword string) error {
 	for _, user := range m.Users {
 		if user.ID == userID {
@@ -226,12 +395,115 @@ func (m *MockAuthRepo) UpdatePassword(_ context.Context, userID uuid.UUID, hashe
 	return common.ErrUserNotFound
 }
 
-func (m *MockAuthRepo) CreateOrUpdateOAuthIdentity(_ context.Context, _, _ string, _ uuid.UUID, _, _ *string) error {
+func (m *MockAuthRepo) CreateOrUpdateOAuthIdentity(_ context.Context, providerName, providerUserID string, userID uuid.UUID, _, _ *string) error {
+	m.Identities[providerName+"/"+providerUserID] = userID
 	return nil
 }
 
-func (m *MockAuthRepo) GetUserByOAuthIdentity(_ context.Context, _, _ string) (*repository.User, error) {
-	return nil, common.ErrUserNotFound
+func (m *MockAuthRepo) GetUserByOAuthIdentity(ctx context.Context, providerName, providerUserID string) (*repository.User, error) {
+	userID, ok := m.Identities[providerName+"/"+providerUserID]
+	if !ok {
+		return nil, common.ErrUserNotFound
+	}
+	return m.GetUserByID(ctx, userID)
+}
+
+func (m *MockAuthRepo) CreateOAuthLoginCode(_ context.Context, code repository.OAuthLoginCode) error {
+	m.LoginCodes[code.CodeHash] = code
+	return nil
+}
+
+func (m *MockAuthRepo) ConsumeOAuthLoginCode(_ context.Context, codeHash string) (*repository.OAuthLoginCode, error) {
+	code, ok := m.LoginCodes[codeHash]
+	delete(m.LoginCodes, codeHash)
+	if !ok || !code.ExpiresAt.After(time.Now()) {
+		return nil, common.ErrInvalidToken
+	}
+	return &code, nil
+}
+
+func (m *MockAuthRepo) RecordLoginFailure(_ context.Context, key string, windowStart time.Time) (*repository.LoginAttempts, error) {
+	attempts, ok := m.Attempts[key]
+	if !ok {
+		attempts = &repository.LoginAttempts{Key: key}
+		m.Attempts[key] = attempts
+	}
+	if attempts.LastFailureAt.Before(windowStart) {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = time.Now()
+	clone := *attempts
+	return &clone, nil
+}
+
+func (m *MockAuthRepo) LockLogin(_ context.Context, key string, until time.Time) error {
+	if attempts, ok := m.Attempts[key]; ok {
+		attempts.LockedUntil = &until
+	}
+	return nil
+}
+
+func (m *MockAuthRepo) GetLoginLockout(_ context.Context, keys []string) (*time.Time, error) {
+	var latest *time.Time
+	for _, key := range keys {
+		attempts, ok := m.Attempts[key]
+		if !ok || attempts.LockedUntil == nil || !attempts.LockedUntil.After(time.Now()) {
+			continue
+		}
+		if latest == nil || attempts.LockedUntil.After(*latest) {
+			latest = attempts.LockedUntil
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockAuthRepo) ClearLoginFailures(_ context.Context, key string) error {
+	delete(m.Attempts, key)
+	return nil
+}
+
+func (m *MockAuthRepo) CreateAuthAuditEvent(_ context.Context, event repository.AuthAuditEvent) error {
+	event.ID = int64(len(m.Audit) + 1)
+	event.CreatedAt = time.Now()
+	m.Audit = append(m.Audit, event)
+	return nil
+}
+
+func (m *MockAuthRepo) ListAuthAuditEvents(_ context.Context, filter repository.AuthAuditFilter) ([]repository.AuthAuditEvent, error) {
+	var events []repository.AuthAuditEvent
+	for i := len(m.Audit) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		e := m.Audit[i]
+		if filter.UserID != nil && (e.UserID == nil || *e.UserID != *filter.UserID) ||
+			filter.EventType != "" && e.EventType != filter.EventType ||
+			filter.BeforeID > 0 && e.ID >= filter.BeforeID {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (m *MockAuthRepo) HasLoginFromUserAgent(_ context.Context, userID uuid.UUID, userAgent string) (hasLogins, known bool, err error) {
+	for _, e := range m.Audit {
+		if e.EventType != repository.AuditEventLoginSucceeded || e.UserID == nil || *e.UserID != userID {
+			continue
+		}
+		hasLogins = true
+		if e.UserAgent != nil && *e.UserAgent == userAgent {
+			known = true
+		}
+	}
+	return hasLogins, known, nil
+}
+
+// AuditEvents returns the types of the recorded audit events in order.
+func (m *MockAuthRepo) AuditEvents() []string {
+	types := make([]string, 0, len(m.Audit))
+	for _, e := range m.Audit {
+		types = append(types, e.EventType)
+	}
+	return types
 }
 
 // NewTestAuthService bundles the mocks with a configured AuthService.
@@ -240,7 +512,7 @@ func NewTestAuthService() (*service.AuthService, *MockAuthRepo, *MockTokenManage
 	tokenManager := &MockTokenManager{}
 	emailSender := &MockEmailSender{}
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
-	authService := service.NewAuthService(repo, tokenManager, emailSender, logger, time.Hour)
+	authService := service.NewAuthService(repo, tokenManager, emailSender, nil, logger, time.Hour)
 	return authService, repo, tokenManager, emailSender
 }
 
@@ -253,19 +525,6 @@ func CloneUser(u *repository.User) *repository.User {
 	return &clone
 }
 
-// WaitFor waits for a condition or times out.
-func WaitFor(t *testing.T, condition func() bool) {
-	t.Helper()
-	deadline := time.Now().Add(200 * time.Millisecond)
-	for time.Now().Before(deadline) {
-		if condition() {
-			return
-		}
-		time.Sleep(5 * time.Millisecond)
-	}
-	t.Fatalf("condition not met before timeout")
-}
-
 // MustHash hashes a password for tests.
 func MustHash(t *testing.T, password string) string {
 	t.Helper()
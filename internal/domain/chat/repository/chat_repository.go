@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +44,8 @@ type Repository interface {
 	GetUserChatSessions(ctx context.Context, userID uuid.UUID, page, limit int) (*locitypes.ChatSessionsResponse, error)
 	UpdateSession(ctx context.Context, session locitypes.ChatSession) error
 	AddMessageToSession(ctx context.Context, sessionID uuid.UUID, message locitypes.ConversationMessage) error
+	SaveMessageEmbedding(ctx context.Context, sessionID uuid.UUID, message locitypes.ConversationMessage, embedding []float32) error
+	FindSimilarSessionMessages(ctx context.Context, sessionID uuid.UUID, queryEmbedding []float32, limit int, minSimilarity float64) ([]locitypes.ConversationMessage, error)
 
 	//
 	SaveSinglePOI(ctx context.Context, poi locitypes.POIDetailedInfo, userID, cityID, llmInteractionID uuid.UUID) (uuid.UUID, error)
@@ -102,8 +105,8 @@ func (r *RepositoryImpl) SaveInteraction(ctx context.Context, interaction locity
 
 	interactionQuery := `
         INSERT INTO llm_interactions (
-            user_id, session_id, prompt, response, model_name, latency_ms, city_name
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+            user_id, session_id, prompt, response, model_name, latency_ms, city_name, provider
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'google'))
         RETURNING id
     `
 	var interactionID uuid.UUID
@@ -115,6 +118,7 @@ func (r *RepositoryImpl) SaveInteraction(ctx context.Context, interaction locity
 		interaction.ModelUsed,
 		interaction.LatencyMs,
 		interaction.CityName,
+		interaction.Provider,
 	).Scan(&interactionID)
 	if err != nil {
 		span.RecordError(err)
@@ -1480,6 +1484,90 @@ func (r *RepositoryImpl) AddMessageToSession(ctx context.Context, sessionID uuid
 	return r.UpdateSession(ctx, *session)
 }
 
+// SaveMessageEmbedding stores the embedding of a session message for
+// FindSimilarSessionMessages. Saving a message twice keeps the first embedding.
+func (r *RepositoryImpl) SaveMessageEmbedding(ctx context.Context, sessionID uuid.UUID, message locitypes.ConversationMessage, embedding []float32) error {
+	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "SaveMessageEmbedding", trace.WithAttributes(
+		attribute.String("session.id", sessionID.String()),
+		attribute.String("message.id", message.ID.String()),
+		attribute.Int("embedding.dimension", len(embedding)),
+	))
+	defer span.End()
+
+	query := `
+        INSERT INTO chat_message_embeddings (message_id, session_id, role, content, message_type, created_at, embedding)
+        VALUES ($1, $2, $3, $4, $5, $6, $7::vector)
+        ON CONFLICT (message_id) DO NOTHING
+    `
+	_, err := r.pgpool.Exec(ctx, query, message.ID, sessionID, string(message.Role), message.Content,
+		string(message.MessageType), message.Timestamp, vectorLiteral(embedding))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save message embedding", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database insert failed")
+		return fmt.Errorf("failed to save message embedding: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Message embedding saved")
+	return nil
+}
+
+// FindSimilarSessionMessages returns the messages of a session closest to the query
+// embedding by cosine similarity, most similar first, leaving out those below
+// minSimilarity.
+func (r *RepositoryImpl) FindSimilarSessionMessages(ctx context.Context, sessionID uuid.UUID, queryEmbedding []float32, limit int, minSimilarity float64) ([]locitypes.ConversationMessage, error) {
+	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "FindSimilarSessionMessages", trace.WithAttributes(
+		attribute.String("session.id", sessionID.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	query := `
+        SELECT message_id, role, content, message_type, created_at
+        FROM chat_message_embeddings
+        WHERE session_id = $1 AND 1 - (embedding <=> $2::vector) >= $4
+        ORDER BY embedding <=> $2::vector
+        LIMIT $3
+    `
+	rows, err := r.pgpool.Query(ctx, query, sessionID, vectorLiteral(queryEmbedding), limit, minSimilarity)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to query similar session messages", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to search session messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []locitypes.ConversationMessage
+	for rows.Next() {
+		var message locitypes.ConversationMessage
+		var role, messageType string
+		if err := rows.Scan(&message.ID, &role, &message.Content, &messageType, &message.Timestamp); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan session message: %w", err)
+		}
+		message.Role = locitypes.MessageRole(role)
+		message.MessageType = locitypes.MessageType(messageType)
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating session messages: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(messages)))
+	span.SetStatus(codes.Ok, "Similar session messages found")
+	return messages, nil
+}
+
+// vectorLiteral formats an embedding as a pgvector literal.
+func vectorLiteral(embedding []float32) string {
+	values := make([]string, len(embedding))
+	for i, v := range embedding {
+		values[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
 func (r *RepositoryImpl) SaveSinglePOI(ctx context.Context, poi locitypes.POIDetailedInfo, userID, cityID, llmInteractionID uuid.UUID) (uuid.UUID, error) {
 	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "SaveSinglePOI", trace.WithAttributes(
 		attribute.String("poi.name", poi.Name), /* ... */
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+)
+
+// recordAttemptTimeout bounds the insert; it runs detached from the request context so a
+// cancelled request still leaves a trace of the attempt it made.
+const recordAttemptTimeout = 5 * time.Second
+
+// LLMAttemptRecorder writes failed LLM fallback attempts to llm_interactions.
+type LLMAttemptRecorder struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewLLMAttemptRecorder(pgpool *pgxpool.Pool, logger *slog.Logger) *LLMAttemptRecorder {
+	return &LLMAttemptRecorder{
+		logger: logger,
+		pgpool: pgpool,
+	}
+}
+
+var _ llm.AttemptRecorder = (*LLMAttemptRecorder)(nil)
+
+func (r *LLMAttemptRecorder) RecordAttempt(ctx context.Context, attempt llm.Attempt) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordAttemptTimeout)
+	defer cancel()
+
+	var errorMessage *string
+	if attempt.Err != nil {
+		msg := attempt.Err.Error()
+		errorMessage = &msg
+	}
+
+	_, err := r.pgpool.Exec(ctx, `
+		INSERT INTO llm_interactions (
+			prompt, model_name, provider, status_code, error_message, latency_ms, is_streaming
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		attempt.Prompt,
+		attempt.Model,
+		attempt.Provider,
+		attempt.StatusCode,
+		errorMessage,
+		attempt.Latency.Milliseconds(),
+		attempt.Streaming,
+	)
+	if err != nil {
+		r.logger.WarnContext(ctx, "Failed to record LLM attempt",
+			slog.String("backend", attempt.Backend),
+			slog.Any("error", err))
+	}
+}
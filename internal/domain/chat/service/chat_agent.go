@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+
+	itinerarylist "github.com/FACorreiaa/loci-connect-api/internal/domain/list"
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+const (
+	// agentSemanticWeight balances meaning against distance in search_pois.
+	agentSemanticWeight   = 0.6
+	agentMaxPOIs          = 10
+	defaultAgentRadiusKm  = 5.0
+	defaultNearbyRadiusM  = 1000.0
+	agentDescriptionRunes = 200
+)
+
+// chatAgent holds what the tool-calling agent needs beyond the service's repositories.
+type chatAgent struct {
+	lists    itinerarylist.Service
+	maxSteps int
+}
+
+// WithAgent makes the unified chat answer through the tool-calling agent instead of
+// the fixed prompt fan-out, so replies are grounded in our own POI, city and list
+// data. lists may be nil, which leaves out add_to_list; maxSteps bounds the model
+// calls per message (see llm.AgentOptions).
+func (l *ServiceImpl) WithAgent(lists itinerarylist.Service, maxSteps int) *ServiceImpl {
+	l.agent = &chatAgent{lists: lists, maxSteps: maxSteps}
+	return l
+}
+
+// agentScope is what the tools of one agent run act on. userID is uuid.Nil in the
+// free chat, which leaves out the tools that need an account.
+type agentScope struct {
+	userID       uuid.UUID
+	profileID    uuid.UUID
+	cityName     string
+	userLocation *locitypes.UserLocation
+}
+
+// runChatAgent answers the first message of session with the agent, streaming its
+// tool calls as they happen and the answer as a message event.
+func (l *ServiceImpl) runChatAgent(ctx context.Context, session *locitypes.ChatSession, message string, domain locitypes.DomainType, userLocation *locitypes.UserLocation, eventCh chan<- locitypes.StreamEvent) error {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "runChatAgent", trace.WithAttributes(
+		attribute.String("session.id", session.ID.String()),
+		attribute.String("city.name", session.CityName),
+	))
+	defer span.End()
+
+	scope := agentScope{
+		userID:       session.UserID,
+		profileID:    session.ProfileID,
+		cityName:     session.CityName,
+		userLocation: userLocation,
+	}
+
+	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+		Type: locitypes.EventTypeStart,
+		Data: map[string]interface{}{
+			"domain":     string(domain),
+			"city":       session.CityName,
+			"session_id": session.ID.String(),
+			"mode":       "agent",
+		},
+	}, 3)
+
+	prompt := getAgentPrompt(session.CityName, message, userLocation, scope.userID != uuid.Nil)
+	config := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)}
+	result, err := llm.RunAgent(ctx, l.aiClient, prompt, config, l.agentTools(scope), llm.AgentOptions{
+		Name:     "chat",
+		MaxSteps: l.agent.maxSteps,
+		Logger:   l.logger,
+		OnEvent: func(event llm.AgentEvent) {
+			data := map[string]interface{}{
+				"tool": event.Tool,
+				"step": event.Step,
+			}
+			eventType := locitypes.EventTypeToolCall
+			if event.Type == llm.AgentEventToolResult {
+				eventType = locitypes.EventTypeToolResult
+				data["duration_ms"] = event.Duration.Milliseconds()
+				data["result"] = event.Result
+			} else {
+				data["args"] = event.Args
+			}
+			l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: eventType, Data: data, Error: event.Error}, 3)
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "agent failed")
+		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeError, Error: err.Error(), IsFinal: true}, 3)
+		return fmt.Errorf("chat agent failed: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int("agent.steps", result.Steps),
+		attribute.Int("agent.tool_calls", result.ToolCalls),
+	)
+
+	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+		Type:    locitypes.EventTypeMessage,
+		Message: result.Text,
+		Data: map[string]interface{}{
+			"steps":              result.Steps,
+			"tool_calls":         result.ToolCalls,
+			"step_limit_reached": result.StepLimitReached,
+		},
+	}, 3)
+
+	session.ConversationHistory = append(session.ConversationHistory, locitypes.ConversationMessage{
+		ID:          uuid.New(),
+		Role:        locitypes.RoleAssistant,
+		Content:     result.Text,
+		MessageType: locitypes.TypeResponse,
+		Timestamp:   time.Now(),
+	})
+	session.UpdatedAt = time.Now()
+	if err := l.llmInteractionRepo.UpdateSession(ctx, *session); err != nil {
+		l.logger.WarnContext(ctx, "Failed to save agent reply to session", slog.Any("error", err))
+	}
+
+	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+		Type:    locitypes.EventTypeComplete,
+		Data:    map[string]interface{}{"session_id": session.ID.String()},
+		IsFinal: true,
+	}, 3)
+	span.SetStatus(codes.Ok, "agent answered")
+	return nil
+}
+
+type searchPOIsArgs struct {
+	Query     string   `json:"query" llm:"required,desc=What the user is looking for, e.g. quiet rooftop bars"`
+	City      string   `json:"city" llm:"desc=City to search in; defaults to the city of the conversation"`
+	Latitude  *float64 `json:"latitude" llm:"min=-90,max=90,desc=Search centre; overrides city"`
+	Longitude *float64 `json:"longitude" llm:"min=-180,max=180,desc=Search centre; overrides city"`
+	RadiusKm  float64  `json:"radius_km" llm:"min=0.1,max=50,desc=Search radius in kilometres (default 5)"`
+	Category  string   `json:"category" llm:"desc=Optional category filter, e.g. museum"`
+}
+
+type nearbyArgs struct {
+	Latitude     float64 `json:"latitude" llm:"required,min=-90,max=90"`
+	Longitude    float64 `json:"longitude" llm:"required,min=-180,max=180"`
+	RadiusMeters float64 `json:"radius_meters" llm:"min=50,max=20000,desc=Search radius in metres (default 1000)"`
+	Category     string  `json:"category" llm:"desc=Optional category filter, e.g. cafe"`
+}
+
+type getCityArgs struct {
+	Name string `json:"name" llm:"required,desc=City name; misspellings are tolerated"`
+}
+
+type addToListArgs struct {
+	POIID    string `json:"poi_id" llm:"required,desc=ID of a place returned by search_pois or nearby"`
+	ListName string `json:"list_name" llm:"required,desc=Name of one of the user's lists"`
+	Notes    string `json:"notes" llm:"desc=Optional note saved with the place"`
+}
+
+type noArgs struct{}
+
+// agentPOI is the view of a POI given to the model. The ID lets it pass the place to
+// add_to_list.
+type agentPOI struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Address     string  `json:"address,omitempty"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Rating      float64 `json:"rating,omitempty"`
+	// DistanceKm is measured from the search centre.
+	DistanceKm float64 `json:"distance_km"`
+}
+
+type agentCity struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Country   string  `json:"country"`
+	Summary   string  `json:"summary,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type agentPreferences struct {
+	ProfileName        string   `json:"profile_name"`
+	SearchRadiusKm     float64  `json:"search_radius_km"`
+	BudgetLevel        int      `json:"budget_level"`
+	PreferredPace      string   `json:"preferred_pace,omitempty"`
+	PreferredTime      string   `json:"preferred_time,omitempty"`
+	PreferredTransport string   `json:"preferred_transport,omitempty"`
+	PreferredVibes     []string `json:"preferred_vibes,omitempty"`
+	DietaryNeeds       []string `json:"dietary_needs,omitempty"`
+	Accessible         bool     `json:"prefers_accessible"`
+	OutdoorSeating     bool     `json:"prefers_outdoor_seating"`
+	DogFriendly        bool     `json:"prefers_dog_friendly"`
+	Interests          []string `json:"interests,omitempty"`
+	AvoidTags          []string `json:"avoid_tags,omitempty"`
+}
+
+// agentTools returns the tools available in scope.
+func (l *ServiceImpl) agentTools(scope agentScope) []llm.Tool {
+	tools := []llm.Tool{
+		llm.NewTool("search_pois",
+			"Search our database for places matching a description, ranked by meaning and distance from a centre.",
+			func(ctx context.Context, args searchPOIsArgs) (any, error) {
+				return l.agentSearchPOIs(ctx, scope, args)
+			}),
+		llm.NewTool("nearby",
+			"List the places in our database closest to a point, optionally of one category.",
+			func(ctx context.Context, args nearbyArgs) (any, error) {
+				return l.agentNearby(ctx, args)
+			}),
+		llm.NewTool("get_city",
+			"Look a city up by name and return its centre coordinates and summary.",
+			func(ctx context.Context, args getCityArgs) (any, error) {
+				return l.agentGetCity(ctx, args.Name)
+			}),
+	}
+	if scope.userID == uuid.Nil {
+		return tools
+	}
+	tools = append(tools, llm.NewTool("get_user_preferences",
+		"Return the travel preferences of the signed-in user: interests, budget, pace and tags to avoid.",
+		func(ctx context.Context, _ noArgs) (any, error) {
+			return l.agentPreferences(ctx, scope)
+		}))
+	if l.agent.lists != nil {
+		tools = append(tools, llm.NewTool("add_to_list",
+			"Save a place to one of the user's lists, by the list's name.",
+			func(ctx context.Context, args addToListArgs) (any, error) {
+				return l.agentAddToList(ctx, scope, args)
+			}))
+	}
+	return tools
+}
+
+func (l *ServiceImpl) agentSearchPOIs(ctx context.Context, scope agentScope, args searchPOIsArgs) (any, error) {
+	lat, lon, err := l.agentSearchCentre(ctx, scope, args)
+	if err != nil {
+		return nil, err
+	}
+	radiusKm := args.RadiusKm
+	if radiusKm == 0 {
+		radiusKm = defaultAgentRadiusKm
+	}
+
+	// Without embeddings the search falls back to the places nearest the centre.
+	if l.embeddingService == nil {
+		pois, err := l.nearbyPOIs(ctx, lat, lon, radiusKm*1000, args.Category)
+		if err != nil {
+			return nil, err
+		}
+		return agentPOIs(pois, lat, lon), nil
+	}
+	embedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, args.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed the query: %w", err)
+	}
+	pois, err := l.poiRepo.SearchPOIsHybrid(ctx, locitypes.POIFilter{
+		Location: locitypes.GeoPoint{Latitude: lat, Longitude: lon},
+		Radius:   radiusKm,
+		Category: args.Category,
+	}, embedding, agentSemanticWeight)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return agentPOIs(pois, lat, lon), nil
+}
+
+// agentSearchCentre picks the centre of search_pois: explicit coordinates, then the
+// requested or conversation city, then the user's location.
+func (l *ServiceImpl) agentSearchCentre(ctx context.Context, scope agentScope, args searchPOIsArgs) (lat, lon float64, err error) {
+	if args.Latitude != nil && args.Longitude != nil {
+		return *args.Latitude, *args.Longitude, nil
+	}
+	cityName := args.City
+	if cityName == "" {
+		cityName = scope.cityName
+	}
+	if cityName != "" {
+		city, err := l.cityRepo.FindCityByFuzzyName(ctx, cityName)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to look up %q: %w", cityName, err)
+		}
+		if city != nil && (city.CenterLatitude != 0 || city.CenterLongitude != 0) {
+			return city.CenterLatitude, city.CenterLongitude, nil
+		}
+	}
+	if scope.userLocation != nil {
+		return scope.userLocation.UserLat, scope.userLocation.UserLon, nil
+	}
+	return 0, 0, errors.New("no search centre: pass a known city or latitude and longitude")
+}
+
+func (l *ServiceImpl) agentNearby(ctx context.Context, args nearbyArgs) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultNearbyRadiusM
+	}
+	pois, err := l.nearbyPOIs(ctx, args.Latitude, args.Longitude, radius, args.Category)
+	if err != nil {
+		return nil, err
+	}
+	return agentPOIs(pois, args.Latitude, args.Longitude), nil
+}
+
+func (l *ServiceImpl) nearbyPOIs(ctx context.Context, lat, lon, radiusMeters float64, category string) ([]locitypes.POIDetailedInfo, error) {
+	var (
+		pois []locitypes.POIDetailedInfo
+		err  error
+	)
+	if category == "" {
+		pois, err = l.poiRepo.GetPOIsByLocationAndDistance(ctx, lat, lon, radiusMeters)
+	} else {
+		pois, err = l.poiRepo.GetPOIsByLocationAndDistanceWithCategory(ctx, lat, lon, radiusMeters, category)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nearby lookup failed: %w", err)
+	}
+	return pois, nil
+}
+
+func (l *ServiceImpl) agentGetCity(ctx context.Context, name string) (any, error) {
+	city, err := l.cityRepo.FindCityByFuzzyName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %q: %w", name, err)
+	}
+	if city == nil {
+		return nil, fmt.Errorf("no city matches %q", name)
+	}
+	return agentCity{
+		ID:        city.ID.String(),
+		Name:      city.Name,
+		Country:   city.Country,
+		Summary:   city.AiSummary,
+		Latitude:  city.CenterLatitude,
+		Longitude: city.CenterLongitude,
+	}, nil
+}
+
+func (l *ServiceImpl) agentPreferences(ctx context.Context, scope agentScope) (any, error) {
+	interests, profile, tags, err := l.FetchUserData(ctx, scope.userID, scope.profileID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, errors.New("the user has no search profile")
+	}
+	prefs := agentPreferences{
+		ProfileName:        profile.ProfileName,
+		SearchRadiusKm:     profile.SearchRadiusKm,
+		BudgetLevel:        profile.BudgetLevel,
+		PreferredPace:      string(profile.PreferredPace),
+		PreferredTime:      string(profile.PreferredTime),
+		PreferredTransport: string(profile.PreferredTransport),
+		PreferredVibes:     profile.PreferredVibes,
+		DietaryNeeds:       profile.DietaryNeeds,
+		Accessible:         profile.PreferAccessiblePOIs,
+		OutdoorSeating:     profile.PreferOutdoorSeating,
+		DogFriendly:        profile.PreferDogFriendly,
+	}
+	for _, interest := range interests {
+		if interest != nil {
+			prefs.Interests = append(prefs.Interests, interest.Name)
+		}
+	}
+	for _, tag := range tags {
+		if tag != nil {
+			prefs.AvoidTags = append(prefs.AvoidTags, tag.Name)
+		}
+	}
+	return prefs, nil
+}
+
+func (l *ServiceImpl) agentAddToList(ctx context.Context, scope agentScope, args addToListArgs) (any, error) {
+	poiID, err := uuid.Parse(args.POIID)
+	if err != nil {
+		return nil, fmt.Errorf("poi_id %q is not a place ID from a search", args.POIID)
+	}
+
+	var (
+		names []string
+		list  *locitypes.List
+	)
+	for _, itineraries := range []bool{false, true} {
+		lists, err := l.agent.lists.GetUserLists(ctx, scope.userID, itineraries)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range lists {
+			names = append(names, candidate.Name)
+			if list == nil && strings.EqualFold(strings.TrimSpace(candidate.Name), strings.TrimSpace(args.ListName)) {
+				list = candidate
+			}
+		}
+	}
+	if list == nil {
+		if len(names) == 0 {
+			return nil, errors.New("the user has no lists; ask them to create one first")
+		}
+		return nil, fmt.Errorf("no list named %q; the user's lists are: %s", args.ListName, strings.Join(names, ", "))
+	}
+
+	details, err := l.agent.lists.GetListDetails(ctx, list.ID, scope.userID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := l.agent.lists.AddListItem(ctx, scope.userID, list.ID, locitypes.AddListItemRequest{
+		ItemID:      poiID,
+		ContentType: locitypes.ContentTypePOI,
+		Position:    len(details.Items),
+		Notes:       args.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"added": true, "list": list.Name, "position": item.Position}, nil
+}
+
+func agentPOIs(pois []locitypes.POIDetailedInfo, lat, lon float64) []agentPOI {
+	out := make([]agentPOI, 0, min(len(pois), agentMaxPOIs))
+	for _, p := range pois {
+		if len(out) == agentMaxPOIs {
+			break
+		}
+		description := p.DescriptionPOI
+		if runes := []rune(description); len(runes) > agentDescriptionRunes {
+			description = string(runes[:agentDescriptionRunes]) + "…"
+		}
+		out = append(out, agentPOI{
+			ID:          p.ID.String(),
+			Name:        p.Name,
+			Category:    p.Category,
+			Description: description,
+			Address:     p.Address,
+			Latitude:    p.Latitude,
+			Longitude:   p.Longitude,
+			Rating:      p.Rating,
+			DistanceKm:  math.Round(distanceKm(lat, lon, p.Latitude, p.Longitude)*100) / 100,
+		})
+	}
+	return out
+}
+
+// distanceKm is the great-circle distance between two points.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/chat/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/poi"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// agentPOIRepo serves nearby lookups from a fixed set of POIs.
+type agentPOIRepo struct {
+	poi.Repository
+	pois       []locitypes.POIDetailedInfo
+	lat, lon   float64
+	radiusSeen float64
+}
+
+func (r *agentPOIRepo) GetPOIsByLocationAndDistance(_ context.Context, lat, lon, radiusMeters float64) ([]locitypes.POIDetailedInfo, error) {
+	r.lat, r.lon, r.radiusSeen = lat, lon, radiusMeters
+	return r.pois, nil
+}
+
+type agentCityRepo struct {
+	city.Repository
+}
+
+func (agentCityRepo) FindCityByFuzzyName(_ context.Context, name string) (*locitypes.CityDetail, error) {
+	if !strings.EqualFold(name, "madrid") {
+		return nil, nil
+	}
+	return &locitypes.CityDetail{ID: uuid.New(), Name: "Madrid", Country: "Spain", CenterLatitude: 40.4168, CenterLongitude: -3.7038}, nil
+}
+
+type agentSessionRepo struct {
+	repository.Repository
+	updated []locitypes.ChatSession
+}
+
+func (r *agentSessionRepo) UpdateSession(_ context.Context, session locitypes.ChatSession) error {
+	r.updated = append(r.updated, session)
+	return nil
+}
+
+func TestRunChatAgent_GroundsAnswerInToolResults(t *testing.T) {
+	prado := locitypes.POIDetailedInfo{ID: uuid.New(), Name: "Museo del Prado", Category: "museum", Latitude: 40.4138, Longitude: -3.6921}
+	pois := &agentPOIRepo{pois: []locitypes.POIDetailedInfo{prado}}
+	sessions := &agentSessionRepo{}
+
+	var prompts []string
+	client := &TestLLMClient{GenerateResponseFn: func(_ context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			names := make([]string, 0)
+			for _, decl := range config.Tools[0].FunctionDeclarations {
+				names = append(names, decl.Name)
+			}
+			// The free chat has no account, so only the lookup tools are offered.
+			assert.Equal(t, []string{"search_pois", "nearby", "get_city"}, names)
+			return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []*genai.Part{{
+				FunctionCall: &genai.FunctionCall{Name: "search_pois", Args: map[string]any{"query": "art museums"}},
+			}}}}}}, nil
+		}
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText("Visit the Museo del Prado.", genai.RoleModel)}}}, nil
+	}}
+
+	svc := &ServiceImpl{
+		logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		aiClient:           client,
+		poiRepo:            pois,
+		cityRepo:           agentCityRepo{},
+		llmInteractionRepo: sessions,
+		deadLetterCh:       make(chan locitypes.StreamEvent, 100),
+		agent:              &chatAgent{maxSteps: 3},
+	}
+	session := &locitypes.ChatSession{ID: uuid.New(), CityName: "Madrid"}
+	eventCh := make(chan locitypes.StreamEvent, 100)
+
+	err := svc.runChatAgent(context.Background(), session, "art museums", locitypes.DomainGeneral, nil, eventCh)
+	require.NoError(t, err)
+	close(eventCh)
+
+	// Without embeddings search_pois falls back to the POIs nearest the city centre.
+	assert.InDelta(t, 40.4168, pois.lat, 1e-9)
+	assert.InDelta(t, defaultAgentRadiusKm*1000, pois.radiusSeen, 1e-9)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[1], prado.ID.String())
+
+	seen := map[string]locitypes.StreamEvent{}
+	for event := range eventCh {
+		seen[event.Type] = event
+	}
+	for _, eventType := range []string{locitypes.EventTypeStart, locitypes.EventTypeToolCall, locitypes.EventTypeToolResult, locitypes.EventTypeMessage, locitypes.EventTypeComplete} {
+		assert.Contains(t, seen, eventType)
+	}
+	assert.Equal(t, "search_pois", seen[locitypes.EventTypeToolCall].Data.(map[string]interface{})["tool"])
+	results := seen[locitypes.EventTypeToolResult].Data.(map[string]interface{})["result"].([]agentPOI)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Museo del Prado", results[0].Name)
+	assert.InDelta(t, 1.0, results[0].DistanceKm, 0.1)
+	assert.Equal(t, "Visit the Museo del Prado.", seen[locitypes.EventTypeMessage].Message)
+
+	require.NotEmpty(t, sessions.updated)
+	history := sessions.updated[0].ConversationHistory
+	require.Len(t, history, 1)
+	assert.Equal(t, locitypes.RoleAssistant, history[0].Role)
+}
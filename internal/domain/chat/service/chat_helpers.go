@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"regexp"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 )
 
@@ -79,7 +79,7 @@ func (l *ServiceImpl) ProcessAndSaveUnifiedResponse(
 	if activitiesContent, ok := responses["activities"]; ok && activitiesContent.Len() > 0 {
 		l.logger.InfoContext(ctx, "Processing activities POIs from unified response",
 			slog.Int("content_length", activitiesContent.Len()))
-		l.handleGeneralPoisFromResponse(ctx, activitiesContent.String(), cityID)
+		l.handleActivitiesFromResponse(ctx, activitiesContent.String(), cityID)
 	}
 
 	// Process hotel POIs if available (for DomainAccommodation)
@@ -126,7 +126,7 @@ func (l *ServiceImpl) ProcessAndSaveUnifiedResponseFree(
 	if activitiesContent, ok := responses["activities"]; ok && activitiesContent.Len() > 0 {
 		l.logger.InfoContext(ctx, "Processing activities POIs from unified response",
 			slog.Int("content_length", activitiesContent.Len()))
-		l.handleGeneralPoisFromResponse(ctx, activitiesContent.String(), cityID)
+		l.handleActivitiesFromResponse(ctx, activitiesContent.String(), cityID)
 	}
 
 	// Process hotel POIs if available (for DomainAccommodation)
@@ -145,10 +145,8 @@ func (l *ServiceImpl) ProcessAndSaveUnifiedResponseFree(
 }
 
 func (l *ServiceImpl) handleGeneralPoisFromResponse(ctx context.Context, content string, cityID uuid.UUID) {
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(CleanJSONResponse(content)), &poiData); err != nil {
+	poiData, _, err := llm.DecodeStructured[locitypes.POIListResponse](content)
+	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to parse general POIs from unified response", slog.Any("error", err))
 		return
 	}
@@ -156,6 +154,16 @@ func (l *ServiceImpl) handleGeneralPoisFromResponse(ctx context.Context, content
 	l.HandleGeneralPOIs(ctx, poiData.PointsOfInterest, cityID)
 }
 
+func (l *ServiceImpl) handleActivitiesFromResponse(ctx context.Context, content string, cityID uuid.UUID) {
+	activityData, _, err := llm.DecodeStructured[locitypes.ActivityListResponse](content)
+	if err != nil {
+		l.logger.ErrorContext(ctx, "Failed to parse activities from unified response", slog.Any("error", err))
+		return
+	}
+
+	l.HandleGeneralPOIs(ctx, activityData.Activities, cityID)
+}
+
 func (l *ServiceImpl) handleItineraryFromResponse(
 	ctx context.Context,
 	content string,
@@ -163,29 +171,22 @@ func (l *ServiceImpl) handleItineraryFromResponse(
 	llmInteractionID uuid.UUID,
 	userLocation *locitypes.UserLocation,
 ) {
-	var itineraryData struct {
-		ItineraryName      string                      `json:"itinerary_name"`
-		OverallDescription string                      `json:"overall_description"`
-		PointsOfInterest   []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(CleanJSONResponse(content)), &itineraryData); err != nil {
+	itineraryData, _, err := llm.DecodeStructured[locitypes.AIItineraryResponse](content)
+	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to parse itinerary from unified response", slog.Any("error", err))
 		return
 	}
 
 	// Save the itinerary and its POIs
-	_, err := l.HandlePersonalisedPOIs(ctx, itineraryData.PointsOfInterest, cityID, userLocation, llmInteractionID, userID, profileID)
+	_, err = l.HandlePersonalisedPOIs(ctx, itineraryData.PointsOfInterest, cityID, userLocation, llmInteractionID, userID, profileID)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to save personalised POIs from unified response", slog.Any("error", err))
 	}
 }
 
 func (l *ServiceImpl) handleHotelsFromResponse(ctx context.Context, content string, cityID, _, llmInteractionID uuid.UUID) {
-	var hotelData struct {
-		Hotels []locitypes.HotelDetailedInfo `json:"hotels"`
-	}
-	clean := CleanJSONResponse(content)
-	if err := json.Unmarshal([]byte(clean), &hotelData); err != nil {
+	hotelData, _, err := llm.DecodeStructured[locitypes.HotelListResponse](content)
+	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to parse hotels from unified response", slog.Any("error", err))
 		return
 	}
@@ -203,11 +204,8 @@ func (l *ServiceImpl) handleHotelsFromResponse(ctx context.Context, content stri
 }
 
 func (l *ServiceImpl) handleRestaurantsFromResponse(ctx context.Context, content string, cityID, _, llmInteractionID uuid.UUID) {
-	var restaurantData struct {
-		Restaurants []locitypes.RestaurantDetailedInfo `json:"restaurants"`
-	}
-	clean := CleanJSONResponse(content)
-	if err := json.Unmarshal([]byte(clean), &restaurantData); err != nil {
+	restaurantData, clean, err := llm.DecodeStructured[locitypes.RestaurantListResponse](content)
+	if err != nil {
 		l.logger.ErrorContext(ctx, "Failed to parse restaurants from unified response", slog.Any("error", err), slog.String("cleaned_response", clean))
 		return
 	}
@@ -250,63 +248,3 @@ func CleanLLMResponse(responseText string) string {
 
 	return cleaned
 }
-
-// CleanJSONResponse performs advanced cleaning of LLM JSON responses.
-// It handles markdown code blocks, extracts valid JSON by brace counting,
-// and removes extraneous content before/after the JSON object.
-// Use this for more robust JSON extraction from LLM responses.
-func CleanJSONResponse(response string) string {
-	response = strings.TrimSpace(response)
-
-	// Remove markdown code block markers
-	if strings.HasPrefix(response, "```json") {
-		response = strings.TrimPrefix(response, "```json")
-	} else if strings.HasPrefix(response, "```") {
-		response = strings.TrimPrefix(response, "```")
-	}
-
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
-
-	firstBrace := strings.Index(response, "{")
-	if firstBrace == -1 {
-		return response // No JSON found, return as is
-	}
-
-	// Find the matching closing brace by counting braces
-	braceCount := 0
-	var lastValidBrace int
-	for i := firstBrace; i < len(response); i++ {
-		switch response[i] {
-		case '{':
-			braceCount++
-		case '}':
-			braceCount--
-			if braceCount == 0 {
-				lastValidBrace = i
-				break
-			}
-		}
-	}
-
-	if braceCount != 0 {
-		// Fallback to last brace method if brace counting fails
-		lastBrace := strings.LastIndex(response, "}")
-		if lastBrace == -1 || lastBrace <= firstBrace {
-			return response // No valid JSON structure found
-		}
-		lastValidBrace = lastBrace
-	}
-
-	// Extract the JSON portion
-	jsonPortion := response[firstBrace : lastValidBrace+1]
-
-	// Remove any remaining backticks that might be within the JSON content
-	// This handles cases where the AI includes markdown formatting within JSON strings
-	jsonPortion = strings.ReplaceAll(jsonPortion, "`", "")
-
-	// Remove trailing commas before closing braces/brackets (common LLM error)
-	jsonPortion = regexp.MustCompile(`,(\s*[}\]])`).ReplaceAllString(jsonPortion, "$1")
-
-	return strings.TrimSpace(jsonPortion)
-}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	a "github.com/petar-dambovaliev/aho-corasick"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+)
+
+// Keys of the entities the intent classifier puts in Intent.Entities.
+const (
+	EntityPOIName     = "poi_name"    // the place to add, remove or replace
+	EntityReplacement = "replacement" // the place replacing poi_name
+	EntityDay         = "day"         // an int, 1 for the first day of the trip
+	EntityTime        = "time"        // e.g. "3pm", "15:30" or "evening"
+	EntityBudget      = "budget"      // an amount with its currency, e.g. "€50"
+)
+
+const (
+	// minIntentConfidence is the confidence below which the classifier tries its next
+	// layer, and below which ContinueSessionStreamed asks what the user meant.
+	minIntentConfidence = 0.6
+	// keywordIntentConfidence is given to messages with the keywords of one intent only.
+	keywordIntentConfidence = 0.85
+	// A message takes the intent of its nearest labelled example when the example is at
+	// least embeddingIntentMinScore similar, and embeddingIntentMargin more similar than
+	// the nearest example of any other intent.
+	embeddingIntentMinScore = 0.75
+	embeddingIntentMargin   = 0.05
+	// intentExamplesTimeout bounds the background embedding of the labelled examples.
+	intentExamplesTimeout = time.Minute
+)
+
+// intentKeywords are the words that give a message away. A message naming those of
+// a single intent is classified without further work.
+var intentKeywords = []struct {
+	keyword string
+	intent  locitypes.IntentType
+}{
+	{"add", locitypes.IntentAddPOI}, {"include", locitypes.IntentAddPOI}, {"visit", locitypes.IntentAddPOI},
+	{"insert", locitypes.IntentAddPOI}, {"squeeze in", locitypes.IntentAddPOI}, {"fit in", locitypes.IntentAddPOI},
+
+	{"remove", locitypes.IntentRemovePOI}, {"delete", locitypes.IntentRemovePOI}, {"skip", locitypes.IntentRemovePOI},
+	{"drop", locitypes.IntentRemovePOI}, {"get rid of", locitypes.IntentRemovePOI}, {"take out", locitypes.IntentRemovePOI},
+
+	{"replace", locitypes.IntentReplacePOI}, {"swap", locitypes.IntentReplacePOI},
+	{"instead of", locitypes.IntentReplacePOI}, {"substitute", locitypes.IntentReplacePOI},
+
+	{"change", locitypes.IntentModifyItinerary}, {"reorder", locitypes.IntentModifyItinerary},
+	{"rearrange", locitypes.IntentModifyItinerary}, {"move", locitypes.IntentModifyItinerary},
+	{"reschedule", locitypes.IntentModifyItinerary}, {"shorten", locitypes.IntentModifyItinerary},
+
+	{"what", locitypes.IntentAskQuestion}, {"where", locitypes.IntentAskQuestion}, {"how", locitypes.IntentAskQuestion},
+	{"why", locitypes.IntentAskQuestion}, {"when", locitypes.IntentAskQuestion}, {"which", locitypes.IntentAskQuestion},
+	{"is there", locitypes.IntentAskQuestion}, {"are there", locitypes.IntentAskQuestion}, {"tell me", locitypes.IntentAskQuestion},
+}
+
+// intentPriority breaks ties between the intents of a message with mixed keywords,
+// most specific first.
+var intentPriority = []locitypes.IntentType{
+	locitypes.IntentReplacePOI,
+	locitypes.IntentRemovePOI,
+	locitypes.IntentAddPOI,
+	locitypes.IntentModifyItinerary,
+	locitypes.IntentAskQuestion,
+}
+
+var intentKeywordMatcher = func() a.AhoCorasick {
+	patterns := make([]string, len(intentKeywords))
+	for i, k := range intentKeywords {
+		patterns[i] = k.keyword
+	}
+	builder := a.NewAhoCorasickBuilder(a.Opts{
+		AsciiCaseInsensitive: true,
+		MatchOnlyWholeWords:  true,
+		MatchKind:            a.LeftMostLongestMatch,
+	})
+	return builder.Build(patterns)
+}()
+
+// intentExamples are labelled messages; a message is compared with their embeddings
+// when its keywords do not settle the intent.
+var intentExamples = []struct {
+	intent locitypes.IntentType
+	text   string
+}{
+	{locitypes.IntentAddPOI, "I'd also like to see the cathedral"},
+	{locitypes.IntentAddPOI, "Put a wine tasting in the plan"},
+	{locitypes.IntentAddPOI, "Can we go to the aquarium too?"},
+	{locitypes.IntentAddPOI, "Throw in a rooftop bar on the last evening"},
+	{locitypes.IntentAddPOI, "I want a food market somewhere on day two"},
+	{locitypes.IntentAddPOI, "Let's also stop by the botanical garden"},
+
+	{locitypes.IntentRemovePOI, "I'm not interested in the museum anymore"},
+	{locitypes.IntentRemovePOI, "Forget about the castle"},
+	{locitypes.IntentRemovePOI, "We don't need the boat tour"},
+	{locitypes.IntentRemovePOI, "Leave out the shopping street"},
+	{locitypes.IntentRemovePOI, "No more churches please"},
+	{locitypes.IntentRemovePOI, "Cut the zoo from the plan"},
+
+	{locitypes.IntentReplacePOI, "Could we do the beach rather than the old town?"},
+	{locitypes.IntentReplacePOI, "Trade the art gallery for a cooking class"},
+	{locitypes.IntentReplacePOI, "Go to the market in place of the palace"},
+	{locitypes.IntentReplacePOI, "Exchange the bus tour for a bike tour"},
+
+	{locitypes.IntentModifyItinerary, "Make the second day less busy"},
+	{locitypes.IntentModifyItinerary, "Start later in the mornings"},
+	{locitypes.IntentModifyItinerary, "Put the places closest to the hotel first"},
+	{locitypes.IntentModifyItinerary, "Make it a cheaper trip"},
+	{locitypes.IntentModifyItinerary, "Spread the visits over three days"},
+	{locitypes.IntentModifyItinerary, "Keep the afternoons free"},
+
+	{locitypes.IntentAskQuestion, "Is the castle open on Mondays?"},
+	{locitypes.IntentAskQuestion, "Do I need tickets for the tower"},
+	{locitypes.IntentAskQuestion, "Can I walk between the first two stops"},
+	{locitypes.IntentAskQuestion, "Are the museums free on Sundays"},
+	{locitypes.IntentAskQuestion, "Is it safe at night around the harbour"},
+	{locitypes.IntentAskQuestion, "Should I book the restaurant in advance"},
+}
+
+type intentExample struct {
+	intent    locitypes.IntentType
+	embedding []float32
+}
+
+// LayeredIntentClassifier classifies the messages of continued chat sessions in three
+// layers, each tried only while the cheaper ones are unsure:
+//
+//  1. keywords: one Aho-Corasick scan for the verbs of each intent;
+//  2. embeddings: the nearest of the labelled intentExamples;
+//  3. the LLM, asked for the intent and its confidence with a response schema.
+//
+// Entities (see the Entity keys) are taken from the message with patterns, or from
+// the LLM when it was asked. When every layer is unsure, the best guess is returned
+// with a low confidence and RequiredAction is ActionRequestClarification.
+type LayeredIntentClassifier struct {
+	chat       llm.ChatClient
+	embeddings llm.EmbeddingClient
+	logger     *slog.Logger
+
+	examples atomic.Pointer[[]intentExample]
+	warming  atomic.Bool
+}
+
+// NewLayeredIntentClassifier returns a classifier asking chat when its keywords and
+// embeddings are unsure. Either client may be nil to skip its layer.
+func NewLayeredIntentClassifier(chat llm.ChatClient, embeddings llm.EmbeddingClient, logger *slog.Logger) *LayeredIntentClassifier {
+	return &LayeredIntentClassifier{chat: chat, embeddings: embeddings, logger: logger}
+}
+
+// Classify classifies message; conversation is the rendered context of the earlier
+// turns, given to the LLM layer only. Failing layers are skipped, so Classify returns
+// an error only when ctx is done.
+func (c *LayeredIntentClassifier) Classify(ctx context.Context, message, conversation string) (locitypes.Intent, error) {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "classifyIntent")
+	defer span.End()
+
+	intent, layer := classifyByKeywords(message), "keyword"
+	if intent.Confidence < minIntentConfidence {
+		if nearest, ok := c.classifyByEmbedding(ctx, message); ok {
+			intent, layer = nearest, "embedding"
+		}
+	}
+	var llmEntities map[string]interface{}
+	if intent.Confidence < minIntentConfidence && c.chat != nil {
+		asked, err := c.classifyWithLLM(ctx, message, conversation)
+		if err != nil {
+			c.logger.WarnContext(ctx, "LLM intent classification failed, keeping the best guess",
+				slog.String("guess", string(intent.Type)),
+				slog.Any("error", err))
+		} else {
+			intent, layer = asked, "llm"
+			llmEntities = asked.Entities
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return locitypes.Intent{}, err
+	}
+
+	intent.Entities = extractIntentEntities(message, intent.Type)
+	for k, v := range llmEntities {
+		intent.Entities[k] = v
+	}
+	intent.RequiredAction = requiredAction(intent)
+	if intent.RequiredAction == locitypes.ActionRequestClarification {
+		layer = "unsure"
+	}
+
+	observability.ChatIntentClassifications.WithLabelValues(layer, string(intent.Type)).Inc()
+	span.SetAttributes(
+		attribute.String("intent.layer", layer),
+		attribute.String("intent.type", string(intent.Type)),
+		attribute.Float64("intent.confidence", intent.Confidence),
+	)
+	return intent, nil
+}
+
+// classifyByKeywords returns the intent of the keywords of message. Keywords of
+// several intents, or none, give a guess below minIntentConfidence.
+func classifyByKeywords(message string) locitypes.Intent {
+	found := make(map[locitypes.IntentType]bool)
+	for _, m := range intentKeywordMatcher.FindAll(message) {
+		found[intentKeywords[m.Pattern()].intent] = true
+	}
+	switch len(found) {
+	case 0:
+		return locitypes.Intent{Type: locitypes.IntentModifyItinerary, Confidence: 0.3}
+	case 1:
+		for intent := range found {
+			return locitypes.Intent{Type: intent, Confidence: keywordIntentConfidence}
+		}
+	}
+	for _, intent := range intentPriority {
+		if found[intent] {
+			return locitypes.Intent{Type: intent, Confidence: 0.45}
+		}
+	}
+	return locitypes.Intent{Type: locitypes.IntentModifyItinerary, Confidence: 0.3}
+}
+
+// classifyByEmbedding returns the intent of the labelled example nearest to message,
+// if it is near enough and clearly nearer than the examples of other intents.
+func (c *LayeredIntentClassifier) classifyByEmbedding(ctx context.Context, message string) (locitypes.Intent, bool) {
+	examples := c.labelledExamples(ctx)
+	if len(examples) == 0 {
+		return locitypes.Intent{}, false
+	}
+	embedding, err := c.embeddings.GenerateQueryEmbedding(ctx, message)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to embed message for intent classification", slog.Any("error", err))
+		return locitypes.Intent{}, false
+	}
+
+	nearest := make(map[locitypes.IntentType]float64)
+	for _, ex := range examples {
+		if score := cosineSimilarity(embedding, ex.embedding); score > nearest[ex.intent] {
+			nearest[ex.intent] = score
+		}
+	}
+	var best locitypes.IntentType
+	var bestScore, runnerUp float64
+	for intent, score := range nearest {
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = intent, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < embeddingIntentMinScore || bestScore-runnerUp < embeddingIntentMargin {
+		return locitypes.Intent{}, false
+	}
+	return locitypes.Intent{Type: best, Confidence: min(bestScore, 0.95)}, true
+}
+
+// labelledExamples returns the embedded intentExamples, or nil while they are being
+// embedded in the background. A failed attempt is retried on a later message.
+func (c *LayeredIntentClassifier) labelledExamples(ctx context.Context) []intentExample {
+	if c.embeddings == nil {
+		return nil
+	}
+	if examples := c.examples.Load(); examples != nil {
+		return *examples
+	}
+	if c.warming.CompareAndSwap(false, true) {
+		go c.embedExamples(context.WithoutCancel(ctx))
+	}
+	return nil
+}
+
+func (c *LayeredIntentClassifier) embedExamples(ctx context.Context) {
+	defer c.warming.Store(false)
+	ctx, cancel := context.WithTimeout(ctx, intentExamplesTimeout)
+	defer cancel()
+
+	examples := make([]intentExample, 0, len(intentExamples))
+	for _, ex := range intentExamples {
+		embedding, err := c.embeddings.GenerateQueryEmbedding(ctx, ex.text)
+		if err != nil {
+			c.logger.WarnContext(ctx, "Failed to embed intent examples", slog.Any("error", err))
+			return
+		}
+		examples = append(examples, intentExample{intent: ex.intent, embedding: embedding})
+	}
+	c.examples.Store(&examples)
+}
+
+// intentReply is the response schema of the LLM layer.
+type intentReply struct {
+	Intent      string  `json:"intent" llm:"required,enum=add_poi|remove_poi|replace_poi|modify_itinerary|ask_question"`
+	Confidence  float64 `json:"confidence" llm:"required,min=0,max=1,desc=How sure you are of the intent, from 0 to 1"`
+	POIName     string  `json:"poi_name,omitempty" llm:"desc=The place to add, remove or replace, as the user named it"`
+	Replacement string  `json:"replacement,omitempty" llm:"desc=The place replacing poi_name, for replace_poi"`
+	Day         int     `json:"day,omitempty" llm:"min=0,max=60,desc=The day of the trip the message is about, 1 for the first; 0 when none"`
+	Time        string  `json:"time,omitempty" llm:"desc=The time of day the message mentions, e.g. 3pm or evening"`
+	Budget      string  `json:"budget,omitempty" llm:"desc=The amount of money the message mentions, with its currency"`
+}
+
+func (c *LayeredIntentClassifier) classifyWithLLM(ctx context.Context, message, conversation string) (locitypes.Intent, error) {
+	result, err := llm.GenerateStructured[intentReply](ctx, c.chat, getIntentClassificationPrompt(conversation, message),
+		&genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.1)},
+		llm.StructuredOptions{Name: "intent_classification", Logger: c.logger})
+	if err != nil {
+		return locitypes.Intent{}, fmt.Errorf("failed to classify intent: %w", err)
+	}
+	reply := result.Value
+
+	entities := make(map[string]interface{})
+	for key, value := range map[string]string{
+		EntityPOIName:     reply.POIName,
+		EntityReplacement: reply.Replacement,
+		EntityTime:        reply.Time,
+		EntityBudget:      reply.Budget,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			entities[key] = value
+		}
+	}
+	if reply.Day > 0 {
+		entities[EntityDay] = reply.Day
+	}
+	return locitypes.Intent{
+		Type:       locitypes.IntentType(reply.Intent),
+		Confidence: math.Max(0, math.Min(reply.Confidence, 1)),
+		Entities:   entities,
+	}, nil
+}
+
+func requiredAction(intent locitypes.Intent) locitypes.ActionType {
+	switch {
+	case intent.Confidence < minIntentConfidence:
+		return locitypes.ActionRequestClarification
+	case intent.Type == locitypes.IntentAskQuestion:
+		return locitypes.ActionProvideInfo
+	default:
+		return locitypes.ActionUpdateExisting
+	}
+}
+
+var (
+	dayNumberPattern  = regexp.MustCompile(`(?i)\bday\s+(\d{1,2})\b`)
+	dayOrdinalPattern = regexp.MustCompile(`(?i)\b(first|second|third|fourth|fifth|sixth|seventh|\d{1,2}(?:st|nd|rd|th))\s+day\b`)
+	timePattern       = regexp.MustCompile(`(?i)\b(\d{1,2}(?::\d{2})?\s?[ap]\.?m\b\.?|\d{1,2}:\d{2}|noon|midnight|morning|afternoon|evening|tonight)`)
+	budgetPattern     = regexp.MustCompile(`(?i)[$€£]\s?\d+(?:[.,]\d+)?|\b\d+(?:[.,]\d+)?\s?(?:€|\$|£|(?:eur|euros?|usd|dollars?|gbp|pounds?)\b)`)
+	replacePattern    = regexp.MustCompile(`(?i)\b(?:replace|swap|substitute)\s+(.+?)\s+(?:with|for)\s+(.+)`)
+	poiActionPattern  = regexp.MustCompile(`(?i)\b(?:add|include|visit|insert|squeeze in|fit in|remove|delete|skip|drop|get rid of|take out)\s+(.+)`)
+	// poiTailPattern cuts what follows a place name: where it goes in the plan, or when.
+	poiTailPattern = regexp.MustCompile(`(?i)\s+(?:(?:to|from|on|in|into|for|off|of)\s+(?:my|the|our|this|that|day|\d)|(?:at|around|under|before|after|by|please)\b).*$`)
+	articlePattern = regexp.MustCompile(`(?i)^(?:the|a|an|some)\s+`)
+)
+
+var ordinalDays = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6, "seventh": 7}
+
+// extractIntentEntities finds the entities of a message of the given intent.
+func extractIntentEntities(message string, intent locitypes.IntentType) map[string]interface{} {
+	entities := make(map[string]interface{})
+	if m := dayNumberPattern.FindStringSubmatch(message); m != nil {
+		if day, err := strconv.Atoi(m[1]); err == nil && day > 0 {
+			entities[EntityDay] = day
+		}
+	} else if m := dayOrdinalPattern.FindStringSubmatch(message); m != nil {
+		word := strings.ToLower(m[1])
+		day, ok := ordinalDays[word]
+		if !ok {
+			day, _ = strconv.Atoi(strings.TrimRight(word, "stndrh"))
+		}
+		if day > 0 {
+			entities[EntityDay] = day
+		}
+	}
+	if m := timePattern.FindString(message); m != "" {
+		entities[EntityTime] = strings.ToLower(m)
+	}
+	if m := budgetPattern.FindString(message); m != "" {
+		entities[EntityBudget] = m
+	}
+
+	switch intent {
+	case locitypes.IntentReplacePOI, locitypes.IntentModifyItinerary:
+		if m := replacePattern.FindStringSubmatch(message); m != nil {
+			setPOIEntity(entities, EntityPOIName, m[1])
+			setPOIEntity(entities, EntityReplacement, m[2])
+		}
+	case locitypes.IntentAddPOI, locitypes.IntentRemovePOI:
+		if m := poiActionPattern.FindStringSubmatch(message); m != nil {
+			setPOIEntity(entities, EntityPOIName, m[1])
+		}
+	}
+	return entities
+}
+
+func setPOIEntity(entities map[string]interface{}, key, name string) {
+	name = poiTailPattern.ReplaceAllString(name, "")
+	name = strings.TrimRight(strings.TrimSpace(name), "?!.,;")
+	name = articlePattern.ReplaceAllString(name, "")
+	if name != "" {
+		entities[key] = name
+	}
+}
+
+// intentPOIName is the place named by the classified intent, falling back to the
+// words of message.
+func intentPOIName(intent locitypes.Intent, message string) string {
+	if name, ok := intent.Entities[EntityPOIName].(string); ok && name != "" {
+		return name
+	}
+	return extractPOIName(message)
+}
+
+// clarificationQuestion asks what the user meant by a message the classifier was
+// unsure of, starting from its best guess.
+func clarificationQuestion(intent locitypes.Intent) string {
+	name, _ := intent.Entities[EntityPOIName].(string)
+	switch {
+	case intent.Type == locitypes.IntentAddPOI && name != "":
+		return fmt.Sprintf("Would you like me to add %s to your itinerary, or were you asking about it?", name)
+	case intent.Type == locitypes.IntentRemovePOI && name != "":
+		return fmt.Sprintf("Should I remove %s from your itinerary?", name)
+	case intent.Type == locitypes.IntentReplacePOI:
+		return "Which place should I replace, and with what? For example: 'replace the museum with the park'."
+	default:
+		return "I'm not sure what you'd like me to do. Do you want to add or remove a place, change the itinerary, or ask a question about your trip?"
+	}
+}
+
+func cosineSimilarity(x, y []float32) float64 {
+	if len(x) != len(y) || len(x) == 0 {
+		return 0
+	}
+	var dot, nx, ny float64
+	for i := range x {
+		dot += float64(x[i]) * float64(y[i])
+		nx += float64(x[i]) * float64(x[i])
+		ny += float64(y[i]) * float64(y[i])
+	}
+	if nx == 0 || ny == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(nx) * math.Sqrt(ny))
+}
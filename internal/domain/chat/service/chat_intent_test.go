@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/chat/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// unusedLLM fails the test when the classifier asks the LLM.
+func unusedLLM(t *testing.T) *TestLLMClient {
+	return &TestLLMClient{GenerateResponseFn: func(context.Context, string, *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+		t.Error("the LLM was asked")
+		return nil, errors.New("not expected")
+	}}
+}
+
+func replyLLM(prompts *[]string, reply string, err error) *TestLLMClient {
+	return &TestLLMClient{GenerateResponseFn: func(_ context.Context, prompt string, _ *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+		*prompts = append(*prompts, prompt)
+		if err != nil {
+			return nil, err
+		}
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(reply, genai.RoleModel)}}}, nil
+	}}
+}
+
+// intentEmbeddings embeds each labelled example as the unit vector of its intent, and
+// any other text as vectors[text].
+type intentEmbeddings struct {
+	vectors map[string][]float32
+}
+
+var intentAxes = map[locitypes.IntentType]int{
+	locitypes.IntentAddPOI:          0,
+	locitypes.IntentRemovePOI:       1,
+	locitypes.IntentReplacePOI:      2,
+	locitypes.IntentModifyItinerary: 3,
+	locitypes.IntentAskQuestion:     4,
+}
+
+func (e intentEmbeddings) GenerateQueryEmbedding(_ context.Context, text string) ([]float32, error) {
+	for _, ex := range intentExamples {
+		if ex.text == text {
+			v := make([]float32, len(intentAxes))
+			v[intentAxes[ex.intent]] = 1
+			return v, nil
+		}
+	}
+	if v, ok := e.vectors[text]; ok {
+		return v, nil
+	}
+	return nil, errors.New("unknown text")
+}
+
+func (intentEmbeddings) GeneratePOIEmbedding(context.Context, string, string, string) ([]float32, error) {
+	return nil, errors.New("not used")
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestLayeredIntentClassifier_Keywords(t *testing.T) {
+	c := NewLayeredIntentClassifier(unusedLLM(t), nil, discardLogger())
+
+	tests := []struct {
+		message  string
+		intent   locitypes.IntentType
+		entities map[string]interface{}
+	}{
+		{
+			message:  "Please add the Louvre to my itinerary on day 2 at 3pm",
+			intent:   locitypes.IntentAddPOI,
+			entities: map[string]interface{}{EntityPOIName: "Louvre", EntityDay: 2, EntityTime: "3pm"},
+		},
+		{
+			message:  "Skip the Eiffel Tower on the second day",
+			intent:   locitypes.IntentRemovePOI,
+			entities: map[string]interface{}{EntityPOIName: "Eiffel Tower", EntityDay: 2},
+		},
+		{
+			message:  "Replace the Orsay museum with a cooking class under €60",
+			intent:   locitypes.IntentReplacePOI,
+			entities: map[string]interface{}{EntityPOIName: "Orsay museum", EntityReplacement: "cooking class", EntityBudget: "€60"},
+		},
+		{
+			message:  "Where is the best place for dinner tonight?",
+			intent:   locitypes.IntentAskQuestion,
+			entities: map[string]interface{}{EntityTime: "tonight"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			intent, err := c.Classify(context.Background(), tt.message, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.intent, intent.Type)
+			assert.Equal(t, keywordIntentConfidence, intent.Confidence)
+			assert.Equal(t, tt.entities, intent.Entities)
+			assert.NotEqual(t, locitypes.ActionRequestClarification, intent.RequiredAction)
+		})
+	}
+}
+
+func TestLayeredIntentClassifier_NearestExample(t *testing.T) {
+	embeddings := intentEmbeddings{vectors: map[string][]float32{
+		"I'm over the castle, honestly": {0.1, 0.95, 0, 0.05, 0},
+		"hmm the castle":                {0.5, 0.5, 0, 0, 0.5},
+	}}
+	var prompts []string
+	c := NewLayeredIntentClassifier(replyLLM(&prompts, "", errors.New("model unavailable")), embeddings, discardLogger())
+
+	// The examples are embedded in the background on first use.
+	assert.Nil(t, c.labelledExamples(context.Background()))
+	require.Eventually(t, func() bool { return c.examples.Load() != nil }, 2*time.Second, 10*time.Millisecond)
+
+	intent, err := c.Classify(context.Background(), "I'm over the castle, honestly", "")
+	require.NoError(t, err)
+	assert.Equal(t, locitypes.IntentRemovePOI, intent.Type)
+	assert.GreaterOrEqual(t, intent.Confidence, embeddingIntentMinScore)
+	assert.Empty(t, prompts)
+
+	// No example stands out, the LLM fails: the guess comes back unsure.
+	intent, err = c.Classify(context.Background(), "hmm the castle", "")
+	require.NoError(t, err)
+	assert.Len(t, prompts, 1)
+	assert.Less(t, intent.Confidence, minIntentConfidence)
+	assert.Equal(t, locitypes.ActionRequestClarification, intent.RequiredAction)
+}
+
+func TestLayeredIntentClassifier_LLMFallback(t *testing.T) {
+	var prompts []string
+	c := NewLayeredIntentClassifier(replyLLM(&prompts,
+		`{"intent": "remove_poi", "confidence": 0.9, "poi_name": "Belém Tower", "day": 3}`, nil), nil, discardLogger())
+
+	intent, err := c.Classify(context.Background(), "Actually, that tower isn't for us", "user: add Belém Tower on day 3\n")
+	require.NoError(t, err)
+
+	require.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "user: add Belém Tower on day 3")
+	assert.Contains(t, prompts[0], "New user message: Actually, that tower isn't for us")
+	assert.Equal(t, locitypes.IntentRemovePOI, intent.Type)
+	assert.Equal(t, 0.9, intent.Confidence)
+	assert.Equal(t, locitypes.ActionUpdateExisting, intent.RequiredAction)
+	assert.Equal(t, map[string]interface{}{EntityPOIName: "Belém Tower", EntityDay: 3}, intent.Entities)
+}
+
+// clarifyingClassifier is always unsure whether message adds a place.
+type clarifyingClassifier struct{}
+
+func (clarifyingClassifier) Classify(context.Context, string, string) (locitypes.Intent, error) {
+	return locitypes.Intent{
+		Type:           locitypes.IntentAddPOI,
+		Confidence:     0.4,
+		Entities:       map[string]interface{}{EntityPOIName: "Belém Tower"},
+		RequiredAction: locitypes.ActionRequestClarification,
+	}, nil
+}
+
+type sessionRepo struct {
+	repository.Repository
+	session *locitypes.ChatSession
+	updated *locitypes.ChatSession
+}
+
+func (r *sessionRepo) GetSession(context.Context, uuid.UUID) (*locitypes.ChatSession, error) {
+	return r.session, nil
+}
+
+func (r *sessionRepo) AddMessageToSession(context.Context, uuid.UUID, locitypes.ConversationMessage) error {
+	return nil
+}
+
+func (r *sessionRepo) UpdateSession(_ context.Context, session locitypes.ChatSession) error {
+	r.updated = &session
+	return nil
+}
+
+type lisbonRepo struct{ city.Repository }
+
+func (lisbonRepo) FindCityByNameAndCountry(context.Context, string, string) (*locitypes.CityDetail, error) {
+	return &locitypes.CityDetail{ID: uuid.New(), Name: "Lisbon"}, nil
+}
+
+func TestContinueSessionStreamed_AsksForClarificationWhenUnsure(t *testing.T) {
+	repo := &sessionRepo{session: &locitypes.ChatSession{
+		ID:             uuid.New(),
+		Status:         locitypes.StatusActive,
+		SessionContext: locitypes.SessionContext{CityName: "Lisbon"},
+	}}
+	svc := &ServiceImpl{
+		logger:             discardLogger(),
+		aiClient:           unusedLLM(t),
+		llmInteractionRepo: repo,
+		cityRepo:           lisbonRepo{},
+		intentClassifier:   clarifyingClassifier{},
+		deadLetterCh:       make(chan locitypes.StreamEvent, 10),
+	}
+
+	events := make(chan locitypes.StreamEvent, 50)
+	err := svc.ContinueSessionStreamed(context.Background(), repo.session.ID, "Belém Tower", nil, events)
+	require.NoError(t, err)
+	close(events)
+
+	var clarification *locitypes.StreamEvent
+	for event := range events {
+		if event.Type == locitypes.EventTypeClarification {
+			clarification = &event
+		}
+	}
+	require.NotNil(t, clarification)
+	assert.Contains(t, clarification.Message, "add Belém Tower")
+
+	require.NotNil(t, repo.updated)
+	history := repo.updated.ConversationHistory
+	require.Len(t, history, 2)
+	assert.Equal(t, locitypes.TypeClarification, history[1].MessageType)
+	assert.Equal(t, clarification.Message, history[1].Content)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+const (
+	defaultMemoryRecentMessages = 8
+	defaultMemoryTokenBudget    = 1500
+	defaultMemoryRecallLimit    = 3
+	// memoryMinSimilarity keeps recalled messages to those clearly about the same thing.
+	memoryMinSimilarity = 0.55
+	// memoryEmbedTimeout bounds the background embedding of summarized messages.
+	memoryEmbedTimeout = 30 * time.Second
+	maxSummaryRunes    = 2000
+)
+
+// MemoryOptions tunes the conversation memory of continued chat sessions. Zero values
+// use the defaults.
+type MemoryOptions struct {
+	// RecentMessages are always given to the model verbatim (default 8).
+	RecentMessages int
+	// TokenBudget is the estimated size the unsummarized history may grow to before
+	// the messages older than RecentMessages are folded into the summary (default 1500).
+	TokenBudget int
+	// RecallLimit bounds the summarized messages recalled verbatim because they are
+	// similar to the new message (default 3). A negative limit turns recall off.
+	RecallLimit int
+}
+
+func (o MemoryOptions) recentMessages() int {
+	if o.RecentMessages > 0 {
+		return o.RecentMessages
+	}
+	return defaultMemoryRecentMessages
+}
+
+func (o MemoryOptions) tokenBudget() int {
+	if o.TokenBudget > 0 {
+		return o.TokenBudget
+	}
+	return defaultMemoryTokenBudget
+}
+
+func (o MemoryOptions) recallLimit() int {
+	if o.RecallLimit == 0 {
+		return defaultMemoryRecallLimit
+	}
+	return max(o.RecallLimit, 0)
+}
+
+// WithMemory sets how much of a session's earlier conversation continued messages
+// see; see buildConversationContext.
+func (l *ServiceImpl) WithMemory(opts MemoryOptions) *ServiceImpl {
+	l.memory = opts
+	return l
+}
+
+// ConversationContext is the view of earlier turns given to the model with a new
+// message: a rolling summary of old turns, the old turns most similar to the message,
+// and the latest turns verbatim.
+type ConversationContext struct {
+	Summary  string
+	Recalled []locitypes.ConversationMessage
+	Recent   []locitypes.ConversationMessage
+}
+
+// Prompt renders the context for a prompt, or "" when there is no earlier turn.
+func (c ConversationContext) Prompt() string {
+	var b strings.Builder
+	if c.Summary != "" {
+		fmt.Fprintf(&b, "Summary of the earlier conversation:\n%s\n", c.Summary)
+	}
+	if len(c.Recalled) > 0 {
+		b.WriteString("\nEarlier messages related to the new one:\n")
+		writeMessages(&b, c.Recalled)
+	}
+	if len(c.Recent) > 0 {
+		b.WriteString("\nLatest messages:\n")
+		writeMessages(&b, c.Recent)
+	}
+	return b.String()
+}
+
+func writeMessages(b *strings.Builder, messages []locitypes.ConversationMessage) {
+	for _, m := range messages {
+		fmt.Fprintf(b, "%s: %s\n", m.Role, strings.TrimSpace(m.Content))
+	}
+}
+
+// buildConversationContext gives the model a bounded view of the conversation before
+// the last message of session, which is the one being answered. Once the messages not
+// yet summarized outgrow the token budget, all but the latest are folded into
+// SessionContext.ConversationSummary with the LLM; the caller persists the session.
+// Folded messages are embedded in the background so later messages can recall them.
+// Both continue paths (streamed and unary) build their context here.
+func (l *ServiceImpl) buildConversationContext(ctx context.Context, session *locitypes.ChatSession, message string) ConversationContext {
+	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "buildConversationContext", trace.WithAttributes(
+		attribute.String("session.id", session.ID.String()),
+		attribute.Int("history.messages", len(session.ConversationHistory)),
+	))
+	defer span.End()
+
+	earlier := session.ConversationHistory
+	if n := len(earlier); n > 0 && earlier[n-1].Role == locitypes.RoleUser {
+		earlier = earlier[:n-1]
+	}
+
+	if folded := l.compactConversation(ctx, session, earlier); len(folded) > 0 {
+		l.rememberMessages(ctx, session.ID, folded)
+	}
+
+	summarized := min(max(session.SessionContext.SummarizedMessages, 0), len(earlier))
+	convo := ConversationContext{
+		Summary: session.SessionContext.ConversationSummary,
+		// Summarizing may have failed, so the budget is enforced here as well.
+		Recent: withinTokenBudget(earlier[summarized:], l.memory.recentMessages(), l.memory.tokenBudget()),
+	}
+	if summarized > 0 {
+		convo.Recalled = l.recallMessages(ctx, session.ID, message, convo.Recent)
+	}
+
+	span.SetAttributes(
+		attribute.Int("context.summarized", summarized),
+		attribute.Int("context.recalled", len(convo.Recalled)),
+		attribute.Int("context.recent", len(convo.Recent)),
+	)
+	return convo
+}
+
+// compactConversation folds the unsummarized messages of earlier, except the latest
+// RecentMessages, into the session summary once they exceed the token budget. It
+// returns the folded messages.
+func (l *ServiceImpl) compactConversation(ctx context.Context, session *locitypes.ChatSession, earlier []locitypes.ConversationMessage) []locitypes.ConversationMessage {
+	start := min(max(session.SessionContext.SummarizedMessages, 0), len(earlier))
+	pending := earlier[start:]
+	keep := l.memory.recentMessages()
+	if len(pending) <= keep || estimateMessageTokens(pending) <= l.memory.tokenBudget() {
+		return nil
+	}
+	folded := pending[:len(pending)-keep]
+
+	summary, err := l.summarizeConversation(ctx, session.SessionContext.ConversationSummary, folded)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to summarize conversation, keeping the latest messages only",
+			slog.String("session_id", session.ID.String()),
+			slog.Any("error", err))
+		return nil
+	}
+	session.SessionContext.ConversationSummary = summary
+	session.SessionContext.SummarizedMessages = start + len(folded)
+	l.logger.DebugContext(ctx, "Conversation summarized",
+		slog.String("session_id", session.ID.String()),
+		slog.Int("folded_messages", len(folded)),
+		slog.Int("summarized_messages", session.SessionContext.SummarizedMessages))
+	return folded
+}
+
+func (l *ServiceImpl) summarizeConversation(ctx context.Context, previous string, messages []locitypes.ConversationMessage) (string, error) {
+	resp, err := l.aiClient.GenerateResponse(ctx, getConversationSummaryPrompt(previous, messages), &genai.GenerateContentConfig{
+		Temperature: genai.Ptr[float32](0.2),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+	summary := strings.TrimSpace(resp.Text())
+	if summary == "" {
+		return "", fmt.Errorf("failed to summarize conversation: empty summary")
+	}
+	if utf8.RuneCountInString(summary) > maxSummaryRunes {
+		summary = string([]rune(summary)[:maxSummaryRunes])
+	}
+	return summary, nil
+}
+
+// rememberMessages embeds messages for recall without holding up the reply.
+func (l *ServiceImpl) rememberMessages(ctx context.Context, sessionID uuid.UUID, messages []locitypes.ConversationMessage) {
+	if l.embeddingService == nil || l.memory.recallLimit() == 0 {
+		return
+	}
+	messages = append([]locitypes.ConversationMessage(nil), messages...)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), memoryEmbedTimeout)
+		defer cancel()
+		for _, m := range messages {
+			if strings.TrimSpace(m.Content) == "" || m.ID == uuid.Nil {
+				continue
+			}
+			embedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, m.Content)
+			if err == nil {
+				err = l.llmInteractionRepo.SaveMessageEmbedding(ctx, sessionID, m, embedding)
+			}
+			if err != nil {
+				l.logger.WarnContext(ctx, "Failed to remember conversation message",
+					slog.String("session_id", sessionID.String()),
+					slog.String("message_id", m.ID.String()),
+					slog.Any("error", err))
+				// Later messages are still worth remembering unless the deadline has passed.
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// recallMessages returns the summarized messages most similar to message, oldest first.
+func (l *ServiceImpl) recallMessages(ctx context.Context, sessionID uuid.UUID, message string, recent []locitypes.ConversationMessage) []locitypes.ConversationMessage {
+	limit := l.memory.recallLimit()
+	if l.embeddingService == nil || limit == 0 {
+		return nil
+	}
+	embedding, err := l.embeddingService.GenerateQueryEmbedding(ctx, message)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to embed message for recall", slog.Any("error", err))
+		return nil
+	}
+	found, err := l.llmInteractionRepo.FindSimilarSessionMessages(ctx, sessionID, embedding, limit, memoryMinSimilarity)
+	if err != nil {
+		l.logger.WarnContext(ctx, "Failed to recall conversation messages", slog.Any("error", err))
+		return nil
+	}
+
+	inRecent := make(map[uuid.UUID]bool, len(recent))
+	for _, m := range recent {
+		inRecent[m.ID] = true
+	}
+	recalled := make([]locitypes.ConversationMessage, 0, len(found))
+	for _, m := range found {
+		if !inRecent[m.ID] {
+			recalled = append(recalled, m)
+		}
+	}
+	sort.SliceStable(recalled, func(i, j int) bool { return recalled[i].Timestamp.Before(recalled[j].Timestamp) })
+	return recalled
+}
+
+// withinTokenBudget returns the latest of messages, at most keep of them, dropping
+// older ones while they exceed budget. The latest message is always kept.
+func withinTokenBudget(messages []locitypes.ConversationMessage, keep, budget int) []locitypes.ConversationMessage {
+	if len(messages) > keep && estimateMessageTokens(messages) > budget {
+		messages = messages[len(messages)-keep:]
+	}
+	for len(messages) > 1 && estimateMessageTokens(messages) > budget {
+		messages = messages[1:]
+	}
+	return messages
+}
+
+func estimateMessageTokens(messages []locitypes.ConversationMessage) int {
+	chars := 0
+	for _, m := range messages {
+		chars += utf8.RuneCountInString(m.Content)
+	}
+	return llm.EstimateTokens(chars)
+}
+
+// answerFromConversation answers a question about the trip with the conversation so far.
+func (l *ServiceImpl) answerFromConversation(ctx context.Context, session *locitypes.ChatSession, convo ConversationContext, message string) (string, error) {
+	var itinerary []string
+	if session.CurrentItinerary != nil {
+		for _, p := range session.CurrentItinerary.AIItineraryResponse.PointsOfInterest {
+			itinerary = append(itinerary, p.Name)
+		}
+	}
+	prompt := getConversationAnswerPrompt(session.SessionContext.CityName, convo, itinerary, message)
+	resp, err := l.aiClient.GenerateResponse(ctx, prompt, &genai.GenerateContentConfig{
+		Temperature: genai.Ptr[float32](0.5),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to answer question: %w", err)
+	}
+	answer := strings.TrimSpace(resp.Text())
+	if answer == "" {
+		return "", fmt.Errorf("failed to answer question: empty answer")
+	}
+	return answer, nil
+}
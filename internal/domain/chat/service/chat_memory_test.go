@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/chat/repository"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// memoryRepo records embedded messages and answers recall with a fixed result.
+type memoryRepo struct {
+	repository.Repository
+	saved    chan uuid.UUID
+	similar  []locitypes.ConversationMessage
+	searched uuid.UUID
+	failSave uuid.UUID // message whose embedding fails to save
+}
+
+func (r *memoryRepo) SaveMessageEmbedding(_ context.Context, _ uuid.UUID, message locitypes.ConversationMessage, _ []float32) error {
+	if message.ID == r.failSave {
+		return errors.New("insert failed")
+	}
+	r.saved <- message.ID
+	return nil
+}
+
+func (r *memoryRepo) FindSimilarSessionMessages(_ context.Context, sessionID uuid.UUID, _ []float32, _ int, _ float64) ([]locitypes.ConversationMessage, error) {
+	r.searched = sessionID
+	return r.similar, nil
+}
+
+type memoryEmbeddings struct{}
+
+func (memoryEmbeddings) GenerateQueryEmbedding(context.Context, string) ([]float32, error) {
+	return []float32{0.1, 0.2}, nil
+}
+
+func (memoryEmbeddings) GeneratePOIEmbedding(context.Context, string, string, string) ([]float32, error) {
+	return nil, errors.New("not used")
+}
+
+// longHistory returns n alternating user and assistant messages of about 100 tokens.
+func longHistory(n int) []locitypes.ConversationMessage {
+	history := make([]locitypes.ConversationMessage, n)
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := range history {
+		role := locitypes.RoleUser
+		if i%2 == 1 {
+			role = locitypes.RoleAssistant
+		}
+		history[i] = locitypes.ConversationMessage{
+			ID:        uuid.New(),
+			Role:      role,
+			Content:   fmt.Sprintf("message %d %s", i, strings.Repeat("x", 400)),
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return history
+}
+
+func TestBuildConversationContext_SummarizesOldTurns(t *testing.T) {
+	history := longHistory(12)
+	repo := &memoryRepo{saved: make(chan uuid.UUID, 20), similar: []locitypes.ConversationMessage{history[3], history[1], history[11]}}
+
+	var prompts []string
+	svc := &ServiceImpl{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		aiClient: &TestLLMClient{GenerateResponseFn: func(_ context.Context, prompt string, _ *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+			prompts = append(prompts, prompt)
+			return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText("Three days in Porto, loves port wine.", genai.RoleModel)}}}, nil
+		}},
+		embeddingService:   memoryEmbeddings{},
+		llmInteractionRepo: repo,
+		memory:             MemoryOptions{RecentMessages: 4, TokenBudget: 500},
+	}
+	session := &locitypes.ChatSession{
+		ID:                  uuid.New(),
+		ConversationHistory: append(history, locitypes.ConversationMessage{ID: uuid.New(), Role: locitypes.RoleUser, Content: "Where was that wine cellar?"}),
+		SessionContext:      locitypes.SessionContext{CityName: "Porto", ConversationSummary: "Trip plan for Porto"},
+	}
+
+	convo := svc.buildConversationContext(context.Background(), session, "Where was that wine cellar?")
+
+	require.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "Trip plan for Porto")
+	assert.Contains(t, prompts[0], "message 0 ")
+	assert.Contains(t, prompts[0], "message 7 ")
+	assert.NotContains(t, prompts[0], "message 8 ")
+	assert.Equal(t, 8, session.SessionContext.SummarizedMessages)
+	assert.Equal(t, "Three days in Porto, loves port wine.", convo.Summary)
+	assert.Equal(t, history[8:], convo.Recent, "the new message is not part of its own context")
+
+	// Recall skips messages already shown verbatim and keeps the conversation order.
+	assert.Equal(t, session.ID, repo.searched)
+	require.Len(t, convo.Recalled, 2)
+	assert.Equal(t, history[1].ID, convo.Recalled[0].ID)
+	assert.Equal(t, history[3].ID, convo.Recalled[1].ID)
+
+	rendered := convo.Prompt()
+	assert.Contains(t, rendered, "Summary of the earlier conversation:\nThree days in Porto")
+	assert.Contains(t, rendered, "Latest messages:\nuser: message 8 ")
+
+	// The folded messages are embedded in the background for later recall.
+	embedded := map[uuid.UUID]bool{}
+	for range 8 {
+		select {
+		case id := <-repo.saved:
+			embedded[id] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("folded messages were not embedded")
+		}
+	}
+	for _, m := range history[:8] {
+		assert.True(t, embedded[m.ID])
+	}
+
+	// Under budget again, the next message needs no new summary.
+	session.ConversationHistory = append(session.ConversationHistory, locitypes.ConversationMessage{ID: uuid.New(), Role: locitypes.RoleUser, Content: "Thanks"})
+	svc.buildConversationContext(context.Background(), session, "Thanks")
+	assert.Len(t, prompts, 1)
+}
+
+func TestRememberMessages_ContinuesPastFailures(t *testing.T) {
+	history := longHistory(3)
+	repo := &memoryRepo{saved: make(chan uuid.UUID, 3), failSave: history[0].ID}
+	svc := &ServiceImpl{
+		logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		embeddingService:   memoryEmbeddings{},
+		llmInteractionRepo: repo,
+		memory:             MemoryOptions{RecentMessages: 4, TokenBudget: 500},
+	}
+
+	svc.rememberMessages(context.Background(), uuid.New(), history)
+
+	for _, m := range history[1:] {
+		select {
+		case id := <-repo.saved:
+			assert.Equal(t, m.ID, id)
+		case <-time.After(2 * time.Second):
+			t.Fatal("messages after a failed save were not embedded")
+		}
+	}
+}
+
+func TestBuildConversationContext_SummaryFailureKeepsBudget(t *testing.T) {
+	svc := &ServiceImpl{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		aiClient: &TestLLMClient{GenerateResponseFn: func(context.Context, string, *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+			return nil, errors.New("model unavailable")
+		}},
+		memory: MemoryOptions{RecentMessages: 6, TokenBudget: 250},
+	}
+	history := longHistory(10)
+	session := &locitypes.ChatSession{ID: uuid.New(), ConversationHistory: history}
+
+	convo := svc.buildConversationContext(context.Background(), session, "next")
+
+	assert.Zero(t, session.SessionContext.SummarizedMessages)
+	assert.Empty(t, convo.Summary)
+	assert.Empty(t, convo.Recalled, "nothing was summarized, so nothing is recalled")
+	// history ends with an assistant message, which stays in the context.
+	assert.Equal(t, history[8:], convo.Recent)
+}
@@ -292,13 +292,13 @@ func getPOIDetailsPrompt(city string, lat, lon float64) string {
     		"address": "address of the point of interest",
     		"website": "website of the POI if available",
     		"phone_number": "phone number of the POI if available",
-    		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
+    		"opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"},
     		"price_range": "price level if available",
             "category": "Primary category (e.g., Museum, Historical Site, Park, Restaurant, Bar)",
             "tags": ["tag1", "tag2", ...], -- Tags related to the POI
             "images": ["image_url_1", "image_url_2", ...], // images from wikipedia or pininterest
             "rating": <float> -- Average rating if available
-            "stars": type of stars if available (e.g., "3 stars", "5 stars")
+            "star_rating": type of stars if available (e.g., "3 stars", "5 stars")
 
 		}
 	`, city, lat, lon)
@@ -331,7 +331,7 @@ func getCityDescriptionPrompt(cityName string) string {
 	return fmt.Sprintf(`
         Provide detailed information about the city %s in JSON format with the following structure:
         {
-            "city_name": "%s",
+            "city": "%s",
             "country": "Country name",
             "state_province": "State or province, if applicable",
             "description": "A detailed description of the city",
@@ -380,7 +380,7 @@ Respond with JSON:
             "description_poi": "",
             "address": "",
             "website": "",
-                		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
+            "opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"}
 
         }
     ]
@@ -405,8 +405,7 @@ Respond with JSON:
             "description_poi": "",
             "address": "",
             "website": "",
-                		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
-,
+            "opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"},
             "distance": <float>
         }
     ]
@@ -429,8 +428,7 @@ Respond with JSON:
             "description_poi": "",
             "address": "",
             "website": "",
-                		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
-,
+            "opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"},
             "distance": <float>
         }
     ]
@@ -570,8 +568,7 @@ Respond with JSON:
             "description": "Description matching preferences",
             "address": "",
             "website": "",
-                		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
-,
+            "opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"},
             "price_range": "Free|$|$$|$$$",
             "rating": 0,
             "tags": [],
@@ -597,8 +594,7 @@ Respond with JSON:
             "description": "Description matching preferences",
             "address": "",
             "website": "",
-                		"opening_hours": "Opening hours as string (e.g., 'Mon-Fri 9:00-17:00, Sat 10:00-15:00')"
-,
+            "opening_hours": {"monday": "09:00-17:00", "saturday": "10:00-15:00", "sunday": "closed"},
             "price_range": "Free|$|$$|$$$",
             "rating": 0,
             "tags": [],
@@ -608,3 +604,89 @@ Respond with JSON:
     ]
 }`, cityName, cityName)
 }
+
+func getAgentPrompt(cityName, message string, userLocation *locitypes.UserLocation, signedIn bool) string {
+	var b strings.Builder
+	b.WriteString(`
+You are a travel assistant with access to our database of points of interest.
+Answer the user's message below. Look places up with the tools instead of recalling
+them: search_pois finds places matching a description, nearby lists places around a
+point and get_city returns a city's centre and summary.`)
+	if signedIn {
+		b.WriteString(`
+Call get_user_preferences before recommending places, and tailor the answer to it.
+Use add_to_list only when the user asks to save a place, with an ID from a previous
+search.`)
+	}
+	b.WriteString(`
+Only recommend places returned by the tools, by their exact name. If the tools find
+nothing suitable, say so instead of inventing places. Answer in plain text, without JSON.
+`)
+	if cityName != "" {
+		fmt.Fprintf(&b, "\nCity of the conversation: %s", cityName)
+	}
+	if userLocation != nil {
+		fmt.Fprintf(&b, "\nUser location: %.6f, %.6f", userLocation.UserLat, userLocation.UserLon)
+	}
+	fmt.Fprintf(&b, "\n\nUser message: %s", message)
+	return b.String()
+}
+
+func getConversationSummaryPrompt(previousSummary string, messages []locitypes.ConversationMessage) string {
+	var b strings.Builder
+	b.WriteString(`
+You maintain the memory of a travel planning conversation. Update the summary below with
+the messages that follow it. Keep what later replies may depend on: the city, dates,
+budget, who is travelling, stated likes and dislikes, places added to or removed from
+the itinerary, and open questions. Drop greetings and small talk. Write at most 150
+words of plain text, without headings or JSON.
+`)
+	if previousSummary != "" {
+		fmt.Fprintf(&b, "\nCurrent summary:\n%s\n", previousSummary)
+	}
+	b.WriteString("\nMessages to add:\n")
+	writeMessages(&b, messages)
+	return b.String()
+}
+
+func getConversationAnswerPrompt(cityName string, convo ConversationContext, itinerary []string, message string) string {
+	var b strings.Builder
+	b.WriteString(`
+You are a travel assistant continuing a conversation about a trip. Answer the user's
+new message in plain text, consistent with what was said before. If the answer depends
+on something the conversation does not settle, ask one short question instead.
+`)
+	if cityName != "" {
+		fmt.Fprintf(&b, "\nCity of the conversation: %s\n", cityName)
+	}
+	if len(itinerary) > 0 {
+		fmt.Fprintf(&b, "Places in the itinerary: %s\n", strings.Join(itinerary, ", "))
+	}
+	if earlier := convo.Prompt(); earlier != "" {
+		b.WriteString("\n" + earlier)
+	}
+	fmt.Fprintf(&b, "\nNew user message: %s", message)
+	return b.String()
+}
+
+func getIntentClassificationPrompt(conversation, message string) string {
+	var b strings.Builder
+	b.WriteString(`
+You classify the messages a traveller sends about an itinerary they are planning.
+Pick the intent of the new message:
+- add_poi: add a place or activity to the itinerary
+- remove_poi: take a place out of the itinerary
+- replace_poi: put one place in place of another
+- modify_itinerary: any other change, e.g. reorder, slow down or make it cheaper
+- ask_question: ask about the trip, a place or the city, changing nothing
+
+Give your confidence from 0 to 1; below 0.6 means the user should be asked what they
+meant. Fill in the place, day of the trip, time of day and budget the message
+mentions, reading references such as "it" or "that one" from the conversation.
+`)
+	if conversation != "" {
+		b.WriteString("\n" + conversation)
+	}
+	fmt.Fprintf(&b, "\nNew user message: %s", message)
+	return b.String()
+}
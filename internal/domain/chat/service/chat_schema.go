@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// responsePart is the response schema of a streamed part, with its validation and
+// repair bound to the Go type the part decodes into.
+type responsePart struct {
+	schema *genai.Schema
+	// validate returns the JSON document of a reply that matches the schema.
+	validate func(text string) (string, error)
+	// repair asks the model to fix a rejected reply; see llm.RepairStructured.
+	repair func(ctx context.Context, client llm.ChatClient, prompt string, config *genai.GenerateContentConfig,
+		text string, cause error, opts llm.StructuredOptions) (string, error)
+}
+
+func structuredPart[T any]() responsePart {
+	return responsePart{
+		schema: llm.SchemaFor[T](),
+		validate: func(text string) (string, error) {
+			_, clean, err := llm.DecodeStructured[T](text)
+			return clean, err
+		},
+		repair: func(ctx context.Context, client llm.ChatClient, prompt string, config *genai.GenerateContentConfig,
+			text string, cause error, opts llm.StructuredOptions,
+		) (string, error) {
+			result, err := llm.RepairStructured[T](ctx, client, prompt, config, text, cause, opts)
+			return result.JSON, err
+		},
+	}
+}
+
+// responseParts maps the parts streamed by the unified chat to their reply types.
+var responseParts = map[string]responsePart{
+	"city_data":    structuredPart[locitypes.GeneralCityData](),
+	"general_pois": structuredPart[locitypes.POIListResponse](),
+	"itinerary":    structuredPart[locitypes.AIItineraryResponse](),
+	"hotels":       structuredPart[locitypes.HotelListResponse](),
+	"restaurants":  structuredPart[locitypes.RestaurantListResponse](),
+	"activities":   structuredPart[locitypes.ActivityListResponse](),
+}
+
+// structuredOptions labels a structured LLM call in metrics and logs.
+func (l *ServiceImpl) structuredOptions(name string) llm.StructuredOptions {
+	return llm.StructuredOptions{Name: name, Logger: l.logger}
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
 	"log/slog"
 	"net/url"
 	"os"
@@ -38,6 +37,7 @@ import (
 	"github.com/FACorreiaa/loci-connect-api/internal/domain/tags"
 	"github.com/FACorreiaa/loci-connect-api/internal/llm"
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
 )
 
 const (
@@ -81,8 +81,10 @@ type LlmInteractiontService interface {
 	GetRecentInteractions(ctx context.Context, userID uuid.UUID, pagination *commonpb.PaginationRequest) (*chatv1.GetRecentInteractionsResponse, error)
 }
 
+// IntentClassifier classifies a message of a continued session; conversation is the
+// rendered context of the earlier turns. See LayeredIntentClassifier.
 type IntentClassifier interface {
-	Classify(ctx context.Context, message string) (locitypes.IntentType, error) // e.g., "start_trip", "modify_itinerary"
+	Classify(ctx context.Context, message, conversation string) (locitypes.Intent, error)
 }
 
 // ServiceImpl provides the implementation for LlmInteractiontService.
@@ -104,9 +106,18 @@ type ServiceImpl struct {
 	// events
 	deadLetterCh     chan locitypes.StreamEvent
 	intentClassifier IntentClassifier
+
+	// agent, when set, answers unified chat messages with tool calls; see WithAgent.
+	agent *chatAgent
+	// gazetteer, when set, finds the city of a message before the LLM is asked; see
+	// WithGazetteer.
+	gazetteer *city.Gazetteer
+	memory    MemoryOptions
 }
 
-// NewLlmInteractiontService creates a new user service instance.
+// NewLlmInteractiontService creates a new user service instance. The chat and embedding
+// clients are injected so the backend can be chosen by configuration; embeddingService
+// may be nil, which disables semantic search.
 func NewLlmInteractiontService(interestRepo interests.Repository,
 	searchProfileRepo profiles.Repository,
 	searchProfileSvc profiles.Service,
@@ -114,23 +125,10 @@ func NewLlmInteractiontService(interestRepo interests.Repository,
 	llmInteractionRepo repository.Repository,
 	cityRepo city.Repository,
 	poiRepo poi.Repository,
+	aiClient llm.ChatClient,
+	embeddingService llm.EmbeddingClient,
 	logger *slog.Logger,
 ) *ServiceImpl {
-	ctx := context.Background()
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	aiClient, err := llm.NewGeminiChatClient(ctx, apiKey)
-	if err != nil {
-		panic(err)
-	}
-
-	// Initialize embedding service
-	embeddingService, err := llm.NewGeminiEmbeddingClient(ctx, logger)
-	if err != nil {
-		log.Fatalf("Failed to create embedding service: %v", err) // Terminate if initialization fails
-	}
-
-	// Initialize RAG service
-
 	c := cache.New(48*time.Hour, 1*time.Hour) // Cache for 48 hours with cleanup every hour
 	service := &ServiceImpl{
 		logger:             logger,
@@ -145,7 +143,7 @@ func NewLlmInteractiontService(interestRepo interests.Repository,
 		poiRepo:            poiRepo,
 		cache:              c,
 		deadLetterCh:       make(chan locitypes.StreamEvent, 100),
-		intentClassifier:   &locitypes.SimpleIntentClassifier{},
+		intentClassifier:   NewLayeredIntentClassifier(aiClient, embeddingService, logger),
 	}
 	go service.processDeadLetterQueue()
 	return service
@@ -401,7 +399,7 @@ func (l *ServiceImpl) GenerateEnhancedPersonalisedPOIWorker(ctx context.Context,
 	prompt := l.getEnhancedPersonalizedPOIPrompt(cityName, enhancedPromptData, domain)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
 
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.AIItineraryResponse](ctx, l.aiClient, prompt, config, l.structuredOptions("itinerary"))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "AI generation failed")
@@ -411,31 +409,11 @@ func (l *ServiceImpl) GenerateEnhancedPersonalisedPOIWorker(ctx context.Context,
 
 	duration := time.Since(startTime)
 	span.SetAttributes(attribute.Int64("generation.duration_ms", duration.Milliseconds()))
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid enhanced personalized POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := CleanJSONResponse(txt)
-	var itineraryData locitypes.AIItineraryResponse
-	if err := json.Unmarshal([]byte(cleanTxt), &itineraryData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse enhanced personalized POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse enhanced personalized POI JSON: %w", err)}
-		return
-	}
+	span.SetAttributes(
+		attribute.Int("response.length", len(result.JSON)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
+	itineraryData := result.Value
 
 	span.SetAttributes(attribute.Int("pois.count", len(itineraryData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "Enhanced personalized POIs generated successfully")
@@ -827,38 +805,20 @@ func (l *ServiceImpl) getPOIDetailedInfos(ctx context.Context, wg *sync.WaitGrou
 
 	prompt := getPOIDetailsPrompt(city, lat, lon)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+	ctx = llm.WithServedBackend(ctx)
+	result, err := llm.GenerateStructured[locitypes.POIDetailedInfo](ctx, l.aiClient, prompt, config, l.structuredOptions("poi_details"))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to generate POI details")
 		resultCh <- locitypes.POIDetailedInfo{Err: fmt.Errorf("failed to generate POI details: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid POI details content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.POIDetailedInfo{Err: err}
-		return
-	}
-
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-	cleanTxt := CleanJSONResponse(txt)
-	var detailedInfo locitypes.POIDetailedInfo
-	if err := json.Unmarshal([]byte(cleanTxt), &detailedInfo); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse POI details JSON")
-		resultCh <- locitypes.POIDetailedInfo{Err: fmt.Errorf("failed to parse POI details JSON: %w", err)}
-		return
-	}
+	txt := result.JSON
+	detailedInfo := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(txt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 	span.SetStatus(codes.Ok, "POI details generated successfully")
@@ -866,7 +826,8 @@ func (l *ServiceImpl) getPOIDetailedInfos(ctx context.Context, wg *sync.WaitGrou
 		UserID:       userID,
 		Prompt:       prompt,
 		ResponseText: txt,
-		ModelUsed:    model, // Adjust based on your AI client
+		ModelUsed:    llm.ModelName(ctx, l.aiClient),
+		Provider:     llm.ProviderName(ctx, l.aiClient),
 		LatencyMs:    latencyMs,
 		CityName:     city,
 		// request payload
@@ -1020,18 +981,20 @@ func (l *ServiceImpl) generatePOIData(ctx context.Context, poiName, cityName str
 	// Create a prompt for the LLM
 	prompt := generatedContinuedConversationPrompt(poiName, cityName)
 
-	// Generate LLM response
-	response, err := l.aiClient.GenerateContent(ctx, prompt, "", nil)
+	ctx = llm.WithServedBackend(ctx)
+	result, err := llm.GenerateStructured[locitypes.POIDetailedInfo](ctx, l.aiClient, prompt, nil, l.structuredOptions("poi_details"))
 	if err != nil {
 		span.RecordError(err)
 		return locitypes.POIDetailedInfo{}, fmt.Errorf("failed to generate POI data: %w", err)
 	}
+	span.SetAttributes(attribute.Int("response.attempts", result.Attempts))
 
 	interaction := locitypes.LlmInteraction{
 		UserID:       userID,
 		Prompt:       prompt,
-		ResponseText: response,
-		ModelUsed:    model,
+		ResponseText: result.JSON,
+		ModelUsed:    llm.ModelName(ctx, l.aiClient),
+		Provider:     llm.ProviderName(ctx, l.aiClient),
 		CityName:     cityName,
 	}
 	savedLlmInteractionID, err := l.llmInteractionRepo.SaveInteraction(ctx, interaction)
@@ -1042,27 +1005,8 @@ func (l *ServiceImpl) generatePOIData(ctx context.Context, poiName, cityName str
 	}
 	span.SetAttributes(attribute.String("llm.interaction_id.for_poi_data", savedLlmInteractionID.String()))
 
-	cleanResponse := CleanJSONResponse(response)
-	var poiData locitypes.POIDetailedInfo
-	if err := json.Unmarshal([]byte(cleanResponse), &poiData); err != nil || poiData.Name == "" {
-		l.logger.WarnContext(ctx, "LLM returned invalid or empty POI data",
-			slog.String("poiName", poiName),
-			slog.String("llmResponse", response),
-			slog.Any("unmarshalError", err))
-		span.AddEvent("Invalid LLM response")
-		poiData = locitypes.POIDetailedInfo{
-			ID:             uuid.New(),
-			Name:           poiName,
-			Latitude:       0,
-			Longitude:      0,
-			Category:       "Attraction",
-			DescriptionPOI: fmt.Sprintf("Added %s based on user request, but detailed data not available.", poiName),
-			Distance:       0,
-		}
-	}
-	if poiData.ID == uuid.Nil { // Assign an ID if LLM didn't provide one
-		poiData.ID = uuid.New()
-	}
+	poiData := result.Value
+	poiData.ID = uuid.New()
 	poiData.LlmInteractionID = savedLlmInteractionID
 
 	// Calculate distance if coordinates are valid
@@ -1276,11 +1220,11 @@ func (l *ServiceImpl) generateSemanticPOIRecommendations(ctx context.Context, us
 }
 
 // handleSemanticRemovePOI handles removing POIs with semantic understanding
-func (l *ServiceImpl) handleSemanticRemovePOI(ctx context.Context, message string, session *locitypes.ChatSession) string {
+func (l *ServiceImpl) handleSemanticRemovePOI(ctx context.Context, message string, intent locitypes.Intent, session *locitypes.ChatSession) string {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "handleSemanticRemovePOI")
 	defer span.End()
 
-	poiName := extractPOIName(message)
+	poiName := intentPOIName(intent, message)
 	if poiName == "" {
 		return "I'd be happy to remove a POI from your itinerary! Could you please specify which place you'd like to remove?"
 	}
@@ -1328,8 +1272,40 @@ func normalizeCacheComponent(s string) string {
 	return s
 }
 
-// extractCityFromMessage uses AI to extract city name and clean the message
-func (l *ServiceImpl) extractCityFromMessage(ctx context.Context, message string) (cityName, cleanedMessage string, err error) {
+// cityExtraction is the reply of the city extraction prompt.
+type cityExtraction struct {
+	City    string `json:"city" llm:"required,desc=The city named in the message, empty when there is none"`
+	Message string `json:"message" llm:"required,desc=The message without the city"`
+}
+
+// WithGazetteer makes extractCityFromMessage look cities up in g first, so the LLM is
+// only asked when g is unsure.
+func (l *ServiceImpl) WithGazetteer(g *city.Gazetteer) *ServiceImpl {
+	l.gazetteer = g
+	return l
+}
+
+// extractCityFromMessage finds the city named in message and returns the message
+// without it. The gazetteer answers when it is confident, using userLocation to tell
+// homonyms apart; otherwise the LLM parses the message.
+func (l *ServiceImpl) extractCityFromMessage(ctx context.Context, message string, userLocation *locitypes.UserLocation) (cityName, cleanedMessage string, err error) {
+	if l.gazetteer != nil {
+		match, found := l.gazetteer.Resolve(message, userLocation)
+		switch {
+		case found && match.Confident:
+			observability.CityExtractions.WithLabelValues("gazetteer").Inc()
+			return match.Label, match.Cleaned, nil
+		case !found && l.gazetteer.Exhaustive():
+			observability.CityExtractions.WithLabelValues("gazetteer_none").Inc()
+			return "", message, nil
+		case found:
+			l.logger.DebugContext(ctx, "Gazetteer unsure of city, asking the LLM",
+				slog.String("candidate", match.Label),
+				slog.String("reason", match.Reason))
+		}
+	}
+	observability.CityExtractions.WithLabelValues("llm").Inc()
+
 	prompt := fmt.Sprintf(`
 You are a text parser. Extract the city name from the user's travel request and return a clean version of the message.
 
@@ -1351,37 +1327,13 @@ Examples:
 If no city is mentioned, use empty string for city.
 `, message)
 
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, &genai.GenerateContentConfig{
+	result, err := llm.GenerateStructured[cityExtraction](ctx, l.aiClient, prompt, &genai.GenerateContentConfig{
 		Temperature: genai.Ptr[float32](0.1), // Low temperature for consistent parsing
-	})
+	}, l.structuredOptions("city_extraction"))
 	if err != nil {
 		return "", "", fmt.Errorf("failed to parse message: %w", err)
 	}
-
-	var responseText string
-	for _, cand := range response.Candidates {
-		if cand.Content != nil {
-			for _, part := range cand.Content.Parts {
-				if part.Text != "" {
-					responseText += string(part.Text)
-				}
-			}
-		}
-	}
-
-	if responseText == "" {
-		return "", "", fmt.Errorf("empty response from AI parser")
-	}
-
-	cleanResponse := CleanJSONResponse(responseText)
-	var parsed struct {
-		City    string `json:"city"`
-		Message string `json:"message"`
-	}
-
-	if err := json.Unmarshal([]byte(cleanResponse), &parsed); err != nil {
-		return "", "", fmt.Errorf("failed to parse extraction response: %w", err)
-	}
+	parsed := result.Value
 
 	// If no city extracted, return original message
 	if parsed.City == "" {
@@ -1609,41 +1561,59 @@ func (l *ServiceImpl) ContinueSessionStreamed(
 		span.RecordError(err, trace.WithAttributes(attribute.String("warning", "User message DB save failed")))
 	}
 	session.ConversationHistory = append(session.ConversationHistory, userMessage)
+	convo := l.buildConversationContext(ctx, session, message)
 
 	// --- 4. Classify Intent ---
-	intent, err := l.intentClassifier.Classify(ctx, message)
+	intent, err := l.intentClassifier.Classify(ctx, message, convo.Prompt())
 	if err != nil {
 		err = fmt.Errorf("failed to classify intent for message '%s': %w", message, err)
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeError, Error: err.Error(), IsFinal: true}, 3)
 		return err
 	}
-	l.logger.InfoContext(ctx, "Intent classified", slog.String("intent", string(intent)))
-	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: "intent_classified", Data: map[string]string{"intent": string(intent)}}, 3)
+	// Unsure intents are not acted on; the user is asked what they meant instead.
+	intentType := intent.Type
+	if intent.RequiredAction == locitypes.ActionRequestClarification {
+		intentType = locitypes.IntentClarification
+	}
+	l.logger.InfoContext(ctx, "Intent classified",
+		slog.String("intent", string(intent.Type)),
+		slog.Float64("confidence", intent.Confidence),
+		slog.Any("entities", intent.Entities))
+	span.SetAttributes(attribute.String("intent.type", string(intent.Type)), attribute.Float64("intent.confidence", intent.Confidence))
+	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: "intent_classified", Data: map[string]interface{}{
+		"intent":          string(intent.Type),
+		"confidence":      intent.Confidence,
+		"entities":        intent.Entities,
+		"required_action": string(intent.RequiredAction),
+	}}, 3)
 
 	// --- 5. Enhance with Semantic POI Recommendations ---
-	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-		Type: locitypes.EventTypeProgress,
-		Data: map[string]interface{}{"status": "generating_semantic_context", "progress": 20},
-	}, 3)
-
-	semanticPOIs, err := l.generateSemanticPOIRecommendations(ctx, message, cityID, session.UserID, userLocation, 0.6)
-	if err != nil {
-		l.logger.WarnContext(ctx, "Failed to generate semantic POI recommendations for streaming session", slog.Any("error", err))
+	var semanticPOIs []locitypes.POIDetailedInfo
+	if intentType != locitypes.IntentClarification {
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
 			Type: locitypes.EventTypeProgress,
-			Data: map[string]interface{}{"status": "semantic_context_failed", "progress": 22},
-		}, 3)
-	} else {
-		l.logger.InfoContext(ctx, "Generated semantic POI recommendations for streaming session",
-			slog.Int("semantic_recommendations", len(semanticPOIs)))
-		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-			Type: "semantic_context_generated",
-			Data: map[string]interface{}{
-				"status":                         "semantic_context_ready",
-				"semantic_recommendations_count": len(semanticPOIs),
-				"progress":                       25,
-			},
+			Data: map[string]interface{}{"status": "generating_semantic_context", "progress": 20},
 		}, 3)
+
+		semanticPOIs, err = l.generateSemanticPOIRecommendations(ctx, message, cityID, session.UserID, userLocation, 0.6)
+		if err != nil {
+			l.logger.WarnContext(ctx, "Failed to generate semantic POI recommendations for streaming session", slog.Any("error", err))
+			l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+				Type: locitypes.EventTypeProgress,
+				Data: map[string]interface{}{"status": "semantic_context_failed", "progress": 22},
+			}, 3)
+		} else {
+			l.logger.InfoContext(ctx, "Generated semantic POI recommendations for streaming session",
+				slog.Int("semantic_recommendations", len(semanticPOIs)))
+			l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+				Type: "semantic_context_generated",
+				Data: map[string]interface{}{
+					"status":                         "semantic_context_ready",
+					"semantic_recommendations_count": len(semanticPOIs),
+					"progress":                       25,
+				},
+			}, 3)
+		}
 	}
 
 	// --- 5. Handle Intent and Generate Response ---
@@ -1651,11 +1621,24 @@ func (l *ServiceImpl) ContinueSessionStreamed(
 	assistantMessageType := locitypes.TypeResponse
 	itineraryModifiedByThisTurn := false
 
-	switch intent { // Align with ContinueSession's string-based intents
+	switch intentType {
+	case locitypes.IntentClarification:
+		finalResponseMessage = clarificationQuestion(intent)
+		assistantMessageType = locitypes.TypeClarification
+		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
+			Type:    locitypes.EventTypeClarification,
+			Message: finalResponseMessage,
+			Data: map[string]interface{}{
+				"intent":     string(intent.Type),
+				"confidence": intent.Confidence,
+				"entities":   intent.Entities,
+			},
+		}, 3)
+
 	case locitypes.IntentAddPOI:
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeProgress, Data: "Processing: Adding Point of Interest with semantic enhancement..."}, 3)
 		var genErr error
-		finalResponseMessage, genErr = l.handleSemanticAddPOIStreamed(ctx, message, session, semanticPOIs, userLocation, cityID, eventCh)
+		finalResponseMessage, genErr = l.handleSemanticAddPOIStreamed(ctx, message, intent, session, semanticPOIs, userLocation, cityID, eventCh)
 		if genErr != nil {
 			finalResponseMessage = "I had trouble understanding your request. Could you please specify which POI you'd like to add?"
 			assistantMessageType = locitypes.TypeError
@@ -1665,22 +1648,28 @@ func (l *ServiceImpl) ContinueSessionStreamed(
 
 	case locitypes.IntentRemovePOI:
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeProgress, Data: "Processing: Removing Point of Interest with semantic understanding..."}, 3)
-		finalResponseMessage = l.handleSemanticRemovePOI(ctx, message, session)
+		finalResponseMessage = l.handleSemanticRemovePOI(ctx, message, intent, session)
 		if strings.Contains(finalResponseMessage, "I've removed") {
 			itineraryModifiedByThisTurn = true
 		}
 
 	case locitypes.IntentAskQuestion:
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeProgress, Data: "Processing: Answering your question with semantic context..."}, 3)
-		finalResponseMessage = "I’m here to help! For now, I’ll assume you’re asking about your trip. What specifically would you like to know?"
+		answer, err := l.answerFromConversation(ctx, session, convo, message)
+		if err != nil {
+			l.logger.WarnContext(ctx, "Failed to answer question", slog.Any("error", err))
+			span.RecordError(err)
+			answer = "I’m here to help! For now, I’ll assume you’re asking about your trip. What specifically would you like to know?"
+		}
+		finalResponseMessage = answer
 
-	case "replace_poi":
+	case locitypes.IntentReplacePOI:
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeProgress, Data: "Processing: Replacing Point of Interest..."}, 3)
-		if matches := regexp.MustCompile(`replace\s+(.+?)\s+with\s+(.+?)(?:\s+in\s+my\s+itinerary)?`).FindStringSubmatch(strings.ToLower(message)); len(matches) == 3 {
-			oldPOI := matches[1]
-			newPOIName := matches[2]
+		oldPOI, _ := intent.Entities[EntityPOIName].(string)
+		newPOIName, _ := intent.Entities[EntityReplacement].(string)
+		if oldPOI != "" && newPOIName != "" && session.CurrentItinerary != nil {
 			for i, p := range session.CurrentItinerary.AIItineraryResponse.PointsOfInterest {
-				if strings.Contains(strings.ToLower(p.Name), oldPOI) {
+				if strings.Contains(strings.ToLower(p.Name), strings.ToLower(oldPOI)) {
 					newPOI, err := l.generatePOIDataStream(ctx, newPOIName, session.SessionContext.CityName, userLocation, session.UserID, cityID, eventCh)
 					if err != nil {
 						finalResponseMessage = fmt.Sprintf("Could not replace %s with %s due to an error: %v", oldPOI, newPOIName, err)
@@ -1743,7 +1732,7 @@ func (l *ServiceImpl) ContinueSessionStreamed(
 			}
 		}
 
-		if (intent == locitypes.IntentAddPOI || intent == locitypes.IntentModifyItinerary) && userLocation != nil && userLocation.UserLat != 0 && userLocation.UserLon != 0 {
+		if (intentType == locitypes.IntentAddPOI || intentType == locitypes.IntentModifyItinerary) && userLocation != nil && userLocation.UserLat != 0 && userLocation.UserLon != 0 {
 			sortedPOIs, err := l.llmInteractionRepo.GetPOIsBySessionSortedByDistance(ctx, sessionID, cityID, *userLocation)
 			if err != nil {
 				l.logger.WarnContext(ctx, "Failed to sort POIs by distance", slog.Any("error", err))
@@ -1836,7 +1825,7 @@ func (l *ServiceImpl) ContinueSessionStreamed(
 		},
 	}, 3)
 
-	l.logger.InfoContext(ctx, "Streamed session continued", slog.String("sessionID", sessionID.String()), slog.String("intent", string(intent)))
+	l.logger.InfoContext(ctx, "Streamed session continued", slog.String("sessionID", sessionID.String()), slog.String("intent", string(intentType)))
 	return nil
 }
 
@@ -1854,18 +1843,6 @@ func (l *ServiceImpl) generatePOIDataStream(
 	config := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.2)}
 	startTime := time.Now()
 
-	var responseTextBuilder strings.Builder
-	iter, err := l.aiClient.GenerateContentStream(ctx, prompt, config)
-	if err != nil {
-		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-			Type:      locitypes.EventTypeError,
-			Error:     fmt.Sprintf("Failed to generate POI data for '%s': %v", poiName, err),
-			Timestamp: time.Now(),
-			EventID:   uuid.New().String(),
-		}, 3)
-		return locitypes.POIDetailedInfo{}, fmt.Errorf("AI stream init failed for POI '%s': %w", poiName, err)
-	}
-
 	l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
 		Type:      locitypes.EventTypeProgress,
 		Data:      map[string]string{"status": fmt.Sprintf("Getting details for %s...", poiName)},
@@ -1873,59 +1850,26 @@ func (l *ServiceImpl) generatePOIDataStream(
 		EventID:   uuid.New().String(),
 	}, 3)
 
-	for resp, err := range iter {
-		if err != nil {
-			l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-				Type:      locitypes.EventTypeError,
-				Error:     fmt.Sprintf("Streaming failed for POI '%s': %v", poiName, err),
-				Timestamp: time.Now(),
-				EventID:   uuid.New().String(),
-			}, 3)
-			return locitypes.POIDetailedInfo{}, fmt.Errorf("streaming POI details for '%s' failed: %w", poiName, err)
-		}
-		for _, cand := range resp.Candidates {
-			if cand.Content != nil {
-				for _, part := range cand.Content.Parts {
-					if part.Text != "" {
-						responseTextBuilder.WriteString(string(part.Text))
-						l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-							Type:      "poi_detail_chunk",
-							Data:      map[string]string{"poi_name": poiName, "chunk": string(part.Text)},
-							Timestamp: time.Now(),
-							EventID:   uuid.New().String(),
-						}, 3)
-					}
-				}
-			}
-		}
-	}
-
-	if ctx.Err() != nil {
-		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
-			Type:      locitypes.EventTypeError,
-			Error:     ctx.Err().Error(),
-			Timestamp: time.Now(),
-			EventID:   uuid.New().String(),
-		}, 3)
-		return locitypes.POIDetailedInfo{}, fmt.Errorf("context cancelled during POI detail generation: %w", ctx.Err())
-	}
-
-	fullText := responseTextBuilder.String()
-	if fullText == "" {
+	ctx = llm.WithServedBackend(ctx)
+	result, err := llm.GenerateStructured[locitypes.POIDetailedInfo](ctx, l.aiClient, prompt, config, l.structuredOptions("poi_details"))
+	if err != nil {
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{
 			Type:      locitypes.EventTypeError,
-			Error:     fmt.Sprintf("Empty response for POI '%s'", poiName),
+			Error:     fmt.Sprintf("Failed to generate POI data for '%s': %v", poiName, err),
 			Timestamp: time.Now(),
 			EventID:   uuid.New().String(),
 		}, 3)
-		return locitypes.POIDetailedInfo{Name: poiName, DescriptionPOI: "Details not found."}, fmt.Errorf("empty response for POI details '%s'", poiName)
+		return locitypes.POIDetailedInfo{}, fmt.Errorf("failed to generate POI details for '%s': %w", poiName, err)
 	}
+	span.SetAttributes(attribute.Int("response.attempts", result.Attempts))
 
 	// Save LLM interaction
 	interaction := locitypes.LlmInteraction{
 		UserID:       userID,
 		Prompt:       prompt,
-		ResponseText: fullText,
+		ResponseText: result.JSON,
+		ModelUsed:    llm.ModelName(ctx, l.aiClient),
+		Provider:     llm.ProviderName(ctx, l.aiClient),
 		Timestamp:    startTime,
 		CityName:     cityName,
 	}
@@ -1940,21 +1884,8 @@ func (l *ServiceImpl) generatePOIDataStream(
 		return locitypes.POIDetailedInfo{}, fmt.Errorf("failed to save LLM interaction: %w", err)
 	}
 
-	// Parse response
-	cleanJSON := CleanJSONResponse(fullText)
-	var poiData locitypes.POIDetailedInfo
-	if err := json.Unmarshal([]byte(cleanJSON), &poiData); err != nil || poiData.Name == "" {
-		l.logger.WarnContext(ctx, "Invalid POI data from LLM", slog.String("response", fullText), slog.Any("error", err))
-		poiData = locitypes.POIDetailedInfo{
-			ID:             uuid.New(),
-			Name:           poiName,
-			Category:       "Attraction",
-			DescriptionPOI: fmt.Sprintf("Added %s based on user request, but detailed data not available.", poiName),
-		}
-	}
-	if poiData.ID == uuid.Nil {
-		poiData.ID = uuid.New()
-	}
+	poiData := result.Value
+	poiData.ID = uuid.New()
 	poiData.LlmInteractionID = llmInteractionID
 	poiData.City = cityName
 
@@ -2017,7 +1948,7 @@ func (l *ServiceImpl) saveCityInteraction(ctx context.Context, interaction locit
 }
 
 // handleSemanticAddPOIStreamed handles adding POIs with semantic search enhancement and streaming updates
-func (l *ServiceImpl) handleSemanticAddPOIStreamed(ctx context.Context, message string, session *locitypes.ChatSession, semanticPOIs []locitypes.POIDetailedInfo, userLocation *locitypes.UserLocation, cityID uuid.UUID, eventCh chan<- locitypes.StreamEvent) (string, error) {
+func (l *ServiceImpl) handleSemanticAddPOIStreamed(ctx context.Context, message string, intent locitypes.Intent, session *locitypes.ChatSession, semanticPOIs []locitypes.POIDetailedInfo, userLocation *locitypes.UserLocation, cityID uuid.UUID, eventCh chan<- locitypes.StreamEvent) (string, error) {
 	ctx, span := otel.Tracer("LlmInteractionService").Start(ctx, "handleSemanticAddPOIStreamed")
 	defer span.End()
 
@@ -2105,7 +2036,7 @@ func (l *ServiceImpl) handleSemanticAddPOIStreamed(ctx context.Context, message
 		Data: map[string]interface{}{"status": "extracting_poi_name"},
 	}, 3)
 
-	poiName := extractPOIName(message)
+	poiName := intentPOIName(intent, message)
 	if poiName == "" {
 		return "I'd be happy to add a POI to your itinerary! Could you please specify which place you'd like to add?", nil
 	}
@@ -2155,9 +2086,10 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 		attribute.String("message", message),
 	))
 	defer span.End()
+	ctx = llm.WithServedBackend(ctx)
 
 	// Extract city and clean message
-	extractedCity, cleanedMessage, err := l.extractCityFromMessage(ctx, message)
+	extractedCity, cleanedMessage, err := l.extractCityFromMessage(ctx, message, userLocation)
 	if err != nil {
 		span.RecordError(err)
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeError, Error: err.Error()}, 3)
@@ -2221,6 +2153,10 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 		return fmt.Errorf("failed to create session: %w", err)
 	}
 
+	if l.agent != nil {
+		return l.runChatAgent(ctx, &session, cleanedMessage, domain, userLocation, eventCh)
+	}
+
 	// Generate cache key based on session parameters
 	cacheKeyData := map[string]interface{}{
 		"user_id":     userID.String(),
@@ -2267,6 +2203,9 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 						if responses[partType] == nil {
 							responses[partType] = &strings.Builder{}
 						}
+						if replace, _ := data["replace"].(bool); replace {
+							responses[partType].Reset()
+						}
 						responses[partType].WriteString(chunk)
 					}
 				}
@@ -2390,8 +2329,9 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 					cityDataContent = content
 				}
 
-				// Extract JSON from markdown code blocks if present
-				content = extractJSONFromMarkdown(content)
+				// Parts are validated as they stream; this only drops a code fence around
+				// responses cached before that.
+				content = llm.ExtractJSON(content)
 
 				// Try to parse as JSON
 				var parsedJSON interface{}
@@ -2476,7 +2416,8 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 			CityName:     cityName,
 			Prompt:       fmt.Sprintf("Unified Chat Stream - Domain: %s, Message: %s", domain, cleanedMessage),
 			ResponseText: fullResponse,
-			ModelUsed:    model,
+			ModelUsed:    llm.ModelName(ctx, l.aiClient),
+			Provider:     llm.ProviderName(ctx, l.aiClient),
 			LatencyMs:    int(time.Since(startTime).Milliseconds()),
 			Timestamp:    startTime,
 		}
@@ -2775,8 +2716,9 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 		for partType, builder := range responses {
 			if builder != nil && builder.Len() > 0 {
 				content := builder.String()
-				// Extract JSON from markdown code blocks if present
-				content = extractJSONFromMarkdown(content)
+				// Parts are validated as they stream; this only drops a code fence around
+				// responses cached before that.
+				content = llm.ExtractJSON(content)
 
 				// Try to parse as JSON
 				var parsedJSON interface{}
@@ -2828,7 +2770,8 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStream(ctx context.Context, userI
 			CityName:     cityName,
 			Prompt:       fmt.Sprintf("Unified Chat Stream - Domain: %s, Message: %s", domain, cleanedMessage),
 			ResponseText: fullResponse,
-			ModelUsed:    model,
+			ModelUsed:    llm.ModelName(ctx, l.aiClient),
+			Provider:     llm.ProviderName(ctx, l.aiClient),
 			LatencyMs:    int(time.Since(startTime).Milliseconds()),
 			Timestamp:    startTime,
 		}
@@ -2857,9 +2800,10 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 		attribute.String("message", message),
 	))
 	defer span.End()
+	ctx = llm.WithServedBackend(ctx)
 
 	// Extract city and clean message
-	extractedCity, cleanedMessage, err := l.extractCityFromMessage(ctx, message)
+	extractedCity, cleanedMessage, err := l.extractCityFromMessage(ctx, message, userLocation)
 	if err != nil {
 		span.RecordError(err)
 		l.sendEvent(ctx, eventCh, locitypes.StreamEvent{Type: locitypes.EventTypeError, Error: err.Error()}, 3)
@@ -2900,6 +2844,10 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 		return fmt.Errorf("failed to create session: %w", err)
 	}
 
+	if l.agent != nil {
+		return l.runChatAgent(ctx, &session, cleanedMessage, domain, userLocation, eventCh)
+	}
+
 	// Generate cache key based on session parameters
 	cacheKeyData := map[string]interface{}{
 		"city":    normalizeCacheComponent(cityName),
@@ -2943,6 +2891,9 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 						if responses[partType] == nil {
 							responses[partType] = &strings.Builder{}
 						}
+						if replace, _ := data["replace"].(bool); replace {
+							responses[partType].Reset()
+						}
 						responses[partType].WriteString(chunk)
 					}
 				}
@@ -3064,8 +3015,9 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 					cityDataContent = content
 				}
 
-				// Extract JSON from markdown code blocks if present
-				content = extractJSONFromMarkdown(content)
+				// Parts are validated as they stream; this only drops a code fence around
+				// responses cached before that.
+				content = llm.ExtractJSON(content)
 
 				// Try to parse as JSON
 				var parsedJSON interface{}
@@ -3150,7 +3102,8 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 			CityName:     cityName,
 			Prompt:       fmt.Sprintf("Unified Chat Stream - Domain: %s, Message: %s", domain, cleanedMessage),
 			ResponseText: fullResponse,
-			ModelUsed:    model,
+			ModelUsed:    llm.ModelName(ctx, l.aiClient),
+			Provider:     llm.ProviderName(ctx, l.aiClient),
 			LatencyMs:    int(time.Since(startTime).Milliseconds()),
 			Timestamp:    startTime,
 		}
@@ -3417,7 +3370,8 @@ func (l *ServiceImpl) ProcessUnifiedChatMessageStreamFree(ctx context.Context, c
 			CityName:     cityName,
 			Prompt:       fmt.Sprintf("Unified Chat Stream - Domain: %s, Message: %s", domain, cleanedMessage),
 			ResponseText: fullResponse,
-			ModelUsed:    model,
+			ModelUsed:    llm.ModelName(ctx, l.aiClient),
+			Provider:     llm.ProviderName(ctx, l.aiClient),
 			LatencyMs:    int(time.Since(startTime).Milliseconds()),
 			Timestamp:    startTime,
 		}
@@ -3458,37 +3412,10 @@ func (l *ServiceImpl) ensureItineraryExists(session *locitypes.ChatSession) {
 
 // parseCityDataFromResponse extracts and parses city data from streamed response content
 func (l *ServiceImpl) parseCityDataFromResponse(_ context.Context, responseContent string) (*locitypes.GeneralCityData, error) {
-	// Clean the response by extracting JSON content between ```json and ```
-	cleanedResponse := responseContent
-
-	// Look for JSON blocks in the response
-	if strings.Contains(responseContent, "```json") {
-		start := strings.Index(responseContent, "```json")
-		if start != -1 {
-			start += len("```json")
-			end := strings.Index(responseContent[start:], "```")
-			if end != -1 {
-				cleanedResponse = strings.TrimSpace(responseContent[start : start+end])
-			}
-		}
-	}
-
-	// Validate JSON
-	if !json.Valid([]byte(cleanedResponse)) {
-		return nil, fmt.Errorf("invalid JSON in city data response")
-	}
-
-	// Try to parse as GeneralCityData
-	var generalCity locitypes.GeneralCityData
-	if err := json.Unmarshal([]byte(cleanedResponse), &generalCity); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal city data: %w", err)
-	}
-
-	// Validate that we have minimum required city data
-	if generalCity.City == "" {
-		return nil, fmt.Errorf("parsed city data is missing city name")
+	generalCity, _, err := llm.DecodeStructured[locitypes.GeneralCityData](responseContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid city data response: %w", err)
 	}
-
 	return &generalCity, nil
 }
 
@@ -3544,7 +3471,14 @@ func (l *ServiceImpl) streamWorkerWithResponseAndCache(ctx context.Context, prom
 		slog.String("cache_key", cacheKey),
 		slog.Int("prompt_length", len(prompt)))
 
-	iter, err := l.aiClient.GenerateContentStreamWithCache(ctx, prompt, &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)}, cacheKey)
+	config := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](defaultTemperature)}
+	part, structured := responseParts[partType]
+	if structured {
+		config = llm.WithResponseSchema(config, part.schema)
+	}
+
+	llmStart := time.Now()
+	iter, err := l.aiClient.GenerateContentStreamWithCache(ctx, prompt, config, cacheKey)
 	if err != nil {
 		l.logger.ErrorContext(ctx, "LLM call failed",
 			slog.String("part_type", partType),
@@ -3561,6 +3495,9 @@ func (l *ServiceImpl) streamWorkerWithResponseAndCache(ctx context.Context, prom
 	// Step 3: Stream response and collect full text for caching
 	var fullResponse strings.Builder
 	chunkCount := 0
+	defer func() {
+		observability.LLMStreamChunks.WithLabelValues(string(domain)).Observe(float64(chunkCount))
+	}()
 	for resp, err := range iter {
 		if ctx.Err() != nil {
 			l.logger.WarnContext(ctx, "Context canceled during streaming",
@@ -3586,6 +3523,9 @@ func (l *ServiceImpl) streamWorkerWithResponseAndCache(ctx context.Context, prom
 					if part.Text != "" {
 						chunk := string(part.Text)
 						chunkCount++
+						if chunkCount == 1 {
+							observability.LLMTimeToFirstToken.WithLabelValues(string(domain)).Observe(time.Since(llmStart).Seconds())
+						}
 						fullResponse.WriteString(chunk)
 
 						// Log first few chunks for debugging
@@ -3618,13 +3558,47 @@ func (l *ServiceImpl) streamWorkerWithResponseAndCache(ctx context.Context, prom
 		slog.Int("total_chunks", chunkCount),
 		slog.Int("total_response_length", fullResponse.Len()))
 
-	// Step 4: Save full response to cache if cacheKey is provided
-	if cacheKey != "" && fullResponse.Len() > 0 {
-		l.cache.Set(cacheKey, fullResponse.String(), cache.DefaultExpiration)
+	// Step 4: Check the reply against its schema. A broken reply is repaired with a
+	// follow-up call and replaces the streamed chunks; it is never cached.
+	response := fullResponse.String()
+	if structured && response != "" {
+		clean, err := part.validate(response)
+		if err != nil {
+			clean, err = part.repair(ctx, l.aiClient, prompt, config, response, err, l.structuredOptions(partType))
+			if err != nil {
+				l.logger.ErrorContext(ctx, "LLM response could not be repaired",
+					slog.String("part_type", partType),
+					slog.Any("error", err))
+				if ctx.Err() == nil {
+					sendEvent(locitypes.StreamEvent{
+						Type:  locitypes.EventTypeError,
+						Error: fmt.Sprintf("%s response was malformed", partType),
+					})
+				}
+				return
+			}
+			sendEvent(locitypes.StreamEvent{
+				Type: locitypes.EventTypeChunk,
+				Data: map[string]interface{}{
+					"part":       partType,
+					"chunk":      clean,
+					"domain":     string(domain),
+					"cache_key":  cacheKey,
+					"cache_used": false,
+					"replace":    true,
+				},
+			})
+		}
+		response = clean
+	}
+
+	// Step 5: Save the response to cache if cacheKey is provided
+	if cacheKey != "" && response != "" {
+		l.cache.Set(cacheKey, response, cache.DefaultExpiration)
 		l.logger.InfoContext(ctx, "Saved LLM response to cache",
 			slog.String("part_type", partType),
 			slog.String("cache_key", cacheKey),
-			slog.Int("response_length", fullResponse.Len()))
+			slog.Int("response_length", len(response)))
 	}
 }
 
@@ -3701,32 +3675,3 @@ func convertHotelsToPOIs(hotels []locitypes.HotelDetailedInfo) []locitypes.POIDe
 	}
 	return pois
 }
-
-// extractJSONFromMarkdown extracts JSON content from markdown code blocks
-func extractJSONFromMarkdown(content string) string {
-	// Remove markdown code block delimiters
-	lines := strings.Split(content, "\n")
-	var jsonLines []string
-	inCodeBlock := false
-
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if trimmedLine == "```json" || trimmedLine == "```" {
-			inCodeBlock = !inCodeBlock
-			continue
-		}
-		if inCodeBlock || (!strings.HasPrefix(trimmedLine, "```") && (strings.HasPrefix(trimmedLine, "{") || strings.HasPrefix(trimmedLine, "[") || len(jsonLines) > 0)) {
-			jsonLines = append(jsonLines, line)
-		}
-	}
-
-	result := strings.Join(jsonLines, "\n")
-	result = strings.TrimSpace(result)
-
-	// If no JSON was extracted, return the original content
-	if result == "" {
-		return strings.TrimSpace(content)
-	}
-
-	return result
-}
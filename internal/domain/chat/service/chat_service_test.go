@@ -2,11 +2,17 @@ package service
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"testing"
 
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city"
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/genai" // For genai.GenerateContentConfig
 )
 
@@ -110,6 +116,14 @@ func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUI
 	return args.Get(0).([]locitypes.POIDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error) {
+	args := m.Called(ctx, poiID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*locitypes.POIDetailedInfo), args.Error(1)
+}
+
 func (m *MockPOIRepository) FindPOIDetailedInfos(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) (*locitypes.POIDetailedInfo, error) {
 	args := m.Called(ctx, cityID, lat, lon, tolerance)
 	if args.Get(0) == nil {
@@ -123,12 +137,12 @@ func (m *MockPOIRepository) SavePOIDetailedInfos(ctx context.Context, poi locity
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error) {
+func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.Int(1), args.Error(2)
 	}
-	return args.Get(0).([]locitypes.POIDetailedInfo), args.Error(1)
+	return args.Get(0).([]locitypes.POIDetailedInfo), args.Int(1), args.Error(2)
 }
 
 func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]locitypes.POIDetailedInfo, error) {
@@ -218,6 +232,38 @@ func (m *MockPOIRepository) GetItinerary(ctx context.Context, userID, itineraryI
 	return args.Get(0).(*locitypes.UserSavedItinerary), args.Error(1)
 }
 
+func (m *MockPOIRepository) GetPOIsByIDs(ctx context.Context, poiIDs []uuid.UUID) (map[uuid.UUID]*locitypes.POIDetailedInfo, error) {
+	args := m.Called(ctx, poiIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.POIDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetHotelsByIDs(ctx context.Context, hotelIDs []uuid.UUID) (map[uuid.UUID]*locitypes.HotelDetailedInfo, error) {
+	args := m.Called(ctx, hotelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.HotelDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetRestaurantsByIDs(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*locitypes.RestaurantDetailedInfo, error) {
+	args := m.Called(ctx, restaurantIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.RestaurantDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetItinerariesByIDs(ctx context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error) {
+	args := m.Called(ctx, userID, itineraryIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.UserSavedItinerary), args.Error(1)
+}
+
 func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]locitypes.UserSavedItinerary, int, error) {
 	args := m.Called(ctx, userID, page, pageSize)
 	if args.Get(0) == nil {
@@ -295,6 +341,14 @@ func (m *MockCityRepository) FindSimilarCities(ctx context.Context, queryEmbeddi
 	return args.Get(0).([]locitypes.CityDetail), args.Error(1)
 }
 
+func (m *MockCityRepository) GetCityByID(ctx context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*locitypes.CityDetail), args.Error(1)
+}
+
 func (m *MockCityRepository) UpdateCityEmbedding(ctx context.Context, cityID uuid.UUID, embedding []float32) error {
 	args := m.Called(ctx, cityID, embedding)
 	return args.Error(0)
@@ -457,6 +511,32 @@ func (m *MockinterestsRepo) GetInterestsForProfile(ctx context.Context, profileI
 	return args.Get(0).([]*locitypes.Interest), args.Error(1)
 }
 
+func (m *MockinterestsRepo) GetAvailableInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetDefaultProfileID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockinterestsRepo) UpdatePreferenceLevel(ctx context.Context, profileID uuid.UUID, interestIDs []uuid.UUID, level int) (int64, error) {
+	args := m.Called(ctx, profileID, interestIDs, level)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 type MockSearchProfileRepo struct{ mock.Mock }
 
 func (m *MockSearchProfileRepo) GetSearchProfiles(ctx context.Context, userID uuid.UUID) ([]locitypes.UserPreferenceProfileResponse, error) {
@@ -578,3 +658,31 @@ func (m *MockTagsRepo) GetTagsForProfile(ctx context.Context, profileID uuid.UUI
 
 // --- Integration Tests for llmInteraction (Example for GetPOIDetailedInfosResponse) ---
 // These would require a running database instance and potentially a configured AI client.
+
+func TestExtractCityFromMessage_GazetteerBeforeLLM(t *testing.T) {
+	var prompts []string
+	client := &TestLLMClient{GenerateResponseFn: func(_ context.Context, prompt string, _ *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+		prompts = append(prompts, prompt)
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(
+			`{"city": "Springfield", "message": "diners"}`, genai.RoleModel)}}}, nil
+	}}
+	svc := &ServiceImpl{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), aiClient: client}
+	svc.WithGazetteer(city.NewGazetteer([]city.GazetteerEntry{
+		{Name: "Lisbon", Aliases: []string{"Lisboa"}, CountryCode: "PT", Latitude: 38.7167, Longitude: -9.1333, Population: 517802},
+		{Name: "Springfield", RegionCode: "IL", CountryCode: "US", Population: 114000},
+		{Name: "Springfield", RegionCode: "MO", CountryCode: "US", Population: 169000},
+	}, false))
+
+	cityName, cleaned, err := svc.extractCityFromMessage(context.Background(), "Restaurantes em Lisboa", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Lisbon", cityName)
+	assert.Equal(t, "Restaurantes", cleaned)
+	assert.Empty(t, prompts, "a confident gazetteer match needs no LLM call")
+
+	// Two Springfields of similar size and no location: the LLM decides.
+	cityName, cleaned, err = svc.extractCityFromMessage(context.Background(), "Springfield diners", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Springfield", cityName)
+	assert.Equal(t, "diners", cleaned)
+	assert.Len(t, prompts, 1)
+}
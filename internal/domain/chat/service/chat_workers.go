@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -14,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 )
 
@@ -30,54 +30,19 @@ func (l *ServiceImpl) GenerateCityDataWorker(ctx context.Context, wg *sync.WaitG
 		prompt := getCityDescriptionPrompt(cityName)
 		span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
 
-		response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+		result, err := llm.GenerateStructured[locitypes.GeneralCityData](ctx, l.aiClient, prompt, config, l.structuredOptions("city_data"))
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "Failed to generate city data")
 			resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate city data: %w", err)}
 			return
 		}
-
-		var txt string
-		for _, candidate := range response.Candidates {
-			if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-				txt = candidate.Content.Parts[0].Text
-				break
-			}
-		}
-		if txt == "" {
-			err := fmt.Errorf("no valid city data content from AI")
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Empty response from AI")
-			resultCh <- locitypes.GenAIResponse{Err: err}
-			return
-		}
-		span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-		cleanTxt := CleanJSONResponse(txt)
-		var cityDataFromAI struct {
-			CityName        string  `json:"city_name"`
-			StateProvince   *string `json:"state_province"` // Use pointer for nullable string
-			Country         string  `json:"country"`
-			CenterLatitude  float64 `json:"center_latitude"`
-			CenterLongitude float64 `json:"center_longitude"`
-			Description     string  `json:"description"`
-			// BoundingBox     string  `json:"bounding_box,omitempty"` // If trying to get BBox string
-		}
-		if err := json.Unmarshal([]byte(cleanTxt), &cityDataFromAI); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Failed to parse city data JSON")
-			resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse city data JSON: %w", err)}
-			return
-		}
-
-		stateProvinceValue := ""
-		if cityDataFromAI.StateProvince != nil {
-			stateProvinceValue = *cityDataFromAI.StateProvince
-		}
+		cityDataFromAI := result.Value
 
 		span.SetAttributes(
-			attribute.String("city.name", cityDataFromAI.CityName),
+			attribute.Int("response.length", len(result.JSON)),
+			attribute.Int("response.attempts", result.Attempts),
+			attribute.String("city.name", cityDataFromAI.City),
 			attribute.String("city.country", cityDataFromAI.Country),
 			attribute.Float64("city.latitude", cityDataFromAI.CenterLatitude),
 			attribute.Float64("city.longitude", cityDataFromAI.CenterLongitude),
@@ -85,9 +50,9 @@ func (l *ServiceImpl) GenerateCityDataWorker(ctx context.Context, wg *sync.WaitG
 		span.SetStatus(codes.Ok, "City data generated successfully")
 
 		resultCh <- locitypes.GenAIResponse{
-			City:            cityDataFromAI.CityName,
+			City:            cityDataFromAI.City,
 			Country:         cityDataFromAI.Country,
-			StateProvince:   stateProvinceValue,
+			StateProvince:   cityDataFromAI.StateProvince,
 			CityDescription: cityDataFromAI.Description,
 			Latitude:        cityDataFromAI.CenterLatitude,
 			Longitude:       cityDataFromAI.CenterLongitude,
@@ -109,7 +74,7 @@ func (l *ServiceImpl) GenerateGeneralPOIWorker(ctx context.Context, wg *sync.Wai
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
 
 	startTime := time.Now()
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, l.aiClient, prompt, config, l.structuredOptions("general_pois"))
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -119,33 +84,11 @@ func (l *ServiceImpl) GenerateGeneralPOIWorker(ctx context.Context, wg *sync.Wai
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := CleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(result.JSON)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
@@ -170,43 +113,20 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorker(ctx context.Context, wg *syn
 	prompt := getPersonalizedPOI(interestNames, cityName, tagsPromptPart, userPrefs)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
 
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+	ctx = llm.WithServedBackend(ctx)
+	result, err := llm.GenerateStructured[locitypes.AIItineraryResponse](ctx, l.aiClient, prompt, config, l.structuredOptions("itinerary"))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to generate personalized itinerary")
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate personalized itinerary: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid personalized itinerary content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := CleanJSONResponse(txt)
-	var itineraryData struct {
-		ItineraryName      string                      `json:"itinerary_name"`
-		OverallDescription string                      `json:"overall_description"`
-		PointsOfInterest   []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-
-	if err := json.Unmarshal([]byte(cleanTxt), &itineraryData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse personalized itinerary JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse personalized itinerary JSON: %w", err)}
-		return
-	}
+	itineraryData := result.Value
+	txt := result.JSON
+	span.SetAttributes(
+		attribute.Int("response.length", len(txt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 	span.SetAttributes(
 		attribute.String("itinerary.name", itineraryData.ItineraryName),
 		attribute.Int("personalized_pois.count", len(itineraryData.PointsOfInterest)),
@@ -220,7 +140,8 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorker(ctx context.Context, wg *syn
 		SessionID:    sessionID,
 		Prompt:       prompt,
 		ResponseText: txt,
-		ModelUsed:    model, // Adjust based on your AI client
+		ModelUsed:    llm.ModelName(ctx, l.aiClient),
+		Provider:     llm.ProviderName(ctx, l.aiClient),
 		LatencyMs:    latencyMs,
 		CityName:     cityName,
 		// request payload
@@ -268,43 +189,20 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorkerWithSemantics(ctx context.Con
 	prompt := l.getPersonalizedPOIWithSemanticContext(interestNames, cityName, tagsPromptPart, userPrefs, semanticPOIs)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
 
-	response, err := l.aiClient.GenerateResponse(ctx, prompt, config)
+	ctx = llm.WithServedBackend(ctx)
+	result, err := llm.GenerateStructured[locitypes.AIItineraryResponse](ctx, l.aiClient, prompt, config, l.structuredOptions("itinerary"))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to generate semantic-enhanced personalized itinerary")
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate semantic-enhanced personalized itinerary: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid semantic-enhanced personalized itinerary content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := CleanJSONResponse(txt)
-	var itineraryData struct {
-		ItineraryName      string                      `json:"itinerary_name"`
-		OverallDescription string                      `json:"overall_description"`
-		PointsOfInterest   []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-
-	if err := json.Unmarshal([]byte(cleanTxt), &itineraryData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse semantic-enhanced personalized itinerary JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse semantic-enhanced personalized itinerary JSON: %w", err)}
-		return
-	}
+	itineraryData := result.Value
+	txt := result.JSON
+	span.SetAttributes(
+		attribute.Int("response.length", len(txt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 	span.SetAttributes(
 		attribute.String("itinerary.name", itineraryData.ItineraryName),
 		attribute.Int("personalized_pois.count", len(itineraryData.PointsOfInterest)),
@@ -318,7 +216,8 @@ func (l *ServiceImpl) GeneratePersonalisedPOIWorkerWithSemantics(ctx context.Con
 		SessionID:    sessionID,
 		Prompt:       prompt,
 		ResponseText: txt,
-		ModelUsed:    model,
+		ModelUsed:    llm.ModelName(ctx, l.aiClient),
+		Provider:     llm.ProviderName(ctx, l.aiClient),
 		LatencyMs:    latencyMs,
 		CityName:     cityName,
 	}
@@ -3,6 +3,7 @@ package city
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -24,6 +25,7 @@ type Repository interface {
 	FindCityByNameAndCountry(ctx context.Context, city, country string) (*locitypes.CityDetail, error)
 	FindCityByFuzzyName(ctx context.Context, cityName string) (*locitypes.CityDetail, error)
 	GetCityIDByName(ctx context.Context, cityName string) (uuid.UUID, error)
+	GetCityByID(ctx context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error)
 	GetAllCities(ctx context.Context) ([]locitypes.CityDetail, error)
 
 	// Vector similarity search methods
@@ -265,6 +267,57 @@ func (r *RepositoryImpl) GetCityIDByName(ctx context.Context, cityName string) (
 	return cityID, nil
 }
 
+// GetCityByID retrieves a city by its ID. Returns locitypes.ErrNotFound if it does not exist.
+func (r *RepositoryImpl) GetCityByID(ctx context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error) {
+	ctx, span := otel.Tracer("CityRepository").Start(ctx, "GetCityByID", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+	))
+	defer span.End()
+
+	query := `
+        SELECT
+            id, name, country,
+            COALESCE(state_province, '') as state_province,
+            COALESCE(ai_summary, '') as ai_summary,
+            ST_Y(center_location) as center_latitude,
+            ST_X(center_location) as center_longitude
+        FROM cities
+        WHERE id = $1
+    `
+
+	var cityDetail locitypes.CityDetail
+	var lat, lon sql.NullFloat64
+
+	err := r.pgpool.QueryRow(ctx, query, cityID).Scan(
+		&cityDetail.ID,
+		&cityDetail.Name,
+		&cityDetail.Country,
+		&cityDetail.StateProvince,
+		&cityDetail.AiSummary,
+		&lat,
+		&lon,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "City not found")
+			return nil, fmt.Errorf("city %s: %w", cityID, locitypes.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Database query failed")
+		return nil, fmt.Errorf("failed to get city %s: %w", cityID, err)
+	}
+
+	if lat.Valid {
+		cityDetail.CenterLatitude = lat.Float64
+	}
+	if lon.Valid {
+		cityDetail.CenterLongitude = lon.Float64
+	}
+
+	span.SetStatus(codes.Ok, "City retrieved")
+	return &cityDetail, nil
+}
+
 // FindSimilarCities finds cities similar to the provided query embedding using cosine similarity
 func (r *RepositoryImpl) FindSimilarCities(ctx context.Context, queryEmbedding []float32, limit int) ([]locitypes.CityDetail, error) {
 	ctx, span := otel.Tracer("CityRepository").Start(ctx, "FindSimilarCities", trace.WithAttributes(
@@ -4,26 +4,43 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
-	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
 )
 
 type Service interface {
 	GetAllCities(ctx context.Context) ([]locitypes.CityDetail, error)
+	GetCityByID(ctx context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error)
+	GetCityByName(ctx context.Context, name string) (*locitypes.CityDetail, error)
+	SearchCities(ctx context.Context, query string, limit int) ([]locitypes.CityDetail, error)
 }
 
 type ServiceImpl struct {
-	logger *slog.Logger
-	repo   Repository
+	logger           *slog.Logger
+	repo             Repository
+	embeddingService llm.EmbeddingClient
 }
 
-func NewCityService(repo Repository, logger *slog.Logger) *ServiceImpl {
+// NewCityService creates a city service. embeddingService may be nil, in which case
+// searches fall back to trigram matching only.
+func NewCityService(repo Repository, embeddingService llm.EmbeddingClient, logger *slog.Logger) *ServiceImpl {
 	return &ServiceImpl{
-		logger: logger,
-		repo:   repo,
+		logger:           logger,
+		repo:             repo,
+		embeddingService: embeddingService,
 	}
 }
 
@@ -50,3 +67,125 @@ func (s *ServiceImpl) GetAllCities(ctx context.Context) ([]locitypes.CityDetail,
 
 	return cities, nil
 }
+
+// GetCityByID retrieves a single city by ID.
+func (s *ServiceImpl) GetCityByID(ctx context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error) {
+	ctx, span := otel.Tracer("CityService").Start(ctx, "GetCityByID", trace.WithAttributes(
+		attribute.String("city.id", cityID.String()),
+	))
+	defer span.End()
+
+	city, err := s.repo.GetCityByID(ctx, cityID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Repository operation failed")
+		return nil, fmt.Errorf("failed to get city: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "City retrieved")
+	return city, nil
+}
+
+// GetCityByName resolves a possibly misspelled city name to the closest stored city.
+func (s *ServiceImpl) GetCityByName(ctx context.Context, name string) (*locitypes.CityDetail, error) {
+	ctx, span := otel.Tracer("CityService").Start(ctx, "GetCityByName", trace.WithAttributes(
+		attribute.String("city.name", name),
+	))
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		span.SetStatus(codes.Error, "Empty city name")
+		return nil, fmt.Errorf("city name is required: %w", locitypes.ErrBadRequest)
+	}
+
+	city, err := s.repo.FindCityByFuzzyName(ctx, name)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Repository operation failed")
+		return nil, fmt.Errorf("failed to find city: %w", err)
+	}
+	if city == nil {
+		span.SetStatus(codes.Error, "City not found")
+		return nil, fmt.Errorf("city '%s': %w", name, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "City retrieved")
+	return city, nil
+}
+
+// SearchCities combines the best trigram match on the name with cities whose
+// embeddings are closest to the query, so both typos ("Lisbn") and descriptive
+// queries ("surf town in Portugal") return results. The trigram match comes first.
+func (s *ServiceImpl) SearchCities(ctx context.Context, query string, limit int) ([]locitypes.CityDetail, error) {
+	ctx, span := otel.Tracer("CityService").Start(ctx, "SearchCities", trace.WithAttributes(
+		attribute.String("search.query", query),
+		attribute.Int("search.limit", limit),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "SearchCities"), slog.String("query", query))
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		span.SetStatus(codes.Error, "Empty query")
+		return nil, fmt.Errorf("search query is required: %w", locitypes.ErrBadRequest)
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results := make([]locitypes.CityDetail, 0, limit)
+	seen := make(map[uuid.UUID]bool, limit)
+	add := func(cities ...locitypes.CityDetail) {
+		for _, c := range cities {
+			if len(results) >= limit || seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			results = append(results, c)
+		}
+	}
+
+	fuzzy, fuzzyErr := s.repo.FindCityByFuzzyName(ctx, query)
+	if fuzzyErr != nil {
+		l.WarnContext(ctx, "Trigram city search failed", slog.Any("error", fuzzyErr))
+		span.RecordError(fuzzyErr)
+	} else if fuzzy != nil {
+		add(*fuzzy)
+	}
+
+	semanticOK := false
+	if s.embeddingService != nil {
+		similar, err := s.similarCities(ctx, query, limit)
+		if err != nil {
+			// Embeddings are an enhancement; trigram results are still useful on their own.
+			l.WarnContext(ctx, "Semantic city search failed", slog.Any("error", err))
+			span.RecordError(err)
+		} else {
+			semanticOK = true
+			add(similar...)
+		}
+	}
+
+	if fuzzyErr != nil && !semanticOK {
+		span.SetStatus(codes.Error, "City search failed")
+		return nil, fmt.Errorf("failed to search cities: %w", fuzzyErr)
+	}
+
+	l.DebugContext(ctx, "City search completed", slog.Int("count", len(results)))
+	span.SetAttributes(attribute.Int("results.count", len(results)))
+	span.SetStatus(codes.Ok, "Cities searched")
+	return results, nil
+}
+
+func (s *ServiceImpl) similarCities(ctx context.Context, query string, limit int) ([]locitypes.CityDetail, error) {
+	embedding, err := s.embeddingService.GenerateQueryEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+	return s.repo.FindSimilarCities(ctx, embedding, limit)
+}
@@ -0,0 +1,109 @@
+package city
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// stubRepository implements only the lookups SearchCities uses; anything else panics.
+type stubRepository struct {
+	Repository
+	fuzzy      *locitypes.CityDetail
+	fuzzyErr   error
+	similar    []locitypes.CityDetail
+	lastLimit  int
+	similarHit bool
+}
+
+func (r *stubRepository) FindCityByFuzzyName(_ context.Context, _ string) (*locitypes.CityDetail, error) {
+	return r.fuzzy, r.fuzzyErr
+}
+
+func (r *stubRepository) FindSimilarCities(_ context.Context, _ []float32, limit int) ([]locitypes.CityDetail, error) {
+	r.similarHit = true
+	r.lastLimit = limit
+	return r.similar, nil
+}
+
+type stubEmbeddingClient struct{ err error }
+
+func (c stubEmbeddingClient) GenerateQueryEmbedding(_ context.Context, _ string) ([]float32, error) {
+	return []float32{0.1, 0.2}, c.err
+}
+
+func (c stubEmbeddingClient) GeneratePOIEmbedding(_ context.Context, _, _, _ string) ([]float32, error) {
+	return nil, c.err
+}
+
+func TestSearchCities_MergesFuzzyAndSemanticMatches(t *testing.T) {
+	lisbon := locitypes.CityDetail{ID: uuid.New(), Name: "Lisbon", Country: "Portugal"}
+	porto := locitypes.CityDetail{ID: uuid.New(), Name: "Porto", Country: "Portugal"}
+	repo := &stubRepository{fuzzy: &lisbon, similar: []locitypes.CityDetail{lisbon, porto}}
+	svc := NewCityService(repo, stubEmbeddingClient{}, testutil.NewLogger())
+
+	cities, err := svc.SearchCities(context.Background(), "  Lisbn ", 0)
+	require.NoError(t, err)
+	require.Equal(t, []locitypes.CityDetail{lisbon, porto}, cities)
+	require.Equal(t, defaultSearchLimit, repo.lastLimit)
+}
+
+func TestSearchCities_RespectsLimit(t *testing.T) {
+	lisbon := locitypes.CityDetail{ID: uuid.New(), Name: "Lisbon"}
+	repo := &stubRepository{fuzzy: &lisbon, similar: []locitypes.CityDetail{{ID: uuid.New(), Name: "Porto"}}}
+	svc := NewCityService(repo, stubEmbeddingClient{}, testutil.NewLogger())
+
+	cities, err := svc.SearchCities(context.Background(), "Lisbon", 1)
+	require.NoError(t, err)
+	require.Len(t, cities, 1)
+	require.Equal(t, "Lisbon", cities[0].Name)
+}
+
+func TestSearchCities_FallsBackWhenEmbeddingsFail(t *testing.T) {
+	lisbon := locitypes.CityDetail{ID: uuid.New(), Name: "Lisbon"}
+	repo := &stubRepository{fuzzy: &lisbon}
+	svc := NewCityService(repo, stubEmbeddingClient{err: errors.New("quota exceeded")}, testutil.NewLogger())
+
+	cities, err := svc.SearchCities(context.Background(), "Lisbon", 5)
+	require.NoError(t, err)
+	require.Len(t, cities, 1)
+	require.False(t, repo.similarHit)
+}
+
+func TestSearchCities_WithoutEmbeddingClient(t *testing.T) {
+	repo := &stubRepository{}
+	svc := NewCityService(repo, nil, testutil.NewLogger())
+
+	cities, err := svc.SearchCities(context.Background(), "Atlantis", 5)
+	require.NoError(t, err)
+	require.Empty(t, cities)
+	require.False(t, repo.similarHit)
+}
+
+func TestSearchCities_FailsWhenNoStrategySucceeds(t *testing.T) {
+	repo := &stubRepository{fuzzyErr: errors.New("connection refused")}
+	svc := NewCityService(repo, nil, testutil.NewLogger())
+
+	_, err := svc.SearchCities(context.Background(), "Lisbon", 5)
+	require.Error(t, err)
+}
+
+func TestSearchCities_RejectsEmptyQuery(t *testing.T) {
+	svc := NewCityService(&stubRepository{}, nil, testutil.NewLogger())
+
+	_, err := svc.SearchCities(context.Background(), "   ", 5)
+	require.ErrorIs(t, err, locitypes.ErrBadRequest)
+}
+
+func TestGetCityByName_NotFound(t *testing.T) {
+	svc := NewCityService(&stubRepository{}, nil, testutil.NewLogger())
+
+	_, err := svc.GetCityByName(context.Background(), "Atlantis")
+	require.ErrorIs(t, err, locitypes.ErrNotFound)
+}
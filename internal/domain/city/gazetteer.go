@@ -0,0 +1,644 @@
+package city
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	a "github.com/petar-dambovaliev/aho-corasick"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+const (
+	// mergeRadiusKm is how close a GeoNames place must be to a city of ours with the
+	// same name to be treated as the same city.
+	mergeRadiusKm = 30.0
+	// nearbyRadiusKm is how close to the user a homonym must be to win on location.
+	nearbyRadiusKm = 200.0
+	// prominenceRatio is how much larger the biggest homonym must be to be picked
+	// without a qualifier or location, e.g. Paris, France over Paris, Texas.
+	prominenceRatio = 5
+	// maxQualifierWords bounds the words read after a comma, as in "Sydney, New South Wales".
+	maxQualifierWords = 3
+	minPatternBytes   = 3
+)
+
+// Resolution reasons, reported in GazetteerMatch.Reason.
+const (
+	ReasonUnique     = "unique"
+	ReasonQualifier  = "qualifier"
+	ReasonNearby     = "nearby"
+	ReasonProminent  = "prominent"
+	ReasonAmbiguous  = "ambiguous"
+	ReasonManyCities = "many_cities"
+)
+
+// GazetteerEntry is a city known to the gazetteer.
+type GazetteerEntry struct {
+	// CityID is the row in cities, or uuid.Nil for places only in the GeoNames file.
+	CityID    uuid.UUID
+	GeoNameID int
+	Name      string
+	// Aliases are other names of the city: exonyms, native spellings and names in
+	// other languages.
+	Aliases     []string
+	Country     string
+	CountryCode string
+	// Region is the state or province name; RegionCode is the GeoNames admin1 code,
+	// which is the postal abbreviation for US states.
+	Region     string
+	RegionCode string
+	Latitude   float64
+	Longitude  float64
+	Population int
+}
+
+// GazetteerMatch is the city found in a message.
+type GazetteerMatch struct {
+	Entry GazetteerEntry
+	// Label names the city for prompts. It is the plain name unless a more prominent
+	// city shares it, in which case the region or country is added ("Paris, TX").
+	Label string
+	// Matched is the city as written in the message, with its qualifier.
+	Matched string
+	// Cleaned is the message without the city and a preposition before it.
+	Cleaned string
+	// Confident is false when the match should be confirmed by someone smarter: the
+	// name is shared by cities nothing told apart, the message names several cities,
+	// or the name is a common word written in lower case ("nice views").
+	Confident bool
+	Reason    string
+}
+
+// Gazetteer finds city names in free text without calling a model. Names are folded
+// (lower case, no diacritics) on both sides, so "sao paulo" finds São Paulo, and all
+// names and aliases are matched in one pass with an Aho-Corasick automaton, the same
+// approach DomainDetector takes for intents.
+type Gazetteer struct {
+	entries []GazetteerEntry
+	// candidates[i] are the entries named by pattern i, most prominent first.
+	candidates [][]int
+	matcher    a.AhoCorasick
+	patterns   []string
+	exhaustive bool
+}
+
+// prepositions are dropped with the city when they come right before it.
+var prepositions = map[string]bool{
+	"in": true, "to": true, "at": true, "near": true, "around": true, "visiting": true, "for": true,
+	"em": true, "no": true, "na": true, "en": true, "a": true, "nach": true, "di": true,
+}
+
+// commonWords are city names that are also everyday words; written in lower case they
+// are most likely not the city.
+var commonWords = map[string]bool{
+	"nice": true, "mobile": true, "split": true, "bath": true, "reading": true, "orange": true,
+	"hope": true, "kind": true, "best": true, "most": true, "male": true, "why": true,
+	"chance": true, "victoria": true, "eagle": true, "university": true, "college": true,
+}
+
+// foldReplacements are letters that do not decompose into a base letter and a mark.
+var foldReplacements = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// NewGazetteer indexes entries. exhaustive marks a gazetteer loaded from a world list,
+// where a message without a match names no city at all (see Exhaustive).
+func NewGazetteer(entries []GazetteerEntry, exhaustive bool) *Gazetteer {
+	g := &Gazetteer{entries: entries, exhaustive: exhaustive}
+
+	byPattern := make(map[string]int)
+	for i, entry := range entries {
+		for _, name := range entryNames(entry) {
+			pattern := foldName(name)
+			if len(pattern) < minPatternBytes {
+				continue
+			}
+			idx, ok := byPattern[pattern]
+			if !ok {
+				idx = len(g.patterns)
+				byPattern[pattern] = idx
+				g.patterns = append(g.patterns, pattern)
+				g.candidates = append(g.candidates, nil)
+			}
+			if !containsInt(g.candidates[idx], i) {
+				g.candidates[idx] = append(g.candidates[idx], i)
+			}
+		}
+	}
+	for _, candidates := range g.candidates {
+		sort.SliceStable(candidates, func(x, y int) bool {
+			return moreProminent(entries[candidates[x]], entries[candidates[y]])
+		})
+	}
+
+	builder := a.NewAhoCorasickBuilder(a.Opts{MatchKind: a.LeftMostLongestMatch})
+	g.matcher = builder.Build(g.patterns)
+	return g
+}
+
+// Len returns the number of cities in the gazetteer.
+func (g *Gazetteer) Len() int {
+	return len(g.entries)
+}
+
+// Exhaustive reports whether the gazetteer holds a world list of cities, so a message
+// it finds no city in can be taken to name none.
+func (g *Gazetteer) Exhaustive() bool {
+	return g.exhaustive
+}
+
+// Resolve finds the city named in message. Homonyms are told apart by a qualifier
+// after a comma ("Paris, TX", "Paris, France"), then by being within nearbyRadiusKm of
+// near, then by being much larger than the others. ok is false when no city is named.
+func (g *Gazetteer) Resolve(message string, near *locitypes.UserLocation) (match GazetteerMatch, ok bool) {
+	folded, starts, ends := foldWithOffsets(message)
+	if folded == "" || len(g.patterns) == 0 {
+		return GazetteerMatch{}, false
+	}
+
+	type hit struct{ pattern, start, end int }
+	var hits []hit
+	for _, m := range g.matcher.FindAll(folded) {
+		if wordBoundary(folded, m.Start(), m.End()) {
+			hits = append(hits, hit{pattern: m.Pattern(), start: m.Start(), end: m.End()})
+		}
+	}
+	if len(hits) == 0 {
+		return GazetteerMatch{}, false
+	}
+
+	first := hits[0]
+	all := g.candidates[first.pattern]
+
+	candidates := all
+	startByte, endByte := starts[first.start], ends[first.end-1]
+	reason := ""
+	if len(candidates) > 1 {
+		if filtered, qualifierEnd := g.qualify(message, folded, ends, first.end, candidates); len(filtered) > 0 {
+			candidates, reason = filtered, ReasonQualifier
+			endByte = ends[qualifierEnd-1]
+			// A qualifier such as "Texas" may itself be a city name.
+			for len(hits) > 1 && hits[1].start < qualifierEnd {
+				hits = append(hits[:1], hits[2:]...)
+			}
+		}
+	}
+
+	manyCities := false
+	for _, other := range hits[1:] {
+		if !intersects(all, g.candidates[other.pattern]) {
+			manyCities = true
+			break
+		}
+	}
+
+	chosen, confident := candidates[0], true
+	switch {
+	case len(candidates) == 1:
+		if reason == "" {
+			reason = ReasonUnique
+		}
+	default:
+		if nearest, ok := g.nearest(candidates, near); ok {
+			chosen, reason = nearest, ReasonNearby
+		} else if top, second := g.entries[candidates[0]], g.entries[candidates[1]]; clearlyMoreProminent(top, second) {
+			reason = ReasonProminent
+		} else {
+			confident, reason = false, ReasonAmbiguous
+		}
+	}
+
+	matched := message[starts[first.start]:ends[first.end-1]]
+	if commonWords[g.patterns[first.pattern]] && strings.ToLower(matched) == matched {
+		confident = false
+	}
+	if manyCities {
+		confident, reason = false, ReasonManyCities
+	}
+
+	entry := g.entries[chosen]
+	label := entry.Name
+	if chosen != all[0] {
+		label = entry.Name + ", " + regionLabel(entry)
+	}
+
+	if word := previousWord(folded, first.start); word >= 0 && prepositions[folded[word:first.start-1]] {
+		startByte = starts[word]
+	}
+
+	return GazetteerMatch{
+		Entry:     entry,
+		Label:     label,
+		Matched:   message[starts[first.start]:endByte],
+		Cleaned:   cleanMessage(message, startByte, endByte),
+		Confident: confident,
+		Reason:    reason,
+	}, true
+}
+
+// qualify narrows candidates with the words after a comma that follows the city, e.g.
+// "TX" or "New South Wales". It returns the matching candidates and where the qualifier
+// ends in the folded text, preferring the longest qualifier that matches.
+func (g *Gazetteer) qualify(message, folded string, ends []int, cityEnd int, candidates []int) (qualified []int, qualifierEnd int) {
+	if !strings.HasPrefix(strings.TrimLeft(message[ends[cityEnd-1]:], " "), ",") || cityEnd >= len(folded) {
+		return nil, 0
+	}
+
+	var wordEnds []int
+	for i := cityEnd + 1; i <= len(folded) && len(wordEnds) < maxQualifierWords; i++ {
+		if i == len(folded) || folded[i] == ' ' {
+			wordEnds = append(wordEnds, i)
+		}
+	}
+	for n := len(wordEnds) - 1; n >= 0; n-- {
+		qualifier := folded[cityEnd+1 : wordEnds[n]]
+		var filtered []int
+		for _, idx := range candidates {
+			if qualifies(g.entries[idx], qualifier) {
+				filtered = append(filtered, idx)
+			}
+		}
+		if len(filtered) > 0 {
+			return filtered, wordEnds[n]
+		}
+	}
+	return nil, 0
+}
+
+func qualifies(entry GazetteerEntry, qualifier string) bool {
+	for _, field := range []string{entry.Region, entry.RegionCode, entry.Country, entry.CountryCode} {
+		if field != "" && foldName(field) == qualifier {
+			return true
+		}
+	}
+	return false
+}
+
+// nearest returns the candidate closest to near when it is within nearbyRadiusKm.
+func (g *Gazetteer) nearest(candidates []int, near *locitypes.UserLocation) (int, bool) {
+	if near == nil || (near.UserLat == 0 && near.UserLon == 0) {
+		return 0, false
+	}
+	best, bestKm := -1, math.MaxFloat64
+	for _, idx := range candidates {
+		entry := g.entries[idx]
+		if entry.Latitude == 0 && entry.Longitude == 0 {
+			continue
+		}
+		if km := haversineKm(near.UserLat, near.UserLon, entry.Latitude, entry.Longitude); km < bestKm {
+			best, bestKm = idx, km
+		}
+	}
+	if best < 0 || bestKm > nearbyRadiusKm {
+		return 0, false
+	}
+	return best, true
+}
+
+// moreProminent orders homonyms: our own cities first, then by population.
+func moreProminent(x, y GazetteerEntry) bool {
+	if (x.CityID != uuid.Nil) != (y.CityID != uuid.Nil) {
+		return x.CityID != uuid.Nil
+	}
+	return x.Population > y.Population
+}
+
+func clearlyMoreProminent(top, second GazetteerEntry) bool {
+	if top.CityID != uuid.Nil && second.CityID == uuid.Nil {
+		return true
+	}
+	return top.Population > 0 && top.Population >= prominenceRatio*second.Population
+}
+
+func regionLabel(entry GazetteerEntry) string {
+	switch {
+	case entry.Region != "":
+		return entry.Region
+	case entry.RegionCode != "" && !isDigits(entry.RegionCode):
+		return entry.RegionCode
+	case entry.Country != "":
+		return entry.Country
+	default:
+		return entry.CountryCode
+	}
+}
+
+// BuildGazetteerEntries merges our cities with places from a GeoNames file. A place
+// with the same name as one of our cities within mergeRadiusKm adds its aliases and
+// population to it; the other places are kept as they are.
+func BuildGazetteerEntries(cities []locitypes.CityDetail, places []GazetteerEntry) []GazetteerEntry {
+	byName := make(map[string][]int)
+	for i, place := range places {
+		for _, name := range entryNames(place) {
+			key := foldName(name)
+			if !containsInt(byName[key], i) {
+				byName[key] = append(byName[key], i)
+			}
+		}
+	}
+
+	merged := make([]bool, len(places))
+	entries := make([]GazetteerEntry, 0, len(cities)+len(places))
+	for _, c := range cities {
+		entry := GazetteerEntry{
+			CityID:    c.ID,
+			Name:      c.Name,
+			Country:   c.Country,
+			Region:    c.StateProvince,
+			Latitude:  c.CenterLatitude,
+			Longitude: c.CenterLongitude,
+		}
+		if entry.Region == "Unknown" {
+			entry.Region = ""
+		}
+		if match := nearestPlace(places, byName[foldName(c.Name)], merged, entry); match >= 0 {
+			place := places[match]
+			merged[match] = true
+			entry.GeoNameID = place.GeoNameID
+			entry.CountryCode = place.CountryCode
+			entry.RegionCode = place.RegionCode
+			entry.Population = place.Population
+			entry.Aliases = append([]string{place.Name}, place.Aliases...)
+		}
+		entries = append(entries, entry)
+	}
+	for i, place := range places {
+		if !merged[i] {
+			entries = append(entries, place)
+		}
+	}
+	return entries
+}
+
+func nearestPlace(places []GazetteerEntry, candidates []int, merged []bool, city GazetteerEntry) int {
+	if city.Latitude == 0 && city.Longitude == 0 {
+		return -1
+	}
+	best, bestKm := -1, mergeRadiusKm
+	for _, i := range candidates {
+		if merged[i] {
+			continue
+		}
+		if km := haversineKm(city.Latitude, city.Longitude, places[i].Latitude, places[i].Longitude); km <= bestKm {
+			best, bestKm = i, km
+		}
+	}
+	return best
+}
+
+// ParseGeoNames reads places in the GeoNames dump format (cities15000.txt and
+// friends): tab separated, with the name, ASCII name and comma separated alternate
+// names in columns 2-4, coordinates in 5-6, country code in 9, admin1 code in 11 and
+// population in 15. Places below minPopulation are skipped.
+func ParseGeoNames(r io.Reader, minPopulation int) ([]GazetteerEntry, error) {
+	scanner := bufio.NewScanner(r)
+	// Alternate names of large cities run to tens of kilobytes.
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	var places []GazetteerEntry
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 15 {
+			return nil, fmt.Errorf("geonames line %d: expected at least 15 columns, got %d", line, len(fields))
+		}
+		if fields[6] != "" && fields[6] != "P" {
+			continue // not a populated place
+		}
+		population, _ := strconv.Atoi(fields[14])
+		if population < minPopulation {
+			continue
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("geonames line %d: invalid id %q", line, fields[0])
+		}
+		lat, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geonames line %d: invalid latitude %q", line, fields[4])
+		}
+		lon, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geonames line %d: invalid longitude %q", line, fields[5])
+		}
+
+		place := GazetteerEntry{
+			GeoNameID:   id,
+			Name:        fields[1],
+			CountryCode: fields[8],
+			RegionCode:  fields[10],
+			Latitude:    lat,
+			Longitude:   lon,
+			Population:  population,
+		}
+		seen := map[string]bool{foldName(place.Name): true}
+		for _, alias := range append([]string{fields[2]}, strings.Split(fields[3], ",")...) {
+			key := foldName(alias)
+			if seen[key] || !usableAlias(alias) {
+				continue
+			}
+			seen[key] = true
+			place.Aliases = append(place.Aliases, alias)
+		}
+		places = append(places, place)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read geonames: %w", err)
+	}
+	return places, nil
+}
+
+// usableAlias drops the codes and links GeoNames keeps among alternate names, e.g.
+// the IATA code "PAR" or a wikipedia URL.
+func usableAlias(alias string) bool {
+	alias = strings.TrimSpace(alias)
+	if len(alias) < minPatternBytes || strings.Contains(alias, "://") {
+		return false
+	}
+	if strings.IndexFunc(alias, unicode.IsDigit) >= 0 {
+		return false
+	}
+	return !(len(alias) <= 4 && strings.ToUpper(alias) == alias)
+}
+
+// LoadGazetteer builds the gazetteer from the cities table and, when geonamesPath is
+// set, a GeoNames dump.
+func LoadGazetteer(ctx context.Context, repo Repository, geonamesPath string, minPopulation int) (*Gazetteer, error) {
+	cities, err := repo.GetAllCities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cities: %w", err)
+	}
+
+	var places []GazetteerEntry
+	if geonamesPath != "" {
+		file, err := os.Open(geonamesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open geonames file: %w", err)
+		}
+		defer file.Close()
+		if places, err = ParseGeoNames(file, minPopulation); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewGazetteer(BuildGazetteerEntries(cities, places), len(places) > 0), nil
+}
+
+// entryNames returns the names an entry is found by. "Saint" and "St" are
+// interchangeable in city names, so each form adds the other.
+func entryNames(entry GazetteerEntry) []string {
+	names := append([]string{entry.Name}, entry.Aliases...)
+	for _, name := range names {
+		folded := foldName(name)
+		switch {
+		case strings.HasPrefix(folded, "saint "):
+			names = append(names, "st "+strings.TrimPrefix(folded, "saint "))
+		case strings.HasPrefix(folded, "st "):
+			names = append(names, "saint "+strings.TrimPrefix(folded, "st "))
+		}
+	}
+	return names
+}
+
+// foldName folds a name the way messages are folded before matching.
+func foldName(s string) string {
+	folded, _, _ := foldWithOffsets(s)
+	return folded
+}
+
+// foldWithOffsets lower-cases s, strips diacritics and turns punctuation into single
+// spaces. starts[i] and ends[i] are the bytes of s that folded byte i came from, so a
+// match in the folded text can be cut out of the original.
+func foldWithOffsets(s string) (folded string, starts, ends []int) {
+	var b strings.Builder
+	b.Grow(len(s))
+	write := func(out string, start, end int) {
+		for i := 0; i < len(out); i++ {
+			b.WriteByte(out[i])
+			starts = append(starts, start)
+			ends = append(ends, end)
+		}
+	}
+
+	for i, r := range s {
+		end := i + utf8.RuneLen(r)
+		if r == utf8.RuneError {
+			end = i + 1
+		}
+		r = unicode.ToLower(r)
+		switch {
+		case foldReplacements[r] != "":
+			write(foldReplacements[r], i, end)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			var base strings.Builder
+			for _, d := range norm.NFD.String(string(r)) {
+				if !unicode.Is(unicode.Mn, d) {
+					base.WriteRune(d)
+				}
+			}
+			write(base.String(), i, end)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), " "):
+			write(" ", i, end)
+		}
+	}
+
+	folded = b.String()
+	if strings.HasSuffix(folded, " ") {
+		folded = folded[:len(folded)-1]
+		starts, ends = starts[:len(folded)], ends[:len(folded)]
+	}
+	return folded, starts, ends
+}
+
+// wordBoundary reports whether folded[start:end] is a whole word. The matcher's own
+// whole-word option looks at single bytes, which misreads multi-byte letters, and
+// scripts written without spaces (Chinese, Japanese, Thai) have no boundaries to check.
+func wordBoundary(folded string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(folded[:start]); r != ' ' && !unspaced(r) {
+			return false
+		}
+	}
+	if end < len(folded) {
+		if r, _ := utf8.DecodeRuneInString(folded[end:]); r != ' ' && !unspaced(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func unspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
+
+// previousWord returns where the word before folded[at:] starts, or -1.
+func previousWord(folded string, at int) int {
+	if at < 2 || folded[at-1] != ' ' {
+		return -1
+	}
+	return strings.LastIndexByte(folded[:at-1], ' ') + 1
+}
+
+// cleanMessage removes message[start:end] and tidies the spaces and punctuation left
+// behind. A message that was only the city is returned as it was.
+func cleanMessage(message string, start, end int) string {
+	cleaned := strings.Join(strings.Fields(message[:start]+" "+message[end:]), " ")
+	for _, punct := range []string{"?", "!", ".", ",", ";", ":"} {
+		cleaned = strings.ReplaceAll(cleaned, " "+punct, punct)
+	}
+	cleaned = strings.TrimSpace(strings.Trim(cleaned, ",;:- "))
+	if cleaned == "" || strings.Trim(cleaned, "?!.") == "" {
+		return message
+	}
+	return cleaned
+}
+
+// haversineKm returns the great-circle distance between two coordinates in kilometres.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(x, y []int) bool {
+	for _, v := range x {
+		if containsInt(y, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
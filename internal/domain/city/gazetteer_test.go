@@ -0,0 +1,125 @@
+package city
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// geonamesSample is a few rows of cities15000.txt, trimmed to the columns we read.
+const geonamesSample = "2988507\tParis\tParis\tPAR,Paname,Parigi,Parijs,Paryz,Paryż,París,https://en.wikipedia.org/wiki/Paris\t48.85341\t2.3488\tP\tPPLC\tFR\t\t11\t75\t751\t75056\t2138551\t\t42\tEurope/Paris\t2024-01-01\n" +
+	"4717560\tParis\tParis\t\t33.66094\t-95.55551\tP\tPPLA2\tUS\t\tTX\t277\t\t\t24782\t\t183\tAmerica/Chicago\t2017-03-09\n" +
+	"2267057\tLisbon\tLisbon\tLisboa,Lissabon,Lisbonne,LIS\t38.71667\t-9.13333\tP\tPPLC\tPT\t\t14\t1106\t\t\t517802\t\t45\tEurope/Lisbon\t2022-03-01\n" +
+	"3448439\tSão Paulo\tSao Paulo\tSampa\t-23.5475\t-46.63611\tP\tPPLA\tBR\t\t27\t3550308\t\t\t10021295\t\t760\tAmerica/Sao_Paulo\t2023-01-03\n" +
+	"2990440\tNice\tNice\tNizza\t43.70313\t7.26608\tP\tPPLA2\tFR\t\t93\t06\t062\t06088\t338620\t\t25\tEurope/Paris\t2019-05-22\n" +
+	"3000000\tTiny\tTiny\t\t0\t0\tP\tPPL\tFR\t\t11\t\t\t\t800\t\t0\tEurope/Paris\t2019-05-22\n"
+
+func newTestGazetteer(t *testing.T) *Gazetteer {
+	t.Helper()
+	places, err := ParseGeoNames(strings.NewReader(geonamesSample), 15000)
+	require.NoError(t, err)
+	ours := []locitypes.CityDetail{
+		{ID: uuid.New(), Name: "Paris", Country: "France", StateProvince: "Île-de-France", CenterLatitude: 48.8566, CenterLongitude: 2.3522},
+		{ID: uuid.New(), Name: "Porto", Country: "Portugal", CenterLatitude: 41.1579, CenterLongitude: -8.6291},
+	}
+	return NewGazetteer(BuildGazetteerEntries(ours, places), true)
+}
+
+func TestParseGeoNames(t *testing.T) {
+	places, err := ParseGeoNames(strings.NewReader(geonamesSample), 15000)
+	require.NoError(t, err)
+	require.Len(t, places, 5, "places below the minimum population are skipped")
+
+	paris := places[0]
+	assert.Equal(t, 2988507, paris.GeoNameID)
+	assert.Equal(t, "FR", paris.CountryCode)
+	assert.Equal(t, 2138551, paris.Population)
+	// Codes, links and spellings that fold to the name are dropped.
+	assert.Equal(t, []string{"Paname", "Parigi", "Parijs", "Paryz"}, paris.Aliases)
+
+	_, err = ParseGeoNames(strings.NewReader("1\tBroken\n"), 0)
+	assert.Error(t, err)
+}
+
+func TestBuildGazetteerEntries_MergesOurCities(t *testing.T) {
+	g := newTestGazetteer(t)
+	require.Equal(t, 6, g.Len(), "our Paris absorbs the GeoNames one")
+
+	match, ok := g.Resolve("Best croissants in Parigi", nil)
+	require.True(t, ok)
+	assert.NotEqual(t, uuid.Nil, match.Entry.CityID)
+	assert.Equal(t, "France", match.Entry.Country)
+	assert.Equal(t, 2138551, match.Entry.Population)
+}
+
+func TestGazetteer_Resolve(t *testing.T) {
+	g := newTestGazetteer(t)
+	dallas := &locitypes.UserLocation{UserLat: 32.7767, UserLon: -96.797}
+
+	tests := []struct {
+		name      string
+		message   string
+		near      *locitypes.UserLocation
+		label     string
+		cleaned   string
+		confident bool
+		reason    string
+	}{
+		{"diacritics", "restaurants in sao paulo", nil, "São Paulo", "restaurants", true, ReasonUnique},
+		{"alias in another language", "Was kann man in Lissabon machen?", nil, "Lisbon", "Was kann man machen?", true, ReasonUnique},
+		{"city first", "Porto wine cellars", nil, "Porto", "wine cellars", true, ReasonUnique},
+		{"qualifier picks the smaller homonym", "Things to do in Paris, TX", nil, "Paris, TX", "Things to do", true, ReasonQualifier},
+		{"qualifier by country", "Museums in Paris, France please", nil, "Paris", "Museums please", true, ReasonQualifier},
+		{"location picks the nearby homonym", "What to do in Paris?", dallas, "Paris, TX", "What to do?", true, ReasonNearby},
+		{"prominence without location", "What to do in Paris?", nil, "Paris", "What to do?", true, ReasonProminent},
+		{"several cities", "Trains from Lisbon to Porto", nil, "Lisbon", "Trains from to Porto", false, ReasonManyCities},
+		{"common word in lower case", "hotels with nice views", nil, "Nice", "hotels with views", false, ReasonUnique},
+		{"common word capitalised", "Beaches near Nice", nil, "Nice", "Beaches", true, ReasonUnique},
+		{"only the city", "Lisbon", nil, "Lisbon", "Lisbon", true, ReasonUnique},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := g.Resolve(tt.message, tt.near)
+			require.True(t, ok)
+			assert.Equal(t, tt.label, match.Label)
+			assert.Equal(t, tt.cleaned, match.Cleaned)
+			assert.Equal(t, tt.confident, match.Confident)
+			assert.Equal(t, tt.reason, match.Reason)
+		})
+	}
+
+	_, ok := g.Resolve("Parisian cafés and lisbonese food", nil)
+	assert.False(t, ok, "names inside longer words are not matches")
+}
+
+func TestGazetteer_AmbiguousHomonyms(t *testing.T) {
+	g := NewGazetteer([]GazetteerEntry{
+		{Name: "Springfield", Region: "Illinois", RegionCode: "IL", CountryCode: "US", Population: 114000},
+		{Name: "Springfield", Region: "Missouri", RegionCode: "MO", CountryCode: "US", Population: 169000},
+	}, false)
+
+	match, ok := g.Resolve("Springfield diners", nil)
+	require.True(t, ok)
+	assert.False(t, match.Confident)
+	assert.Equal(t, ReasonAmbiguous, match.Reason)
+
+	match, ok = g.Resolve("Springfield, Illinois diners", nil)
+	require.True(t, ok)
+	assert.True(t, match.Confident)
+	assert.Equal(t, "Springfield, Illinois", match.Label)
+	assert.Equal(t, "Springfield, Illinois", match.Matched)
+	assert.Equal(t, "diners", match.Cleaned)
+}
+
+func TestFoldWithOffsets(t *testing.T) {
+	folded, starts, ends := foldWithOffsets("Straße — Łódź, Saint-Étienne!")
+	assert.Equal(t, "strasse lodz saint etienne", folded)
+	require.Len(t, starts, len(folded))
+	i := strings.Index(folded, "lodz")
+	assert.Equal(t, "Łódź", "Straße — Łódź, Saint-Étienne!"[starts[i]:ends[i+3]])
+}
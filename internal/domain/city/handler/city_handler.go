@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	cityv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city/cityconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// CityHandler implements the CityServiceHandler interface.
+type CityHandler struct {
+	cityconnect.UnimplementedCityServiceHandler
+	service city.Service
+	logger  *slog.Logger
+}
+
+// NewCityHandler creates a new CityHandler.
+func NewCityHandler(svc city.Service, logger *slog.Logger) *CityHandler {
+	return &CityHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// GetCity looks a city up by ID or, failing that, by its closest matching name.
+func (h *CityHandler) GetCity(
+	ctx context.Context,
+	req *connect.Request[cityv1.GetCityRequest],
+) (*connect.Response[cityv1.GetCityResponse], error) {
+	var (
+		found *locitypes.CityDetail
+		err   error
+	)
+
+	switch id := req.Msg.GetIdentifier().(type) {
+	case *cityv1.GetCityRequest_CityId:
+		cityID, parseErr := uuid.Parse(id.CityId)
+		if parseErr != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid city_id"))
+		}
+		found, err = h.service.GetCityByID(ctx, cityID)
+	case *cityv1.GetCityRequest_CityName:
+		found, err = h.service.GetCityByName(ctx, id.CityName)
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("city_id or city_name is required"))
+	}
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&cityv1.GetCityResponse{
+		City: presenter.ToProtoCity(found),
+	}), nil
+}
+
+// SearchCities returns cities matching the query by name similarity and semantic closeness.
+func (h *CityHandler) SearchCities(
+	ctx context.Context,
+	req *connect.Request[cityv1.SearchCitiesRequest],
+) (*connect.Response[cityv1.SearchCitiesResponse], error) {
+	cities, err := h.service.SearchCities(ctx, req.Msg.GetQuery(), int(req.Msg.GetLimit()))
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&cityv1.SearchCitiesResponse{
+		Cities: presenter.ToProtoCities(cities),
+	}), nil
+}
+
+func (h *CityHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("city request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	cityv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city/cityconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/city"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	city.Service
+	city      *locitypes.CityDetail
+	cities    []locitypes.CityDetail
+	err       error
+	lastID    uuid.UUID
+	lastName  string
+	lastQuery string
+	lastLimit int
+}
+
+func (s *stubService) GetCityByID(_ context.Context, cityID uuid.UUID) (*locitypes.CityDetail, error) {
+	s.lastID = cityID
+	return s.city, s.err
+}
+
+func (s *stubService) GetCityByName(_ context.Context, name string) (*locitypes.CityDetail, error) {
+	s.lastName = name
+	return s.city, s.err
+}
+
+func (s *stubService) SearchCities(_ context.Context, query string, limit int) ([]locitypes.CityDetail, error) {
+	s.lastQuery = query
+	s.lastLimit = limit
+	return s.cities, s.err
+}
+
+func TestGetCity_ByIDAndName(t *testing.T) {
+	lisbon := &locitypes.CityDetail{ID: uuid.New(), Name: "Lisbon", Country: "Portugal", CenterLatitude: 38.72, CenterLongitude: -9.14}
+	svc := &stubService{city: lisbon}
+	h := NewCityHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetCity(context.Background(), connect.NewRequest(&cityv1.GetCityRequest{
+		Identifier: &cityv1.GetCityRequest_CityId{CityId: lisbon.ID.String()},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, lisbon.ID, svc.lastID)
+	require.Equal(t, "Lisbon", resp.Msg.GetCity().GetName())
+	require.InDelta(t, 38.72, resp.Msg.GetCity().GetCenterLatitude(), 0.001)
+	require.Nil(t, resp.Msg.GetCity().StateProvince)
+
+	_, err = h.GetCity(context.Background(), connect.NewRequest(&cityv1.GetCityRequest{
+		Identifier: &cityv1.GetCityRequest_CityName{CityName: "Lisboa"},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "Lisboa", svc.lastName)
+}
+
+func TestGetCity_ValidatesIdentifier(t *testing.T) {
+	h := NewCityHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.GetCity(context.Background(), connect.NewRequest(&cityv1.GetCityRequest{}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	_, err = h.GetCity(context.Background(), connect.NewRequest(&cityv1.GetCityRequest{
+		Identifier: &cityv1.GetCityRequest_CityId{CityId: "lisbon"},
+	}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestGetCity_NotFound(t *testing.T) {
+	h := NewCityHandler(&stubService{err: fmt.Errorf("city: %w", locitypes.ErrNotFound)}, testutil.NewLogger())
+
+	_, err := h.GetCity(context.Background(), connect.NewRequest(&cityv1.GetCityRequest{
+		Identifier: &cityv1.GetCityRequest_CityName{CityName: "Atlantis"},
+	}))
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func TestCityE2E_SearchCities(t *testing.T) {
+	svc := &stubService{cities: []locitypes.CityDetail{
+		{ID: uuid.New(), Name: "Porto", Country: "Portugal", StateProvince: "Norte"},
+	}}
+
+	mux := http.NewServeMux()
+	path, h := cityconnect.NewCityServiceHandler(NewCityHandler(svc, testutil.NewLogger()))
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := cityconnect.NewCityServiceClient(server.Client(), server.URL)
+	limit := int32(3)
+	resp, err := client.SearchCities(context.Background(), connect.NewRequest(&cityv1.SearchCitiesRequest{
+		Query: "port wine",
+		Limit: &limit,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "port wine", svc.lastQuery)
+	require.Equal(t, 3, svc.lastLimit)
+	require.Len(t, resp.Msg.GetCities(), 1)
+	require.Equal(t, "Norte", resp.Msg.GetCities()[0].GetStateProvince())
+}
@@ -0,0 +1,39 @@
+package presenter
+
+import (
+	cityv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/city"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// ToProtoCity maps a stored city onto the proto message. Zero coordinates are
+// left unset because they mean the city has no centre location.
+func ToProtoCity(c *locitypes.CityDetail) *cityv1.CityDetail {
+	if c == nil {
+		return nil
+	}
+	out := &cityv1.CityDetail{
+		Id:        c.ID.String(),
+		Name:      c.Name,
+		Country:   c.Country,
+		AiSummary: c.AiSummary,
+	}
+	if c.StateProvince != "" {
+		state := c.StateProvince
+		out.StateProvince = &state
+	}
+	if c.CenterLatitude != 0 || c.CenterLongitude != 0 {
+		lat, lon := c.CenterLatitude, c.CenterLongitude
+		out.CenterLatitude = &lat
+		out.CenterLongitude = &lon
+	}
+	return out
+}
+
+func ToProtoCities(cities []locitypes.CityDetail) []*cityv1.CityDetail {
+	out := make([]*cityv1.CityDetail, 0, len(cities))
+	for i := range cities {
+		out = append(out, ToProtoCity(&cities[i]))
+	}
+	return out
+}
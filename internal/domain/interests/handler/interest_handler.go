@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+	interestv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest/interestconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/interests"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/interests/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// InterestHandler implements the InterestServiceHandler interface.
+type InterestHandler struct {
+	interestconnect.UnimplementedInterestServiceHandler
+	service interests.Service
+	logger  *slog.Logger
+}
+
+// NewInterestHandler creates a new InterestHandler.
+func NewInterestHandler(svc interests.Service, logger *slog.Logger) *InterestHandler {
+	return &InterestHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// GetInterests lists the interest catalogue. It is public so onboarding can show it
+// before sign-up; signed-in callers also see their custom interests.
+func (h *InterestHandler) GetInterests(
+	ctx context.Context,
+	req *connect.Request[interestv1.GetInterestsRequest],
+) (*connect.Response[interestv1.GetInterestsResponse], error) {
+	userID, _ := interceptors.OptionalUserID(ctx)
+	list, err := h.service.GetInterests(ctx, userID, req.Msg.GetActiveOnly())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&interestv1.GetInterestsResponse{
+		Interests: presenter.ToProtoInterests(list),
+	}), nil
+}
+
+func (h *InterestHandler) GetUserInterests(
+	ctx context.Context,
+	req *connect.Request[interestv1.GetUserInterestsRequest],
+) (*connect.Response[interestv1.GetUserInterestsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := h.service.GetUserInterests(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&interestv1.GetUserInterestsResponse{
+		Interests: presenter.ToProtoInterests(list),
+	}), nil
+}
+
+// CreateInterest creates a custom interest owned by the caller.
+func (h *InterestHandler) CreateInterest(
+	ctx context.Context,
+	req *connect.Request[interestv1.CreateInterestRequest],
+) (*connect.Response[commonpb.Response], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := h.service.CreateInterest(ctx, req.Msg.GetName(), req.Msg.Description, req.Msg.GetActive(), userID.String())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(successResponse(fmt.Sprintf("interest %s created", created.ID))), nil
+}
+
+// UpdateInterest updates one of the caller's custom interests.
+func (h *InterestHandler) UpdateInterest(
+	ctx context.Context,
+	req *connect.Request[interestv1.UpdateInterestRequest],
+) (*connect.Response[commonpb.Response], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	interestID, err := uuid.Parse(req.Msg.GetInterestId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid interest_id"))
+	}
+
+	if err := h.service.Updateinterests(ctx, userID, interestID, presenter.FromUpdateRequest(req.Msg)); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(successResponse("interest updated")), nil
+}
+
+// AddInterestToUser adds a catalogue interest to the caller's default profile.
+func (h *InterestHandler) AddInterestToUser(
+	ctx context.Context,
+	req *connect.Request[interestv1.AddInterestRequest],
+) (*connect.Response[commonpb.Response], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	interestID, err := uuid.Parse(req.Msg.GetInterestId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid interest_id"))
+	}
+
+	if err := h.service.AddInterestToUser(ctx, userID, interestID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(successResponse("interest added")), nil
+}
+
+// UpdatePreferenceLevel sets the preference level for the caller's default profile.
+// The request carries no interest ID, so the level applies to every interest on the profile.
+func (h *InterestHandler) UpdatePreferenceLevel(
+	ctx context.Context,
+	req *connect.Request[interestv1.UpdatePreferenceLevelRequest],
+) (*connect.Response[commonpb.Response], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.UpdatePreferenceLevel(ctx, userID, nil, int(req.Msg.GetPreferenceLevel())); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(successResponse("preference level updated")), nil
+}
+
+func (h *InterestHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrConflict):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("interest request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+func successResponse(message string) *commonpb.Response {
+	return &commonpb.Response{Success: true, Message: &message}
+}
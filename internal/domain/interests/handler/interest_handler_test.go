@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	interestv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest/interestconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/interests"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	interests.Service
+	interests      []*locitypes.Interest
+	err            error
+	lastUserID     uuid.UUID
+	lastInterestID uuid.UUID
+	lastActiveOnly bool
+	lastLevel      int
+	lastParams     locitypes.UpdateinterestsParams
+}
+
+func (s *stubService) GetInterests(_ context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	s.lastUserID = userID
+	s.lastActiveOnly = activeOnly
+	return s.interests, s.err
+}
+
+func (s *stubService) GetUserInterests(_ context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	s.lastUserID = userID
+	return s.interests, s.err
+}
+
+func (s *stubService) CreateInterest(_ context.Context, name string, description *string, isActive bool, userID string) (*locitypes.Interest, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.lastUserID = uuid.MustParse(userID)
+	return &locitypes.Interest{ID: uuid.New(), Name: name, Description: description, Active: &isActive}, nil
+}
+
+func (s *stubService) Updateinterests(_ context.Context, userID, interestID uuid.UUID, params locitypes.UpdateinterestsParams) error {
+	s.lastUserID = userID
+	s.lastInterestID = interestID
+	s.lastParams = params
+	return s.err
+}
+
+func (s *stubService) AddInterestToUser(_ context.Context, userID, interestID uuid.UUID) error {
+	s.lastUserID = userID
+	s.lastInterestID = interestID
+	return s.err
+}
+
+func (s *stubService) UpdatePreferenceLevel(_ context.Context, userID uuid.UUID, _ []uuid.UUID, level int) error {
+	s.lastUserID = userID
+	s.lastLevel = level
+	return s.err
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func TestGetInterests_AllowsAnonymousCallers(t *testing.T) {
+	active := true
+	svc := &stubService{interests: []*locitypes.Interest{
+		{ID: uuid.New(), Name: "Museums", Active: &active, CreatedAt: time.Now(), Source: "global"},
+	}}
+	h := NewInterestHandler(svc, testutil.NewLogger())
+
+	activeOnly := true
+	resp, err := h.GetInterests(context.Background(), connect.NewRequest(&interestv1.GetInterestsRequest{ActiveOnly: &activeOnly}))
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, svc.lastUserID)
+	require.True(t, svc.lastActiveOnly)
+	require.Len(t, resp.Msg.GetInterests(), 1)
+	require.Equal(t, "global", resp.Msg.GetInterests()[0].GetSource())
+	require.Nil(t, resp.Msg.GetInterests()[0].UpdatedAt)
+}
+
+func TestGetUserInterests_RejectsOtherUsers(t *testing.T) {
+	h := NewInterestHandler(&stubService{}, testutil.NewLogger())
+	other := uuid.NewString()
+
+	_, err := h.GetUserInterests(authedContext(uuid.New()), connect.NewRequest(&interestv1.GetUserInterestsRequest{UserId: &other}))
+	require.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+	_, err = h.GetUserInterests(context.Background(), connect.NewRequest(&interestv1.GetUserInterestsRequest{}))
+	require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func TestUpdateInterest_MapsPartialUpdate(t *testing.T) {
+	svc := &stubService{}
+	h := NewInterestHandler(svc, testutil.NewLogger())
+	interestID := uuid.New()
+
+	_, err := h.UpdateInterest(authedContext(uuid.New()), connect.NewRequest(&interestv1.UpdateInterestRequest{
+		InterestId: interestID.String(),
+		Active:     false,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, interestID, svc.lastInterestID)
+	require.Nil(t, svc.lastParams.Name)
+	require.NotNil(t, svc.lastParams.Active)
+	require.False(t, *svc.lastParams.Active)
+}
+
+func TestAddInterestToUser_MapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected connect.Code
+	}{
+		{name: "unknown interest", err: fmt.Errorf("interest: %w", locitypes.ErrNotFound), expected: connect.CodeNotFound},
+		{name: "unexpected", err: fmt.Errorf("connection reset"), expected: connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewInterestHandler(&stubService{err: tt.err}, testutil.NewLogger())
+
+			_, err := h.AddInterestToUser(authedContext(uuid.New()), connect.NewRequest(&interestv1.AddInterestRequest{InterestId: uuid.NewString()}))
+			require.Equal(t, tt.expected, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestInterestE2E_CreateThenSetPreference(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubService{}
+
+	// Stand-in for the auth interceptor: trust the test token as the user ID.
+	auth := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, req.Header().Get("X-Test-User")), req)
+		}
+	})
+
+	mux := http.NewServeMux()
+	path, h := interestconnect.NewInterestServiceHandler(NewInterestHandler(svc, testutil.NewLogger()), connect.WithInterceptors(auth))
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := interestconnect.NewInterestServiceClient(server.Client(), server.URL)
+
+	createReq := connect.NewRequest(&interestv1.CreateInterestRequest{Name: "Azulejos", Active: true})
+	createReq.Header().Set("X-Test-User", userID.String())
+	resp, err := client.CreateInterest(context.Background(), createReq)
+	require.NoError(t, err)
+	require.True(t, resp.Msg.GetSuccess())
+	require.Equal(t, userID, svc.lastUserID)
+
+	levelReq := connect.NewRequest(&interestv1.UpdatePreferenceLevelRequest{PreferenceLevel: 2})
+	levelReq.Header().Set("X-Test-User", userID.String())
+	_, err = client.UpdatePreferenceLevel(context.Background(), levelReq)
+	require.NoError(t, err)
+	require.Equal(t, 2, svc.lastLevel)
+}
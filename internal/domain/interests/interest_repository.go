@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel"
@@ -33,6 +34,14 @@ type Repository interface {
 	AddInterestToProfile(ctx context.Context, profileID, interestID uuid.UUID) error
 	// GetInterestsForProfile retrieves all interests associated with a profile
 	GetInterestsForProfile(ctx context.Context, profileID uuid.UUID) ([]*locitypes.Interest, error)
+	// GetAvailableInterests returns the global catalogue plus the user's own custom interests.
+	GetAvailableInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error)
+	// GetUserInterests returns the interests on the user's default profile plus their custom interests.
+	GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error)
+	// GetDefaultProfileID returns locitypes.ErrNotFound if the user has no default profile.
+	GetDefaultProfileID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	// UpdatePreferenceLevel sets the level on the given profile interests, or on all of them when interestIDs is nil.
+	UpdatePreferenceLevel(ctx context.Context, profileID uuid.UUID, interestIDs []uuid.UUID, level int) (int64, error)
 	// GetUserEnhancedInterests retrieves all interests for a user with their preference levels
 	// GetUserEnhancedInterests(ctx context.Context, userID uuid.UUID) ([]locitypes.EnhancedInterest, error)
 }
@@ -421,6 +430,11 @@ func (r *RepositoryImpl) AddInterestToProfile(ctx context.Context, profileID, in
 
 	_, err := r.pgpool.Exec(ctx, query, profileID, interestID, 1) // Default preference_level = 1
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // Foreign key violation
+			span.SetStatus(codes.Error, "Interest or profile not found")
+			return fmt.Errorf("interest %s not found: %w", interestID, locitypes.ErrNotFound)
+		}
 		l.ErrorContext(ctx, "Failed to link interest to profile", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "DB INSERT failed")
@@ -487,3 +501,154 @@ func (r *RepositoryImpl) GetInterestsForProfile(ctx context.Context, profileID u
 	span.SetStatus(codes.Ok, "Interests fetched")
 	return interests, nil
 }
+
+// GetAvailableInterests returns the global interests plus the custom interests owned by userID.
+// Pass uuid.Nil for anonymous callers to get the global catalogue only.
+func (r *RepositoryImpl) GetAvailableInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "GetAvailableInterests", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "interests, user_custom_interests"),
+		attribute.Bool("active_only", activeOnly),
+	))
+	defer span.End()
+
+	query := `
+		SELECT id, name, description, active, created_at, updated_at, source FROM (
+			SELECT id, name, description, COALESCE(active, TRUE) AS active,
+			       created_at, updated_at, 'global' AS source
+			FROM interests
+			UNION ALL
+			SELECT id, name, description, active, created_at, updated_at, 'custom' AS source
+			FROM user_custom_interests
+			WHERE user_id = $1
+		) AS available
+		WHERE NOT $2::boolean OR active
+		ORDER BY name`
+
+	interests, err := r.queryInterests(ctx, query, userID, activeOnly)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching available interests: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interests fetched")
+	return interests, nil
+}
+
+// GetUserInterests returns the interests linked to the user's default profile and their custom interests.
+func (r *RepositoryImpl) GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "GetUserInterests", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "user_profile_interests, user_custom_interests"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	query := `
+		SELECT i.id, i.name, i.description, COALESCE(i.active, TRUE), i.created_at, i.updated_at, 'global' AS source
+		FROM interests i
+		JOIN user_profile_interests upi ON upi.interest_id = i.id
+		JOIN user_preference_profiles p ON p.id = upi.profile_id
+		WHERE p.user_id = $1 AND p.is_default = TRUE
+		UNION ALL
+		SELECT id, name, description, active, created_at, updated_at, 'custom' AS source
+		FROM user_custom_interests
+		WHERE user_id = $1
+		ORDER BY name`
+
+	interests, err := r.queryInterests(ctx, query, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return nil, fmt.Errorf("database error fetching user interests: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interests fetched")
+	return interests, nil
+}
+
+func (r *RepositoryImpl) queryInterests(ctx context.Context, query string, args ...any) ([]*locitypes.Interest, error) {
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interests []*locitypes.Interest
+	for rows.Next() {
+		var i locitypes.Interest
+		if err := rows.Scan(&i.ID, &i.Name, &i.Description, &i.Active, &i.CreatedAt, &i.UpdatedAt, &i.Source); err != nil {
+			return nil, err
+		}
+		interests = append(interests, &i)
+	}
+	return interests, rows.Err()
+}
+
+// GetDefaultProfileID returns the ID of the user's default search profile.
+func (r *RepositoryImpl) GetDefaultProfileID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "GetDefaultProfileID", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.sql.table", "user_preference_profiles"),
+		attribute.String("db.user.id", userID.String()),
+	))
+	defer span.End()
+
+	var profileID uuid.UUID
+	err := r.pgpool.QueryRow(ctx,
+		`SELECT id FROM user_preference_profiles WHERE user_id = $1 AND is_default = TRUE`,
+		userID,
+	).Scan(&profileID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "Default profile not found")
+			return uuid.Nil, fmt.Errorf("default profile for user %s: %w", userID, locitypes.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB query failed")
+		return uuid.Nil, fmt.Errorf("database error fetching default profile: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Default profile found")
+	return profileID, nil
+}
+
+// UpdatePreferenceLevel updates preference_level on a profile's interests and returns the number of rows changed.
+func (r *RepositoryImpl) UpdatePreferenceLevel(ctx context.Context, profileID uuid.UUID, interestIDs []uuid.UUID, level int) (int64, error) {
+	ctx, span := otel.Tracer("UserRepo").Start(ctx, "UpdatePreferenceLevel", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.sql.table", "user_profile_interests"),
+		attribute.String("db.profile.id", profileID.String()),
+		attribute.Int("preference_level", level),
+	))
+	defer span.End()
+
+	l := r.logger.With(slog.String("method", "UpdatePreferenceLevel"), slog.String("profileID", profileID.String()))
+
+	query := `
+		UPDATE user_profile_interests
+		SET preference_level = $2
+		WHERE profile_id = $1
+		  AND ($3::uuid[] IS NULL OR interest_id = ANY($3::uuid[]))`
+
+	tag, err := r.pgpool.Exec(ctx, query, profileID, level, interestIDs)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" { // Check violation
+			span.SetStatus(codes.Error, "Preference level out of range")
+			return 0, fmt.Errorf("preference level %d out of range: %w", level, locitypes.ErrBadRequest)
+		}
+		l.ErrorContext(ctx, "Failed to update preference level", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "DB UPDATE failed")
+		return 0, fmt.Errorf("database error updating preference level: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("rows_affected", tag.RowsAffected()))
+	span.SetStatus(codes.Ok, "Preference level updated")
+	return tag.RowsAffected(), nil
+}
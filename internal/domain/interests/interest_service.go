@@ -14,19 +14,33 @@ import (
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 )
 
+// Preference levels match the user_profile_interests CHECK constraint: 0=Nice, 1=Like, 2=Must-Have.
+const (
+	MinPreferenceLevel = 0
+	MaxPreferenceLevel = 2
+)
+
 // Ensure implementation satisfies the interface
-var _ interestsService = (*interestsServiceImpl)(nil)
+var _ Service = (*interestsServiceImpl)(nil)
 
-// interestsService defines the business logic contract for user operations.
-type interestsService interface {
+// Service defines the business logic contract for interest operations.
+type Service interface {
 	// Removeinterests remove interests
 	Removeinterests(ctx context.Context, userID, interestID uuid.UUID) error
 	GetAllInterests(ctx context.Context) ([]*locitypes.Interest, error)
 	CreateInterest(ctx context.Context, name string, description *string, isActive bool, userID string) (*locitypes.Interest, error)
 	Updateinterests(ctx context.Context, userID, interestID uuid.UUID, params locitypes.UpdateinterestsParams) error
+
+	// GetInterests lists the global catalogue plus the caller's custom interests; userID may be uuid.Nil.
+	GetInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error)
+	GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error)
+	// AddInterestToUser links a global interest to the user's default profile.
+	AddInterestToUser(ctx context.Context, userID, interestID uuid.UUID) error
+	// UpdatePreferenceLevel sets the level on interests of the user's default profile; nil interestIDs means all of them.
+	UpdatePreferenceLevel(ctx context.Context, userID uuid.UUID, interestIDs []uuid.UUID, level int) error
 }
 
-// interestsServiceImpl provides the implementation for interestsService.
+// interestsServiceImpl provides the implementation for Service.
 type interestsServiceImpl struct {
 	logger *slog.Logger
 	repo   Repository
@@ -44,14 +58,18 @@ func NewinterestsService(repo Repository, logger *slog.Logger) *interestsService
 
 // CreateInterest create user interest
 func (s *interestsServiceImpl) CreateInterest(ctx context.Context, name string, description *string, isActive bool, userID string) (*locitypes.Interest, error) {
+	var desc string
+	if description != nil {
+		desc = *description
+	}
 	ctx, span := otel.Tracer("interestsService").Start(ctx, "Createinterests", trace.WithAttributes(
 		attribute.String("name", name),
-		attribute.String("description", *description),
+		attribute.String("description", desc),
 	))
 	defer span.End()
 
 	l := s.logger.With(slog.String("method", "Createinterests"),
-		slog.String("name", name), slog.String("description", *description))
+		slog.String("name", name), slog.String("description", desc))
 	l.DebugContext(ctx, "Adding user interest")
 
 	interest, err := s.repo.CreateInterest(ctx, name, description, isActive, userID)
@@ -132,6 +150,125 @@ func (s *interestsServiceImpl) Updateinterests(ctx context.Context, userID, inte
 	return nil
 }
 
+// GetInterests lists the interests a user can pick from.
+func (s *interestsServiceImpl) GetInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	ctx, span := otel.Tracer("interestsService").Start(ctx, "GetInterests", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Bool("active_only", activeOnly),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetInterests"), slog.String("userID", userID.String()))
+	l.DebugContext(ctx, "Fetching available interests")
+
+	interests, err := s.repo.GetAvailableInterests(ctx, userID, activeOnly)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch available interests", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to fetch available interests")
+		return nil, fmt.Errorf("error fetching available interests: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Available interests fetched successfully")
+	return interests, nil
+}
+
+// GetUserInterests retrieves the interests a user has selected or created.
+func (s *interestsServiceImpl) GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	ctx, span := otel.Tracer("interestsService").Start(ctx, "GetUserInterests", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetUserInterests"), slog.String("userID", userID.String()))
+	l.DebugContext(ctx, "Fetching user interests")
+
+	interests, err := s.repo.GetUserInterests(ctx, userID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch user interests", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to fetch user interests")
+		return nil, fmt.Errorf("error fetching user interests: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "User interests fetched successfully")
+	return interests, nil
+}
+
+// AddInterestToUser adds a global interest to the user's default profile. Adding it twice is a no-op.
+func (s *interestsServiceImpl) AddInterestToUser(ctx context.Context, userID, interestID uuid.UUID) error {
+	ctx, span := otel.Tracer("interestsService").Start(ctx, "AddInterestToUser", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("interest.id", interestID.String()),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "AddInterestToUser"), slog.String("userID", userID.String()), slog.String("interestID", interestID.String()))
+	l.DebugContext(ctx, "Adding interest to user")
+
+	profileID, err := s.repo.GetDefaultProfileID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve default profile")
+		return fmt.Errorf("error resolving default profile: %w", err)
+	}
+
+	if err := s.repo.AddInterestToProfile(ctx, profileID, interestID); err != nil {
+		l.ErrorContext(ctx, "Failed to add interest to user", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to add interest to user")
+		return fmt.Errorf("error adding interest to user: %w", err)
+	}
+
+	l.InfoContext(ctx, "Interest added to user successfully")
+	span.SetStatus(codes.Ok, "Interest added to user")
+	return nil
+}
+
+// UpdatePreferenceLevel changes how strongly interests on the user's default profile are weighted.
+func (s *interestsServiceImpl) UpdatePreferenceLevel(ctx context.Context, userID uuid.UUID, interestIDs []uuid.UUID, level int) error {
+	ctx, span := otel.Tracer("interestsService").Start(ctx, "UpdatePreferenceLevel", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Int("preference_level", level),
+		attribute.Int("interests.count", len(interestIDs)),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "UpdatePreferenceLevel"), slog.String("userID", userID.String()), slog.Int("level", level))
+	l.DebugContext(ctx, "Updating preference level")
+
+	if level < MinPreferenceLevel || level > MaxPreferenceLevel {
+		span.SetStatus(codes.Error, "Preference level out of range")
+		return fmt.Errorf("preference level must be between %d and %d: %w", MinPreferenceLevel, MaxPreferenceLevel, locitypes.ErrBadRequest)
+	}
+	if len(interestIDs) == 0 {
+		interestIDs = nil
+	}
+
+	profileID, err := s.repo.GetDefaultProfileID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve default profile")
+		return fmt.Errorf("error resolving default profile: %w", err)
+	}
+
+	updated, err := s.repo.UpdatePreferenceLevel(ctx, profileID, interestIDs, level)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to update preference level", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to update preference level")
+		return fmt.Errorf("error updating preference level: %w", err)
+	}
+	if updated == 0 {
+		span.SetStatus(codes.Error, "No matching interests")
+		return fmt.Errorf("no matching interests on default profile: %w", locitypes.ErrNotFound)
+	}
+
+	l.InfoContext(ctx, "Preference level updated successfully", slog.Int64("updated", updated))
+	span.SetStatus(codes.Ok, "Preference level updated")
+	return nil
+}
+
 // GetUserEnhancedInterests retrieves a user's enhanced interests.
 //func (s *interestsServiceImpl) GetUserEnhancedInterests(ctx context.Context, userID uuid.UUID) ([]locitypes.EnhancedInterest, error) {
 //	ctx, span := otel.Tracer("interestsService").Start(ctx, "GetUserEnhancedInterests", trace.WithAttributes(
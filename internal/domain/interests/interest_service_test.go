@@ -73,6 +73,32 @@ func (m *MockinterestsRepo) GetInterestsForProfile(ctx context.Context, profileI
 	return args.Get(0).([]*locitypes.Interest), args.Error(1)
 }
 
+func (m *MockinterestsRepo) GetAvailableInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetDefaultProfileID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockinterestsRepo) UpdatePreferenceLevel(ctx context.Context, profileID uuid.UUID, interestIDs []uuid.UUID, level int) (int64, error) {
+	args := m.Called(ctx, profileID, interestIDs, level)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestCreateInterest(t *testing.T) {
 	skipInterestsLegacy(t)
 	// Setup
@@ -336,3 +362,58 @@ func TestUpdateinterests(t *testing.T) {
 		})
 	}
 }
+
+func TestAddInterestToUser(t *testing.T) {
+	ctx := context.Background()
+	userID, profileID, interestID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("Links to default profile", func(t *testing.T) {
+		mockRepo := new(MockinterestsRepo)
+		mockRepo.On("GetDefaultProfileID", mock.Anything, userID).Return(profileID, nil)
+		mockRepo.On("AddInterestToProfile", mock.Anything, profileID, interestID).Return(nil)
+
+		err := NewinterestsService(mockRepo, slog.Default()).AddInterestToUser(ctx, userID, interestID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("No default profile", func(t *testing.T) {
+		mockRepo := new(MockinterestsRepo)
+		mockRepo.On("GetDefaultProfileID", mock.Anything, userID).Return(uuid.Nil, locitypes.ErrNotFound)
+
+		err := NewinterestsService(mockRepo, slog.Default()).AddInterestToUser(ctx, userID, interestID)
+		assert.ErrorIs(t, err, locitypes.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "AddInterestToProfile", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdatePreferenceLevel(t *testing.T) {
+	ctx := context.Background()
+	userID, profileID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		level       int
+		updated     int64
+		expectedErr error
+	}{
+		{name: "Success", level: 2, updated: 3},
+		{name: "Out of range", level: 5, expectedErr: locitypes.ErrBadRequest},
+		{name: "No interests on profile", level: 1, updated: 0, expectedErr: locitypes.ErrNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockinterestsRepo)
+			mockRepo.On("GetDefaultProfileID", mock.Anything, userID).Return(profileID, nil).Maybe()
+			mockRepo.On("UpdatePreferenceLevel", mock.Anything, profileID, []uuid.UUID(nil), tc.level).Return(tc.updated, nil).Maybe()
+
+			err := NewinterestsService(mockRepo, slog.Default()).UpdatePreferenceLevel(ctx, userID, []uuid.UUID{}, tc.level)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
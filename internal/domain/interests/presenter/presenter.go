@@ -0,0 +1,49 @@
+package presenter
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	interestv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/interest"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+func ToProtoInterest(i *locitypes.Interest) *interestv1.Interest {
+	if i == nil {
+		return nil
+	}
+	out := &interestv1.Interest{
+		Id:          i.ID.String(),
+		Name:        i.Name,
+		Description: i.Description,
+		Active:      i.Active,
+		CreatedAt:   timestamppb.New(i.CreatedAt),
+		Source:      i.Source,
+	}
+	if i.UpdatedAt != nil {
+		out.UpdatedAt = timestamppb.New(*i.UpdatedAt)
+	}
+	return out
+}
+
+func ToProtoInterests(interests []*locitypes.Interest) []*interestv1.Interest {
+	out := make([]*interestv1.Interest, 0, len(interests))
+	for _, i := range interests {
+		out = append(out, ToProtoInterest(i))
+	}
+	return out
+}
+
+// FromUpdateRequest maps an UpdateInterest request onto partial update params.
+// An empty name leaves the name unchanged; active is always applied since the proto field is not optional.
+func FromUpdateRequest(req *interestv1.UpdateInterestRequest) locitypes.UpdateinterestsParams {
+	active := req.GetActive()
+	params := locitypes.UpdateinterestsParams{
+		Description: req.Description,
+		Active:      &active,
+	}
+	if name := req.GetName(); name != "" {
+		params.Name = &name
+	}
+	return params
+}
@@ -0,0 +1,566 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+
+	listv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1/listv1connect"
+
+	itinerarylist "github.com/FACorreiaa/loci-connect-api/internal/domain/list"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/list/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListHandler implements the ListServiceHandler interface.
+type ListHandler struct {
+	listv1connect.UnimplementedListServiceHandler
+	service itinerarylist.Service
+	logger  *slog.Logger
+}
+
+// NewListHandler creates a new ListHandler.
+func NewListHandler(svc itinerarylist.Service, logger *slog.Logger) *ListHandler {
+	return &ListHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+func (h *ListHandler) CreateList(
+	ctx context.Context,
+	req *connect.Request[listv1.CreateListRequest],
+) (*connect.Response[listv1.CreateListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Msg.GetName()) == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("name is required"))
+	}
+	cityID, err := presenter.ParseOptionalUUID("city_id", req.Msg.GetCityId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	list, err := h.service.CreateTopLevelList(ctx, userID, req.Msg.GetName(), req.Msg.GetDescription(), cityID, req.Msg.GetIsItinerary(), req.Msg.GetIsPublic())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.CreateListResponse{
+		Success: true,
+		Message: "list created",
+		List:    presenter.ToProtoList(*list, 0),
+	}), nil
+}
+
+// GetLists returns the caller's top-level lists and itineraries, newest first.
+func (h *ListHandler) GetLists(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListsRequest],
+) (*connect.Response[listv1.GetListsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	offset := max(req.Msg.GetOffset(), 0)
+	lists, total, err := h.service.GetUserListsPage(ctx, userID, int(pageLimit(req.Msg.GetLimit())), int(offset), req.Msg.GetIncludeItems())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	result := make([]*listv1.ListWithItems, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, presenter.ToProtoListWithItems(list.List, list.Items))
+	}
+
+	return connect.NewResponse(&listv1.GetListsResponse{
+		Lists:      result,
+		TotalCount: int32(total),
+	}), nil
+}
+
+func (h *ListHandler) GetList(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListRequest],
+) (*connect.Response[listv1.GetListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	if req.Msg.GetIncludeDetailedItems() {
+		detailed, err := h.service.GetListWithDetailedItems(ctx, listID, userID)
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+		return connect.NewResponse(&listv1.GetListResponse{
+			List: presenter.ToProtoListWithDetailedItems(detailed),
+		}), nil
+	}
+
+	details, err := h.service.GetListDetails(ctx, listID, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	items := make([]*locitypes.ListItemWithContent, 0, len(details.Items))
+	for _, item := range details.Items {
+		items = append(items, &locitypes.ListItemWithContent{ListItem: *item})
+	}
+	return connect.NewResponse(&listv1.GetListResponse{
+		List: presenter.ToProtoListWithDetailedItems(&locitypes.ListWithDetailedItems{
+			List:  details.List,
+			Items: items,
+		}),
+	}), nil
+}
+
+func (h *ListHandler) UpdateList(
+	ctx context.Context,
+	req *connect.Request[listv1.UpdateListRequest],
+) (*connect.Response[listv1.UpdateListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	params, err := presenter.FromUpdateListRequest(req.Msg)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	list, err := h.service.UpdateListDetails(ctx, listID, userID, params)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.UpdateListResponse{
+		Success: true,
+		Message: "list updated",
+		List:    presenter.ToProtoList(*list, 0),
+	}), nil
+}
+
+func (h *ListHandler) DeleteList(
+	ctx context.Context,
+	req *connect.Request[listv1.DeleteListRequest],
+) (*connect.Response[listv1.DeleteListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	if err := h.service.DeleteUserList(ctx, listID, userID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.DeleteListResponse{
+		Success: true,
+		Message: "list deleted",
+	}), nil
+}
+
+func (h *ListHandler) CreateItinerary(
+	ctx context.Context,
+	req *connect.Request[listv1.CreateItineraryRequest],
+) (*connect.Response[listv1.CreateItineraryResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	parentListID, err := presenter.ParseUUID("parent_list_id", req.Msg.GetParentListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if strings.TrimSpace(req.Msg.GetName()) == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("name is required"))
+	}
+
+	itinerary, err := h.service.CreateItineraryForList(ctx, userID, parentListID, req.Msg.GetName(), req.Msg.GetDescription(), req.Msg.GetIsPublic())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.CreateItineraryResponse{
+		Success:   true,
+		Message:   "itinerary created",
+		Itinerary: presenter.ToProtoList(*itinerary, 0),
+	}), nil
+}
+
+func (h *ListHandler) AddListItem(
+	ctx context.Context,
+	req *connect.Request[listv1.AddListItemRequest],
+) (*connect.Response[listv1.AddListItemResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	params, err := presenter.FromAddListItemRequest(req.Msg)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	item, err := h.service.AddListItem(ctx, userID, listID, params)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.AddListItemResponse{
+		Success: true,
+		Message: "item added to list",
+		Item:    presenter.ToProtoListItem(*item),
+	}), nil
+}
+
+func (h *ListHandler) UpdateListItem(
+	ctx context.Context,
+	req *connect.Request[listv1.UpdateListItemRequest],
+) (*connect.Response[listv1.UpdateListItemResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	itemID, err := presenter.ParseUUID("item_id", req.Msg.GetItemId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	params, err := presenter.FromUpdateListItemRequest(req.Msg)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	item, err := h.service.UpdateListItem(ctx, userID, listID, itemID, params)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.UpdateListItemResponse{
+		Success: true,
+		Message: "list item updated",
+		Item:    presenter.ToProtoListItem(*item),
+	}), nil
+}
+
+func (h *ListHandler) RemoveListItem(
+	ctx context.Context,
+	req *connect.Request[listv1.RemoveListItemRequest],
+) (*connect.Response[listv1.RemoveListItemResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	itemID, err := presenter.ParseUUID("item_id", req.Msg.GetItemId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	if err := h.service.RemoveListItem(ctx, userID, listID, itemID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.RemoveListItemResponse{
+		Success: true,
+		Message: "list item removed",
+	}), nil
+}
+
+func (h *ListHandler) GetListItems(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListItemsRequest],
+) (*connect.Response[listv1.GetListItemsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	var items []*locitypes.ListItemWithContent
+	if req.Msg.GetIncludeContentDetails() {
+		items, err = h.service.GetListItemsWithContent(ctx, userID, listID, "")
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+	} else {
+		details, err := h.service.GetListDetails(ctx, listID, userID)
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+		items = make([]*locitypes.ListItemWithContent, 0, len(details.Items))
+		for _, item := range details.Items {
+			items = append(items, &locitypes.ListItemWithContent{ListItem: *item})
+		}
+	}
+
+	return connect.NewResponse(&listv1.GetListItemsResponse{
+		Items:      presenter.ToProtoListItemsWithContent(items),
+		TotalCount: int32(len(items)),
+	}), nil
+}
+
+func (h *ListHandler) GetListRestaurants(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListRestaurantsRequest],
+) (*connect.Response[listv1.GetListRestaurantsResponse], error) {
+	items, err := h.contentItems(ctx, req.Msg.GetUserId(), req.Msg.GetListId(), locitypes.ContentTypeRestaurant)
+	if err != nil {
+		return nil, err
+	}
+
+	restaurants := make([]*listv1.RestaurantDetailedInfo, 0, len(items))
+	for _, item := range items {
+		if item.Restaurant != nil {
+			restaurants = append(restaurants, presenter.ToProtoRestaurant(*item.Restaurant))
+		}
+	}
+	return connect.NewResponse(&listv1.GetListRestaurantsResponse{Restaurants: restaurants}), nil
+}
+
+func (h *ListHandler) GetListHotels(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListHotelsRequest],
+) (*connect.Response[listv1.GetListHotelsResponse], error) {
+	items, err := h.contentItems(ctx, req.Msg.GetUserId(), req.Msg.GetListId(), locitypes.ContentTypeHotel)
+	if err != nil {
+		return nil, err
+	}
+
+	hotels := make([]*listv1.HotelDetailedInfo, 0, len(items))
+	for _, item := range items {
+		if item.Hotel != nil {
+			hotels = append(hotels, presenter.ToProtoHotel(*item.Hotel))
+		}
+	}
+	return connect.NewResponse(&listv1.GetListHotelsResponse{Hotels: hotels}), nil
+}
+
+func (h *ListHandler) GetListItineraries(
+	ctx context.Context,
+	req *connect.Request[listv1.GetListItinerariesRequest],
+) (*connect.Response[listv1.GetListItinerariesResponse], error) {
+	items, err := h.contentItems(ctx, req.Msg.GetUserId(), req.Msg.GetListId(), locitypes.ContentTypeItinerary)
+	if err != nil {
+		return nil, err
+	}
+
+	itineraries := make([]*listv1.UserSavedItinerary, 0, len(items))
+	for _, item := range items {
+		if item.Itinerary != nil {
+			itineraries = append(itineraries, presenter.ToProtoItinerary(*item.Itinerary))
+		}
+	}
+	return connect.NewResponse(&listv1.GetListItinerariesResponse{Itineraries: itineraries}), nil
+}
+
+func (h *ListHandler) SavePublicList(
+	ctx context.Context,
+	req *connect.Request[listv1.SavePublicListRequest],
+) (*connect.Response[listv1.SavePublicListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	if err := h.service.SaveList(ctx, userID, listID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.SavePublicListResponse{
+		Success: true,
+		Message: "list saved",
+	}), nil
+}
+
+func (h *ListHandler) UnsaveList(
+	ctx context.Context,
+	req *connect.Request[listv1.UnsaveListRequest],
+) (*connect.Response[listv1.UnsaveListResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", req.Msg.GetListId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	if err := h.service.UnsaveList(ctx, userID, listID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&listv1.UnsaveListResponse{
+		Success: true,
+		Message: "list unsaved",
+	}), nil
+}
+
+func (h *ListHandler) GetSavedLists(
+	ctx context.Context,
+	req *connect.Request[listv1.GetSavedListsRequest],
+) (*connect.Response[listv1.GetSavedListsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	offset := max(req.Msg.GetOffset(), 0)
+	lists, total, err := h.service.GetUserSavedListsPage(ctx, userID, int(pageLimit(req.Msg.GetLimit())), int(offset))
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	result := make([]*listv1.ListWithItems, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, presenter.ToProtoListWithItems(*list, nil))
+	}
+
+	return connect.NewResponse(&listv1.GetSavedListsResponse{
+		Lists:      result,
+		TotalCount: int32(total),
+	}), nil
+}
+
+// SearchPublicLists searches public lists by name/description, ordered by popularity
+// unless sort_by is "recent" or "name".
+func (h *ListHandler) SearchPublicLists(
+	ctx context.Context,
+	req *connect.Request[listv1.SearchPublicListsRequest],
+) (*connect.Response[listv1.SearchPublicListsResponse], error) {
+	start := time.Now()
+
+	cityID, err := presenter.ParseOptionalUUID("city_id", req.Msg.GetCityId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	query := strings.TrimSpace(req.Msg.GetQuery())
+	filters := map[string]string{}
+	if len(req.Msg.GetCategories()) > 0 {
+		filters["categories"] = strings.Join(req.Msg.GetCategories(), ",")
+	}
+	if cityID != nil {
+		filters["city_id"] = cityID.String()
+	}
+
+	sortBy := strings.ToLower(req.Msg.GetSortBy())
+	if sortBy != itinerarylist.SortByRecent && sortBy != itinerarylist.SortByName {
+		sortBy = itinerarylist.SortByPopularity
+	}
+	filters["sort_by"] = sortBy
+
+	offset := max(req.Msg.GetOffset(), 0)
+	lists, total, err := h.service.SearchListsPage(ctx, query, cityID, sortBy, int(pageLimit(req.Msg.GetLimit())), int(offset))
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	result := make([]*listv1.ListWithItems, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, presenter.ToProtoListWithItems(*list, nil))
+	}
+
+	searchMethod := "browse"
+	if query != "" {
+		searchMethod = "text"
+	}
+
+	return connect.NewResponse(&listv1.SearchPublicListsResponse{
+		Lists:      result,
+		TotalCount: int32(total),
+		Metadata: &listv1.SearchMetadata{
+			QueryTimeMs:    float64(time.Since(start).Microseconds()) / 1000,
+			SearchMethod:   searchMethod,
+			FiltersApplied: filters,
+		},
+	}), nil
+}
+
+func (h *ListHandler) contentItems(ctx context.Context, requestedUserID, rawListID string, contentType locitypes.ContentType) ([]*locitypes.ListItemWithContent, error) {
+	userID, err := interceptors.AuthorizeUser(ctx, requestedUserID)
+	if err != nil {
+		return nil, err
+	}
+	listID, err := presenter.ParseUUID("list_id", rawListID)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	items, err := h.service.GetListItemsWithContent(ctx, userID, listID, contentType)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	return items, nil
+}
+
+func (h *ListHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrConflict):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, locitypes.ErrUnauthenticated):
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("list request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+// pageLimit applies the default and maximum page size to a requested limit.
+func pageLimit(limit int32) int32 {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	return min(limit, maxListLimit)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	listv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1/listv1connect"
+
+	itinerarylist "github.com/FACorreiaa/loci-connect-api/internal/domain/list"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	itinerarylist.Service
+	lists      []*locitypes.List
+	items      []*locitypes.ListItemWithContent
+	err        error
+	lastUserID uuid.UUID
+	lastLimit  int
+	lastOffset int
+	lastSortBy string
+}
+
+func (s *stubService) CreateTopLevelList(_ context.Context, userID uuid.UUID, name, description string, cityID *uuid.UUID, isItinerary, isPublic bool) (*locitypes.List, error) {
+	s.lastUserID = userID
+	if s.err != nil {
+		return nil, s.err
+	}
+	list := &locitypes.List{ID: uuid.New(), UserID: userID, Name: name, Description: description, IsItinerary: isItinerary, IsPublic: isPublic}
+	if cityID != nil {
+		list.CityID = *cityID
+	}
+	return list, nil
+}
+
+func (s *stubService) DeleteUserList(_ context.Context, _, userID uuid.UUID) error {
+	s.lastUserID = userID
+	return s.err
+}
+
+func (s *stubService) GetListItemsWithContent(_ context.Context, userID, _ uuid.UUID, _ locitypes.ContentType) ([]*locitypes.ListItemWithContent, error) {
+	s.lastUserID = userID
+	return s.items, s.err
+}
+
+func (s *stubService) GetUserListsPage(_ context.Context, userID uuid.UUID, limit, offset int, _ bool) ([]*locitypes.ListWithItems, int, error) {
+	s.lastUserID = userID
+	s.lastLimit, s.lastOffset = limit, offset
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	page := make([]*locitypes.ListWithItems, 0, len(s.lists))
+	for _, list := range s.lists {
+		page = append(page, &locitypes.ListWithItems{List: *list})
+	}
+	return page, len(s.lists) + offset, nil
+}
+
+func (s *stubService) SearchListsPage(_ context.Context, _ string, _ *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error) {
+	s.lastSortBy, s.lastLimit, s.lastOffset = sortBy, limit, offset
+	return s.lists, len(s.lists) + offset, s.err
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func TestCreateList_RequiresAuthentication(t *testing.T) {
+	h := NewListHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.CreateList(context.Background(), connect.NewRequest(&listv1.CreateListRequest{Name: "Lisbon"}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func TestCreateList_RejectsForeignUserID(t *testing.T) {
+	svc := &stubService{}
+	h := NewListHandler(svc, testutil.NewLogger())
+
+	_, err := h.CreateList(authedContext(uuid.New()), connect.NewRequest(&listv1.CreateListRequest{
+		UserId: uuid.NewString(),
+		Name:   "Lisbon",
+	}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	require.Equal(t, uuid.Nil, svc.lastUserID)
+}
+
+func TestCreateList_UsesAuthenticatedUser(t *testing.T) {
+	svc := &stubService{}
+	h := NewListHandler(svc, testutil.NewLogger())
+	userID := uuid.New()
+
+	resp, err := h.CreateList(authedContext(userID), connect.NewRequest(&listv1.CreateListRequest{Name: "Lisbon", IsPublic: true}))
+	require.NoError(t, err)
+	require.Equal(t, userID, svc.lastUserID)
+	require.Equal(t, userID.String(), resp.Msg.GetList().GetUserId())
+	require.True(t, resp.Msg.GetList().GetIsPublic())
+}
+
+func TestDeleteList_MapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected connect.Code
+	}{
+		{name: "not found", err: fmt.Errorf("list not found: %w", locitypes.ErrNotFound), expected: connect.CodeNotFound},
+		{name: "not owner", err: fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden), expected: connect.CodePermissionDenied},
+		{name: "unexpected", err: fmt.Errorf("connection reset"), expected: connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewListHandler(&stubService{err: tt.err}, testutil.NewLogger())
+
+			_, err := h.DeleteList(authedContext(uuid.New()), connect.NewRequest(&listv1.DeleteListRequest{ListId: uuid.NewString()}))
+			require.Error(t, err)
+			require.Equal(t, tt.expected, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestGetListRestaurants_ReturnsResolvedContent(t *testing.T) {
+	cuisine := "Portuguese"
+	svc := &stubService{
+		items: []*locitypes.ListItemWithContent{
+			{
+				ListItem:   locitypes.ListItem{ItemID: uuid.New(), ContentType: locitypes.ContentTypeRestaurant},
+				Restaurant: &locitypes.RestaurantDetailedInfo{ID: uuid.New(), Name: "Cervejaria Ramiro", CuisineType: &cuisine},
+			},
+			{
+				// Restaurant deleted since it was added to the list.
+				ListItem: locitypes.ListItem{ItemID: uuid.New(), ContentType: locitypes.ContentTypeRestaurant},
+			},
+		},
+	}
+	h := NewListHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetListRestaurants(authedContext(uuid.New()), connect.NewRequest(&listv1.GetListRestaurantsRequest{ListId: uuid.NewString()}))
+	require.NoError(t, err)
+	require.Len(t, resp.Msg.GetRestaurants(), 1)
+	require.Equal(t, "Cervejaria Ramiro", resp.Msg.GetRestaurants()[0].GetPoi().GetName())
+	require.Equal(t, "Portuguese", resp.Msg.GetRestaurants()[0].GetCuisineType())
+}
+
+func TestSearchPublicLists_PassesSortAndPageToService(t *testing.T) {
+	svc := &stubService{
+		lists: []*locitypes.List{
+			{ID: uuid.New(), Name: "Belém", IsPublic: true},
+			{ID: uuid.New(), Name: "Porto", IsPublic: true},
+		},
+	}
+	h := NewListHandler(svc, testutil.NewLogger())
+
+	resp, err := h.SearchPublicLists(context.Background(), connect.NewRequest(&listv1.SearchPublicListsRequest{
+		SortBy: "Name",
+		Limit:  2,
+		Offset: 1,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "name", svc.lastSortBy)
+	require.Equal(t, 2, svc.lastLimit)
+	require.Equal(t, 1, svc.lastOffset)
+	require.Equal(t, int32(3), resp.Msg.GetTotalCount())
+	require.Len(t, resp.Msg.GetLists(), 2)
+	require.Equal(t, "Belém", resp.Msg.GetLists()[0].GetList().GetName())
+	require.Equal(t, "browse", resp.Msg.GetMetadata().GetSearchMethod())
+	require.Equal(t, "name", resp.Msg.GetMetadata().GetFiltersApplied()["sort_by"])
+
+	_, err = h.SearchPublicLists(context.Background(), connect.NewRequest(&listv1.SearchPublicListsRequest{SortBy: "rating"}))
+	require.NoError(t, err)
+	require.Equal(t, "popularity", svc.lastSortBy)
+}
+
+func TestGetLists_PassesPageToService(t *testing.T) {
+	svc := &stubService{lists: []*locitypes.List{{ID: uuid.New(), Name: "Lisbon"}}}
+	h := NewListHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetLists(authedContext(uuid.New()), connect.NewRequest(&listv1.GetListsRequest{Limit: 500, Offset: 40}))
+	require.NoError(t, err)
+	require.Equal(t, maxListLimit, svc.lastLimit)
+	require.Equal(t, 40, svc.lastOffset)
+	require.Equal(t, int32(41), resp.Msg.GetTotalCount())
+	require.Len(t, resp.Msg.GetLists(), 1)
+
+	_, err = h.GetLists(authedContext(uuid.New()), connect.NewRequest(&listv1.GetListsRequest{Offset: -5}))
+	require.NoError(t, err)
+	require.Equal(t, defaultListLimit, svc.lastLimit)
+	require.Equal(t, 0, svc.lastOffset)
+}
+
+func TestListE2E_CreateList(t *testing.T) {
+	userID := uuid.New()
+	handler := NewListHandler(&stubService{}, testutil.NewLogger())
+
+	// Stand-in for the auth interceptor: trust the test token as the user ID.
+	auth := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, req.Header().Get("X-Test-User")), req)
+		}
+	})
+
+	mux := http.NewServeMux()
+	path, h := listv1connect.NewListServiceHandler(handler, connect.WithInterceptors(auth))
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := listv1connect.NewListServiceClient(server.Client(), server.URL)
+	req := connect.NewRequest(&listv1.CreateListRequest{Name: "Weekend in Lisbon"})
+	req.Header().Set("X-Test-User", userID.String())
+
+	resp, err := client.CreateList(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.Msg.GetSuccess())
+	require.Equal(t, "Weekend in Lisbon", resp.Msg.GetList().GetName())
+	require.Equal(t, userID.String(), resp.Msg.GetList().GetUserId())
+}
@@ -0,0 +1,157 @@
+//go:build integration
+
+package itinerarylist
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+var (
+	testListDB   *pgxpool.Pool
+	testListRepo *RepositoryImpl
+	testCityID   uuid.UUID
+)
+
+func TestMain(m *testing.M) {
+	if err := godotenv.Load("../../../.env.test"); err != nil {
+		log.Println("Warning: .env.test file not found for list integration tests.")
+	}
+
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("TEST_DATABASE_URL environment variable is not set for list integration tests")
+	}
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		log.Fatalf("Unable to parse TEST_DATABASE_URL: %v\n", err)
+	}
+	config.MaxConns = 5
+
+	testListDB, err = pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		log.Fatalf("Unable to create connection pool for list tests: %v\n", err)
+	}
+	defer testListDB.Close()
+
+	if err := testListDB.Ping(context.Background()); err != nil {
+		log.Fatalf("Unable to ping test database for list tests: %v\n", err)
+	}
+
+	testListRepo = NewRepository(testListDB, slog.New(slog.DiscardHandler))
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
+
+func createTestUserForList(t *testing.T) uuid.UUID {
+	t.Helper()
+	userID := uuid.New()
+	_, err := testListDB.Exec(context.Background(),
+		"INSERT INTO users (id, username, email, password_hash) VALUES ($1, $2, $3, $4)",
+		userID, "listuser-"+userID.String()[:8], "listuser-"+userID.String()[:8]+"@test.com", "hash")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = testListDB.Exec(context.Background(), "DELETE FROM users WHERE id = $1", userID)
+	})
+	return userID
+}
+
+func createTestCityForList(t *testing.T) uuid.UUID {
+	t.Helper()
+	cityID := uuid.New()
+	_, err := testListDB.Exec(context.Background(),
+		"INSERT INTO cities (id, name, country) VALUES ($1, $2, $3)", cityID, "Test City", "Test Country")
+	require.NoError(t, err)
+	return cityID
+}
+
+func createTestList(t *testing.T, list locitypes.List) *locitypes.List {
+	t.Helper()
+	now := time.Now()
+	list.ID = uuid.New()
+	if testCityID == uuid.Nil {
+		testCityID = createTestCityForList(t)
+	}
+	list.CityID = testCityID
+	list.CreatedAt, list.UpdatedAt = now, now
+	require.NoError(t, testListRepo.CreateList(context.Background(), list))
+	return &list
+}
+
+func TestGetUserListsPage_LeavesOutNestedItineraries(t *testing.T) {
+	ctx := context.Background()
+	userID := createTestUserForList(t)
+
+	lisbon := createTestList(t, locitypes.List{UserID: userID, Name: "Lisbon"})
+	createTestList(t, locitypes.List{UserID: userID, Name: "Lisbon day 1", IsItinerary: true, ParentListID: &lisbon.ID})
+	porto := createTestList(t, locitypes.List{UserID: userID, Name: "Porto weekend", IsItinerary: true})
+
+	lists, total, err := testListRepo.GetUserListsPage(ctx, userID, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, lists, 2)
+	assert.Equal(t, porto.ID, lists[0].ID)
+	assert.Equal(t, lisbon.ID, lists[1].ID)
+
+	lists, total, err = testListRepo.GetUserListsPage(ctx, userID, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, lists, 1)
+	assert.Equal(t, lisbon.ID, lists[0].ID)
+}
+
+func TestSearchListsPage_SortsAndCountsInSQL(t *testing.T) {
+	ctx := context.Background()
+	userID := createTestUserForList(t)
+	term := "sqlpage-" + uuid.NewString()[:8]
+
+	porto := createTestList(t, locitypes.List{UserID: userID, Name: "Porto " + term, IsPublic: true, SaveCount: 9})
+	alfama := createTestList(t, locitypes.List{UserID: userID, Name: "alfama " + term, IsPublic: true, SaveCount: 1})
+	createTestList(t, locitypes.List{UserID: userID, Name: "Private " + term})
+
+	lists, total, err := testListRepo.SearchListsPage(ctx, term, nil, SortByName, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, lists, 1)
+	assert.Equal(t, alfama.ID, lists[0].ID)
+
+	lists, total, err = testListRepo.SearchListsPage(ctx, term, nil, SortByPopularity, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, lists, 2)
+	assert.Equal(t, porto.ID, lists[0].ID)
+}
+
+func TestGetUserSavedListsPage_PagesInSQL(t *testing.T) {
+	ctx := context.Background()
+	userID := createTestUserForList(t)
+	first := createTestList(t, locitypes.List{UserID: userID, Name: "Sintra", IsPublic: true})
+	second := createTestList(t, locitypes.List{UserID: userID, Name: "Cascais", IsPublic: true})
+	require.NoError(t, testListRepo.SaveList(ctx, userID, first.ID))
+	require.NoError(t, testListRepo.SaveList(ctx, userID, second.ID))
+
+	lists, total, err := testListRepo.GetUserSavedListsPage(ctx, userID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, lists, 1)
+	assert.Equal(t, second.ID, lists[0].ID)
+
+	lists, total, err = testListRepo.GetUserSavedListsPage(ctx, userID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Empty(t, lists)
+}
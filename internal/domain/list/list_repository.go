@@ -51,6 +51,10 @@ type Repository interface {
 	DeleteListItem(ctx context.Context, listID, itemID uuid.UUID, contentType string) error
 	DeleteList(ctx context.Context, listID uuid.UUID) error
 	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*locitypes.List, error)
+	GetUserListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error)
+	GetUserSavedListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error)
+	SearchListsPage(ctx context.Context, searchTerm string, cityID *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error)
+	GetItemsForLists(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]*locitypes.ListItem, error)
 }
 
 func NewRepository(pgxpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
@@ -97,7 +101,7 @@ func (r *RepositoryImpl) GetList(ctx context.Context, listID uuid.UUID) (locityp
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return locitypes.List{}, fmt.Errorf("list not found: %w", err)
+			return locitypes.List{}, fmt.Errorf("list not found: %w", locitypes.ErrNotFound)
 		}
 		r.logger.ErrorContext(ctx, "Failed to get list", slog.Any("error", err))
 		return locitypes.List{}, fmt.Errorf("failed to get list: %w", err)
@@ -155,7 +159,42 @@ func (r *RepositoryImpl) GetListItems(ctx context.Context, listID uuid.UUID) ([]
 		return nil, fmt.Errorf("failed to get list items: %w", err)
 	}
 	defer rows.Close()
+	return r.scanListItems(ctx, rows)
+}
+
+// GetItemsForLists retrieves the items of every list in listIDs in one query, keyed
+// by list ID. Lists without items have no entry.
+func (r *RepositoryImpl) GetItemsForLists(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]*locitypes.ListItem, error) {
+	byList := make(map[uuid.UUID][]*locitypes.ListItem, len(listIDs))
+	if len(listIDs) == 0 {
+		return byList, nil
+	}
+	query := `
+        SELECT list_id, item_id, content_type, position, notes, day_number, time_slot, duration,
+               source_llm_interaction_id, item_ai_description, created_at, updated_at
+        FROM list_items
+        WHERE list_id = ANY($1)
+        ORDER BY list_id, position
+    `
+	rows, err := r.pgpool.Query(ctx, query, listIDs)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get items for lists", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get items for lists: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := r.scanListItems(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		byList[item.ListID] = append(byList[item.ListID], item)
+	}
+	return byList, nil
+}
 
+// scanListItems scans list_items rows selected in the column order of GetListItems.
+func (r *RepositoryImpl) scanListItems(ctx context.Context, rows pgx.Rows) ([]*locitypes.ListItem, error) {
 	var items []*locitypes.ListItem
 	for rows.Next() {
 		var item locitypes.ListItem
@@ -195,7 +234,7 @@ func (r *RepositoryImpl) GetListItems(ctx context.Context, listID uuid.UUID) ([]
 		}
 		items = append(items, &item)
 	}
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		r.logger.ErrorContext(ctx, "Error iterating list item rows", slog.Any("error", err))
 		return nil, fmt.Errorf("error iterating list item rows: %w", err)
 	}
@@ -236,7 +275,7 @@ func (r *RepositoryImpl) DeleteListItem(ctx context.Context, listID, itemID uuid
 		return fmt.Errorf("failed to delete list item: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no list item found for list_id %s, item_id %s, and content_type %s", listID, itemID, contentType)
+		return fmt.Errorf("no list item found for list_id %s, item_id %s, and content_type %s: %w", listID, itemID, contentType, locitypes.ErrNotFound)
 	}
 	return nil
 }
@@ -250,7 +289,7 @@ func (r *RepositoryImpl) DeleteList(ctx context.Context, listID uuid.UUID) error
 		return fmt.Errorf("failed to delete list: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no list found with ID %s", listID)
+		return fmt.Errorf("no list found with ID %s: %w", listID, locitypes.ErrNotFound)
 	}
 	return nil
 }
@@ -272,7 +311,7 @@ func (r *RepositoryImpl) UpdateList(ctx context.Context, list locitypes.List) er
 		return fmt.Errorf("failed to update list: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no list found with ID %s", list.ID)
+		return fmt.Errorf("no list found with ID %s: %w", list.ID, locitypes.ErrNotFound)
 	}
 	return nil
 }
@@ -299,7 +338,7 @@ func (r *RepositoryImpl) GetListItem(ctx context.Context, listID, itemID uuid.UU
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return locitypes.ListItem{}, fmt.Errorf("list item not found: %w", err)
+			return locitypes.ListItem{}, fmt.Errorf("list item not found: %w", locitypes.ErrNotFound)
 		}
 		r.logger.ErrorContext(ctx, "Failed to get list item", slog.Any("error", err))
 		return locitypes.ListItem{}, fmt.Errorf("failed to get list item: %w", err)
@@ -346,7 +385,7 @@ func (r *RepositoryImpl) UpdateListItem(ctx context.Context, item locitypes.List
 		return fmt.Errorf("failed to update list item: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no list item found for list_id %s and item_id %s", item.ListID, item.ItemID)
+		return fmt.Errorf("no list item found for list_id %s and item_id %s: %w", item.ListID, item.ItemID, locitypes.ErrNotFound)
 	}
 	return nil
 }
@@ -387,6 +426,151 @@ func (r *RepositoryImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isI
 	return lists, nil
 }
 
+// GetUserListsPage retrieves one page of a user's top-level lists and itineraries,
+// newest first, with their total number. Itineraries nested in a list are left out.
+func (r *RepositoryImpl) GetUserListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error) {
+	var total int
+	if err := r.pgpool.QueryRow(ctx, `SELECT COUNT(*) FROM lists WHERE user_id = $1 AND parent_list_id IS NULL`, userID).Scan(&total); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to count user lists", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to count user lists: %w", err)
+	}
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	query := `
+        SELECT id, user_id, name, description, image_url, is_public, is_itinerary,
+               parent_list_id, city_id, view_count, save_count, created_at, updated_at
+        FROM lists
+        WHERE user_id = $1 AND parent_list_id IS NULL
+        ORDER BY created_at DESC, id
+        LIMIT $2 OFFSET $3
+    `
+	rows, err := r.pgpool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get user lists page", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to get user lists page: %w", err)
+	}
+	defer rows.Close()
+
+	lists, err := r.scanLists(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lists, total, nil
+}
+
+// GetUserSavedListsPage retrieves one page of the lists a user saved, most recently
+// saved first, with their total number.
+func (r *RepositoryImpl) GetUserSavedListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error) {
+	var total int
+	if err := r.pgpool.QueryRow(ctx, `SELECT COUNT(*) FROM saved_lists WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to count user saved lists", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to count user saved lists: %w", err)
+	}
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	query := `
+		SELECT l.id, l.user_id, l.name, l.description, l.image_url, l.is_public, l.is_itinerary,
+		       l.parent_list_id, l.city_id, l.view_count, l.save_count, l.created_at, l.updated_at
+		FROM lists l
+		INNER JOIN saved_lists sl ON l.id = sl.list_id
+		WHERE sl.user_id = $1
+		ORDER BY sl.saved_at DESC, l.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.pgpool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get user saved lists page", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to get user saved lists page: %w", err)
+	}
+	defer rows.Close()
+
+	lists, err := r.scanLists(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lists, total, nil
+}
+
+// searchListsOrder maps the sort orders of SearchListsPage to ORDER BY clauses; each
+// ends on the primary key so pages do not overlap.
+var searchListsOrder = map[string]string{
+	SortByPopularity: "l.save_count DESC, l.created_at DESC, l.id",
+	SortByRecent:     "l.created_at DESC, l.id",
+	SortByName:       "lower(l.name), l.id",
+}
+
+// SearchListsPage retrieves one page of the public lists whose name or description
+// contains searchTerm, optionally in one city, with the total number of matches.
+// sortBy is one of the SortBy constants; anything else sorts by popularity.
+func (r *RepositoryImpl) SearchListsPage(ctx context.Context, searchTerm string, cityID *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error) {
+	where := " WHERE l.is_public = true"
+	var args []interface{}
+	if searchTerm != "" {
+		args = append(args, "%"+searchTerm+"%")
+		where += fmt.Sprintf(" AND (l.name ILIKE $%d OR l.description ILIKE $%d)", len(args), len(args))
+	}
+	if cityID != nil {
+		args = append(args, *cityID)
+		where += fmt.Sprintf(" AND l.city_id = $%d", len(args))
+	}
+
+	var total int
+	if err := r.pgpool.QueryRow(ctx, "SELECT COUNT(*) FROM lists l"+where, args...).Scan(&total); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to count list search results", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to count list search results: %w", err)
+	}
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	order, ok := searchListsOrder[sortBy]
+	if !ok {
+		order = searchListsOrder[SortByPopularity]
+	}
+	query := `
+		SELECT l.id, l.user_id, l.name, l.description, l.image_url, l.is_public, l.is_itinerary,
+		       l.parent_list_id, l.city_id, l.view_count, l.save_count, l.created_at, l.updated_at
+		FROM lists l` + where + fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(args)+1, len(args)+2)
+	rows, err := r.pgpool.Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to search lists page", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to search lists page: %w", err)
+	}
+	defer rows.Close()
+
+	lists, err := r.scanLists(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lists, total, nil
+}
+
+// scanLists scans lists rows selected in the column order of GetList.
+func (r *RepositoryImpl) scanLists(ctx context.Context, rows pgx.Rows) ([]*locitypes.List, error) {
+	var lists []*locitypes.List
+	for rows.Next() {
+		var list locitypes.List
+		err := rows.Scan(
+			&list.ID, &list.UserID, &list.Name, &list.Description, &list.ImageURL, &list.IsPublic, &list.IsItinerary,
+			&list.ParentListID, &list.CityID, &list.ViewCount, &list.SaveCount, &list.CreatedAt, &list.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to scan list", slog.Any("error", err))
+			return nil, fmt.Errorf("failed to scan list: %w", err)
+		}
+		lists = append(lists, &list)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Error iterating list rows", slog.Any("error", err))
+		return nil, fmt.Errorf("error iterating list rows: %w", err)
+	}
+	return lists, nil
+}
+
 // Generic list item methods (support all content types)
 
 // GetListItemByID retrieves a specific item from a list using generic item_id
@@ -406,7 +590,7 @@ func (r *RepositoryImpl) GetListItemByID(ctx context.Context, listID, itemID uui
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return locitypes.ListItem{}, fmt.Errorf("no list item found for list_id %s and item_id %s", listID, itemID)
+			return locitypes.ListItem{}, fmt.Errorf("no list item found for list_id %s and item_id %s: %w", listID, itemID, locitypes.ErrNotFound)
 		}
 		r.logger.ErrorContext(ctx, "Failed to get list item by ID", slog.Any("error", err))
 		return locitypes.ListItem{}, fmt.Errorf("failed to get list item: %w", err)
@@ -423,7 +607,7 @@ func (r *RepositoryImpl) DeleteListItemByID(ctx context.Context, listID, itemID
 		return fmt.Errorf("failed to delete list item: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no list item found for list_id %s and item_id %s", listID, itemID)
+		return fmt.Errorf("no list item found for list_id %s and item_id %s: %w", listID, itemID, locitypes.ErrNotFound)
 	}
 	return nil
 }
@@ -452,7 +636,7 @@ func (r *RepositoryImpl) UnsaveList(ctx context.Context, userID, listID uuid.UUI
 		return fmt.Errorf("failed to unsave list: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("list was not saved by user")
+		return fmt.Errorf("list was not saved by user: %w", locitypes.ErrNotFound)
 	}
 	return nil
 }
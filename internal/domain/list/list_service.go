@@ -46,18 +46,44 @@ type Service interface {
 	RemovePOIListItem(ctx context.Context, userID, listID, poiID uuid.UUID) error
 
 	GetUserLists(ctx context.Context, userID uuid.UUID, isItinerary bool) ([]*locitypes.List, error)
+	GetUserListsPage(ctx context.Context, userID uuid.UUID, limit, offset int, includeItems bool) ([]*locitypes.ListWithItems, int, error)
+	GetUserSavedListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error)
+	SearchListsPage(ctx context.Context, searchTerm string, cityID *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error)
+
+	// Content resolution
+	GetListWithDetailedItems(ctx context.Context, listID, userID uuid.UUID) (*locitypes.ListWithDetailedItems, error)
+	GetListItemsWithContent(ctx context.Context, userID, listID uuid.UUID, contentType locitypes.ContentType) ([]*locitypes.ListItemWithContent, error)
+}
+
+// ContentRepository resolves the generic item IDs stored in list_items to the
+// POI, restaurant, hotel or saved itinerary they reference.
+type ContentRepository interface {
+	GetPOIsByIDs(ctx context.Context, poiIDs []uuid.UUID) (map[uuid.UUID]*locitypes.POIDetailedInfo, error)
+	GetRestaurantsByIDs(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*locitypes.RestaurantDetailedInfo, error)
+	GetHotelsByIDs(ctx context.Context, hotelIDs []uuid.UUID) (map[uuid.UUID]*locitypes.HotelDetailedInfo, error)
+	GetItinerariesByIDs(ctx context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error)
 }
 
+// Sort orders of SearchListsPage.
+const (
+	SortByPopularity = "popularity"
+	SortByRecent     = "recent"
+	SortByName       = "name"
+)
+
 type ServiceImpl struct {
-	logger         *slog.Logger
-	listRepository Repository
+	logger            *slog.Logger
+	listRepository    Repository
+	contentRepository ContentRepository
 }
 
-// NewServiceImpl creates a new instance of ServiceImpl
-func NewServiceImpl(repo Repository, logger *slog.Logger) *ServiceImpl {
+// NewServiceImpl creates a new instance of ServiceImpl. contentRepo may be nil,
+// in which case items are returned without their content details.
+func NewServiceImpl(repo Repository, contentRepo ContentRepository, logger *slog.Logger) *ServiceImpl {
 	return &ServiceImpl{
-		logger:         logger,
-		listRepository: repo,
+		logger:            logger,
+		listRepository:    repo,
+		contentRepository: contentRepo,
 	}
 }
 
@@ -130,7 +156,7 @@ func (s *ServiceImpl) CreateItineraryForList(ctx context.Context, userID, parent
 		l.WarnContext(ctx, "User does not own parent list",
 			slog.String("listOwnerID", parentList.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own parent list")
-		return nil, fmt.Errorf("user does not own parent list")
+		return nil, fmt.Errorf("user does not own parent list: %w", locitypes.ErrForbidden)
 	}
 
 	// Create the itinerary
@@ -187,19 +213,16 @@ func (s *ServiceImpl) GetListDetails(ctx context.Context, listID, userID uuid.UU
 		l.WarnContext(ctx, "Access denied to list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "Access denied")
-		return nil, fmt.Errorf("access denied to list")
+		return nil, fmt.Errorf("access denied to list: %w", locitypes.ErrForbidden)
 	}
 
-	// Fetch list items if it's an itinerary
-	var items []*locitypes.ListItem
-	if list.IsItinerary {
-		items, err = s.listRepository.GetListItems(ctx, listID)
-		if err != nil {
-			l.ErrorContext(ctx, "Failed to fetch list items", slog.Any("error", err))
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Failed to fetch list items")
-			return nil, fmt.Errorf("failed to fetch list items: %w", err)
-		}
+	// Fetch list items; top-level lists hold content-typed items too
+	items, err := s.listRepository.GetListItems(ctx, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch list items", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to fetch list items")
+		return nil, fmt.Errorf("failed to fetch list items: %w", err)
 	}
 
 	result := &locitypes.ListWithItems{
@@ -240,7 +263,7 @@ func (s *ServiceImpl) UpdateListDetails(ctx context.Context, listID, userID uuid
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return nil, fmt.Errorf("user does not own list")
+		return nil, fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Update fields if provided
@@ -303,7 +326,7 @@ func (s *ServiceImpl) DeleteUserList(ctx context.Context, listID, userID uuid.UU
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return fmt.Errorf("user does not own list")
+		return fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Delete the list
@@ -353,7 +376,7 @@ func (s *ServiceImpl) AddListItem(ctx context.Context, userID, listID uuid.UUID,
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return nil, fmt.Errorf("user does not own list")
+		return nil, fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Create the list item with the new structure
@@ -415,7 +438,7 @@ func (s *ServiceImpl) UpdateListItem(ctx context.Context, userID, listID, itemID
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return nil, fmt.Errorf("user does not own list")
+		return nil, fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Fetch the current item by generic item ID
@@ -500,7 +523,7 @@ func (s *ServiceImpl) RemoveListItem(ctx context.Context, userID, listID, itemID
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return fmt.Errorf("user does not own list")
+		return fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Delete the item by generic item ID
@@ -548,14 +571,14 @@ func (s *ServiceImpl) AddPOIListItem(ctx context.Context, userID, listID, poiID
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return nil, fmt.Errorf("user does not own list")
+		return nil, fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Check if the list is an itinerary
 	if !list.IsItinerary {
 		l.WarnContext(ctx, "List is not an itinerary")
 		span.SetStatus(codes.Error, "List is not an itinerary")
-		return nil, fmt.Errorf("list is not an itinerary")
+		return nil, fmt.Errorf("list is not an itinerary: %w", locitypes.ErrBadRequest)
 	}
 
 	// Create the list item
@@ -614,7 +637,7 @@ func (s *ServiceImpl) UpdatePOIListItem(ctx context.Context, userID, listID, poi
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return nil, fmt.Errorf("user does not own list")
+		return nil, fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Fetch the current item
@@ -689,7 +712,7 @@ func (s *ServiceImpl) RemovePOIListItem(ctx context.Context, userID, listID, poi
 		l.WarnContext(ctx, "User does not own list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "User does not own list")
-		return fmt.Errorf("user does not own list")
+		return fmt.Errorf("user does not own list: %w", locitypes.ErrForbidden)
 	}
 
 	// Delete the item
@@ -733,6 +756,57 @@ func (s *ServiceImpl) GetUserLists(ctx context.Context, userID uuid.UUID, isItin
 	return lists, nil
 }
 
+// GetUserListsPage retrieves one page of a user's top-level lists and itineraries,
+// newest first, with their total number. With includeItems the items of the whole
+// page are fetched in one query.
+func (s *ServiceImpl) GetUserListsPage(ctx context.Context, userID uuid.UUID, limit, offset int, includeItems bool) ([]*locitypes.ListWithItems, int, error) {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetUserListsPage", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+		attribute.Bool("include_items", includeItems),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetUserListsPage"),
+		slog.String("userID", userID.String()),
+		slog.Int("limit", limit),
+		slog.Int("offset", offset))
+	l.DebugContext(ctx, "Getting user lists page")
+
+	lists, total, err := s.listRepository.GetUserListsPage(ctx, userID, limit, offset)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get user lists page", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get user lists page")
+		return nil, 0, fmt.Errorf("failed to get user lists: %w", err)
+	}
+
+	var items map[uuid.UUID][]*locitypes.ListItem
+	if includeItems && len(lists) > 0 {
+		ids := make([]uuid.UUID, len(lists))
+		for i, list := range lists {
+			ids[i] = list.ID
+		}
+		items, err = s.listRepository.GetItemsForLists(ctx, ids)
+		if err != nil {
+			l.ErrorContext(ctx, "Failed to get list items", slog.Any("error", err))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to get list items")
+			return nil, 0, fmt.Errorf("failed to get list items: %w", err)
+		}
+	}
+
+	page := make([]*locitypes.ListWithItems, len(lists))
+	for i, list := range lists {
+		page[i] = &locitypes.ListWithItems{List: *list, Items: items[list.ID]}
+	}
+
+	l.InfoContext(ctx, "User lists page fetched successfully", slog.Int("count", len(page)), slog.Int("total", total))
+	span.SetStatus(codes.Ok, "User lists page fetched")
+	return page, total, nil
+}
+
 func (s *ServiceImpl) SaveList(ctx context.Context, userID, listID uuid.UUID) error {
 	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "SaveList", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
@@ -758,14 +832,14 @@ func (s *ServiceImpl) SaveList(ctx context.Context, userID, listID uuid.UUID) er
 	if list.UserID == userID {
 		l.WarnContext(ctx, "User cannot save their own list")
 		span.SetStatus(codes.Error, "Cannot save own list")
-		return fmt.Errorf("cannot save your own list")
+		return fmt.Errorf("cannot save your own list: %w", locitypes.ErrBadRequest)
 	}
 
 	// List must be public to be saved by others
 	if !list.IsPublic {
 		l.WarnContext(ctx, "Cannot save private list")
 		span.SetStatus(codes.Error, "Cannot save private list")
-		return fmt.Errorf("cannot save private list")
+		return fmt.Errorf("cannot save private list: %w", locitypes.ErrForbidden)
 	}
 
 	// Save the list
@@ -832,6 +906,35 @@ func (s *ServiceImpl) GetUserSavedLists(ctx context.Context, userID uuid.UUID) (
 	return lists, nil
 }
 
+// GetUserSavedListsPage retrieves one page of the lists a user saved, most recently
+// saved first, with their total number.
+func (s *ServiceImpl) GetUserSavedListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error) {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetUserSavedListsPage", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetUserSavedListsPage"),
+		slog.String("userID", userID.String()),
+		slog.Int("limit", limit),
+		slog.Int("offset", offset))
+	l.DebugContext(ctx, "Getting user saved lists page")
+
+	lists, total, err := s.listRepository.GetUserSavedListsPage(ctx, userID, limit, offset)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get user saved lists page", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get saved lists page")
+		return nil, 0, fmt.Errorf("failed to get saved lists: %w", err)
+	}
+
+	l.InfoContext(ctx, "User saved lists page fetched successfully", slog.Int("count", len(lists)), slog.Int("total", total))
+	span.SetStatus(codes.Ok, "Saved lists page fetched")
+	return lists, total, nil
+}
+
 func (s *ServiceImpl) GetListItemsByContentType(ctx context.Context, userID, listID uuid.UUID, contentType locitypes.ContentType) ([]*locitypes.ListItem, error) {
 	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetListItemsByContentType", trace.WithAttributes(
 		attribute.String("user.id", userID.String()),
@@ -860,7 +963,7 @@ func (s *ServiceImpl) GetListItemsByContentType(ctx context.Context, userID, lis
 		l.WarnContext(ctx, "Access denied to list",
 			slog.String("listOwnerID", list.UserID.String()))
 		span.SetStatus(codes.Error, "Access denied")
-		return nil, fmt.Errorf("access denied to list")
+		return nil, fmt.Errorf("access denied to list: %w", locitypes.ErrForbidden)
 	}
 
 	// Get items by content type
@@ -912,6 +1015,213 @@ func (s *ServiceImpl) SearchLists(ctx context.Context, searchTerm, category, con
 	return lists, nil
 }
 
+// SearchListsPage retrieves one page of the public lists matching searchTerm and,
+// when set, cityID, ordered by sortBy, with the total number of matches.
+func (s *ServiceImpl) SearchListsPage(ctx context.Context, searchTerm string, cityID *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error) {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "SearchListsPage", trace.WithAttributes(
+		attribute.String("search.term", searchTerm),
+		attribute.String("sort_by", sortBy),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "SearchListsPage"),
+		slog.String("searchTerm", searchTerm),
+		slog.String("sortBy", sortBy))
+	l.DebugContext(ctx, "Searching lists page")
+
+	if cityID != nil {
+		span.SetAttributes(attribute.String("city.id", cityID.String()))
+		l = l.With(slog.String("cityID", cityID.String()))
+	}
+
+	lists, total, err := s.listRepository.SearchListsPage(ctx, searchTerm, cityID, sortBy, limit, offset)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to search lists page", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to search lists")
+		return nil, 0, fmt.Errorf("failed to search lists: %w", err)
+	}
+
+	l.InfoContext(ctx, "Lists search page completed successfully", slog.Int("resultCount", len(lists)), slog.Int("total", total))
+	span.SetStatus(codes.Ok, "Lists search completed")
+	return lists, total, nil
+}
+
+// GetListWithDetailedItems retrieves a list with every item resolved to its content
+func (s *ServiceImpl) GetListWithDetailedItems(ctx context.Context, listID, userID uuid.UUID) (*locitypes.ListWithDetailedItems, error) {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetListWithDetailedItems", trace.WithAttributes(
+		attribute.String("list.id", listID.String()),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetListWithDetailedItems"),
+		slog.String("listID", listID.String()),
+		slog.String("userID", userID.String()))
+	l.DebugContext(ctx, "Getting list with detailed items")
+
+	list, err := s.listRepository.GetList(ctx, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch list", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "List not found")
+		return nil, fmt.Errorf("list not found: %w", err)
+	}
+
+	// Check if user has access (owner or public list)
+	if list.UserID != userID && !list.IsPublic {
+		l.WarnContext(ctx, "Access denied to list",
+			slog.String("listOwnerID", list.UserID.String()))
+		span.SetStatus(codes.Error, "Access denied")
+		return nil, fmt.Errorf("access denied to list: %w", locitypes.ErrForbidden)
+	}
+
+	items, err := s.listRepository.GetListItems(ctx, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to fetch list items", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to fetch list items")
+		return nil, fmt.Errorf("failed to fetch list items: %w", err)
+	}
+
+	detailed, err := s.resolveItemContent(ctx, list.UserID, userID, items)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to resolve list item content", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve list item content")
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "List with detailed items fetched successfully", slog.Int("itemCount", len(detailed)))
+	span.SetStatus(codes.Ok, "List with detailed items fetched")
+	return &locitypes.ListWithDetailedItems{
+		List:  list,
+		Items: detailed,
+	}, nil
+}
+
+// GetListItemsWithContent retrieves a list's items resolved to their content.
+// An empty contentType returns every item in the list.
+func (s *ServiceImpl) GetListItemsWithContent(ctx context.Context, userID, listID uuid.UUID, contentType locitypes.ContentType) ([]*locitypes.ListItemWithContent, error) {
+	ctx, span := otel.Tracer("ItineraryListService").Start(ctx, "GetListItemsWithContent", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("list.id", listID.String()),
+		attribute.String("content.type", string(contentType)),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "GetListItemsWithContent"),
+		slog.String("userID", userID.String()),
+		slog.String("listID", listID.String()),
+		slog.String("contentType", string(contentType)))
+	l.DebugContext(ctx, "Getting list items with content")
+
+	list, err := s.listRepository.GetList(ctx, listID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get list", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "List not found")
+		return nil, fmt.Errorf("list not found: %w", err)
+	}
+
+	// Check if user has access (owner or public list)
+	if list.UserID != userID && !list.IsPublic {
+		l.WarnContext(ctx, "Access denied to list",
+			slog.String("listOwnerID", list.UserID.String()))
+		span.SetStatus(codes.Error, "Access denied")
+		return nil, fmt.Errorf("access denied to list: %w", locitypes.ErrForbidden)
+	}
+
+	var items []*locitypes.ListItem
+	if contentType == "" {
+		items, err = s.listRepository.GetListItems(ctx, listID)
+	} else {
+		items, err = s.listRepository.GetListItemsByContentType(ctx, listID, contentType)
+	}
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get list items", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get list items")
+		return nil, fmt.Errorf("failed to get list items: %w", err)
+	}
+
+	detailed, err := s.resolveItemContent(ctx, list.UserID, userID, items)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to resolve list item content", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to resolve list item content")
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "List items with content fetched successfully", slog.Int("count", len(detailed)))
+	span.SetStatus(codes.Ok, "List items fetched")
+	return detailed, nil
+}
+
+// resolveItemContent looks up the content behind the items with one query per
+// content type. Content that no longer exists is left nil so a deleted POI does not make the whole list unreadable.
+// Saved itineraries are looked up on behalf of the list owner, who owns them; other
+// callers, who can only see a public list, only get the itineraries that are public
+// themselves.
+func (s *ServiceImpl) resolveItemContent(ctx context.Context, ownerID, callerID uuid.UUID, items []*locitypes.ListItem) ([]*locitypes.ListItemWithContent, error) {
+	result := make([]*locitypes.ListItemWithContent, 0, len(items))
+	idsByType := make(map[locitypes.ContentType][]uuid.UUID)
+	for _, item := range items {
+		result = append(result, &locitypes.ListItemWithContent{ListItem: *item})
+		idsByType[item.ContentType] = append(idsByType[item.ContentType], item.ItemID)
+	}
+	if s.contentRepository == nil {
+		return result, nil
+	}
+
+	var (
+		pois        map[uuid.UUID]*locitypes.POIDetailedInfo
+		restaurants map[uuid.UUID]*locitypes.RestaurantDetailedInfo
+		hotels      map[uuid.UUID]*locitypes.HotelDetailedInfo
+		itineraries map[uuid.UUID]*locitypes.UserSavedItinerary
+		err         error
+	)
+	if ids := idsByType[locitypes.ContentTypePOI]; len(ids) > 0 {
+		if pois, err = s.contentRepository.GetPOIsByIDs(ctx, ids); err != nil {
+			return nil, fmt.Errorf("failed to resolve POIs: %w", err)
+		}
+	}
+	if ids := idsByType[locitypes.ContentTypeRestaurant]; len(ids) > 0 {
+		if restaurants, err = s.contentRepository.GetRestaurantsByIDs(ctx, ids); err != nil {
+			return nil, fmt.Errorf("failed to resolve restaurants: %w", err)
+		}
+	}
+	if ids := idsByType[locitypes.ContentTypeHotel]; len(ids) > 0 {
+		if hotels, err = s.contentRepository.GetHotelsByIDs(ctx, ids); err != nil {
+			return nil, fmt.Errorf("failed to resolve hotels: %w", err)
+		}
+	}
+	if ids := idsByType[locitypes.ContentTypeItinerary]; len(ids) > 0 {
+		if itineraries, err = s.contentRepository.GetItinerariesByIDs(ctx, ownerID, ids); err != nil {
+			return nil, fmt.Errorf("failed to resolve itineraries: %w", err)
+		}
+	}
+
+	for _, withContent := range result {
+		id := withContent.ListItem.ItemID
+		switch withContent.ListItem.ContentType {
+		case locitypes.ContentTypePOI:
+			withContent.POI = pois[id]
+		case locitypes.ContentTypeRestaurant:
+			withContent.Restaurant = restaurants[id]
+		case locitypes.ContentTypeHotel:
+			withContent.Hotel = hotels[id]
+		case locitypes.ContentTypeItinerary:
+			if it := itineraries[id]; it != nil && (callerID == ownerID || it.IsPublic) {
+				withContent.Itinerary = it
+			}
+		}
+	}
+	return result, nil
+}
+
 // todo
 // func (r *RepositoryImpl) SaveItinerary(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, name, description string, isPublic bool, parentListID *uuid.UUID) (locitypes.List, error) {
 // 	// Fetch session from chat_sessions
@@ -34,6 +34,38 @@ func (m *MockListRepository) GetList(ctx context.Context, listID uuid.UUID) (loc
 	return args.Get(0).(locitypes.List), args.Error(1)
 }
 
+func (m *MockListRepository) GetUserListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*locitypes.List), args.Int(1), args.Error(2)
+}
+
+func (m *MockListRepository) GetUserSavedListsPage(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*locitypes.List, int, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*locitypes.List), args.Int(1), args.Error(2)
+}
+
+func (m *MockListRepository) SearchListsPage(ctx context.Context, searchTerm string, cityID *uuid.UUID, sortBy string, limit, offset int) ([]*locitypes.List, int, error) {
+	args := m.Called(ctx, searchTerm, cityID, sortBy, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*locitypes.List), args.Int(1), args.Error(2)
+}
+
+func (m *MockListRepository) GetItemsForLists(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]*locitypes.ListItem, error) {
+	args := m.Called(ctx, listIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]*locitypes.ListItem), args.Error(1)
+}
+
 func (m *MockListRepository) UpdateList(ctx context.Context, list locitypes.List) error {
 	args := m.Called(ctx, list)
 	return args.Error(0)
@@ -141,11 +173,27 @@ func (m *MockListRepository) SearchLists(ctx context.Context, searchTerm, catego
 	return args.Get(0).([]*locitypes.List), args.Error(1)
 }
 
+// itineraryContent resolves saved itineraries of their owner; other content is not used.
+type itineraryContent struct {
+	ContentRepository
+	itineraries map[uuid.UUID]*locitypes.UserSavedItinerary
+}
+
+func (c itineraryContent) GetItinerariesByIDs(_ context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error) {
+	found := make(map[uuid.UUID]*locitypes.UserSavedItinerary)
+	for _, id := range itineraryIDs {
+		if it, ok := c.itineraries[id]; ok && it.UserID == userID {
+			found[id] = it
+		}
+	}
+	return found, nil
+}
+
 // Helper to setup service with mock repository
 func setupListServiceTest() (*ServiceImpl, *MockListRepository) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	mockRepo := new(MockListRepository)
-	service := NewServiceImpl(mockRepo, logger)
+	service := NewServiceImpl(mockRepo, nil, logger)
 	return service, mockRepo
 }
 
@@ -580,3 +628,72 @@ func TestServiceImpl_DeleteUserList(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestServiceImpl_GetListWithDetailedItems_HidesPrivateItinerariesFromOthers(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	listID := uuid.New()
+	public := &locitypes.UserSavedItinerary{ID: uuid.New(), UserID: ownerID, Title: "Lisbon in 3 days", IsPublic: true}
+	private := &locitypes.UserSavedItinerary{ID: uuid.New(), UserID: ownerID, Title: "Anniversary trip"}
+	content := itineraryContent{itineraries: map[uuid.UUID]*locitypes.UserSavedItinerary{public.ID: public, private.ID: private}}
+
+	list := locitypes.List{ID: listID, UserID: ownerID, Name: "Portugal", IsPublic: true}
+	items := []*locitypes.ListItem{
+		{ListID: listID, ItemID: public.ID, ContentType: locitypes.ContentTypeItinerary, Position: 1},
+		{ListID: listID, ItemID: private.ID, ContentType: locitypes.ContentTypeItinerary, Position: 2},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		caller  uuid.UUID
+		private *locitypes.UserSavedItinerary
+	}{
+		{name: "owner", caller: ownerID, private: private},
+		{name: "other user", caller: uuid.New(), private: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListRepository)
+			mockRepo.On("GetList", mock.Anything, listID).Return(list, nil).Once()
+			mockRepo.On("GetListItems", mock.Anything, listID).Return(items, nil).Once()
+			service := NewServiceImpl(mockRepo, content, slog.New(slog.DiscardHandler))
+
+			result, err := service.GetListWithDetailedItems(ctx, listID, tt.caller)
+
+			require.NoError(t, err)
+			require.Len(t, result.Items, 2)
+			assert.Equal(t, public, result.Items[0].Itinerary)
+			assert.Equal(t, tt.private, result.Items[1].Itinerary)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServiceImpl_GetUserListsPage_FetchesItemsInOneQuery(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	lisbon := &locitypes.List{ID: uuid.New(), UserID: userID, Name: "Lisbon"}
+	porto := &locitypes.List{ID: uuid.New(), UserID: userID, Name: "Porto", IsItinerary: true}
+	tram := &locitypes.ListItem{ListID: lisbon.ID, ItemID: uuid.New(), ContentType: locitypes.ContentTypePOI}
+
+	mockRepo := new(MockListRepository)
+	mockRepo.On("GetUserListsPage", mock.Anything, userID, 2, 4).Return([]*locitypes.List{lisbon, porto}, 7, nil).Once()
+	mockRepo.On("GetItemsForLists", mock.Anything, []uuid.UUID{lisbon.ID, porto.ID}).
+		Return(map[uuid.UUID][]*locitypes.ListItem{lisbon.ID: {tram}}, nil).Once()
+	service := NewServiceImpl(mockRepo, nil, slog.New(slog.DiscardHandler))
+
+	page, total, err := service.GetUserListsPage(ctx, userID, 2, 4, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, []*locitypes.ListItem{tram}, page[0].Items)
+	assert.Empty(t, page[1].Items)
+	mockRepo.AssertExpectations(t)
+
+	// Without items the item query is skipped.
+	mockRepo.On("GetUserListsPage", mock.Anything, userID, 2, 0).Return([]*locitypes.List{lisbon}, 7, nil).Once()
+	page, _, err = service.GetUserListsPage(ctx, userID, 2, 0, false)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	mockRepo.AssertNumberOfCalls(t, "GetItemsForLists", 1)
+}
@@ -0,0 +1,363 @@
+package presenter
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	listv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/list/v1"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// ParseUUID parses a required UUID field, naming the field in the error.
+func ParseUUID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return id, nil
+}
+
+// ParseOptionalUUID parses a UUID field that may be left empty.
+func ParseOptionalUUID(field, value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := ParseUUID(field, value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// FromContentType maps the proto enum to the domain content type; UNSPECIFIED maps to "".
+func FromContentType(ct listv1.ContentType) locitypes.ContentType {
+	switch ct {
+	case listv1.ContentType_CONTENT_TYPE_POI:
+		return locitypes.ContentTypePOI
+	case listv1.ContentType_CONTENT_TYPE_RESTAURANT:
+		return locitypes.ContentTypeRestaurant
+	case listv1.ContentType_CONTENT_TYPE_HOTEL:
+		return locitypes.ContentTypeHotel
+	case listv1.ContentType_CONTENT_TYPE_ITINERARY:
+		return locitypes.ContentTypeItinerary
+	default:
+		return ""
+	}
+}
+
+func ToContentType(ct locitypes.ContentType) listv1.ContentType {
+	switch ct {
+	case locitypes.ContentTypePOI:
+		return listv1.ContentType_CONTENT_TYPE_POI
+	case locitypes.ContentTypeRestaurant:
+		return listv1.ContentType_CONTENT_TYPE_RESTAURANT
+	case locitypes.ContentTypeHotel:
+		return listv1.ContentType_CONTENT_TYPE_HOTEL
+	case locitypes.ContentTypeItinerary:
+		return listv1.ContentType_CONTENT_TYPE_ITINERARY
+	default:
+		return listv1.ContentType_CONTENT_TYPE_UNSPECIFIED
+	}
+}
+
+// FromUpdateListRequest only sets the string fields the caller filled in; is_public
+// has no presence in the proto so it is always applied.
+func FromUpdateListRequest(req *listv1.UpdateListRequest) (locitypes.UpdateListRequest, error) {
+	params := locitypes.UpdateListRequest{}
+	if req.GetName() != "" {
+		name := req.GetName()
+		params.Name = &name
+	}
+	if req.GetDescription() != "" {
+		description := req.GetDescription()
+		params.Description = &description
+	}
+	if req.GetImageUrl() != "" {
+		imageURL := req.GetImageUrl()
+		params.ImageURL = &imageURL
+	}
+	isPublic := req.GetIsPublic()
+	params.IsPublic = &isPublic
+
+	cityID, err := ParseOptionalUUID("city_id", req.GetCityId())
+	if err != nil {
+		return params, err
+	}
+	params.CityID = cityID
+	return params, nil
+}
+
+func FromAddListItemRequest(req *listv1.AddListItemRequest) (locitypes.AddListItemRequest, error) {
+	itemID, err := ParseUUID("item_id", req.GetItemId())
+	if err != nil {
+		return locitypes.AddListItemRequest{}, err
+	}
+	contentType := FromContentType(req.GetContentType())
+	if contentType == "" {
+		return locitypes.AddListItemRequest{}, fmt.Errorf("content_type is required")
+	}
+	sourceID, err := ParseOptionalUUID("source_llm_interaction_id", req.GetSourceLlmInteractionId())
+	if err != nil {
+		return locitypes.AddListItemRequest{}, err
+	}
+
+	params := locitypes.AddListItemRequest{
+		ItemID:                 itemID,
+		ContentType:            contentType,
+		Position:               int(req.GetPosition()),
+		Notes:                  req.GetNotes(),
+		SourceLlmInteractionID: sourceID,
+		ItemAIDescription:      req.GetItemAiDescription(),
+	}
+	if req.GetDayNumber() > 0 {
+		day := int(req.GetDayNumber())
+		params.DayNumber = &day
+	}
+	if req.GetTimeSlot() != nil {
+		slot := req.GetTimeSlot().AsTime()
+		params.TimeSlot = &slot
+	}
+	if req.GetDurationMinutes() > 0 {
+		duration := int(req.GetDurationMinutes())
+		params.DurationMinutes = &duration
+	}
+	return params, nil
+}
+
+// FromUpdateListItemRequest treats zero values as "leave unchanged" since the proto
+// fields carry no presence.
+func FromUpdateListItemRequest(req *listv1.UpdateListItemRequest) (locitypes.UpdateListItemRequest, error) {
+	params := locitypes.UpdateListItemRequest{}
+	if ct := FromContentType(req.GetContentType()); ct != "" {
+		params.ContentType = &ct
+	}
+	if req.GetPosition() > 0 {
+		position := int(req.GetPosition())
+		params.Position = &position
+	}
+	if req.GetNotes() != "" {
+		notes := req.GetNotes()
+		params.Notes = &notes
+	}
+	if req.GetDayNumber() > 0 {
+		day := int(req.GetDayNumber())
+		params.DayNumber = &day
+	}
+	if req.GetTimeSlot() != nil {
+		slot := req.GetTimeSlot().AsTime()
+		params.TimeSlot = &slot
+	}
+	if req.GetDurationMinutes() > 0 {
+		duration := int(req.GetDurationMinutes())
+		params.DurationMinutes = &duration
+	}
+	if req.GetItemAiDescription() != "" {
+		description := req.GetItemAiDescription()
+		params.ItemAIDescription = &description
+	}
+	sourceID, err := ParseOptionalUUID("source_llm_interaction_id", req.GetSourceLlmInteractionId())
+	if err != nil {
+		return params, err
+	}
+	params.SourceLlmInteractionID = sourceID
+	return params, nil
+}
+
+// ToProtoList converts a list; itemCount is passed separately because the lists
+// table does not store it.
+func ToProtoList(list locitypes.List, itemCount int) *listv1.List {
+	resp := &listv1.List{
+		Id:          list.ID.String(),
+		UserId:      list.UserID.String(),
+		Name:        list.Name,
+		Description: list.Description,
+		ImageUrl:    list.ImageURL,
+		IsPublic:    list.IsPublic,
+		IsItinerary: list.IsItinerary,
+		ViewCount:   int32(list.ViewCount),
+		SaveCount:   int32(list.SaveCount),
+		ItemCount:   int32(itemCount),
+	}
+	if list.ParentListID != nil {
+		resp.ParentListId = list.ParentListID.String()
+	}
+	if list.CityID != uuid.Nil {
+		resp.CityId = list.CityID.String()
+	}
+	if !list.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(list.CreatedAt)
+	}
+	if !list.UpdatedAt.IsZero() {
+		resp.UpdatedAt = timestamppb.New(list.UpdatedAt)
+	}
+	return resp
+}
+
+func ToProtoListItem(item locitypes.ListItem) *listv1.ListItem {
+	resp := &listv1.ListItem{
+		ListId:            item.ListID.String(),
+		ItemId:            item.ItemID.String(),
+		ContentType:       ToContentType(item.ContentType),
+		Position:          int32(item.Position),
+		Notes:             item.Notes,
+		ItemAiDescription: item.ItemAIDescription,
+	}
+	if item.ContentType == locitypes.ContentTypePOI {
+		resp.PoiId = item.ItemID.String()
+	}
+	if item.DayNumber != nil {
+		resp.DayNumber = int32(*item.DayNumber)
+	}
+	if item.TimeSlot != nil {
+		resp.TimeSlot = timestamppb.New(*item.TimeSlot)
+	}
+	if item.Duration != nil {
+		resp.Duration = int32(*item.Duration)
+	}
+	if item.SourceLlmInteractionID != nil {
+		resp.SourceLlmInteractionId = item.SourceLlmInteractionID.String()
+	}
+	if !item.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(item.CreatedAt)
+	}
+	if !item.UpdatedAt.IsZero() {
+		resp.UpdatedAt = timestamppb.New(item.UpdatedAt)
+	}
+	return resp
+}
+
+func ToProtoListItems(items []*locitypes.ListItem) []*listv1.ListItem {
+	result := make([]*listv1.ListItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, ToProtoListItem(*item))
+		}
+	}
+	return result
+}
+
+func ToProtoListWithItems(list locitypes.List, items []*locitypes.ListItem) *listv1.ListWithItems {
+	return &listv1.ListWithItems{
+		List:  ToProtoList(list, len(items)),
+		Items: ToProtoListItems(items),
+	}
+}
+
+func ToProtoListWithDetailedItems(list *locitypes.ListWithDetailedItems) *listv1.ListWithDetailedItems {
+	return &listv1.ListWithDetailedItems{
+		List:  ToProtoList(list.List, len(list.Items)),
+		Items: ToProtoListItemsWithContent(list.Items),
+	}
+}
+
+func ToProtoListItemsWithContent(items []*locitypes.ListItemWithContent) []*listv1.ListItemWithContent {
+	result := make([]*listv1.ListItemWithContent, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		resp := &listv1.ListItemWithContent{ListItem: ToProtoListItem(item.ListItem)}
+		if item.POI != nil {
+			resp.Poi = ToProtoPOI(*item.POI)
+		}
+		if item.Restaurant != nil {
+			resp.Restaurant = ToProtoRestaurant(*item.Restaurant)
+		}
+		if item.Hotel != nil {
+			resp.Hotel = ToProtoHotel(*item.Hotel)
+		}
+		if item.Itinerary != nil {
+			resp.Itinerary = ToProtoItinerary(*item.Itinerary)
+		}
+		result = append(result, resp)
+	}
+	return result
+}
+
+func ToProtoPOI(poi locitypes.POIDetailedInfo) *listv1.POIDetailedInfo {
+	return &listv1.POIDetailedInfo{
+		Id:          poi.ID.String(),
+		Name:        poi.Name,
+		Latitude:    poi.Latitude,
+		Longitude:   poi.Longitude,
+		Category:    poi.Category,
+		Description: poi.Description,
+		Rating:      poi.Rating,
+		ReviewCount: int32(len(poi.Reviews)),
+		PriceRange:  poi.PriceRange,
+		Address:     poi.Address,
+		Phone:       poi.PhoneNumber,
+		Website:     poi.Website,
+		Photos:      poi.Images,
+	}
+}
+
+func ToProtoRestaurant(r locitypes.RestaurantDetailedInfo) *listv1.RestaurantDetailedInfo {
+	return &listv1.RestaurantDetailedInfo{
+		Poi: &listv1.POIDetailedInfo{
+			Id:          r.ID.String(),
+			Name:        r.Name,
+			Latitude:    r.Latitude,
+			Longitude:   r.Longitude,
+			Category:    r.Category,
+			Description: r.Description,
+			Rating:      r.Rating,
+			PriceRange:  deref(r.PriceLevel),
+			Address:     deref(r.Address),
+			Phone:       deref(r.PhoneNumber),
+			Website:     deref(r.Website),
+			Photos:      r.Images,
+		},
+		CuisineType: deref(r.CuisineType),
+	}
+}
+
+func ToProtoHotel(h locitypes.HotelDetailedInfo) *listv1.HotelDetailedInfo {
+	return &listv1.HotelDetailedInfo{
+		Poi: &listv1.POIDetailedInfo{
+			Id:          h.ID.String(),
+			Name:        h.Name,
+			Latitude:    h.Latitude,
+			Longitude:   h.Longitude,
+			Category:    h.Category,
+			Description: h.Description,
+			Rating:      h.Rating,
+			PriceRange:  deref(h.PriceRange),
+			Address:     h.Address,
+			Phone:       deref(h.PhoneNumber),
+			Website:     deref(h.Website),
+			Photos:      h.Images,
+		},
+	}
+}
+
+// ToProtoItinerary exposes the itinerary markdown as itinerary_data.
+func ToProtoItinerary(it locitypes.UserSavedItinerary) *listv1.UserSavedItinerary {
+	resp := &listv1.UserSavedItinerary{
+		Id:            it.ID.String(),
+		UserId:        it.UserID.String(),
+		Title:         it.Title,
+		Description:   it.Description.String,
+		ItineraryData: it.MarkdownContent,
+	}
+	if it.SessionID.Valid {
+		resp.SessionId = uuid.UUID(it.SessionID.Bytes).String()
+	}
+	if !it.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(it.CreatedAt)
+	}
+	if !it.UpdatedAt.IsZero() {
+		resp.UpdatedAt = timestamppb.New(it.UpdatedAt)
+	}
+	return resp
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
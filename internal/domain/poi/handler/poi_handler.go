@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	poiv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi/poiconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/poi"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/poi/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	defaultSemanticWeight = 0.6
+	defaultSemanticLimit  = 20
+	// searchPageSize is the page size of spatial searches, the only ones paged in SQL.
+	searchPageSize = 50
+)
+
+// POIHandler implements the POIServiceHandler interface.
+type POIHandler struct {
+	poiconnect.UnimplementedPOIServiceHandler
+	service poi.Service
+	logger  *slog.Logger
+}
+
+// NewPOIHandler creates a new POIHandler.
+func NewPOIHandler(svc poi.Service, logger *slog.Logger) *POIHandler {
+	return &POIHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// SearchPOI dispatches on search_type: restaurant, hotel, activity and attraction run the
+// nearby lookups, semantic runs the embedding search, and anything else runs a hybrid search.
+func (h *POIHandler) SearchPOI(
+	ctx context.Context,
+	req *connect.Request[poiv1.SearchPOIRequest],
+) (*connect.Response[poiv1.SearchPOIResponse], error) {
+	params, err := presenter.FromSearchRequest(req.Msg)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if params.Query == "" && params.CityName == "" && !params.HasLocation {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query, city_name or coordinates are required"))
+	}
+
+	userID, _ := interceptors.OptionalUserID(ctx)
+	radiusMeters := params.RadiusKm * 1000
+
+	var (
+		pois  []locitypes.POIDetailedInfo
+		total int
+		// paged is set by the spatial search, which filters, sorts and pages in SQL.
+		paged bool
+	)
+
+	switch strings.ToLower(params.SearchType) {
+	case "restaurant", "restaurants":
+		if !params.HasLocation {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("coordinates are required for restaurant search"))
+		}
+		pois, err = h.service.GetNearbyRestaurants(ctx, userID, params.Latitude, params.Longitude, radiusMeters, "", "")
+	case "hotel", "hotels":
+		if !params.HasLocation {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("coordinates are required for hotel search"))
+		}
+		pois, err = h.service.GetNearbyHotels(ctx, userID, params.Latitude, params.Longitude, radiusMeters, "", "")
+	case "activity", "activities":
+		if !params.HasLocation {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("coordinates are required for activity search"))
+		}
+		pois, err = h.service.GetNearbyActivities(ctx, userID, params.Latitude, params.Longitude, radiusMeters, "", "")
+	case "attraction", "attractions":
+		if !params.HasLocation {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("coordinates are required for attraction search"))
+		}
+		pois, err = h.service.GetNearbyAttractions(ctx, userID, params.Latitude, params.Longitude, radiusMeters, "", "")
+	case "semantic":
+		if params.Query == "" {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query is required for semantic search"))
+		}
+		if params.CityName != "" {
+			pois, err = h.service.SearchPOIsByQueryAndCity(ctx, params.Query, params.CityName)
+		} else {
+			pois, err = h.service.SearchPOIsSemantic(ctx, params.Query, defaultSemanticLimit)
+		}
+	default:
+		switch {
+		case params.Query != "" && params.HasLocation:
+			pois, err = h.service.SearchPOIsHybrid(ctx, params.ToPOIFilter(), params.Query, defaultSemanticWeight)
+		case params.Query != "" && params.CityName != "":
+			pois, err = h.service.SearchPOIsByQueryAndCity(ctx, params.Query, params.CityName)
+		case params.HasLocation:
+			pois, total, err = h.service.SearchPOIs(ctx, params.ToSearchFilter(searchPageSize))
+			paged = true
+		default:
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query requires city_name or coordinates"))
+		}
+	}
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	if paged {
+		return connect.NewResponse(presenter.ToSearchPOIResponse(pois, total, searchPageSize)), nil
+	}
+
+	// The other searches return a bounded candidate set that is filtered here and
+	// reported as a single page.
+	pois = filterPOIs(pois, params)
+	sortPOIs(pois, params.SortBy, params.SortOrder)
+
+	return connect.NewResponse(presenter.ToSearchPOIResponse(pois, len(pois), len(pois))), nil
+}
+
+// GetPOI returns a single POI by ID.
+func (h *POIHandler) GetPOI(
+	ctx context.Context,
+	req *connect.Request[poiv1.GetPOIRequest],
+) (*connect.Response[poiv1.GetPOIResponse], error) {
+	poiID, err := uuid.Parse(req.Msg.GetPoiId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid POI ID"))
+	}
+
+	result, err := h.service.GetPOIByID(ctx, poiID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	if result == nil {
+		return nil, connect.NewError(connect.CodeNotFound, locitypes.ErrNotFound)
+	}
+
+	return connect.NewResponse(&poiv1.GetPOIResponse{
+		Poi: presenter.ToPOIDetailedInfo(*result),
+	}), nil
+}
+
+func (h *POIHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrConflict):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrUnauthenticated):
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("POI request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+func filterPOIs(pois []locitypes.POIDetailedInfo, params presenter.SearchParams) []locitypes.POIDetailedInfo {
+	if params.MinRating <= 0 && params.MinPriceLevel == 0 && len(params.SearchTags) == 0 {
+		return pois
+	}
+
+	filtered := make([]locitypes.POIDetailedInfo, 0, len(pois))
+	for _, p := range pois {
+		if params.MinRating > 0 && p.Rating < params.MinRating {
+			continue
+		}
+		if params.MinPriceLevel > 0 && priceLevel(p) < params.MinPriceLevel {
+			continue
+		}
+		if len(params.SearchTags) > 0 && !hasAnyTag(p.Tags, params.SearchTags) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	return filtered
+}
+
+// priceLevel returns the price level of p, or 0 when it has none, so that a POI of
+// unknown price never meets a minimum price.
+func priceLevel(p locitypes.POIDetailedInfo) int {
+	if level, ok := locitypes.ParsePriceLevel(p.PriceLevel); ok {
+		return level
+	}
+	level, _ := locitypes.ParsePriceLevel(p.PriceRange)
+	return level
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, t := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(t, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortPOIs(pois []locitypes.POIDetailedInfo, sortBy, sortOrder string) {
+	desc := strings.EqualFold(sortOrder, "desc")
+	var less func(i, j int) bool
+	switch strings.ToLower(sortBy) {
+	case "rating":
+		less = func(i, j int) bool { return pois[i].Rating < pois[j].Rating }
+	case "distance":
+		less = func(i, j int) bool { return pois[i].Distance < pois[j].Distance }
+	case "name":
+		less = func(i, j int) bool { return strings.ToLower(pois[i].Name) < strings.ToLower(pois[j].Name) }
+	default:
+		return
+	}
+	sort.SliceStable(pois, func(i, j int) bool {
+		if desc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
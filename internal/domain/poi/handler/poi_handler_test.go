@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	poiv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi/poiconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/poi"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	poi.Service
+	pois       []locitypes.POIDetailedInfo
+	single     *locitypes.POIDetailedInfo
+	err        error
+	total      int
+	lastCalls  []string
+	lastUserID uuid.UUID
+	lastFilter locitypes.POIFilter
+}
+
+func (s *stubService) SearchPOIs(_ context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error) {
+	s.lastCalls = append(s.lastCalls, "SearchPOIs")
+	s.lastFilter = filter
+	return s.pois, s.total, s.err
+}
+
+func (s *stubService) SearchPOIsHybrid(_ context.Context, _ locitypes.POIFilter, _ string, _ float64) ([]locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "SearchPOIsHybrid")
+	return s.pois, s.err
+}
+
+func (s *stubService) SearchPOIsSemantic(_ context.Context, _ string, _ int) ([]locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "SearchPOIsSemantic")
+	return s.pois, s.err
+}
+
+func (s *stubService) SearchPOIsByQueryAndCity(_ context.Context, _, _ string) ([]locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "SearchPOIsByQueryAndCity")
+	return s.pois, s.err
+}
+
+func (s *stubService) GetNearbyRestaurants(_ context.Context, userID uuid.UUID, _, _, _ float64, _, _ string) ([]locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "GetNearbyRestaurants")
+	s.lastUserID = userID
+	return s.pois, s.err
+}
+
+func (s *stubService) GetNearbyHotels(_ context.Context, userID uuid.UUID, _, _, _ float64, _, _ string) ([]locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "GetNearbyHotels")
+	s.lastUserID = userID
+	return s.pois, s.err
+}
+
+func (s *stubService) GetPOIByID(_ context.Context, _ uuid.UUID) (*locitypes.POIDetailedInfo, error) {
+	s.lastCalls = append(s.lastCalls, "GetPOIByID")
+	return s.single, s.err
+}
+
+func TestSearchPOI_RequiresSomeCriteria(t *testing.T) {
+	h := NewPOIHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.SearchPOI(context.Background(), connect.NewRequest(&poiv1.SearchPOIRequest{}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestSearchPOI_DispatchesOnSearchType(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *poiv1.SearchPOIRequest
+		expected string
+	}{
+		{
+			name:     "hybrid when query and coordinates",
+			req:      &poiv1.SearchPOIRequest{Query: "museum", Latitude: 38.7, Longitude: -9.1},
+			expected: "SearchPOIsHybrid",
+		},
+		{
+			name:     "semantic by city",
+			req:      &poiv1.SearchPOIRequest{Query: "museum", CityName: "Lisbon", SearchType: ptr("semantic")},
+			expected: "SearchPOIsByQueryAndCity",
+		},
+		{
+			name:     "semantic without city",
+			req:      &poiv1.SearchPOIRequest{Query: "museum", SearchType: ptr("semantic")},
+			expected: "SearchPOIsSemantic",
+		},
+		{
+			name:     "restaurants",
+			req:      &poiv1.SearchPOIRequest{Latitude: 38.7, Longitude: -9.1, SearchType: ptr("restaurant")},
+			expected: "GetNearbyRestaurants",
+		},
+		{
+			name:     "hotels",
+			req:      &poiv1.SearchPOIRequest{Latitude: 38.7, Longitude: -9.1, SearchType: ptr("hotels")},
+			expected: "GetNearbyHotels",
+		},
+		{
+			name:     "spatial only",
+			req:      &poiv1.SearchPOIRequest{Latitude: 38.7, Longitude: -9.1},
+			expected: "SearchPOIs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubService{}
+			h := NewPOIHandler(svc, testutil.NewLogger())
+
+			_, err := h.SearchPOI(context.Background(), connect.NewRequest(tt.req))
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.expected}, svc.lastCalls)
+		})
+	}
+}
+
+func TestSearchPOI_PassesAuthenticatedUser(t *testing.T) {
+	svc := &stubService{}
+	h := NewPOIHandler(svc, testutil.NewLogger())
+	userID := uuid.New()
+
+	ctx := context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+	_, err := h.SearchPOI(ctx, connect.NewRequest(&poiv1.SearchPOIRequest{
+		Latitude: 38.7, Longitude: -9.1, SearchType: ptr("restaurants"),
+	}))
+	require.NoError(t, err)
+	require.Equal(t, userID, svc.lastUserID)
+}
+
+func TestSearchPOI_FiltersAndSorts(t *testing.T) {
+	svc := &stubService{
+		pois: []locitypes.POIDetailedInfo{
+			{ID: uuid.New(), Name: "Low", Rating: 2.0},
+			{ID: uuid.New(), Name: "Mid", Rating: 4.1},
+			{ID: uuid.New(), Name: "Top", Rating: 4.8},
+		},
+	}
+	h := NewPOIHandler(svc, testutil.NewLogger())
+
+	resp, err := h.SearchPOI(context.Background(), connect.NewRequest(&poiv1.SearchPOIRequest{
+		Query:     "museum",
+		Latitude:  38.7,
+		Longitude: -9.1,
+		MinRating: ptr(4.0),
+		SortBy:    ptr("rating"),
+		SortOrder: ptr("desc"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, resp.Msg.GetPois(), 2)
+	require.Equal(t, "Top", resp.Msg.GetPois()[0].GetName())
+	require.Equal(t, int32(2), resp.Msg.GetPagination().GetTotalRecords())
+	require.False(t, resp.Msg.GetPagination().GetHasMore())
+}
+
+func TestSearchPOI_SpatialSearchFiltersAndPagesInSQL(t *testing.T) {
+	svc := &stubService{
+		pois:  []locitypes.POIDetailedInfo{{ID: uuid.New(), Name: "Top", Rating: 4.8}},
+		total: 120,
+	}
+	h := NewPOIHandler(svc, testutil.NewLogger())
+
+	resp, err := h.SearchPOI(context.Background(), connect.NewRequest(&poiv1.SearchPOIRequest{
+		Latitude:   38.7,
+		Longitude:  -9.1,
+		MinRating:  ptr(4.0),
+		MinPrice:   ptr("€€"),
+		SearchTags: []string{"Historic"},
+		SortBy:     ptr("rating"),
+		SortOrder:  ptr("desc"),
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, 4.0, svc.lastFilter.MinRating)
+	require.Equal(t, 2, svc.lastFilter.MinPriceLevel)
+	require.Equal(t, []string{"Historic"}, svc.lastFilter.Tags)
+	require.Equal(t, "rating", svc.lastFilter.SortBy)
+	require.False(t, svc.lastFilter.SortAscending)
+	require.Equal(t, searchPageSize, svc.lastFilter.Limit)
+
+	pagination := resp.Msg.GetPagination()
+	require.Equal(t, int32(120), pagination.GetTotalRecords())
+	require.Equal(t, int32(searchPageSize), pagination.GetPageSize())
+	require.Equal(t, int32(3), pagination.GetTotalPages())
+	require.True(t, pagination.GetHasMore())
+}
+
+func TestSearchPOI_MinPriceIsALowerBound(t *testing.T) {
+	svc := &stubService{
+		pois: []locitypes.POIDetailedInfo{
+			{ID: uuid.New(), Name: "Tasca", PriceRange: "€"},
+			{ID: uuid.New(), Name: "Bistro", PriceLevel: "3"},
+			{ID: uuid.New(), Name: "Belcanto", PriceRange: "€€€€"},
+			{ID: uuid.New(), Name: "Unknown"},
+		},
+	}
+	h := NewPOIHandler(svc, testutil.NewLogger())
+
+	resp, err := h.SearchPOI(context.Background(), connect.NewRequest(&poiv1.SearchPOIRequest{
+		Query: "tasca", Latitude: 38.7, Longitude: -9.1, MinPrice: ptr("€€"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, resp.Msg.GetPois(), 2)
+	require.Equal(t, "Bistro", resp.Msg.GetPois()[0].GetName())
+	require.Equal(t, "Belcanto", resp.Msg.GetPois()[1].GetName())
+
+	_, err = h.SearchPOI(context.Background(), connect.NewRequest(&poiv1.SearchPOIRequest{
+		Latitude: 38.7, Longitude: -9.1, MinPrice: ptr("cheap"),
+	}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestGetPOI_InvalidID(t *testing.T) {
+	h := NewPOIHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.GetPOI(context.Background(), connect.NewRequest(&poiv1.GetPOIRequest{PoiId: "nope"}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestGetPOI_MapsNotFound(t *testing.T) {
+	svc := &stubService{err: fmt.Errorf("poi: %w", locitypes.ErrNotFound)}
+	h := NewPOIHandler(svc, testutil.NewLogger())
+
+	_, err := h.GetPOI(context.Background(), connect.NewRequest(&poiv1.GetPOIRequest{PoiId: uuid.NewString()}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func TestPOIE2E_GetPOI(t *testing.T) {
+	poiID := uuid.New()
+	svc := &stubService{
+		single: &locitypes.POIDetailedInfo{ID: poiID, Name: "Torre de Belém", Latitude: 38.69, Longitude: -9.21, Category: "monument"},
+	}
+	handler := NewPOIHandler(svc, testutil.NewLogger())
+
+	mux := http.NewServeMux()
+	path, h := poiconnect.NewPOIServiceHandler(handler)
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := poiconnect.NewPOIServiceClient(server.Client(), server.URL)
+	resp, err := client.GetPOI(context.Background(), connect.NewRequest(&poiv1.GetPOIRequest{PoiId: poiID.String()}))
+	require.NoError(t, err)
+	require.Equal(t, poiID.String(), resp.Msg.GetPoi().GetId())
+	require.Equal(t, "Torre de Belém", resp.Msg.GetPoi().GetName())
+	require.Equal(t, "monument", resp.Msg.GetPoi().GetCategory())
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,11 +47,13 @@ type Repository interface {
 	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]locitypes.POIDetailedInfo, error)
 	GetFavouritePOIsByUserIDPaginated(ctx context.Context, userID uuid.UUID, limit, offset int) ([]locitypes.POIDetailedInfo, int, error)
 	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]locitypes.POIDetailedInfo, error)
+	GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error)
+	GetPOIsByIDs(ctx context.Context, poiIDs []uuid.UUID) (map[uuid.UUID]*locitypes.POIDetailedInfo, error)
 
 	// POI details
 	FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) (*locitypes.POIDetailedInfo, error)
 	SavePOIDetails(ctx context.Context, poi locitypes.POIDetailedInfo, cityID uuid.UUID) (uuid.UUID, error)
-	SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error)
+	SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error)
 
 	// Vector similarity search methods
 	FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]locitypes.POIDetailedInfo, error)
@@ -63,10 +66,12 @@ type Repository interface {
 	FindHotelDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) ([]locitypes.HotelDetailedInfo, error)
 	SaveHotelDetails(ctx context.Context, hotel locitypes.HotelDetailedInfo, cityID uuid.UUID) (uuid.UUID, error)
 	GetHotelByID(ctx context.Context, hotelID uuid.UUID) (*locitypes.HotelDetailedInfo, error)
+	GetHotelsByIDs(ctx context.Context, hotelIDs []uuid.UUID) (map[uuid.UUID]*locitypes.HotelDetailedInfo, error)
 	// Restaurants
 	FindRestaurantDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64, preferences *locitypes.RestaurantUserPreferences) ([]locitypes.RestaurantDetailedInfo, error)
 	SaveRestaurantDetails(ctx context.Context, restaurant locitypes.RestaurantDetailedInfo, cityID uuid.UUID) (uuid.UUID, error)
 	GetRestaurantByID(ctx context.Context, restaurantID uuid.UUID) (*locitypes.RestaurantDetailedInfo, error)
+	GetRestaurantsByIDs(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*locitypes.RestaurantDetailedInfo, error)
 	// GetPOIsByCityIDAndCategory(ctx context.Context, cityID uuid.UUID, category string) ([]locitypes.POIDetailedInfo, error)
 	// GetPOIsByCityIDAndCategories(ctx context.Context, cityID uuid.UUID, categories []string) ([]locitypes.POIDetailedInfo, error)
 	// GetPOIsByCityIDAndName(ctx context.Context, cityID uuid.UUID, name string) ([]locitypes.POIDetailedInfo, error)
@@ -77,6 +82,7 @@ type Repository interface {
 	// AddPersonalizedPOItoFavourites(ctx context.Context, poiID uuid.UUID, userID uuid.UUID) (uuid.UUID, error)
 
 	GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*locitypes.UserSavedItinerary, error)
+	GetItinerariesByIDs(ctx context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error)
 	GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]locitypes.UserSavedItinerary, int, error)
 	UpdateItinerary(ctx context.Context, userID, itineraryID uuid.UUID, updates locitypes.UpdateItineraryRequest) (*locitypes.UserSavedItinerary, error)
 	SaveItinerary(ctx context.Context, userID, cityID uuid.UUID) (uuid.UUID, error)
@@ -620,6 +626,98 @@ func (r *RepositoryImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID)
 	return pois, nil
 }
 
+// GetPOIByID returns a single POI from points_of_interest, wrapping locitypes.ErrNotFound when missing.
+func (r *RepositoryImpl) GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "GetPOIByID", trace.WithAttributes(
+		attribute.String("poi.id", poiID.String()),
+	))
+	defer span.End()
+
+	poi, err := r.scanPOIByID(ctx, r.pgpool.QueryRow(ctx, poiByIDQuery+" WHERE p.id = $1", poiID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "POI not found")
+			return nil, fmt.Errorf("poi %s: %w", poiID, locitypes.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query POI by ID")
+		return nil, fmt.Errorf("failed to query points_of_interest by ID: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "POI found by ID")
+	return poi, nil
+}
+
+// GetPOIsByIDs returns the POIs among poiIDs that exist, keyed by ID.
+func (r *RepositoryImpl) GetPOIsByIDs(ctx context.Context, poiIDs []uuid.UUID) (map[uuid.UUID]*locitypes.POIDetailedInfo, error) {
+	ctx, span := otel.Tracer("Repository").Start(ctx, "GetPOIsByIDs", trace.WithAttributes(
+		attribute.Int("poi.count", len(poiIDs)),
+	))
+	defer span.End()
+
+	pois := make(map[uuid.UUID]*locitypes.POIDetailedInfo, len(poiIDs))
+	if len(poiIDs) == 0 {
+		return pois, nil
+	}
+	rows, err := r.pgpool.Query(ctx, poiByIDQuery+" WHERE p.id = ANY($1)", poiIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query POIs by ID")
+		return nil, fmt.Errorf("failed to query points_of_interest by IDs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		poi, err := r.scanPOIByID(ctx, rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan points_of_interest row: %w", err)
+		}
+		pois[poi.ID] = poi
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating points_of_interest rows: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "POIs found by ID")
+	return pois, nil
+}
+
+// poiByIDQuery selects the columns scanned by scanPOIByID; callers add the WHERE clause.
+const poiByIDQuery = `
+		SELECT
+			p.id, p.name, COALESCE(p.description, ''), ST_X(p.location) AS longitude, ST_Y(p.location) AS latitude,
+			COALESCE(p.category, p.poi_type, ''), COALESCE(p.address, ''), COALESCE(p.website, ''),
+			COALESCE(p.phone_number, ''), COALESCE(p.average_rating, 0)::float8, COALESCE(p.tags, '{}'),
+			p.opening_hours, p.city_id, COALESCE(c.name, ''), p.source::text, p.created_at
+		FROM points_of_interest p
+		LEFT JOIN cities c ON c.id = p.city_id`
+
+func (r *RepositoryImpl) scanPOIByID(ctx context.Context, row pgx.Row) (*locitypes.POIDetailedInfo, error) {
+	var poi locitypes.POIDetailedInfo
+	var openingHours []byte
+	var cityID uuid.NullUUID
+	if err := row.Scan(
+		&poi.ID, &poi.Name, &poi.DescriptionPOI, &poi.Longitude, &poi.Latitude,
+		&poi.Category, &poi.Address, &poi.Website,
+		&poi.PhoneNumber, &poi.Rating, &poi.Tags,
+		&openingHours, &cityID, &poi.City, &poi.Source, &poi.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if cityID.Valid {
+		poi.CityID = cityID.UUID
+	}
+	if len(openingHours) > 0 {
+		if err := json.Unmarshal(openingHours, &poi.OpeningHours); err != nil {
+			r.logger.WarnContext(ctx, "Failed to decode opening hours", slog.String("poi_id", poi.ID.String()), slog.Any("error", err))
+		}
+	}
+	poi.Description = poi.DescriptionPOI
+	return &poi, nil
+}
+
 func (r *RepositoryImpl) FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) (*locitypes.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "FindPOIDetailedInfos", trace.WithAttributes(
 		attribute.String("city.id", cityID.String()),
@@ -764,30 +862,15 @@ func (r *RepositoryImpl) SavePOIDetails(ctx context.Context, poi locitypes.POIDe
 	// Convert price_range to price_level for points_of_interest
 	var priceLevel *int
 	if poi.PriceRange != "" {
-		switch poi.PriceRange {
-		case "€", "$", "free", "Free", "1":
-			level := 1
-			priceLevel = &level
-		case "€€", "$$", "budget", "Budget", "2":
-			level := 2
-			priceLevel = &level
-		case "€€€", "$$$", "moderate", "Moderate", "3":
-			level := 3
-			priceLevel = &level
-		case "€€€€", "$$$$", "expensive", "Expensive", "4":
-			level := 4
-			priceLevel = &level
-		case "luxury", "Luxury", "premium", "Premium", "5":
-			level := 5
-			priceLevel = &level
-		default:
+		level, ok := locitypes.ParsePriceLevel(poi.PriceRange)
+		if !ok {
 			r.logger.WarnContext(ctx, "Unknown price range",
 				slog.String("price_range", poi.PriceRange),
 				slog.String("poi_name", poi.Name))
 			// Default to level 2 (budget) for unknown price ranges
-			level := 2
-			priceLevel = &level
+			level = 2
 		}
+		priceLevel = &level
 	}
 
 	// Insert into points_of_interest table
@@ -968,37 +1051,77 @@ func (r *RepositoryImpl) GetHotelByID(ctx context.Context, hotelID uuid.UUID) (*
 	))
 	defer span.End()
 
-	query := `
+	hotel, err := scanHotelByID(r.pgpool.QueryRow(ctx, hotelByIDQuery+" WHERE id = $1", hotelID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			span.SetStatus(codes.Ok, "No hotel found")
+			return nil, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query hotel details by ID")
+		return nil, fmt.Errorf("failed to query hotel_details by ID: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Hotel details found by ID")
+	return hotel, nil
+}
+
+// GetHotelsByIDs returns the hotels among hotelIDs that exist, keyed by ID.
+func (r *RepositoryImpl) GetHotelsByIDs(ctx context.Context, hotelIDs []uuid.UUID) (map[uuid.UUID]*locitypes.HotelDetailedInfo, error) {
+	ctx, span := otel.Tracer("HotelRepository").Start(ctx, "GetHotelsByIDs", trace.WithAttributes(
+		attribute.Int("hotel.count", len(hotelIDs)),
+	))
+	defer span.End()
+
+	hotels := make(map[uuid.UUID]*locitypes.HotelDetailedInfo, len(hotelIDs))
+	if len(hotelIDs) == 0 {
+		return hotels, nil
+	}
+	rows, err := r.pgpool.Query(ctx, hotelByIDQuery+" WHERE id = ANY($1)", hotelIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query hotel details by ID")
+		return nil, fmt.Errorf("failed to query hotel_details by IDs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		hotel, err := scanHotelByID(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan hotel_details row: %w", err)
+		}
+		hotels[hotel.ID] = hotel
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating hotel_details rows: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Hotel details found by ID")
+	return hotels, nil
+}
+
+// hotelByIDQuery selects the columns scanned by scanHotelByID; callers add the WHERE clause.
+const hotelByIDQuery = `
 		SELECT
 			id, name, description, latitude, longitude, address, website, phone_number,
 			opening_hours, price_range, category, tags, images, rating, llm_interaction_id
-		FROM hotel_details
-		WHERE id = $1
-	`
-	row := r.pgpool.QueryRow(ctx, query, hotelID)
+		FROM hotel_details`
 
+func scanHotelByID(row pgx.Row) (*locitypes.HotelDetailedInfo, error) {
 	var hotel locitypes.HotelDetailedInfo
 	var llmInteractionID uuid.NullUUID
-	err := row.Scan(
+	if err := row.Scan(
 		&hotel.ID, &hotel.Name, &hotel.Description, &hotel.Latitude, &hotel.Longitude,
 		&hotel.Address, &hotel.Website, &hotel.PhoneNumber, &hotel.OpeningHours,
 		&hotel.PriceRange, &hotel.Category, &hotel.Tags, &hotel.Images, &hotel.Rating,
 		&llmInteractionID,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			span.SetStatus(codes.Ok, "No hotel found")
-			return nil, nil
-		}
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to query hotel details by ID")
-		return nil, fmt.Errorf("failed to query hotel_details by ID: %w", err)
+	); err != nil {
+		return nil, err
 	}
-
 	if llmInteractionID.Valid {
 		hotel.LlmInteractionID = llmInteractionID.UUID
 	}
-	span.SetStatus(codes.Ok, "Hotel details found by ID")
 	return &hotel, nil
 }
 
@@ -1177,83 +1300,170 @@ func (r *RepositoryImpl) GetRestaurantByID(ctx context.Context, restaurantID uui
 	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "GetRestaurantByID")
 	defer span.End()
 
-	query := `
+	restaurant, err := scanRestaurantByID(r.pgpool.QueryRow(ctx, restaurantByIDQuery+" WHERE id = $1", restaurantID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			span.SetStatus(codes.Ok, "Restaurant not found")
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get restaurant: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Restaurant found")
+	return restaurant, nil
+}
+
+// GetRestaurantsByIDs returns the restaurants among restaurantIDs that exist, keyed by ID.
+func (r *RepositoryImpl) GetRestaurantsByIDs(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*locitypes.RestaurantDetailedInfo, error) {
+	ctx, span := otel.Tracer("RestaurantRepository").Start(ctx, "GetRestaurantsByIDs", trace.WithAttributes(
+		attribute.Int("restaurant.count", len(restaurantIDs)),
+	))
+	defer span.End()
+
+	restaurants := make(map[uuid.UUID]*locitypes.RestaurantDetailedInfo, len(restaurantIDs))
+	if len(restaurantIDs) == 0 {
+		return restaurants, nil
+	}
+	rows, err := r.pgpool.Query(ctx, restaurantByIDQuery+" WHERE id = ANY($1)", restaurantIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get restaurants: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		restaurant, err := scanRestaurantByID(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan restaurant: %w", err)
+		}
+		restaurants[restaurant.ID] = restaurant
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating restaurant rows: %w", err)
+	}
+	span.SetStatus(codes.Ok, "Restaurants found")
+	return restaurants, nil
+}
+
+// restaurantByIDQuery selects the columns scanned by scanRestaurantByID; callers add
+// the WHERE clause.
+const restaurantByIDQuery = `
         SELECT
             id, name, description, latitude, longitude, address, website, phone_number,
             opening_hours, price_level, category, tags, images, rating, cuisine_type, llm_interaction_id
-        FROM restaurant_details
-        WHERE id = $1
-    `
+        FROM restaurant_details`
+
+func scanRestaurantByID(row pgx.Row) (*locitypes.RestaurantDetailedInfo, error) {
 	var restaurant locitypes.RestaurantDetailedInfo
 	var llmID uuid.NullUUID
-	err := r.pgpool.QueryRow(ctx, query, restaurantID).Scan(&restaurant.ID, &restaurant.Name,
+	if err := row.Scan(&restaurant.ID, &restaurant.Name,
 		&restaurant.Description, &restaurant.Latitude,
 		&restaurant.Longitude, &restaurant.Address,
 		&restaurant.Website, &restaurant.PhoneNumber,
 		&restaurant.OpeningHours, &restaurant.PriceLevel,
 		&restaurant.Category, &restaurant.Tags,
 		&restaurant.Images, &restaurant.Rating,
-		&restaurant.CuisineType, &llmID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			span.SetStatus(codes.Ok, "Restaurant not found")
-			return nil, nil
-		}
-		span.RecordError(err)
-		return nil, fmt.Errorf("failed to get restaurant: %w", err)
+		&restaurant.CuisineType, &llmID); err != nil {
+		return nil, err
 	}
 	if llmID.Valid {
 		restaurant.LlmInteractionID = llmID.UUID
 	}
-	span.SetStatus(codes.Ok, "Restaurant found")
 	return &restaurant, nil
 }
 
-func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error) {
+// SearchPOIs returns the page of POIs within filter.Radius of filter.Location that
+// match its filters, with the total number of matches.
+func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error) {
 	ctx, span := otel.Tracer("Repository").Start(ctx, "SearchPOIs", trace.WithAttributes(
 		attribute.Float64("location.latitude", filter.Location.Latitude),
 		attribute.Float64("location.longitude", filter.Location.Longitude),
 		attribute.Float64("radius", filter.Radius),
 		attribute.String("category", filter.Category),
+		attribute.Int("limit", filter.Limit),
+		attribute.Int("offset", filter.Offset),
 	))
 	defer span.End()
 
 	l := r.logger.With(slog.String("method", "SearchPOIs"))
 
 	// Base query using PostGIS for geospatial filtering
-	query := `
-        SELECT
-            id,
-            name,
-            description,
-            ST_X(location::geometry) AS longitude,
-            ST_Y(location::geometry) AS latitude,
-            category,
-            ST_Distance(
-                location,
-                ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
-            ) AS distance_meters
-        FROM points_of_interest
-        WHERE ST_DWithin(
+	conditions := []string{`ST_DWithin(
             location,
             ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
             $3
-        )
-    `
+        )`}
 	args := []interface{}{
 		filter.Location.Longitude, // $1
 		filter.Location.Latitude,  // $2
 		filter.Radius * 1000,      // $3 (convert km to meters for ST_DWithin)
 	}
+	argIndex := 4
 
-	// Add category filter if provided
 	if filter.Category != "" {
-		query += ` AND category = $4`
-		args = append(args, filter.Category) // $4
+		conditions = append(conditions, fmt.Sprintf("category = $%d", argIndex))
+		args = append(args, filter.Category)
+		argIndex++
+	}
+	if filter.MinRating > 0 {
+		conditions = append(conditions, fmt.Sprintf("average_rating >= $%d", argIndex))
+		args = append(args, filter.MinRating)
+		argIndex++
+	}
+	if filter.MinPriceLevel > 0 {
+		// A POI of unknown price never meets a minimum price.
+		conditions = append(conditions, fmt.Sprintf("price_level >= $%d", argIndex))
+		args = append(args, filter.MinPriceLevel)
+		argIndex++
+	}
+	if len(filter.Tags) > 0 {
+		tags := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			tags[i] = strings.ToLower(tag)
+		}
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(tags) AS t(tag) WHERE lower(t.tag) = ANY($%d))", argIndex))
+		args = append(args, tags)
+		argIndex++
 	}
 
-	// Order by distance
-	query += ` ORDER BY distance_meters ASC`
+	direction := "DESC"
+	if filter.SortAscending {
+		direction = "ASC"
+	}
+	orderBy := "distance_meters ASC"
+	switch strings.ToLower(filter.SortBy) {
+	case "rating":
+		orderBy = fmt.Sprintf("average_rating %s NULLS LAST, distance_meters ASC", direction)
+	case "distance":
+		orderBy = "distance_meters " + direction
+	case "name":
+		orderBy = fmt.Sprintf("lower(name) %s, id", direction)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT
+            id,
+            name,
+            description,
+            ST_X(location::geometry) AS longitude,
+            ST_Y(location::geometry) AS latitude,
+            category,
+            average_rating,
+            price_level,
+            tags,
+            ST_Distance(
+                location,
+                ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
+            ) AS distance_meters,
+            COUNT(*) OVER()
+        FROM points_of_interest
+        WHERE %s
+        ORDER BY %s`, strings.Join(conditions, " AND "), orderBy)
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+		args = append(args, filter.Limit, filter.Offset)
+	}
 
 	l.DebugContext(ctx, "Executing POI search query", slog.String("query", query), slog.Any("args", args))
 
@@ -1263,16 +1473,21 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFil
 		l.ErrorContext(ctx, "Failed to query POIs", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Database query failed")
-		return nil, fmt.Errorf("failed to search points_of_interest: %w", err)
+		return nil, 0, fmt.Errorf("failed to search points_of_interest: %w", err)
 	}
 	defer rows.Close()
 
 	// Collect results
-	var pois []locitypes.POIDetailedInfo
+	var (
+		pois  []locitypes.POIDetailedInfo
+		total int
+	)
 	for rows.Next() {
 		var poi locitypes.POIDetailedInfo
 		var distanceMeters float64
 		var description sql.NullString // Handle NULL description
+		var rating sql.NullFloat64
+		var priceLevel sql.NullInt32
 
 		err := rows.Scan(
 			&poi.ID,
@@ -1281,18 +1496,26 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFil
 			&poi.Longitude,
 			&poi.Latitude,
 			&poi.Category,
+			&rating,
+			&priceLevel,
+			&poi.Tags,
 			&distanceMeters,
+			&total,
 		)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to scan POI row", slog.Any("error", err))
 			span.RecordError(err)
-			return nil, fmt.Errorf("failed to scan POI row: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan POI row: %w", err)
 		}
 
 		// Set description if valid
 		if description.Valid {
 			poi.DescriptionPOI = description.String
 		}
+		poi.Rating = rating.Float64
+		if priceLevel.Valid {
+			poi.PriceLevel = strconv.Itoa(int(priceLevel.Int32))
+		}
 
 		// Convert distance from meters to kilometers
 		poi.Distance = distanceMeters / 1000
@@ -1304,7 +1527,15 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFil
 	if err = rows.Err(); err != nil {
 		l.ErrorContext(ctx, "Error iterating POI rows", slog.Any("error", err))
 		span.RecordError(err)
-		return nil, fmt.Errorf("error iterating POI rows: %w", err)
+		return nil, 0, fmt.Errorf("error iterating POI rows: %w", err)
+	}
+	if len(pois) == 0 && filter.Offset > 0 {
+		// Past the last page, so COUNT(*) OVER() saw no rows; count the matches instead.
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM points_of_interest WHERE %s", strings.Join(conditions, " AND "))
+		if err := r.pgpool.QueryRow(ctx, countQuery, args[:argIndex-1]...).Scan(&total); err != nil {
+			span.RecordError(err)
+			return nil, 0, fmt.Errorf("failed to count points_of_interest: %w", err)
+		}
 	}
 
 	// Log and set span status
@@ -1312,11 +1543,11 @@ func (r *RepositoryImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFil
 		l.InfoContext(ctx, "No POIs found")
 		span.SetStatus(codes.Ok, "No POIs found")
 	} else {
-		l.InfoContext(ctx, "POIs found", slog.Int("count", len(pois)))
+		l.InfoContext(ctx, "POIs found", slog.Int("count", len(pois)), slog.Int("total", total))
 		span.SetStatus(codes.Ok, "POIs found")
 	}
 
-	return pois, nil
+	return pois, total, nil
 }
 
 func (r *RepositoryImpl) GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*locitypes.UserSavedItinerary, error) {
@@ -1329,15 +1560,66 @@ func (r *RepositoryImpl) GetItinerary(ctx context.Context, userID, itineraryID u
 	))
 	defer span.End()
 
-	query := `
+	itinerary, err := scanItinerary(r.pgpool.QueryRow(ctx, itineraryQuery+" WHERE id = $1 AND user_id = $2", itineraryID, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = fmt.Errorf("no itinerary found with ID %s for user %s: %w", itineraryID, userID, locitypes.ErrNotFound)
+			span.RecordError(err)
+			return nil, err
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to scan user_saved_itineraries row: %w", err)
+	}
+
+	return itinerary, nil
+}
+
+// GetItinerariesByIDs returns the itineraries among itineraryIDs that userID owns,
+// keyed by ID.
+func (r *RepositoryImpl) GetItinerariesByIDs(ctx context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error) {
+	ctx, span := otel.Tracer("LlmInteractionRepo").Start(ctx, "GetItinerariesByIDs", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.sql.table", "user_saved_itineraries"),
+		attribute.String("user.id", userID.String()),
+		attribute.Int("itinerary.count", len(itineraryIDs)),
+	))
+	defer span.End()
+
+	itineraries := make(map[uuid.UUID]*locitypes.UserSavedItinerary, len(itineraryIDs))
+	if len(itineraryIDs) == 0 {
+		return itineraries, nil
+	}
+	rows, err := r.pgpool.Query(ctx, itineraryQuery+" WHERE id = ANY($1) AND user_id = $2", itineraryIDs, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query user_saved_itineraries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		itinerary, err := scanItinerary(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan user_saved_itineraries row: %w", err)
+		}
+		itineraries[itinerary.ID] = itinerary
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error iterating user_saved_itineraries rows: %w", err)
+	}
+
+	return itineraries, nil
+}
+
+// itineraryQuery selects the columns scanned by scanItinerary; callers add the WHERE clause.
+const itineraryQuery = `
 		SELECT
 			id, user_id, source_llm_interaction_id, session_id, primary_city_id, title, description,
 			markdown_content, tags, estimated_duration_days, estimated_cost_level, is_public
-		FROM user_saved_itineraries
-		WHERE id = $1 AND user_id = $2
-	`
-	row := r.pgpool.QueryRow(ctx, query, itineraryID, userID)
+		FROM user_saved_itineraries`
 
+func scanItinerary(row pgx.Row) (*locitypes.UserSavedItinerary, error) {
 	var itinerary locitypes.UserSavedItinerary
 	if err := row.Scan(
 		&itinerary.ID,
@@ -1353,15 +1635,8 @@ func (r *RepositoryImpl) GetItinerary(ctx context.Context, userID, itineraryID u
 		&itinerary.EstimatedCostLevel,
 		&itinerary.IsPublic,
 	); err != nil {
-		if err == pgx.ErrNoRows {
-			err = fmt.Errorf("no itinerary found with ID %s for user %s", itineraryID, userID)
-			span.RecordError(err)
-			return nil, err
-		}
-		span.RecordError(err)
-		return nil, fmt.Errorf("failed to scan user_saved_itineraries row: %w", err)
+		return nil, err
 	}
-
 	return &itinerary, nil
 }
 
@@ -2533,13 +2808,13 @@ func (r *RepositoryImpl) SaveLlmInteraction(ctx context.Context, interaction *lo
 	l := r.logger.With(slog.String("method", "SaveLlmInteraction"))
 
 	query := `
-		INSERT INTO llm_interactions (user_id, model_name, prompt, response, latitude, longitude, distance)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO llm_interactions (user_id, model_name, prompt, response, latitude, longitude, distance, provider)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'google'))
 		RETURNING id
 	`
 
 	var id uuid.UUID
-	err := r.pgpool.QueryRow(ctx, query, interaction.UserID, interaction.ModelName, interaction.Prompt, interaction.Response, interaction.Latitude, interaction.Longitude, interaction.Distance).Scan(&id)
+	err := r.pgpool.QueryRow(ctx, query, interaction.UserID, interaction.ModelName, interaction.Prompt, interaction.Response, interaction.Latitude, interaction.Longitude, interaction.Distance, interaction.Provider).Scan(&id)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to save LLM interaction", slog.Any("error", err))
 		span.RecordError(err)
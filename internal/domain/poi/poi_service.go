@@ -2,11 +2,8 @@ package poi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -34,9 +31,10 @@ type Service interface {
 	GetFavouritePOIsByUserID(ctx context.Context, userID uuid.UUID) ([]locitypes.POIDetailedInfo, error)
 	GetFavouritePOIsByUserIDPaginated(ctx context.Context, userID uuid.UUID, limit, offset int) ([]locitypes.POIDetailedInfo, int, error)
 	GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]locitypes.POIDetailedInfo, error)
+	GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error)
 
 	// SearchPOIs Traditional search
-	SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error)
+	SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error)
 
 	// SearchPOIsSemantic Semantic search methods
 	SearchPOIsSemantic(ctx context.Context, query string, limit int) ([]locitypes.POIDetailedInfo, error)
@@ -78,6 +76,7 @@ type ServiceImpl struct {
 
 func NewServiceImpl(
 	poiRepository Repository,
+	aiClient llm.ChatClient,
 	embeddingService llm.EmbeddingClient,
 	cityRepo city.Repository,
 	discoverRepo interface {
@@ -85,22 +84,6 @@ func NewServiceImpl(
 	},
 	logger *slog.Logger,
 ) *ServiceImpl {
-	ctx := context.Background()
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	aiClient, err := llm.NewGeminiChatClient(ctx, apiKey)
-	if err != nil {
-		logger.Error("Failed to initialize AI client", slog.Any("error", err))
-		// For now, set to nil and handle gracefully in methods
-		aiClient = nil
-	}
-
-	if embeddingService == nil {
-		embeddingService, err = llm.NewGeminiEmbeddingClient(ctx, logger)
-		if err != nil {
-			logger.Error("Failed to initialize embedding client", slog.Any("error", err))
-		}
-	}
-
 	return &ServiceImpl{
 		logger:           logger,
 		poiRepository:    poiRepository,
@@ -176,13 +159,23 @@ func (s *ServiceImpl) GetPOIsByCityID(ctx context.Context, cityID uuid.UUID) ([]
 	return pois, nil
 }
 
-func (s *ServiceImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error) {
-	pois, err := s.poiRepository.SearchPOIs(ctx, filter)
+func (s *ServiceImpl) GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error) {
+	poi, err := s.poiRepository.GetPOIByID(ctx, poiID)
 	if err != nil {
-		s.logger.Error("failed to search POIs", "error", err)
+		s.logger.Error("failed to get POI by ID", "error", err)
 		return nil, err
 	}
-	return pois, nil
+	return poi, nil
+}
+
+// SearchPOIs returns a page of the POIs matching filter and the total number of matches.
+func (s *ServiceImpl) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error) {
+	pois, total, err := s.poiRepository.SearchPOIs(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to search POIs", "error", err)
+		return nil, 0, err
+	}
+	return pois, total, nil
 }
 
 func (s *ServiceImpl) GetItinerary(ctx context.Context, userID, itineraryID uuid.UUID) (*locitypes.UserSavedItinerary, error) {
@@ -478,6 +471,22 @@ func (s *ServiceImpl) SearchPOIsByQueryAndCity(ctx context.Context, query, cityN
 	return pois, nil
 }
 
+// discoverSearchResponse is the LLM reply of the discover search prompt.
+type discoverSearchResponse struct {
+	Results []struct {
+		Name        string  `json:"name" llm:"required"`
+		Latitude    float64 `json:"latitude" llm:"required,min=-90,max=90"`
+		Longitude   float64 `json:"longitude" llm:"required,min=-180,max=180"`
+		Category    string  `json:"category" llm:"required"`
+		Description string  `json:"description"`
+		Address     string  `json:"address"`
+		Rating      float64 `json:"rating" llm:"min=0,max=5"`
+		PriceLevel  string  `json:"price_level" llm:"enum=$|$$|$$$|$$$$"`
+		Website     *string `json:"website,omitempty"`
+		PhoneNumber *string `json:"phone_number,omitempty"`
+	} `json:"results" llm:"required"`
+}
+
 // generatePOIsWithLLM generates POIs using LLM when database search returns no results
 func (s *ServiceImpl) generatePOIsWithLLM(ctx context.Context, query, cityName string) ([]locitypes.POIDetailedInfo, error) {
 	ctx, span := otel.Tracer("POIService").Start(ctx, "generatePOIsWithLLM", trace.WithAttributes(
@@ -535,9 +544,9 @@ Generate 5-10 relevant results.`, query, cityName)
 		slog.String("city", cityName))
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, &genai.GenerateContentConfig{
+	result, err := llm.GenerateStructured[discoverSearchResponse](ctx, s.aiClient, prompt, &genai.GenerateContentConfig{
 		Temperature: genai.Ptr[float32](0.7),
-	})
+	}, llm.StructuredOptions{Name: "discover_search", Logger: s.logger})
 	latencyMs := time.Since(startTime).Milliseconds()
 
 	if err != nil {
@@ -546,62 +555,12 @@ Generate 5-10 relevant results.`, query, cityName)
 		span.SetStatus(codes.Error, "LLM request failed")
 		return nil, fmt.Errorf("LLM request failed: %w", err)
 	}
-
-	if response == nil || len(response.Candidates) == 0 {
-		err := fmt.Errorf("empty LLM response")
-		l.ErrorContext(ctx, "Empty LLM response")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty LLM response")
-		return nil, err
-	}
-
-	// Extract text from response
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-
-	// Clean the LLM response (remove markdown, trim, fix JSON issues)
-	responseStr := cleanLLMResponse(txt)
+	searchResponse := result.Value
 
 	l.DebugContext(ctx, "LLM response received",
 		slog.Int64("latency_ms", latencyMs),
-		slog.Int("response_length", len(responseStr)),
-		slog.String("response_preview", responseStr[:minInt(200, len(responseStr))]))
-
-	// Parse JSON response
-	var searchResponse struct {
-		Results []struct {
-			Name        string  `json:"name"`
-			Latitude    float64 `json:"latitude"`
-			Longitude   float64 `json:"longitude"`
-			Category    string  `json:"category"`
-			Description string  `json:"description"`
-			Address     string  `json:"address"`
-			Rating      float64 `json:"rating"`
-			PriceLevel  string  `json:"price_level"`
-			Website     *string `json:"website,omitempty"`
-			PhoneNumber *string `json:"phone_number,omitempty"`
-		} `json:"results"`
-	}
-
-	if err := json.Unmarshal([]byte(responseStr), &searchResponse); err != nil {
-		// Log the full response for debugging
-		responsePreview := responseStr
-		if len(responseStr) > 1000 {
-			responsePreview = responseStr[:1000] + "... (truncated)"
-		}
-		l.ErrorContext(ctx, "Failed to parse LLM JSON response",
-			slog.Any("error", err),
-			slog.String("response", responsePreview),
-			slog.Int("response_length", len(responseStr)))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse LLM response")
-		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
-	}
+		slog.Int("attempts", result.Attempts),
+		slog.Int("response_length", len(result.JSON)))
 
 	// Convert to POIDetailedInfo
 	pois := make([]locitypes.POIDetailedInfo, len(searchResponse.Results))
@@ -894,6 +853,7 @@ func (s *ServiceImpl) GetGeneralPOIByDistance(ctx context.Context, userID uuid.U
 	s.logger.InfoContext(ctx, "No POIs found in database, falling back to LLM generation")
 	span.AddEvent("database_miss_fallback_to_llm")
 
+	ctx = llm.WithServedBackend(ctx)
 	genAIResponse, err := s.generatePOIsFromLLM(ctx, userID, lat, lon, distance)
 	if err != nil {
 		span.RecordError(err)
@@ -909,6 +869,7 @@ func (s *ServiceImpl) GetGeneralPOIByDistance(ctx context.Context, userID uuid.U
 		interaction := &locitypes.LlmInteraction{
 			UserID:    userID,
 			ModelName: genAIResponse.ModelName,
+			Provider:  llm.ProviderName(ctx, s.aiClient),
 			Prompt:    genAIResponse.Prompt,
 			Response:  genAIResponse.Response,
 			Latitude:  &lat,
@@ -965,6 +926,7 @@ func (s *ServiceImpl) getGeneralPOIByDistance(ctx context.Context, wg *sync.Wait
 
 	defer span.End()
 	defer wg.Done()
+	ctx = llm.WithServedBackend(ctx)
 
 	prompt := getGeneralPOIByDistance(lat, lon, distance)
 	span.SetAttributes(attribute.Int("prompt.length", len(prompt)))
@@ -978,7 +940,8 @@ func (s *ServiceImpl) getGeneralPOIByDistance(ctx context.Context, wg *sync.Wait
 	}
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, s.aiClient, prompt, config,
+		llm.StructuredOptions{Name: "general_pois", Logger: s.logger})
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -988,41 +951,18 @@ func (s *ServiceImpl) getGeneralPOIByDistance(ctx context.Context, wg *sync.Wait
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := cleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
-
-	fmt.Println(cleanTxt)
+	cleanTxt := result.JSON
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(cleanTxt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
 	resultCh <- locitypes.GenAIResponse{
 		GeneralPOI: poiData.PointsOfInterest,
-		ModelName:  s.aiClient.Model(),
+		ModelName:  llm.ModelName(ctx, s.aiClient),
 		Prompt:     prompt,
 		Response:   cleanTxt,
 	}
@@ -1413,6 +1353,7 @@ func (s *ServiceImpl) getGeneralRestaurantByDistance(ctx context.Context, wg *sy
 
 	defer span.End()
 	defer wg.Done()
+	ctx = llm.WithServedBackend(ctx)
 
 	userLocation := locitypes.UserLocation{
 		UserLat:        lat,
@@ -1431,7 +1372,8 @@ func (s *ServiceImpl) getGeneralRestaurantByDistance(ctx context.Context, wg *sy
 	}
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, s.aiClient, prompt, config,
+		llm.StructuredOptions{Name: "nearby_restaurants", Logger: s.logger})
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -1441,41 +1383,18 @@ func (s *ServiceImpl) getGeneralRestaurantByDistance(ctx context.Context, wg *sy
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := cleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
-
-	fmt.Println(cleanTxt)
+	cleanTxt := result.JSON
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(cleanTxt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
 	resultCh <- locitypes.GenAIResponse{
 		GeneralPOI: poiData.PointsOfInterest,
-		ModelName:  s.aiClient.Model(),
+		ModelName:  llm.ModelName(ctx, s.aiClient),
 		Prompt:     prompt,
 		Response:   cleanTxt,
 	}
@@ -1513,6 +1432,7 @@ func (s *ServiceImpl) getGeneralActivitiesByDistance(ctx context.Context, wg *sy
 
 	defer span.End()
 	defer wg.Done()
+	ctx = llm.WithServedBackend(ctx)
 
 	userLocation := locitypes.UserLocation{
 		UserLat:        lat,
@@ -1531,7 +1451,8 @@ func (s *ServiceImpl) getGeneralActivitiesByDistance(ctx context.Context, wg *sy
 	}
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, s.aiClient, prompt, config,
+		llm.StructuredOptions{Name: "nearby_activities", Logger: s.logger})
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -1541,41 +1462,18 @@ func (s *ServiceImpl) getGeneralActivitiesByDistance(ctx context.Context, wg *sy
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := cleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
-
-	fmt.Println(cleanTxt)
+	cleanTxt := result.JSON
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(cleanTxt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
 	resultCh <- locitypes.GenAIResponse{
 		GeneralPOI: poiData.PointsOfInterest,
-		ModelName:  s.aiClient.Model(),
+		ModelName:  llm.ModelName(ctx, s.aiClient),
 		Prompt:     prompt,
 		Response:   cleanTxt,
 	}
@@ -1613,6 +1511,7 @@ func (s *ServiceImpl) getGeneralHotelsByDistance(ctx context.Context, wg *sync.W
 
 	defer span.End()
 	defer wg.Done()
+	ctx = llm.WithServedBackend(ctx)
 
 	userLocation := locitypes.UserLocation{
 		UserLat:        lat,
@@ -1631,7 +1530,8 @@ func (s *ServiceImpl) getGeneralHotelsByDistance(ctx context.Context, wg *sync.W
 	}
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, s.aiClient, prompt, config,
+		llm.StructuredOptions{Name: "nearby_hotels", Logger: s.logger})
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -1641,41 +1541,18 @@ func (s *ServiceImpl) getGeneralHotelsByDistance(ctx context.Context, wg *sync.W
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := cleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
-
-	fmt.Println(cleanTxt)
+	cleanTxt := result.JSON
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(cleanTxt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
 	resultCh <- locitypes.GenAIResponse{
 		GeneralPOI: poiData.PointsOfInterest,
-		ModelName:  s.aiClient.Model(),
+		ModelName:  llm.ModelName(ctx, s.aiClient),
 		Prompt:     prompt,
 		Response:   cleanTxt,
 	}
@@ -1713,6 +1590,7 @@ func (s *ServiceImpl) getGeneralAttractionsByDistance(ctx context.Context, wg *s
 
 	defer span.End()
 	defer wg.Done()
+	ctx = llm.WithServedBackend(ctx)
 
 	userLocation := locitypes.UserLocation{
 		UserLat:        lat,
@@ -1731,7 +1609,8 @@ func (s *ServiceImpl) getGeneralAttractionsByDistance(ctx context.Context, wg *s
 	}
 
 	startTime := time.Now()
-	response, err := s.aiClient.GenerateResponse(ctx, prompt, config)
+	result, err := llm.GenerateStructured[locitypes.POIListResponse](ctx, s.aiClient, prompt, config,
+		llm.StructuredOptions{Name: "nearby_attractions", Logger: s.logger})
 	latencyMs := int(time.Since(startTime).Milliseconds())
 	span.SetAttributes(attribute.Int("response.latency_ms", latencyMs))
 
@@ -1741,117 +1620,19 @@ func (s *ServiceImpl) getGeneralAttractionsByDistance(ctx context.Context, wg *s
 		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to generate general POIs: %w", err)}
 		return
 	}
-
-	var txt string
-	for _, candidate := range response.Candidates {
-		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-			txt = candidate.Content.Parts[0].Text
-			break
-		}
-	}
-	if txt == "" {
-		err := fmt.Errorf("no valid general POI content from AI")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Empty response from AI")
-		resultCh <- locitypes.GenAIResponse{Err: err}
-		return
-	}
-	span.SetAttributes(attribute.Int("response.length", len(txt)))
-
-	cleanTxt := cleanJSONResponse(txt)
-	var poiData struct {
-		PointsOfInterest []locitypes.POIDetailedInfo `json:"points_of_interest"`
-	}
-	if err := json.Unmarshal([]byte(cleanTxt), &poiData); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to parse general POI JSON")
-		resultCh <- locitypes.GenAIResponse{Err: fmt.Errorf("failed to parse general POI JSON: %w", err)}
-		return
-	}
-
-	fmt.Println(cleanTxt)
+	cleanTxt := result.JSON
+	poiData := result.Value
+	span.SetAttributes(
+		attribute.Int("response.length", len(cleanTxt)),
+		attribute.Int("response.attempts", result.Attempts),
+	)
 
 	span.SetAttributes(attribute.Int("pois.count", len(poiData.PointsOfInterest)))
 	span.SetStatus(codes.Ok, "General POIs generated successfully")
 	resultCh <- locitypes.GenAIResponse{
 		GeneralPOI: poiData.PointsOfInterest,
-		ModelName:  s.aiClient.Model(),
+		ModelName:  llm.ModelName(ctx, s.aiClient),
 		Prompt:     prompt,
 		Response:   cleanTxt,
 	}
 }
-
-func cleanLLMResponse(responseText string) string {
-	cleaned := strings.TrimSpace(responseText)
-
-	if strings.HasPrefix(cleaned, "```json") {
-		cleaned = strings.TrimPrefix(cleaned, "```json")
-		cleaned = strings.TrimSuffix(cleaned, "```")
-		cleaned = strings.TrimSpace(cleaned)
-	} else if strings.HasPrefix(cleaned, "```") {
-		cleaned = strings.TrimPrefix(cleaned, "```")
-		cleaned = strings.TrimSuffix(cleaned, "```")
-		cleaned = strings.TrimSpace(cleaned)
-	}
-
-	cleaned = regexp.MustCompile(`,(\s*[}\\]])`).ReplaceAllString(cleaned, "$1")
-	return cleaned
-}
-
-func cleanJSONResponse(response string) string {
-	response = strings.TrimSpace(response)
-
-	if strings.HasPrefix(response, "```json") {
-		response = strings.TrimPrefix(response, "```json")
-	} else if strings.HasPrefix(response, "```") {
-		response = strings.TrimPrefix(response, "```")
-	}
-
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
-
-	firstBrace := strings.Index(response, "{")
-	if firstBrace == -1 {
-		return response
-	}
-
-	braceCount := 0
-	lastValidBrace := -1
-	for i := firstBrace; i < len(response); i++ {
-		switch response[i] {
-		case '{':
-			braceCount++
-		case '}':
-			braceCount--
-			if braceCount == 0 {
-				lastValidBrace = i
-				break
-			}
-		}
-	}
-
-	if braceCount != 0 {
-		lastBrace := strings.LastIndex(response, "}")
-		if lastBrace == -1 || lastBrace <= firstBrace {
-			return response
-		}
-		lastValidBrace = lastBrace
-	}
-
-	if lastValidBrace == -1 {
-		return response
-	}
-
-	jsonPortion := response[firstBrace : lastValidBrace+1]
-	jsonPortion = strings.ReplaceAll(jsonPortion, "`", "")
-	jsonPortion = regexp.MustCompile(`,(\s*[}\\]])`).ReplaceAllString(jsonPortion, "$1")
-
-	return strings.TrimSpace(jsonPortion)
-}
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
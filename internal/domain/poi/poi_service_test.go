@@ -203,6 +203,14 @@ func (m *MockPOIRepository) GetPOIsByCityID(ctx context.Context, cityID uuid.UUI
 	return args.Get(0).([]locitypes.POIDetailedInfo), args.Error(1)
 }
 
+func (m *MockPOIRepository) GetPOIByID(ctx context.Context, poiID uuid.UUID) (*locitypes.POIDetailedInfo, error) {
+	args := m.Called(ctx, poiID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*locitypes.POIDetailedInfo), args.Error(1)
+}
+
 func (m *MockPOIRepository) FindPOIDetails(ctx context.Context, cityID uuid.UUID, lat, lon, tolerance float64) (*locitypes.POIDetailedInfo, error) {
 	args := m.Called(ctx, cityID, lat, lon, tolerance)
 	if args.Get(0) == nil {
@@ -216,12 +224,12 @@ func (m *MockPOIRepository) SavePOIDetails(ctx context.Context, poi locitypes.PO
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, error) {
+func (m *MockPOIRepository) SearchPOIs(ctx context.Context, filter locitypes.POIFilter) ([]locitypes.POIDetailedInfo, int, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.Int(1), args.Error(2)
 	}
-	return args.Get(0).([]locitypes.POIDetailedInfo), args.Error(1)
+	return args.Get(0).([]locitypes.POIDetailedInfo), args.Int(1), args.Error(2)
 }
 
 func (m *MockPOIRepository) FindSimilarPOIs(ctx context.Context, queryEmbedding []float32, limit int) ([]locitypes.POIDetailedInfo, error) {
@@ -311,6 +319,38 @@ func (m *MockPOIRepository) GetItinerary(ctx context.Context, userID, itineraryI
 	return args.Get(0).(*locitypes.UserSavedItinerary), args.Error(1)
 }
 
+func (m *MockPOIRepository) GetPOIsByIDs(ctx context.Context, poiIDs []uuid.UUID) (map[uuid.UUID]*locitypes.POIDetailedInfo, error) {
+	args := m.Called(ctx, poiIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.POIDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetHotelsByIDs(ctx context.Context, hotelIDs []uuid.UUID) (map[uuid.UUID]*locitypes.HotelDetailedInfo, error) {
+	args := m.Called(ctx, hotelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.HotelDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetRestaurantsByIDs(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*locitypes.RestaurantDetailedInfo, error) {
+	args := m.Called(ctx, restaurantIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.RestaurantDetailedInfo), args.Error(1)
+}
+
+func (m *MockPOIRepository) GetItinerariesByIDs(ctx context.Context, userID uuid.UUID, itineraryIDs []uuid.UUID) (map[uuid.UUID]*locitypes.UserSavedItinerary, error) {
+	args := m.Called(ctx, userID, itineraryIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*locitypes.UserSavedItinerary), args.Error(1)
+}
+
 func (m *MockPOIRepository) GetItineraries(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]locitypes.UserSavedItinerary, int, error) {
 	args := m.Called(ctx, userID, page, pageSize)
 	if args.Get(0) == nil {
@@ -396,7 +436,7 @@ func setupPOIServiceTest() (*ServiceImpl, *MockPOIRepository, *MockCityRepositor
 	mockRepo := new(MockPOIRepository)
 	mockCityRepo := new(MockCityRepository)
 	embeddingService := stubEmbeddingClient{}
-	service := NewServiceImpl(mockRepo, embeddingService, mockCityRepo, stubDiscoverRepo{}, logger)
+	service := NewServiceImpl(mockRepo, nil, embeddingService, mockCityRepo, stubDiscoverRepo{}, logger)
 	return service, mockRepo, mockCityRepo
 }
 
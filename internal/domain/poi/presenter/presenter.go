@@ -0,0 +1,169 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+	poiv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+const defaultRadiusKm = 5.0
+
+// SearchParams is the domain-side view of a SearchPOIRequest.
+type SearchParams struct {
+	Query      string
+	CityName   string
+	Latitude   float64
+	Longitude  float64
+	RadiusKm   float64
+	SearchType string
+	SearchTags []string
+	SortBy     string
+	SortOrder  string
+	MinRating  float64
+	// MinPriceLevel is the lowest price level, from locitypes.ParsePriceLevel, a
+	// result may have; 0 leaves prices unbounded.
+	MinPriceLevel int
+	HasLocation   bool
+}
+
+// FromSearchRequest normalises a SearchPOIRequest, falling back to search_text when query is empty.
+// It fails when min_price is not a price level.
+func FromSearchRequest(req *poiv1.SearchPOIRequest) (SearchParams, error) {
+	params := SearchParams{
+		Query:      req.GetQuery(),
+		CityName:   req.GetCityName(),
+		Latitude:   req.GetLatitude(),
+		Longitude:  req.GetLongitude(),
+		RadiusKm:   defaultRadiusKm,
+		SearchType: req.GetSearchType(),
+		SearchTags: req.GetSearchTags(),
+		SortBy:     req.GetSortBy(),
+		SortOrder:  req.GetSortOrder(),
+		MinRating:  req.GetMinRating(),
+	}
+	if req.MinPrice != nil {
+		level, ok := locitypes.ParsePriceLevel(req.GetMinPrice())
+		if !ok {
+			return SearchParams{}, fmt.Errorf("min_price %q is not a price level such as €€ or 2", req.GetMinPrice())
+		}
+		params.MinPriceLevel = level
+	}
+	if params.Query == "" {
+		params.Query = req.GetSearchText()
+	}
+	if req.GetRadiusKm() > 0 {
+		params.RadiusKm = req.GetRadiusKm()
+	}
+	params.HasLocation = params.Latitude != 0 || params.Longitude != 0
+	return params, nil
+}
+
+// ToPOIFilter builds the spatial filter used by the repository search methods.
+func (p SearchParams) ToPOIFilter() locitypes.POIFilter {
+	return locitypes.POIFilter{
+		Location: locitypes.GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude},
+		Radius:   p.RadiusKm,
+	}
+}
+
+// ToSearchFilter builds the filter for SearchPOIs, which applies the rating, price, tag
+// and sort options in SQL and returns the first limit matches.
+func (p SearchParams) ToSearchFilter(limit int) locitypes.POIFilter {
+	filter := p.ToPOIFilter()
+	filter.MinRating = p.MinRating
+	filter.MinPriceLevel = p.MinPriceLevel
+	filter.Tags = p.SearchTags
+	filter.SortBy = p.SortBy
+	filter.SortAscending = !strings.EqualFold(p.SortOrder, "desc")
+	filter.Limit = limit
+	return filter
+}
+
+func ToPOIDetailedInfoSlice(pois []locitypes.POIDetailedInfo) []*poiv1.POIDetailedInfo {
+	if pois == nil {
+		return nil
+	}
+	result := make([]*poiv1.POIDetailedInfo, len(pois))
+	for i, p := range pois {
+		result[i] = ToPOIDetailedInfo(p)
+	}
+	return result
+}
+
+func ToPOIDetailedInfo(poi locitypes.POIDetailedInfo) *poiv1.POIDetailedInfo {
+	resp := &poiv1.POIDetailedInfo{
+		Id:               poi.ID.String(),
+		City:             poi.City,
+		CityId:           poi.CityID.String(),
+		Name:             poi.Name,
+		Distance:         poi.Distance,
+		Category:         poi.Category,
+		Description:      poi.Description,
+		Rating:           poi.Rating,
+		Address:          poi.Address,
+		PhoneNumber:      poi.PhoneNumber,
+		Website:          poi.Website,
+		OpeningHours:     poi.OpeningHours,
+		Images:           poi.Images,
+		PriceRange:       poi.PriceRange,
+		PriceLevel:       poi.PriceLevel,
+		Reviews:          poi.Reviews,
+		LlmInteractionId: poi.LlmInteractionID.String(),
+		Tags:             poi.Tags,
+		Amenities:        poi.Amenities,
+	}
+
+	if poi.DescriptionPOI != "" {
+		resp.DescriptionPoi = proto.String(poi.DescriptionPOI)
+	}
+	if poi.Latitude != 0 {
+		resp.Latitude = proto.Float64(poi.Latitude)
+	}
+	if poi.Longitude != 0 {
+		resp.Longitude = proto.Float64(poi.Longitude)
+	}
+	if poi.Priority != 0 {
+		priority := int32(poi.Priority)
+		resp.Priority = &priority
+	}
+	if !poi.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(poi.CreatedAt)
+	}
+	if poi.CuisineType != "" {
+		resp.CuisineType = proto.String(poi.CuisineType)
+	}
+	if poi.StarRating != "" {
+		resp.StarRating = proto.String(poi.StarRating)
+	}
+	if poi.Source != "" {
+		resp.Source = proto.String(poi.Source)
+	}
+
+	return resp
+}
+
+// ToSearchPOIResponse wraps the first page of search results. SearchPOIRequest carries no
+// page, so the page is always 1; total is the number of matches across all pages.
+func ToSearchPOIResponse(pois []locitypes.POIDetailedInfo, total, pageSize int) *poiv1.SearchPOIResponse {
+	totalPages := 0
+	if total > 0 && pageSize > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &poiv1.SearchPOIResponse{
+		Pois: ToPOIDetailedInfoSlice(pois),
+		Pagination: &commonpb.PaginationMetadata{
+			TotalRecords: int32(total),
+			Page:         1,
+			PageSize:     int32(pageSize),
+			TotalPages:   int32(totalPages),
+			HasMore:      total > len(pois),
+		},
+	}
+}
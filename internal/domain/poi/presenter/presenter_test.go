@@ -0,0 +1,35 @@
+package presenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	poiv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/poi"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestFromSearchRequest(t *testing.T) {
+	params, err := FromSearchRequest(&poiv1.SearchPOIRequest{
+		CityName:   "Lisbon",
+		SearchText: ptr("pastéis"),
+		MinRating:  ptr(4.0),
+		MinPrice:   ptr("€€"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "pastéis", params.Query)
+	require.Equal(t, defaultRadiusKm, params.RadiusKm)
+	require.Equal(t, 4.0, params.MinRating)
+	require.Equal(t, 2, params.MinPriceLevel)
+	require.False(t, params.HasLocation)
+
+	params, err = FromSearchRequest(&poiv1.SearchPOIRequest{Query: "museum", Latitude: 38.7, RadiusKm: ptr(2.0)})
+	require.NoError(t, err)
+	require.Equal(t, 2.0, params.RadiusKm)
+	require.Zero(t, params.MinPriceLevel)
+	require.True(t, params.HasLocation)
+
+	_, err = FromSearchRequest(&poiv1.SearchPOIRequest{Query: "museum", MinPrice: ptr("cheap-ish")})
+	require.Error(t, err)
+}
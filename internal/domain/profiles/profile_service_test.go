@@ -100,6 +100,32 @@ func (m *MockinterestsRepo) GetInterestsForProfile(ctx context.Context, profileI
 	return args.Get(0).([]*locitypes.Interest), args.Error(1)
 }
 
+func (m *MockinterestsRepo) GetAvailableInterests(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetUserInterests(ctx context.Context, userID uuid.UUID) ([]*locitypes.Interest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*locitypes.Interest), args.Error(1)
+}
+
+func (m *MockinterestsRepo) GetDefaultProfileID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockinterestsRepo) UpdatePreferenceLevel(ctx context.Context, profileID uuid.UUID, interestIDs []uuid.UUID, level int) (int64, error) {
+	args := m.Called(ctx, profileID, interestIDs, level)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockinterestsRepo) GetInterest(ctx context.Context, interestID uuid.UUID) (*locitypes.Interest, error) {
 	args := m.Called(ctx, interestID)
 	if args.Get(0) == nil {
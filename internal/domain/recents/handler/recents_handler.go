@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+
+	recentsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1/recentsv1connect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/recents"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/recents/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/statistics"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const cityDetailLimit = 50
+
+// RecentsHandler implements the RecentsServiceHandler interface.
+type RecentsHandler struct {
+	recentsv1connect.UnimplementedRecentsServiceHandler
+	service recents.Service
+	logger  *slog.Logger
+}
+
+// NewRecentsHandler creates a new RecentsHandler.
+func NewRecentsHandler(svc recents.Service, logger *slog.Logger) *RecentsHandler {
+	return &RecentsHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// GetRecentInteractions returns the caller's latest POI interactions, optionally grouped by city.
+func (h *RecentsHandler) GetRecentInteractions(
+	ctx context.Context,
+	req *connect.Request[recentsv1.GetRecentInteractionsRequest],
+) (*connect.Response[recentsv1.GetRecentInteractionsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	filter, err := presenter.FromFilter(req.Msg.GetFilter())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	filter.Limit = int(req.Msg.GetLimit())
+	filter.Offset = int(req.Msg.GetOffset())
+
+	interactions, total, err := h.service.GetInteractionHistory(ctx, userID, filter)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	analytics, _, err := h.service.GetInteractionAnalytics(ctx, userID, filter)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	resp := &recentsv1.GetRecentInteractionsResponse{
+		Interactions: presenter.ToProtoInteractions(interactions),
+		TotalCount:   int32(total),
+		Analytics:    presenter.ToProtoAnalytics(analytics),
+	}
+	if req.Msg.GetGroupByCity() {
+		resp.CitySummaries = presenter.ToProtoCitySummaries(interactions)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// GetCityInteractions summarises the caller's activity in one city.
+func (h *RecentsHandler) GetCityInteractions(
+	ctx context.Context,
+	req *connect.Request[recentsv1.GetCityInteractionsRequest],
+) (*connect.Response[recentsv1.GetCityInteractionsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end *time.Time
+	if req.Msg.GetStartDate() != nil {
+		t := req.Msg.GetStartDate().AsTime()
+		start = &t
+	}
+	if req.Msg.GetEndDate() != nil {
+		t := req.Msg.GetEndDate().AsTime()
+		end = &t
+	}
+
+	activity, err := h.service.GetCityActivity(ctx, userID, req.Msg.GetCityName(), start, end)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	resp := &recentsv1.GetCityInteractionsResponse{
+		CityInteractions: presenter.ToProtoCityInteractions(activity),
+		Insights:         presenter.ToProtoCityInsights(activity),
+	}
+	if req.Msg.GetIncludeDetails() {
+		interactions, _, err := h.service.GetInteractionHistory(ctx, userID, locitypes.POIInteractionFilter{
+			CityName:  req.Msg.GetCityName(),
+			StartDate: start,
+			EndDate:   end,
+			Limit:     cityDetailLimit,
+		})
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+		resp.DetailedInteractions = presenter.ToProtoInteractions(interactions)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// RecordInteraction queues a POI interaction for the caller. The event is written in the
+// next batch, so it may take a moment to show up in the history.
+func (h *RecentsHandler) RecordInteraction(
+	ctx context.Context,
+	req *connect.Request[recentsv1.RecordInteractionRequest],
+) (*connect.Response[recentsv1.RecordInteractionResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	interaction, err := presenter.FromRecordRequest(userID, req.Msg)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	id, err := h.service.RecordInteraction(ctx, interaction)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&recentsv1.RecordInteractionResponse{
+		Success:       true,
+		InteractionId: id.String(),
+		Message:       "interaction recorded",
+	}), nil
+}
+
+// GetInteractionHistory returns a filtered, sorted page of the caller's POI interactions.
+func (h *RecentsHandler) GetInteractionHistory(
+	ctx context.Context,
+	req *connect.Request[recentsv1.GetInteractionHistoryRequest],
+) (*connect.Response[recentsv1.GetInteractionHistoryResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	filter, err := presenter.FromFilter(req.Msg.GetFilter())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	filter.Limit = int(req.Msg.GetLimit())
+	filter.Offset = int(req.Msg.GetOffset())
+	switch req.Msg.GetSortBy() {
+	case "", "timestamp", "created_at":
+		filter.SortBy = "timestamp"
+	case "entity_name":
+		filter.SortBy = "poi_name"
+	case "city_name":
+		filter.SortBy = "city_name"
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("sort_by must be one of created_at, entity_name, city_name"))
+	}
+	filter.SortAscending = req.Msg.GetSortOrder() == "asc"
+
+	interactions, total, err := h.service.GetInteractionHistory(ctx, userID, filter)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	resp := &recentsv1.GetInteractionHistoryResponse{
+		Interactions: presenter.ToProtoInteractions(interactions),
+		TotalCount:   int32(total),
+	}
+	if req.Msg.GetIncludeAnalytics() {
+		analytics, trends, err := h.service.GetInteractionAnalytics(ctx, userID, filter)
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+		resp.Analytics = presenter.ToProtoAnalytics(analytics)
+		resp.Trends = presenter.ToProtoTrends(trends)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// GetFrequentPlaces returns the places the caller keeps coming back to. time_range
+// accepts the statistics ranges ("7d", "30d", ...) or "all", the default.
+func (h *RecentsHandler) GetFrequentPlaces(
+	ctx context.Context,
+	req *connect.Request[recentsv1.GetFrequentPlacesRequest],
+) (*connect.Response[recentsv1.GetFrequentPlacesResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	var since *time.Time
+	if tr := req.Msg.GetTimeRange(); tr != "" && tr != "all" {
+		_, window, err := statistics.ParseTimeRange(tr)
+		if err != nil {
+			return nil, h.toConnectError(err)
+		}
+		t := time.Now().Add(-window)
+		since = &t
+	}
+
+	places, err := h.service.GetFrequentPlaces(ctx, userID, since, req.Msg.GetPlaceTypes(), int(req.Msg.GetLimit()))
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	kept := places[:0]
+	for _, p := range places {
+		if p.Score >= req.Msg.GetMinFrequencyScore() {
+			kept = append(kept, p)
+		}
+	}
+
+	resp := &recentsv1.GetFrequentPlacesResponse{
+		Insights: presenter.ToProtoFrequentPlaceInsights(kept),
+	}
+	for i := range kept {
+		resp.Places = append(resp.Places, presenter.ToProtoFrequentPlace(&kept[i]))
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (h *RecentsHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrUnauthenticated):
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("recents request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	recentsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/recents"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	recents.Service
+	recorded     locitypes.POIInteraction
+	lastFilter   locitypes.POIInteractionFilter
+	lastSince    *time.Time
+	interactions []locitypes.POIInteraction
+	places       []locitypes.FrequentPlace
+}
+
+func (s *stubService) RecordInteraction(_ context.Context, interaction locitypes.POIInteraction) (uuid.UUID, error) {
+	s.recorded = interaction
+	return uuid.New(), nil
+}
+
+func (s *stubService) GetInteractionHistory(_ context.Context, _ uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.POIInteraction, int, error) {
+	s.lastFilter = filter
+	return s.interactions, len(s.interactions), nil
+}
+
+func (s *stubService) GetInteractionAnalytics(context.Context, uuid.UUID, locitypes.POIInteractionFilter) (*locitypes.InteractionAnalytics, []locitypes.InteractionTrendPoint, error) {
+	return &locitypes.InteractionAnalytics{MostActiveHour: 20}, nil, nil
+}
+
+func (s *stubService) GetFrequentPlaces(_ context.Context, _ uuid.UUID, since *time.Time, _ []string, _ int) ([]locitypes.FrequentPlace, error) {
+	s.lastSince = since
+	return s.places, nil
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func TestRecordInteraction_MapsContextAndMetadata(t *testing.T) {
+	svc := &stubService{}
+	h := NewRecentsHandler(svc, testutil.NewLogger())
+	userID := uuid.New()
+	cityID := uuid.New()
+
+	resp, err := h.RecordInteraction(authedContext(userID), connect.NewRequest(&recentsv1.RecordInteractionRequest{
+		InteractionType: recentsv1.InteractionType_INTERACTION_TYPE_RECOMMENDATION_CLICK,
+		EntityId:        "poi-42",
+		EntityName:      "LX Factory",
+		CityId:          cityID.String(),
+		Metadata:        map[string]string{"category": "market", "latitude": "38.703", "longitude": "-9.178"},
+		Context: &recentsv1.InteractionContext{
+			SessionId: "s-1",
+			Location:  &recentsv1.GeoLocation{Latitude: 38.71, Longitude: -9.14, City: "Lisbon"},
+		},
+	}))
+	require.NoError(t, err)
+	require.True(t, resp.Msg.GetSuccess())
+	require.NotEmpty(t, resp.Msg.GetInteractionId())
+
+	got := svc.recorded
+	require.Equal(t, userID, got.UserID)
+	require.Equal(t, locitypes.POIInteractionClick, got.InteractionType)
+	require.Equal(t, "market", got.POICategory)
+	require.Equal(t, "Lisbon", got.CityName)
+	require.Equal(t, cityID, *got.CityID)
+	require.InDelta(t, 38.703, *got.POILatitude, 1e-9)
+	require.InDelta(t, 38.71, *got.UserLatitude, 1e-9)
+	require.Equal(t, "s-1", got.SessionID)
+}
+
+func TestRecordInteraction_RejectsNonPOITypes(t *testing.T) {
+	h := NewRecentsHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.RecordInteraction(authedContext(uuid.New()), connect.NewRequest(&recentsv1.RecordInteractionRequest{
+		InteractionType: recentsv1.InteractionType_INTERACTION_TYPE_CHAT,
+		EntityId:        "session",
+	}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestRecordInteraction_RequiresAuthentication(t *testing.T) {
+	h := NewRecentsHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.RecordInteraction(context.Background(), connect.NewRequest(&recentsv1.RecordInteractionRequest{}))
+	require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func TestGetInteractionHistory_SortsAndIncludesAnalytics(t *testing.T) {
+	svc := &stubService{interactions: []locitypes.POIInteraction{{
+		ID: uuid.New(), POIID: "poi-1", POIName: "Oceanário", InteractionType: locitypes.POIInteractionFavorite, CityName: "Lisbon",
+	}}}
+	h := NewRecentsHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetInteractionHistory(authedContext(uuid.New()), connect.NewRequest(&recentsv1.GetInteractionHistoryRequest{
+		SortBy:           "entity_name",
+		SortOrder:        "asc",
+		Limit:            5,
+		IncludeAnalytics: true,
+		Filter: &recentsv1.InteractionFilter{
+			InteractionTypes: []recentsv1.InteractionType{recentsv1.InteractionType_INTERACTION_TYPE_FAVORITE},
+		},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "poi_name", svc.lastFilter.SortBy)
+	require.True(t, svc.lastFilter.SortAscending)
+	require.Equal(t, []locitypes.POIInteractionType{locitypes.POIInteractionFavorite}, svc.lastFilter.Types)
+	require.Equal(t, "Saved Oceanário to favourites", resp.Msg.GetInteractions()[0].GetDescription())
+	require.Equal(t, "evening", resp.Msg.GetAnalytics().GetMostActiveTimeOfDay())
+}
+
+func TestGetFrequentPlaces_FiltersByScore(t *testing.T) {
+	svc := &stubService{places: []locitypes.FrequentPlace{
+		{POIID: "a", Category: "cafe", CityName: "Porto", VisitDays: 12, InteractionCount: 20, Score: 4.2},
+		{POIID: "b", Category: "museum", CityName: "Porto", VisitDays: 1, InteractionCount: 1, Score: 0.1},
+	}}
+	h := NewRecentsHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetFrequentPlaces(authedContext(uuid.New()), connect.NewRequest(&recentsv1.GetFrequentPlacesRequest{
+		TimeRange:         "30d",
+		MinFrequencyScore: 1,
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, svc.lastSince)
+	require.Len(t, resp.Msg.GetPlaces(), 1)
+	require.Equal(t, "loyal", resp.Msg.GetInsights().GetLoyaltyLevel())
+	require.Equal(t, []string{"cafe"}, resp.Msg.GetInsights().GetFavoriteCategories())
+}
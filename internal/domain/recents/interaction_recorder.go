@@ -0,0 +1,155 @@
+package recents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+)
+
+const (
+	defaultRecorderBatchSize     = 100
+	defaultRecorderFlushInterval = 2 * time.Second
+	recorderFlushTimeout         = 10 * time.Second
+)
+
+var errRecorderClosed = errors.New("interaction recorder is closed")
+
+// InteractionRecorder buffers POI interactions and writes them in batches, so a burst of
+// view events from a scrolling client costs one COPY rather than one INSERT per event.
+// When the buffer is full the event is written synchronously instead of being dropped.
+// A batch whose insert fails is kept and retried on the next tick; while retrying,
+// at most maxPending events are kept and older ones are dropped.
+type InteractionRecorder struct {
+	repo          Repository
+	logger        *slog.Logger
+	batchSize     int
+	maxPending    int
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	events chan locitypes.POIInteraction
+	done   chan struct{}
+}
+
+// NewInteractionRecorder starts a recorder. Non-positive values fall back to the defaults.
+// Close must be called on shutdown to flush buffered events.
+func NewInteractionRecorder(repo Repository, logger *slog.Logger, batchSize int, flushInterval time.Duration) *InteractionRecorder {
+	if batchSize <= 0 {
+		batchSize = defaultRecorderBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultRecorderFlushInterval
+	}
+	r := &InteractionRecorder{
+		repo:          repo,
+		logger:        logger,
+		batchSize:     batchSize,
+		maxPending:    batchSize * 4,
+		flushInterval: flushInterval,
+		events:        make(chan locitypes.POIInteraction, batchSize*4),
+		done:          make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record queues an interaction for the next batch.
+func (r *InteractionRecorder) Record(ctx context.Context, interaction locitypes.POIInteraction) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return errRecorderClosed
+	}
+
+	select {
+	case r.events <- interaction:
+		return nil
+	default:
+		r.logger.WarnContext(ctx, "Interaction buffer full; writing synchronously")
+		return r.repo.InsertPOIInteractions(ctx, []locitypes.POIInteraction{interaction})
+	}
+}
+
+// Close stops accepting interactions and flushes what is buffered.
+func (r *InteractionRecorder) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.events)
+	}
+	r.mu.Unlock()
+	<-r.done
+}
+
+func (r *InteractionRecorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]locitypes.POIInteraction, 0, r.batchSize)
+	// retrying is set after a failed insert; full batches then wait for the ticker
+	// rather than hitting a failing database on every event.
+	retrying := false
+	flush := func() {
+		if err := r.flush(batch); err != nil {
+			retrying = true
+			return
+		}
+		retrying = false
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case interaction, ok := <-r.events:
+			if !ok {
+				if err := r.flush(batch); err != nil {
+					r.drop(len(batch))
+				}
+				return
+			}
+			batch = append(batch, interaction)
+			if retrying && len(batch) > r.maxPending {
+				n := len(batch) - r.maxPending
+				r.drop(n)
+				batch = append(batch[:0], batch[n:]...)
+			}
+			if len(batch) >= r.batchSize && !retrying {
+				flush()
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				flush()
+			}
+		}
+	}
+}
+
+func (r *InteractionRecorder) flush(batch []locitypes.POIInteraction) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	// Requests that queued these events have already returned, so flushes use their own deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), recorderFlushTimeout)
+	defer cancel()
+
+	if err := r.repo.InsertPOIInteractions(ctx, batch); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to flush POI interactions",
+			slog.Int("batch_size", len(batch)),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *InteractionRecorder) drop(n int) {
+	observability.POIInteractionsDropped.Add(float64(n))
+	r.logger.Warn("Dropped POI interactions after failed flushes", slog.Int("count", n))
+}
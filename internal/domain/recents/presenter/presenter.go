@@ -0,0 +1,405 @@
+package presenter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	recentsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/recents/v1"
+
+	statisticspresenter "github.com/FACorreiaa/loci-connect-api/internal/domain/statistics/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// Metadata keys the client can use to describe the POI itself.
+const (
+	MetadataCategory  = "category"
+	MetadataLatitude  = "latitude"
+	MetadataLongitude = "longitude"
+	MetadataCityName  = "city_name"
+)
+
+// FromInteractionType maps a proto interaction type onto the events stored in
+// poi_interactions. Searches, chats, lists and itineraries are recorded by their own
+// services, so they are not accepted here.
+func FromInteractionType(t recentsv1.InteractionType) (locitypes.POIInteractionType, bool) {
+	switch t {
+	case recentsv1.InteractionType_INTERACTION_TYPE_VIEW, recentsv1.InteractionType_INTERACTION_TYPE_DISCOVERY:
+		return locitypes.POIInteractionView, true
+	case recentsv1.InteractionType_INTERACTION_TYPE_RECOMMENDATION_CLICK:
+		return locitypes.POIInteractionClick, true
+	case recentsv1.InteractionType_INTERACTION_TYPE_FAVORITE:
+		return locitypes.POIInteractionFavorite, true
+	case recentsv1.InteractionType_INTERACTION_TYPE_UNFAVORITE:
+		return locitypes.POIInteractionUnfavorite, true
+	case recentsv1.InteractionType_INTERACTION_TYPE_BOOKING_ATTEMPT:
+		return locitypes.POIInteractionBookingAttempt, true
+	default:
+		return "", false
+	}
+}
+
+func ToInteractionType(t locitypes.POIInteractionType) recentsv1.InteractionType {
+	switch t {
+	case locitypes.POIInteractionView:
+		return recentsv1.InteractionType_INTERACTION_TYPE_VIEW
+	case locitypes.POIInteractionClick:
+		return recentsv1.InteractionType_INTERACTION_TYPE_RECOMMENDATION_CLICK
+	case locitypes.POIInteractionFavorite:
+		return recentsv1.InteractionType_INTERACTION_TYPE_FAVORITE
+	case locitypes.POIInteractionUnfavorite:
+		return recentsv1.InteractionType_INTERACTION_TYPE_UNFAVORITE
+	case locitypes.POIInteractionBookingAttempt:
+		return recentsv1.InteractionType_INTERACTION_TYPE_BOOKING_ATTEMPT
+	default:
+		return recentsv1.InteractionType_INTERACTION_TYPE_UNSPECIFIED
+	}
+}
+
+// FromRecordRequest builds the interaction to store. The user's position comes from the
+// request context; the POI's category and position from the metadata keys above.
+func FromRecordRequest(userID uuid.UUID, req *recentsv1.RecordInteractionRequest) (locitypes.POIInteraction, error) {
+	interactionType, ok := FromInteractionType(req.GetInteractionType())
+	if !ok {
+		return locitypes.POIInteraction{}, fmt.Errorf("interaction type %s cannot be recorded: %w",
+			req.GetInteractionType(), locitypes.ErrBadRequest)
+	}
+
+	metadata := req.GetMetadata()
+	interaction := locitypes.POIInteraction{
+		UserID:          userID,
+		POIID:           req.GetEntityId(),
+		POIName:         req.GetEntityName(),
+		POICategory:     metadata[MetadataCategory],
+		EntityType:      req.GetEntityType(),
+		InteractionType: interactionType,
+		CityName:        metadata[MetadataCityName],
+		Metadata:        metadata,
+	}
+	if req.GetCityId() != "" {
+		cityID, err := uuid.Parse(req.GetCityId())
+		if err != nil {
+			return locitypes.POIInteraction{}, fmt.Errorf("invalid city_id: %w", locitypes.ErrBadRequest)
+		}
+		interaction.CityID = &cityID
+	}
+
+	var err error
+	if interaction.POILatitude, err = parseCoordinate(metadata[MetadataLatitude]); err != nil {
+		return locitypes.POIInteraction{}, err
+	}
+	if interaction.POILongitude, err = parseCoordinate(metadata[MetadataLongitude]); err != nil {
+		return locitypes.POIInteraction{}, err
+	}
+
+	if c := req.GetContext(); c != nil {
+		interaction.SessionID = c.GetSessionId()
+		interaction.SourcePage = c.GetSourcePage()
+		interaction.DeviceType = c.GetDeviceType()
+		if loc := c.GetLocation(); loc != nil {
+			lat, lon := loc.GetLatitude(), loc.GetLongitude()
+			interaction.UserLatitude = &lat
+			interaction.UserLongitude = &lon
+			if interaction.CityName == "" {
+				interaction.CityName = loc.GetCity()
+			}
+		}
+	}
+	return interaction, nil
+}
+
+// FromFilter maps the proto filter; limit, offset and sorting are set by the caller.
+func FromFilter(f *recentsv1.InteractionFilter) (locitypes.POIInteractionFilter, error) {
+	var filter locitypes.POIInteractionFilter
+	if f == nil {
+		return filter, nil
+	}
+	for _, t := range f.GetInteractionTypes() {
+		mapped, ok := FromInteractionType(t)
+		if !ok {
+			return filter, fmt.Errorf("interaction type %s is not stored as a POI interaction: %w", t, locitypes.ErrBadRequest)
+		}
+		filter.Types = append(filter.Types, mapped)
+	}
+	filter.EntityTypes = f.GetEntityTypes()
+	if f.GetCityId() != "" {
+		cityID, err := uuid.Parse(f.GetCityId())
+		if err != nil {
+			return filter, fmt.Errorf("invalid city_id: %w", locitypes.ErrBadRequest)
+		}
+		filter.CityID = &cityID
+	}
+	filter.Categories = f.GetCategories()
+	filter.Search = f.GetSearchQuery()
+	if f.GetStartDate() != nil {
+		t := f.GetStartDate().AsTime()
+		filter.StartDate = &t
+	}
+	if f.GetEndDate() != nil {
+		t := f.GetEndDate().AsTime()
+		filter.EndDate = &t
+	}
+	return filter, nil
+}
+
+func ToProtoInteraction(i *locitypes.POIInteraction) *recentsv1.RecentInteraction {
+	pb := &recentsv1.RecentInteraction{
+		Id:              i.ID.String(),
+		UserId:          i.UserID.String(),
+		InteractionType: ToInteractionType(i.InteractionType),
+		EntityId:        i.POIID,
+		EntityType:      i.EntityType,
+		EntityName:      i.POIName,
+		Description:     describe(i),
+		CityName:        i.CityName,
+		Metadata:        i.Metadata,
+		CreatedAt:       timestamppb.New(i.Timestamp),
+		Context: &recentsv1.InteractionContext{
+			SourcePage: i.SourcePage,
+			DeviceType: i.DeviceType,
+			SessionId:  i.SessionID,
+		},
+	}
+	if i.CityID != nil {
+		pb.CityId = i.CityID.String()
+	}
+	if i.UserLatitude != nil && i.UserLongitude != nil {
+		pb.Context.Location = &recentsv1.GeoLocation{
+			Latitude:  *i.UserLatitude,
+			Longitude: *i.UserLongitude,
+			City:      i.CityName,
+		}
+	}
+	return pb
+}
+
+func ToProtoInteractions(interactions []locitypes.POIInteraction) []*recentsv1.RecentInteraction {
+	out := make([]*recentsv1.RecentInteraction, 0, len(interactions))
+	for i := range interactions {
+		out = append(out, ToProtoInteraction(&interactions[i]))
+	}
+	return out
+}
+
+// ToProtoCitySummaries groups a page of interactions by city, keeping the order in
+// which cities first appear and up to three interactions each.
+func ToProtoCitySummaries(interactions []locitypes.POIInteraction) []*recentsv1.CityInteractionSummary {
+	var summaries []*recentsv1.CityInteractionSummary
+	byCity := make(map[string]*recentsv1.CityInteractionSummary)
+	for i := range interactions {
+		in := &interactions[i]
+		if in.CityName == "" {
+			continue
+		}
+		summary, ok := byCity[in.CityName]
+		if !ok {
+			summary = &recentsv1.CityInteractionSummary{CityName: in.CityName}
+			if in.CityID != nil {
+				summary.CityId = in.CityID.String()
+			}
+			byCity[in.CityName] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.InteractionCount++
+		if summary.LatestInteraction == nil || in.Timestamp.After(summary.LatestInteraction.AsTime()) {
+			summary.LatestInteraction = timestamppb.New(in.Timestamp)
+		}
+		if len(summary.RecentInteractions) < 3 {
+			summary.RecentInteractions = append(summary.RecentInteractions, ToProtoInteraction(in))
+		}
+	}
+	return summaries
+}
+
+func ToProtoAnalytics(a *locitypes.InteractionAnalytics) *recentsv1.InteractionAnalytics {
+	if a == nil {
+		return nil
+	}
+	return &recentsv1.InteractionAnalytics{
+		TotalInteractionsToday:    int32(a.InteractionsToday),
+		TotalInteractionsThisWeek: int32(a.InteractionsThisWeek),
+		UniqueCitiesVisited:       int32(a.UniqueCities),
+		TopCategories:             a.TopCategories,
+		MostActiveTimeOfDay:       statisticspresenter.TimeOfDay(a.MostActiveHour),
+		AverageInteractionsPerDay: a.AveragePerDay,
+	}
+}
+
+func ToProtoTrends(trends []locitypes.InteractionTrendPoint) []*recentsv1.TrendData {
+	out := make([]*recentsv1.TrendData, 0, len(trends))
+	for _, t := range trends {
+		pb := &recentsv1.TrendData{
+			Date:             timestamppb.New(t.Date),
+			InteractionCount: int32(t.Total),
+		}
+		for _, it := range []locitypes.POIInteractionType{
+			locitypes.POIInteractionView,
+			locitypes.POIInteractionClick,
+			locitypes.POIInteractionFavorite,
+			locitypes.POIInteractionUnfavorite,
+			locitypes.POIInteractionBookingAttempt,
+		} {
+			if n := t.ByType[it]; n > 0 {
+				pb.TypeBreakdown = append(pb.TypeBreakdown, &recentsv1.InteractionTypeCount{
+					Type:  ToInteractionType(it),
+					Count: int32(n),
+				})
+			}
+		}
+		out = append(out, pb)
+	}
+	return out
+}
+
+func ToProtoFrequentPlace(p *locitypes.FrequentPlace) *recentsv1.FrequentPlace {
+	pb := &recentsv1.FrequentPlace{
+		PlaceId:             p.POIID,
+		PlaceName:           p.POIName,
+		PlaceType:           p.EntityType,
+		Category:            p.Category,
+		CityName:            p.CityName,
+		VisitCount:          int32(p.VisitDays),
+		InteractionCount:    int32(p.InteractionCount),
+		FirstVisit:          timestamppb.New(p.FirstVisit),
+		LastVisit:           timestamppb.New(p.LastVisit),
+		VisitFrequencyScore: p.Score,
+	}
+	if p.Latitude != nil && p.Longitude != nil {
+		pb.Latitude = *p.Latitude
+		pb.Longitude = *p.Longitude
+	}
+	for _, t := range p.Types {
+		pb.InteractionTypes = append(pb.InteractionTypes, ToInteractionType(t))
+	}
+	return pb
+}
+
+// ToProtoFrequentPlaceInsights derives coarse habits from the ranked places.
+func ToProtoFrequentPlaceInsights(places []locitypes.FrequentPlace) *recentsv1.FrequentPlaceInsights {
+	insights := &recentsv1.FrequentPlaceInsights{}
+	if len(places) == 0 {
+		return insights
+	}
+
+	cities := make(map[string]bool)
+	categoryCounts := make(map[string]int)
+	var categories []string
+	for _, p := range places {
+		if p.CityName != "" {
+			cities[p.CityName] = true
+		}
+		if p.Category == "" {
+			continue
+		}
+		if categoryCounts[p.Category] == 0 {
+			categories = append(categories, p.Category)
+		}
+		categoryCounts[p.Category] += p.InteractionCount
+	}
+
+	insights.TravelPattern = "single_city"
+	if len(cities) > 1 {
+		insights.TravelPattern = "multi_city"
+	}
+	insights.FavoriteCategories = topN(categories, categoryCounts, 3)
+	insights.ExplorationDiversityScore = float64(len(categories)) / float64(len(places)) * 100
+
+	switch top := places[0].VisitDays; {
+	case top >= 10:
+		insights.LoyaltyLevel = "loyal"
+	case top >= 3:
+		insights.LoyaltyLevel = "regular"
+	default:
+		insights.LoyaltyLevel = "explorer"
+	}
+	return insights
+}
+
+func ToProtoCityInteractions(a *locitypes.CityPOIActivity) *recentsv1.CityInteractions {
+	pb := &recentsv1.CityInteractions{
+		CityName:           a.CityName,
+		Country:            a.Country,
+		TotalInteractions:  int32(a.TotalInteractions),
+		Searches:           int32(a.Searches),
+		Favorites:          int32(a.Favorites),
+		ItinerariesCreated: int32(a.ItinerariesCreated),
+		PoisViewed:         int32(a.Views),
+		TopCategories:      a.TopCategories,
+		Preferences: &recentsv1.CityPreferences{
+			PreferredCategories:   a.TopCategories,
+			PreferredRadiusMeters: a.AverageDistanceKm * 1000,
+			PreferredTimeOfDay:    statisticspresenter.TimeOfDay(a.MostActiveHour),
+		},
+	}
+	if a.CityID != nil {
+		pb.CityId = a.CityID.String()
+	}
+	if a.FirstInteraction != nil {
+		pb.FirstInteraction = timestamppb.New(*a.FirstInteraction)
+	}
+	if a.LastInteraction != nil {
+		pb.LastInteraction = timestamppb.New(*a.LastInteraction)
+	}
+	return pb
+}
+
+// ToProtoCityInsights classifies how a user engages with a city.
+func ToProtoCityInsights(a *locitypes.CityPOIActivity) *recentsv1.CityInsights {
+	insights := &recentsv1.CityInsights{DiscoveryPattern: "focused"}
+	if poiEvents := a.Views + a.Favorites; poiEvents > 0 && float64(a.DistinctPOIs)/float64(poiEvents) >= 0.6 {
+		insights.DiscoveryPattern = "explorer"
+	}
+	switch {
+	case a.ActiveDays >= 10:
+		insights.VisitFrequency = "frequent"
+	case a.ActiveDays >= 3:
+		insights.VisitFrequency = "occasional"
+	default:
+		insights.VisitFrequency = "rare"
+	}
+	// Commitments (favourites, itineraries) relative to browsing (views, searches).
+	if browsing := a.Views + a.Searches; browsing > 0 {
+		insights.EngagementScore = min(100, float64(a.Favorites+a.ItinerariesCreated)/float64(browsing)*100)
+	}
+	return insights
+}
+
+func describe(i *locitypes.POIInteraction) string {
+	switch i.InteractionType {
+	case locitypes.POIInteractionView:
+		return "Viewed " + i.POIName
+	case locitypes.POIInteractionClick:
+		return "Opened " + i.POIName
+	case locitypes.POIInteractionFavorite:
+		return "Saved " + i.POIName + " to favourites"
+	case locitypes.POIInteractionUnfavorite:
+		return "Removed " + i.POIName + " from favourites"
+	case locitypes.POIInteractionBookingAttempt:
+		return "Started a booking at " + i.POIName
+	default:
+		return i.POIName
+	}
+}
+
+func parseCoordinate(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coordinate %q: %w", value, locitypes.ErrBadRequest)
+	}
+	return &f, nil
+}
+
+func topN(keys []string, counts map[string]int, n int) []string {
+	sorted := append([]string(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return counts[sorted[i]] > counts[sorted[j]] })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -26,6 +27,14 @@ type Repository interface {
 	GetCityRestaurantsByInteraction(ctx context.Context, userID uuid.UUID, cityName string) ([]locitypes.RestaurantDetailedInfo, error)
 	GetCityItinerariesByInteraction(ctx context.Context, userID uuid.UUID, cityName string) ([]locitypes.UserSavedItinerary, error)
 	GetCityFavorites(ctx context.Context, userID uuid.UUID, cityName string) ([]locitypes.POIDetailedInfo, error)
+
+	// InsertPOIInteractions writes a batch of interactions with a single COPY.
+	InsertPOIInteractions(ctx context.Context, interactions []locitypes.POIInteraction) error
+	GetPOIInteractions(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.POIInteraction, int, error)
+	GetInteractionAnalytics(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) (*locitypes.InteractionAnalytics, error)
+	GetInteractionTrends(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.InteractionTrendPoint, error)
+	GetFrequentPlaces(ctx context.Context, userID uuid.UUID, since *time.Time, entityTypes []string, limit int) ([]locitypes.FrequentPlace, error)
+	GetCityPOIActivity(ctx context.Context, userID uuid.UUID, cityName string, start, end *time.Time) (*locitypes.CityPOIActivity, error)
 }
 
 type RepositoryImpl struct {
@@ -860,3 +869,429 @@ func (r *RepositoryImpl) GetCityFavorites(ctx context.Context, userID uuid.UUID,
 
 	return pois, nil
 }
+
+var poiInteractionColumns = []string{
+	"id", "user_id", "poi_id", "poi_name", "poi_category", "entity_type", "interaction_type",
+	"city_id", "city_name", "user_latitude", "user_longitude", "poi_latitude", "poi_longitude",
+	"distance", "session_id", "source_page", "device_type", "metadata", "timestamp",
+}
+
+// InsertPOIInteractions writes a batch of interactions with a single COPY.
+func (r *RepositoryImpl) InsertPOIInteractions(ctx context.Context, interactions []locitypes.POIInteraction) error {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "InsertPOIInteractions", trace.WithAttributes(
+		attribute.Int("batch.size", len(interactions)),
+	))
+	defer span.End()
+
+	if len(interactions) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, 0, len(interactions))
+	for _, i := range interactions {
+		metadata := i.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		rows = append(rows, []any{
+			i.ID, i.UserID, i.POIID, i.POIName, i.POICategory, i.EntityType, string(i.InteractionType),
+			i.CityID, i.CityName, i.UserLatitude, i.UserLongitude, i.POILatitude, i.POILongitude,
+			i.DistanceKm, i.SessionID, i.SourcePage, i.DeviceType, metadata, i.Timestamp,
+		})
+	}
+
+	_, err := r.pgpool.CopyFrom(ctx, pgx.Identifier{"poi_interactions"}, poiInteractionColumns, pgx.CopyFromRows(rows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to insert POI interactions")
+		return fmt.Errorf("failed to insert poi interactions: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "POI interactions inserted")
+	return nil
+}
+
+// interactionConditions builds the WHERE clause shared by the interaction history queries.
+// Pagination and sorting are left to the caller.
+func interactionConditions(userID uuid.UUID, filter locitypes.POIInteractionFilter) (string, []any) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	add := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if len(filter.Types) > 0 {
+		types := make([]string, 0, len(filter.Types))
+		for _, t := range filter.Types {
+			types = append(types, string(t))
+		}
+		add("interaction_type = ANY($%d)", types)
+	}
+	if len(filter.EntityTypes) > 0 {
+		add("entity_type = ANY($%d)", filter.EntityTypes)
+	}
+	if filter.CityID != nil {
+		add("city_id = $%d", *filter.CityID)
+	}
+	if filter.CityName != "" {
+		add("lower(city_name) = lower($%d)", filter.CityName)
+	}
+	if len(filter.Categories) > 0 {
+		categories := make([]string, 0, len(filter.Categories))
+		for _, c := range filter.Categories {
+			categories = append(categories, strings.ToLower(c))
+		}
+		add("lower(poi_category) = ANY($%d)", categories)
+	}
+	if filter.StartDate != nil {
+		add("timestamp >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		add("timestamp < $%d", *filter.EndDate)
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("(poi_name ILIKE $%d OR city_name ILIKE $%d)", len(args), len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetPOIInteractions returns a page of a user's interactions and the total matching the filter.
+func (r *RepositoryImpl) GetPOIInteractions(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.POIInteraction, int, error) {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetPOIInteractions", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+		attribute.Int("limit", filter.Limit),
+		attribute.Int("offset", filter.Offset),
+	))
+	defer span.End()
+
+	where, args := interactionConditions(userID, filter)
+
+	var total int
+	if err := r.pgpool.QueryRow(ctx, "SELECT COUNT(*) FROM poi_interactions WHERE "+where, args...).Scan(&total); err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to count poi interactions: %w", err)
+	}
+
+	sortColumn := "timestamp"
+	switch filter.SortBy {
+	case "poi_name", "city_name":
+		sortColumn = filter.SortBy
+	}
+	direction := "DESC"
+	if filter.SortAscending {
+		direction = "ASC"
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM poi_interactions
+	WHERE %s
+	ORDER BY %s %s, id
+	LIMIT $%d OFFSET $%d`,
+		strings.Join(poiInteractionColumns, ", "), where, sortColumn, direction, len(args)+1, len(args)+2)
+
+	rows, err := r.pgpool.Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query POI interactions")
+		return nil, 0, fmt.Errorf("failed to query poi interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var interactions []locitypes.POIInteraction
+	for rows.Next() {
+		var i locitypes.POIInteraction
+		var interactionType string
+		if err := rows.Scan(
+			&i.ID, &i.UserID, &i.POIID, &i.POIName, &i.POICategory, &i.EntityType, &interactionType,
+			&i.CityID, &i.CityName, &i.UserLatitude, &i.UserLongitude, &i.POILatitude, &i.POILongitude,
+			&i.DistanceKm, &i.SessionID, &i.SourcePage, &i.DeviceType, &i.Metadata, &i.Timestamp,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan poi interaction: %w", err)
+		}
+		i.InteractionType = locitypes.POIInteractionType(interactionType)
+		interactions = append(interactions, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating poi interactions: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(interactions)), attribute.Int("results.total", total))
+	span.SetStatus(codes.Ok, "POI interactions retrieved")
+	return interactions, total, nil
+}
+
+// GetInteractionAnalytics summarises the interactions matching the filter.
+func (r *RepositoryImpl) GetInteractionAnalytics(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) (*locitypes.InteractionAnalytics, error) {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetInteractionAnalytics", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+	))
+	defer span.End()
+
+	where, args := interactionConditions(userID, filter)
+
+	var analytics locitypes.InteractionAnalytics
+	var total, activeSpanDays int
+	err := r.pgpool.QueryRow(ctx, `
+	SELECT
+		COUNT(*) FILTER (WHERE timestamp >= date_trunc('day', NOW())),
+		COUNT(*) FILTER (WHERE timestamp >= NOW() - INTERVAL '7 days'),
+		COUNT(DISTINCT lower(city_name)) FILTER (WHERE city_name <> ''),
+		COUNT(*),
+		COALESCE(EXTRACT(DAY FROM MAX(timestamp) - MIN(timestamp))::int, 0) + 1,
+		COALESCE(mode() WITHIN GROUP (ORDER BY EXTRACT(HOUR FROM timestamp)::int), 0)
+	FROM poi_interactions
+	WHERE `+where, args...).Scan(
+		&analytics.InteractionsToday,
+		&analytics.InteractionsThisWeek,
+		&analytics.UniqueCities,
+		&total,
+		&activeSpanDays,
+		&analytics.MostActiveHour,
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get interaction analytics")
+		return nil, fmt.Errorf("failed to get interaction analytics: %w", err)
+	}
+	if total > 0 {
+		analytics.AveragePerDay = float64(total) / float64(activeSpanDays)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT lower(poi_category)
+	FROM poi_interactions
+	WHERE `+where+` AND poi_category <> ''
+	GROUP BY 1
+	ORDER BY COUNT(*) DESC, 1
+	LIMIT 5`, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get top categories: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		analytics.TopCategories = append(analytics.TopCategories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interaction analytics retrieved")
+	return &analytics, nil
+}
+
+// GetInteractionTrends counts the interactions matching the filter per day and type.
+func (r *RepositoryImpl) GetInteractionTrends(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.InteractionTrendPoint, error) {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetInteractionTrends", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+	))
+	defer span.End()
+
+	where, args := interactionConditions(userID, filter)
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT date_trunc('day', timestamp) AS day, interaction_type, COUNT(*)
+	FROM poi_interactions
+	WHERE `+where+`
+	GROUP BY 1, 2
+	ORDER BY 1`, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get interaction trends")
+		return nil, fmt.Errorf("failed to get interaction trends: %w", err)
+	}
+	defer rows.Close()
+
+	var trends []locitypes.InteractionTrendPoint
+	for rows.Next() {
+		var day time.Time
+		var interactionType string
+		var count int
+		if err := rows.Scan(&day, &interactionType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan interaction trend: %w", err)
+		}
+		if len(trends) == 0 || !trends[len(trends)-1].Date.Equal(day) {
+			trends = append(trends, locitypes.InteractionTrendPoint{
+				Date:   day,
+				ByType: make(map[locitypes.POIInteractionType]int),
+			})
+		}
+		point := &trends[len(trends)-1]
+		point.Total += count
+		point.ByType[locitypes.POIInteractionType(interactionType)] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating interaction trends: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interaction trends retrieved")
+	return trends, nil
+}
+
+// GetFrequentPlaces ranks the POIs a user keeps coming back to. Each interaction
+// contributes exp(-age/30 days) to the score, so recent activity outweighs old habits.
+func (r *RepositoryImpl) GetFrequentPlaces(ctx context.Context, userID uuid.UUID, since *time.Time, entityTypes []string, limit int) ([]locitypes.FrequentPlace, error) {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetFrequentPlaces", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	query := `
+	SELECT
+		poi_id,
+		(array_agg(poi_name ORDER BY timestamp DESC))[1],
+		(array_agg(entity_type ORDER BY timestamp DESC))[1],
+		(array_agg(poi_category ORDER BY timestamp DESC))[1],
+		(array_agg(city_name ORDER BY timestamp DESC))[1],
+		(array_agg(poi_latitude ORDER BY timestamp DESC) FILTER (WHERE poi_latitude IS NOT NULL))[1],
+		(array_agg(poi_longitude ORDER BY timestamp DESC) FILTER (WHERE poi_longitude IS NOT NULL))[1],
+		COUNT(DISTINCT date_trunc('day', timestamp)),
+		COUNT(*),
+		MIN(timestamp),
+		MAX(timestamp),
+		SUM(EXP(-EXTRACT(EPOCH FROM NOW() - timestamp) / 2592000.0))::float8,
+		array_agg(DISTINCT interaction_type)
+	FROM poi_interactions
+	WHERE user_id = $1
+	  AND ($2::timestamptz IS NULL OR timestamp >= $2)
+	  AND (cardinality($3::text[]) = 0 OR entity_type = ANY($3))
+	  AND interaction_type <> 'unfavorite'
+	GROUP BY poi_id
+	ORDER BY 12 DESC, 9 DESC
+	LIMIT $4`
+
+	if entityTypes == nil {
+		entityTypes = []string{}
+	}
+	rows, err := r.pgpool.Query(ctx, query, userID, since, entityTypes, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get frequent places")
+		return nil, fmt.Errorf("failed to get frequent places: %w", err)
+	}
+	defer rows.Close()
+
+	var places []locitypes.FrequentPlace
+	for rows.Next() {
+		var p locitypes.FrequentPlace
+		var types []string
+		if err := rows.Scan(
+			&p.POIID, &p.POIName, &p.EntityType, &p.Category, &p.CityName, &p.Latitude, &p.Longitude,
+			&p.VisitDays, &p.InteractionCount, &p.FirstVisit, &p.LastVisit, &p.Score, &types,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan frequent place: %w", err)
+		}
+		for _, t := range types {
+			p.Types = append(p.Types, locitypes.POIInteractionType(t))
+		}
+		places = append(places, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating frequent places: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("results.count", len(places)))
+	span.SetStatus(codes.Ok, "Frequent places retrieved")
+	return places, nil
+}
+
+// GetCityPOIActivity summarises a user's searches, POI interactions and saved itineraries in a city.
+func (r *RepositoryImpl) GetCityPOIActivity(ctx context.Context, userID uuid.UUID, cityName string, start, end *time.Time) (*locitypes.CityPOIActivity, error) {
+	ctx, span := otel.Tracer("RecentsRepository").Start(ctx, "GetCityPOIActivity", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+		attribute.String("city_name", cityName),
+	))
+	defer span.End()
+
+	query := `
+	WITH interactions AS (
+		SELECT * FROM poi_interactions
+		WHERE user_id = $1 AND lower(city_name) = lower($2)
+		  AND ($3::timestamptz IS NULL OR timestamp >= $3)
+		  AND ($4::timestamptz IS NULL OR timestamp < $4)
+	),
+	searches AS (
+		SELECT created_at FROM llm_interactions
+		WHERE user_id = $1 AND lower(city_name) = lower($2)
+		  AND ($3::timestamptz IS NULL OR created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR created_at < $4)
+	),
+	itineraries AS (
+		SELECT usi.created_at FROM user_saved_itineraries usi
+		JOIN cities c ON c.id = usi.primary_city_id
+		WHERE usi.user_id = $1 AND lower(c.name) = lower($2)
+		  AND ($3::timestamptz IS NULL OR usi.created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR usi.created_at < $4)
+	),
+	activity AS (
+		SELECT timestamp AS at FROM interactions
+		UNION ALL SELECT created_at FROM searches
+		UNION ALL SELECT created_at FROM itineraries
+	)
+	SELECT
+		c.id,
+		COALESCE(c.name, $2),
+		COALESCE(c.country, ''),
+		(SELECT COUNT(*) FROM searches),
+		(SELECT COUNT(*) FROM interactions WHERE interaction_type IN ('view', 'click')),
+		(SELECT COUNT(*) FROM interactions WHERE interaction_type = 'favorite'),
+		(SELECT COUNT(*) FROM itineraries),
+		(SELECT COUNT(*) FROM activity),
+		(SELECT COUNT(DISTINCT poi_id) FROM interactions),
+		(SELECT COUNT(DISTINCT date_trunc('day', at)) FROM activity),
+		(SELECT MIN(at) FROM activity),
+		(SELECT MAX(at) FROM activity),
+		COALESCE((SELECT mode() WITHIN GROUP (ORDER BY EXTRACT(HOUR FROM at)::int) FROM activity), 0),
+		COALESCE((SELECT AVG(distance) FROM interactions WHERE distance IS NOT NULL), 0)::float8
+	FROM (SELECT 1) one
+	LEFT JOIN LATERAL (
+		SELECT id, name, country FROM cities WHERE lower(name) = lower($2) LIMIT 1
+	) c ON TRUE`
+
+	var a locitypes.CityPOIActivity
+	err := r.pgpool.QueryRow(ctx, query, userID, cityName, start, end).Scan(
+		&a.CityID, &a.CityName, &a.Country, &a.Searches, &a.Views, &a.Favorites, &a.ItinerariesCreated,
+		&a.TotalInteractions, &a.DistinctPOIs, &a.ActiveDays, &a.FirstInteraction, &a.LastInteraction,
+		&a.MostActiveHour, &a.AverageDistanceKm,
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get city activity")
+		return nil, fmt.Errorf("failed to get city activity: %w", err)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT lower(poi_category)
+	FROM poi_interactions
+	WHERE user_id = $1 AND lower(city_name) = lower($2) AND poi_category <> ''
+	  AND ($3::timestamptz IS NULL OR timestamp >= $3)
+	  AND ($4::timestamptz IS NULL OR timestamp < $4)
+	GROUP BY 1
+	ORDER BY COUNT(*) DESC, 1
+	LIMIT 5`, userID, cityName, start, end)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get city categories: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		a.TopCategories = append(a.TopCategories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "City activity retrieved")
+	return &a, nil
+}
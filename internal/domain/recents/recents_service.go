@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,18 +22,35 @@ var _ Service = (*ServiceImpl)(nil)
 type Service interface {
 	GetUserRecentInteractions(ctx context.Context, userID uuid.UUID, page, limit int, filterOptions *locitypes.RecentInteractionsFilter) (*locitypes.RecentInteractionsResponse, error)
 	GetCityDetailsForUser(ctx context.Context, userID uuid.UUID, cityName string) (*locitypes.CityInteractions, error)
+	RecordInteraction(ctx context.Context, interaction locitypes.POIInteraction) (uuid.UUID, error)
+	GetInteractionHistory(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.POIInteraction, int, error)
+	GetInteractionAnalytics(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) (*locitypes.InteractionAnalytics, []locitypes.InteractionTrendPoint, error)
+	GetFrequentPlaces(ctx context.Context, userID uuid.UUID, since *time.Time, entityTypes []string, limit int) ([]locitypes.FrequentPlace, error)
+	GetCityActivity(ctx context.Context, userID uuid.UUID, cityName string, start, end *time.Time) (*locitypes.CityPOIActivity, error)
+}
+
+// interactionRecorder is implemented by InteractionRecorder.
+type interactionRecorder interface {
+	Record(ctx context.Context, interaction locitypes.POIInteraction) error
 }
 
 type ServiceImpl struct {
-	repo   Repository
-	logger *slog.Logger
+	repo     Repository
+	recorder interactionRecorder
+	logger   *slog.Logger
 }
 
-func NewService(repo Repository, logger *slog.Logger) *ServiceImpl {
-	return &ServiceImpl{
+// NewService creates the recents service. With a nil recorder interactions are
+// written synchronously.
+func NewService(repo Repository, recorder *InteractionRecorder, logger *slog.Logger) *ServiceImpl {
+	s := &ServiceImpl{
 		repo:   repo,
 		logger: logger,
 	}
+	if recorder != nil {
+		s.recorder = recorder
+	}
+	return s
 }
 
 // GetUserRecentInteractions retrieves recent interactions for a user
@@ -251,3 +270,224 @@ func convertPOIsToDetail(detailedPOIs []locitypes.POIDetailedInfo) []locitypes.P
 	}
 	return pois
 }
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+	maxFrequentPlaces   = 50
+)
+
+var validInteractionTypes = map[locitypes.POIInteractionType]bool{
+	locitypes.POIInteractionView:           true,
+	locitypes.POIInteractionClick:          true,
+	locitypes.POIInteractionFavorite:       true,
+	locitypes.POIInteractionUnfavorite:     true,
+	locitypes.POIInteractionBookingAttempt: true,
+}
+
+// RecordInteraction validates an interaction and queues it for the next batch insert.
+// The ID is assigned here so the caller can reference the event before it is flushed.
+func (s *ServiceImpl) RecordInteraction(ctx context.Context, interaction locitypes.POIInteraction) (uuid.UUID, error) {
+	ctx, span := otel.Tracer("RecentsService").Start(ctx, "RecordInteraction", trace.WithAttributes(
+		attribute.String("user_id", interaction.UserID.String()),
+		attribute.String("interaction_type", string(interaction.InteractionType)),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "RecordInteraction"))
+
+	if interaction.UserID == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("user is required: %w", locitypes.ErrBadRequest)
+	}
+	if !validInteractionTypes[interaction.InteractionType] {
+		return uuid.Nil, fmt.Errorf("unsupported interaction type %q: %w", interaction.InteractionType, locitypes.ErrBadRequest)
+	}
+	interaction.POIID = strings.TrimSpace(interaction.POIID)
+	if interaction.POIID == "" {
+		return uuid.Nil, fmt.Errorf("entity id is required: %w", locitypes.ErrBadRequest)
+	}
+	if interaction.POIName == "" {
+		interaction.POIName = interaction.POIID
+	}
+	if interaction.EntityType == "" {
+		interaction.EntityType = "poi"
+	}
+	for _, coord := range []struct {
+		value *float64
+		limit float64
+	}{
+		{interaction.UserLatitude, 90}, {interaction.UserLongitude, 180},
+		{interaction.POILatitude, 90}, {interaction.POILongitude, 180},
+	} {
+		if coord.value != nil && math.Abs(*coord.value) > coord.limit {
+			return uuid.Nil, fmt.Errorf("coordinates out of range: %w", locitypes.ErrBadRequest)
+		}
+	}
+	if interaction.DistanceKm == nil &&
+		interaction.UserLatitude != nil && interaction.UserLongitude != nil &&
+		interaction.POILatitude != nil && interaction.POILongitude != nil {
+		d := haversineKm(*interaction.UserLatitude, *interaction.UserLongitude, *interaction.POILatitude, *interaction.POILongitude)
+		interaction.DistanceKm = &d
+	}
+
+	interaction.ID = uuid.New()
+	if interaction.Timestamp.IsZero() {
+		interaction.Timestamp = time.Now()
+	}
+
+	var err error
+	if s.recorder != nil {
+		err = s.recorder.Record(ctx, interaction)
+	} else {
+		err = s.repo.InsertPOIInteractions(ctx, []locitypes.POIInteraction{interaction})
+	}
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to record interaction", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to record interaction")
+		return uuid.Nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Interaction recorded")
+	return interaction.ID, nil
+}
+
+// GetInteractionHistory returns a page of a user's POI interactions.
+func (s *ServiceImpl) GetInteractionHistory(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) ([]locitypes.POIInteraction, int, error) {
+	ctx, span := otel.Tracer("RecentsService").Start(ctx, "GetInteractionHistory", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+	))
+	defer span.End()
+
+	if err := validateInteractionFilter(&filter); err != nil {
+		return nil, 0, err
+	}
+
+	interactions, total, err := s.repo.GetPOIInteractions(ctx, userID, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get interaction history", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get interaction history")
+		return nil, 0, err
+	}
+
+	span.SetStatus(codes.Ok, "Interaction history retrieved")
+	return interactions, total, nil
+}
+
+// GetInteractionAnalytics summarises the interactions matching the filter, with daily trends.
+func (s *ServiceImpl) GetInteractionAnalytics(ctx context.Context, userID uuid.UUID, filter locitypes.POIInteractionFilter) (*locitypes.InteractionAnalytics, []locitypes.InteractionTrendPoint, error) {
+	ctx, span := otel.Tracer("RecentsService").Start(ctx, "GetInteractionAnalytics", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+	))
+	defer span.End()
+
+	if err := validateInteractionFilter(&filter); err != nil {
+		return nil, nil, err
+	}
+
+	analytics, err := s.repo.GetInteractionAnalytics(ctx, userID, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	trends, err := s.repo.GetInteractionTrends(ctx, userID, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Interaction analytics retrieved")
+	return analytics, trends, nil
+}
+
+// GetFrequentPlaces returns the POIs a user interacts with most, most relevant first.
+func (s *ServiceImpl) GetFrequentPlaces(ctx context.Context, userID uuid.UUID, since *time.Time, entityTypes []string, limit int) ([]locitypes.FrequentPlace, error) {
+	ctx, span := otel.Tracer("RecentsService").Start(ctx, "GetFrequentPlaces", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > maxFrequentPlaces {
+		limit = maxFrequentPlaces
+	}
+
+	places, err := s.repo.GetFrequentPlaces(ctx, userID, since, entityTypes, limit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get frequent places", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get frequent places")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Frequent places retrieved")
+	return places, nil
+}
+
+// GetCityActivity summarises a user's activity in a city. A city the user never
+// interacted with is reported as not found.
+func (s *ServiceImpl) GetCityActivity(ctx context.Context, userID uuid.UUID, cityName string, start, end *time.Time) (*locitypes.CityPOIActivity, error) {
+	ctx, span := otel.Tracer("RecentsService").Start(ctx, "GetCityActivity", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+		attribute.String("city_name", cityName),
+	))
+	defer span.End()
+
+	cityName = strings.TrimSpace(cityName)
+	if cityName == "" {
+		return nil, fmt.Errorf("city name is required: %w", locitypes.ErrBadRequest)
+	}
+	if start != nil && end != nil && !end.After(*start) {
+		return nil, fmt.Errorf("end date must be after start date: %w", locitypes.ErrBadRequest)
+	}
+
+	activity, err := s.repo.GetCityPOIActivity(ctx, userID, cityName, start, end)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get city activity", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get city activity")
+		return nil, err
+	}
+	if activity.TotalInteractions == 0 {
+		return nil, fmt.Errorf("no interactions in %s: %w", cityName, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "City activity retrieved")
+	return activity, nil
+}
+
+func validateInteractionFilter(filter *locitypes.POIInteractionFilter) error {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+	if filter.Limit > maxHistoryLimit {
+		filter.Limit = maxHistoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	for _, t := range filter.Types {
+		if !validInteractionTypes[t] {
+			return fmt.Errorf("unsupported interaction type %q: %w", t, locitypes.ErrBadRequest)
+		}
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.EndDate.After(*filter.StartDate) {
+		return fmt.Errorf("end date must be after start date: %w", locitypes.ErrBadRequest)
+	}
+	return nil
+}
+
+// haversineKm returns the great-circle distance between two coordinates in kilometres.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,166 @@
+package recents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// fakeRepository records inserted batches; other methods are not used by these tests.
+type fakeRepository struct {
+	Repository
+
+	mu       sync.Mutex
+	batches  [][]locitypes.POIInteraction
+	flushed  chan int
+	failures int // inserts to fail before succeeding
+}
+
+func (f *fakeRepository) InsertPOIInteractions(_ context.Context, interactions []locitypes.POIInteraction) error {
+	f.mu.Lock()
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		return errors.New("database unavailable")
+	}
+	f.batches = append(f.batches, append([]locitypes.POIInteraction(nil), interactions...))
+	f.mu.Unlock()
+	if f.flushed != nil {
+		f.flushed <- len(interactions)
+	}
+	return nil
+}
+
+func (f *fakeRepository) inserted() []locitypes.POIInteraction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var all []locitypes.POIInteraction
+	for _, b := range f.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func ptr(f float64) *float64 { return &f }
+
+func TestRecordInteraction_ValidatesAndComputesDistance(t *testing.T) {
+	repo := &fakeRepository{}
+	svc := NewService(repo, nil, testutil.NewLogger())
+	userID := uuid.New()
+
+	id, err := svc.RecordInteraction(context.Background(), locitypes.POIInteraction{
+		UserID:          userID,
+		POIID:           "poi-1",
+		POIName:         "Torre de Belém",
+		InteractionType: locitypes.POIInteractionView,
+		UserLatitude:    ptr(38.7223),
+		UserLongitude:   ptr(-9.1393),
+		POILatitude:     ptr(38.6916),
+		POILongitude:    ptr(-9.2160),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, id)
+
+	inserted := repo.inserted()
+	require.Len(t, inserted, 1)
+	require.Equal(t, id, inserted[0].ID)
+	require.Equal(t, "poi", inserted[0].EntityType)
+	require.NotNil(t, inserted[0].DistanceKm)
+	require.InDelta(t, 7.4, *inserted[0].DistanceKm, 0.2)
+	require.False(t, inserted[0].Timestamp.IsZero())
+}
+
+func TestRecordInteraction_RejectsInvalidInput(t *testing.T) {
+	svc := NewService(&fakeRepository{}, nil, testutil.NewLogger())
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		interaction locitypes.POIInteraction
+	}{
+		{name: "missing entity", interaction: locitypes.POIInteraction{UserID: userID, InteractionType: locitypes.POIInteractionView}},
+		{name: "unknown type", interaction: locitypes.POIInteraction{UserID: userID, POIID: "poi-1", InteractionType: "share"}},
+		{name: "bad latitude", interaction: locitypes.POIInteraction{UserID: userID, POIID: "poi-1", InteractionType: locitypes.POIInteractionClick, UserLatitude: ptr(123)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.RecordInteraction(context.Background(), tt.interaction)
+			require.ErrorIs(t, err, locitypes.ErrBadRequest)
+		})
+	}
+}
+
+func TestInteractionRecorder_FlushesFullBatches(t *testing.T) {
+	repo := &fakeRepository{flushed: make(chan int, 10)}
+	recorder := NewInteractionRecorder(repo, testutil.NewLogger(), 3, time.Hour)
+	svc := NewService(repo, recorder, testutil.NewLogger())
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.RecordInteraction(context.Background(), locitypes.POIInteraction{
+			UserID:          uuid.New(),
+			POIID:           "poi-1",
+			InteractionType: locitypes.POIInteractionView,
+		})
+		require.NoError(t, err)
+	}
+
+	select {
+	case n := <-repo.flushed:
+		require.Equal(t, 3, n)
+	case <-time.After(2 * time.Second):
+		t.Fatal("batch was not flushed")
+	}
+	recorder.Close()
+	require.Len(t, repo.batches, 1)
+}
+
+func TestInteractionRecorder_CloseFlushesRemainder(t *testing.T) {
+	repo := &fakeRepository{}
+	recorder := NewInteractionRecorder(repo, testutil.NewLogger(), 100, time.Hour)
+
+	require.NoError(t, recorder.Record(context.Background(), locitypes.POIInteraction{ID: uuid.New()}))
+	require.NoError(t, recorder.Record(context.Background(), locitypes.POIInteraction{ID: uuid.New()}))
+	recorder.Close()
+
+	require.Len(t, repo.inserted(), 2)
+	require.ErrorIs(t, recorder.Record(context.Background(), locitypes.POIInteraction{}), errRecorderClosed)
+}
+
+func TestInteractionRecorder_RetriesFailedBatches(t *testing.T) {
+	repo := &fakeRepository{failures: 2}
+	recorder := NewInteractionRecorder(repo, testutil.NewLogger(), 2, 10*time.Millisecond)
+
+	require.NoError(t, recorder.Record(context.Background(), locitypes.POIInteraction{ID: uuid.New()}))
+	require.NoError(t, recorder.Record(context.Background(), locitypes.POIInteraction{ID: uuid.New()}))
+
+	require.Eventually(t, func() bool { return len(repo.inserted()) == 2 }, 2*time.Second, 5*time.Millisecond)
+	recorder.Close()
+	require.Len(t, repo.inserted(), 2)
+}
+
+func TestGetCityActivity_NotFoundWithoutActivity(t *testing.T) {
+	svc := NewService(&cityRepository{activity: &locitypes.CityPOIActivity{CityName: "Lisbon"}}, nil, testutil.NewLogger())
+
+	_, err := svc.GetCityActivity(context.Background(), uuid.New(), "Lisbon", nil, nil)
+	require.ErrorIs(t, err, locitypes.ErrNotFound)
+
+	_, err = svc.GetCityActivity(context.Background(), uuid.New(), "  ", nil, nil)
+	require.ErrorIs(t, err, locitypes.ErrBadRequest)
+}
+
+type cityRepository struct {
+	Repository
+	activity *locitypes.CityPOIActivity
+}
+
+func (c *cityRepository) GetCityPOIActivity(context.Context, uuid.UUID, string, *time.Time, *time.Time) (*locitypes.CityPOIActivity, error) {
+	return c.activity, nil
+}
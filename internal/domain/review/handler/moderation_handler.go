@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// ModerateReview handles POST /v1/moderation/reviews/{id} with a JSON body
+// {"status": "published" | "hidden" | "flagged" | "pending"}. It is plain HTTP, as the
+// ReviewService proto has no moderation procedure, and is mounted behind the
+// moderator policy; the role check here keeps it safe if it is mounted elsewhere.
+func (h *ReviewHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	if !interceptors.HasRole(r.Context(), interceptors.RoleModerator) {
+		http.Error(w, "moderator role required", http.StatusForbidden)
+		return
+	}
+	reviewID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid review id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Status locitypes.ReviewStatus `json:"status"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ModerateReview(r.Context(), reviewID, body.Status); err != nil {
+		switch {
+		case errors.Is(err, locitypes.ErrBadRequest):
+			http.Error(w, "invalid status", http.StatusBadRequest)
+		case errors.Is(err, locitypes.ErrNotFound):
+			http.Error(w, "review not found", http.StatusNotFound)
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to moderate review", slog.String("review_id", reviewID.String()), slog.Any("error", err))
+			http.Error(w, "failed to moderate review", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	userID, _ := interceptors.GetUserIDFromContext(r.Context())
+	h.logger.InfoContext(r.Context(), "review moderated",
+		slog.String("review_id", reviewID.String()),
+		slog.String("status", string(body.Status)),
+		slog.String("moderator_id", userID))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	reviewv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1/reviewv1connect"
+	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+
+	discoverpresenter "github.com/FACorreiaa/loci-connect-api/internal/domain/discover/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/review"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/review/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 50
+)
+
+// ReviewHandler implements the ReviewServiceHandler interface.
+type ReviewHandler struct {
+	reviewv1connect.UnimplementedReviewServiceHandler
+	service review.Service
+	logger  *slog.Logger
+}
+
+// NewReviewHandler creates a new ReviewHandler.
+func NewReviewHandler(svc review.Service, logger *slog.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+func (h *ReviewHandler) CreateReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.CreateReviewRequest],
+) (*connect.Response[reviewv1.CreateReviewResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	poiID, err := uuid.Parse(req.Msg.GetPoiId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid poi_id"))
+	}
+
+	input := locitypes.ReviewInput{
+		Rating:    presenter.FromRating(req.Msg.GetRating()),
+		Title:     req.Msg.GetTitle(),
+		Content:   req.Msg.GetContent(),
+		ImageURLs: req.Msg.GetPhotoUrls(),
+		Language:  req.Msg.GetLanguage(),
+		Aspects:   presenter.FromAspects(req.Msg.GetAspects()),
+	}
+	if req.Msg.GetVisitDate() != nil {
+		t := req.Msg.GetVisitDate().AsTime()
+		input.VisitDate = &t
+	}
+
+	created, err := h.service.CreateReview(ctx, userID, poiID, input)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.CreateReviewResponse{
+		Response: successResponse("review created"),
+		Review:   presenter.ToProtoReview(created),
+	}), nil
+}
+
+// GetPOIReviews returns a page of published reviews for a POI together with its rating statistics.
+func (h *ReviewHandler) GetPOIReviews(
+	ctx context.Context,
+	req *connect.Request[reviewv1.GetPOIReviewsRequest],
+) (*connect.Response[reviewv1.GetPOIReviewsResponse], error) {
+	poiID, err := uuid.Parse(req.Msg.GetPoiId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid poi_id"))
+	}
+
+	page, pageSize := paginationParams(req.Msg.GetPagination())
+	filter := presenter.FromFilter(req.Msg.GetFilter())
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	reviews, total, err := h.service.GetPOIReviews(ctx, poiID, filter)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	stats, err := h.service.GetPOIStatistics(ctx, poiID, false)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.GetPOIReviewsResponse{
+		Reviews:    presenter.ToProtoReviews(reviews),
+		Pagination: discoverpresenter.ToPaginationMetadata(total, page, pageSize),
+		Statistics: presenter.ToProtoStatistics(stats),
+	}), nil
+}
+
+func (h *ReviewHandler) GetReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.GetReviewRequest],
+) (*connect.Response[reviewv1.GetReviewResponse], error) {
+	reviewID, err := uuid.Parse(req.Msg.GetReviewId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid review_id"))
+	}
+
+	viewerID, _ := interceptors.OptionalUserID(ctx)
+	r, err := h.service.GetReview(ctx, reviewID, viewerID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	isAuthor := viewerID != uuid.Nil && r.UserID == viewerID
+	return connect.NewResponse(&reviewv1.GetReviewResponse{
+		Review:    presenter.ToProtoReview(r),
+		CanEdit:   isAuthor,
+		CanDelete: isAuthor,
+	}), nil
+}
+
+func (h *ReviewHandler) UpdateReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.UpdateReviewRequest],
+) (*connect.Response[reviewv1.UpdateReviewResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	reviewID, err := uuid.Parse(req.Msg.GetReviewId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid review_id"))
+	}
+
+	input := locitypes.ReviewInput{
+		Rating:    presenter.FromRating(req.Msg.GetRating()),
+		Title:     req.Msg.GetTitle(),
+		Content:   req.Msg.GetContent(),
+		ImageURLs: req.Msg.GetPhotoUrls(),
+		Aspects:   presenter.FromAspects(req.Msg.GetAspects()),
+	}
+	if req.Msg.GetVisitDate() != nil {
+		t := req.Msg.GetVisitDate().AsTime()
+		input.VisitDate = &t
+	}
+
+	updated, err := h.service.UpdateReview(ctx, userID, reviewID, input)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.UpdateReviewResponse{
+		Response: successResponse("review updated"),
+		Review:   presenter.ToProtoReview(updated),
+	}), nil
+}
+
+func (h *ReviewHandler) DeleteReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.DeleteReviewRequest],
+) (*connect.Response[reviewv1.DeleteReviewResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	reviewID, err := uuid.Parse(req.Msg.GetReviewId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid review_id"))
+	}
+
+	if err := h.service.DeleteReview(ctx, userID, reviewID); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.DeleteReviewResponse{
+		Response: successResponse("review deleted"),
+	}), nil
+}
+
+// GetUserReviews lists the reviews written by a user. Callers looking at their own
+// reviews also see the ones that are pending or hidden by moderation.
+func (h *ReviewHandler) GetUserReviews(
+	ctx context.Context,
+	req *connect.Request[reviewv1.GetUserReviewsRequest],
+) (*connect.Response[reviewv1.GetUserReviewsResponse], error) {
+	viewerID, _ := interceptors.OptionalUserID(ctx)
+	userID := viewerID
+	if req.Msg.GetUserId() != "" {
+		parsed, err := uuid.Parse(req.Msg.GetUserId())
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid user_id"))
+		}
+		userID = parsed
+	}
+	if userID == uuid.Nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("user_id is required"))
+	}
+
+	page, pageSize := paginationParams(req.Msg.GetPagination())
+	filter := presenter.FromFilter(req.Msg.GetFilter())
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	reviews, total, err := h.service.GetUserReviews(ctx, userID, viewerID, filter)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	stats, err := h.service.GetUserStatistics(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.GetUserReviewsResponse{
+		Reviews:    presenter.ToProtoReviews(reviews),
+		Pagination: discoverpresenter.ToPaginationMetadata(total, page, pageSize),
+		Statistics: presenter.ToProtoUserStatistics(stats),
+	}), nil
+}
+
+// LikeReview marks a review as helpful, or withdraws the vote when is_like is false.
+func (h *ReviewHandler) LikeReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.LikeReviewRequest],
+) (*connect.Response[reviewv1.LikeReviewResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	reviewID, err := uuid.Parse(req.Msg.GetReviewId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid review_id"))
+	}
+
+	var vote *bool
+	if req.Msg.GetIsLike() {
+		helpful := true
+		vote = &helpful
+	}
+	count, err := h.service.SetHelpfulVote(ctx, userID, reviewID, vote)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.LikeReviewResponse{
+		Response:        successResponse("vote recorded"),
+		NewHelpfulCount: int32(count),
+	}), nil
+}
+
+func (h *ReviewHandler) ReportReview(
+	ctx context.Context,
+	req *connect.Request[reviewv1.ReportReviewRequest],
+) (*connect.Response[reviewv1.ReportReviewResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	reviewID, err := uuid.Parse(req.Msg.GetReviewId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid review_id"))
+	}
+
+	if err := h.service.ReportReview(ctx, userID, reviewID, req.Msg.GetReason(), req.Msg.GetDetails()); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.ReportReviewResponse{
+		Response: successResponse("review reported"),
+	}), nil
+}
+
+func (h *ReviewHandler) GetReviewStatistics(
+	ctx context.Context,
+	req *connect.Request[reviewv1.GetReviewStatisticsRequest],
+) (*connect.Response[reviewv1.GetReviewStatisticsResponse], error) {
+	poiID, err := uuid.Parse(req.Msg.GetPoiId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid poi_id"))
+	}
+
+	stats, err := h.service.GetPOIStatistics(ctx, poiID, req.Msg.GetIncludeTrends())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&reviewv1.GetReviewStatisticsResponse{
+		Statistics: presenter.ToProtoStatistics(stats),
+	}), nil
+}
+
+func (h *ReviewHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrConflict):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, locitypes.ErrUnauthenticated):
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("review request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+func paginationParams(p *commonpb.PaginationRequest) (page, pageSize int) {
+	page = 1
+	pageSize = defaultPageSize
+	if p != nil {
+		if p.GetPage() > 0 {
+			page = int(p.GetPage())
+		}
+		if p.GetPageSize() > 0 {
+			pageSize = int(p.GetPageSize())
+		}
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+func successResponse(message string) *commonpb.Response {
+	return &commonpb.Response{Success: true, Message: &message}
+}
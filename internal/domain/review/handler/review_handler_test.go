@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	reviewv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1/reviewv1connect"
+	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/review"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	review.Service
+	review     *locitypes.Review
+	reviews    []locitypes.Review
+	total      int
+	stats      *locitypes.ReviewStatistics
+	err        error
+	lastUserID uuid.UUID
+	lastInput  locitypes.ReviewInput
+	lastFilter locitypes.ReviewFilter
+	lastVote   *bool
+	lastStatus locitypes.ReviewStatus
+}
+
+func (s *stubService) CreateReview(_ context.Context, userID, poiID uuid.UUID, input locitypes.ReviewInput) (*locitypes.Review, error) {
+	s.lastUserID = userID
+	s.lastInput = input
+	if s.err != nil {
+		return nil, s.err
+	}
+	return locitypes.NewReview(userID, poiID, input.Rating, input.Title, input.Content), nil
+}
+
+func (s *stubService) GetReview(_ context.Context, _, viewerID uuid.UUID) (*locitypes.Review, error) {
+	s.lastUserID = viewerID
+	return s.review, s.err
+}
+
+func (s *stubService) GetPOIReviews(_ context.Context, _ uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error) {
+	s.lastFilter = filter
+	return s.reviews, s.total, s.err
+}
+
+func (s *stubService) GetPOIStatistics(_ context.Context, poiID uuid.UUID, _ bool) (*locitypes.ReviewStatistics, error) {
+	if s.stats != nil {
+		return s.stats, nil
+	}
+	return &locitypes.ReviewStatistics{POIID: poiID}, nil
+}
+
+func (s *stubService) SetHelpfulVote(_ context.Context, userID, _ uuid.UUID, isHelpful *bool) (int, error) {
+	s.lastUserID = userID
+	s.lastVote = isHelpful
+	return 7, s.err
+}
+
+func (s *stubService) ModerateReview(_ context.Context, _ uuid.UUID, status locitypes.ReviewStatus) error {
+	s.lastStatus = status
+	return s.err
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func TestCreateReview_RequiresAuthentication(t *testing.T) {
+	h := NewReviewHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.CreateReview(context.Background(), connect.NewRequest(&reviewv1.CreateReviewRequest{PoiId: uuid.NewString(), Rating: 4}))
+	require.Error(t, err)
+	require.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+func TestCreateReview_RoundsRatingAndMapsAspects(t *testing.T) {
+	svc := &stubService{}
+	h := NewReviewHandler(svc, testutil.NewLogger())
+	userID := uuid.New()
+
+	resp, err := h.CreateReview(authedContext(userID), connect.NewRequest(&reviewv1.CreateReviewRequest{
+		PoiId:   uuid.NewString(),
+		Rating:  4.6,
+		Content: "Great pastéis de nata",
+		Aspects: &reviewv1.ReviewAspects{FoodRating: 5},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, userID, svc.lastUserID)
+	require.Equal(t, 5, svc.lastInput.Rating)
+	require.Equal(t, float64(5), svc.lastInput.Aspects.Food)
+	require.True(t, resp.Msg.GetResponse().GetSuccess())
+	require.Equal(t, reviewv1.ReviewStatus_REVIEW_STATUS_PUBLISHED, resp.Msg.GetReview().GetStatus())
+}
+
+func TestCreateReview_MapsServiceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected connect.Code
+	}{
+		{name: "duplicate", err: fmt.Errorf("user has already reviewed this poi: %w", locitypes.ErrConflict), expected: connect.CodeAlreadyExists},
+		{name: "invalid", err: fmt.Errorf("rating must be between 1 and 5: %w", locitypes.ErrBadRequest), expected: connect.CodeInvalidArgument},
+		{name: "missing poi", err: fmt.Errorf("poi: %w", locitypes.ErrNotFound), expected: connect.CodeNotFound},
+		{name: "unexpected", err: fmt.Errorf("connection reset"), expected: connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewReviewHandler(&stubService{err: tt.err}, testutil.NewLogger())
+
+			_, err := h.CreateReview(authedContext(uuid.New()), connect.NewRequest(&reviewv1.CreateReviewRequest{
+				PoiId:   uuid.NewString(),
+				Rating:  3,
+				Content: "ok",
+			}))
+			require.Error(t, err)
+			require.Equal(t, tt.expected, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestGetReview_OnlyAuthorCanEdit(t *testing.T) {
+	authorID := uuid.New()
+	svc := &stubService{review: locitypes.NewReview(authorID, uuid.New(), 4, "", "Lovely view")}
+	h := NewReviewHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetReview(authedContext(authorID), connect.NewRequest(&reviewv1.GetReviewRequest{ReviewId: svc.review.ID.String()}))
+	require.NoError(t, err)
+	require.True(t, resp.Msg.GetCanEdit())
+
+	resp, err = h.GetReview(context.Background(), connect.NewRequest(&reviewv1.GetReviewRequest{ReviewId: svc.review.ID.String()}))
+	require.NoError(t, err)
+	require.False(t, resp.Msg.GetCanEdit())
+	require.Equal(t, uuid.Nil, svc.lastUserID)
+}
+
+func TestGetPOIReviews_PaginatesAndFilters(t *testing.T) {
+	svc := &stubService{
+		reviews: []locitypes.Review{*locitypes.NewReview(uuid.New(), uuid.New(), 5, "", "Superb")},
+		total:   25,
+		stats:   &locitypes.ReviewStatistics{AverageRating: 4.26, TotalReviews: 25},
+	}
+	h := NewReviewHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetPOIReviews(context.Background(), connect.NewRequest(&reviewv1.GetPOIReviewsRequest{
+		PoiId:      uuid.NewString(),
+		Pagination: &commonpb.PaginationRequest{Page: 2, PageSize: 10},
+		Filter: &reviewv1.ReviewFilter{
+			RatingFilters: []float64{5},
+			SortBy:        reviewv1.ReviewSortBy_REVIEW_SORT_BY_HELPFUL,
+		},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 10, svc.lastFilter.Limit)
+	require.Equal(t, 10, svc.lastFilter.Offset)
+	require.Equal(t, []int{5}, svc.lastFilter.Ratings)
+	require.Equal(t, "helpful", svc.lastFilter.SortBy)
+	require.Len(t, resp.Msg.GetReviews(), 1)
+	require.Equal(t, int32(3), resp.Msg.GetPagination().GetTotalPages())
+	require.True(t, resp.Msg.GetPagination().GetHasMore())
+	require.InDelta(t, 4.3, resp.Msg.GetStatistics().GetOverallRating(), 0.001)
+}
+
+func TestLikeReview_UnlikeClearsVote(t *testing.T) {
+	svc := &stubService{}
+	h := NewReviewHandler(svc, testutil.NewLogger())
+
+	resp, err := h.LikeReview(authedContext(uuid.New()), connect.NewRequest(&reviewv1.LikeReviewRequest{
+		ReviewId: uuid.NewString(),
+		IsLike:   false,
+	}))
+	require.NoError(t, err)
+	require.Nil(t, svc.lastVote)
+	require.Equal(t, int32(7), resp.Msg.GetNewHelpfulCount())
+}
+
+func TestReviewE2E_CreateReview(t *testing.T) {
+	userID := uuid.New()
+	handler := NewReviewHandler(&stubService{}, testutil.NewLogger())
+
+	// Stand-in for the auth interceptor: trust the test token as the user ID.
+	auth := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, req.Header().Get("X-Test-User")), req)
+		}
+	})
+
+	mux := http.NewServeMux()
+	path, h := reviewv1connect.NewReviewServiceHandler(handler, connect.WithInterceptors(auth))
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := reviewv1connect.NewReviewServiceClient(server.Client(), server.URL)
+	req := connect.NewRequest(&reviewv1.CreateReviewRequest{
+		PoiId:   uuid.NewString(),
+		Rating:  4,
+		Title:   "Worth the queue",
+		Content: "Best custard tarts in Belém",
+	})
+	req.Header().Set("X-Test-User", userID.String())
+
+	resp, err := client.CreateReview(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, "Worth the queue", resp.Msg.GetReview().GetTitle())
+	require.Equal(t, userID.String(), resp.Msg.GetReview().GetUserId())
+	require.WithinDuration(t, time.Now(), resp.Msg.GetReview().GetCreatedAt().AsTime(), time.Minute)
+}
+
+func TestModerateReview_RequiresModerator(t *testing.T) {
+	svc := &stubService{}
+	h := NewReviewHandler(svc, testutil.NewLogger())
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/moderation/reviews/{id}", h.ModerateReview)
+
+	moderate := func(role string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/moderation/reviews/"+uuid.NewString(), strings.NewReader(`{"status":"hidden"}`))
+		req = req.WithContext(interceptors.ContextWithClaims(req.Context(), &interceptors.Claims{UserID: uuid.NewString(), Role: role}))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, moderate("user"))
+	require.Empty(t, svc.lastStatus)
+
+	require.Equal(t, http.StatusNoContent, moderate("moderator"))
+	require.Equal(t, locitypes.ReviewStatusHidden, svc.lastStatus)
+
+	svc.err = fmt.Errorf("review: %w", locitypes.ErrNotFound)
+	require.Equal(t, http.StatusNotFound, moderate("admin"))
+}
@@ -0,0 +1,260 @@
+package presenter
+
+import (
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	reviewv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/review/v1"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+var languageNames = map[string]string{
+	"en": "English",
+	"pt": "Portuguese",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"nl": "Dutch",
+}
+
+// FromRating rounds a proto rating to the whole stars stored in the database.
+func FromRating(rating float64) int {
+	return int(math.Round(rating))
+}
+
+func FromAspects(a *reviewv1.ReviewAspects) locitypes.ReviewAspects {
+	if a == nil {
+		return locitypes.ReviewAspects{}
+	}
+	return locitypes.ReviewAspects{
+		Service:     a.GetServiceRating(),
+		Quality:     a.GetQualityRating(),
+		Value:       a.GetValueRating(),
+		Atmosphere:  a.GetAtmosphereRating(),
+		Cleanliness: a.GetCleanlinessRating(),
+		Location:    a.GetLocationRating(),
+		Food:        a.GetFoodRating(),
+		Room:        a.GetRoomRating(),
+		Amenities:   a.GetAmenitiesRating(),
+		Staff:       a.GetStaffRating(),
+	}
+}
+
+// FromFilter maps the proto filter to the domain filter; limit and offset are set by the caller.
+func FromFilter(f *reviewv1.ReviewFilter) locitypes.ReviewFilter {
+	var filter locitypes.ReviewFilter
+	if f == nil {
+		return filter
+	}
+	for _, r := range f.GetRatingFilters() {
+		filter.Ratings = append(filter.Ratings, FromRating(r))
+	}
+	if f.GetStartDate() != nil {
+		t := f.GetStartDate().AsTime()
+		filter.StartDate = &t
+	}
+	if f.GetEndDate() != nil {
+		t := f.GetEndDate().AsTime()
+		filter.EndDate = &t
+	}
+	filter.Languages = f.GetLanguages()
+	filter.VerifiedOnly = f.GetVerifiedOnly()
+	filter.WithPhotosOnly = f.GetWithPhotosOnly()
+	filter.Keywords = f.GetKeywords()
+
+	switch f.GetSortBy() {
+	case reviewv1.ReviewSortBy_REVIEW_SORT_BY_RATING:
+		filter.SortBy = "rating"
+	case reviewv1.ReviewSortBy_REVIEW_SORT_BY_HELPFUL, reviewv1.ReviewSortBy_REVIEW_SORT_BY_RELEVANCE:
+		filter.SortBy = "helpful"
+	default:
+		filter.SortBy = "date"
+	}
+	filter.SortAscending = f.GetSortDirection() == reviewv1.SortDirection_SORT_DIRECTION_ASC
+	return filter
+}
+
+func ToStatus(s locitypes.ReviewStatus) reviewv1.ReviewStatus {
+	switch s {
+	case locitypes.ReviewStatusPending:
+		return reviewv1.ReviewStatus_REVIEW_STATUS_PENDING
+	case locitypes.ReviewStatusPublished:
+		return reviewv1.ReviewStatus_REVIEW_STATUS_PUBLISHED
+	case locitypes.ReviewStatusHidden:
+		return reviewv1.ReviewStatus_REVIEW_STATUS_HIDDEN
+	case locitypes.ReviewStatusFlagged:
+		return reviewv1.ReviewStatus_REVIEW_STATUS_FLAGGED
+	default:
+		return reviewv1.ReviewStatus_REVIEW_STATUS_UNSPECIFIED
+	}
+}
+
+// ReviewerLevel derives the badge shown next to a reviewer from their published review count.
+func ReviewerLevel(reviewCount int) string {
+	switch {
+	case reviewCount < 5:
+		return "Bronze"
+	case reviewCount < 20:
+		return "Silver"
+	case reviewCount < 50:
+		return "Gold"
+	default:
+		return "Platinum"
+	}
+}
+
+func ToProtoReview(r *locitypes.Review) *reviewv1.Review {
+	if r == nil {
+		return nil
+	}
+	pb := &reviewv1.Review{
+		Id:           r.ID.String(),
+		UserId:       r.UserID.String(),
+		PoiId:        r.POIID.String(),
+		Rating:       float64(r.Rating),
+		Title:        r.Title,
+		Content:      r.Content,
+		Photos:       r.ImageURLs,
+		Status:       ToStatus(r.Status),
+		CreatedAt:    timestamppb.New(r.CreatedAt),
+		UpdatedAt:    timestamppb.New(r.UpdatedAt),
+		HelpfulCount: int32(r.Helpful),
+		ReportCount:  int32(r.ReportCount),
+		IsVerified:   r.IsVerified,
+		Language:     r.Language,
+		Aspects: &reviewv1.ReviewAspects{
+			ServiceRating:     r.Aspects.Service,
+			QualityRating:     r.Aspects.Quality,
+			ValueRating:       r.Aspects.Value,
+			AtmosphereRating:  r.Aspects.Atmosphere,
+			CleanlinessRating: r.Aspects.Cleanliness,
+			LocationRating:    r.Aspects.Location,
+			FoodRating:        r.Aspects.Food,
+			RoomRating:        r.Aspects.Room,
+			AmenitiesRating:   r.Aspects.Amenities,
+			StaffRating:       r.Aspects.Staff,
+		},
+	}
+	if r.VisitDate != nil {
+		pb.VisitDate = timestamppb.New(*r.VisitDate)
+	}
+	if r.Reviewer != nil {
+		pb.Reviewer = &reviewv1.ReviewerInfo{
+			UserId:      r.Reviewer.UserID.String(),
+			DisplayName: r.Reviewer.DisplayName,
+			AvatarUrl:   r.Reviewer.AvatarURL,
+			ReviewCount: int32(r.Reviewer.ReviewCount),
+			Level:       ReviewerLevel(r.Reviewer.ReviewCount),
+			MemberSince: timestamppb.New(r.Reviewer.MemberSince),
+		}
+	}
+	if r.OfficialReply != nil {
+		pb.BusinessResponse = &reviewv1.BusinessResponse{
+			Id:             r.OfficialReply.ID.String(),
+			BusinessUserId: r.OfficialReply.UserID.String(),
+			Content:        r.OfficialReply.Content,
+			CreatedAt:      timestamppb.New(r.OfficialReply.CreatedAt),
+		}
+	}
+	return pb
+}
+
+func ToProtoReviews(reviews []locitypes.Review) []*reviewv1.Review {
+	out := make([]*reviewv1.Review, 0, len(reviews))
+	for i := range reviews {
+		out = append(out, ToProtoReview(&reviews[i]))
+	}
+	return out
+}
+
+func ToProtoStatistics(s *locitypes.ReviewStatistics) *reviewv1.ReviewStatistics {
+	if s == nil {
+		return nil
+	}
+	pb := &reviewv1.ReviewStatistics{
+		PoiId:         s.POIID.String(),
+		OverallRating: math.Round(s.AverageRating*10) / 10,
+		TotalReviews:  int32(s.TotalReviews),
+		RatingBreakdown: &reviewv1.RatingBreakdown{
+			OneStar:   int32(s.Breakdown.OneStar),
+			TwoStar:   int32(s.Breakdown.TwoStar),
+			ThreeStar: int32(s.Breakdown.ThreeStar),
+			FourStar:  int32(s.Breakdown.FourStar),
+			FiveStar:  int32(s.Breakdown.FiveStar),
+		},
+		AspectAverages: &reviewv1.ReviewAspectAverages{
+			ServiceAverage:     s.AspectAverages.Service,
+			QualityAverage:     s.AspectAverages.Quality,
+			ValueAverage:       s.AspectAverages.Value,
+			AtmosphereAverage:  s.AspectAverages.Atmosphere,
+			CleanlinessAverage: s.AspectAverages.Cleanliness,
+			LocationAverage:    s.AspectAverages.Location,
+			FoodAverage:        s.AspectAverages.Food,
+			RoomAverage:        s.AspectAverages.Room,
+			AmenitiesAverage:   s.AspectAverages.Amenities,
+			StaffAverage:       s.AspectAverages.Staff,
+		},
+		LanguageDistribution: &reviewv1.LanguageDistribution{},
+	}
+	if !s.LastUpdated.IsZero() {
+		pb.LastUpdated = timestamppb.New(s.LastUpdated)
+	} else {
+		pb.LastUpdated = timestamppb.New(time.Now())
+	}
+
+	for _, lc := range s.Languages {
+		name := languageNames[lc.Language]
+		if name == "" {
+			name = lc.Language
+		}
+		var pct float64
+		if s.TotalReviews > 0 {
+			pct = float64(lc.Count) * 100 / float64(s.TotalReviews)
+		}
+		pb.LanguageDistribution.Languages = append(pb.LanguageDistribution.Languages, &reviewv1.LanguageCount{
+			LanguageCode: lc.Language,
+			LanguageName: name,
+			Count:        int32(lc.Count),
+			Percentage:   pct,
+		})
+	}
+
+	if t := s.Trends; t != nil {
+		trends := &reviewv1.RecentReviewTrends{
+			ReviewsLast_30Days:       int32(t.ReviewsLast30Days),
+			AverageRatingLast_30Days: t.AverageRatingLast30Days,
+		}
+		// Only compare windows that both have reviews; an empty window averages to 0.
+		if t.AverageRatingLast30Days > 0 && t.AverageRatingPrevious30d > 0 {
+			trends.RatingTrend = t.AverageRatingLast30Days - t.AverageRatingPrevious30d
+			trends.RatingChangePercentage = trends.RatingTrend / t.AverageRatingPrevious30d * 100
+		}
+		for _, m := range t.Monthly {
+			trends.MonthlyData = append(trends.MonthlyData, &reviewv1.MonthlyReviewData{
+				Year:          int32(m.Year),
+				Month:         int32(m.Month),
+				ReviewCount:   int32(m.ReviewCount),
+				AverageRating: m.AverageRating,
+			})
+		}
+		pb.Trends = trends
+	}
+	return pb
+}
+
+func ToProtoUserStatistics(s *locitypes.UserReviewStatistics) *reviewv1.UserReviewStatistics {
+	if s == nil {
+		return nil
+	}
+	return &reviewv1.UserReviewStatistics{
+		TotalReviews:          int32(s.TotalReviews),
+		AverageRatingGiven:    s.AverageRatingGiven,
+		HelpfulVotesReceived:  int32(s.HelpfulVotesReceived),
+		ReviewerLevel:         ReviewerLevel(s.TotalReviews),
+		TopCategoriesReviewed: s.TopCategories,
+	}
+}
@@ -55,8 +55,8 @@ func clearReviewTables(t *testing.T) {
 	t.Helper()
 	_, err := testReviewDB.Exec(context.Background(), "DELETE FROM review_replies")
 	require.NoError(t, err, "Failed to clear review_replies table")
-	_, err = testReviewDB.Exec(context.Background(), "DELETE FROM review_helpful")
-	require.NoError(t, err, "Failed to clear review_helpful table")
+	_, err = testReviewDB.Exec(context.Background(), "DELETE FROM review_helpfuls")
+	require.NoError(t, err, "Failed to clear review_helpfuls table")
 	_, err = testReviewDB.Exec(context.Background(), "DELETE FROM reviews")
 	require.NoError(t, err, "Failed to clear reviews table")
 }
@@ -66,7 +66,7 @@ func createTestUserForReview(t *testing.T) uuid.UUID {
 	userID := uuid.New()
 	_, err := testReviewDB.Exec(context.Background(),
 		"INSERT INTO users (id, username, email, password_hash) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING",
-		userID, "reviewuser", "reviewuser@test.com", "hash")
+		userID, "reviewuser-"+userID.String()[:8], "reviewuser-"+userID.String()[:8]+"@test.com", "hash")
 	require.NoError(t, err)
 	return userID
 }
@@ -84,8 +84,8 @@ func createTestPOIForReview(t *testing.T) uuid.UUID {
 
 	// Create POI
 	_, err = testReviewDB.Exec(context.Background(),
-		"INSERT INTO pois (id, city_id, name, latitude, longitude, location, category) VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($6, $7), 4326), $8) ON CONFLICT (id) DO NOTHING",
-		poiID, cityID, "Test POI", 38.7223, -9.1393, -9.1393, 38.7223, "Test")
+		"INSERT INTO points_of_interest (id, city_id, name, location, category) VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6) ON CONFLICT (id) DO NOTHING",
+		poiID, cityID, "Test POI", -9.1393, 38.7223, "Test")
 	require.NoError(t, err)
 	return poiID
 }
@@ -127,8 +127,8 @@ func TestReviewModels_Integration(t *testing.T) {
 	})
 
 	t.Run("Create and save review helpful", func(t *testing.T) {
-		// Create a review first
-		review := locitypes.NewReview(userID, poiID, 4, "Good place", "Nice location")
+		// Create a review first (users can review a POI only once)
+		review := locitypes.NewReview(userID, createTestPOIForReview(t), 4, "Good place", "Nice location")
 		query := `
 			INSERT INTO reviews (id, user_id, poi_id, rating, title, content, helpful, unhelpful, is_verified, is_published, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
@@ -145,7 +145,7 @@ func TestReviewModels_Integration(t *testing.T) {
 
 		// Insert review helpful record
 		helpfulQuery := `
-			INSERT INTO review_helpful (user_id, review_id, is_helpful, created_at)
+			INSERT INTO review_helpfuls (user_id, review_id, is_helpful, created_at)
 			VALUES ($1, $2, $3, $4)
 		`
 		_, err = testReviewDB.Exec(ctx, helpfulQuery,
@@ -154,7 +154,7 @@ func TestReviewModels_Integration(t *testing.T) {
 
 		// Verify review helpful was saved
 		var dbIsHelpful bool
-		err = testReviewDB.QueryRow(ctx, "SELECT is_helpful FROM review_helpful WHERE user_id = $1 AND review_id = $2",
+		err = testReviewDB.QueryRow(ctx, "SELECT is_helpful FROM review_helpfuls WHERE user_id = $1 AND review_id = $2",
 			reviewHelpful.UserID, reviewHelpful.ReviewID).Scan(&dbIsHelpful)
 		require.NoError(t, err)
 
@@ -162,8 +162,8 @@ func TestReviewModels_Integration(t *testing.T) {
 	})
 
 	t.Run("Create and save review reply", func(t *testing.T) {
-		// Create a review first
-		review := locitypes.NewReview(userID, poiID, 3, "Average place", "It was okay")
+		// Create a review first (users can review a POI only once)
+		review := locitypes.NewReview(userID, createTestPOIForReview(t), 3, "Average place", "It was okay")
 		query := `
 			INSERT INTO reviews (id, user_id, poi_id, rating, title, content, helpful, unhelpful, is_verified, is_published, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
@@ -206,11 +206,11 @@ func TestReviewQueries_Integration(t *testing.T) {
 	userID := createTestUserForReview(t)
 	poiID := createTestPOIForReview(t)
 
-	// Create multiple reviews for testing
+	// Create multiple reviews for testing, one per user
 	reviews := []*locitypes.Review{
 		locitypes.NewReview(userID, poiID, 5, "Excellent!", "Perfect place"),
-		locitypes.NewReview(userID, poiID, 4, "Very good", "Really enjoyed it"),
-		locitypes.NewReview(userID, poiID, 3, "Average", "It was okay"),
+		locitypes.NewReview(createTestUserForReview(t), poiID, 4, "Very good", "Really enjoyed it"),
+		locitypes.NewReview(createTestUserForReview(t), poiID, 3, "Average", "It was okay"),
 	}
 
 	// Insert reviews
@@ -267,7 +267,26 @@ func TestReviewQueries_Integration(t *testing.T) {
 		err := testReviewDB.QueryRow(ctx, "SELECT COUNT(*) FROM reviews WHERE user_id = $1", userID).Scan(&count)
 		require.NoError(t, err)
 
-		assert.Equal(t, 3, count)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("POI rating follows published reviews", func(t *testing.T) {
+		var avgRating float64
+		var ratingCount int
+		err := testReviewDB.QueryRow(ctx, "SELECT average_rating, rating_count FROM points_of_interest WHERE id = $1", poiID).
+			Scan(&avgRating, &ratingCount)
+		require.NoError(t, err)
+		assert.InDelta(t, 4.0, avgRating, 0.01)
+		assert.Equal(t, 3, ratingCount)
+
+		repo := NewRepository(testReviewDB, slog.Default())
+		require.NoError(t, repo.UpdateReviewStatus(ctx, reviews[2].ID, locitypes.ReviewStatusFlagged))
+
+		err = testReviewDB.QueryRow(ctx, "SELECT average_rating, rating_count FROM points_of_interest WHERE id = $1", poiID).
+			Scan(&avgRating, &ratingCount)
+		require.NoError(t, err)
+		assert.InDelta(t, 4.5, avgRating, 0.01)
+		assert.Equal(t, 2, ratingCount)
 	})
 }
 
@@ -290,9 +309,16 @@ func TestReviewConstraints_Integration(t *testing.T) {
 			review.Helpful, review.Unhelpful, review.IsVerified, review.IsPublished, review.CreatedAt, review.UpdatedAt)
 		require.NoError(t, err)
 
+		// A second review of the same POI by the same user is rejected
+		repo := NewRepository(testReviewDB, slog.Default())
+		duplicate := locitypes.NewReview(userID, poiID, 4, "Changed my mind", "Content")
+		err = repo.CreateReview(ctx, duplicate)
+		require.ErrorIs(t, err, locitypes.ErrConflict)
+
 		// Test invalid rating (if constraints exist in DB schema)
-		invalidReview := locitypes.NewReview(userID, poiID, 10, "Invalid rating", "Content") // Assuming rating should be 1-5
-		invalidReview.ID = uuid.New()                                                        // Different ID
+		otherUserID := createTestUserForReview(t)
+		invalidReview := locitypes.NewReview(otherUserID, poiID, 10, "Invalid rating", "Content") // Assuming rating should be 1-5
+		invalidReview.ID = uuid.New()                                                             // Different ID
 		_, err = testReviewDB.Exec(ctx, query,
 			invalidReview.ID, invalidReview.UserID, invalidReview.POIID, invalidReview.Rating, invalidReview.Title, invalidReview.Content,
 			invalidReview.Helpful, invalidReview.Unhelpful, invalidReview.IsVerified, invalidReview.IsPublished, invalidReview.CreatedAt, invalidReview.UpdatedAt)
@@ -1 +1,749 @@
 package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+var _ Repository = (*RepositoryImpl)(nil)
+
+// Repository persists reviews, helpful votes, replies and reports. The POI's
+// average_rating/rating_count are kept in sync by the update_poi_rating trigger
+// (migration 0006), which only counts rows with is_published = TRUE.
+type Repository interface {
+	POIExists(ctx context.Context, poiID uuid.UUID) (bool, error)
+
+	CreateReview(ctx context.Context, review *locitypes.Review) error
+	GetReview(ctx context.Context, reviewID uuid.UUID) (*locitypes.Review, error)
+	UpdateReview(ctx context.Context, review *locitypes.Review) error
+	DeleteReview(ctx context.Context, reviewID uuid.UUID) error
+	UpdateReviewStatus(ctx context.Context, reviewID uuid.UUID, status locitypes.ReviewStatus) error
+
+	// ListPOIReviews returns a page of published reviews and the total matching count.
+	ListPOIReviews(ctx context.Context, poiID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error)
+	// ListUserReviews returns a page of a user's reviews; unpublished ones only when includeUnpublished.
+	ListUserReviews(ctx context.Context, userID uuid.UUID, filter locitypes.ReviewFilter, includeUnpublished bool) ([]locitypes.Review, int, error)
+
+	// SetHelpfulVote records or clears (isHelpful == nil) a user's vote and returns the new helpful count.
+	SetHelpfulVote(ctx context.Context, userID, reviewID uuid.UUID, isHelpful *bool) (int, error)
+	// AddReport records a report and returns the review's new report count.
+	AddReport(ctx context.Context, report locitypes.ReviewReport) (int, error)
+
+	CreateReply(ctx context.Context, reply *locitypes.ReviewReply) error
+	GetReplies(ctx context.Context, reviewID uuid.UUID) ([]locitypes.ReviewReply, error)
+
+	GetPOIStatistics(ctx context.Context, poiID uuid.UUID, includeTrends bool) (*locitypes.ReviewStatistics, error)
+	GetUserStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.UserReviewStatistics, error)
+}
+
+type RepositoryImpl struct {
+	logger *slog.Logger
+	pgpool *pgxpool.Pool
+}
+
+func NewRepository(pgpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		logger: logger,
+		pgpool: pgpool,
+	}
+}
+
+const reviewColumns = `
+	r.id, r.user_id, r.poi_id, r.rating, COALESCE(r.title, ''), r.content, r.visit_date,
+	COALESCE(r.image_urls, '{}'), r.helpful, r.unhelpful, r.report_count, r.is_verified,
+	r.is_published, r.status, COALESCE(r.language, ''), r.aspects, r.created_at, r.updated_at,
+	COALESCE(u.display_name, u.username::text, ''), COALESCE(u.profile_image_url, ''), u.created_at,
+	(SELECT COUNT(*) FROM reviews ur WHERE ur.user_id = r.user_id AND ur.status = 'published'),
+	reply.id, reply.user_id, reply.content, reply.created_at, reply.updated_at`
+
+const reviewJoins = `
+	FROM reviews r
+	JOIN users u ON u.id = r.user_id
+	LEFT JOIN LATERAL (
+		SELECT rr.id, rr.user_id, rr.content, rr.created_at, rr.updated_at
+		FROM review_replies rr
+		WHERE rr.review_id = r.id AND rr.is_official = TRUE
+		ORDER BY rr.created_at DESC
+		LIMIT 1
+	) reply ON TRUE`
+
+func (r *RepositoryImpl) POIExists(ctx context.Context, poiID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pgpool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM points_of_interest WHERE id = $1)", poiID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check poi existence: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *RepositoryImpl) CreateReview(ctx context.Context, review *locitypes.Review) error {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "CreateReview", trace.WithAttributes(
+		attribute.String("review.id", review.ID.String()),
+		attribute.String("poi.id", review.POIID.String()),
+	))
+	defer span.End()
+
+	aspects, err := json.Marshal(review.Aspects)
+	if err != nil {
+		return fmt.Errorf("failed to encode review aspects: %w", err)
+	}
+
+	query := `
+		INSERT INTO reviews (id, user_id, poi_id, rating, title, content, visit_date, image_urls,
+		                     is_verified, is_published, status, language, aspects, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $15)
+	`
+	_, err = r.pgpool.Exec(ctx, query,
+		review.ID, review.UserID, review.POIID, review.Rating, review.Title, review.Content, review.VisitDate,
+		review.ImageURLs, review.IsVerified, review.Status == locitypes.ReviewStatusPublished, review.Status,
+		review.Language, aspects, review.CreatedAt, review.UpdatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to insert review")
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // Unique violation on (user_id, poi_id)
+				return fmt.Errorf("user has already reviewed this poi: %w", locitypes.ErrConflict)
+			case "23503": // Foreign key violation
+				return fmt.Errorf("poi %s: %w", review.POIID, locitypes.ErrNotFound)
+			}
+		}
+		r.logger.ErrorContext(ctx, "Failed to insert review", slog.Any("error", err))
+		return fmt.Errorf("failed to insert review: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review created")
+	return nil
+}
+
+func (r *RepositoryImpl) GetReview(ctx context.Context, reviewID uuid.UUID) (*locitypes.Review, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "GetReview", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	query := "SELECT " + reviewColumns + reviewJoins + " WHERE r.id = $1"
+	review, err := scanReview(r.pgpool.QueryRow(ctx, query, reviewID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.SetStatus(codes.Error, "Review not found")
+			return nil, fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query review")
+		return nil, fmt.Errorf("failed to query review: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review found")
+	return review, nil
+}
+
+func (r *RepositoryImpl) UpdateReview(ctx context.Context, review *locitypes.Review) error {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "UpdateReview", trace.WithAttributes(
+		attribute.String("review.id", review.ID.String()),
+	))
+	defer span.End()
+
+	aspects, err := json.Marshal(review.Aspects)
+	if err != nil {
+		return fmt.Errorf("failed to encode review aspects: %w", err)
+	}
+
+	query := `
+		UPDATE reviews
+		SET rating = $2, title = $3, content = $4, visit_date = $5, image_urls = $6, aspects = $7
+		WHERE id = $1
+	`
+	result, err := r.pgpool.Exec(ctx, query,
+		review.ID, review.Rating, review.Title, review.Content, review.VisitDate, review.ImageURLs, aspects)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to update review")
+		r.logger.ErrorContext(ctx, "Failed to update review", slog.Any("error", err))
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("review %s: %w", review.ID, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "Review updated")
+	return nil
+}
+
+func (r *RepositoryImpl) DeleteReview(ctx context.Context, reviewID uuid.UUID) error {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "DeleteReview", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	result, err := r.pgpool.Exec(ctx, "DELETE FROM reviews WHERE id = $1", reviewID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to delete review")
+		r.logger.ErrorContext(ctx, "Failed to delete review", slog.Any("error", err))
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "Review deleted")
+	return nil
+}
+
+// UpdateReviewStatus keeps is_published in step with the moderation status so the
+// rating trigger drops hidden or flagged reviews from the POI average.
+func (r *RepositoryImpl) UpdateReviewStatus(ctx context.Context, reviewID uuid.UUID, status locitypes.ReviewStatus) error {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "UpdateReviewStatus", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+		attribute.String("review.status", string(status)),
+	))
+	defer span.End()
+
+	query := `
+		UPDATE reviews
+		SET status = $2, is_published = $3, moderated_at = NOW()
+		WHERE id = $1
+	`
+	result, err := r.pgpool.Exec(ctx, query, reviewID, status, status == locitypes.ReviewStatusPublished)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to update review status")
+		return fmt.Errorf("failed to update review status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "Review status updated")
+	return nil
+}
+
+func (r *RepositoryImpl) ListPOIReviews(ctx context.Context, poiID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "ListPOIReviews", trace.WithAttributes(
+		attribute.String("poi.id", poiID.String()),
+	))
+	defer span.End()
+
+	reviews, total, err := r.listReviews(ctx, "r.poi_id = $1 AND r.status = 'published'", poiID, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list poi reviews")
+		return nil, 0, err
+	}
+	span.SetStatus(codes.Ok, "POI reviews listed")
+	return reviews, total, nil
+}
+
+func (r *RepositoryImpl) ListUserReviews(ctx context.Context, userID uuid.UUID, filter locitypes.ReviewFilter, includeUnpublished bool) ([]locitypes.Review, int, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "ListUserReviews", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.Bool("include_unpublished", includeUnpublished),
+	))
+	defer span.End()
+
+	where := "r.user_id = $1"
+	if !includeUnpublished {
+		where += " AND r.status = 'published'"
+	}
+	reviews, total, err := r.listReviews(ctx, where, userID, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list user reviews")
+		return nil, 0, err
+	}
+	span.SetStatus(codes.Ok, "User reviews listed")
+	return reviews, total, nil
+}
+
+// listReviews applies the filter on top of a base condition whose only argument is $1.
+func (r *RepositoryImpl) listReviews(ctx context.Context, where string, id uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error) {
+	args := []interface{}{id}
+	argIndex := 2
+	conditions := []string{where}
+
+	if len(filter.Ratings) > 0 {
+		conditions = append(conditions, fmt.Sprintf("r.rating = ANY($%d)", argIndex))
+		args = append(args, filter.Ratings)
+		argIndex++
+	}
+	if filter.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", argIndex))
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("r.created_at <= $%d", argIndex))
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+	if len(filter.Languages) > 0 {
+		conditions = append(conditions, fmt.Sprintf("r.language = ANY($%d)", argIndex))
+		args = append(args, filter.Languages)
+		argIndex++
+	}
+	if filter.VerifiedOnly {
+		conditions = append(conditions, "r.is_verified = TRUE")
+	}
+	if filter.WithPhotosOnly {
+		conditions = append(conditions, "cardinality(r.image_urls) > 0")
+	}
+	if len(filter.Keywords) > 0 {
+		patterns := make([]string, len(filter.Keywords))
+		for i, kw := range filter.Keywords {
+			patterns[i] = "%" + kw + "%"
+		}
+		conditions = append(conditions, fmt.Sprintf("(r.title ILIKE ANY($%d) OR r.content ILIKE ANY($%d))", argIndex, argIndex))
+		args = append(args, patterns)
+		argIndex++
+	}
+
+	direction := "DESC"
+	if filter.SortAscending {
+		direction = "ASC"
+	}
+	orderBy := "r.created_at " + direction
+	switch filter.SortBy {
+	case "rating":
+		orderBy = fmt.Sprintf("r.rating %s, r.created_at DESC", direction)
+	case "helpful":
+		orderBy = fmt.Sprintf("r.helpful %s, r.created_at DESC", direction)
+	}
+
+	query := fmt.Sprintf("SELECT %s, COUNT(*) OVER() %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d",
+		reviewColumns, reviewJoins, strings.Join(conditions, " AND "), orderBy, argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.pgpool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to query reviews", slog.Any("error", err))
+		return nil, 0, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		reviews []locitypes.Review
+		total   int
+	)
+	for rows.Next() {
+		review, err := scanReview(rows, &total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, total, nil
+}
+
+func (r *RepositoryImpl) SetHelpfulVote(ctx context.Context, userID, reviewID uuid.UUID, isHelpful *bool) (int, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "SetHelpfulVote", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			r.logger.ErrorContext(ctx, "Failed to rollback transaction", slog.Any("error", rollbackErr))
+		}
+	}()
+
+	if isHelpful == nil {
+		_, err = tx.Exec(ctx, "DELETE FROM review_helpfuls WHERE user_id = $1 AND review_id = $2", userID, reviewID)
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO review_helpfuls (user_id, review_id, is_helpful)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, review_id) DO UPDATE SET is_helpful = EXCLUDED.is_helpful
+		`, userID, reviewID, *isHelpful)
+	}
+	if err != nil {
+		span.RecordError(err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return 0, fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+		}
+		return 0, fmt.Errorf("failed to record helpful vote: %w", err)
+	}
+
+	var helpful int
+	err = tx.QueryRow(ctx, `
+		UPDATE reviews
+		SET helpful = (SELECT COUNT(*) FROM review_helpfuls WHERE review_id = $1 AND is_helpful = TRUE),
+		    unhelpful = (SELECT COUNT(*) FROM review_helpfuls WHERE review_id = $1 AND is_helpful = FALSE)
+		WHERE id = $1
+		RETURNING helpful
+	`, reviewID).Scan(&helpful)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+		}
+		return 0, fmt.Errorf("failed to update helpful counts: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to commit helpful vote: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Helpful vote recorded")
+	return helpful, nil
+}
+
+func (r *RepositoryImpl) AddReport(ctx context.Context, report locitypes.ReviewReport) (int, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "AddReport", trace.WithAttributes(
+		attribute.String("review.id", report.ReviewID.String()),
+		attribute.String("report.reason", report.Reason),
+	))
+	defer span.End()
+
+	tx, err := r.pgpool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			r.logger.ErrorContext(ctx, "Failed to rollback transaction", slog.Any("error", rollbackErr))
+		}
+	}()
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO review_reports (id, review_id, user_id, reason, details, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		ON CONFLICT (review_id, user_id) DO NOTHING
+	`, report.ID, report.ReviewID, report.UserID, report.Reason, report.Details, report.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return 0, fmt.Errorf("review %s: %w", report.ReviewID, locitypes.ErrNotFound)
+		}
+		return 0, fmt.Errorf("failed to insert review report: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("review already reported by user: %w", locitypes.ErrConflict)
+	}
+
+	var reportCount int
+	err = tx.QueryRow(ctx, `
+		UPDATE reviews SET report_count = report_count + 1
+		WHERE id = $1
+		RETURNING report_count
+	`, report.ReviewID).Scan(&reportCount)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to update report count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to commit review report: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review reported")
+	return reportCount, nil
+}
+
+func (r *RepositoryImpl) CreateReply(ctx context.Context, reply *locitypes.ReviewReply) error {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "CreateReply", trace.WithAttributes(
+		attribute.String("review.id", reply.ReviewID.String()),
+	))
+	defer span.End()
+
+	_, err := r.pgpool.Exec(ctx, `
+		INSERT INTO review_replies (id, review_id, user_id, content, is_official, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, reply.ID, reply.ReviewID, reply.UserID, reply.Content, reply.IsOfficial, reply.CreatedAt, reply.UpdatedAt)
+	if err != nil {
+		span.RecordError(err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("review %s: %w", reply.ReviewID, locitypes.ErrNotFound)
+		}
+		return fmt.Errorf("failed to insert review reply: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Reply created")
+	return nil
+}
+
+func (r *RepositoryImpl) GetReplies(ctx context.Context, reviewID uuid.UUID) ([]locitypes.ReviewReply, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "GetReplies", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT id, review_id, user_id, content, is_official, created_at, updated_at
+		FROM review_replies
+		WHERE review_id = $1
+		ORDER BY created_at
+	`, reviewID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query review replies: %w", err)
+	}
+	defer rows.Close()
+
+	var replies []locitypes.ReviewReply
+	for rows.Next() {
+		var reply locitypes.ReviewReply
+		if err := rows.Scan(&reply.ID, &reply.ReviewID, &reply.UserID, &reply.Content, &reply.IsOfficial,
+			&reply.CreatedAt, &reply.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan review reply: %w", err)
+		}
+		replies = append(replies, reply)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review replies: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Replies fetched")
+	return replies, nil
+}
+
+func (r *RepositoryImpl) GetPOIStatistics(ctx context.Context, poiID uuid.UUID, includeTrends bool) (*locitypes.ReviewStatistics, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "GetPOIStatistics", trace.WithAttributes(
+		attribute.String("poi.id", poiID.String()),
+		attribute.Bool("include_trends", includeTrends),
+	))
+	defer span.End()
+
+	stats := &locitypes.ReviewStatistics{POIID: poiID}
+	var lastUpdated *time.Time
+	err := r.pgpool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(AVG(rating), 0)::float8,
+			COUNT(*) FILTER (WHERE rating = 1),
+			COUNT(*) FILTER (WHERE rating = 2),
+			COUNT(*) FILTER (WHERE rating = 3),
+			COUNT(*) FILTER (WHERE rating = 4),
+			COUNT(*) FILTER (WHERE rating = 5),
+			COALESCE(AVG(NULLIF((aspects->>'service')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'quality')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'value')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'atmosphere')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'cleanliness')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'location')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'food')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'room')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'amenities')::float8, 0)), 0),
+			COALESCE(AVG(NULLIF((aspects->>'staff')::float8, 0)), 0),
+			MAX(updated_at)
+		FROM reviews
+		WHERE poi_id = $1 AND status = 'published'
+	`, poiID).Scan(
+		&stats.TotalReviews, &stats.AverageRating,
+		&stats.Breakdown.OneStar, &stats.Breakdown.TwoStar, &stats.Breakdown.ThreeStar,
+		&stats.Breakdown.FourStar, &stats.Breakdown.FiveStar,
+		&stats.AspectAverages.Service, &stats.AspectAverages.Quality, &stats.AspectAverages.Value,
+		&stats.AspectAverages.Atmosphere, &stats.AspectAverages.Cleanliness, &stats.AspectAverages.Location,
+		&stats.AspectAverages.Food, &stats.AspectAverages.Room, &stats.AspectAverages.Amenities,
+		&stats.AspectAverages.Staff, &lastUpdated,
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to query review statistics")
+		return nil, fmt.Errorf("failed to query review statistics: %w", err)
+	}
+	if lastUpdated != nil {
+		stats.LastUpdated = *lastUpdated
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT COALESCE(NULLIF(language, ''), 'unknown'), COUNT(*)
+		FROM reviews
+		WHERE poi_id = $1 AND status = 'published'
+		GROUP BY 1
+		ORDER BY 2 DESC
+	`, poiID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query review languages: %w", err)
+	}
+	for rows.Next() {
+		var lc locitypes.ReviewLanguageCount
+		if err := rows.Scan(&lc.Language, &lc.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan review language: %w", err)
+		}
+		stats.Languages = append(stats.Languages, lc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review languages: %w", err)
+	}
+
+	if includeTrends {
+		trends, err := r.getPOITrends(ctx, poiID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		stats.Trends = trends
+	}
+
+	span.SetStatus(codes.Ok, "Review statistics computed")
+	return stats, nil
+}
+
+func (r *RepositoryImpl) getPOITrends(ctx context.Context, poiID uuid.UUID) (*locitypes.ReviewTrends, error) {
+	trends := &locitypes.ReviewTrends{}
+	err := r.pgpool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days'),
+			COALESCE(AVG(rating) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days'), 0)::float8,
+			COALESCE(AVG(rating) FILTER (WHERE created_at >= NOW() - INTERVAL '60 days'
+			                               AND created_at < NOW() - INTERVAL '30 days'), 0)::float8
+		FROM reviews
+		WHERE poi_id = $1 AND status = 'published'
+	`, poiID).Scan(&trends.ReviewsLast30Days, &trends.AverageRatingLast30Days, &trends.AverageRatingPrevious30d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review trends: %w", err)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT EXTRACT(YEAR FROM created_at)::int, EXTRACT(MONTH FROM created_at)::int, COUNT(*), AVG(rating)::float8
+		FROM reviews
+		WHERE poi_id = $1 AND status = 'published'
+		  AND created_at >= date_trunc('month', NOW()) - INTERVAL '11 months'
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, poiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly review data: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m locitypes.MonthlyReviewData
+		if err := rows.Scan(&m.Year, &m.Month, &m.ReviewCount, &m.AverageRating); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly review data: %w", err)
+		}
+		trends.Monthly = append(trends.Monthly, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monthly review data: %w", err)
+	}
+	return trends, nil
+}
+
+func (r *RepositoryImpl) GetUserStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.UserReviewStatistics, error) {
+	ctx, span := otel.Tracer("ReviewRepository").Start(ctx, "GetUserStatistics", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	stats := &locitypes.UserReviewStatistics{}
+	err := r.pgpool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(AVG(rating), 0)::float8, COALESCE(SUM(helpful), 0)
+		FROM reviews
+		WHERE user_id = $1 AND status = 'published'
+	`, userID).Scan(&stats.TotalReviews, &stats.AverageRatingGiven, &stats.HelpfulVotesReceived)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query user review statistics: %w", err)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+		SELECT p.category
+		FROM reviews r
+		JOIN points_of_interest p ON p.id = r.poi_id
+		WHERE r.user_id = $1 AND r.status = 'published' AND p.category IS NOT NULL AND p.category <> ''
+		GROUP BY p.category
+		ORDER BY COUNT(*) DESC, p.category
+		LIMIT 3
+	`, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query reviewed categories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewed category: %w", err)
+		}
+		stats.TopCategories = append(stats.TopCategories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewed categories: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "User review statistics computed")
+	return stats, nil
+}
+
+// scanReview scans reviewColumns, plus any trailing destinations (e.g. a window count).
+func scanReview(row pgx.Row, extra ...interface{}) (*locitypes.Review, error) {
+	var (
+		review       locitypes.Review
+		aspects      []byte
+		reviewer     locitypes.ReviewerInfo
+		replyID      *uuid.UUID
+		replyUserID  *uuid.UUID
+		replyContent *string
+		replyCreated *time.Time
+		replyUpdated *time.Time
+	)
+	dest := []interface{}{
+		&review.ID, &review.UserID, &review.POIID, &review.Rating, &review.Title, &review.Content, &review.VisitDate,
+		&review.ImageURLs, &review.Helpful, &review.Unhelpful, &review.ReportCount, &review.IsVerified,
+		&review.IsPublished, &review.Status, &review.Language, &aspects, &review.CreatedAt, &review.UpdatedAt,
+		&reviewer.DisplayName, &reviewer.AvatarURL, &reviewer.MemberSince, &reviewer.ReviewCount,
+		&replyID, &replyUserID, &replyContent, &replyCreated, &replyUpdated,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if len(aspects) > 0 {
+		if err := json.Unmarshal(aspects, &review.Aspects); err != nil {
+			return nil, fmt.Errorf("failed to decode review aspects: %w", err)
+		}
+	}
+	reviewer.UserID = review.UserID
+	review.Reviewer = &reviewer
+	if replyID != nil {
+		review.OfficialReply = &locitypes.ReviewReply{
+			ID:         *replyID,
+			ReviewID:   review.ID,
+			UserID:     *replyUserID,
+			Content:    *replyContent,
+			IsOfficial: true,
+			CreatedAt:  *replyCreated,
+			UpdatedAt:  *replyUpdated,
+		}
+	}
+	return &review, nil
+}
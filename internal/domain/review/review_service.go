@@ -1 +1,455 @@
 package review
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// reportFlagThreshold is the number of distinct reporters after which a published
+// review is flagged and drops out of listings and the POI rating until moderated.
+const reportFlagThreshold = 3
+
+var validReportReasons = map[string]bool{
+	"spam":          true,
+	"inappropriate": true,
+	"fake":          true,
+	"offensive":     true,
+	"other":         true,
+}
+
+var _ Service = (*ServiceImpl)(nil)
+
+type Service interface {
+	CreateReview(ctx context.Context, userID, poiID uuid.UUID, input locitypes.ReviewInput) (*locitypes.Review, error)
+	// GetReview returns a review; unpublished reviews are only visible to their author.
+	GetReview(ctx context.Context, reviewID, viewerID uuid.UUID) (*locitypes.Review, error)
+	UpdateReview(ctx context.Context, userID, reviewID uuid.UUID, input locitypes.ReviewInput) (*locitypes.Review, error)
+	DeleteReview(ctx context.Context, userID, reviewID uuid.UUID) error
+
+	GetPOIReviews(ctx context.Context, poiID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error)
+	GetUserReviews(ctx context.Context, userID, viewerID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error)
+
+	// SetHelpfulVote records a helpful/unhelpful vote, or clears it when isHelpful is nil.
+	SetHelpfulVote(ctx context.Context, userID, reviewID uuid.UUID, isHelpful *bool) (int, error)
+	ReportReview(ctx context.Context, userID, reviewID uuid.UUID, reason, details string) error
+	ModerateReview(ctx context.Context, reviewID uuid.UUID, status locitypes.ReviewStatus) error
+
+	AddReply(ctx context.Context, userID, reviewID uuid.UUID, content string, isOfficial bool) (*locitypes.ReviewReply, error)
+	GetReplies(ctx context.Context, reviewID uuid.UUID) ([]locitypes.ReviewReply, error)
+
+	GetPOIStatistics(ctx context.Context, poiID uuid.UUID, includeTrends bool) (*locitypes.ReviewStatistics, error)
+	GetUserStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.UserReviewStatistics, error)
+}
+
+type ServiceImpl struct {
+	logger     *slog.Logger
+	repository Repository
+}
+
+// NewServiceImpl creates a new instance of ServiceImpl
+func NewServiceImpl(repo Repository, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		logger:     logger,
+		repository: repo,
+	}
+}
+
+// CreateReview publishes the user's review of a POI. A user can review a POI once;
+// later changes go through UpdateReview.
+func (s *ServiceImpl) CreateReview(ctx context.Context, userID, poiID uuid.UUID, input locitypes.ReviewInput) (*locitypes.Review, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "CreateReview", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("poi.id", poiID.String()),
+		attribute.Int("review.rating", input.Rating),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "CreateReview"), slog.String("userID", userID.String()), slog.String("poiID", poiID.String()))
+	l.DebugContext(ctx, "Creating review")
+
+	if err := validateReviewInput(input); err != nil {
+		span.SetStatus(codes.Error, "Invalid review")
+		return nil, err
+	}
+
+	exists, err := s.repository.POIExists(ctx, poiID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to check POI", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to check POI")
+		return nil, fmt.Errorf("failed to check poi: %w", err)
+	}
+	if !exists {
+		span.SetStatus(codes.Error, "POI not found")
+		return nil, fmt.Errorf("poi %s: %w", poiID, locitypes.ErrNotFound)
+	}
+
+	review := locitypes.NewReview(userID, poiID, input.Rating, strings.TrimSpace(input.Title), strings.TrimSpace(input.Content))
+	review.VisitDate = input.VisitDate
+	review.ImageURLs = input.ImageURLs
+	review.Language = input.Language
+	review.Aspects = input.Aspects
+
+	if err := s.repository.CreateReview(ctx, review); err != nil {
+		l.ErrorContext(ctx, "Failed to create review", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create review")
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	created, err := s.repository.GetReview(ctx, review.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to load created review: %w", err)
+	}
+
+	l.InfoContext(ctx, "Review created", slog.String("reviewID", review.ID.String()))
+	span.SetStatus(codes.Ok, "Review created")
+	return created, nil
+}
+
+func (s *ServiceImpl) GetReview(ctx context.Context, reviewID, viewerID uuid.UUID) (*locitypes.Review, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "GetReview", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	review, err := s.repository.GetReview(ctx, reviewID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get review")
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+	if review.Status != locitypes.ReviewStatusPublished && review.UserID != viewerID {
+		span.SetStatus(codes.Error, "Review not visible")
+		return nil, fmt.Errorf("review %s: %w", reviewID, locitypes.ErrNotFound)
+	}
+
+	span.SetStatus(codes.Ok, "Review retrieved")
+	return review, nil
+}
+
+func (s *ServiceImpl) UpdateReview(ctx context.Context, userID, reviewID uuid.UUID, input locitypes.ReviewInput) (*locitypes.Review, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "UpdateReview", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "UpdateReview"), slog.String("userID", userID.String()), slog.String("reviewID", reviewID.String()))
+
+	if err := validateReviewInput(input); err != nil {
+		span.SetStatus(codes.Error, "Invalid review")
+		return nil, err
+	}
+
+	review, err := s.ownedReview(ctx, userID, reviewID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Cannot update review")
+		return nil, err
+	}
+
+	review.Rating = input.Rating
+	review.Title = strings.TrimSpace(input.Title)
+	review.Content = strings.TrimSpace(input.Content)
+	review.VisitDate = input.VisitDate
+	review.ImageURLs = input.ImageURLs
+	review.Aspects = input.Aspects
+
+	if err := s.repository.UpdateReview(ctx, review); err != nil {
+		l.ErrorContext(ctx, "Failed to update review", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to update review")
+		return nil, fmt.Errorf("failed to update review: %w", err)
+	}
+
+	updated, err := s.repository.GetReview(ctx, reviewID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to load updated review: %w", err)
+	}
+
+	l.InfoContext(ctx, "Review updated")
+	span.SetStatus(codes.Ok, "Review updated")
+	return updated, nil
+}
+
+func (s *ServiceImpl) DeleteReview(ctx context.Context, userID, reviewID uuid.UUID) error {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "DeleteReview", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	if _, err := s.ownedReview(ctx, userID, reviewID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Cannot delete review")
+		return err
+	}
+
+	if err := s.repository.DeleteReview(ctx, reviewID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete review", slog.String("reviewID", reviewID.String()), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to delete review")
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review deleted")
+	return nil
+}
+
+func (s *ServiceImpl) GetPOIReviews(ctx context.Context, poiID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "GetPOIReviews", trace.WithAttributes(
+		attribute.String("poi.id", poiID.String()),
+		attribute.Int("limit", filter.Limit),
+		attribute.Int("offset", filter.Offset),
+	))
+	defer span.End()
+
+	reviews, total, err := s.repository.ListPOIReviews(ctx, poiID, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list POI reviews")
+		return nil, 0, fmt.Errorf("failed to list poi reviews: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("reviews.total", total))
+	span.SetStatus(codes.Ok, "POI reviews retrieved")
+	return reviews, total, nil
+}
+
+// GetUserReviews lists a user's reviews. Pending, hidden and flagged reviews are
+// included only when the user is looking at their own reviews.
+func (s *ServiceImpl) GetUserReviews(ctx context.Context, userID, viewerID uuid.UUID, filter locitypes.ReviewFilter) ([]locitypes.Review, int, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "GetUserReviews", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+	))
+	defer span.End()
+
+	reviews, total, err := s.repository.ListUserReviews(ctx, userID, filter, userID == viewerID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to list user reviews")
+		return nil, 0, fmt.Errorf("failed to list user reviews: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "User reviews retrieved")
+	return reviews, total, nil
+}
+
+func (s *ServiceImpl) SetHelpfulVote(ctx context.Context, userID, reviewID uuid.UUID, isHelpful *bool) (int, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "SetHelpfulVote", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("review.id", reviewID.String()),
+	))
+	defer span.End()
+
+	review, err := s.GetReview(ctx, reviewID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	if review.UserID == userID {
+		span.SetStatus(codes.Error, "Cannot vote on own review")
+		return 0, fmt.Errorf("cannot vote on your own review: %w", locitypes.ErrBadRequest)
+	}
+
+	helpful, err := s.repository.SetHelpfulVote(ctx, userID, reviewID, isHelpful)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to record vote")
+		return 0, fmt.Errorf("failed to record helpful vote: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Vote recorded")
+	return helpful, nil
+}
+
+// ReportReview records a user's report. Once reportFlagThreshold distinct users have
+// reported a published review it is flagged for moderation.
+func (s *ServiceImpl) ReportReview(ctx context.Context, userID, reviewID uuid.UUID, reason, details string) error {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "ReportReview", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("review.id", reviewID.String()),
+		attribute.String("report.reason", reason),
+	))
+	defer span.End()
+
+	l := s.logger.With(slog.String("method", "ReportReview"), slog.String("userID", userID.String()), slog.String("reviewID", reviewID.String()))
+
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if !validReportReasons[reason] {
+		span.SetStatus(codes.Error, "Invalid report reason")
+		return fmt.Errorf("invalid report reason %q: %w", reason, locitypes.ErrBadRequest)
+	}
+
+	review, err := s.GetReview(ctx, reviewID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if review.UserID == userID {
+		span.SetStatus(codes.Error, "Cannot report own review")
+		return fmt.Errorf("cannot report your own review: %w", locitypes.ErrBadRequest)
+	}
+
+	reportCount, err := s.repository.AddReport(ctx, locitypes.ReviewReport{
+		ID:        uuid.New(),
+		ReviewID:  reviewID,
+		UserID:    userID,
+		Reason:    reason,
+		Details:   strings.TrimSpace(details),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to report review")
+		return fmt.Errorf("failed to report review: %w", err)
+	}
+
+	if reportCount >= reportFlagThreshold && review.Status == locitypes.ReviewStatusPublished {
+		if err := s.repository.UpdateReviewStatus(ctx, reviewID, locitypes.ReviewStatusFlagged); err != nil {
+			l.ErrorContext(ctx, "Failed to flag reported review", slog.Any("error", err))
+			span.RecordError(err)
+			return fmt.Errorf("failed to flag review: %w", err)
+		}
+		l.InfoContext(ctx, "Review flagged for moderation", slog.Int("reportCount", reportCount))
+	}
+
+	span.SetStatus(codes.Ok, "Review reported")
+	return nil
+}
+
+// ModerateReview sets the moderation status of a review. Authorization is left to the caller.
+func (s *ServiceImpl) ModerateReview(ctx context.Context, reviewID uuid.UUID, status locitypes.ReviewStatus) error {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "ModerateReview", trace.WithAttributes(
+		attribute.String("review.id", reviewID.String()),
+		attribute.String("review.status", string(status)),
+	))
+	defer span.End()
+
+	switch status {
+	case locitypes.ReviewStatusPending, locitypes.ReviewStatusPublished,
+		locitypes.ReviewStatusHidden, locitypes.ReviewStatusFlagged:
+	default:
+		span.SetStatus(codes.Error, "Invalid review status")
+		return fmt.Errorf("invalid review status %q: %w", status, locitypes.ErrBadRequest)
+	}
+
+	if err := s.repository.UpdateReviewStatus(ctx, reviewID, status); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to moderate review")
+		return fmt.Errorf("failed to moderate review: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review moderated")
+	return nil
+}
+
+func (s *ServiceImpl) AddReply(ctx context.Context, userID, reviewID uuid.UUID, content string, isOfficial bool) (*locitypes.ReviewReply, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "AddReply", trace.WithAttributes(
+		attribute.String("user.id", userID.String()),
+		attribute.String("review.id", reviewID.String()),
+		attribute.Bool("reply.is_official", isOfficial),
+	))
+	defer span.End()
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		span.SetStatus(codes.Error, "Empty reply")
+		return nil, fmt.Errorf("reply content is required: %w", locitypes.ErrBadRequest)
+	}
+	if _, err := s.GetReview(ctx, reviewID, userID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	reply := locitypes.NewReviewReply(reviewID, userID, content, isOfficial)
+	if err := s.repository.CreateReply(ctx, reply); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to add reply")
+		return nil, fmt.Errorf("failed to add reply: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Reply added")
+	return reply, nil
+}
+
+func (s *ServiceImpl) GetReplies(ctx context.Context, reviewID uuid.UUID) ([]locitypes.ReviewReply, error) {
+	replies, err := s.repository.GetReplies(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+	return replies, nil
+}
+
+func (s *ServiceImpl) GetPOIStatistics(ctx context.Context, poiID uuid.UUID, includeTrends bool) (*locitypes.ReviewStatistics, error) {
+	ctx, span := otel.Tracer("ReviewService").Start(ctx, "GetPOIStatistics", trace.WithAttributes(
+		attribute.String("poi.id", poiID.String()),
+	))
+	defer span.End()
+
+	stats, err := s.repository.GetPOIStatistics(ctx, poiID, includeTrends)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get review statistics")
+		return nil, fmt.Errorf("failed to get review statistics: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "Review statistics retrieved")
+	return stats, nil
+}
+
+func (s *ServiceImpl) GetUserStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.UserReviewStatistics, error) {
+	stats, err := s.repository.GetUserStatistics(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user review statistics: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *ServiceImpl) ownedReview(ctx context.Context, userID, reviewID uuid.UUID) (*locitypes.Review, error) {
+	review, err := s.repository.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+	if review.UserID != userID {
+		return nil, fmt.Errorf("user does not own review: %w", locitypes.ErrForbidden)
+	}
+	return review, nil
+}
+
+func validateReviewInput(input locitypes.ReviewInput) error {
+	if input.Rating < 1 || input.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5: %w", locitypes.ErrBadRequest)
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return fmt.Errorf("review content is required: %w", locitypes.ErrBadRequest)
+	}
+	for name, v := range map[string]float64{
+		"service": input.Aspects.Service, "quality": input.Aspects.Quality, "value": input.Aspects.Value,
+		"atmosphere": input.Aspects.Atmosphere, "cleanliness": input.Aspects.Cleanliness,
+		"location": input.Aspects.Location, "food": input.Aspects.Food, "room": input.Aspects.Room,
+		"amenities": input.Aspects.Amenities, "staff": input.Aspects.Staff,
+	} {
+		if v != 0 && (v < 1 || v > 5) {
+			return fmt.Errorf("%s rating must be between 1 and 5: %w", name, locitypes.ErrBadRequest)
+		}
+	}
+	if input.VisitDate != nil && input.VisitDate.After(time.Now()) {
+		return fmt.Errorf("visit date cannot be in the future: %w", locitypes.ErrBadRequest)
+	}
+	return nil
+}
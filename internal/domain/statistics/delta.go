@@ -0,0 +1,83 @@
+package statistics
+
+import (
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// MetricDelta is a single main page counter that changed between two snapshots.
+type MetricDelta struct {
+	Name     string
+	Value    float64
+	Previous float64
+}
+
+type namedMetric struct {
+	name  string
+	value float64
+}
+
+// mainPageMetrics lists the scalar counters of a snapshot, named after the proto fields.
+func mainPageMetrics(o *locitypes.MainPageOverview) []namedMetric {
+	return []namedMetric{
+		{"total_pois", float64(o.Totals.TotalUniquePOIs)},
+		{"total_cities", float64(o.Activity.TotalCities)},
+		{"total_users", float64(o.Totals.TotalUsersCount)},
+		{"total_itineraries", float64(o.Totals.TotalItinerariesSaved)},
+		{"total_searches_today", float64(o.Activity.SearchesToday)},
+		{"active_users_today", float64(o.Activity.ActiveUsersToday)},
+		{"searches_last_hour", float64(o.Activity.SearchesLastHour)},
+		{"new_users_today", float64(o.Activity.NewUsersToday)},
+		{"itineraries_created_today", float64(o.Activity.ItinerariesCreatedToday)},
+		{"pois_favorited_today", float64(o.Activity.POIsFavoritedToday)},
+	}
+}
+
+// MetricNames returns the names DiffMainPage can report, in a stable order.
+func MetricNames() []string {
+	metrics := mainPageMetrics(&locitypes.MainPageOverview{})
+	names := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// DiffMainPage returns the counters whose value differs between prev and cur.
+func DiffMainPage(prev, cur *locitypes.MainPageOverview) []MetricDelta {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	before := mainPageMetrics(prev)
+	var deltas []MetricDelta
+	for i, m := range mainPageMetrics(cur) {
+		if m.value != before[i].value {
+			deltas = append(deltas, MetricDelta{Name: m.name, Value: m.value, Previous: before[i].value})
+		}
+	}
+	return deltas
+}
+
+// RankingsChanged reports whether the popular destinations or trending categories differ,
+// which cannot be expressed as a single metric update.
+func RankingsChanged(prev, cur *locitypes.MainPageOverview) bool {
+	if prev == nil || cur == nil {
+		return prev != cur
+	}
+	if len(prev.PopularDestinations) != len(cur.PopularDestinations) ||
+		len(prev.TrendingCategories) != len(cur.TrendingCategories) {
+		return true
+	}
+	for i, d := range cur.PopularDestinations {
+		p := prev.PopularDestinations[i]
+		if d.CityName != p.CityName || d.SearchCount != p.SearchCount || d.UserCount != p.UserCount {
+			return true
+		}
+	}
+	for i, c := range cur.TrendingCategories {
+		p := prev.TrendingCategories[i]
+		if c.Category != p.Category || c.SearchCount != p.SearchCount {
+			return true
+		}
+	}
+	return false
+}
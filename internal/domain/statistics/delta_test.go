@@ -0,0 +1,55 @@
+package statistics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+func TestDiffMainPage(t *testing.T) {
+	prev := &locitypes.MainPageOverview{
+		Totals:   locitypes.MainPageStatistics{TotalUsersCount: 10, TotalUniquePOIs: 50},
+		Activity: locitypes.PlatformActivity{SearchesLastHour: 4},
+	}
+	cur := &locitypes.MainPageOverview{
+		Totals:   locitypes.MainPageStatistics{TotalUsersCount: 11, TotalUniquePOIs: 50},
+		Activity: locitypes.PlatformActivity{SearchesLastHour: 2},
+	}
+
+	require.Equal(t, []MetricDelta{
+		{Name: "total_users", Value: 11, Previous: 10},
+		{Name: "searches_last_hour", Value: 2, Previous: 4},
+	}, DiffMainPage(prev, cur))
+	require.Empty(t, DiffMainPage(cur, cur))
+	require.Nil(t, DiffMainPage(nil, cur))
+}
+
+func TestRankingsChanged(t *testing.T) {
+	base := &locitypes.MainPageOverview{
+		PopularDestinations: []locitypes.PopularDestination{{CityName: "Lisbon", SearchCount: 5}},
+		TrendingCategories:  []locitypes.CategoryTrend{{Category: "museum", SearchCount: 3}},
+	}
+	same := &locitypes.MainPageOverview{
+		PopularDestinations: []locitypes.PopularDestination{{CityName: "Lisbon", SearchCount: 5}},
+		TrendingCategories:  []locitypes.CategoryTrend{{Category: "museum", SearchCount: 3}},
+	}
+	reordered := &locitypes.MainPageOverview{
+		PopularDestinations: []locitypes.PopularDestination{{CityName: "Porto", SearchCount: 5}},
+		TrendingCategories:  []locitypes.CategoryTrend{{Category: "museum", SearchCount: 3}},
+	}
+
+	require.False(t, RankingsChanged(base, same))
+	require.True(t, RankingsChanged(base, reordered))
+}
+
+func TestParseTimeRange(t *testing.T) {
+	label, window, err := ParseTimeRange("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultTimeRange, label)
+	require.Equal(t, float64(30*24), window.Hours())
+
+	_, _, err = ParseTimeRange("fortnight")
+	require.ErrorIs(t, err, locitypes.ErrBadRequest)
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	statisticsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1/statisticsv1connect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/statistics"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/statistics/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+const (
+	defaultStreamInterval = 30
+	minStreamInterval     = 5
+	maxStreamInterval     = 300
+
+	eventSnapshot     = "snapshot"
+	eventMetricUpdate = "metric_update"
+	eventSystemAlert  = "system_alert"
+)
+
+var systemAnalyticsSections = map[string]bool{
+	"user_growth": true,
+	"usage":       true,
+	"performance": true,
+	"errors":      true,
+	"geographic":  true,
+	"features":    true,
+}
+
+// StatisticsHandler implements the StatisticsServiceHandler interface.
+type StatisticsHandler struct {
+	statisticsv1connect.UnimplementedStatisticsServiceHandler
+	service statistics.Service
+	logger  *slog.Logger
+
+	// intervalUnit scales update_interval_seconds; tests shorten it.
+	intervalUnit time.Duration
+}
+
+// NewStatisticsHandler creates a new StatisticsHandler.
+func NewStatisticsHandler(svc statistics.Service, logger *slog.Logger) *StatisticsHandler {
+	return &StatisticsHandler{
+		service:      svc,
+		logger:       logger,
+		intervalUnit: time.Second,
+	}
+}
+
+// GetMainPageStatistics returns the public platform overview shown on the main page.
+func (h *StatisticsHandler) GetMainPageStatistics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.GetMainPageStatisticsRequest],
+) (*connect.Response[statisticsv1.GetMainPageStatisticsResponse], error) {
+	overview, err := h.service.GetMainPageOverview(ctx, req.Msg.GetTimeRange())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&statisticsv1.GetMainPageStatisticsResponse{
+		Statistics: presenter.ToProtoMainPageStatistics(overview, req.Msg.GetIncludeTrends()),
+	}), nil
+}
+
+// StreamMainPageStatistics sends a full snapshot, then on every interval sends a
+// metric_update per changed counter. A changed ranking re-sends the snapshot, and a
+// failed refresh sends a warning alert and keeps the stream open.
+func (h *StatisticsHandler) StreamMainPageStatistics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.StreamMainPageStatisticsRequest],
+	stream *connect.ServerStream[statisticsv1.StatisticsEvent],
+) error {
+	interval := streamInterval(req.Msg.GetUpdateIntervalSeconds()) * h.intervalUnit
+	wanted, err := metricFilter(req.Msg.GetMetricFilters())
+	if err != nil {
+		return err
+	}
+
+	prev, err := h.service.GetMainPageOverview(ctx, statistics.DefaultTimeRange)
+	if err != nil {
+		return h.toConnectError(err)
+	}
+	if err := stream.Send(snapshotEvent(prev)); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Info("Statistics stream closed by client")
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := h.service.GetMainPageOverview(ctx, statistics.DefaultTimeRange)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			h.logger.Warn("Failed to refresh main page statistics", slog.Any("error", err))
+			if err := stream.Send(alertEvent("statistics_unavailable", "statistics refresh failed; showing last known values", "warning")); err != nil {
+				return err
+			}
+			continue
+		}
+		if cur == prev {
+			continue
+		}
+
+		if statistics.RankingsChanged(prev, cur) {
+			if err := stream.Send(snapshotEvent(cur)); err != nil {
+				return err
+			}
+			prev = cur
+			continue
+		}
+		for _, d := range statistics.DiffMainPage(prev, cur) {
+			if wanted != nil && !wanted[d.Name] {
+				continue
+			}
+			if err := stream.Send(metricEvent(d, cur.GeneratedAt)); err != nil {
+				return err
+			}
+		}
+		prev = cur
+	}
+}
+
+// GetDetailedPOIStatistics returns the caller's search and POI engagement over time_range.
+func (h *StatisticsHandler) GetDetailedPOIStatistics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.GetDetailedPOIStatisticsRequest],
+) (*connect.Response[statisticsv1.GetDetailedPOIStatisticsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	label, window, err := statistics.ParseTimeRange(req.Msg.GetTimeRange())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	engagement, err := h.service.GetUserEngagement(ctx, userID, label)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	now := time.Now()
+	activity, err := h.service.GetUserActivityAnalytics(ctx, userID, now.Add(-window), now, "day")
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	resp := &statisticsv1.GetDetailedPOIStatisticsResponse{
+		Statistics: presenter.ToProtoDetailedPOIStatistics(userID.String(), engagement, activity, now),
+	}
+	if req.Msg.GetIncludePredictions() {
+		resp.Predictions = weeklyPredictions(engagement, window)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// GetLandingPageStatistics returns the caller's recent activity and achievement badges.
+func (h *StatisticsHandler) GetLandingPageStatistics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.GetLandingPageStatisticsRequest],
+) (*connect.Response[statisticsv1.GetLandingPageStatisticsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := h.service.GetLandingPageStatistics(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+	recent, err := h.service.GetUserRecentActivity(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&statisticsv1.GetLandingPageStatisticsResponse{
+		Statistics: presenter.ToProtoLandingPageStatistics(userID.String(), stats, recent),
+	}), nil
+}
+
+// GetUserActivityAnalytics returns the caller's activity bucketed by granularity.
+// Without dates it covers the last 30 days. Admins may ask for any user.
+func (h *StatisticsHandler) GetUserActivityAnalytics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.GetUserActivityAnalyticsRequest],
+) (*connect.Response[statisticsv1.GetUserActivityAnalyticsResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId(), interceptors.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if req.Msg.GetEndDate() != nil {
+		end = req.Msg.GetEndDate().AsTime()
+	}
+	start := end.AddDate(0, 0, -30)
+	if req.Msg.GetStartDate() != nil {
+		start = req.Msg.GetStartDate().AsTime()
+	}
+
+	analytics, err := h.service.GetUserActivityAnalytics(ctx, userID, start, end, req.Msg.GetGranularity())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	points, summary := presenter.ToProtoActivity(analytics)
+	return connect.NewResponse(&statisticsv1.GetUserActivityAnalyticsResponse{
+		ActivityData: points,
+		Summary:      summary,
+	}), nil
+}
+
+// GetSystemAnalytics returns platform analytics. Admin only.
+func (h *StatisticsHandler) GetSystemAnalytics(
+	ctx context.Context,
+	req *connect.Request[statisticsv1.GetSystemAnalyticsRequest],
+) (*connect.Response[statisticsv1.GetSystemAnalyticsResponse], error) {
+	if !interceptors.HasRole(ctx, interceptors.RoleAdmin) {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("admin role required"))
+	}
+
+	var sections map[string]bool
+	if categories := req.Msg.GetMetricCategories(); len(categories) > 0 {
+		sections = make(map[string]bool, len(categories))
+		for _, c := range categories {
+			if !systemAnalyticsSections[c] {
+				return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unknown metric category %q", c))
+			}
+			sections[c] = true
+		}
+	}
+
+	analytics, err := h.service.GetSystemAnalytics(ctx, req.Msg.GetTimeRange())
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&statisticsv1.GetSystemAnalyticsResponse{
+		Analytics: presenter.ToProtoSystemAnalytics(analytics, sections),
+	}), nil
+}
+
+func (h *StatisticsHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrUnauthenticated):
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("statistics request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+func streamInterval(seconds int32) time.Duration {
+	switch {
+	case seconds <= 0:
+		seconds = defaultStreamInterval
+	case seconds < minStreamInterval:
+		seconds = minStreamInterval
+	case seconds > maxStreamInterval:
+		seconds = maxStreamInterval
+	}
+	return time.Duration(seconds)
+}
+
+// metricFilter validates metric_filters; nil means every metric.
+func metricFilter(filters []string) (map[string]bool, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]bool)
+	for _, name := range statistics.MetricNames() {
+		known[name] = true
+	}
+	wanted := make(map[string]bool, len(filters))
+	for _, f := range filters {
+		if !known[f] {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unknown metric %q", f))
+		}
+		wanted[f] = true
+	}
+	return wanted, nil
+}
+
+func snapshotEvent(o *locitypes.MainPageOverview) *statisticsv1.StatisticsEvent {
+	return &statisticsv1.StatisticsEvent{
+		EventType: eventSnapshot,
+		Timestamp: timestamppb.New(o.GeneratedAt),
+		Payload: &statisticsv1.StatisticsEvent_MainStats{
+			MainStats: presenter.ToProtoMainPageStatistics(o, true),
+		},
+	}
+}
+
+func metricEvent(d statistics.MetricDelta, at time.Time) *statisticsv1.StatisticsEvent {
+	changeType := "increase"
+	if d.Value < d.Previous {
+		changeType = "decrease"
+	}
+	return &statisticsv1.StatisticsEvent{
+		EventType: eventMetricUpdate,
+		Timestamp: timestamppb.New(at),
+		Payload: &statisticsv1.StatisticsEvent_MetricUpdate{
+			MetricUpdate: &statisticsv1.MetricUpdate{
+				MetricName:    d.Name,
+				Value:         d.Value,
+				ChangeType:    changeType,
+				PreviousValue: d.Previous,
+			},
+		},
+	}
+}
+
+func alertEvent(alertType, message, severity string) *statisticsv1.StatisticsEvent {
+	return &statisticsv1.StatisticsEvent{
+		EventType: eventSystemAlert,
+		Timestamp: timestamppb.Now(),
+		Payload: &statisticsv1.StatisticsEvent_SystemAlert{
+			SystemAlert: &statisticsv1.SystemAlert{
+				AlertType: alertType,
+				Message:   message,
+				Severity:  severity,
+			},
+		},
+	}
+}
+
+// weeklyPredictions projects the period's daily average over the next week. It is a
+// naive extrapolation, so it is always reported with low confidence.
+func weeklyPredictions(e *locitypes.UserEngagement, window time.Duration) []*statisticsv1.Prediction {
+	days := window.Hours() / 24
+	if days <= 0 {
+		return nil
+	}
+	project := func(metric string, total int) *statisticsv1.Prediction {
+		return &statisticsv1.Prediction{
+			Metric:          metric,
+			PredictedValue:  float64(total) / days * 7,
+			ConfidenceLevel: "low",
+			TimeHorizon:     "7d",
+		}
+	}
+	return []*statisticsv1.Prediction{
+		project("poi_searches", e.Searches),
+		project("favorite_pois", e.Favorites),
+	}
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	statisticsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1/statisticsv1connect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/statistics"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	statistics.Service
+
+	mu        sync.Mutex
+	overviews []*locitypes.MainPageOverview
+	calls     int
+	err       error
+
+	analytics       *locitypes.UserActivityAnalytics
+	lastGranularity string
+}
+
+// GetMainPageOverview returns the queued overviews in order, repeating the last one.
+func (s *stubService) GetMainPageOverview(_ context.Context, timeRange string) (*locitypes.MainPageOverview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, _, err := statistics.ParseTimeRange(timeRange); err != nil {
+		return nil, err
+	}
+	if s.err != nil && s.calls > 0 {
+		s.calls++
+		return nil, s.err
+	}
+	o := s.overviews[min(s.calls, len(s.overviews)-1)]
+	s.calls++
+	return o, nil
+}
+
+func (s *stubService) GetUserActivityAnalytics(_ context.Context, _ uuid.UUID, _, _ time.Time, granularity string) (*locitypes.UserActivityAnalytics, error) {
+	s.lastGranularity = granularity
+	return s.analytics, s.err
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func overview(users int64, searchesToday int64, cities ...string) *locitypes.MainPageOverview {
+	o := &locitypes.MainPageOverview{
+		Totals:      locitypes.MainPageStatistics{TotalUsersCount: users, TotalUniquePOIs: 120},
+		Activity:    locitypes.PlatformActivity{SearchesToday: searchesToday, TotalCities: 12},
+		TrendPeriod: "30d",
+		GeneratedAt: time.Now(),
+	}
+	for i, c := range cities {
+		o.PopularDestinations = append(o.PopularDestinations, locitypes.PopularDestination{CityName: c, SearchCount: 10 - i})
+	}
+	return o
+}
+
+func TestGetMainPageStatistics_OmitsTrendsUnlessRequested(t *testing.T) {
+	h := NewStatisticsHandler(&stubService{overviews: []*locitypes.MainPageOverview{overview(40, 7, "Lisbon")}}, testutil.NewLogger())
+
+	resp, err := h.GetMainPageStatistics(context.Background(), connect.NewRequest(&statisticsv1.GetMainPageStatisticsRequest{}))
+	require.NoError(t, err)
+	require.Equal(t, int64(40), resp.Msg.GetStatistics().GetTotalUsers())
+	require.Equal(t, int64(12), resp.Msg.GetStatistics().GetTotalCities())
+	require.Empty(t, resp.Msg.GetStatistics().GetPopularDestinations())
+
+	resp, err = h.GetMainPageStatistics(context.Background(), connect.NewRequest(&statisticsv1.GetMainPageStatisticsRequest{IncludeTrends: true}))
+	require.NoError(t, err)
+	require.Len(t, resp.Msg.GetStatistics().GetPopularDestinations(), 1)
+	require.Equal(t, "30d", resp.Msg.GetStatistics().GetTrendingCategories().GetTimePeriod())
+}
+
+func TestGetMainPageStatistics_RejectsUnknownTimeRange(t *testing.T) {
+	h := NewStatisticsHandler(&stubService{overviews: []*locitypes.MainPageOverview{overview(1, 1)}}, testutil.NewLogger())
+
+	_, err := h.GetMainPageStatistics(context.Background(), connect.NewRequest(&statisticsv1.GetMainPageStatisticsRequest{TimeRange: "2w"}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestGetUserActivityAnalytics_SummarisesBuckets(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	svc := &stubService{analytics: &locitypes.UserActivityAnalytics{
+		Points: []locitypes.ActivityDataPoint{
+			{Bucket: day, Searches: 3, Favorites: 1, ChatMessages: 4},
+			{Bucket: day.AddDate(0, 0, 1), Searches: 2, ItineraryActions: 1},
+		},
+		MostActiveHour: 19,
+		MostActiveDay:  "friday",
+	}}
+	h := NewStatisticsHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetUserActivityAnalytics(authedContext(userID), connect.NewRequest(&statisticsv1.GetUserActivityAnalyticsRequest{
+		Granularity: "day",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "day", svc.lastGranularity)
+	require.Len(t, resp.Msg.GetActivityData(), 2)
+	require.Equal(t, int32(5), resp.Msg.GetSummary().GetTotalSearches())
+	require.Equal(t, int32(1), resp.Msg.GetSummary().GetTotalItineraryActions())
+	require.Equal(t, int32(19), resp.Msg.GetSummary().GetMostActiveHour())
+}
+
+func TestGetUserActivityAnalytics_RejectsOtherUsers(t *testing.T) {
+	h := NewStatisticsHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.GetUserActivityAnalytics(authedContext(uuid.New()), connect.NewRequest(&statisticsv1.GetUserActivityAnalyticsRequest{
+		UserId: uuid.NewString(),
+	}))
+	require.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func TestGetSystemAnalytics_RequiresAdmin(t *testing.T) {
+	h := NewStatisticsHandler(&stubService{}, testutil.NewLogger())
+
+	_, err := h.GetSystemAnalytics(authedContext(uuid.New()), connect.NewRequest(&statisticsv1.GetSystemAnalyticsRequest{}))
+	require.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func TestStreamInterval_Clamps(t *testing.T) {
+	require.Equal(t, time.Duration(defaultStreamInterval), streamInterval(0))
+	require.Equal(t, time.Duration(minStreamInterval), streamInterval(1))
+	require.Equal(t, time.Duration(maxStreamInterval), streamInterval(3600))
+	require.Equal(t, time.Duration(60), streamInterval(60))
+}
+
+func startStatisticsServer(t *testing.T, handler *StatisticsHandler) statisticsv1connect.StatisticsServiceClient {
+	t.Helper()
+	handler.intervalUnit = time.Millisecond
+
+	mux := http.NewServeMux()
+	path, h := statisticsv1connect.NewStatisticsServiceHandler(handler)
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return statisticsv1connect.NewStatisticsServiceClient(server.Client(), server.URL)
+}
+
+func TestStreamMainPageStatistics_SendsSnapshotThenDeltas(t *testing.T) {
+	svc := &stubService{overviews: []*locitypes.MainPageOverview{
+		overview(40, 7, "Lisbon", "Porto"),
+		overview(40, 7, "Lisbon", "Porto"),
+		overview(41, 9, "Lisbon", "Porto"),
+		overview(41, 9, "Porto", "Lisbon"),
+	}}
+	client := startStatisticsServer(t, NewStatisticsHandler(svc, testutil.NewLogger()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamMainPageStatistics(ctx, connect.NewRequest(&statisticsv1.StreamMainPageStatisticsRequest{
+		UpdateIntervalSeconds: 5,
+		MetricFilters:         []string{"total_users"},
+	}))
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var events []*statisticsv1.StatisticsEvent
+	for len(events) < 3 && stream.Receive() {
+		events = append(events, stream.Msg())
+	}
+	require.NoError(t, stream.Err())
+	require.Len(t, events, 3)
+
+	require.Equal(t, eventSnapshot, events[0].GetEventType())
+	require.Equal(t, int64(40), events[0].GetMainStats().GetTotalUsers())
+
+	// Unchanged snapshots send nothing; searches_today is filtered out.
+	require.Equal(t, eventMetricUpdate, events[1].GetEventType())
+	update := events[1].GetMetricUpdate()
+	require.Equal(t, "total_users", update.GetMetricName())
+	require.Equal(t, float64(41), update.GetValue())
+	require.Equal(t, float64(40), update.GetPreviousValue())
+	require.Equal(t, "increase", update.GetChangeType())
+
+	require.Equal(t, eventSnapshot, events[2].GetEventType())
+	require.Equal(t, "Porto", events[2].GetMainStats().GetPopularDestinations()[0].GetCityName())
+}
+
+func TestStreamMainPageStatistics_AlertsOnRefreshFailure(t *testing.T) {
+	svc := &stubService{
+		overviews: []*locitypes.MainPageOverview{overview(40, 7)},
+		err:       fmt.Errorf("connection reset"),
+	}
+	client := startStatisticsServer(t, NewStatisticsHandler(svc, testutil.NewLogger()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamMainPageStatistics(ctx, connect.NewRequest(&statisticsv1.StreamMainPageStatisticsRequest{}))
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.True(t, stream.Receive())
+	require.Equal(t, eventSnapshot, stream.Msg().GetEventType())
+	require.True(t, stream.Receive())
+	require.Equal(t, eventSystemAlert, stream.Msg().GetEventType())
+	require.Equal(t, "warning", stream.Msg().GetSystemAlert().GetSeverity())
+}
+
+func TestStreamMainPageStatistics_RejectsUnknownMetric(t *testing.T) {
+	client := startStatisticsServer(t, NewStatisticsHandler(&stubService{}, testutil.NewLogger()))
+
+	stream, err := client.StreamMainPageStatistics(context.Background(), connect.NewRequest(&statisticsv1.StreamMainPageStatisticsRequest{
+		MetricFilters: []string{"bogus"},
+	}))
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.False(t, stream.Receive())
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(stream.Err()))
+}
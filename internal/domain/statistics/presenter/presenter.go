@@ -0,0 +1,335 @@
+package presenter
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	statisticsv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/ai_poi/statistics/v1"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+func ToProtoMainPageStatistics(o *locitypes.MainPageOverview, includeTrends bool) *statisticsv1.MainPageStatistics {
+	if o == nil {
+		return nil
+	}
+	pb := &statisticsv1.MainPageStatistics{
+		TotalPois:          o.Totals.TotalUniquePOIs,
+		TotalCities:        o.Activity.TotalCities,
+		TotalUsers:         o.Totals.TotalUsersCount,
+		TotalItineraries:   o.Totals.TotalItinerariesSaved,
+		TotalSearchesToday: o.Activity.SearchesToday,
+		ActiveUsersToday:   o.Activity.ActiveUsersToday,
+		RecentActivity: &statisticsv1.RecentActivity{
+			SearchesLastHour:        int32(o.Activity.SearchesLastHour),
+			NewUsersToday:           int32(o.Activity.NewUsersToday),
+			ItinerariesCreatedToday: int32(o.Activity.ItinerariesCreatedToday),
+			PoisFavoritedToday:      int32(o.Activity.POIsFavoritedToday),
+		},
+		LastUpdated: timestamppb.New(o.GeneratedAt),
+	}
+	if !includeTrends {
+		return pb
+	}
+
+	for _, d := range o.PopularDestinations {
+		dest := &statisticsv1.PopularDestination{
+			CityName:         d.CityName,
+			Country:          d.Country,
+			SearchCount:      int32(d.SearchCount),
+			UserCount:        int32(d.UserCount),
+			GrowthPercentage: d.GrowthPercentage,
+		}
+		if d.CityID != nil {
+			dest.CityId = d.CityID.String()
+		}
+		pb.PopularDestinations = append(pb.PopularDestinations, dest)
+	}
+	pb.TrendingCategories = &statisticsv1.TrendingCategories{TimePeriod: o.TrendPeriod}
+	for i, c := range o.TrendingCategories {
+		pb.TrendingCategories.Categories = append(pb.TrendingCategories.Categories, &statisticsv1.CategoryTrend{
+			Category:         c.Category,
+			SearchCount:      int32(c.SearchCount),
+			GrowthPercentage: c.GrowthPercentage,
+			Rank:             int32(i + 1),
+		})
+	}
+	return pb
+}
+
+// ToProtoDetailedPOIStatistics combines a user's engagement with their activity buckets.
+func ToProtoDetailedPOIStatistics(userID string, e *locitypes.UserEngagement, a *locitypes.UserActivityAnalytics, generatedAt time.Time) *statisticsv1.DetailedPOIStatistics {
+	pb := &statisticsv1.DetailedPOIStatistics{
+		UserId:             userID,
+		TotalPoiSearches:   int32(e.Searches),
+		FavoritePoisCount:  int32(e.Favorites),
+		VisitedCitiesCount: int32(e.VisitedCities),
+		TopCategories:      e.TopCategories,
+		SearchPatterns: &statisticsv1.SearchPatterns{
+			PreferredCategories: e.TopCategories,
+			MostActiveTimeOfDay: TimeOfDay(busiestHour(e.HourlyActivity)),
+		},
+		TimeAnalytics: &statisticsv1.TimeBasedAnalytics{},
+		GeneratedAt:   timestamppb.New(generatedAt),
+	}
+	for _, c := range e.Cities {
+		stats := &statisticsv1.CityInteractionStats{
+			CityName:           c.CityName,
+			SearchCount:        int32(c.SearchCount),
+			PoisFavorited:      int32(c.Favorites),
+			ItinerariesCreated: int32(c.Itineraries),
+			LastInteraction:    timestamppb.New(c.LastInteraction),
+		}
+		if c.CityID != nil {
+			stats.CityId = c.CityID.String()
+		}
+		pb.CityInteractions = append(pb.CityInteractions, stats)
+	}
+	for hour, count := range e.HourlyActivity {
+		pb.TimeAnalytics.HourlyActivity = append(pb.TimeAnalytics.HourlyActivity, &statisticsv1.HourlyActivity{
+			Hour:          int32(hour),
+			ActivityCount: int32(count),
+		})
+	}
+	if a == nil {
+		return pb
+	}
+
+	pb.SearchPatterns.MostActiveDayOfWeek = a.MostActiveDay
+	type month struct{ year, month int }
+	var months []month
+	totals := make(map[month]int)
+	for _, p := range a.Points {
+		pb.TimeAnalytics.DailyActivity = append(pb.TimeAnalytics.DailyActivity, &statisticsv1.DailyActivity{
+			Date:               timestamppb.New(p.Bucket),
+			Searches:           int32(p.Searches),
+			FavoritesAdded:     int32(p.Favorites),
+			ItinerariesCreated: int32(p.ItineraryActions),
+		})
+		m := month{p.Bucket.Year(), int(p.Bucket.Month())}
+		if _, ok := totals[m]; !ok {
+			months = append(months, m)
+		}
+		totals[m] += p.Searches + p.Favorites + p.ItineraryActions + p.ChatMessages
+	}
+	for i, m := range months {
+		monthly := &statisticsv1.MonthlyActivity{Year: int32(m.year), Month: int32(m.month), TotalActivity: int32(totals[m])}
+		if i > 0 {
+			if prev := totals[months[i-1]]; prev > 0 {
+				monthly.GrowthPercentage = float64(totals[m]-prev) / float64(prev) * 100
+			}
+		}
+		pb.TimeAnalytics.MonthlyActivity = append(pb.TimeAnalytics.MonthlyActivity, monthly)
+	}
+	return pb
+}
+
+func ToProtoActivity(a *locitypes.UserActivityAnalytics) ([]*statisticsv1.ActivityDataPoint, *statisticsv1.ActivitySummary) {
+	summary := &statisticsv1.ActivitySummary{
+		MostActiveHour: int32(a.MostActiveHour),
+		MostActiveDay:  a.MostActiveDay,
+	}
+	points := make([]*statisticsv1.ActivityDataPoint, 0, len(a.Points))
+	for _, p := range a.Points {
+		points = append(points, &statisticsv1.ActivityDataPoint{
+			Timestamp:        timestamppb.New(p.Bucket),
+			Searches:         int32(p.Searches),
+			Favorites:        int32(p.Favorites),
+			ItineraryActions: int32(p.ItineraryActions),
+			ChatMessages:     int32(p.ChatMessages),
+		})
+		summary.TotalSearches += int32(p.Searches)
+		summary.TotalFavorites += int32(p.Favorites)
+		summary.TotalItineraryActions += int32(p.ItineraryActions)
+	}
+	return points, summary
+}
+
+// badgeDefinitions are the achievements derived from the landing page counters.
+var badgeDefinitions = []struct {
+	id, name, description string
+	target                int
+	progress              func(*locitypes.LandingPageUserStats) int
+}{
+	{"explorer", "Explorer", "Explore 5 different cities", 5, func(s *locitypes.LandingPageUserStats) int { return s.CitiesExplored }},
+	{"planner", "Planner", "Save 3 itineraries", 3, func(s *locitypes.LandingPageUserStats) int { return s.Itineraries }},
+	{"collector", "Collector", "Save 10 places", 10, func(s *locitypes.LandingPageUserStats) int { return s.SavedPlaces }},
+	{"curious", "Curious Mind", "Discover 10 points of interest", 10, func(s *locitypes.LandingPageUserStats) int { return s.Discoveries }},
+}
+
+func ToProtoLandingPageStatistics(userID string, stats *locitypes.LandingPageUserStats, recent *locitypes.UserRecentActivity) *statisticsv1.LandingPageUserStats {
+	pb := &statisticsv1.LandingPageUserStats{
+		UserId:                      userID,
+		SearchesThisWeek:            int32(recent.SearchesThisWeek),
+		NewFavoritesThisWeek:        int32(recent.FavoritesThisWeek),
+		ItinerariesCreatedThisMonth: int32(recent.ItinerariesThisMonth),
+		RecentlySearchedCities:      recent.RecentCities,
+		Badges:                      &statisticsv1.AchievementBadges{},
+	}
+	for _, b := range badgeDefinitions {
+		progress := b.progress(stats)
+		badge := &statisticsv1.Badge{
+			Id:          b.id,
+			Name:        b.name,
+			Description: b.description,
+			Progress:    int32(min(progress, b.target)),
+			Target:      int32(b.target),
+		}
+		if progress >= b.target {
+			pb.Badges.EarnedBadges = append(pb.Badges.EarnedBadges, badge)
+		} else {
+			pb.Badges.AvailableBadges = append(pb.Badges.AvailableBadges, badge)
+		}
+	}
+	return pb
+}
+
+// ToProtoSystemAnalytics maps the requested sections of the admin analytics; a nil
+// sections set includes everything.
+func ToProtoSystemAnalytics(a *locitypes.SystemAnalytics, sections map[string]bool) *statisticsv1.SystemAnalytics {
+	include := func(name string) bool { return sections == nil || sections[name] }
+	pb := &statisticsv1.SystemAnalytics{GeneratedAt: timestamppb.New(a.GeneratedAt)}
+
+	if include("user_growth") {
+		g := a.UserGrowth
+		pb.UserGrowth = &statisticsv1.UserGrowthMetrics{
+			TotalUsers:           g.TotalUsers,
+			NewUsersToday:        int32(g.NewUsersToday),
+			NewUsersThisWeek:     int32(g.NewUsersThisWeek),
+			NewUsersThisMonth:    int32(g.NewUsersThisMonth),
+			GrowthRateWeekly:     g.GrowthRateWeekly,
+			GrowthRateMonthly:    g.GrowthRateMonthly,
+			ActiveUsersToday:     int32(g.ActiveUsersToday),
+			ActiveUsersThisWeek:  int32(g.ActiveUsersThisWeek),
+			RetentionRateWeekly:  g.RetentionRateWeekly,
+			RetentionRateMonthly: g.RetentionRateMonthly,
+		}
+	}
+	if include("usage") {
+		pb.UsageMetrics = &statisticsv1.UsageMetrics{
+			TotalSearches:          a.Usage.TotalSearches,
+			SearchesToday:          int32(a.Usage.SearchesToday),
+			SearchesThisWeek:       int32(a.Usage.SearchesThisWeek),
+			AverageSearchesPerUser: a.Usage.AverageSearchesPerUser,
+			TotalApiCalls:          a.Usage.TotalSearches,
+			AverageResponseTimeMs:  a.Usage.AverageResponseTimeMs,
+		}
+	}
+	if include("performance") {
+		pb.PerformanceMetrics = &statisticsv1.PerformanceMetrics{
+			ActiveDatabaseConnections: int32(a.ActiveDBConnections),
+		}
+	}
+	if include("errors") {
+		errs := &statisticsv1.ErrorMetrics{
+			TotalErrorsToday:    int32(a.Errors.ErrorsToday),
+			TotalErrorsThisWeek: int32(a.Errors.ErrorsThisWeek),
+			ErrorRatePercentage: a.Errors.ErrorRate,
+		}
+		var total int
+		for _, e := range a.Errors.Breakdown {
+			total += e.Count
+		}
+		for _, e := range a.Errors.Breakdown {
+			errs.ErrorBreakdown = append(errs.ErrorBreakdown, &statisticsv1.ErrorBreakdown{
+				ErrorType:  e.ErrorType,
+				Count:      int32(e.Count),
+				Percentage: share(e.Count, total),
+			})
+		}
+		pb.ErrorMetrics = errs
+	}
+	if include("geographic") {
+		geo := &statisticsv1.GeographicDistribution{}
+		var searches, topCity int
+		for _, c := range a.Countries {
+			searches += c.SearchCount
+		}
+		for _, c := range a.Countries {
+			geo.CountryStats = append(geo.CountryStats, &statisticsv1.CountryStats{
+				CountryName:       c.Country,
+				UserCount:         int32(c.UserCount),
+				SearchCount:       int32(c.SearchCount),
+				PercentageOfTotal: share(c.SearchCount, searches),
+			})
+		}
+		if len(a.Cities) > 0 {
+			topCity = a.Cities[0].SearchCount
+		}
+		for _, c := range a.Cities {
+			stats := &statisticsv1.CityStats{
+				CityName:    c.CityName,
+				SearchCount: int32(c.SearchCount),
+				PoiCount:    int32(c.POICount),
+				// Popularity is relative to the most searched city in the period.
+				PopularityScore: share(c.SearchCount, topCity),
+			}
+			if c.CityID != nil {
+				stats.CityId = c.CityID.String()
+			}
+			geo.CityStats = append(geo.CityStats, stats)
+		}
+		pb.GeographicDistribution = geo
+	}
+	if include("features") {
+		f := a.Features
+		usage := &statisticsv1.FeatureUsage{
+			SemanticSearches:   int32(f.Searches),
+			FavoritesAdded:     int32(f.FavoritesAdded),
+			ItinerariesCreated: int32(f.ItinerariesCreated),
+			ListsCreated:       int32(f.ListsCreated),
+			ChatSessions:       int32(f.ChatSessions),
+		}
+		counts := []struct {
+			name  string
+			count int
+		}{
+			{"searches", f.Searches},
+			{"favorites", f.FavoritesAdded},
+			{"itineraries", f.ItinerariesCreated},
+			{"lists", f.ListsCreated},
+			{"chat", f.ChatSessions},
+		}
+		for _, c := range counts {
+			usage.FeatureMetrics = append(usage.FeatureMetrics, &statisticsv1.FeatureMetric{
+				FeatureName:  c.name,
+				UsageCount:   int32(c.count),
+				AdoptionRate: share(f.FeatureUsers[c.name], int(a.UserGrowth.TotalUsers)),
+			})
+		}
+		pb.FeatureUsage = usage
+	}
+	return pb
+}
+
+// TimeOfDay names the part of the day an hour falls in.
+func TimeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return "morning"
+	case hour >= 12 && hour < 17:
+		return "afternoon"
+	case hour >= 17 && hour < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+func busiestHour(hours [24]int) int {
+	best := 0
+	for h, c := range hours {
+		if c > hours[best] {
+			best = h
+		}
+	}
+	return best
+}
+
+func share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
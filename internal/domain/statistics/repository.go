@@ -2,7 +2,10 @@ package statistics
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,6 +22,23 @@ type Repository interface {
 	GetDetailedPOIStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.DetailedPOIStatistics, error)
 	// LandingPageStatistics retrieves user-specific landing page statistics.
 	LandingPageStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.LandingPageUserStats, error)
+
+	// GetPlatformActivity retrieves today's platform-wide activity counters.
+	GetPlatformActivity(ctx context.Context) (*locitypes.PlatformActivity, error)
+	// GetPopularDestinations ranks searched cities since a point in time, with growth
+	// against the preceding window of the same length.
+	GetPopularDestinations(ctx context.Context, since time.Time, limit int) ([]locitypes.PopularDestination, error)
+	// GetTrendingCategories ranks POI categories users interacted with since a point in time.
+	GetTrendingCategories(ctx context.Context, since time.Time, limit int) ([]locitypes.CategoryTrend, error)
+	// GetUserActivityAnalytics buckets a user's searches, favourites, itinerary actions and
+	// chat messages between start and end. truncUnit is a date_trunc unit and step its interval.
+	GetUserActivityAnalytics(ctx context.Context, userID uuid.UUID, start, end time.Time, truncUnit, step string) (*locitypes.UserActivityAnalytics, error)
+	// GetUserEngagement retrieves a user's search and POI engagement since a point in time.
+	GetUserEngagement(ctx context.Context, userID uuid.UUID, since time.Time) (*locitypes.UserEngagement, error)
+	// GetUserRecentActivity retrieves the weekly and monthly counters for the landing page.
+	GetUserRecentActivity(ctx context.Context, userID uuid.UUID) (*locitypes.UserRecentActivity, error)
+	// GetSystemAnalytics retrieves admin analytics over users, LLM usage and errors.
+	GetSystemAnalytics(ctx context.Context, since time.Time) (*locitypes.SystemAnalytics, error)
 }
 
 type RepositoryImpl struct {
@@ -264,3 +284,648 @@ func (r *RepositoryImpl) LandingPageStatistics(ctx context.Context, userID uuid.
 
 	return &stats, nil
 }
+
+func (r *RepositoryImpl) GetPlatformActivity(ctx context.Context) (*locitypes.PlatformActivity, error) {
+	query := `
+	SELECT
+		(SELECT COUNT(*) FROM cities) AS total_cities,
+		(SELECT COUNT(*) FROM llm_interactions WHERE created_at >= date_trunc('day', NOW()) AND status_code < 400) AS searches_today,
+		(SELECT COUNT(*) FROM (
+			SELECT user_id FROM llm_interactions
+			WHERE user_id IS NOT NULL AND created_at >= date_trunc('day', NOW())
+			UNION
+			SELECT user_id FROM poi_interactions WHERE timestamp >= date_trunc('day', NOW())
+		) active) AS active_users_today,
+		(SELECT COUNT(*) FROM llm_interactions WHERE created_at >= NOW() - INTERVAL '1 hour' AND status_code < 400) AS searches_last_hour,
+		(SELECT COUNT(*) FROM users WHERE created_at >= date_trunc('day', NOW())) AS new_users_today,
+		(SELECT COUNT(*) FROM user_saved_itineraries WHERE created_at >= date_trunc('day', NOW())) AS itineraries_today,
+		(SELECT COUNT(*) FROM poi_interactions
+			WHERE interaction_type = 'favorite' AND timestamp >= date_trunc('day', NOW())) AS favorites_today
+	`
+
+	var activity locitypes.PlatformActivity
+	err := r.pgpool.QueryRow(ctx, query).Scan(
+		&activity.TotalCities,
+		&activity.SearchesToday,
+		&activity.ActiveUsersToday,
+		&activity.SearchesLastHour,
+		&activity.NewUsersToday,
+		&activity.ItinerariesCreatedToday,
+		&activity.POIsFavoritedToday,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get platform activity", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get platform activity: %w", err)
+	}
+
+	return &activity, nil
+}
+
+func (r *RepositoryImpl) GetPopularDestinations(ctx context.Context, since time.Time, limit int) ([]locitypes.PopularDestination, error) {
+	query := `
+	WITH current_window AS (
+		SELECT
+			lower(COALESCE(c.name, li.city_name)) AS city_key,
+			(array_agg(li.city_id) FILTER (WHERE li.city_id IS NOT NULL))[1] AS city_id,
+			MIN(COALESCE(c.name, li.city_name)) AS city_name,
+			COALESCE(MAX(c.country), '') AS country,
+			COUNT(*) AS searches,
+			COUNT(DISTINCT li.user_id) AS users
+		FROM llm_interactions li
+		LEFT JOIN cities c ON c.id = li.city_id
+		WHERE li.created_at >= $1 AND COALESCE(c.name, li.city_name, '') <> ''
+		GROUP BY 1
+	),
+	previous_window AS (
+		SELECT lower(COALESCE(c.name, li.city_name)) AS city_key, COUNT(*) AS searches
+		FROM llm_interactions li
+		LEFT JOIN cities c ON c.id = li.city_id
+		WHERE li.created_at >= $1::timestamptz - (NOW() - $1::timestamptz) AND li.created_at < $1
+		GROUP BY 1
+	)
+	SELECT cw.city_id, cw.city_name, cw.country, cw.searches, cw.users, COALESCE(pw.searches, 0)
+	FROM current_window cw
+	LEFT JOIN previous_window pw ON pw.city_key = cw.city_key
+	ORDER BY cw.searches DESC, cw.city_name
+	LIMIT $2
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, since, limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get popular destinations", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get popular destinations: %w", err)
+	}
+	defer rows.Close()
+
+	var destinations []locitypes.PopularDestination
+	for rows.Next() {
+		var d locitypes.PopularDestination
+		var previous int
+		if err := rows.Scan(&d.CityID, &d.CityName, &d.Country, &d.SearchCount, &d.UserCount, &previous); err != nil {
+			return nil, fmt.Errorf("failed to scan popular destination: %w", err)
+		}
+		d.GrowthPercentage = growthPercentage(d.SearchCount, previous)
+		destinations = append(destinations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating popular destinations: %w", err)
+	}
+
+	return destinations, nil
+}
+
+func (r *RepositoryImpl) GetTrendingCategories(ctx context.Context, since time.Time, limit int) ([]locitypes.CategoryTrend, error) {
+	query := `
+	WITH current_window AS (
+		SELECT lower(poi_category) AS category, COUNT(*) AS interactions
+		FROM poi_interactions
+		WHERE timestamp >= $1 AND poi_category <> ''
+		GROUP BY 1
+	),
+	previous_window AS (
+		SELECT lower(poi_category) AS category, COUNT(*) AS interactions
+		FROM poi_interactions
+		WHERE timestamp >= $1::timestamptz - (NOW() - $1::timestamptz) AND timestamp < $1
+		GROUP BY 1
+	)
+	SELECT cw.category, cw.interactions, COALESCE(pw.interactions, 0)
+	FROM current_window cw
+	LEFT JOIN previous_window pw ON pw.category = cw.category
+	ORDER BY cw.interactions DESC, cw.category
+	LIMIT $2
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, since, limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get trending categories", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get trending categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []locitypes.CategoryTrend
+	for rows.Next() {
+		var c locitypes.CategoryTrend
+		var previous int
+		if err := rows.Scan(&c.Category, &c.SearchCount, &previous); err != nil {
+			return nil, fmt.Errorf("failed to scan trending category: %w", err)
+		}
+		c.GrowthPercentage = growthPercentage(c.SearchCount, previous)
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trending categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *RepositoryImpl) GetUserActivityAnalytics(ctx context.Context, userID uuid.UUID, start, end time.Time, truncUnit, step string) (*locitypes.UserActivityAnalytics, error) {
+	r.logger.InfoContext(ctx, "Getting user activity analytics",
+		slog.String("user_id", userID.String()), slog.String("granularity", truncUnit))
+
+	// Chat messages live in chat_sessions.conversation_history; only the user's own
+	// messages count as activity. Messages whose timestamp is not an ISO 8601 time are
+	// skipped rather than failing the cast for the whole query.
+	query := `
+	WITH buckets AS (
+		SELECT generate_series(date_trunc($2, $3::timestamptz), $4::timestamptz, $5::interval) AS bucket
+	),
+	searches AS (
+		SELECT date_trunc($2, created_at) AS bucket, COUNT(*) AS n
+		FROM llm_interactions
+		WHERE user_id = $1 AND created_at >= $3 AND created_at < $4
+		GROUP BY 1
+	),
+	favorites AS (
+		SELECT date_trunc($2, timestamp) AS bucket, COUNT(*) AS n
+		FROM poi_interactions
+		WHERE user_id = $1 AND interaction_type = 'favorite' AND timestamp >= $3 AND timestamp < $4
+		GROUP BY 1
+	),
+	itineraries AS (
+		SELECT date_trunc($2, created_at) AS bucket, COUNT(*) AS n
+		FROM (
+			SELECT created_at FROM user_saved_itineraries
+			WHERE user_id = $1 AND created_at >= $3 AND created_at < $4
+			UNION ALL
+			SELECT created_at FROM lists
+			WHERE user_id = $1 AND is_itinerary AND created_at >= $3 AND created_at < $4
+		) itinerary_actions
+		GROUP BY 1
+	),
+	messages AS (
+		SELECT date_trunc($2, t.sent_at) AS bucket, COUNT(*) AS n
+		FROM chat_sessions cs
+		CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(cs.conversation_history) = 'array' THEN cs.conversation_history ELSE '[]'::jsonb END
+		) m
+		CROSS JOIN LATERAL (
+			SELECT CASE
+				WHEN m->>'timestamp' ~ '^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])[T ]([01]\d|2[0-3]):[0-5]\d:[0-5]\d'
+				THEN (m->>'timestamp')::timestamptz
+			END AS sent_at
+		) t
+		WHERE cs.user_id = $1
+		  AND cs.updated_at >= $3
+		  AND m->>'role' = 'user'
+		  AND t.sent_at >= $3
+		  AND t.sent_at < $4
+		GROUP BY 1
+	)
+	SELECT b.bucket, COALESCE(s.n, 0), COALESCE(f.n, 0), COALESCE(i.n, 0), COALESCE(m.n, 0)
+	FROM buckets b
+	LEFT JOIN searches s ON s.bucket = b.bucket
+	LEFT JOIN favorites f ON f.bucket = b.bucket
+	LEFT JOIN itineraries i ON i.bucket = b.bucket
+	LEFT JOIN messages m ON m.bucket = b.bucket
+	ORDER BY b.bucket
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, userID, truncUnit, start, end, step)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get user activity analytics", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get user activity analytics: %w", err)
+	}
+	defer rows.Close()
+
+	analytics := &locitypes.UserActivityAnalytics{}
+	for rows.Next() {
+		var p locitypes.ActivityDataPoint
+		if err := rows.Scan(&p.Bucket, &p.Searches, &p.Favorites, &p.ItineraryActions, &p.ChatMessages); err != nil {
+			return nil, fmt.Errorf("failed to scan activity bucket: %w", err)
+		}
+		analytics.Points = append(analytics.Points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activity buckets: %w", err)
+	}
+
+	hours, days, err := r.activityTimes(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	analytics.MostActiveHour = maxIndex(hours[:])
+	analytics.MostActiveDay = busiestDay(days)
+
+	return analytics, nil
+}
+
+func (r *RepositoryImpl) GetUserEngagement(ctx context.Context, userID uuid.UUID, since time.Time) (*locitypes.UserEngagement, error) {
+	r.logger.InfoContext(ctx, "Getting user engagement", slog.String("user_id", userID.String()))
+
+	var engagement locitypes.UserEngagement
+	err := r.pgpool.QueryRow(ctx, `
+	SELECT
+		(SELECT COUNT(*) FROM llm_interactions WHERE user_id = $1 AND created_at >= $2),
+		(SELECT COUNT(*) FROM poi_interactions
+			WHERE user_id = $1 AND interaction_type = 'favorite' AND timestamp >= $2),
+		(SELECT COUNT(DISTINCT lower(city_name)) FROM llm_interactions
+			WHERE user_id = $1 AND created_at >= $2 AND city_name <> '')
+	`, userID, since).Scan(&engagement.Searches, &engagement.Favorites, &engagement.VisitedCities)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get user engagement", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get user engagement: %w", err)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT lower(poi_category)
+	FROM poi_interactions
+	WHERE user_id = $1 AND timestamp >= $2 AND poi_category <> ''
+	GROUP BY 1
+	ORDER BY COUNT(*) DESC, 1
+	LIMIT 5
+	`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user top categories: %w", err)
+	}
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		engagement.TopCategories = append(engagement.TopCategories, category)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+
+	rows, err = r.pgpool.Query(ctx, `
+	WITH searches AS (
+		SELECT
+			lower(city_name) AS city_key,
+			(array_agg(city_id) FILTER (WHERE city_id IS NOT NULL))[1] AS city_id,
+			MIN(city_name) AS city_name,
+			COUNT(*) AS n,
+			MAX(created_at) AS last_at
+		FROM llm_interactions
+		WHERE user_id = $1 AND created_at >= $2 AND city_name <> ''
+		GROUP BY 1
+	),
+	favorites AS (
+		SELECT lower(lsp.city_name) AS city_key, COUNT(*) AS n, MAX(f.added_at) AS last_at
+		FROM user_favorite_llm_pois f
+		JOIN llm_suggested_pois lsp ON lsp.id = f.llm_poi_id
+		WHERE f.user_id = $1 AND f.added_at >= $2
+		GROUP BY 1
+	),
+	itineraries AS (
+		SELECT lower(c.name) AS city_key, COUNT(*) AS n, MAX(usi.created_at) AS last_at
+		FROM user_saved_itineraries usi
+		JOIN cities c ON c.id = usi.primary_city_id
+		WHERE usi.user_id = $1 AND usi.created_at >= $2
+		GROUP BY 1
+	)
+	SELECT s.city_id, s.city_name, s.n, COALESCE(f.n, 0), COALESCE(i.n, 0),
+	       GREATEST(s.last_at, f.last_at, i.last_at)
+	FROM searches s
+	LEFT JOIN favorites f ON f.city_key = s.city_key
+	LEFT JOIN itineraries i ON i.city_key = s.city_key
+	ORDER BY s.n DESC, s.city_name
+	LIMIT 10
+	`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user city interactions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c locitypes.CityInteraction
+		if err := rows.Scan(&c.CityID, &c.CityName, &c.SearchCount, &c.Favorites, &c.Itineraries, &c.LastInteraction); err != nil {
+			return nil, fmt.Errorf("failed to scan city interaction: %w", err)
+		}
+		engagement.Cities = append(engagement.Cities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating city interactions: %w", err)
+	}
+
+	hours, _, err := r.activityTimes(ctx, userID, since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	engagement.HourlyActivity = hours
+
+	return &engagement, nil
+}
+
+func (r *RepositoryImpl) GetUserRecentActivity(ctx context.Context, userID uuid.UUID) (*locitypes.UserRecentActivity, error) {
+	var activity locitypes.UserRecentActivity
+	err := r.pgpool.QueryRow(ctx, `
+	SELECT
+		(SELECT COUNT(*) FROM llm_interactions WHERE user_id = $1 AND created_at >= NOW() - INTERVAL '7 days'),
+		(SELECT COUNT(*) FROM poi_interactions
+			WHERE user_id = $1 AND interaction_type = 'favorite' AND timestamp >= NOW() - INTERVAL '7 days'),
+		(SELECT COUNT(*) FROM user_saved_itineraries WHERE user_id = $1 AND created_at >= date_trunc('month', NOW()))
+	`, userID).Scan(&activity.SearchesThisWeek, &activity.FavoritesThisWeek, &activity.ItinerariesThisMonth)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get user recent activity", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get user recent activity: %w", err)
+	}
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT MIN(city_name)
+	FROM llm_interactions
+	WHERE user_id = $1 AND city_name <> ''
+	GROUP BY lower(city_name)
+	ORDER BY MAX(created_at) DESC
+	LIMIT 5
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently searched cities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city); err != nil {
+			return nil, fmt.Errorf("failed to scan city: %w", err)
+		}
+		activity.RecentCities = append(activity.RecentCities, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cities: %w", err)
+	}
+
+	return &activity, nil
+}
+
+func (r *RepositoryImpl) GetSystemAnalytics(ctx context.Context, since time.Time) (*locitypes.SystemAnalytics, error) {
+	r.logger.InfoContext(ctx, "Getting system analytics", slog.Time("since", since))
+
+	analytics := &locitypes.SystemAnalytics{Since: since, GeneratedAt: time.Now()}
+	growth := &analytics.UserGrowth
+
+	var newPrevWeek, newPrevMonth int
+	err := r.pgpool.QueryRow(ctx, `
+	SELECT
+		COUNT(*),
+		COUNT(*) FILTER (WHERE created_at >= date_trunc('day', NOW())),
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days'),
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '14 days' AND created_at < NOW() - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '60 days' AND created_at < NOW() - INTERVAL '30 days')
+	FROM users
+	WHERE is_active = TRUE
+	`).Scan(&growth.TotalUsers, &growth.NewUsersToday, &growth.NewUsersThisWeek, &growth.NewUsersThisMonth,
+		&newPrevWeek, &newPrevMonth)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get user growth", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get user growth: %w", err)
+	}
+	growth.GrowthRateWeekly = growthPercentage(growth.NewUsersThisWeek, newPrevWeek)
+	growth.GrowthRateMonthly = growthPercentage(growth.NewUsersThisMonth, newPrevMonth)
+
+	// Retention: share of users active in the previous window who came back in the current one.
+	var activeLastWeek, retainedWeek, activeLastMonth, retainedMonth int
+	err = r.pgpool.QueryRow(ctx, `
+	WITH activity AS (
+		SELECT user_id, created_at AS at FROM llm_interactions
+		WHERE user_id IS NOT NULL AND created_at >= NOW() - INTERVAL '60 days'
+		UNION ALL
+		SELECT user_id, timestamp FROM poi_interactions WHERE timestamp >= NOW() - INTERVAL '60 days'
+	),
+	windows AS (
+		SELECT
+			user_id,
+			bool_or(at >= date_trunc('day', NOW())) AS today,
+			bool_or(at >= NOW() - INTERVAL '7 days') AS this_week,
+			bool_or(at >= NOW() - INTERVAL '14 days' AND at < NOW() - INTERVAL '7 days') AS last_week,
+			bool_or(at >= NOW() - INTERVAL '30 days') AS this_month,
+			bool_or(at < NOW() - INTERVAL '30 days') AS last_month
+		FROM activity
+		GROUP BY user_id
+	)
+	SELECT
+		COUNT(*) FILTER (WHERE today),
+		COUNT(*) FILTER (WHERE this_week),
+		COUNT(*) FILTER (WHERE last_week),
+		COUNT(*) FILTER (WHERE last_week AND this_week),
+		COUNT(*) FILTER (WHERE last_month),
+		COUNT(*) FILTER (WHERE last_month AND this_month)
+	FROM windows
+	`).Scan(&growth.ActiveUsersToday, &growth.ActiveUsersThisWeek, &activeLastWeek, &retainedWeek,
+		&activeLastMonth, &retainedMonth)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get active users", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+	growth.RetentionRateWeekly = percentage(retainedWeek, activeLastWeek)
+	growth.RetentionRateMonthly = percentage(retainedMonth, activeLastMonth)
+
+	var periodSearches, periodUsers, periodErrors int
+	err = r.pgpool.QueryRow(ctx, `
+	SELECT
+		COUNT(*),
+		COUNT(*) FILTER (WHERE created_at >= date_trunc('day', NOW())),
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE created_at >= $1),
+		COUNT(DISTINCT user_id) FILTER (WHERE created_at >= $1),
+		COALESCE(AVG(latency_ms) FILTER (WHERE created_at >= $1), 0)::float8,
+		COUNT(*) FILTER (WHERE status_code >= 400 AND created_at >= date_trunc('day', NOW())),
+		COUNT(*) FILTER (WHERE status_code >= 400 AND created_at >= NOW() - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE status_code >= 400 AND created_at >= $1)
+	FROM llm_interactions
+	`, since).Scan(&analytics.Usage.TotalSearches, &analytics.Usage.SearchesToday, &analytics.Usage.SearchesThisWeek,
+		&periodSearches, &periodUsers, &analytics.Usage.AverageResponseTimeMs,
+		&analytics.Errors.ErrorsToday, &analytics.Errors.ErrorsThisWeek, &periodErrors)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get usage metrics", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get usage metrics: %w", err)
+	}
+	if periodUsers > 0 {
+		analytics.Usage.AverageSearchesPerUser = float64(periodSearches) / float64(periodUsers)
+	}
+	analytics.Errors.ErrorRate = percentage(periodErrors, periodSearches)
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT
+		CASE
+			WHEN status_code = 429 THEN 'rate_limited'
+			WHEN status_code IN (408, 504) THEN 'timeout'
+			WHEN status_code >= 500 THEN 'server_error'
+			ELSE 'client_error'
+		END AS error_type,
+		COUNT(*)
+	FROM llm_interactions
+	WHERE status_code >= 400 AND created_at >= $1
+	GROUP BY 1
+	ORDER BY 2 DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get error breakdown: %w", err)
+	}
+	for rows.Next() {
+		var e locitypes.ErrorCount
+		if err := rows.Scan(&e.ErrorType, &e.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan error breakdown: %w", err)
+		}
+		analytics.Errors.Breakdown = append(analytics.Errors.Breakdown, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error breakdown: %w", err)
+	}
+
+	rows, err = r.pgpool.Query(ctx, `
+	SELECT c.country, COUNT(DISTINCT li.user_id), COUNT(*)
+	FROM llm_interactions li
+	JOIN cities c ON c.id = li.city_id
+	WHERE li.created_at >= $1
+	GROUP BY c.country
+	ORDER BY 3 DESC, 1
+	LIMIT 10
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country distribution: %w", err)
+	}
+	for rows.Next() {
+		var c locitypes.CountryUsage
+		if err := rows.Scan(&c.Country, &c.UserCount, &c.SearchCount); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan country distribution: %w", err)
+		}
+		analytics.Countries = append(analytics.Countries, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating country distribution: %w", err)
+	}
+
+	rows, err = r.pgpool.Query(ctx, `
+	SELECT s.city_id, s.city_name, s.searches,
+	       (SELECT COUNT(*) FROM points_of_interest p WHERE p.city_id = s.city_id)
+	FROM (
+		SELECT
+			(array_agg(city_id) FILTER (WHERE city_id IS NOT NULL))[1] AS city_id,
+			MIN(city_name) AS city_name,
+			COUNT(*) AS searches
+		FROM llm_interactions
+		WHERE created_at >= $1 AND city_name <> ''
+		GROUP BY lower(city_name)
+	) s
+	ORDER BY s.searches DESC, s.city_name
+	LIMIT 10
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city distribution: %w", err)
+	}
+	for rows.Next() {
+		var c locitypes.CityUsage
+		if err := rows.Scan(&c.CityID, &c.CityName, &c.SearchCount, &c.POICount); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan city distribution: %w", err)
+		}
+		analytics.Cities = append(analytics.Cities, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating city distribution: %w", err)
+	}
+
+	features := &analytics.Features
+	var searchUsers, favoriteUsers, itineraryUsers, listUsers, chatUsers int
+	err = r.pgpool.QueryRow(ctx, `
+	SELECT
+		(SELECT COUNT(*) FROM llm_interactions WHERE created_at >= $1),
+		(SELECT COUNT(*) FROM poi_interactions WHERE interaction_type = 'favorite' AND timestamp >= $1),
+		(SELECT COUNT(*) FROM user_saved_itineraries WHERE created_at >= $1),
+		(SELECT COUNT(*) FROM lists WHERE created_at >= $1),
+		(SELECT COUNT(*) FROM chat_sessions WHERE created_at >= $1),
+		(SELECT COUNT(DISTINCT user_id) FROM llm_interactions WHERE created_at >= $1),
+		(SELECT COUNT(DISTINCT user_id) FROM poi_interactions WHERE interaction_type = 'favorite' AND timestamp >= $1),
+		(SELECT COUNT(DISTINCT user_id) FROM user_saved_itineraries WHERE created_at >= $1),
+		(SELECT COUNT(DISTINCT user_id) FROM lists WHERE created_at >= $1),
+		(SELECT COUNT(DISTINCT user_id) FROM chat_sessions WHERE created_at >= $1)
+	`, since).Scan(&features.Searches, &features.FavoritesAdded, &features.ItinerariesCreated, &features.ListsCreated,
+		&features.ChatSessions, &searchUsers, &favoriteUsers, &itineraryUsers, &listUsers, &chatUsers)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get feature usage", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to get feature usage: %w", err)
+	}
+	features.FeatureUsers = map[string]int{
+		"searches":    searchUsers,
+		"favorites":   favoriteUsers,
+		"itineraries": itineraryUsers,
+		"lists":       listUsers,
+		"chat":        chatUsers,
+	}
+
+	analytics.ActiveDBConnections = int(r.pgpool.Stat().AcquiredConns())
+
+	return analytics, nil
+}
+
+// activityTimes counts a user's searches and POI interactions by hour of day and weekday.
+func (r *RepositoryImpl) activityTimes(ctx context.Context, userID uuid.UUID, start, end time.Time) ([24]int, map[string]int, error) {
+	var hours [24]int
+	days := make(map[string]int)
+
+	rows, err := r.pgpool.Query(ctx, `
+	SELECT EXTRACT(HOUR FROM at)::int, trim(to_char(at, 'Day')), COUNT(*)
+	FROM (
+		SELECT created_at AS at FROM llm_interactions WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+		UNION ALL
+		SELECT timestamp FROM poi_interactions WHERE user_id = $1 AND timestamp >= $2 AND timestamp < $3
+	) activity
+	GROUP BY 1, 2
+	`, userID, start, end)
+	if err != nil {
+		return hours, nil, fmt.Errorf("failed to get activity times: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hour, count int
+		var day string
+		if err := rows.Scan(&hour, &day, &count); err != nil {
+			return hours, nil, fmt.Errorf("failed to scan activity time: %w", err)
+		}
+		if hour >= 0 && hour < len(hours) {
+			hours[hour] += count
+		}
+		days[strings.ToLower(day)] += count
+	}
+	if err := rows.Err(); err != nil {
+		return hours, nil, fmt.Errorf("error iterating activity times: %w", err)
+	}
+	return hours, days, nil
+}
+
+// growthPercentage compares a window with the preceding one. Anything new counts as 100%.
+func growthPercentage(current, previous int) float64 {
+	if previous == 0 {
+		if current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return float64(current-previous) / float64(previous) * 100
+}
+
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func maxIndex(counts []int) int {
+	best := 0
+	for i, c := range counts {
+		if c > counts[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func busiestDay(days map[string]int) string {
+	var best string
+	for day, count := range days {
+		if count > days[best] || (count == days[best] && day < best) {
+			best = day
+		}
+	}
+	return best
+}
@@ -2,29 +2,96 @@ package statistics
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
+const (
+	// DefaultTimeRange is used when a request does not specify one.
+	DefaultTimeRange = "30d"
+
+	// overviewCacheTTL bounds how often main page subscribers hit the database;
+	// every stream shares the same snapshot within this window.
+	overviewCacheTTL = 10 * time.Second
+
+	popularDestinationsLimit = 5
+	trendingCategoriesLimit  = 5
+	maxActivityBuckets       = 500
+)
+
+var timeRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+}
+
+// granularities maps the supported analytics granularities to date_trunc units and step intervals.
+var granularities = map[string]struct {
+	trunc string
+	step  string
+	size  time.Duration
+}{
+	"hour":  {trunc: "hour", step: "1 hour", size: time.Hour},
+	"day":   {trunc: "day", step: "1 day", size: 24 * time.Hour},
+	"week":  {trunc: "week", step: "1 week", size: 7 * 24 * time.Hour},
+	"month": {trunc: "month", step: "1 month", size: 30 * 24 * time.Hour},
+}
+
+// ParseTimeRange resolves a time range label such as "7d" to its window.
+// An empty label resolves to DefaultTimeRange.
+func ParseTimeRange(label string) (string, time.Duration, error) {
+	if label == "" {
+		label = DefaultTimeRange
+	}
+	window, ok := timeRanges[label]
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported time range %q: %w", label, locitypes.ErrBadRequest)
+	}
+	return label, window, nil
+}
+
 var _ Service = (*ServiceImpl)(nil)
 
 type Service interface {
 	GetMainPageStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.MainPageStatistics, error)
 	GetDetailedPOIStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.DetailedPOIStatistics, error)
 	GetLandingPageStatistics(ctx context.Context, userID uuid.UUID) (*locitypes.LandingPageUserStats, error)
+	GetMainPageOverview(ctx context.Context, timeRange string) (*locitypes.MainPageOverview, error)
+	GetUserActivityAnalytics(ctx context.Context, userID uuid.UUID, start, end time.Time, granularity string) (*locitypes.UserActivityAnalytics, error)
+	GetUserEngagement(ctx context.Context, userID uuid.UUID, timeRange string) (*locitypes.UserEngagement, error)
+	GetUserRecentActivity(ctx context.Context, userID uuid.UUID) (*locitypes.UserRecentActivity, error)
+	GetSystemAnalytics(ctx context.Context, timeRange string) (*locitypes.SystemAnalytics, error)
+}
+
+type cachedOverview struct {
+	overview  *locitypes.MainPageOverview
+	fetchedAt time.Time
 }
 
 type ServiceImpl struct {
 	repo   Repository
 	logger *slog.Logger
+
+	// overviewMu guards overviews only; fetches for a time range are collapsed by
+	// overviewFetches, so different ranges never wait on each other.
+	overviewMu      sync.Mutex
+	overviews       map[string]cachedOverview
+	overviewFetches singleflight.Group
 }
 
 func NewService(repo Repository, logger *slog.Logger) *ServiceImpl {
 	return &ServiceImpl{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		logger:    logger,
+		overviews: make(map[string]cachedOverview),
 	}
 }
 
@@ -63,3 +130,133 @@ func (s *ServiceImpl) GetLandingPageStatistics(ctx context.Context, userID uuid.
 	l.InfoContext(ctx, "Successfully retrieved landing page statistics")
 	return stats, nil
 }
+
+// GetMainPageOverview returns the public main page snapshot with trends over timeRange.
+// Snapshots are cached briefly so that many concurrent streams share one set of queries.
+func (s *ServiceImpl) GetMainPageOverview(ctx context.Context, timeRange string) (*locitypes.MainPageOverview, error) {
+	l := s.logger.With(slog.String("method", "GetMainPageOverview"))
+	label, window, err := ParseTimeRange(timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	s.overviewMu.Lock()
+	cached, ok := s.overviews[label]
+	s.overviewMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < overviewCacheTTL {
+		return cached.overview, nil
+	}
+
+	// Concurrent misses share one fetch, which must outlive the caller that started it.
+	fetchCtx := context.WithoutCancel(ctx)
+	v, err, _ := s.overviewFetches.Do(label, func() (any, error) {
+		return s.fetchMainPageOverview(fetchCtx, l, label, window)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*locitypes.MainPageOverview), nil
+}
+
+func (s *ServiceImpl) fetchMainPageOverview(ctx context.Context, l *slog.Logger, label string, window time.Duration) (*locitypes.MainPageOverview, error) {
+	totals, err := s.repo.GetMainPageStatistics(ctx, uuid.Nil)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get main page totals", "error", err)
+		return nil, err
+	}
+	activity, err := s.repo.GetPlatformActivity(ctx)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get platform activity", "error", err)
+		return nil, err
+	}
+	since := time.Now().Add(-window)
+	destinations, err := s.repo.GetPopularDestinations(ctx, since, popularDestinationsLimit)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get popular destinations", "error", err)
+		return nil, err
+	}
+	categories, err := s.repo.GetTrendingCategories(ctx, since, trendingCategoriesLimit)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get trending categories", "error", err)
+		return nil, err
+	}
+
+	overview := &locitypes.MainPageOverview{
+		Totals:              *totals,
+		Activity:            *activity,
+		PopularDestinations: destinations,
+		TrendingCategories:  categories,
+		TrendPeriod:         label,
+		GeneratedAt:         time.Now(),
+	}
+	s.overviewMu.Lock()
+	s.overviews[label] = cachedOverview{overview: overview, fetchedAt: overview.GeneratedAt}
+	s.overviewMu.Unlock()
+	return overview, nil
+}
+
+func (s *ServiceImpl) GetUserActivityAnalytics(ctx context.Context, userID uuid.UUID, start, end time.Time, granularity string) (*locitypes.UserActivityAnalytics, error) {
+	l := s.logger.With(slog.String("method", "GetUserActivityAnalytics"))
+	if granularity == "" {
+		granularity = "day"
+	}
+	g, ok := granularities[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported granularity %q: %w", granularity, locitypes.ErrBadRequest)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end date must be after start date: %w", locitypes.ErrBadRequest)
+	}
+	if end.Sub(start)/g.size > maxActivityBuckets {
+		return nil, fmt.Errorf("date range too large for %s granularity: %w", granularity, locitypes.ErrBadRequest)
+	}
+
+	analytics, err := s.repo.GetUserActivityAnalytics(ctx, userID, start, end, g.trunc, g.step)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get user activity analytics", "error", err)
+		return nil, err
+	}
+	return analytics, nil
+}
+
+func (s *ServiceImpl) GetUserEngagement(ctx context.Context, userID uuid.UUID, timeRange string) (*locitypes.UserEngagement, error) {
+	l := s.logger.With(slog.String("method", "GetUserEngagement"))
+	_, window, err := ParseTimeRange(timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	engagement, err := s.repo.GetUserEngagement(ctx, userID, time.Now().Add(-window))
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get user engagement", "error", err)
+		return nil, err
+	}
+	return engagement, nil
+}
+
+func (s *ServiceImpl) GetUserRecentActivity(ctx context.Context, userID uuid.UUID) (*locitypes.UserRecentActivity, error) {
+	l := s.logger.With(slog.String("method", "GetUserRecentActivity"))
+	activity, err := s.repo.GetUserRecentActivity(ctx, userID)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get user recent activity", "error", err)
+		return nil, err
+	}
+	return activity, nil
+}
+
+func (s *ServiceImpl) GetSystemAnalytics(ctx context.Context, timeRange string) (*locitypes.SystemAnalytics, error) {
+	l := s.logger.With(slog.String("method", "GetSystemAnalytics"))
+	_, window, err := ParseTimeRange(timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics, err := s.repo.GetSystemAnalytics(ctx, time.Now().Add(-window))
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get system analytics", "error", err)
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "Successfully retrieved system analytics")
+	return analytics, nil
+}
@@ -0,0 +1,73 @@
+package statistics
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// overviewRepo implements only the queries behind GetMainPageOverview; anything else panics.
+// Fetches with a window of blockWindow wait for release.
+type overviewRepo struct {
+	Repository
+
+	blockWindow time.Duration
+	release     chan struct{}
+	fetches     atomic.Int32
+}
+
+func (r *overviewRepo) GetMainPageStatistics(context.Context, uuid.UUID) (*locitypes.MainPageStatistics, error) {
+	r.fetches.Add(1)
+	return &locitypes.MainPageStatistics{TotalUsersCount: 1}, nil
+}
+
+func (r *overviewRepo) GetPlatformActivity(context.Context) (*locitypes.PlatformActivity, error) {
+	return &locitypes.PlatformActivity{}, nil
+}
+
+func (r *overviewRepo) GetPopularDestinations(_ context.Context, since time.Time, _ int) ([]locitypes.PopularDestination, error) {
+	if time.Since(since).Round(time.Hour) == r.blockWindow {
+		<-r.release
+	}
+	return nil, nil
+}
+
+func (r *overviewRepo) GetTrendingCategories(context.Context, time.Time, int) ([]locitypes.CategoryTrend, error) {
+	return nil, nil
+}
+
+func TestGetMainPageOverview_SharesFetchesPerTimeRange(t *testing.T) {
+	repo := &overviewRepo{blockWindow: timeRanges["7d"], release: make(chan struct{})}
+	svc := NewService(repo, testutil.NewLogger())
+
+	// Callers of a slow range share its fetch and do not hold up other ranges.
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			overview, err := svc.GetMainPageOverview(context.Background(), "7d")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "7d", overview.TrendPeriod)
+			}
+		}()
+	}
+	require.Eventually(t, func() bool { return repo.fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+	overview, err := svc.GetMainPageOverview(context.Background(), "30d")
+	require.NoError(t, err)
+	require.Equal(t, "30d", overview.TrendPeriod)
+
+	close(repo.release)
+	wg.Wait()
+	require.Equal(t, int32(2), repo.fetches.Load())
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/usage"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// UsageHandler serves the caller's LLM usage as JSON. There is no Connect service for
+// usage yet, so it is a plain HTTP endpoint behind AuthInterceptor.HTTPMiddleware.
+type UsageHandler struct {
+	service usage.Service
+	logger  *slog.Logger
+}
+
+// NewUsageHandler creates a new UsageHandler.
+func NewUsageHandler(svc usage.Service, logger *slog.Logger) *UsageHandler {
+	return &UsageHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP handles GET /v1/usage.
+func (h *UsageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userIDStr, ok := interceptors.GetUserIDFromContext(r.Context())
+	if !ok || userIDStr == "" {
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusUnauthorized)
+		return
+	}
+
+	summary, err := h.service.GetUsage(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get usage", slog.String("user_id", userIDStr), slog.Any("error", err))
+		http.Error(w, "failed to get usage", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode usage", slog.Any("error", err))
+	}
+}
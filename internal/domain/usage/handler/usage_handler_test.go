@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/usage"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	usage.Service
+	requested uuid.UUID
+}
+
+func (s *stubService) GetUsage(_ context.Context, userID uuid.UUID) (*locitypes.UsageSummary, error) {
+	s.requested = userID
+	remaining := int64(400)
+	return &locitypes.UsageSummary{
+		Plan:  locitypes.PlanFree,
+		Daily: locitypes.UsageWindow{Limit: 1000, Used: 600, Remaining: &remaining},
+	}, nil
+}
+
+func TestUsageHandler_ReturnsCallerUsage(t *testing.T) {
+	svc := &stubService{}
+	h := NewUsageHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	userID := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/usage", nil)
+	req = req.WithContext(context.WithValue(req.Context(), interceptors.UserIDKey, userID.String()))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, userID, svc.requested)
+	var got locitypes.UsageSummary
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, locitypes.PlanFree, got.Plan)
+	require.NotNil(t, got.Daily.Remaining)
+	assert.Equal(t, int64(400), *got.Daily.Remaining)
+}
+
+func TestUsageHandler_RequiresUser(t *testing.T) {
+	h := NewUsageHandler(&stubService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/usage", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,48 @@
+package usage
+
+import "strings"
+
+// Price is the list price of a model in USD per million tokens.
+type Price struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// prices is keyed by model name prefix so dated or suffixed versions
+// ("gemini-2.0-flash-001", "gpt-4o-mini-2024-07-18") resolve to their family.
+// The longest matching prefix wins.
+var prices = map[string]Price{
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-1.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 5},
+	"gemini-1.5-flash":      {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gpt-4o":                {InputPerMillion: 2.50, OutputPerMillion: 10},
+	"gpt-4o-mini":           {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+}
+
+// PriceFor returns the price of model. Unknown models, such as self-hosted ones,
+// are free.
+func PriceFor(model string) (Price, bool) {
+	model = strings.ToLower(strings.TrimPrefix(model, "models/"))
+	var (
+		best    Price
+		bestLen int
+	)
+	for prefix, p := range prices {
+		if len(prefix) > bestLen && strings.HasPrefix(model, prefix) {
+			best, bestLen = p, len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// Cost returns the USD cost of a call to model.
+func Cost(model string, promptTokens, completionTokens int) float64 {
+	p, ok := PriceFor(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1_000_000
+}
@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+var (
+	_ Repository                       = (*RepositoryImpl)(nil)
+	_ locitypes.SubscriptionRepository = (*RepositoryImpl)(nil)
+)
+
+type Repository interface {
+	locitypes.SubscriptionRepository
+
+	InsertUsage(ctx context.Context, record locitypes.LLMUsageRecord) error
+	// GetUsageTotals sums the ledger from dayStart and from monthStart onwards.
+	GetUsageTotals(ctx context.Context, userID uuid.UUID, dayStart, monthStart time.Time) (*locitypes.LLMUsageTotals, error)
+}
+
+type RepositoryImpl struct {
+	pgpool *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewRepository(pgpool *pgxpool.Pool, logger *slog.Logger) *RepositoryImpl {
+	return &RepositoryImpl{
+		pgpool: pgpool,
+		logger: logger,
+	}
+}
+
+// GetCurrentSubscriptionByUserID returns the user's subscription row, or nil when the
+// user has none.
+func (r *RepositoryImpl) GetCurrentSubscriptionByUserID(ctx context.Context, userID string) (*locitypes.Subscription, error) {
+	var sub locitypes.Subscription
+	err := r.pgpool.QueryRow(ctx, `
+		SELECT plan::text, status::text
+		FROM subscriptions
+		WHERE user_id = $1`, userID).Scan(&sub.Plan, &sub.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get subscription for user %s: %w", userID, err)
+	}
+	return &sub, nil
+}
+
+// CreateDefaultSubscription gives the user an active free plan unless they already
+// have a subscription.
+func (r *RepositoryImpl) CreateDefaultSubscription(ctx context.Context, userID string) error {
+	_, err := r.pgpool.Exec(ctx, `
+		INSERT INTO subscriptions (user_id, plan, status)
+		VALUES ($1, 'free', 'active')
+		ON CONFLICT (user_id) DO NOTHING`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to create default subscription for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+func (r *RepositoryImpl) InsertUsage(ctx context.Context, record locitypes.LLMUsageRecord) error {
+	_, err := r.pgpool.Exec(ctx, `
+		INSERT INTO llm_usage (
+			user_id, provider, model_name, prompt_tokens, completion_tokens, total_tokens, cost_usd, estimated
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		record.UserID,
+		record.Provider,
+		record.ModelName,
+		record.PromptTokens,
+		record.CompletionTokens,
+		record.PromptTokens+record.CompletionTokens,
+		record.CostUSD,
+		record.Estimated,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert llm usage: %w", err)
+	}
+	return nil
+}
+
+func (r *RepositoryImpl) GetUsageTotals(ctx context.Context, userID uuid.UUID, dayStart, monthStart time.Time) (*locitypes.LLMUsageTotals, error) {
+	var totals locitypes.LLMUsageTotals
+	err := r.pgpool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(total_tokens) FILTER (WHERE created_at >= $2), 0),
+			COALESCE(SUM(total_tokens), 0),
+			COALESCE(SUM(cost_usd), 0)::float8,
+			COUNT(*)
+		FROM llm_usage
+		WHERE user_id = $1 AND created_at >= $3`,
+		userID, dayStart, monthStart,
+	).Scan(&totals.DailyTokens, &totals.MonthlyTokens, &totals.MonthlyCostUSD, &totals.MonthlyRequests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum llm usage for user %s: %w", userID, err)
+	}
+	return &totals, nil
+}
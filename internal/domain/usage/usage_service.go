@@ -0,0 +1,209 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+var (
+	_ Service                   = (*ServiceImpl)(nil)
+	_ llm.UsageSink             = (*ServiceImpl)(nil)
+	_ interceptors.QuotaChecker = (*ServiceImpl)(nil)
+	_ interceptors.PlanResolver = (*ServiceImpl)(nil)
+)
+
+// recordUsageTimeout bounds the ledger insert, which runs detached from the request so
+// a client hanging up mid-stream is still billed for what was generated.
+const recordUsageTimeout = 5 * time.Second
+
+type Service interface {
+	RecordUsage(ctx context.Context, usage llm.Usage)
+	CheckQuota(ctx context.Context, userID string) (interceptors.QuotaStatus, error)
+	GetUsage(ctx context.Context, userID uuid.UUID) (*locitypes.UsageSummary, error)
+	PlanForUser(ctx context.Context, userID string) (string, error)
+}
+
+// PlanQuota caps the tokens a plan may consume. Zero means uncapped.
+type PlanQuota struct {
+	DailyTokens   int64
+	MonthlyTokens int64
+}
+
+// Quotas maps subscription plans to their caps. Plans missing from the map fall back
+// to the free quota.
+type Quotas map[string]PlanQuota
+
+type ServiceImpl struct {
+	repo   Repository
+	quotas Quotas
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewService(repo Repository, quotas Quotas, logger *slog.Logger) *ServiceImpl {
+	return &ServiceImpl{
+		repo:   repo,
+		quotas: quotas,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// RecordUsage prices a completed LLM call and writes it to the caller's ledger. Calls
+// made outside an authenticated request, e.g. by background workers, are not metered.
+func (s *ServiceImpl) RecordUsage(ctx context.Context, usage llm.Usage) {
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Skipping usage for invalid user id", slog.String("user_id", userIDStr))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordUsageTimeout)
+	defer cancel()
+
+	record := locitypes.LLMUsageRecord{
+		UserID:           userID,
+		Provider:         usage.Provider,
+		ModelName:        usage.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		CostUSD:          Cost(usage.Model, usage.PromptTokens, usage.CompletionTokens),
+		Estimated:        usage.Estimated,
+	}
+	if err := s.repo.InsertUsage(ctx, record); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record LLM usage",
+			slog.String("user_id", userIDStr),
+			slog.String("model", usage.Model),
+			slog.Int("total_tokens", usage.TotalTokens()),
+			slog.Any("error", err))
+	}
+}
+
+// CheckQuota returns the user's consumption against their plan for the current UTC day
+// and month.
+func (s *ServiceImpl) CheckQuota(ctx context.Context, userID string) (interceptors.QuotaStatus, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return interceptors.QuotaStatus{}, fmt.Errorf("%w: invalid user id", locitypes.ErrBadRequest)
+	}
+	status, _, err := s.quotaStatus(ctx, id)
+	return status, err
+}
+
+// PlanForUser returns the plan the user is currently entitled to.
+func (s *ServiceImpl) PlanForUser(ctx context.Context, userID string) (string, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid user id", locitypes.ErrBadRequest)
+	}
+	return s.currentPlan(ctx, id)
+}
+
+// GetUsage returns the user's usage summary for display.
+func (s *ServiceImpl) GetUsage(ctx context.Context, userID uuid.UUID) (*locitypes.UsageSummary, error) {
+	ctx, span := otel.Tracer("UsageService").Start(ctx, "GetUsage", trace.WithAttributes(
+		attribute.String("user_id", userID.String()),
+	))
+	defer span.End()
+
+	status, totals, err := s.quotaStatus(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get usage")
+		return nil, err
+	}
+
+	summary := &locitypes.UsageSummary{
+		Plan: status.Plan,
+		Daily: locitypes.UsageWindow{
+			Limit:    status.DailyLimit,
+			Used:     status.DailyUsed,
+			ResetsAt: status.DailyResetAt,
+		},
+		Monthly: locitypes.UsageWindow{
+			Limit:    status.MonthlyLimit,
+			Used:     status.MonthlyUsed,
+			ResetsAt: status.MonthlyResetAt,
+		},
+		MonthlyCostUSD:  totals.MonthlyCostUSD,
+		MonthlyRequests: totals.MonthlyRequests,
+	}
+	if status.DailyLimit > 0 {
+		remaining := status.DailyRemaining()
+		summary.Daily.Remaining = &remaining
+	}
+	if status.MonthlyLimit > 0 {
+		remaining := status.MonthlyRemaining()
+		summary.Monthly.Remaining = &remaining
+	}
+	span.SetStatus(codes.Ok, "usage retrieved")
+	return summary, nil
+}
+
+func (s *ServiceImpl) quotaStatus(ctx context.Context, userID uuid.UUID) (interceptors.QuotaStatus, *locitypes.LLMUsageTotals, error) {
+	plan, err := s.currentPlan(ctx, userID)
+	if err != nil {
+		return interceptors.QuotaStatus{}, nil, err
+	}
+
+	now := s.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	totals, err := s.repo.GetUsageTotals(ctx, userID, dayStart, monthStart)
+	if err != nil {
+		return interceptors.QuotaStatus{}, nil, err
+	}
+
+	quota := s.quotaFor(plan)
+	return interceptors.QuotaStatus{
+		Plan:           plan,
+		DailyLimit:     quota.DailyTokens,
+		DailyUsed:      totals.DailyTokens,
+		DailyResetAt:   dayStart.AddDate(0, 0, 1),
+		MonthlyLimit:   quota.MonthlyTokens,
+		MonthlyUsed:    totals.MonthlyTokens,
+		MonthlyResetAt: monthStart.AddDate(0, 1, 0),
+	}, totals, nil
+}
+
+// currentPlan resolves the plan the user is entitled to right now. Users without a
+// subscription, or whose subscription lapsed, are on the free plan.
+func (s *ServiceImpl) currentPlan(ctx context.Context, userID uuid.UUID) (string, error) {
+	sub, err := s.repo.GetCurrentSubscriptionByUserID(ctx, userID.String())
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return locitypes.PlanFree, nil
+	}
+	switch sub.Status {
+	case "active", "trialing":
+		return sub.Plan, nil
+	default:
+		return locitypes.PlanFree, nil
+	}
+}
+
+func (s *ServiceImpl) quotaFor(plan string) PlanQuota {
+	if q, ok := s.quotas[plan]; ok {
+		return q
+	}
+	return s.quotas[locitypes.PlanFree]
+}
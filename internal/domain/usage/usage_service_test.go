@@ -0,0 +1,129 @@
+package usage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/llm"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+type fakeRepository struct {
+	subscription *locitypes.Subscription
+	totals       locitypes.LLMUsageTotals
+	inserted     []locitypes.LLMUsageRecord
+
+	dayStart, monthStart time.Time
+}
+
+func (f *fakeRepository) GetCurrentSubscriptionByUserID(context.Context, string) (*locitypes.Subscription, error) {
+	return f.subscription, nil
+}
+
+func (f *fakeRepository) CreateDefaultSubscription(context.Context, string) error { return nil }
+
+func (f *fakeRepository) InsertUsage(_ context.Context, record locitypes.LLMUsageRecord) error {
+	f.inserted = append(f.inserted, record)
+	return nil
+}
+
+func (f *fakeRepository) GetUsageTotals(_ context.Context, _ uuid.UUID, dayStart, monthStart time.Time) (*locitypes.LLMUsageTotals, error) {
+	f.dayStart, f.monthStart = dayStart, monthStart
+	totals := f.totals
+	return &totals, nil
+}
+
+var testQuotas = Quotas{
+	locitypes.PlanFree:           {DailyTokens: 1000, MonthlyTokens: 10000},
+	locitypes.PlanPremiumMonthly: {DailyTokens: 0, MonthlyTokens: 500000},
+}
+
+func newTestService(repo *fakeRepository) *ServiceImpl {
+	svc := NewService(repo, testQuotas, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.now = func() time.Time { return time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC) }
+	return svc
+}
+
+func TestCost(t *testing.T) {
+	assert.InDelta(t, 0.00015+0.0006, Cost("gpt-4o-mini-2024-07-18", 1000, 1000), 1e-12)
+	assert.InDelta(t, 0.0025+0.01, Cost("gpt-4o", 1000, 1000), 1e-12)
+	assert.InDelta(t, 0.000075+0.0003, Cost("models/gemini-2.0-flash-lite", 1000, 1000), 1e-12, "longest prefix wins")
+	assert.Zero(t, Cost("llama3.1", 1000, 1000))
+}
+
+func TestRecordUsage_PricesCallForAuthenticatedUser(t *testing.T) {
+	repo := &fakeRepository{}
+	svc := newTestService(repo)
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+
+	svc.RecordUsage(ctx, llm.Usage{Provider: llm.ProviderGoogle, Model: "gemini-2.0-flash", PromptTokens: 2000, CompletionTokens: 500})
+	svc.RecordUsage(context.Background(), llm.Usage{Model: "gemini-2.0-flash", PromptTokens: 10})
+
+	require.Len(t, repo.inserted, 1, "calls without a user are not metered")
+	got := repo.inserted[0]
+	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, "gemini-2.0-flash", got.ModelName)
+	assert.InDelta(t, 0.0002+0.0002, got.CostUSD, 1e-12)
+}
+
+func TestCheckQuota_FreePlanExceeded(t *testing.T) {
+	repo := &fakeRepository{totals: locitypes.LLMUsageTotals{DailyTokens: 1000, MonthlyTokens: 4000}}
+	svc := newTestService(repo)
+
+	status, err := svc.CheckQuota(context.Background(), uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, locitypes.PlanFree, status.Plan, "users without a subscription are on the free plan")
+	assert.True(t, status.Exceeded())
+	assert.Zero(t, status.DailyRemaining())
+	assert.Equal(t, int64(6000), status.MonthlyRemaining())
+
+	assert.Equal(t, time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), repo.dayStart)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), repo.monthStart)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), status.DailyResetAt)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), status.MonthlyResetAt)
+}
+
+func TestCheckQuota_PlanResolution(t *testing.T) {
+	repo := &fakeRepository{
+		subscription: &locitypes.Subscription{Plan: locitypes.PlanPremiumMonthly, Status: "active"},
+		totals:       locitypes.LLMUsageTotals{DailyTokens: 5000, MonthlyTokens: 5000},
+	}
+	svc := newTestService(repo)
+
+	status, err := svc.CheckQuota(context.Background(), uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, locitypes.PlanPremiumMonthly, status.Plan)
+	assert.False(t, status.Exceeded(), "premium has no daily cap")
+
+	repo.subscription.Status = "canceled"
+	status, err = svc.CheckQuota(context.Background(), uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, locitypes.PlanFree, status.Plan, "lapsed subscriptions fall back to free")
+	assert.True(t, status.Exceeded())
+}
+
+func TestGetUsage_Summary(t *testing.T) {
+	repo := &fakeRepository{
+		subscription: &locitypes.Subscription{Plan: locitypes.PlanPremiumMonthly, Status: "trialing"},
+		totals:       locitypes.LLMUsageTotals{DailyTokens: 100, MonthlyTokens: 2500, MonthlyCostUSD: 0.42, MonthlyRequests: 7},
+	}
+	svc := newTestService(repo)
+
+	summary, err := svc.GetUsage(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, locitypes.PlanPremiumMonthly, summary.Plan)
+	assert.Nil(t, summary.Daily.Remaining)
+	require.NotNil(t, summary.Monthly.Remaining)
+	assert.Equal(t, int64(497500), *summary.Monthly.Remaining)
+	assert.InDelta(t, 0.42, summary.MonthlyCostUSD, 1e-9)
+	assert.Equal(t, int64(7), summary.MonthlyRequests)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"connectrpc.com/connect"
+
+	commonpb "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/common"
+	userv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user/userconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/user"
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/user/presenter"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// UserHandler implements the UserServiceHandler interface.
+type UserHandler struct {
+	userconnect.UnimplementedUserServiceHandler
+	service user.UserService
+	logger  *slog.Logger
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(svc user.UserService, logger *slog.Logger) *UserHandler {
+	return &UserHandler{
+		service: svc,
+		logger:  logger,
+	}
+}
+
+// GetUserProfile returns the caller's profile. Profiles hold contact details, so
+// requesting another user's profile is rejected.
+func (h *UserHandler) GetUserProfile(
+	ctx context.Context,
+	req *connect.Request[userv1.GetUserProfileRequest],
+) (*connect.Response[userv1.GetUserProfileResponse], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := h.service.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	return connect.NewResponse(&userv1.GetUserProfileResponse{
+		Profile: presenter.ToProtoUserProfile(profile),
+	}), nil
+}
+
+func (h *UserHandler) UpdateUserProfile(
+	ctx context.Context,
+	req *connect.Request[userv1.UpdateUserProfileRequest],
+) (*connect.Response[commonpb.Response], error) {
+	userID, err := interceptors.AuthorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if req.Msg.GetParams() == nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("params are required"))
+	}
+	if req.Msg.GetParams().Email != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("email cannot be changed through the profile; use AuthService/ChangeEmail"))
+	}
+
+	if err := h.service.UpdateUserProfile(ctx, userID, presenter.FromUpdateProfileParams(req.Msg.GetParams())); err != nil {
+		return nil, h.toConnectError(err)
+	}
+
+	message := "profile updated"
+	return connect.NewResponse(&commonpb.Response{Success: true, Message: &message}), nil
+}
+
+func (h *UserHandler) toConnectError(err error) error {
+	switch {
+	case errors.Is(err, locitypes.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, locitypes.ErrBadRequest):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, locitypes.ErrConflict):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	default:
+		h.logger.Error("user request failed", slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
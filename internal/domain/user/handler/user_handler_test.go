@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	userv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user"
+	"github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user/userconnect"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/domain/user"
+	"github.com/FACorreiaa/loci-connect-api/internal/testutil"
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+	"github.com/FACorreiaa/loci-connect-api/pkg/interceptors"
+)
+
+// stubService implements only the methods the handler calls; anything else panics.
+type stubService struct {
+	user.UserService
+	profile    *locitypes.UserProfile
+	err        error
+	lastUserID uuid.UUID
+	lastParams locitypes.UpdateProfileParams
+}
+
+func (s *stubService) GetUserProfile(_ context.Context, userID uuid.UUID) (*locitypes.UserProfile, error) {
+	s.lastUserID = userID
+	return s.profile, s.err
+}
+
+func (s *stubService) UpdateUserProfile(_ context.Context, userID uuid.UUID, params locitypes.UpdateProfileParams) error {
+	s.lastUserID = userID
+	s.lastParams = params
+	return s.err
+}
+
+func authedContext(userID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), interceptors.UserIDKey, userID.String())
+}
+
+func TestGetUserProfile_MapsProfile(t *testing.T) {
+	userID := uuid.New()
+	age := 31
+	verified := time.Now().Add(-time.Hour)
+	svc := &stubService{profile: &locitypes.UserProfile{
+		ID:              userID,
+		Email:           "ana@example.com",
+		Age:             &age,
+		Interests:       []string{"museums"},
+		Stats:           &locitypes.UserStats{ReviewsWritten: 4},
+		EmailVerifiedAt: &verified,
+		IsActive:        true,
+		CreatedAt:       time.Now(),
+	}}
+	h := NewUserHandler(svc, testutil.NewLogger())
+
+	resp, err := h.GetUserProfile(authedContext(userID), connect.NewRequest(&userv1.GetUserProfileRequest{}))
+	require.NoError(t, err)
+	require.Equal(t, userID, svc.lastUserID)
+	profile := resp.Msg.GetProfile()
+	require.Equal(t, int32(31), profile.GetAge())
+	require.Equal(t, int32(4), profile.GetStats().GetReviewsWritten())
+	require.NotNil(t, profile.EmailVerifiedAt)
+	require.Nil(t, profile.LastLoginAt)
+}
+
+func TestGetUserProfile_RejectsOtherUsers(t *testing.T) {
+	h := NewUserHandler(&stubService{}, testutil.NewLogger())
+	other := uuid.NewString()
+
+	_, err := h.GetUserProfile(authedContext(uuid.New()), connect.NewRequest(&userv1.GetUserProfileRequest{UserId: &other}))
+	require.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+}
+
+func TestUpdateUserProfile_MapsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected connect.Code
+	}{
+		{name: "duplicate username", err: fmt.Errorf("username taken: %w", locitypes.ErrConflict), expected: connect.CodeAlreadyExists},
+		{name: "invalid age", err: fmt.Errorf("age: %w", locitypes.ErrBadRequest), expected: connect.CodeInvalidArgument},
+		{name: "unexpected", err: fmt.Errorf("connection reset"), expected: connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(&stubService{err: tt.err}, testutil.NewLogger())
+
+			_, err := h.UpdateUserProfile(authedContext(uuid.New()), connect.NewRequest(&userv1.UpdateUserProfileRequest{
+				Params: &userv1.UpdateProfileParams{},
+			}))
+			require.Equal(t, tt.expected, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestUpdateUserProfile_RejectsEmail(t *testing.T) {
+	svc := &stubService{}
+	h := NewUserHandler(svc, testutil.NewLogger())
+	email := "new@example.com"
+
+	_, err := h.UpdateUserProfile(authedContext(uuid.New()), connect.NewRequest(&userv1.UpdateUserProfileRequest{
+		Params: &userv1.UpdateProfileParams{Email: &email},
+	}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	require.Contains(t, err.Error(), "ChangeEmail")
+	require.Equal(t, uuid.Nil, svc.lastUserID)
+}
+
+func TestUserE2E_UpdateUserProfile(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubService{}
+
+	// Stand-in for the auth interceptor: trust the test token as the user ID.
+	auth := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, req.Header().Get("X-Test-User")), req)
+		}
+	})
+
+	mux := http.NewServeMux()
+	path, h := userconnect.NewUserServiceHandler(NewUserHandler(svc, testutil.NewLogger()), connect.WithInterceptors(auth))
+	mux.Handle(path, h)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := userconnect.NewUserServiceClient(server.Client(), server.URL)
+	city := "Lisbon"
+	age := int32(29)
+	req := connect.NewRequest(&userv1.UpdateUserProfileRequest{Params: &userv1.UpdateProfileParams{
+		City:      &city,
+		Age:       &age,
+		Interests: []string{"food", "history"},
+		Badges:    []string{"legend"},
+	}})
+	req.Header().Set("X-Test-User", userID.String())
+
+	resp, err := client.UpdateUserProfile(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.Msg.GetSuccess())
+	require.Equal(t, userID, svc.lastUserID)
+	require.Equal(t, "Lisbon", *svc.lastParams.City)
+	require.Equal(t, 29, *svc.lastParams.Age)
+	require.Equal(t, []string{"food", "history"}, *svc.lastParams.Interests)
+	require.Nil(t, svc.lastParams.Badges)
+}
@@ -0,0 +1,95 @@
+package presenter
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "github.com/FACorreiaa/loci-connect-proto/gen/go/loci/user"
+
+	"github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+func ToProtoUserProfile(p *locitypes.UserProfile) *userv1.UserProfile {
+	if p == nil {
+		return nil
+	}
+	out := &userv1.UserProfile{
+		Id:              p.ID.String(),
+		Email:           p.Email,
+		Username:        p.Username,
+		Firstname:       p.Firstname,
+		Lastname:        p.Lastname,
+		PhoneNumber:     p.PhoneNumber,
+		City:            p.City,
+		Country:         p.Country,
+		AboutYou:        p.AboutYou,
+		Bio:             p.Bio,
+		Location:        p.Location,
+		JoinedDate:      timestamppb.New(p.JoinedDate),
+		Avatar:          p.Avatar,
+		Interests:       p.Interests,
+		Badges:          p.Badges,
+		DisplayName:     p.DisplayName,
+		ProfileImageUrl: p.ProfileImageURL,
+		IsActive:        p.IsActive,
+		EmailVerifiedAt: optionalTimestamp(p.EmailVerifiedAt),
+		LastLoginAt:     optionalTimestamp(p.LastLoginAt),
+		Theme:           p.Theme,
+		Language:        p.Language,
+		CreatedAt:       timestamppb.New(p.CreatedAt),
+		UpdatedAt:       timestamppb.New(p.UpdatedAt),
+	}
+	if p.Age != nil {
+		age := int32(*p.Age)
+		out.Age = &age
+	}
+	if p.Stats != nil {
+		out.Stats = &userv1.UserStats{
+			PlacesVisited:  int32(p.Stats.PlacesVisited),
+			ReviewsWritten: int32(p.Stats.ReviewsWritten),
+			ListsCreated:   int32(p.Stats.ListsCreated),
+			Followers:      int32(p.Stats.Followers),
+			Following:      int32(p.Stats.Following),
+		}
+	}
+	return out
+}
+
+// FromUpdateProfileParams maps the proto update onto partial params. Interests are only
+// applied when non-empty, since proto3 cannot tell "unset" from "cleared". Badges are
+// awarded by the platform, so client-supplied values are ignored. Email is not a
+// profile field; it changes through AuthService/ChangeEmail.
+func FromUpdateProfileParams(p *userv1.UpdateProfileParams) locitypes.UpdateProfileParams {
+	if p == nil {
+		return locitypes.UpdateProfileParams{}
+	}
+	params := locitypes.UpdateProfileParams{
+		Username:        p.Username,
+		PhoneNumber:     p.PhoneNumber,
+		DisplayName:     p.DisplayName,
+		ProfileImageURL: p.ProfileImageUrl,
+		Firstname:       p.Firstname,
+		Lastname:        p.Lastname,
+		City:            p.City,
+		Country:         p.Country,
+		AboutYou:        p.AboutYou,
+		Location:        p.Location,
+	}
+	if p.Age != nil {
+		age := int(*p.Age)
+		params.Age = &age
+	}
+	if len(p.Interests) > 0 {
+		interests := p.Interests
+		params.Interests = &interests
+	}
+	return params
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -141,7 +142,10 @@ func (r *PostgresUserRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (*
 		&user.CreatedAt,
 		&user.UpdatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", locitypes.ErrNotFound)
+		}
+		return nil, fmt.Errorf("database error fetching user: %w", err)
 	}
 
 	// Set additional fields for frontend compatibility
@@ -179,12 +183,6 @@ func (r *PostgresUserRepo) UpdateProfile(ctx context.Context, userID uuid.UUID,
 		argID++
 		span.SetAttributes(attribute.Bool("update.username", true)) // Add trace attribute
 	}
-	if params.Email != nil {
-		setClauses = append(setClauses, fmt.Sprintf("email = $%d", argID))
-		args = append(args, *params.Email)
-		argID++
-		span.SetAttributes(attribute.Bool("update.email", true))
-	}
 	if params.DisplayName != nil {
 		setClauses = append(setClauses, fmt.Sprintf("display_name = $%d", argID))
 		args = append(args, *params.DisplayName)
@@ -284,7 +282,12 @@ func (r *PostgresUserRepo) UpdateProfile(ctx context.Context, userID uuid.UUID,
 	// Execute the dynamic query
 	tag, err := r.pgpool.Exec(ctx, query, args...)
 	if err != nil {
-		// Add specific error checking (e.g., unique constraint violations on email/username if updated)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation on email/username
+			l.WarnContext(ctx, "Profile update conflicts with an existing user", slog.String("constraint", pgErr.ConstraintName))
+			span.SetStatus(codes.Error, "Duplicate email or username")
+			return fmt.Errorf("email or username already in use: %w", locitypes.ErrConflict)
+		}
 		l.ErrorContext(ctx, "Failed to execute update profile query", slog.Any("error", err))
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "DB UPDATE failed")
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/google/uuid"
 
 	"github.com/FACorreiaa/loci-connect-api/internal/types"
 )
 
+const maxProfileAge = 120
+
 // Ensure implementation satisfies the interface
 var _ UserService = (*ServiceUserImpl)(nil)
 
@@ -62,6 +65,10 @@ func (s *ServiceUserImpl) UpdateUserProfile(ctx context.Context, userID uuid.UUI
 	l := s.logger.With(slog.String("method", "UpdateUserProfile"), slog.String("userID", userID.String()))
 	l.DebugContext(ctx, "Updating user profile")
 
+	if err := validateProfileParams(params); err != nil {
+		return err
+	}
+
 	err := s.repo.UpdateProfile(ctx, userID, params)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to update user profile", slog.Any("error", err))
@@ -131,3 +138,14 @@ func (s *ServiceUserImpl) ReactivateUser(ctx context.Context, userID uuid.UUID)
 	l.InfoContext(ctx, "User reactivated successfully")
 	return nil
 }
+
+// validateProfileParams rejects values the users table would accept but the app cannot use.
+func validateProfileParams(params locitypes.UpdateProfileParams) error {
+	if params.Username != nil && strings.TrimSpace(*params.Username) == "" {
+		return fmt.Errorf("username cannot be empty: %w", locitypes.ErrBadRequest)
+	}
+	if params.Age != nil && (*params.Age < 0 || *params.Age > maxProfileAge) {
+		return fmt.Errorf("age must be between 0 and %d: %w", maxProfileAge, locitypes.ErrBadRequest)
+	}
+	return nil
+}
@@ -263,3 +263,21 @@ func TestServiceUserImpl_ReactivateUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestServiceUserImpl_UpdateUserProfile_Validation(t *testing.T) {
+	service, mockRepo := setupUserServiceTest()
+	ctx := context.Background()
+	empty := " "
+	badAge := 130
+
+	for name, params := range map[string]locitypes.UpdateProfileParams{
+		"empty username": {Username: &empty},
+		"age too high":   {Age: &badAge},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := service.UpdateUserProfile(ctx, uuid.New(), params)
+			require.ErrorIs(t, err, locitypes.ErrBadRequest)
+		})
+	}
+	mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,294 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+)
+
+// Agent events, reported through AgentOptions.OnEvent.
+const (
+	AgentEventToolCall   = "tool_call"
+	AgentEventToolResult = "tool_result"
+)
+
+const (
+	defaultAgentSteps = 5
+	// maxToolCallsPerStep bounds the calls the model may request at once; extra calls
+	// are answered with an error instead of being run.
+	maxToolCallsPerStep = 8
+	// maxToolResultEcho caps how much of a tool result is shown to the model.
+	maxToolResultEcho = 6000
+)
+
+// ErrUnknownTool is reported to the model when it calls a tool it was not given.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Tool is a function the model may call during RunAgent.
+type Tool struct {
+	Declaration *genai.FunctionDeclaration
+	// Call runs the tool with the arguments chosen by the model. The result is encoded
+	// as JSON for the model. Errors are shown to the model as the result, so it can
+	// correct its arguments or answer without the tool.
+	Call func(ctx context.Context, args map[string]any) (any, error)
+}
+
+// NewTool declares a tool whose arguments are an A. The parameters are the schema of
+// A (see SchemaFor), and the model's arguments are validated against it before call
+// runs, so a bad call is reported back to the model.
+func NewTool[A any](name, description string, call func(ctx context.Context, args A) (any, error)) Tool {
+	schema := SchemaFor[A]()
+	declaration := &genai.FunctionDeclaration{Name: name, Description: description}
+	// Tools without arguments are declared without parameters; Gemini rejects objects
+	// with no properties.
+	if len(schema.Properties) > 0 {
+		declaration.Parameters = schema
+	}
+	return Tool{
+		Declaration: declaration,
+		Call: func(ctx context.Context, raw map[string]any) (any, error) {
+			if raw == nil {
+				raw = map[string]any{}
+			}
+			data, err := json.Marshal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			if err := Validate(schema, data); err != nil {
+				return nil, err
+			}
+			var args A
+			if err := json.Unmarshal(data, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			return call(ctx, args)
+		},
+	}
+}
+
+// AgentEvent reports a tool call of an agent run.
+type AgentEvent struct {
+	Type string
+	// Step is the model call that requested the tool, starting at 1.
+	Step int
+	Tool string
+	Args map[string]any
+	// Result and Error are set on AgentEventToolResult.
+	Result   any
+	Error    string
+	Duration time.Duration
+}
+
+// AgentOptions tunes RunAgent.
+type AgentOptions struct {
+	// Name labels the run in spans and logs, e.g. "chat".
+	Name string
+	// MaxSteps bounds the model calls that may request tools (default 5). The model is
+	// then asked to answer with the results it has.
+	MaxSteps int
+	// OnEvent, when set, is called before and after every tool call.
+	OnEvent func(AgentEvent)
+	Logger  *slog.Logger
+}
+
+func (o AgentOptions) steps() int {
+	if o.MaxSteps > 0 {
+		return o.MaxSteps
+	}
+	return defaultAgentSteps
+}
+
+func (o AgentOptions) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.Default()
+}
+
+func (o AgentOptions) emit(event AgentEvent) {
+	if o.OnEvent != nil {
+		o.OnEvent(event)
+	}
+}
+
+// AgentResult is the answer of an agent run.
+type AgentResult struct {
+	Text string
+	// Steps counts the model calls made.
+	Steps     int
+	ToolCalls int
+	// StepLimitReached is set when the model still wanted tools after MaxSteps calls
+	// and had to answer without them.
+	StepLimitReached bool
+}
+
+// toolExchange is a tool call and its result, as shown to the model.
+type toolExchange struct {
+	name   string
+	args   map[string]any
+	result string
+}
+
+// RunAgent answers prompt with client, letting the model call tools. Each step sends
+// the prompt with the tool calls made so far; the run ends when the model answers
+// without calling a tool. ChatClient takes a single prompt, so earlier calls and their
+// results are written into it rather than sent as separate turns.
+func RunAgent(ctx context.Context, client ChatClient, prompt string, config *genai.GenerateContentConfig, tools []Tool, opts AgentOptions) (AgentResult, error) {
+	ctx, span := otel.Tracer("LLMAgent").Start(ctx, "RunAgent", trace.WithAttributes(
+		attribute.String("agent.name", opts.Name),
+		attribute.Int("agent.tools", len(tools)),
+		attribute.Int("agent.max_steps", opts.steps()),
+	))
+	defer span.End()
+
+	byName := make(map[string]Tool, len(tools))
+	declarations := make([]*genai.FunctionDeclaration, 0, len(tools))
+	for _, tool := range tools {
+		byName[tool.Declaration.Name] = tool
+		declarations = append(declarations, tool.Declaration)
+	}
+	withTools := &genai.GenerateContentConfig{}
+	if config != nil {
+		*withTools = *config
+	}
+	withTools.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+
+	var (
+		result    AgentResult
+		exchanges []toolExchange
+	)
+	for {
+		result.Steps++
+		final := result.Steps > opts.steps()
+		stepConfig := withTools
+		if final {
+			stepConfig = config
+		}
+
+		resp, err := client.GenerateResponse(ctx, agentPrompt(prompt, exchanges, final), stepConfig)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "model call failed")
+			return result, fmt.Errorf("agent step %d: %w", result.Steps, err)
+		}
+
+		calls := resp.FunctionCalls()
+		if len(calls) == 0 || final {
+			result.Text = resp.Text()
+			result.StepLimitReached = final
+			span.SetAttributes(
+				attribute.Int("agent.steps", result.Steps),
+				attribute.Int("agent.tool_calls", result.ToolCalls),
+				attribute.Bool("agent.step_limit_reached", final),
+			)
+			span.SetStatus(codes.Ok, "agent answered")
+			return result, nil
+		}
+
+		for i, call := range calls {
+			if i == maxToolCallsPerStep {
+				exchanges = append(exchanges, toolExchange{
+					name:   call.Name,
+					args:   call.Args,
+					result: fmt.Sprintf(`{"error":"at most %d tool calls are run per step"}`, maxToolCallsPerStep),
+				})
+				continue
+			}
+			result.ToolCalls++
+			exchanges = append(exchanges, callTool(ctx, byName, call, result.Steps, opts))
+		}
+	}
+}
+
+// callTool runs one tool call in its own span and reports it through opts.OnEvent.
+func callTool(ctx context.Context, tools map[string]Tool, call *genai.FunctionCall, step int, opts AgentOptions) toolExchange {
+	ctx, span := otel.Tracer("LLMAgent").Start(ctx, "tool."+call.Name, trace.WithAttributes(
+		attribute.String("tool.name", call.Name),
+		attribute.Int("agent.step", step),
+	))
+	defer span.End()
+
+	opts.emit(AgentEvent{Type: AgentEventToolCall, Step: step, Tool: call.Name, Args: call.Args})
+
+	start := time.Now()
+	var (
+		value any
+		err   error
+	)
+	// Unknown names are counted under one label; the model may invent any number.
+	metricLabel := "unknown"
+	if tool, ok := tools[call.Name]; ok {
+		metricLabel = call.Name
+		value, err = tool.Call(ctx, call.Args)
+	} else {
+		err = fmt.Errorf("%w %q", ErrUnknownTool, call.Name)
+	}
+	elapsed := time.Since(start)
+
+	event := AgentEvent{Type: AgentEventToolResult, Step: step, Tool: call.Name, Args: call.Args, Result: value, Duration: elapsed}
+	outcome := "ok"
+	var encoded string
+	if err == nil {
+		raw, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			err = fmt.Errorf("failed to encode result: %w", marshalErr)
+		} else {
+			encoded = string(raw)
+		}
+	}
+	if err != nil {
+		outcome = "error"
+		event.Result, event.Error = nil, err.Error()
+		raw, _ := json.Marshal(map[string]string{"error": err.Error()})
+		encoded = string(raw)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tool call failed")
+		opts.logger().WarnContext(ctx, "Agent tool call failed",
+			slog.String("agent", opts.Name),
+			slog.String("tool", call.Name),
+			slog.Any("error", err))
+	} else {
+		span.SetStatus(codes.Ok, "tool call succeeded")
+	}
+	span.SetAttributes(attribute.Int("tool.result_bytes", len(encoded)))
+	observability.LLMToolCalls.WithLabelValues(metricLabel, outcome).Inc()
+	opts.emit(event)
+
+	if len(encoded) > maxToolResultEcho {
+		encoded = encoded[:maxToolResultEcho] + " [truncated]"
+	}
+	return toolExchange{name: call.Name, args: call.Args, result: encoded}
+}
+
+func agentPrompt(prompt string, exchanges []toolExchange, final bool) string {
+	if len(exchanges) == 0 && !final {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	if len(exchanges) > 0 {
+		b.WriteString("\n\nTool calls made so far, with their results:\n")
+		for i, ex := range exchanges {
+			args, _ := json.Marshal(ex.args)
+			fmt.Fprintf(&b, "[%d] %s(%s) -> %s\n", i+1, ex.name, args, ex.result)
+		}
+	}
+	if final {
+		b.WriteString("\nNo more tools can be called. Answer the user now with the results above, and say so if they are not enough.")
+	} else {
+		b.WriteString("\nUse these results. Call another tool only if you still need information, otherwise answer the user.")
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+// agentClient answers GenerateResponse with responses in order, repeating the last.
+type agentClient struct {
+	*fakeBackend
+	responses []*genai.GenerateContentResponse
+	prompts   []string
+	configs   []*genai.GenerateContentConfig
+}
+
+func (a *agentClient) GenerateResponse(_ context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	a.prompts = append(a.prompts, prompt)
+	a.configs = append(a.configs, config)
+	resp := a.responses[0]
+	if len(a.responses) > 1 {
+		a.responses = a.responses[1:]
+	}
+	return resp, nil
+}
+
+func callResponse(calls ...*genai.FunctionCall) *genai.GenerateContentResponse {
+	parts := make([]*genai.Part, 0, len(calls))
+	for _, call := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: call})
+	}
+	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}}}
+}
+
+type lookupArgs struct {
+	City  string `json:"city" llm:"required"`
+	Limit int    `json:"limit" llm:"min=1,max=10"`
+}
+
+func TestRunAgent_CallsToolsUntilAnswer(t *testing.T) {
+	var got lookupArgs
+	lookup := NewTool("lookup", "Look a city up.", func(_ context.Context, args lookupArgs) (any, error) {
+		got = args
+		return map[string]string{"centre": "40.41,-3.70"}, nil
+	})
+	client := &agentClient{fakeBackend: &fakeBackend{}, responses: []*genai.GenerateContentResponse{
+		callResponse(&genai.FunctionCall{Name: "lookup", Args: map[string]any{"city": "Madrid", "limit": 3}}),
+		textResponse("Madrid's centre is Puerta del Sol."),
+	}}
+
+	var events []AgentEvent
+	result, err := RunAgent(context.Background(), client, "Where is the centre of Madrid?", nil, []Tool{lookup}, AgentOptions{
+		Name:    "test",
+		OnEvent: func(e AgentEvent) { events = append(events, e) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Madrid's centre is Puerta del Sol.", result.Text)
+	assert.Equal(t, 2, result.Steps)
+	assert.Equal(t, 1, result.ToolCalls)
+	assert.False(t, result.StepLimitReached)
+	assert.Equal(t, lookupArgs{City: "Madrid", Limit: 3}, got)
+
+	require.Len(t, client.prompts, 2)
+	assert.Equal(t, "Where is the centre of Madrid?", client.prompts[0])
+	assert.Contains(t, client.prompts[1], `[1] lookup({"city":"Madrid","limit":3}) -> {"centre":"40.41,-3.70"}`)
+	decl := client.configs[0].Tools[0].FunctionDeclarations[0]
+	assert.Equal(t, "lookup", decl.Name)
+	assert.Equal(t, []string{"city"}, decl.Parameters.Required)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, AgentEventToolCall, events[0].Type)
+	assert.Equal(t, AgentEventToolResult, events[1].Type)
+	assert.Equal(t, 1, events[1].Step)
+	assert.Empty(t, events[1].Error)
+}
+
+func TestRunAgent_ReportsToolErrorsToModel(t *testing.T) {
+	failing := NewTool("lookup", "", func(context.Context, lookupArgs) (any, error) {
+		return nil, errors.New("database unavailable")
+	})
+	client := &agentClient{fakeBackend: &fakeBackend{}, responses: []*genai.GenerateContentResponse{
+		callResponse(
+			&genai.FunctionCall{Name: "lookup", Args: map[string]any{"limit": 50}},
+			&genai.FunctionCall{Name: "lookup", Args: map[string]any{"city": "Porto"}},
+			&genai.FunctionCall{Name: "book_flight"},
+		),
+		textResponse("Sorry, I could not look that up."),
+	}}
+
+	var errs []string
+	result, err := RunAgent(context.Background(), client, "p", nil, []Tool{failing}, AgentOptions{
+		OnEvent: func(e AgentEvent) {
+			if e.Type == AgentEventToolResult {
+				errs = append(errs, e.Error)
+			}
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ToolCalls)
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0], "$.city: is required")
+	assert.Contains(t, errs[0], "$.limit: 50 is above the maximum 10")
+	assert.Equal(t, "database unavailable", errs[1])
+	assert.Contains(t, errs[2], `unknown tool "book_flight"`)
+	assert.Contains(t, client.prompts[1], `-> {"error":"database unavailable"}`)
+}
+
+func TestRunAgent_StepLimit(t *testing.T) {
+	calls := 0
+	tool := NewTool("lookup", "", func(context.Context, lookupArgs) (any, error) {
+		calls++
+		return "nothing", nil
+	})
+	client := &agentClient{fakeBackend: &fakeBackend{}, responses: []*genai.GenerateContentResponse{
+		callResponse(&genai.FunctionCall{Name: "lookup", Args: map[string]any{"city": "Lisbon"}}),
+	}}
+
+	result, err := RunAgent(context.Background(), client, "p", nil, []Tool{tool}, AgentOptions{MaxSteps: 2})
+	require.NoError(t, err)
+	assert.True(t, result.StepLimitReached)
+	assert.Equal(t, 3, result.Steps)
+	assert.Equal(t, 2, calls, "the final step runs no tools")
+	require.Len(t, client.configs, 3)
+	assert.Nil(t, client.configs[2], "the final step is sent without tools")
+	assert.Contains(t, client.prompts[2], "No more tools can be called")
+}
+
+func TestNewTool_NoArguments(t *testing.T) {
+	tool := NewTool("whoami", "", func(context.Context, struct{}) (any, error) { return "me", nil })
+	assert.Nil(t, tool.Declaration.Parameters)
+
+	got, err := tool.Call(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "me", got)
+}
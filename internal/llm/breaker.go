@@ -0,0 +1,195 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker trips after consecutive failures or when the smoothed latency of
+// successful calls exceeds maxLatency. Once the cooldown passes a single probe is let
+// through; its outcome closes or re-opens the circuit.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	failureThreshold int
+	cooldown         time.Duration
+	maxLatency       time.Duration
+	now              func() time.Time
+
+	state       breakerState
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	latencyEWMA time.Duration
+}
+
+// latencyAlpha weights the newest sample in the latency moving average.
+const latencyAlpha = 0.3
+
+func newCircuitBreaker(failureThreshold int, cooldown, maxLatency time.Duration, now func() time.Time) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		maxLatency:       maxLatency,
+		now:              now,
+	}
+}
+
+// allow reports whether a call may proceed. In the half-open state only one probe is
+// in flight at a time.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) onSuccess(latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.latencyEWMA == 0 || b.state == breakerHalfOpen {
+		b.latencyEWMA = latency
+	} else {
+		b.latencyEWMA = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(b.latencyEWMA))
+	}
+	b.failures = 0
+	b.probing = false
+
+	if b.maxLatency > 0 && b.latencyEWMA > b.maxLatency {
+		b.trip()
+		return
+	}
+	b.state = breakerClosed
+}
+
+func (b *circuitBreaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+		b.trip()
+	}
+}
+
+// release frees a half-open probe slot without judging the backend, e.g. when the
+// caller cancelled the request.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = breakerOpen
+	b.openedAt = b.now()
+	b.failures = 0
+}
+
+func (b *circuitBreaker) snapshot() (breakerState, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.latencyEWMA
+}
+
+// retryBudget caps fallbacks to a fraction of traffic so a broad outage can't multiply
+// load on the remaining backends. Every request deposits ratio tokens, a floor of
+// minPerSecond tokens accrues over time, and each fallback spends one token.
+type retryBudget struct {
+	mu sync.Mutex
+
+	ratio        float64
+	minPerSecond float64
+	maxTokens    float64
+	now          func() time.Time
+
+	tokens float64
+	last   time.Time
+}
+
+func newRetryBudget(ratio, minPerSecond float64, now func() time.Time) *retryBudget {
+	if ratio < 0 {
+		ratio = 0
+	}
+	if minPerSecond < 0 {
+		minPerSecond = 0
+	}
+	maxTokens := max(10, minPerSecond*10)
+	return &retryBudget{
+		ratio:        ratio,
+		minPerSecond: minPerSecond,
+		maxTokens:    maxTokens,
+		now:          now,
+		tokens:       maxTokens,
+		last:         now(),
+	}
+}
+
+func (r *retryBudget) refill() {
+	now := r.now()
+	if elapsed := now.Sub(r.last); elapsed > 0 {
+		r.tokens = min(r.maxTokens, r.tokens+elapsed.Seconds()*r.minPerSecond)
+	}
+	r.last = now
+}
+
+func (r *retryBudget) deposit() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill()
+	r.tokens = min(r.maxTokens, r.tokens+r.ratio)
+}
+
+func (r *retryBudget) withdraw() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill()
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
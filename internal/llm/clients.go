@@ -38,6 +38,19 @@ func NewGeminiChatClient(ctx context.Context, apiKey string) (ChatClient, error)
 	return &GeminiChatClient{client: client}, nil
 }
 
+// NewGeminiChatClientWithModel creates a Gemini ChatClient for a specific model,
+// falling back to the SDK default when model is empty.
+func NewGeminiChatClientWithModel(ctx context.Context, apiKey, model string) (ChatClient, error) {
+	client, err := generativeAI.NewLLMChatClient(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if model != "" {
+		client.ModelName = model
+	}
+	return &GeminiChatClient{client: client}, nil
+}
+
 func (g *GeminiChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
 	return g.client.GenerateResponse(ctx, prompt, config)
 }
@@ -61,6 +74,10 @@ func (g *GeminiChatClient) Model() string {
 	return g.client.ModelName
 }
 
+func (g *GeminiChatClient) Provider() string {
+	return ProviderGoogle
+}
+
 // GeminiEmbeddingClient adapts the generativeAI embedding service.
 type GeminiEmbeddingClient struct {
 	service *generativeAI.EmbeddingService
@@ -79,6 +96,10 @@ func (g *GeminiEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, quer
 	return g.service.GenerateQueryEmbedding(ctx, query)
 }
 
+func (g *GeminiEmbeddingClient) Provider() string {
+	return ProviderGoogle
+}
+
 func (g *GeminiEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
 	return g.service.GeneratePOIEmbedding(ctx, name, description, category)
 }
@@ -0,0 +1,342 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrNoBackendAvailable is returned when every backend in a fallback chain failed,
+// was skipped by its circuit breaker, or the retry budget ran out.
+var ErrNoBackendAvailable = errors.New("no llm backend available")
+
+// statusClientClosedRequest mirrors nginx's 499 for calls abandoned by the caller.
+const statusClientClosedRequest = 499
+
+// Backend is one entry in a fallback chain. Name identifies it in logs and
+// llm_interactions, e.g. "google:gemini-2.0-flash".
+type Backend struct {
+	Name   string
+	Client ChatClient
+}
+
+// Attempt describes a failed call to one backend of a fallback chain.
+type Attempt struct {
+	Backend    string
+	Provider   string
+	Model      string
+	Prompt     string
+	StatusCode int
+	Err        error
+	Latency    time.Duration
+	Streaming  bool
+}
+
+// AttemptRecorder persists failed attempts. Successful calls are already recorded by
+// the services that make them, with the provider and model that served them.
+type AttemptRecorder interface {
+	RecordAttempt(ctx context.Context, attempt Attempt)
+}
+
+// FallbackOptions tunes a FallbackChatClient. Zero values pick the defaults noted.
+type FallbackOptions struct {
+	// FailureThreshold consecutive failures open a backend's circuit (default 5).
+	FailureThreshold int
+	// Cooldown is how long an open circuit rejects calls before probing (default 30s).
+	Cooldown time.Duration
+	// MaxLatency ejects a backend whose smoothed latency exceeds it; 0 disables.
+	// Streams are measured to the first chunk.
+	MaxLatency time.Duration
+	// RetryRatio is the fraction of requests that may fall back, plus a floor of
+	// RetryMinPerSecond fallbacks per second.
+	RetryRatio        float64
+	RetryMinPerSecond float64
+	Recorder          AttemptRecorder
+	Logger            *slog.Logger
+}
+
+type fallbackBackend struct {
+	Backend
+	provider string
+	breaker  *circuitBreaker
+}
+
+// FallbackChatClient tries backends in order until one succeeds. A stream only falls
+// back before its first chunk; after that a failure is returned to the caller so it
+// never sees output from two models spliced together. The backend that served a call
+// is recorded on its context; see WithServedBackend.
+type FallbackChatClient struct {
+	backends []*fallbackBackend
+	budget   *retryBudget
+	recorder AttemptRecorder
+	logger   *slog.Logger
+	now      func() time.Time
+
+	// lastServed is the index of the backend that most recently succeeded, so switches
+	// between backends are logged.
+	lastServed atomic.Int32
+}
+
+func NewFallbackChatClient(backends []Backend, opts FallbackOptions) (*FallbackChatClient, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("fallback chain needs at least one backend")
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	f := &FallbackChatClient{
+		recorder: opts.Recorder,
+		logger:   logger,
+		now:      time.Now,
+	}
+	for _, b := range backends {
+		if b.Client == nil {
+			return nil, fmt.Errorf("fallback backend %q has no client", b.Name)
+		}
+		f.backends = append(f.backends, &fallbackBackend{
+			Backend:  b,
+			provider: ProviderName(context.Background(), b.Client),
+			breaker:  newCircuitBreaker(opts.FailureThreshold, opts.Cooldown, opts.MaxLatency, f.clock),
+		})
+	}
+	f.budget = newRetryBudget(opts.RetryRatio, opts.RetryMinPerSecond, f.clock)
+	return f, nil
+}
+
+// clock indirects through f.now so tests can swap the time source after construction.
+func (f *FallbackChatClient) clock() time.Time {
+	return f.now()
+}
+
+func (f *FallbackChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return callWithFallback(f, ctx, prompt, func(_ int, c ChatClient) (*genai.GenerateContentResponse, error) {
+		return c.GenerateResponse(ctx, prompt, config)
+	})
+}
+
+// GenerateContent passes apiKey to the primary backend only; it belongs to that provider.
+func (f *FallbackChatClient) GenerateContent(ctx context.Context, prompt, apiKey string, config *genai.GenerateContentConfig) (string, error) {
+	return callWithFallback(f, ctx, prompt, func(i int, c ChatClient) (string, error) {
+		if i > 0 {
+			apiKey = ""
+		}
+		return c.GenerateContent(ctx, prompt, apiKey, config)
+	})
+}
+
+func (f *FallbackChatClient) GenerateContentStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return f.stream(ctx, prompt, func(c ChatClient) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+		return c.GenerateContentStream(ctx, prompt, config)
+	}), nil
+}
+
+func (f *FallbackChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, cacheKey string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return f.stream(ctx, prompt, func(c ChatClient) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+		return c.GenerateContentStreamWithCache(ctx, prompt, config, cacheKey)
+	}), nil
+}
+
+// Model reports the primary backend's model. ModelName reports the one that served a call.
+func (f *FallbackChatClient) Model() string {
+	return f.backends[0].Client.Model()
+}
+
+// Provider reports the primary backend's provider. ProviderName reports the one that
+// served a call.
+func (f *FallbackChatClient) Provider() string {
+	return f.backends[0].provider
+}
+
+// acquire returns whether backend b may be tried now. Every attempt after the first
+// spends retry budget.
+func (f *FallbackChatClient) acquire(ctx context.Context, b *fallbackBackend, attempted bool) error {
+	if !b.breaker.allow() {
+		return fmt.Errorf("%s: circuit open", b.Name)
+	}
+	if attempted && !f.budget.withdraw() {
+		b.breaker.release()
+		f.logger.WarnContext(ctx, "LLM retry budget exhausted", slog.String("backend", b.Name))
+		return errRetryBudgetExhausted
+	}
+	return nil
+}
+
+var errRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+func callWithFallback[T any](f *FallbackChatClient, ctx context.Context, prompt string, call func(int, ChatClient) (T, error)) (T, error) {
+	var (
+		zero      T
+		errs      []error
+		attempted bool
+	)
+	f.budget.deposit()
+	for i, b := range f.backends {
+		if err := f.acquire(ctx, b, attempted); err != nil {
+			errs = append(errs, err)
+			if errors.Is(err, errRetryBudgetExhausted) {
+				break
+			}
+			continue
+		}
+		attempted = true
+
+		start := f.now()
+		out, err := call(i, b.Client)
+		latency := f.now().Sub(start)
+		if err == nil {
+			f.succeeded(ctx, i, latency)
+			return out, nil
+		}
+
+		f.failed(ctx, b, prompt, err, latency, false)
+		if ctx.Err() != nil {
+			return zero, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+	}
+	return zero, fmt.Errorf("%w: %w", ErrNoBackendAvailable, errors.Join(errs...))
+}
+
+func (f *FallbackChatClient) stream(ctx context.Context, prompt string, open func(ChatClient) (iter.Seq2[*genai.GenerateContentResponse, error], error)) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		var (
+			errs      []error
+			attempted bool
+		)
+		f.budget.deposit()
+		for i, b := range f.backends {
+			if err := f.acquire(ctx, b, attempted); err != nil {
+				errs = append(errs, err)
+				if errors.Is(err, errRetryBudgetExhausted) {
+					break
+				}
+				continue
+			}
+			attempted = true
+
+			start := f.now()
+			seq, err := open(b.Client)
+			if err != nil {
+				f.failed(ctx, b, prompt, err, f.now().Sub(start), true)
+				if ctx.Err() != nil {
+					yield(nil, err)
+					return
+				}
+				errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+				continue
+			}
+
+			started := false
+			var streamErr error
+			for resp, err := range seq {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				if !started {
+					started = true
+					f.succeeded(ctx, i, f.now().Sub(start))
+				}
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if streamErr == nil {
+				if !started {
+					f.succeeded(ctx, i, f.now().Sub(start))
+				}
+				return
+			}
+
+			f.failed(ctx, b, prompt, streamErr, f.now().Sub(start), true)
+			if started || ctx.Err() != nil {
+				yield(nil, streamErr)
+				return
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, streamErr))
+		}
+		yield(nil, fmt.Errorf("%w: %w", ErrNoBackendAvailable, errors.Join(errs...)))
+	}
+}
+
+func (f *FallbackChatClient) succeeded(ctx context.Context, i int, latency time.Duration) {
+	b := f.backends[i]
+	b.breaker.onSuccess(latency)
+	markServed(ctx, b.provider, b.Client.Model())
+	if prev := f.lastServed.Swap(int32(i)); prev != int32(i) {
+		f.logger.Info("LLM backend switched",
+			slog.String("from", f.backends[prev].Name),
+			slog.String("to", f.backends[i].Name))
+	}
+}
+
+func (f *FallbackChatClient) failed(ctx context.Context, b *fallbackBackend, prompt string, err error, latency time.Duration, streaming bool) {
+	status := StatusCode(err)
+	switch {
+	case ctx.Err() != nil || status == statusClientClosedRequest:
+		// The caller gave up; that says nothing about the backend's health.
+		b.breaker.release()
+	case status == http.StatusBadRequest:
+		// Malformed requests fail everywhere; don't punish the backend for them.
+		b.breaker.release()
+	default:
+		b.breaker.onFailure()
+	}
+
+	f.logger.WarnContext(ctx, "LLM backend call failed",
+		slog.String("backend", b.Name),
+		slog.Int("status_code", status),
+		slog.Duration("latency", latency),
+		slog.Any("error", err))
+
+	if f.recorder != nil {
+		f.recorder.RecordAttempt(ctx, Attempt{
+			Backend:    b.Name,
+			Provider:   b.provider,
+			Model:      b.Client.Model(),
+			Prompt:     prompt,
+			StatusCode: status,
+			Err:        err,
+			Latency:    latency,
+			Streaming:  streaming,
+		})
+	}
+}
+
+// StatusCode maps an LLM client error to an HTTP-style status for llm_interactions.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	var genaiErr genai.APIError
+	if errors.As(err, &genaiErr) && genaiErr.Code != 0 {
+		return genaiErr.Code
+	}
+	var genaiErrPtr *genai.APIError
+	if errors.As(err, &genaiErrPtr) && genaiErrPtr.Code != 0 {
+		return genaiErrPtr.Code
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrNoBackendAvailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+// fakeBackend answers with text, fails with err, or fails mid-stream after
+// failAfter chunks. onCall runs at the start of every call.
+type fakeBackend struct {
+	model     string
+	provider  string
+	text      []string
+	err       error
+	failAfter int
+	onCall    func()
+	calls     int
+}
+
+func (f *fakeBackend) begin() error {
+	f.calls++
+	if f.onCall != nil {
+		f.onCall()
+	}
+	if f.failAfter == 0 {
+		return f.err
+	}
+	return nil
+}
+
+func (f *fakeBackend) GenerateResponse(context.Context, string, *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if err := f.begin(); err != nil {
+		return nil, err
+	}
+	return textResponse(f.text[0]), nil
+}
+
+func (f *fakeBackend) GenerateContent(ctx context.Context, prompt, _ string, config *genai.GenerateContentConfig) (string, error) {
+	resp, err := f.GenerateResponse(ctx, prompt, config)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+func (f *fakeBackend) GenerateContentStream(context.Context, string, *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	if err := f.begin(); err != nil {
+		return nil, err
+	}
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for i, t := range f.text {
+			if f.failAfter > 0 && i == f.failAfter {
+				yield(nil, f.err)
+				return
+			}
+			if !yield(textResponse(t), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (f *fakeBackend) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, _ string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return f.GenerateContentStream(ctx, prompt, config)
+}
+
+func (f *fakeBackend) Model() string    { return f.model }
+func (f *fakeBackend) Provider() string { return f.provider }
+
+type memoryRecorder struct {
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+func (m *memoryRecorder) RecordAttempt(_ context.Context, a Attempt) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, a)
+}
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestChain(t *testing.T, opts FallbackOptions, backends ...*fakeBackend) (*FallbackChatClient, *fakeClock) {
+	t.Helper()
+	var chain []Backend
+	for _, b := range backends {
+		chain = append(chain, Backend{Name: b.provider + ":" + b.model, Client: b})
+	}
+	f, err := NewFallbackChatClient(chain, opts)
+	require.NoError(t, err)
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	f.now = clock.now
+	return f, clock
+}
+
+func TestFallbackChatClient_FallsBackAndRecordsAttempt(t *testing.T) {
+	primary := &fakeBackend{model: "gemini-2.0-flash", provider: ProviderGoogle, err: &APIError{StatusCode: http.StatusTooManyRequests, Message: "quota"}}
+	local := &fakeBackend{model: "llama3.1", provider: ProviderOpenAI, text: []string{"hola"}}
+	recorder := &memoryRecorder{}
+	f, _ := newTestChain(t, FallbackOptions{Recorder: recorder}, primary, local)
+
+	ctx := WithServedBackend(context.Background())
+	resp, err := f.GenerateResponse(ctx, "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hola", resp.Text())
+	assert.Equal(t, "llama3.1", ModelName(ctx, f))
+	assert.Equal(t, ProviderOpenAI, ProviderName(ctx, f))
+	// Other callers are not told about a backend that served someone else's call.
+	assert.Equal(t, "gemini-2.0-flash", ModelName(context.Background(), f))
+	assert.Equal(t, ProviderGoogle, ProviderName(WithServedBackend(context.Background()), f))
+
+	require.Len(t, recorder.attempts, 1)
+	got := recorder.attempts[0]
+	assert.Equal(t, ProviderGoogle, got.Provider)
+	assert.Equal(t, "gemini-2.0-flash", got.Model)
+	assert.Equal(t, http.StatusTooManyRequests, got.StatusCode)
+	assert.Equal(t, "hi", got.Prompt)
+}
+
+func TestFallbackChatClient_AllBackendsFail(t *testing.T) {
+	a := &fakeBackend{model: "a", provider: ProviderGoogle, err: errors.New("boom")}
+	b := &fakeBackend{model: "b", provider: ProviderOpenAI, err: &APIError{StatusCode: http.StatusServiceUnavailable}}
+	f, _ := newTestChain(t, FallbackOptions{}, a, b)
+
+	_, err := f.GenerateContent(context.Background(), "hi", "", nil)
+	require.ErrorIs(t, err, ErrNoBackendAvailable)
+	assert.Equal(t, http.StatusServiceUnavailable, StatusCode(err))
+}
+
+func TestFallbackChatClient_CircuitOpensAndProbes(t *testing.T) {
+	primary := &fakeBackend{model: "a", provider: ProviderGoogle, err: &APIError{StatusCode: http.StatusInternalServerError}}
+	secondary := &fakeBackend{model: "b", provider: ProviderOpenAI, text: []string{"ok"}}
+	f, clock := newTestChain(t, FallbackOptions{FailureThreshold: 2, Cooldown: time.Minute, RetryMinPerSecond: 100}, primary, secondary)
+
+	for range 5 {
+		_, err := f.GenerateResponse(context.Background(), "hi", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, primary.calls, "open circuit must skip the primary")
+	assert.Equal(t, 5, secondary.calls)
+
+	// After the cooldown one probe goes through; it succeeds and closes the circuit.
+	clock.advance(time.Minute)
+	primary.err = nil
+	primary.text = []string{"back"}
+	resp, err := f.GenerateResponse(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "back", resp.Text())
+	assert.Equal(t, 3, primary.calls)
+	state, _ := f.backends[0].breaker.snapshot()
+	assert.Equal(t, breakerClosed, state)
+}
+
+func TestFallbackChatClient_LatencyEjection(t *testing.T) {
+	var clock *fakeClock
+	slow := &fakeBackend{model: "slow", provider: ProviderGoogle, text: []string{"late"}}
+	fast := &fakeBackend{model: "fast", provider: ProviderOpenAI, text: []string{"quick"}}
+	f, clock := newTestChain(t, FallbackOptions{MaxLatency: time.Second, Cooldown: time.Minute}, slow, fast)
+	slow.onCall = func() { clock.advance(3 * time.Second) }
+
+	resp, err := f.GenerateResponse(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "late", resp.Text(), "a slow success is still returned")
+
+	resp, err = f.GenerateResponse(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "quick", resp.Text(), "the slow backend is ejected afterwards")
+	assert.Equal(t, 1, slow.calls)
+}
+
+func TestFallbackChatClient_RetryBudget(t *testing.T) {
+	primary := &fakeBackend{model: "a", provider: ProviderGoogle, err: errors.New("down")}
+	secondary := &fakeBackend{model: "b", provider: ProviderOpenAI, text: []string{"ok"}}
+	f, _ := newTestChain(t, FallbackOptions{FailureThreshold: 1000}, primary, secondary)
+
+	var failures int
+	for range 15 {
+		if _, err := f.GenerateResponse(context.Background(), "hi", nil); err != nil {
+			failures++
+		}
+	}
+	assert.Equal(t, 10, secondary.calls, "the initial budget allows ten fallbacks")
+	assert.Equal(t, 5, failures)
+}
+
+func TestFallbackChatClient_StreamFallsBackOnlyBeforeFirstChunk(t *testing.T) {
+	failing := &fakeBackend{model: "a", provider: ProviderGoogle, err: &APIError{StatusCode: http.StatusServiceUnavailable}}
+	backup := &fakeBackend{model: "b", provider: ProviderOpenAI, text: []string{"one", "two"}}
+	f, _ := newTestChain(t, FallbackOptions{}, failing, backup)
+
+	seq, err := f.GenerateContentStreamWithCache(context.Background(), "hi", nil, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, collectText(t, seq))
+
+	midStream := &fakeBackend{model: "a", provider: ProviderGoogle, text: []string{"par", "tial"}, failAfter: 1, err: errors.New("reset")}
+	unused := &fakeBackend{model: "b", provider: ProviderOpenAI, text: []string{"never"}}
+	f, _ = newTestChain(t, FallbackOptions{}, midStream, unused)
+
+	seq, err = f.GenerateContentStream(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	var (
+		texts     []string
+		streamErr error
+	)
+	for resp, err := range seq {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		texts = append(texts, resp.Text())
+	}
+	assert.Equal(t, []string{"par"}, texts)
+	require.Error(t, streamErr)
+	assert.Zero(t, unused.calls)
+}
+
+func TestFallbackChatClient_CancelledCallerDoesNotFallBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	primary := &fakeBackend{model: "a", provider: ProviderGoogle, onCall: cancel}
+	primary.err = context.Canceled
+	secondary := &fakeBackend{model: "b", provider: ProviderOpenAI, text: []string{"ok"}}
+	f, _ := newTestChain(t, FallbackOptions{FailureThreshold: 1}, primary, secondary)
+
+	_, err := f.GenerateResponse(ctx, "hi", nil)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, secondary.calls)
+	state, _ := f.backends[0].breaker.snapshot()
+	assert.Equal(t, breakerClosed, state)
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, StatusCode(nil))
+	assert.Equal(t, http.StatusTooManyRequests, StatusCode(&APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.Equal(t, http.StatusTooManyRequests, StatusCode(genai.APIError{Code: http.StatusTooManyRequests}))
+	assert.Equal(t, http.StatusGatewayTimeout, StatusCode(context.DeadlineExceeded))
+	assert.Equal(t, statusClientClosedRequest, StatusCode(context.Canceled))
+	assert.Equal(t, http.StatusBadGateway, StatusCode(errors.New("connection reset")))
+}
@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// Usage is the token consumption of one LLM call.
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// Estimated is set when the provider reported no counts and they were derived
+	// from text length instead.
+	Estimated bool
+}
+
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// UsageSink receives the usage of every completed call. The caller's identity travels
+// in ctx.
+type UsageSink interface {
+	RecordUsage(ctx context.Context, usage Usage)
+}
+
+// MeteredChatClient reports token usage for each call to a UsageSink, attributed to
+// the backend that served the call.
+type MeteredChatClient struct {
+	inner ChatClient
+	sink  UsageSink
+}
+
+func NewMeteredChatClient(inner ChatClient, sink UsageSink) *MeteredChatClient {
+	return &MeteredChatClient{inner: inner, sink: sink}
+}
+
+func (m *MeteredChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	ctx = WithServedBackend(ctx)
+	resp, err := m.inner.GenerateResponse(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	m.sink.RecordUsage(ctx, m.usage(ctx, prompt, resp.UsageMetadata, resp.ModelVersion, resp.Text()))
+	return resp, nil
+}
+
+func (m *MeteredChatClient) GenerateContent(ctx context.Context, prompt, apiKey string, config *genai.GenerateContentConfig) (string, error) {
+	ctx = WithServedBackend(ctx)
+	text, err := m.inner.GenerateContent(ctx, prompt, apiKey, config)
+	if err != nil {
+		return "", err
+	}
+	m.sink.RecordUsage(ctx, m.usage(ctx, prompt, nil, "", text))
+	return text, nil
+}
+
+func (m *MeteredChatClient) GenerateContentStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	ctx = WithServedBackend(ctx)
+	seq, err := m.inner.GenerateContentStream(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	return m.meter(ctx, prompt, seq), nil
+}
+
+func (m *MeteredChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, cacheKey string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	ctx = WithServedBackend(ctx)
+	seq, err := m.inner.GenerateContentStreamWithCache(ctx, prompt, config, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	return m.meter(ctx, prompt, seq), nil
+}
+
+// meter records usage when the stream ends, however it ends: tokens generated before
+// an error or an abandoned read were still billed by the provider.
+func (m *MeteredChatClient) meter(ctx context.Context, prompt string, seq iter.Seq2[*genai.GenerateContentResponse, error]) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		var (
+			metadata *genai.GenerateContentResponseUsageMetadata
+			model    string
+			outRunes int
+			received bool
+		)
+		defer func() {
+			if received {
+				m.sink.RecordUsage(ctx, m.usageFromRunes(ctx, prompt, metadata, model, outRunes))
+			}
+		}()
+
+		for resp, err := range seq {
+			if resp != nil {
+				received = true
+				// Providers report cumulative counts; the last report wins.
+				if resp.UsageMetadata != nil {
+					metadata = resp.UsageMetadata
+				}
+				if resp.ModelVersion != "" {
+					model = resp.ModelVersion
+				}
+				outRunes += utf8.RuneCountInString(resp.Text())
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+func (m *MeteredChatClient) usage(ctx context.Context, prompt string, metadata *genai.GenerateContentResponseUsageMetadata, model, output string) Usage {
+	return m.usageFromRunes(ctx, prompt, metadata, model, utf8.RuneCountInString(output))
+}
+
+func (m *MeteredChatClient) usageFromRunes(ctx context.Context, prompt string, metadata *genai.GenerateContentResponseUsageMetadata, model string, outRunes int) Usage {
+	if model == "" {
+		model = ModelName(ctx, m.inner)
+	}
+	u := Usage{Provider: ProviderName(ctx, m.inner), Model: model}
+	if metadata != nil && (metadata.PromptTokenCount > 0 || metadata.CandidatesTokenCount > 0) {
+		u.PromptTokens = int(metadata.PromptTokenCount)
+		u.CompletionTokens = int(metadata.CandidatesTokenCount)
+		return u
+	}
+	u.PromptTokens = EstimateTokens(utf8.RuneCountInString(prompt))
+	u.CompletionTokens = EstimateTokens(outRunes)
+	u.Estimated = true
+	return u
+}
+
+// EstimateTokens approximates a token count from a character count using the common
+// four-characters-per-token rule of thumb.
+func EstimateTokens(chars int) int {
+	return (chars + 3) / 4
+}
+
+func (m *MeteredChatClient) Model() string {
+	return m.inner.Model()
+}
+
+func (m *MeteredChatClient) Provider() string {
+	return ProviderName(context.Background(), m.inner)
+}
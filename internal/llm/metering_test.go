@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+type memorySink struct{ usages []Usage }
+
+func (m *memorySink) RecordUsage(_ context.Context, u Usage) { m.usages = append(m.usages, u) }
+
+func TestMeteredChatClient_ReportedUsage(t *testing.T) {
+	inner := &fakeBackend{model: "gemini-2.0-flash", provider: ProviderGoogle, text: []string{"hola"}}
+	sink := &memorySink{}
+	m := NewMeteredChatClient(inner, sink)
+
+	_, err := m.GenerateResponse(context.Background(), "hello there", nil)
+	require.NoError(t, err)
+	require.Len(t, sink.usages, 1)
+	got := sink.usages[0]
+	assert.True(t, got.Estimated, "fake backend reports no usage metadata")
+	assert.Equal(t, "gemini-2.0-flash", got.Model)
+	assert.Equal(t, ProviderGoogle, got.Provider)
+	assert.Equal(t, 3, got.PromptTokens)
+	assert.Equal(t, 1, got.CompletionTokens)
+}
+
+func TestMeteredChatClient_StreamUsesLastMetadata(t *testing.T) {
+	inner := &usageStream{
+		fakeBackend: &fakeBackend{model: "gpt-4o-mini", provider: ProviderOpenAI},
+		chunks: []*genai.GenerateContentResponse{
+			textResponse("one"),
+			withUsage(textResponse("two"), 10, 2),
+			withUsage(textResponse("three"), 10, 5),
+		},
+	}
+	sink := &memorySink{}
+	m := NewMeteredChatClient(inner, sink)
+
+	seq, err := m.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "three"}, collectText(t, seq))
+	require.Len(t, sink.usages, 1)
+	assert.False(t, sink.usages[0].Estimated)
+	assert.Equal(t, 10, sink.usages[0].PromptTokens)
+	assert.Equal(t, 5, sink.usages[0].CompletionTokens)
+
+	// A consumer that stops early is still billed for what it received.
+	seq, err = m.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	for range seq {
+		break
+	}
+	require.Len(t, sink.usages, 2)
+	assert.True(t, sink.usages[1].Estimated)
+}
+
+// usageStream streams fixed responses, which may carry usage metadata.
+type usageStream struct {
+	*fakeBackend
+	chunks []*genai.GenerateContentResponse
+}
+
+func (u *usageStream) GenerateContentStream(context.Context, string, *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, c := range u.chunks {
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func withUsage(resp *genai.GenerateContentResponse, prompt, completion int32) *genai.GenerateContentResponse {
+	resp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     prompt,
+		CandidatesTokenCount: completion,
+	}
+	return resp
+}
@@ -0,0 +1,522 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+const (
+	defaultOpenAITimeout = 60 * time.Second
+	// maxStreamLineBytes bounds a single SSE line; JSON chunks from local servers can be large.
+	maxStreamLineBytes = 1 << 20
+)
+
+// APIError is returned when an OpenAI-compatible server answers with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("llm api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// OpenAIConfig configures clients for the OpenAI chat completions and embeddings APIs,
+// or any server that implements them (llama.cpp, Ollama, vLLM).
+type OpenAIConfig struct {
+	BaseURL        string
+	APIKey         string
+	ChatModel      string
+	EmbeddingModel string
+	// EmbeddingDimensions truncates embeddings server-side; the vector columns are 768-d.
+	EmbeddingDimensions int
+	Timeout             time.Duration
+	HTTPClient          *http.Client
+}
+
+func (c OpenAIConfig) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultOpenAITimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// OpenAIChatClient implements ChatClient against an OpenAI-compatible /chat/completions endpoint.
+// Responses are converted to genai types so callers stay provider-agnostic.
+type OpenAIChatClient struct {
+	cfg    OpenAIConfig
+	client *http.Client
+}
+
+// NewOpenAIChatClient creates a ChatClient for an OpenAI-compatible server.
+func NewOpenAIChatClient(cfg OpenAIConfig) (*OpenAIChatClient, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("openai base URL is required")
+	}
+	if cfg.ChatModel == "" {
+		return nil, errors.New("openai chat model is required")
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &OpenAIChatClient{cfg: cfg, client: cfg.httpClient()}, nil
+}
+
+type openAIMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIFunctionDecl `json:"function"`
+}
+
+type openAIFunctionDecl struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+		// Arguments is a JSON object encoded as a string.
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Temperature    *float32              `json:"temperature,omitempty"`
+	TopP           *float32              `json:"top_p,omitempty"`
+	MaxTokens      int32                 `json:"max_tokens,omitempty"`
+	Stop           []string              `json:"stop,omitempty"`
+	Seed           *int32                `json:"seed,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	Tools          []openAITool          `json:"tools,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int32 `json:"prompt_tokens"`
+	CompletionTokens int32 `json:"completion_tokens"`
+	TotalTokens      int32 `json:"total_tokens"`
+}
+
+type openAIChoice struct {
+	Index        int32          `json:"index"`
+	Message      *openAIMessage `json:"message,omitempty"`
+	Delta        *openAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+}
+
+type openAIChatResponse struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+}
+
+type openAIErrorBody struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *OpenAIChatClient) buildRequest(prompt string, config *genai.GenerateContentConfig, stream bool) openAIChatRequest {
+	req := openAIChatRequest{Model: c.cfg.ChatModel}
+	if config != nil {
+		if system := contentText(config.SystemInstruction); system != "" {
+			req.Messages = append(req.Messages, openAIMessage{Role: "system", Content: system})
+		}
+		req.Temperature = config.Temperature
+		req.TopP = config.TopP
+		req.MaxTokens = config.MaxOutputTokens
+		req.Stop = config.StopSequences
+		req.Seed = config.Seed
+		switch {
+		case config.ResponseSchema != nil:
+			req.ResponseFormat = &openAIResponseFormat{
+				Type:       "json_schema",
+				JSONSchema: &openAIJSONSchema{Name: "response", Schema: jsonSchema(config.ResponseSchema)},
+			}
+		case config.ResponseMIMEType == "application/json":
+			req.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+		}
+		for _, tool := range config.Tools {
+			for _, decl := range tool.FunctionDeclarations {
+				fn := openAIFunctionDecl{Name: decl.Name, Description: decl.Description}
+				if decl.Parameters != nil {
+					fn.Parameters = jsonSchema(decl.Parameters)
+				}
+				req.Tools = append(req.Tools, openAITool{Type: "function", Function: fn})
+			}
+		}
+	}
+	req.Messages = append(req.Messages, openAIMessage{Role: "user", Content: prompt})
+	if stream {
+		req.Stream = true
+		req.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	return req
+}
+
+func (c *OpenAIChatClient) do(ctx context.Context, apiKey string, body openAIChatRequest) (*http.Response, error) {
+	if apiKey == "" {
+		apiKey = c.cfg.APIKey
+	}
+	return postJSON(ctx, c.client, c.cfg.BaseURL+"/chat/completions", apiKey, body)
+}
+
+func (c *OpenAIChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return c.generate(ctx, prompt, "", config)
+}
+
+func (c *OpenAIChatClient) generate(ctx context.Context, prompt, apiKey string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	resp, err := c.do(ctx, apiKey, c.buildRequest(prompt, config, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode chat completion: %w", err)
+	}
+	return toGenAIResponse(&out, false), nil
+}
+
+// GenerateContent returns the text of the first candidate. apiKey overrides the configured key when set.
+func (c *OpenAIChatClient) GenerateContent(ctx context.Context, prompt, apiKey string, config *genai.GenerateContentConfig) (string, error) {
+	resp, err := c.generate(ctx, prompt, apiKey, config)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+// GenerateContentStream starts a streamed completion. The request is sent immediately so
+// connection and status errors surface here; the caller must range over the iterator to
+// release the response body.
+func (c *OpenAIChatClient) GenerateContentStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	resp, err := c.do(ctx, "", c.buildRequest(prompt, config, true))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue // blank separators, comments and event: lines
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				return
+			}
+
+			var chunk openAIChatResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			if !yield(toGenAIResponse(&chunk, true), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+		}
+	}, nil
+}
+
+// GenerateContentStreamWithCache streams without server-side caching; OpenAI-compatible
+// servers apply prompt caching automatically, so the key is ignored.
+func (c *OpenAIChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, _ string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return c.GenerateContentStream(ctx, prompt, config)
+}
+
+func (c *OpenAIChatClient) Model() string {
+	return c.cfg.ChatModel
+}
+
+func (c *OpenAIChatClient) Provider() string {
+	return ProviderOpenAI
+}
+
+// OpenAIEmbeddingClient implements EmbeddingClient against an OpenAI-compatible /embeddings endpoint.
+type OpenAIEmbeddingClient struct {
+	cfg    OpenAIConfig
+	client *http.Client
+}
+
+// NewOpenAIEmbeddingClient creates an EmbeddingClient for an OpenAI-compatible server.
+func NewOpenAIEmbeddingClient(cfg OpenAIConfig) (*OpenAIEmbeddingClient, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("openai base URL is required")
+	}
+	if cfg.EmbeddingModel == "" {
+		return nil, errors.New("openai embedding model is required")
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &OpenAIEmbeddingClient{cfg: cfg, client: cfg.httpClient()}, nil
+}
+
+type openAIEmbeddingRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (c *OpenAIEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	return c.embed(ctx, query)
+}
+
+func (c *OpenAIEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
+	return c.embed(ctx, poiEmbeddingText(name, description, category))
+}
+
+func (c *OpenAIEmbeddingClient) embed(ctx context.Context, input string) ([]float32, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("embedding input is empty")
+	}
+	resp, err := postJSON(ctx, c.client, c.cfg.BaseURL+"/embeddings", c.cfg.APIKey, openAIEmbeddingRequest{
+		Model:      c.cfg.EmbeddingModel,
+		Input:      input,
+		Dimensions: c.cfg.EmbeddingDimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	if len(out.Data) == 0 || len(out.Data[0].Embedding) == 0 {
+		return nil, errors.New("embedding response contained no vectors")
+	}
+	return out.Data[0].Embedding, nil
+}
+
+// poiEmbeddingText renders a POI as a single passage so name, category and description
+// all contribute to the vector.
+func poiEmbeddingText(name, description, category string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if category != "" {
+		fmt.Fprintf(&b, " (%s)", category)
+	}
+	if description != "" {
+		b.WriteString(": ")
+		b.WriteString(description)
+	}
+	return b.String()
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("llm request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		message := strings.TrimSpace(string(raw))
+		var errBody openAIErrorBody
+		if json.Unmarshal(raw, &errBody) == nil && errBody.Error.Message != "" {
+			message = errBody.Error.Message
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: message}
+	}
+	return resp, nil
+}
+
+func toGenAIResponse(in *openAIChatResponse, stream bool) *genai.GenerateContentResponse {
+	out := &genai.GenerateContentResponse{
+		ResponseID:   in.ID,
+		ModelVersion: in.Model,
+	}
+	for _, choice := range in.Choices {
+		msg := choice.Message
+		if stream {
+			msg = choice.Delta
+		}
+		candidate := &genai.Candidate{Index: choice.Index}
+		if msg != nil {
+			candidate.Content = toGenAIContent(msg)
+		}
+		if choice.FinishReason != nil {
+			candidate.FinishReason = finishReason(*choice.FinishReason)
+		}
+		out.Candidates = append(out.Candidates, candidate)
+	}
+	if in.Usage != nil {
+		out.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     in.Usage.PromptTokens,
+			CandidatesTokenCount: in.Usage.CompletionTokens,
+			TotalTokenCount:      in.Usage.TotalTokens,
+		}
+	}
+	return out
+}
+
+// toGenAIContent converts a reply message, turning tool calls into function call parts.
+// It returns nil for an empty message.
+func toGenAIContent(msg *openAIMessage) *genai.Content {
+	var parts []*genai.Part
+	if msg.Content != "" {
+		parts = append(parts, genai.NewPartFromText(msg.Content))
+	}
+	for _, call := range msg.ToolCalls {
+		var args map[string]any
+		if call.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				// Keep the call so the caller can report the bad arguments to the model.
+				args = map[string]any{"invalid_arguments": call.Function.Arguments}
+			}
+		}
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   call.ID,
+			Name: call.Function.Name,
+			Args: args,
+		}})
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &genai.Content{Role: genai.RoleModel, Parts: parts}
+}
+
+func finishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "stop", "tool_calls":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+func contentText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var parts []string
+	for _, p := range c.Parts {
+		if p != nil && p.Text != "" {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// jsonSchema converts a Gemini schema to the JSON Schema OpenAI expects. Strict mode is
+// left off, as it requires every property; replies are validated by the caller.
+func jsonSchema(s *genai.Schema) map[string]any {
+	out := map[string]any{}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Type != "" {
+		typ := strings.ToLower(string(s.Type))
+		if s.Nullable != nil && *s.Nullable {
+			out["type"] = []string{typ, "null"}
+		} else {
+			out["type"] = typ
+		}
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if s.Minimum != nil {
+		out["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		out["maximum"] = *s.Maximum
+	}
+	if s.MinItems != nil {
+		out["minItems"] = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		out["maxItems"] = *s.MaxItems
+	}
+	if s.Items != nil {
+		out["items"] = jsonSchema(s.Items)
+	}
+	if s.Properties != nil {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = jsonSchema(prop)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	return out
+}
@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/config"
+)
+
+func newTestChatClient(t *testing.T, handler http.HandlerFunc) *OpenAIChatClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewOpenAIChatClient(OpenAIConfig{
+		BaseURL:   srv.URL + "/v1/",
+		APIKey:    "test-key",
+		ChatModel: "local-model",
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestOpenAIChatClient_GenerateResponse(t *testing.T) {
+	var got openAIChatRequest
+	client := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "cmpl-1",
+			"model": "local-model-q4",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "{\"city\":\"Porto\"}"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 12, "completion_tokens": 5, "total_tokens": 17}
+		}`)
+	})
+
+	resp, err := client.GenerateResponse(context.Background(), "Where should I go?", &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("You are a travel guide.", genai.RoleUser),
+		Temperature:       genai.Ptr[float32](0.2),
+		MaxOutputTokens:   256,
+		ResponseMIMEType:  "application/json",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "local-model", got.Model)
+	require.Len(t, got.Messages, 2)
+	assert.Equal(t, openAIMessage{Role: "system", Content: "You are a travel guide."}, got.Messages[0])
+	assert.Equal(t, openAIMessage{Role: "user", Content: "Where should I go?"}, got.Messages[1])
+	require.NotNil(t, got.Temperature)
+	assert.InDelta(t, 0.2, *got.Temperature, 1e-6)
+	assert.Equal(t, int32(256), got.MaxTokens)
+	require.NotNil(t, got.ResponseFormat)
+	assert.Equal(t, "json_object", got.ResponseFormat.Type)
+	assert.False(t, got.Stream)
+
+	require.Len(t, resp.Candidates, 1)
+	assert.Equal(t, `{"city":"Porto"}`, resp.Candidates[0].Content.Parts[0].Text)
+	assert.Equal(t, genai.FinishReasonStop, resp.Candidates[0].FinishReason)
+	assert.Equal(t, "local-model-q4", resp.ModelVersion)
+	require.NotNil(t, resp.UsageMetadata)
+	assert.Equal(t, int32(17), resp.UsageMetadata.TotalTokenCount)
+}
+
+func TestOpenAIChatClient_ToolCalls(t *testing.T) {
+	var got openAIChatRequest
+	client := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "get_city", "arguments": "{\"name\":\"Porto\"}"}}
+			]}, "finish_reason": "tool_calls"}]
+		}`)
+	})
+
+	resp, err := client.GenerateResponse(context.Background(), "Where is Porto?", &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+			Name:        "get_city",
+			Description: "Look a city up.",
+			Parameters: &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{"name": {Type: genai.TypeString}},
+				Required:   []string{"name"},
+			},
+		}}}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, got.Tools, 1)
+	assert.Equal(t, "function", got.Tools[0].Type)
+	assert.Equal(t, "get_city", got.Tools[0].Function.Name)
+	assert.Equal(t, "object", got.Tools[0].Function.Parameters["type"])
+
+	calls := resp.FunctionCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, &genai.FunctionCall{ID: "call_1", Name: "get_city", Args: map[string]any{"name": "Porto"}}, calls[0])
+	assert.Equal(t, genai.FinishReasonStop, resp.Candidates[0].FinishReason)
+}
+
+func TestOpenAIChatClient_GenerateContentOverridesAPIKey(t *testing.T) {
+	client := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer override", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "hello"}, "finish_reason": "length"}]}`)
+	})
+
+	text, err := client.GenerateContent(context.Background(), "hi", "override", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", text)
+}
+
+func TestOpenAIChatClient_APIError(t *testing.T) {
+	client := newTestChatClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error": {"message": "rate limited"}}`)
+	})
+
+	_, err := client.GenerateResponse(context.Background(), "hi", nil)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, "rate limited", apiErr.Message)
+
+	_, err = client.GenerateContentStream(context.Background(), "hi", nil)
+	require.True(t, errors.As(err, &apiErr))
+}
+
+func TestOpenAIChatClient_GenerateContentStream(t *testing.T) {
+	var got openAIChatRequest
+	client := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	seq, err := client.GenerateContentStreamWithCache(context.Background(), "hi", nil, "ignored")
+	require.NoError(t, err)
+
+	var (
+		text  strings.Builder
+		usage *genai.GenerateContentResponseUsageMetadata
+	)
+	for resp, err := range seq {
+		require.NoError(t, err)
+		for _, c := range resp.Candidates {
+			if c.Content != nil {
+				text.WriteString(c.Content.Parts[0].Text)
+			}
+		}
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+	}
+
+	assert.True(t, got.Stream)
+	require.NotNil(t, got.StreamOptions)
+	assert.True(t, got.StreamOptions.IncludeUsage)
+	assert.Equal(t, "Hello", text.String())
+	require.NotNil(t, usage)
+	assert.Equal(t, int32(5), usage.TotalTokenCount)
+}
+
+func TestOpenAIEmbeddingClient(t *testing.T) {
+	var got openAIEmbeddingRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"data": [{"embedding": [0.1, 0.2, 0.3]}]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewOpenAIEmbeddingClient(OpenAIConfig{
+		BaseURL:             srv.URL,
+		EmbeddingModel:      "nomic-embed-text",
+		EmbeddingDimensions: 768,
+	})
+	require.NoError(t, err)
+
+	vec, err := client.GeneratePOIEmbedding(context.Background(), "Livraria Lello", "Historic bookshop", "culture")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.1, 0.2, 0.3}, vec)
+	assert.Equal(t, "nomic-embed-text", got.Model)
+	assert.Equal(t, 768, got.Dimensions)
+	assert.Equal(t, "Livraria Lello (culture): Historic bookshop", got.Input)
+
+	_, err = client.GenerateQueryEmbedding(context.Background(), "  ")
+	require.Error(t, err)
+}
+
+func TestNewChatClientFromConfig(t *testing.T) {
+	cfg := config.LLMConfig{
+		Provider: "ollama",
+		OpenAI:   config.OpenAIConfig{BaseURL: "http://localhost:11434/v1", ChatModel: "llama3.1"},
+	}
+	client, err := NewChatClientFromConfig(context.Background(), cfg, slog.New(slog.DiscardHandler), nil)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderOpenAI, ProviderName(context.Background(), client))
+	assert.Equal(t, "llama3.1", client.Model())
+
+	_, err = NewChatClientFromConfig(context.Background(), config.LLMConfig{Provider: "unknown"}, slog.New(slog.DiscardHandler), nil)
+	require.Error(t, err)
+
+	assert.Equal(t, ProviderGoogle, ProviderName(context.Background(), struct{}{}))
+}
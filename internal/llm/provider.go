@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/config"
+)
+
+const (
+	ProviderGoogle = "google"
+	ProviderOpenAI = "openai"
+)
+
+// providerNamer is implemented by clients that know which backend they talk to.
+// It is optional so test doubles only need to satisfy ChatClient.
+type providerNamer interface {
+	Provider() string
+}
+
+// ProviderName reports the backend that served the calls made with ctx, for recording
+// on llm_interactions. It falls back to the backend behind client when ctx carries no
+// WithServedBackend record; clients that don't say are assumed to be Gemini, the
+// original backend.
+func ProviderName(ctx context.Context, client any) string {
+	if s := servedFrom(ctx); s != nil {
+		if provider, _ := s.get(); provider != "" {
+			return provider
+		}
+	}
+	if p, ok := client.(providerNamer); ok {
+		return p.Provider()
+	}
+	return ProviderGoogle
+}
+
+// ModelName reports the model that served the calls made with ctx, or client's model
+// when ctx carries no WithServedBackend record.
+func ModelName(ctx context.Context, client ChatClient) string {
+	if s := servedFrom(ctx); s != nil {
+		if _, model := s.get(); model != "" {
+			return model
+		}
+	}
+	return client.Model()
+}
+
+// NormalizeProvider maps configured provider names to the canonical constants.
+func NormalizeProvider(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ProviderGoogle, "gemini":
+		return ProviderGoogle, nil
+	case ProviderOpenAI, "openai-compatible", "ollama", "llamacpp", "llama.cpp":
+		return ProviderOpenAI, nil
+	default:
+		return "", fmt.Errorf("unknown llm provider %q", name)
+	}
+}
+
+// NormalizeMode maps the configured LLM mode to one of the Mode constants.
+func NormalizeMode(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ModeLive:
+		return ModeLive, nil
+	case ModeRecord:
+		return ModeRecord, nil
+	case ModeReplay:
+		return ModeReplay, nil
+	default:
+		return "", fmt.Errorf("unknown llm mode %q", name)
+	}
+}
+
+// NewChatClientFromConfig builds the ChatClient selected by cfg.Provider, chained with
+// cfg.Fallbacks when set, and wrapped for recording or replaced by fixtures according
+// to cfg.Mode. recorder receives failed fallback attempts and may be nil.
+func NewChatClientFromConfig(ctx context.Context, cfg config.LLMConfig, logger *slog.Logger, recorder AttemptRecorder) (ChatClient, error) {
+	mode, err := NormalizeMode(cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeReplay {
+		return NewReplayChatClient(NewFixtureStore(cfg.FixturesDir), cfg.ReplaySpeed), nil
+	}
+
+	client, err := newLiveChatClient(ctx, cfg, logger, recorder)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeRecord {
+		return NewRecordingChatClient(client, NewFixtureStore(cfg.FixturesDir), logger), nil
+	}
+	return client, nil
+}
+
+func newLiveChatClient(ctx context.Context, cfg config.LLMConfig, logger *slog.Logger, recorder AttemptRecorder) (ChatClient, error) {
+	primary, err := newBackendClient(ctx, cfg, cfg.Provider, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Fallbacks) == 0 {
+		return primary, nil
+	}
+
+	backends := []Backend{{Name: backendName(primary), Client: primary}}
+	for _, spec := range cfg.Fallbacks {
+		provider, model := ParseBackendSpec(spec)
+		client, err := newBackendClient(ctx, cfg, provider, model)
+		if err != nil {
+			return nil, fmt.Errorf("fallback backend %q: %w", spec, err)
+		}
+		backends = append(backends, Backend{Name: backendName(client), Client: client})
+	}
+
+	chain, err := NewFallbackChatClient(backends, FallbackOptions{
+		FailureThreshold:  cfg.Breaker.FailureThreshold,
+		Cooldown:          time.Duration(cfg.Breaker.CooldownSeconds) * time.Second,
+		MaxLatency:        time.Duration(cfg.Breaker.MaxLatencyMs) * time.Millisecond,
+		RetryRatio:        cfg.Breaker.RetryRatio,
+		RetryMinPerSecond: cfg.Breaker.RetryMinPerSecond,
+		Recorder:          recorder,
+		Logger:            logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// newBackendClient builds a single provider client; an empty model keeps the configured default.
+func newBackendClient(ctx context.Context, cfg config.LLMConfig, providerName, model string) (ChatClient, error) {
+	provider, err := NormalizeProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	switch provider {
+	case ProviderOpenAI:
+		oc := openAIConfig(cfg)
+		if model != "" {
+			oc.ChatModel = model
+		}
+		client, err := NewOpenAIChatClient(oc)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return NewGeminiChatClientWithModel(ctx, cfg.GeminiAPIKey, model)
+	}
+}
+
+// ParseBackendSpec splits "provider:model". Only the first colon separates, since
+// local model tags such as "llama3.1:8b" contain one.
+func ParseBackendSpec(spec string) (provider, model string) {
+	provider, model, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	return provider, model
+}
+
+func backendName(c ChatClient) string {
+	return ProviderName(context.Background(), c) + ":" + c.Model()
+}
+
+// NewEmbeddingClientFromConfig builds the EmbeddingClient selected by cfg.EmbeddingProvider.
+// Replay mode uses deterministic hash embeddings so runs stay offline; record mode
+// does not record embeddings.
+func NewEmbeddingClientFromConfig(ctx context.Context, cfg config.LLMConfig, logger *slog.Logger) (EmbeddingClient, error) {
+	mode, err := NormalizeMode(cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeReplay {
+		return NewHashEmbeddingClient(cfg.OpenAI.EmbeddingDimensions), nil
+	}
+
+	provider, err := NormalizeProvider(cfg.EmbeddingProvider)
+	if err != nil {
+		return nil, err
+	}
+	switch provider {
+	case ProviderOpenAI:
+		client, err := NewOpenAIEmbeddingClient(openAIConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return NewGeminiEmbeddingClient(ctx, logger)
+	}
+}
+
+func openAIConfig(cfg config.LLMConfig) OpenAIConfig {
+	return OpenAIConfig{
+		BaseURL:             cfg.OpenAI.BaseURL,
+		APIKey:              cfg.OpenAI.APIKey,
+		ChatModel:           cfg.OpenAI.ChatModel,
+		EmbeddingModel:      cfg.OpenAI.EmbeddingModel,
+		EmbeddingDimensions: cfg.OpenAI.EmbeddingDimensions,
+		Timeout:             time.Duration(cfg.TimeoutSeconds) * time.Second,
+	}
+}
@@ -0,0 +1,406 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Modes select whether the configured provider is called directly, called and recorded
+// to fixtures, or replaced entirely by recorded fixtures.
+const (
+	ModeLive   = "live"
+	ModeRecord = "record"
+	ModeReplay = "replay"
+
+	ProviderReplay = "replay"
+)
+
+// ErrFixtureNotFound is returned in replay mode when no fixture exists for a prompt.
+var ErrFixtureNotFound = errors.New("llm fixture not found")
+
+// Fixture is a recorded LLM exchange. A non-streamed call is stored as a single chunk,
+// so either kind of call can be replayed from either kind of recording.
+type Fixture struct {
+	Key               string         `json:"key"`
+	Provider          string         `json:"provider"`
+	Model             string         `json:"model"`
+	SystemInstruction string         `json:"system_instruction,omitempty"`
+	Prompt            string         `json:"prompt"`
+	RecordedAt        time.Time      `json:"recorded_at"`
+	Chunks            []FixtureChunk `json:"chunks"`
+}
+
+// FixtureChunk is one streamed response and the time elapsed since the previous chunk
+// (or since the request, for the first one).
+type FixtureChunk struct {
+	DelayMs  int64                          `json:"delay_ms"`
+	Response *genai.GenerateContentResponse `json:"response"`
+}
+
+// FixtureKey hashes everything that shapes the model's answer text: the system
+// instruction and the prompt. Sampling parameters are deliberately left out so
+// tuning temperature doesn't invalidate recordings.
+func FixtureKey(prompt string, config *genai.GenerateContentConfig) string {
+	h := sha256.New()
+	if config != nil {
+		h.Write([]byte(contentText(config.SystemInstruction)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(prompt))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FixtureStore reads and writes fixtures as <key>.json files in a directory.
+type FixtureStore struct {
+	dir string
+}
+
+func NewFixtureStore(dir string) *FixtureStore {
+	return &FixtureStore{dir: dir}
+}
+
+func (s *FixtureStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+func (s *FixtureStore) Load(key string) (*Fixture, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFixtureNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", key, err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", key, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture atomically so concurrent recordings of the same prompt
+// never leave a half-written file behind.
+func (s *FixtureStore) Save(fixture *Fixture) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture dir: %w", err)
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, fixture.Key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create fixture file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(fixture.Key))
+}
+
+// RecordingChatClient forwards calls to a live client and saves each completed
+// exchange as a fixture. Recording failures are logged and never fail the call.
+type RecordingChatClient struct {
+	inner  ChatClient
+	store  *FixtureStore
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewRecordingChatClient(inner ChatClient, store *FixtureStore, logger *slog.Logger) *RecordingChatClient {
+	return &RecordingChatClient{inner: inner, store: store, logger: logger, now: time.Now}
+}
+
+func (r *RecordingChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	ctx = WithServedBackend(ctx)
+	start := r.now()
+	resp, err := r.inner.GenerateResponse(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	r.save(ctx, prompt, config, []FixtureChunk{{DelayMs: r.now().Sub(start).Milliseconds(), Response: resp}})
+	return resp, nil
+}
+
+func (r *RecordingChatClient) GenerateContent(ctx context.Context, prompt, apiKey string, config *genai.GenerateContentConfig) (string, error) {
+	ctx = WithServedBackend(ctx)
+	start := r.now()
+	text, err := r.inner.GenerateContent(ctx, prompt, apiKey, config)
+	if err != nil {
+		return "", err
+	}
+	resp := &genai.GenerateContentResponse{
+		ModelVersion: ModelName(ctx, r.inner),
+		Candidates: []*genai.Candidate{{
+			Content:      genai.NewContentFromText(text, genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}
+	r.save(ctx, prompt, config, []FixtureChunk{{DelayMs: r.now().Sub(start).Milliseconds(), Response: resp}})
+	return text, nil
+}
+
+func (r *RecordingChatClient) GenerateContentStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	ctx = WithServedBackend(ctx)
+	start := r.now()
+	seq, err := r.inner.GenerateContentStream(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	return r.record(ctx, prompt, config, start, seq), nil
+}
+
+func (r *RecordingChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, cacheKey string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	ctx = WithServedBackend(ctx)
+	start := r.now()
+	seq, err := r.inner.GenerateContentStreamWithCache(ctx, prompt, config, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	return r.record(ctx, prompt, config, start, seq), nil
+}
+
+// record passes chunks through unchanged and saves them once the stream ends cleanly.
+// Streams that error or are abandoned by the caller are not recorded.
+func (r *RecordingChatClient) record(ctx context.Context, prompt string, config *genai.GenerateContentConfig, start time.Time, seq iter.Seq2[*genai.GenerateContentResponse, error]) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		var chunks []FixtureChunk
+		last := start
+		for resp, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			chunks = append(chunks, FixtureChunk{DelayMs: r.now().Sub(last).Milliseconds(), Response: resp})
+			if !yield(resp, nil) {
+				return
+			}
+			// Time spent by the consumer isn't part of the model's latency.
+			last = r.now()
+		}
+		r.save(ctx, prompt, config, chunks)
+	}
+}
+
+func (r *RecordingChatClient) save(ctx context.Context, prompt string, config *genai.GenerateContentConfig, chunks []FixtureChunk) {
+	fixture := &Fixture{
+		Key:        FixtureKey(prompt, config),
+		Provider:   ProviderName(ctx, r.inner),
+		Model:      ModelName(ctx, r.inner),
+		Prompt:     prompt,
+		RecordedAt: r.now().UTC(),
+		Chunks:     chunks,
+	}
+	if config != nil {
+		fixture.SystemInstruction = contentText(config.SystemInstruction)
+	}
+	if err := r.store.Save(fixture); err != nil {
+		r.logger.WarnContext(ctx, "Failed to record LLM fixture", slog.String("key", fixture.Key), slog.Any("error", err))
+		return
+	}
+	r.logger.DebugContext(ctx, "Recorded LLM fixture", slog.String("key", fixture.Key), slog.Int("chunks", len(chunks)))
+}
+
+func (r *RecordingChatClient) Model() string {
+	return r.inner.Model()
+}
+
+func (r *RecordingChatClient) Provider() string {
+	return ProviderName(context.Background(), r.inner)
+}
+
+// ReplayChatClient serves recorded fixtures without touching the network. speed scales
+// the recorded chunk delays: 1 replays in real time, 0 replays as fast as possible.
+type ReplayChatClient struct {
+	store *FixtureStore
+	speed float64
+}
+
+func NewReplayChatClient(store *FixtureStore, speed float64) *ReplayChatClient {
+	return &ReplayChatClient{store: store, speed: max(speed, 0)}
+}
+
+func (r *ReplayChatClient) load(prompt string, config *genai.GenerateContentConfig) (*Fixture, error) {
+	fixture, err := r.store.Load(FixtureKey(prompt, config))
+	if err != nil {
+		if errors.Is(err, ErrFixtureNotFound) {
+			return nil, fmt.Errorf("%w (prompt %q); record it with LLM_MODE=record", err, truncate(prompt, 80))
+		}
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// wait sleeps for a scaled chunk delay, returning early if ctx is cancelled.
+func (r *ReplayChatClient) wait(ctx context.Context, delayMs int64) error {
+	d := time.Duration(float64(delayMs) * r.speed * float64(time.Millisecond))
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// GenerateResponse replays a fixture as one response, joining streamed chunks and
+// keeping the final chunk's finish reason and usage.
+func (r *ReplayChatClient) GenerateResponse(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	fixture, err := r.load(prompt, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		total int64
+		text  strings.Builder
+		calls []*genai.Part
+		out   = &genai.GenerateContentResponse{ModelVersion: fixture.Model}
+	)
+	candidate := &genai.Candidate{}
+	for _, chunk := range fixture.Chunks {
+		total += chunk.DelayMs
+		if chunk.Response == nil {
+			continue
+		}
+		text.WriteString(chunk.Response.Text())
+		for _, fc := range chunk.Response.FunctionCalls() {
+			calls = append(calls, &genai.Part{FunctionCall: fc})
+		}
+		if chunk.Response.UsageMetadata != nil {
+			out.UsageMetadata = chunk.Response.UsageMetadata
+		}
+		for _, c := range chunk.Response.Candidates {
+			if c != nil && c.FinishReason != "" {
+				candidate.FinishReason = c.FinishReason
+			}
+		}
+	}
+	if err := r.wait(ctx, total); err != nil {
+		return nil, err
+	}
+	candidate.Content = genai.NewContentFromText(text.String(), genai.RoleModel)
+	// Function calls are kept so recorded agent runs replay their tool calls.
+	candidate.Content.Parts = append(candidate.Content.Parts, calls...)
+	out.Candidates = []*genai.Candidate{candidate}
+	return out, nil
+}
+
+func (r *ReplayChatClient) GenerateContent(ctx context.Context, prompt, _ string, config *genai.GenerateContentConfig) (string, error) {
+	resp, err := r.GenerateResponse(ctx, prompt, config)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+func (r *ReplayChatClient) GenerateContentStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	fixture, err := r.load(prompt, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, chunk := range fixture.Chunks {
+			if err := r.wait(ctx, chunk.DelayMs); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(chunk.Response, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (r *ReplayChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, _ string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return r.GenerateContentStream(ctx, prompt, config)
+}
+
+func (r *ReplayChatClient) Model() string {
+	return ProviderReplay
+}
+
+func (r *ReplayChatClient) Provider() string {
+	return ProviderReplay
+}
+
+// HashEmbeddingClient returns deterministic unit vectors derived from the input text.
+// Identical inputs embed identically, which is all offline runs need; the vectors carry
+// no semantic meaning.
+type HashEmbeddingClient struct {
+	dimensions int
+}
+
+func NewHashEmbeddingClient(dimensions int) *HashEmbeddingClient {
+	if dimensions <= 0 {
+		dimensions = 768
+	}
+	return &HashEmbeddingClient{dimensions: dimensions}
+}
+
+func (h *HashEmbeddingClient) GenerateQueryEmbedding(_ context.Context, query string) ([]float32, error) {
+	return h.embed(query), nil
+}
+
+func (h *HashEmbeddingClient) GeneratePOIEmbedding(_ context.Context, name, description, category string) ([]float32, error) {
+	return h.embed(poiEmbeddingText(name, description, category)), nil
+}
+
+func (h *HashEmbeddingClient) Provider() string {
+	return ProviderReplay
+}
+
+func (h *HashEmbeddingClient) embed(text string) []float32 {
+	vec := make([]float32, h.dimensions)
+	seed := sha256.Sum256([]byte(text))
+	block := seed
+	var norm float64
+	for i := range vec {
+		if i > 0 && i%8 == 0 {
+			block = sha256.Sum256(block[:])
+		}
+		v := float64(binary.BigEndian.Uint32(block[(i%8)*4:]))/math.MaxUint32*2 - 1
+		vec[i] = float32(v)
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+	return vec
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
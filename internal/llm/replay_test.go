@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/config"
+)
+
+// scriptedChatClient streams a fixed list of text chunks.
+type scriptedChatClient struct {
+	chunks []string
+	calls  int
+}
+
+func (s *scriptedChatClient) GenerateResponse(context.Context, string, *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	s.calls++
+	return textResponse(s.chunks[0]), nil
+}
+
+func (s *scriptedChatClient) GenerateContent(ctx context.Context, prompt, _ string, config *genai.GenerateContentConfig) (string, error) {
+	resp, err := s.GenerateResponse(ctx, prompt, config)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+func (s *scriptedChatClient) GenerateContentStream(context.Context, string, *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	s.calls++
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, c := range s.chunks {
+			if !yield(textResponse(c), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (s *scriptedChatClient) GenerateContentStreamWithCache(ctx context.Context, prompt string, config *genai.GenerateContentConfig, _ string) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	return s.GenerateContentStream(ctx, prompt, config)
+}
+
+func (s *scriptedChatClient) Model() string { return "scripted-model" }
+
+func textResponse(text string) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(text, genai.RoleModel)}},
+	}
+}
+
+func collectText(t *testing.T, seq iter.Seq2[*genai.GenerateContentResponse, error]) []string {
+	t.Helper()
+	var out []string
+	for resp, err := range seq {
+		require.NoError(t, err)
+		out = append(out, resp.Text())
+	}
+	return out
+}
+
+// steppedClock advances by step on every call so recorded delays are deterministic.
+func steppedClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestRecordThenReplayStream(t *testing.T) {
+	store := NewFixtureStore(t.TempDir())
+	cfg := &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("be brief", genai.RoleUser)}
+	live := &scriptedChatClient{chunks: []string{`{"city":`, `"Lisbon"}`}}
+
+	recorder := NewRecordingChatClient(live, store, slog.New(slog.DiscardHandler))
+	recorder.now = steppedClock(10 * time.Millisecond)
+
+	seq, err := recorder.GenerateContentStreamWithCache(context.Background(), "plan a trip", cfg, "cache-key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"city":`, `"Lisbon"}`}, collectText(t, seq))
+
+	fixture, err := store.Load(FixtureKey("plan a trip", cfg))
+	require.NoError(t, err)
+	assert.Equal(t, "scripted-model", fixture.Model)
+	assert.Equal(t, ProviderGoogle, fixture.Provider)
+	assert.Equal(t, "be brief", fixture.SystemInstruction)
+	require.Len(t, fixture.Chunks, 2)
+	assert.Equal(t, int64(10), fixture.Chunks[0].DelayMs)
+
+	replay := NewReplayChatClient(store, 0)
+	seq, err = replay.GenerateContentStream(context.Background(), "plan a trip", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"city":`, `"Lisbon"}`}, collectText(t, seq))
+
+	text, err := replay.GenerateContent(context.Background(), "plan a trip", "", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `{"city":"Lisbon"}`, text)
+	assert.Equal(t, 1, live.calls, "replay must not call the live client")
+}
+
+func TestReplayHonoursChunkTiming(t *testing.T) {
+	store := NewFixtureStore(t.TempDir())
+	require.NoError(t, store.Save(&Fixture{
+		Key: FixtureKey("hi", nil),
+		Chunks: []FixtureChunk{
+			{DelayMs: 30, Response: textResponse("a")},
+			{DelayMs: 30, Response: textResponse("b")},
+		},
+	}))
+
+	start := time.Now()
+	seq, err := NewReplayChatClient(store, 1).GenerateContentStream(context.Background(), "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, collectText(t, seq))
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	seq, err = NewReplayChatClient(store, 1).GenerateContentStream(ctx, "hi", nil)
+	require.NoError(t, err)
+	for _, err := range seq {
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	_, err := NewReplayChatClient(NewFixtureStore(t.TempDir()), 0).GenerateResponse(context.Background(), "unknown", nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrFixtureNotFound))
+}
+
+func TestRecordingSkipsAbandonedStreams(t *testing.T) {
+	dir := t.TempDir()
+	live := &scriptedChatClient{chunks: []string{"a", "b", "c"}}
+	recorder := NewRecordingChatClient(live, NewFixtureStore(dir), slog.New(slog.DiscardHandler))
+
+	seq, err := recorder.GenerateContentStream(context.Background(), "partial", nil)
+	require.NoError(t, err)
+	for range seq {
+		break
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	_, err = os.Stat(filepath.Join(dir, FixtureKey("partial", nil)+".json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestHashEmbeddingClientIsDeterministic(t *testing.T) {
+	client := NewHashEmbeddingClient(768)
+
+	a, err := client.GenerateQueryEmbedding(context.Background(), "museums in Porto")
+	require.NoError(t, err)
+	b, err := client.GenerateQueryEmbedding(context.Background(), "museums in Porto")
+	require.NoError(t, err)
+	c, err := client.GenerateQueryEmbedding(context.Background(), "beaches in Porto")
+	require.NoError(t, err)
+
+	require.Len(t, a, 768)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+
+	var norm float64
+	for _, v := range a {
+		norm += float64(v) * float64(v)
+	}
+	assert.InDelta(t, 1, math.Sqrt(norm), 1e-4)
+}
+
+func TestNewChatClientFromConfigReplayMode(t *testing.T) {
+	cfg := config.LLMConfig{Mode: "replay", FixturesDir: t.TempDir()}
+
+	chat, err := NewChatClientFromConfig(context.Background(), cfg, slog.New(slog.DiscardHandler), nil)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderReplay, ProviderName(context.Background(), chat))
+
+	embed, err := NewEmbeddingClientFromConfig(context.Background(), cfg, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Equal(t, ProviderReplay, ProviderName(context.Background(), embed))
+
+	_, err = NewChatClientFromConfig(context.Background(), config.LLMConfig{Mode: "bogus"}, slog.New(slog.DiscardHandler), nil)
+	require.Error(t, err)
+}
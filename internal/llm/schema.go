@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+)
+
+// SchemaFor returns the response schema of T, derived from its JSON encoding. Fields
+// are described with an optional llm struct tag. llm:"-" leaves a field out, for
+// values the server fills in such as IDs and timestamps; otherwise the tag holds
+// comma-separated options:
+//
+//	required     the model must always produce the field
+//	nullable     the field may be null
+//	enum=a|b     the value must be one of the listed strings
+//	min=N,max=N  bounds for numbers, or for the item count of arrays
+//	keys=a|b     the properties of a map field; the model may use any of them
+//	desc=...     a description for the model; it must be the last option and may
+//	             contain commas
+//
+// Pointers, slices and maps are nullable, like their JSON encoding. Schemas are cached
+// per type and shared, so callers must not modify them.
+func SchemaFor[T any]() *genai.Schema {
+	return SchemaOf(reflect.TypeFor[T]())
+}
+
+var schemaCache sync.Map // reflect.Type -> *genai.Schema
+
+// SchemaOf is SchemaFor for a reflect.Type.
+func SchemaOf(t reflect.Type) *genai.Schema {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*genai.Schema)
+	}
+	s := schemaOf(t, nil)
+	schemaCache.Store(t, s)
+	return s
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	uuidType            = reflect.TypeFor[uuid.UUID]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// fieldOptions are the parsed llm tag of a struct field.
+type fieldOptions struct {
+	skip     bool
+	required bool
+	nullable bool
+	enum     []string
+	min, max *float64
+	keys     []string
+	desc     string
+}
+
+func parseFieldOptions(tag string) (fieldOptions, error) {
+	var opts fieldOptions
+	if tag == "-" {
+		opts.skip = true
+		return opts, nil
+	}
+	for tag != "" {
+		var opt string
+		if strings.HasPrefix(tag, "desc=") {
+			opt, tag = tag, ""
+		} else {
+			opt, tag, _ = strings.Cut(tag, ",")
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch name {
+		case "required":
+			opts.required = true
+		case "nullable":
+			opts.nullable = true
+		case "enum":
+			opts.enum = strings.Split(value, "|")
+		case "keys":
+			opts.keys = strings.Split(value, "|")
+		case "min", "max":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q", name, value)
+			}
+			if name == "min" {
+				opts.min = &n
+			} else {
+				opts.max = &n
+			}
+		case "desc":
+			opts.desc = value
+		case "":
+		default:
+			return opts, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return opts, nil
+}
+
+// schemaOf builds the schema of t. opts are the options of the field holding t, if any.
+func schemaOf(t reflect.Type, opts *fieldOptions) *genai.Schema {
+	if opts == nil {
+		opts = &fieldOptions{}
+	}
+	nullable := opts.nullable
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		nullable = true
+	}
+
+	s := &genai.Schema{Description: opts.desc}
+	if nullable {
+		s.Nullable = genai.Ptr(true)
+	}
+
+	switch {
+	case t == timeType:
+		s.Type, s.Format = genai.TypeString, "date-time"
+		return s
+	case t == uuidType:
+		s.Type, s.Format = genai.TypeString, "uuid"
+		return s
+	case reflect.PointerTo(t).Implements(textUnmarshalerType) && t.Kind() != reflect.Struct:
+		s.Type = genai.TypeString
+		s.Enum = opts.enum
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = genai.TypeString
+		s.Enum = opts.enum
+	case reflect.Bool:
+		s.Type = genai.TypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = genai.TypeInteger
+		s.Minimum, s.Maximum = opts.min, opts.max
+	case reflect.Float32, reflect.Float64:
+		s.Type = genai.TypeNumber
+		s.Minimum, s.Maximum = opts.min, opts.max
+	case reflect.Slice, reflect.Array:
+		s.Type = genai.TypeArray
+		s.Items = schemaOf(t.Elem(), &fieldOptions{enum: opts.enum})
+		if opts.min != nil {
+			s.MinItems = genai.Ptr(int64(*opts.min))
+		}
+		if opts.max != nil {
+			s.MaxItems = genai.Ptr(int64(*opts.max))
+		}
+	case reflect.Map:
+		// Gemini needs the properties of every object, so maps list the keys the model
+		// may use.
+		s.Type = genai.TypeObject
+		s.Properties = make(map[string]*genai.Schema, len(opts.keys))
+		for _, key := range opts.keys {
+			s.Properties[key] = schemaOf(t.Elem(), nil)
+		}
+		s.PropertyOrdering = opts.keys
+	case reflect.Struct:
+		s.Type = genai.TypeObject
+		s.Properties = map[string]*genai.Schema{}
+		addStructFields(s, t)
+	case reflect.Interface:
+		// Any JSON value; nothing to constrain.
+	default:
+		panic(fmt.Sprintf("llm: cannot build a schema for %s", t))
+	}
+	return s
+}
+
+func addStructFields(s *genai.Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, jsonOpts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && jsonOpts == "" {
+			continue
+		}
+		opts, err := parseFieldOptions(f.Tag.Get("llm"))
+		if err != nil {
+			panic(fmt.Sprintf("llm: %s.%s: %v", t, f.Name, err))
+		}
+		if opts.skip {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaOf(f.Type, &opts)
+		s.PropertyOrdering = append(s.PropertyOrdering, name)
+		if opts.required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
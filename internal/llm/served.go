@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// servedBackend records the backend that most recently served a call made with a
+// context. A fallback chain can answer consecutive calls from different backends, so
+// the client cannot report this itself without mixing up concurrent callers.
+type servedBackend struct {
+	parent *servedBackend
+
+	mu       sync.Mutex
+	provider string
+	model    string
+}
+
+type servedKey struct{}
+
+// WithServedBackend returns a context whose calls record the backend that served
+// them, for ProviderName and ModelName. Install it once per unit of work, e.g. per
+// worker goroutine, so concurrent calls do not overwrite each other's record. Records
+// already on ctx are updated too.
+func WithServedBackend(ctx context.Context) context.Context {
+	return context.WithValue(ctx, servedKey{}, &servedBackend{parent: servedFrom(ctx)})
+}
+
+func servedFrom(ctx context.Context) *servedBackend {
+	s, _ := ctx.Value(servedKey{}).(*servedBackend)
+	return s
+}
+
+// markServed records provider and model as the backend that served a call made with ctx.
+func markServed(ctx context.Context, provider, model string) {
+	for s := servedFrom(ctx); s != nil; s = s.parent {
+		s.mu.Lock()
+		s.provider, s.model = provider, model
+		s.mu.Unlock()
+	}
+}
+
+func (s *servedBackend) get() (provider, model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.provider, s.model
+}
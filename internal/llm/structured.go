@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+)
+
+// Outcomes of structured responses, as counted by observability.LLMStructuredOutput.
+const (
+	OutcomeValid           = "valid"
+	OutcomeInvalidJSON     = "invalid_json"
+	OutcomeSchemaViolation = "schema_violation"
+	OutcomeRepaired        = "repaired"
+	OutcomeFailed          = "failed"
+)
+
+const (
+	defaultStructuredAttempts = 3
+	// maxRepairEcho caps how much of a rejected reply is quoted back to the model.
+	maxRepairEcho = 8000
+)
+
+// StructuredOptions tunes GenerateStructured and RepairStructured.
+type StructuredOptions struct {
+	// Name labels the response type in metrics and logs, e.g. "city_data".
+	Name string
+	// MaxAttempts bounds the calls made, the first included (default 3).
+	MaxAttempts int
+	Logger      *slog.Logger
+}
+
+func (o StructuredOptions) attempts() int {
+	if o.MaxAttempts > 0 {
+		return o.MaxAttempts
+	}
+	return defaultStructuredAttempts
+}
+
+func (o StructuredOptions) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.Default()
+}
+
+// Structured is a response that matched the schema of T.
+type Structured[T any] struct {
+	Value T
+	// JSON is the validated document, without any markdown around it.
+	JSON string
+	// Attempts counts the calls it took, the first included.
+	Attempts int
+}
+
+// WithResponseSchema returns a copy of config that asks for JSON matching schema.
+func WithResponseSchema(config *genai.GenerateContentConfig, schema *genai.Schema) *genai.GenerateContentConfig {
+	out := &genai.GenerateContentConfig{}
+	if config != nil {
+		*out = *config
+	}
+	out.ResponseMIMEType = "application/json"
+	out.ResponseSchema = schema
+	return out
+}
+
+// GenerateStructured asks client for a T, sending the schema of T as the response
+// schema. A reply that is not valid JSON or breaks the schema is counted and retried
+// with a repair prompt listing what was wrong, up to opts.MaxAttempts calls. The error
+// wraps ErrSchemaViolation when every attempt was rejected.
+func GenerateStructured[T any](ctx context.Context, client ChatClient, prompt string, config *genai.GenerateContentConfig, opts StructuredOptions) (Structured[T], error) {
+	return generateStructured[T](ctx, client, prompt, config, opts, "", nil)
+}
+
+// RepairStructured continues from a reply to prompt that DecodeStructured rejected
+// with cause, e.g. the text of a stream. It counts the rejection and asks client to
+// fix the reply, calling it at most opts.MaxAttempts-1 times.
+func RepairStructured[T any](ctx context.Context, client ChatClient, prompt string, config *genai.GenerateContentConfig, text string, cause error, opts StructuredOptions) (Structured[T], error) {
+	countRejection(opts.Name, cause)
+	return generateStructured[T](ctx, client, prompt, config, opts, text, cause)
+}
+
+func generateStructured[T any](ctx context.Context, client ChatClient, prompt string, config *genai.GenerateContentConfig, opts StructuredOptions, lastText string, lastErr error) (Structured[T], error) {
+	config = WithResponseSchema(config, SchemaFor[T]())
+	attempt := 0
+	if lastErr != nil {
+		attempt = 1
+	}
+
+	for attempt < opts.attempts() {
+		attempt++
+		request := prompt
+		if lastErr != nil {
+			request = repairPrompt(prompt, lastText, lastErr)
+		}
+
+		resp, err := client.GenerateResponse(ctx, request, config)
+		if err != nil {
+			return Structured[T]{}, err
+		}
+		lastText = resp.Text()
+
+		value, clean, err := DecodeStructured[T](lastText)
+		if err == nil {
+			outcome := OutcomeValid
+			if attempt > 1 {
+				outcome = OutcomeRepaired
+			}
+			observability.LLMStructuredOutput.WithLabelValues(opts.Name, outcome).Inc()
+			return Structured[T]{Value: value, JSON: clean, Attempts: attempt}, nil
+		}
+		lastErr = err
+		countRejection(opts.Name, err)
+		opts.logger().WarnContext(ctx, "LLM response rejected",
+			slog.String("response_type", opts.Name),
+			slog.Int("attempt", attempt),
+			slog.Any("error", err))
+	}
+
+	observability.LLMStructuredOutput.WithLabelValues(opts.Name, OutcomeFailed).Inc()
+	if !errors.Is(lastErr, ErrSchemaViolation) {
+		lastErr = fmt.Errorf("%w: %w", ErrSchemaViolation, lastErr)
+	}
+	return Structured[T]{}, fmt.Errorf("%s response rejected after %d attempts: %w", opts.Name, attempt, lastErr)
+}
+
+// DecodeStructured extracts the JSON document from text, validates it against the
+// schema of T and decodes it. It also returns the extracted document.
+func DecodeStructured[T any](text string) (T, string, error) {
+	var value T
+	clean := ExtractJSON(text)
+	if err := Validate(SchemaFor[T](), []byte(clean)); err != nil {
+		return value, clean, err
+	}
+	if err := json.Unmarshal([]byte(clean), &value); err != nil {
+		return value, clean, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return value, clean, nil
+}
+
+// ExtractJSON returns the JSON document in text, dropping a markdown code fence and
+// prose around the outermost object or array. It does not fix the document itself.
+func ExtractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if rest, ok := strings.CutPrefix(text, "```"); ok {
+		// Drop the info string, e.g. "json", and the closing fence.
+		if _, body, found := strings.Cut(rest, "\n"); found {
+			rest = body
+		}
+		if i := strings.LastIndex(rest, "```"); i >= 0 {
+			rest = rest[:i]
+		}
+		text = strings.TrimSpace(rest)
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return text
+	}
+	closing := byte('}')
+	if text[start] == '[' {
+		closing = ']'
+	}
+	end := strings.LastIndexByte(text, closing)
+	if end < start {
+		return text[start:]
+	}
+	return text[start : end+1]
+}
+
+func countRejection(name string, err error) {
+	outcome := OutcomeInvalidJSON
+	if errors.Is(err, ErrSchemaViolation) {
+		outcome = OutcomeSchemaViolation
+	}
+	observability.LLMStructuredOutput.WithLabelValues(name, outcome).Inc()
+}
+
+func repairPrompt(prompt, reply string, cause error) string {
+	var problems []string
+	var schemaErr *SchemaError
+	if errors.As(cause, &schemaErr) {
+		problems = schemaErr.Violations
+	} else {
+		problems = []string{cause.Error()}
+	}
+	if len(reply) > maxRepairEcho {
+		reply = reply[:maxRepairEcho] + "\n[truncated]"
+	}
+
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nYour previous reply could not be used:\n")
+	for _, p := range problems {
+		b.WriteString("- ")
+		b.WriteString(p)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nPrevious reply:\n")
+	b.WriteString(reply)
+	b.WriteString("\n\nReply again with only the corrected JSON document. Keep the content, fix the problems listed above and follow the response schema exactly.")
+	return b.String()
+}
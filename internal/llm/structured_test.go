@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	locitypes "github.com/FACorreiaa/loci-connect-api/internal/types"
+)
+
+// scriptedClient answers GenerateResponse with replies in order and records what it
+// was asked.
+type scriptedClient struct {
+	*fakeBackend
+	replies []string
+	prompts []string
+	configs []*genai.GenerateContentConfig
+}
+
+func (s *scriptedClient) GenerateResponse(_ context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	s.prompts = append(s.prompts, prompt)
+	s.configs = append(s.configs, config)
+	reply := s.replies[0]
+	if len(s.replies) > 1 {
+		s.replies = s.replies[1:]
+	}
+	return textResponse(reply), nil
+}
+
+func newScriptedClient(replies ...string) *scriptedClient {
+	return &scriptedClient{fakeBackend: &fakeBackend{model: "test"}, replies: replies}
+}
+
+type schemaPlace struct {
+	ID      string            `json:"id" llm:"-"`
+	Name    string            `json:"name" llm:"required,desc=The name, as locals write it"`
+	Kind    string            `json:"kind" llm:"enum=museum|park"`
+	Rating  float64           `json:"rating" llm:"min=0,max=5"`
+	Tags    []string          `json:"tags" llm:"max=3"`
+	Note    *string           `json:"note"`
+	Hours   map[string]string `json:"hours" llm:"keys=monday|tuesday"`
+	Visited bool              `json:"visited"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	s := SchemaFor[schemaPlace]()
+
+	assert.Equal(t, genai.TypeObject, s.Type)
+	assert.Equal(t, []string{"name"}, s.Required)
+	assert.NotContains(t, s.Properties, "id")
+	assert.Equal(t, []string{"name", "kind", "rating", "tags", "note", "hours", "visited"}, s.PropertyOrdering)
+
+	assert.Equal(t, "The name, as locals write it", s.Properties["name"].Description)
+	assert.Equal(t, []string{"museum", "park"}, s.Properties["kind"].Enum)
+	assert.Equal(t, 5.0, *s.Properties["rating"].Maximum)
+	assert.Equal(t, int64(3), *s.Properties["tags"].MaxItems)
+	assert.Equal(t, genai.TypeString, s.Properties["tags"].Items.Type)
+	assert.True(t, *s.Properties["note"].Nullable)
+	assert.Contains(t, s.Properties["hours"].Properties, "tuesday")
+	assert.Equal(t, genai.TypeBoolean, s.Properties["visited"].Type)
+
+	assert.Same(t, s, SchemaFor[schemaPlace](), "schemas are cached")
+}
+
+func TestSchemaFor_ResponseTypes(t *testing.T) {
+	poi := SchemaFor[locitypes.POIDetailedInfo]()
+	assert.Subset(t, poi.Required, []string{"name", "latitude", "longitude", "category"})
+	assert.NotContains(t, poi.Properties, "llm_interaction_id")
+
+	// Every reply type must build without panicking.
+	for _, build := range []func() *genai.Schema{
+		SchemaFor[locitypes.GeneralCityData],
+		SchemaFor[locitypes.AIItineraryResponse],
+		SchemaFor[locitypes.HotelListResponse],
+		SchemaFor[locitypes.RestaurantListResponse],
+		SchemaFor[locitypes.ActivityListResponse],
+	} {
+		assert.Equal(t, genai.TypeObject, build().Type)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := SchemaFor[schemaPlace]()
+
+	require.NoError(t, Validate(s, []byte(`{"name":"Prado","kind":"museum","rating":4.5,"tags":null,"note":null,"extra":1}`)))
+
+	err := Validate(s, []byte(`{"kind":"zoo","rating":7,"tags":["a","b","c",4],"visited":"yes"}`))
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+	assert.ElementsMatch(t, []string{
+		`$.name: is required`,
+		`$.kind: "zoo" is not one of museum, park`,
+		`$.rating: 7 is above the maximum 5`,
+		`$.tags: expected at most 3 items, got 4`,
+		`$.tags[3]: expected a string, got a number`,
+		`$.visited: expected a boolean, got a string`,
+	}, schemaErr.Violations)
+
+	err = Validate(s, []byte(`{"name":"Prado"} trailing`))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		"```json\n{\"a\":1}\n```":               `{"a":1}`,
+		"Here you go: {\"a\":{\"b\":2}} enjoy!": `{"a":{"b":2}}`,
+		"```\n[1,2]\n```":                       `[1,2]`,
+		"no json here":                          "no json here",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, ExtractJSON(in), in)
+	}
+}
+
+func TestGenerateStructured_RepairsViolations(t *testing.T) {
+	client := newScriptedClient(
+		"```json\n{\"name\":\"Prado\",\"rating\":\"high\"}\n```",
+		`{"name":"Prado","rating":4.7}`,
+	)
+
+	result, err := GenerateStructured[schemaPlace](context.Background(), client, "describe the Prado", nil, StructuredOptions{Name: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, "Prado", result.Value.Name)
+	assert.Equal(t, 4.7, result.Value.Rating)
+	assert.Equal(t, `{"name":"Prado","rating":4.7}`, result.JSON)
+
+	require.Len(t, client.prompts, 2)
+	assert.Equal(t, "describe the Prado", client.prompts[0])
+	assert.Contains(t, client.prompts[1], "$.rating: expected a number, got a string")
+	assert.Contains(t, client.prompts[1], `"rating":"high"`)
+	for _, config := range client.configs {
+		assert.Equal(t, "application/json", config.ResponseMIMEType)
+		assert.Same(t, SchemaFor[schemaPlace](), config.ResponseSchema)
+	}
+}
+
+func TestGenerateStructured_GivesUp(t *testing.T) {
+	client := newScriptedClient("not json at all")
+
+	_, err := GenerateStructured[schemaPlace](context.Background(), client, "p", nil, StructuredOptions{Name: "test", MaxAttempts: 2})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+	assert.Len(t, client.prompts, 2)
+}
+
+func TestRepairStructured(t *testing.T) {
+	client := newScriptedClient(`{"name":"Retiro","kind":"park"}`)
+	_, _, cause := DecodeStructured[schemaPlace](`{"kind":"park"}`)
+	require.Error(t, cause)
+
+	result, err := RepairStructured[schemaPlace](context.Background(), client, "p", nil, `{"kind":"park"}`, cause, StructuredOptions{Name: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Attempts, "the streamed reply counts as the first attempt")
+	assert.Equal(t, "Retiro", result.Value.Name)
+	require.Len(t, client.prompts, 1)
+	assert.Contains(t, client.prompts[0], "$.name: is required")
+
+	errClient := &scriptedClient{fakeBackend: &fakeBackend{}}
+	_, err = RepairStructured[schemaPlace](context.Background(), errClient, "p", nil, "", cause, StructuredOptions{MaxAttempts: 1})
+	assert.True(t, errors.Is(err, ErrSchemaViolation))
+	assert.Empty(t, errClient.prompts)
+}
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// ErrSchemaViolation is wrapped by the errors of responses that do not match their
+// schema.
+var ErrSchemaViolation = errors.New("llm response does not match its schema")
+
+// maxReportedViolations caps the violations a SchemaError lists, keeping repair
+// prompts short.
+const maxReportedViolations = 20
+
+// SchemaError lists where a response breaks its schema, one JSON path per violation.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSchemaViolation, strings.Join(e.Violations, "; "))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// Validate checks the JSON document data against schema. Properties the schema does
+// not know are allowed. It returns a *SchemaError on violations and a plain error when
+// data is not JSON.
+func Validate(schema *genai.Schema, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("invalid JSON: trailing data after the document")
+	}
+
+	v := &validator{}
+	v.check("$", schema, value)
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &SchemaError{Violations: v.violations}
+}
+
+type validator struct {
+	violations []string
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	if len(v.violations) == maxReportedViolations {
+		v.violations = append(v.violations, "further violations omitted")
+	}
+	if len(v.violations) > maxReportedViolations {
+		return
+	}
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) check(path string, s *genai.Schema, value any) {
+	if s == nil {
+		return
+	}
+	if value == nil {
+		if s.Nullable == nil || !*s.Nullable {
+			v.fail(path, "must not be null")
+		}
+		return
+	}
+
+	switch s.Type {
+	case genai.TypeString:
+		str, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected a string, got %s", jsonKind(value))
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			v.fail(path, "%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+	case genai.TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "expected a boolean, got %s", jsonKind(value))
+		}
+	case genai.TypeInteger, genai.TypeNumber:
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "expected a number, got %s", jsonKind(value))
+			return
+		}
+		if s.Type == genai.TypeInteger {
+			if _, err := n.Int64(); err != nil {
+				v.fail(path, "expected an integer, got %s", n)
+				return
+			}
+		}
+		f, err := n.Float64()
+		if err != nil {
+			v.fail(path, "invalid number %s", n)
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			v.fail(path, "%s is below the minimum %g", n, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			v.fail(path, "%s is above the maximum %g", n, *s.Maximum)
+		}
+	case genai.TypeArray:
+		items, ok := value.([]any)
+		if !ok {
+			v.fail(path, "expected an array, got %s", jsonKind(value))
+			return
+		}
+		if s.MinItems != nil && int64(len(items)) < *s.MinItems {
+			v.fail(path, "expected at least %d items, got %d", *s.MinItems, len(items))
+		}
+		if s.MaxItems != nil && int64(len(items)) > *s.MaxItems {
+			v.fail(path, "expected at most %d items, got %d", *s.MaxItems, len(items))
+		}
+		for i, item := range items {
+			v.check(fmt.Sprintf("%s[%d]", path, i), s.Items, item)
+		}
+	case genai.TypeObject:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.fail(path, "expected an object, got %s", jsonKind(value))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				v.fail(path+"."+name, "is required")
+			}
+		}
+		for _, name := range sortedKeys(obj) {
+			if prop, ok := s.Properties[name]; ok {
+				v.check(path+"."+name, prop, obj[name])
+			}
+		}
+	}
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	default:
+		return "null"
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,13 @@
+// Package testutil holds helpers shared by the handler and service tests.
+package testutil
+
+import (
+	"io"
+	"log/slog"
+)
+
+// NewLogger returns a logger that discards everything, for code under test that
+// requires one.
+func NewLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
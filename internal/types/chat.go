@@ -27,6 +27,7 @@ type LlmInteraction struct {
 	ResponseText       string          `json:"response"`
 	ResponsePayload    json.RawMessage `json:"response_payload"`
 	ModelUsed          string          `json:"model_name"`
+	Provider           string          `json:"provider"` // LLM backend that served the request, see llm.Provider*
 	PromptTokens       int             `json:"prompt_tokens"`
 	CompletionTokens   int             `json:"completion_tokens"`
 	TotalTokens        int             `json:"total_tokens"`
@@ -42,20 +43,40 @@ type LlmInteraction struct {
 }
 
 type AIItineraryResponse struct {
-	ItineraryName      string            `json:"itinerary_name"`
-	OverallDescription string            `json:"overall_description"`
-	PointsOfInterest   []POIDetailedInfo `json:"points_of_interest"`
+	ItineraryName      string            `json:"itinerary_name" llm:"required"`
+	OverallDescription string            `json:"overall_description" llm:"required,desc=100-150 words"`
+	PointsOfInterest   []POIDetailedInfo `json:"points_of_interest" llm:"required"`
 	Restaurants        []POIDetailedInfo `json:"restaurants,omitempty"`
 	Bars               []POIDetailedInfo `json:"bars,omitempty"`
 }
 
+// POIListResponse is the LLM reply listing points of interest.
+type POIListResponse struct {
+	PointsOfInterest []POIDetailedInfo `json:"points_of_interest" llm:"required"`
+}
+
+// ActivityListResponse is the LLM reply listing activities.
+type ActivityListResponse struct {
+	Activities []POIDetailedInfo `json:"activities" llm:"required"`
+}
+
+// HotelListResponse is the LLM reply listing accommodation.
+type HotelListResponse struct {
+	Hotels []HotelDetailedInfo `json:"hotels" llm:"required"`
+}
+
+// RestaurantListResponse is the LLM reply listing dining options.
+type RestaurantListResponse struct {
+	Restaurants []RestaurantDetailedInfo `json:"restaurants" llm:"required"`
+}
+
 type GeneralCityData struct {
-	City            string  `json:"city"`
-	Country         string  `json:"country"`
+	City            string  `json:"city" llm:"required"`
+	Country         string  `json:"country" llm:"required"`
 	StateProvince   string  `json:"state_province,omitempty"`
-	Description     string  `json:"description"`
-	CenterLatitude  float64 `json:"center_latitude,omitempty"`
-	CenterLongitude float64 `json:"center_longitude,omitempty"`
+	Description     string  `json:"description" llm:"required,desc=100-150 words"`
+	CenterLatitude  float64 `json:"center_latitude,omitempty" llm:"required,min=-90,max=90"`
+	CenterLongitude float64 `json:"center_longitude,omitempty" llm:"required,min=-180,max=180"`
 	Population      string  `json:"population"`
 	Area            string  `json:"area"`
 	Timezone        string  `json:"timezone"`
@@ -175,6 +196,15 @@ type POIFilter struct {
 	Location GeoPoint `json:"location"` // e.g., "restaurant", "hotel", "bar"
 	Radius   float64  `json:"radius"`   // Radius in kilometers for filtering POIs
 	Category string   `json:"category"` // e.g., "restaurant", "hotel", "bar"
+
+	// The fields below are applied by SearchPOIs only.
+	MinRating     float64  `json:"min_rating"`      // Lowest average rating; 0 leaves ratings unbounded
+	MinPriceLevel int      `json:"min_price_level"` // Lowest price level, from ParsePriceLevel; 0 leaves prices unbounded
+	Tags          []string `json:"tags"`            // Matches POIs carrying any of the tags, case-insensitively
+	SortBy        string   `json:"sort_by"`         // "rating", "distance" or "name"; distance by default
+	SortAscending bool     `json:"sort_ascending"`
+	Limit         int      `json:"limit"` // 0 returns every match
+	Offset        int      `json:"offset"`
 }
 
 type GeoPoint struct {
@@ -211,22 +241,22 @@ type HotelUserPreferences struct {
 }
 
 type HotelDetailedInfo struct {
-	ID               uuid.UUID `json:"id"`
-	City             string    `json:"city"`
-	Name             string    `json:"name"`
-	Latitude         float64   `json:"latitude"`
-	Longitude        float64   `json:"longitude"`
-	Category         string    `json:"category"` // e.g., "Hotel", "Hostel"
+	ID               uuid.UUID `json:"id" llm:"-"`
+	City             string    `json:"city" llm:"required"`
+	Name             string    `json:"name" llm:"required"`
+	Latitude         float64   `json:"latitude" llm:"required,min=-90,max=90"`
+	Longitude        float64   `json:"longitude" llm:"required,min=-180,max=180"`
+	Category         string    `json:"category" llm:"required"` // e.g., "Hotel", "Hostel"
 	Description      string    `json:"description"`
 	Address          string    `json:"address"`
 	PhoneNumber      *string   `json:"phone_number"`
 	Website          *string   `json:"website"`
 	OpeningHours     *string   `json:"opening_hours"`
 	PriceRange       *string   `json:"price_range"`
-	Rating           float64   `json:"rating"`
+	Rating           float64   `json:"rating" llm:"min=0,max=5"`
 	Tags             []string  `json:"tags"`
 	Images           []string  `json:"images"`
-	LlmInteractionID uuid.UUID `json:"llm_interaction_id"`
+	LlmInteractionID uuid.UUID `json:"llm_interaction_id" llm:"-"`
 	Err              error     `json:"-"` // Not serialized
 }
 
@@ -247,23 +277,23 @@ type RestaurantUserPreferences struct {
 }
 
 type RestaurantDetailedInfo struct {
-	ID               uuid.UUID `json:"id"`
-	City             string    `json:"city"`
-	Name             string    `json:"name"`
-	Latitude         float64   `json:"latitude"`
-	Longitude        float64   `json:"longitude"`
-	Category         string    `json:"category"`
+	ID               uuid.UUID `json:"id" llm:"-"`
+	City             string    `json:"city" llm:"required"`
+	Name             string    `json:"name" llm:"required"`
+	Latitude         float64   `json:"latitude" llm:"required,min=-90,max=90"`
+	Longitude        float64   `json:"longitude" llm:"required,min=-180,max=180"`
+	Category         string    `json:"category" llm:"required"`
 	Description      string    `json:"description"`
 	Address          *string   `json:"address"`
 	Website          *string   `json:"website"`
 	PhoneNumber      *string   `json:"phone_number"`
 	OpeningHours     *string   `json:"opening_hours"`
-	PriceLevel       *string   `json:"price_level"`  // Changed to *string
-	CuisineType      *string   `json:"cuisine_type"` // Changed to *string
+	PriceLevel       *string   `json:"price_level" llm:"enum=$|$$|$$$|$$$$"` // Changed to *string
+	CuisineType      *string   `json:"cuisine_type"`                         // Changed to *string
 	Tags             []string  `json:"tags"`
 	Images           []string  `json:"images"`
-	Rating           float64   `json:"rating"`
-	LlmInteractionID uuid.UUID `json:"llm_interaction_id"`
+	Rating           float64   `json:"rating" llm:"min=0,max=5"`
+	LlmInteractionID uuid.UUID `json:"llm_interaction_id" llm:"-"`
 	Err              error     `json:"-"`
 }
 
@@ -83,7 +83,10 @@ type SessionContext struct {
 	ActiveInterests     []string                       `json:"active_interests"`
 	ActiveTags          []string                       `json:"active_tags"`
 	ConversationSummary string                         `json:"conversation_summary"`
-	ModificationHistory []ModificationRecord           `json:"modification_history"`
+	// SummarizedMessages counts the leading ConversationHistory messages already folded
+	// into ConversationSummary.
+	SummarizedMessages  int                  `json:"summarized_messages,omitempty"`
+	ModificationHistory []ModificationRecord `json:"modification_history"`
 }
 
 type ModificationRecord struct {
@@ -229,7 +232,10 @@ const (
 	EventTypeUnifiedChat     = "unified_chat"
 	EventTypeHotels          = "hotels"
 	EventTypeRestaurants     = "restaurants"
-	EventTypeChunk           = "chunk" // For immediate text chunks (Google GenAI pattern)
+	EventTypeChunk           = "chunk"         // For immediate text chunks (Google GenAI pattern)
+	EventTypeToolCall        = "tool_call"     // The chat agent started a tool call
+	EventTypeToolResult      = "tool_result"   // A tool call of the chat agent finished
+	EventTypeClarification   = "clarification" // The assistant asks what an unclear message meant
 )
 
 // StreamingResponse wraps the streaming channel and metadata
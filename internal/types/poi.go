@@ -1,7 +1,6 @@
 package locitypes
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +13,24 @@ type POIFilters struct {
 	PriceRange string `json:"price_range,omitempty"`
 }
 
+// ParsePriceLevel maps a price range or level such as "€€", "$$", "budget" or "2" to a
+// level from 1 (free) to 5 (luxury).
+func ParsePriceLevel(price string) (int, bool) {
+	switch price {
+	case "€", "$", "free", "Free", "1":
+		return 1, true
+	case "€€", "$$", "budget", "Budget", "2":
+		return 2, true
+	case "€€€", "$$$", "moderate", "Moderate", "3":
+		return 3, true
+	case "€€€€", "$$$$", "expensive", "Expensive", "4":
+		return 4, true
+	case "luxury", "Luxury", "premium", "Premium", "5":
+		return 5, true
+	}
+	return 0, false
+}
+
 // type POIDetail struct {
 // 	ID               uuid.UUID `json:"id"`
 // 	LlmInteractionID uuid.UUID `json:"llm_interaction_id,omitempty"` // ID of the LLM interaction that generated this POI
@@ -51,84 +68,37 @@ type POIFilters struct {
 // 	Err         error  `json:"-"`
 // }
 
+// POIDetailedInfo is a point of interest. The llm tags shape the schema LLM replies
+// must follow; fields tagged llm:"-" are filled in by the server.
 type POIDetailedInfo struct {
-	ID               uuid.UUID         `json:"id,omitempty"`
+	ID               uuid.UUID         `json:"id,omitempty" llm:"-"`
 	City             string            `json:"city"`
-	CityID           uuid.UUID         `json:"city_id"`
-	Name             string            `json:"name"`
-	DescriptionPOI   string            `json:"description_poi,omitempty"`
-	Distance         float64           `json:"distance"`
-	Latitude         float64           `json:"latitude,omitempty"`
-	Longitude        float64           `json:"longitude,omitempty"`
-	Category         string            `json:"category"`
+	CityID           uuid.UUID         `json:"city_id" llm:"-"`
+	Name             string            `json:"name" llm:"required"`
+	DescriptionPOI   string            `json:"description_poi,omitempty" llm:"desc=Why the place is worth a visit, 50-100 words"`
+	Distance         float64           `json:"distance" llm:"min=0,desc=Distance from the user in kilometres, 0 when unknown"`
+	Latitude         float64           `json:"latitude,omitempty" llm:"required,min=-90,max=90"`
+	Longitude        float64           `json:"longitude,omitempty" llm:"required,min=-180,max=180"`
+	Category         string            `json:"category" llm:"required"`
 	Description      string            `json:"description"`
-	Rating           float64           `json:"rating"`
+	Rating           float64           `json:"rating" llm:"min=0,max=5"`
 	Address          string            `json:"address"`
 	PhoneNumber      string            `json:"phone_number"`
 	Website          string            `json:"website"`
-	OpeningHours     map[string]string `json:"opening_hours"`
+	OpeningHours     map[string]string `json:"opening_hours" llm:"keys=monday|tuesday|wednesday|thursday|friday|saturday|sunday,desc=Opening hours per weekday, e.g. 09:00-17:00 or closed"`
 	Images           []string          `json:"images,omitempty"`
 	PriceRange       string            `json:"price_range"`
 	PriceLevel       string            `json:"price_level"`
 	Reviews          []string          `json:"reviews"`
-	LlmInteractionID uuid.UUID         `json:"llm_interaction_id"`
+	LlmInteractionID uuid.UUID         `json:"llm_interaction_id" llm:"-"`
 	Tags             []string          `json:"tags,omitempty"`
-	Priority         int               `json:"priority,omitempty"` // Popularity score 1-10
-	CreatedAt        time.Time         `json:"created_at"`
+	Priority         int               `json:"priority,omitempty" llm:"min=1,max=10,desc=Popularity score"` // Popularity score 1-10
+	CreatedAt        time.Time         `json:"created_at" llm:"-"`
 	CuisineType      string            `json:"cuisine_type,omitempty"` // For restaurants
 	StarRating       string            `json:"star_rating,omitempty"`  // For hotels
 	Amenities        string            `json:"amenities"`
 	Err              error             `json:"-"`
-	Source           string            `json:"source,omitempty"` // Source of the POI data (e.g., "google", "yelp", etc.)
-}
-
-// UnmarshalJSON implements custom JSON unmarshaling for POIDetailedInfo
-// to handle opening_hours field that can be either string or map[string]string
-func (p *POIDetailedInfo) UnmarshalJSON(data []byte) error {
-	// Define a temporary struct with the same fields as POIDetailedInfo
-	// but with OpeningHours as json.RawMessage to handle both string and map
-	type Alias POIDetailedInfo
-	aux := &struct {
-		OpeningHours json.RawMessage `json:"opening_hours"`
-		StarRating   json.RawMessage `json:"star_rating"`
-		*Alias
-	}{
-		Alias: (*Alias)(p),
-	}
-
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-
-	// Handle OpeningHours field
-	if len(aux.OpeningHours) > 0 {
-		// Try to unmarshal as map[string]string first
-		var hoursMap map[string]string
-		if err := json.Unmarshal(aux.OpeningHours, &hoursMap); err == nil {
-			p.OpeningHours = hoursMap
-		} else {
-			// If that fails, try to unmarshal as string
-			var hoursString string
-			if err := json.Unmarshal(aux.OpeningHours, &hoursString); err == nil {
-				p.OpeningHours = map[string]string{"general": hoursString}
-			}
-		}
-	}
-
-	// Handle star_rating that may come as number or string
-	if len(aux.StarRating) > 0 {
-		var asString string
-		if err := json.Unmarshal(aux.StarRating, &asString); err == nil {
-			p.StarRating = asString
-		} else {
-			var asNumber json.Number
-			if err := json.Unmarshal(aux.StarRating, &asNumber); err == nil {
-				p.StarRating = asNumber.String()
-			}
-		}
-	}
-
-	return nil
+	Source           string            `json:"source,omitempty" llm:"-"` // Source of the POI data (e.g., "google", "yelp", etc.)
 }
 
 type AddPoiRequest struct {
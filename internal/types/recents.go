@@ -0,0 +1,112 @@
+//revive:disable-next-line:var-naming
+package locitypes
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// POIInteractionType is the kind of event stored in poi_interactions.
+type POIInteractionType string
+
+const (
+	POIInteractionView           POIInteractionType = "view"
+	POIInteractionClick          POIInteractionType = "click"
+	POIInteractionFavorite       POIInteractionType = "favorite"
+	POIInteractionUnfavorite     POIInteractionType = "unfavorite"
+	POIInteractionBookingAttempt POIInteractionType = "booking_attempt"
+)
+
+// POIInteraction is a single behavioural event on a POI. Coordinates are optional
+// because clients do not always share the user's location.
+type POIInteraction struct {
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	POIID           string             `json:"poi_id"`
+	POIName         string             `json:"poi_name"`
+	POICategory     string             `json:"poi_category"`
+	EntityType      string             `json:"entity_type"`
+	InteractionType POIInteractionType `json:"interaction_type"`
+	CityID          *uuid.UUID         `json:"city_id,omitempty"`
+	CityName        string             `json:"city_name"`
+	UserLatitude    *float64           `json:"user_latitude,omitempty"`
+	UserLongitude   *float64           `json:"user_longitude,omitempty"`
+	POILatitude     *float64           `json:"poi_latitude,omitempty"`
+	POILongitude    *float64           `json:"poi_longitude,omitempty"`
+	DistanceKm      *float64           `json:"distance_km,omitempty"`
+	SessionID       string             `json:"session_id"`
+	SourcePage      string             `json:"source_page"`
+	DeviceType      string             `json:"device_type"`
+	Metadata        map[string]string  `json:"metadata,omitempty"`
+	Timestamp       time.Time          `json:"timestamp"`
+}
+
+// POIInteractionFilter narrows an interaction history query.
+type POIInteractionFilter struct {
+	Types         []POIInteractionType `json:"types,omitempty"`
+	EntityTypes   []string             `json:"entity_types,omitempty"`
+	CityID        *uuid.UUID           `json:"city_id,omitempty"`
+	CityName      string               `json:"city_name,omitempty"`
+	Categories    []string             `json:"categories,omitempty"`
+	StartDate     *time.Time           `json:"start_date,omitempty"`
+	EndDate       *time.Time           `json:"end_date,omitempty"`
+	Search        string               `json:"search,omitempty"`
+	SortBy        string               `json:"sort_by"` // timestamp, poi_name, city_name
+	SortAscending bool                 `json:"sort_ascending"`
+	Limit         int                  `json:"limit"`
+	Offset        int                  `json:"offset"`
+}
+
+type InteractionAnalytics struct {
+	InteractionsToday    int      `json:"interactions_today"`
+	InteractionsThisWeek int      `json:"interactions_this_week"`
+	UniqueCities         int      `json:"unique_cities"`
+	TopCategories        []string `json:"top_categories"`
+	MostActiveHour       int      `json:"most_active_hour"`
+	AveragePerDay        float64  `json:"average_per_day"`
+}
+
+type InteractionTrendPoint struct {
+	Date   time.Time                  `json:"date"`
+	Total  int                        `json:"total"`
+	ByType map[POIInteractionType]int `json:"by_type"`
+}
+
+// FrequentPlace aggregates a user's interactions with one POI. Score decays with age,
+// so places visited often and recently rank first.
+type FrequentPlace struct {
+	POIID            string               `json:"poi_id"`
+	POIName          string               `json:"poi_name"`
+	EntityType       string               `json:"entity_type"`
+	Category         string               `json:"category"`
+	CityName         string               `json:"city_name"`
+	Latitude         *float64             `json:"latitude,omitempty"`
+	Longitude        *float64             `json:"longitude,omitempty"`
+	VisitDays        int                  `json:"visit_days"`
+	InteractionCount int                  `json:"interaction_count"`
+	FirstVisit       time.Time            `json:"first_visit"`
+	LastVisit        time.Time            `json:"last_visit"`
+	Score            float64              `json:"score"`
+	Types            []POIInteractionType `json:"types"`
+}
+
+// CityPOIActivity summarises a user's activity in one city across searches,
+// POI interactions and saved itineraries.
+type CityPOIActivity struct {
+	CityID             *uuid.UUID `json:"city_id,omitempty"`
+	CityName           string     `json:"city_name"`
+	Country            string     `json:"country"`
+	Searches           int        `json:"searches"`
+	Views              int        `json:"views"`
+	Favorites          int        `json:"favorites"`
+	ItinerariesCreated int        `json:"itineraries_created"`
+	TotalInteractions  int        `json:"total_interactions"`
+	DistinctPOIs       int        `json:"distinct_pois"`
+	ActiveDays         int        `json:"active_days"`
+	FirstInteraction   *time.Time `json:"first_interaction,omitempty"`
+	LastInteraction    *time.Time `json:"last_interaction,omitempty"`
+	TopCategories      []string   `json:"top_categories"`
+	MostActiveHour     int        `json:"most_active_hour"`
+	AverageDistanceKm  float64    `json:"average_distance_km"`
+}
@@ -0,0 +1,203 @@
+//revive:disable-next-line:var-naming
+package locitypes
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewStatus is the moderation state of a review. Only published reviews are
+// listed publicly and count towards the POI rating.
+type ReviewStatus string
+
+const (
+	ReviewStatusPending   ReviewStatus = "pending"
+	ReviewStatusPublished ReviewStatus = "published"
+	ReviewStatusHidden    ReviewStatus = "hidden"
+	ReviewStatusFlagged   ReviewStatus = "flagged"
+)
+
+// ReviewAspects holds optional 1-5 sub-ratings; zero means not rated.
+type ReviewAspects struct {
+	Service     float64 `json:"service,omitempty"`
+	Quality     float64 `json:"quality,omitempty"`
+	Value       float64 `json:"value,omitempty"`
+	Atmosphere  float64 `json:"atmosphere,omitempty"`
+	Cleanliness float64 `json:"cleanliness,omitempty"`
+	Location    float64 `json:"location,omitempty"`
+	Food        float64 `json:"food,omitempty"`
+	Room        float64 `json:"room,omitempty"`
+	Amenities   float64 `json:"amenities,omitempty"`
+	Staff       float64 `json:"staff,omitempty"`
+}
+
+type Review struct {
+	ID          uuid.UUID     `json:"id"`
+	UserID      uuid.UUID     `json:"user_id"`
+	POIID       uuid.UUID     `json:"poi_id"`
+	Rating      int           `json:"rating"`
+	Title       string        `json:"title"`
+	Content     string        `json:"content"`
+	VisitDate   *time.Time    `json:"visit_date,omitempty"`
+	ImageURLs   []string      `json:"image_urls,omitempty"`
+	Helpful     int           `json:"helpful"`
+	Unhelpful   int           `json:"unhelpful"`
+	ReportCount int           `json:"report_count"`
+	IsVerified  bool          `json:"is_verified"`
+	IsPublished bool          `json:"is_published"`
+	Status      ReviewStatus  `json:"status"`
+	Language    string        `json:"language,omitempty"`
+	Aspects     ReviewAspects `json:"aspects"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+
+	// Populated on reads
+	Reviewer      *ReviewerInfo `json:"reviewer,omitempty"`
+	OfficialReply *ReviewReply  `json:"official_reply,omitempty"`
+}
+
+// NewReview creates a published review with a fresh ID.
+func NewReview(userID, poiID uuid.UUID, rating int, title, content string) *Review {
+	now := time.Now()
+	return &Review{
+		ID:          uuid.New(),
+		UserID:      userID,
+		POIID:       poiID,
+		Rating:      rating,
+		Title:       title,
+		Content:     content,
+		IsPublished: true,
+		Status:      ReviewStatusPublished,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ReviewInput carries the user-editable fields of a review on create and update.
+type ReviewInput struct {
+	Rating    int
+	Title     string
+	Content   string
+	VisitDate *time.Time
+	ImageURLs []string
+	Language  string
+	Aspects   ReviewAspects
+}
+
+type ReviewerInfo struct {
+	UserID      uuid.UUID `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	ReviewCount int       `json:"review_count"`
+	MemberSince time.Time `json:"member_since"`
+}
+
+type ReviewHelpful struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ReviewID  uuid.UUID `json:"review_id"`
+	IsHelpful bool      `json:"is_helpful"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewReviewHelpful(userID, reviewID uuid.UUID, isHelpful bool) *ReviewHelpful {
+	return &ReviewHelpful{
+		UserID:    userID,
+		ReviewID:  reviewID,
+		IsHelpful: isHelpful,
+		CreatedAt: time.Now(),
+	}
+}
+
+type ReviewReply struct {
+	ID         uuid.UUID `json:"id"`
+	ReviewID   uuid.UUID `json:"review_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Content    string    `json:"content"`
+	IsOfficial bool      `json:"is_official"` // POI owner/staff reply
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewReviewReply(reviewID, userID uuid.UUID, content string, isOfficial bool) *ReviewReply {
+	now := time.Now()
+	return &ReviewReply{
+		ID:         uuid.New(),
+		ReviewID:   reviewID,
+		UserID:     userID,
+		Content:    content,
+		IsOfficial: isOfficial,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+type ReviewReport struct {
+	ID        uuid.UUID `json:"id"`
+	ReviewID  uuid.UUID `json:"review_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewFilter narrows a review listing. Zero values mean "no filter".
+type ReviewFilter struct {
+	Ratings        []int
+	StartDate      *time.Time
+	EndDate        *time.Time
+	Languages      []string
+	VerifiedOnly   bool
+	WithPhotosOnly bool
+	Keywords       []string
+	SortBy         string // "date", "rating" or "helpful"
+	SortAscending  bool
+	Limit          int
+	Offset         int
+}
+
+type ReviewRatingBreakdown struct {
+	OneStar   int `json:"one_star"`
+	TwoStar   int `json:"two_star"`
+	ThreeStar int `json:"three_star"`
+	FourStar  int `json:"four_star"`
+	FiveStar  int `json:"five_star"`
+}
+
+type MonthlyReviewData struct {
+	Year          int     `json:"year"`
+	Month         int     `json:"month"`
+	ReviewCount   int     `json:"review_count"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+type ReviewTrends struct {
+	ReviewsLast30Days        int                 `json:"reviews_last_30_days"`
+	AverageRatingLast30Days  float64             `json:"average_rating_last_30_days"`
+	AverageRatingPrevious30d float64             `json:"average_rating_previous_30_days"`
+	Monthly                  []MonthlyReviewData `json:"monthly"`
+}
+
+type ReviewLanguageCount struct {
+	Language string `json:"language"`
+	Count    int    `json:"count"`
+}
+
+// ReviewStatistics aggregates the published reviews of a POI.
+type ReviewStatistics struct {
+	POIID          uuid.UUID             `json:"poi_id"`
+	AverageRating  float64               `json:"average_rating"`
+	TotalReviews   int                   `json:"total_reviews"`
+	Breakdown      ReviewRatingBreakdown `json:"breakdown"`
+	AspectAverages ReviewAspects         `json:"aspect_averages"`
+	Trends         *ReviewTrends         `json:"trends,omitempty"`
+	Languages      []ReviewLanguageCount `json:"languages"`
+	LastUpdated    time.Time             `json:"last_updated"`
+}
+
+type UserReviewStatistics struct {
+	TotalReviews         int      `json:"total_reviews"`
+	AverageRatingGiven   float64  `json:"average_rating_given"`
+	HelpfulVotesReceived int      `json:"helpful_votes_received"`
+	TopCategories        []string `json:"top_categories"`
+}
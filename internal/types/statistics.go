@@ -1,6 +1,12 @@
 //revive:disable-next-line:var-naming
 package locitypes
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type MainPageStatistics struct {
 	TotalUsersCount       int64 `json:"total_users_count"`
 	TotalItinerariesSaved int64 `json:"total_itineraries_saved"`
@@ -21,3 +27,148 @@ type LandingPageUserStats struct {
 	CitiesExplored int `json:"cities_explored"`
 	Discoveries    int `json:"discoveries"`
 }
+
+// PlatformActivity holds the platform-wide counters shown next to the main page totals.
+type PlatformActivity struct {
+	TotalCities             int64 `json:"total_cities"`
+	SearchesToday           int64 `json:"searches_today"`
+	ActiveUsersToday        int64 `json:"active_users_today"`
+	SearchesLastHour        int   `json:"searches_last_hour"`
+	NewUsersToday           int   `json:"new_users_today"`
+	ItinerariesCreatedToday int   `json:"itineraries_created_today"`
+	POIsFavoritedToday      int   `json:"pois_favorited_today"`
+}
+
+type PopularDestination struct {
+	CityID           *uuid.UUID `json:"city_id,omitempty"`
+	CityName         string     `json:"city_name"`
+	Country          string     `json:"country"`
+	SearchCount      int        `json:"search_count"`
+	UserCount        int        `json:"user_count"`
+	GrowthPercentage float64    `json:"growth_percentage"`
+}
+
+type CategoryTrend struct {
+	Category         string  `json:"category"`
+	SearchCount      int     `json:"search_count"`
+	GrowthPercentage float64 `json:"growth_percentage"`
+}
+
+// MainPageOverview is the public main page snapshot pushed to streaming subscribers.
+type MainPageOverview struct {
+	Totals              MainPageStatistics   `json:"totals"`
+	Activity            PlatformActivity     `json:"activity"`
+	PopularDestinations []PopularDestination `json:"popular_destinations"`
+	TrendingCategories  []CategoryTrend      `json:"trending_categories"`
+	TrendPeriod         string               `json:"trend_period"`
+	GeneratedAt         time.Time            `json:"generated_at"`
+}
+
+type ActivityDataPoint struct {
+	Bucket           time.Time `json:"bucket"`
+	Searches         int       `json:"searches"`
+	Favorites        int       `json:"favorites"`
+	ItineraryActions int       `json:"itinerary_actions"`
+	ChatMessages     int       `json:"chat_messages"`
+}
+
+type UserActivityAnalytics struct {
+	Points         []ActivityDataPoint `json:"points"`
+	MostActiveHour int                 `json:"most_active_hour"`
+	MostActiveDay  string              `json:"most_active_day"`
+}
+
+type CityInteraction struct {
+	CityID          *uuid.UUID `json:"city_id,omitempty"`
+	CityName        string     `json:"city_name"`
+	SearchCount     int        `json:"search_count"`
+	Favorites       int        `json:"favorites"`
+	Itineraries     int        `json:"itineraries"`
+	LastInteraction time.Time  `json:"last_interaction"`
+}
+
+// UserEngagement summarises how a user searched and interacted with POIs in a period.
+type UserEngagement struct {
+	Searches       int               `json:"searches"`
+	Favorites      int               `json:"favorites"`
+	VisitedCities  int               `json:"visited_cities"`
+	TopCategories  []string          `json:"top_categories"`
+	Cities         []CityInteraction `json:"cities"`
+	HourlyActivity [24]int           `json:"hourly_activity"`
+}
+
+type UserRecentActivity struct {
+	SearchesThisWeek     int      `json:"searches_this_week"`
+	FavoritesThisWeek    int      `json:"favorites_this_week"`
+	ItinerariesThisMonth int      `json:"itineraries_this_month"`
+	RecentCities         []string `json:"recent_cities"`
+}
+
+type UserGrowthMetrics struct {
+	TotalUsers           int64   `json:"total_users"`
+	NewUsersToday        int     `json:"new_users_today"`
+	NewUsersThisWeek     int     `json:"new_users_this_week"`
+	NewUsersThisMonth    int     `json:"new_users_this_month"`
+	GrowthRateWeekly     float64 `json:"growth_rate_weekly"`
+	GrowthRateMonthly    float64 `json:"growth_rate_monthly"`
+	ActiveUsersToday     int     `json:"active_users_today"`
+	ActiveUsersThisWeek  int     `json:"active_users_this_week"`
+	RetentionRateWeekly  float64 `json:"retention_rate_weekly"`
+	RetentionRateMonthly float64 `json:"retention_rate_monthly"`
+}
+
+type UsageMetrics struct {
+	TotalSearches          int64   `json:"total_searches"`
+	SearchesToday          int     `json:"searches_today"`
+	SearchesThisWeek       int     `json:"searches_this_week"`
+	AverageSearchesPerUser float64 `json:"average_searches_per_user"`
+	AverageResponseTimeMs  float64 `json:"average_response_time_ms"`
+}
+
+type ErrorCount struct {
+	ErrorType string `json:"error_type"`
+	Count     int    `json:"count"`
+}
+
+type ErrorMetrics struct {
+	ErrorsToday    int          `json:"errors_today"`
+	ErrorsThisWeek int          `json:"errors_this_week"`
+	ErrorRate      float64      `json:"error_rate"` // percentage of LLM calls in the period
+	Breakdown      []ErrorCount `json:"breakdown"`
+}
+
+type CountryUsage struct {
+	Country     string `json:"country"`
+	UserCount   int    `json:"user_count"`
+	SearchCount int    `json:"search_count"`
+}
+
+type CityUsage struct {
+	CityID      *uuid.UUID `json:"city_id,omitempty"`
+	CityName    string     `json:"city_name"`
+	SearchCount int        `json:"search_count"`
+	POICount    int        `json:"poi_count"`
+}
+
+type FeatureUsageMetrics struct {
+	Searches           int `json:"searches"`
+	FavoritesAdded     int `json:"favorites_added"`
+	ItinerariesCreated int `json:"itineraries_created"`
+	ListsCreated       int `json:"lists_created"`
+	ChatSessions       int `json:"chat_sessions"`
+	// Distinct users per feature in the period, keyed like the counters above.
+	FeatureUsers map[string]int `json:"feature_users"`
+}
+
+// SystemAnalytics is the admin view over users, LLM usage and errors since a point in time.
+type SystemAnalytics struct {
+	Since               time.Time           `json:"since"`
+	UserGrowth          UserGrowthMetrics   `json:"user_growth"`
+	Usage               UsageMetrics        `json:"usage"`
+	Errors              ErrorMetrics        `json:"errors"`
+	Countries           []CountryUsage      `json:"countries"`
+	Cities              []CityUsage         `json:"cities"`
+	Features            FeatureUsageMetrics `json:"features"`
+	ActiveDBConnections int                 `json:"active_db_connections"`
+	GeneratedAt         time.Time           `json:"generated_at"`
+}
@@ -0,0 +1,52 @@
+//revive:disable-next-line:var-naming
+package locitypes
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription plans as stored in subscription_plan_type.
+const (
+	PlanFree           = "free"
+	PlanPremiumMonthly = "premium_monthly"
+	PlanPremiumAnnual  = "premium_annual"
+)
+
+// LLMUsageRecord is one row of the llm_usage ledger.
+type LLMUsageRecord struct {
+	UserID           uuid.UUID `json:"user_id"`
+	Provider         string    `json:"provider"`
+	ModelName        string    `json:"model_name"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+	Estimated        bool      `json:"estimated"`
+}
+
+// LLMUsageTotals aggregates a user's ledger for the current day and month.
+type LLMUsageTotals struct {
+	DailyTokens     int64
+	MonthlyTokens   int64
+	MonthlyCostUSD  float64
+	MonthlyRequests int64
+}
+
+// UsageWindow reports consumption against a plan limit. A Limit of zero means the
+// plan is not capped for that window and Remaining is omitted.
+type UsageWindow struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining *int64    `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// UsageSummary is what a user sees about their own LLM consumption.
+type UsageSummary struct {
+	Plan            string      `json:"plan"`
+	Daily           UsageWindow `json:"daily"`
+	Monthly         UsageWindow `json:"monthly"`
+	MonthlyCostUSD  float64     `json:"monthly_cost_usd"`
+	MonthlyRequests int64       `json:"monthly_requests"`
+}
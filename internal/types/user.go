@@ -50,7 +50,6 @@ type UserProfile struct {
 type UpdateProfileParams struct {
 	Username        *string   `json:"username,omitempty"`
 	PhoneNumber     *string   `json:"phone,omitempty"`
-	Email           *string   `json:"email,omitempty"`
 	DisplayName     *string   `json:"display_name,omitempty"`
 	ProfileImageURL *string   `json:"profile_image_url,omitempty"`
 	Firstname       *string   `json:"firstname,omitempty"`
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	// Load environment variables from .env files when present.
 	_ "github.com/joho/godotenv"
@@ -16,13 +17,65 @@ type Config struct {
 	Auth          AuthConfig
 	Observability ObservabilityConfig
 	Profiling     ProfilingConfig
+	LLM           LLMConfig
+	Usage         UsageConfig
+	RateLimit     RateLimitConfig
+	Email         EmailConfig
+	Gazetteer     GazetteerConfig
 }
 
 type ServerConfig struct {
-	Host               string
-	Port               int
-	RateLimitPerSecond int
-	RateLimitBurst     int
+	Host string
+	Port int
+}
+
+// RateLimitConfig sets per-caller token buckets. Store is "memory" (per replica),
+// "postgres" (shared across replicas) or "none". A tier with a zero rate is not limited.
+type RateLimitConfig struct {
+	Store string
+	// TrustedProxies are the CIDRs of the reverse proxies whose X-Forwarded-For
+	// headers name the client. Without any, the connecting address is the client.
+	TrustedProxies []string
+	Anonymous      RateLimitTier
+	Free           RateLimitTier
+	Premium        RateLimitTier
+}
+
+type RateLimitTier struct {
+	PerSecond float64
+	Burst     int
+}
+
+// EmailConfig configures outbound email. Transport is "smtp", "file" (write .eml files
+// into FileDir), "log" (only log what would be sent) or "memory"; it defaults to smtp
+// when SMTP.Host is set and to log otherwise. Store is "postgres" or "memory".
+type EmailConfig struct {
+	Transport   string
+	Store       string
+	FromEmail   string
+	FromName    string
+	FrontendURL string
+	FileDir     string
+	SMTP        SMTPConfig
+	// MaxAttempts is how often delivery is tried before a message is abandoned.
+	MaxAttempts         int
+	PollIntervalSeconds int
+}
+
+// GazetteerConfig sets up the in-process city matcher used before asking the LLM for
+// the city of a chat message. GeoNamesFile is an optional GeoNames dump, e.g.
+// cities15000.txt; places below MinPopulation are not loaded from it.
+type GazetteerConfig struct {
+	Enabled       bool
+	GeoNamesFile  string
+	MinPopulation int
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
 }
 
 type DatabaseConfig struct {
@@ -34,13 +87,27 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// AuthConfig selects the JWT keys. Without JWTSigningKeyFile tokens are signed with
+// JWTSecret (HS256); with it they are signed with the RSA or Ed25519 key in the file.
 type AuthConfig struct {
-	JWTSecret string
+	JWTSecret               string
+	JWTSigningKeyFile       string
+	JWTVerificationKeyFiles []string
+	// JWTAcceptHS256 keeps tokens signed with JWTSecret valid after moving to a
+	// signing key file.
+	JWTAcceptHS256 bool
 }
 
+// ObservabilityConfig controls metrics and tracing. Traces are exported over OTLP/HTTP
+// when OTLPEndpoint is set; TraceSampleRatio applies to traces started here.
 type ObservabilityConfig struct {
-	MetricsEnabled bool
-	MetricsPort    int
+	MetricsEnabled   bool
+	MetricsPort      int
+	ServiceName      string
+	ServiceVersion   string
+	Environment      string
+	OTLPEndpoint     string
+	TraceSampleRatio float64
 }
 
 type ProfilingConfig struct {
@@ -48,14 +115,92 @@ type ProfilingConfig struct {
 	Port    int
 }
 
+// LLMConfig selects and configures the chat and embedding backends.
+// Provider is "google" (Gemini) or "openai" (any OpenAI-compatible server).
+// Mode is "live", "record" (call Provider and save fixtures) or "replay"
+// (serve fixtures from FixturesDir without network access).
+type LLMConfig struct {
+	Provider          string
+	EmbeddingProvider string
+	GeminiAPIKey      string
+	OpenAI            OpenAIConfig
+	TimeoutSeconds    int
+	Mode              string
+	FixturesDir       string
+	ReplaySpeed       float64
+	// Fallbacks lists backends tried after the primary, as "provider:model".
+	Fallbacks []string
+	Breaker   LLMBreakerConfig
+	Agent     LLMAgentConfig
+	Memory    LLMMemoryConfig
+}
+
+// LLMAgentConfig switches the unified chat from the fixed prompt fan-out to the
+// tool-calling agent.
+type LLMAgentConfig struct {
+	Enabled  bool
+	MaxSteps int
+}
+
+// LLMMemoryConfig bounds the earlier conversation given to the model when a chat
+// session continues: RecentMessages verbatim, older messages summarized once they pass
+// TokenBudget, and up to RecallLimit summarized messages recalled by similarity.
+type LLMMemoryConfig struct {
+	RecentMessages int
+	TokenBudget    int
+	RecallLimit    int
+}
+
+type LLMBreakerConfig struct {
+	FailureThreshold  int
+	CooldownSeconds   int
+	MaxLatencyMs      int
+	RetryRatio        float64
+	RetryMinPerSecond float64
+}
+
+// UsageConfig sets the LLM token quotas per subscription plan. Zero disables a cap.
+type UsageConfig struct {
+	QuotasEnabled bool
+	Free          PlanQuotaConfig
+	Premium       PlanQuotaConfig
+}
+
+type PlanQuotaConfig struct {
+	DailyTokens   int64
+	MonthlyTokens int64
+}
+
+type OpenAIConfig struct {
+	BaseURL             string
+	APIKey              string
+	ChatModel           string
+	EmbeddingModel      string
+	EmbeddingDimensions int
+}
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Host:               getEnv("SERVER_HOST", "localhost"),
-			Port:               getEnvAsInt("SERVER_PORT", 8080),
-			RateLimitPerSecond: getEnvAsInt("SERVER_RATE_LIMIT_PER_SECOND", 100),
-			RateLimitBurst:     getEnvAsInt("SERVER_RATE_LIMIT_BURST", 200),
+			Host: getEnv("SERVER_HOST", "localhost"),
+			Port: getEnvAsInt("SERVER_PORT", 8080),
+		},
+		RateLimit: RateLimitConfig{
+			Store:          getEnv("RATE_LIMIT_STORE", "memory"),
+			TrustedProxies: getEnvAsSlice("RATE_LIMIT_TRUSTED_PROXIES", nil),
+			Anonymous: RateLimitTier{
+				PerSecond: getEnvAsFloat("RATE_LIMIT_ANONYMOUS_PER_SECOND", 2),
+				Burst:     getEnvAsInt("RATE_LIMIT_ANONYMOUS_BURST", 20),
+			},
+			Free: RateLimitTier{
+				PerSecond: getEnvAsFloat("RATE_LIMIT_FREE_PER_SECOND", 5),
+				Burst:     getEnvAsInt("RATE_LIMIT_FREE_BURST", 50),
+			},
+			Premium: RateLimitTier{
+				PerSecond: getEnvAsFloat("RATE_LIMIT_PREMIUM_PER_SECOND", 20),
+				Burst:     getEnvAsInt("RATE_LIMIT_PREMIUM_BURST", 200),
+			},
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -66,17 +211,96 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret: getEnv("JWT_SECRET", "changeme"),
+			JWTSecret:               getEnv("JWT_SECRET", "changeme"),
+			JWTSigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+			JWTVerificationKeyFiles: getEnvAsSlice("JWT_VERIFICATION_KEY_FILES", nil),
+			JWTAcceptHS256:          getEnvAsBool("JWT_ACCEPT_HS256", false),
 		},
 		Observability: ObservabilityConfig{
-			MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
-			MetricsPort:    getEnvAsInt("METRICS_PORT", 9090),
+			MetricsEnabled:   getEnvAsBool("METRICS_ENABLED", true),
+			MetricsPort:      getEnvAsInt("METRICS_PORT", 9090),
+			ServiceName:      getEnv("OTEL_SERVICE_NAME", "loci-connect-api"),
+			ServiceVersion:   getEnv("OTEL_SERVICE_VERSION", "dev"),
+			Environment:      getEnv("APP_ENV", "development"),
+			OTLPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			TraceSampleRatio: getEnvAsFloat("OTEL_TRACES_SAMPLER_ARG", 1),
 		},
 		Profiling: ProfilingConfig{
 			Enabled: getEnvAsBool("PPROF_ENABLED", false),
 			Port:    getEnvAsInt("PPROF_PORT", 6060),
 		},
+		LLM: LLMConfig{
+			Provider:       getEnv("LLM_PROVIDER", "google"),
+			GeminiAPIKey:   getEnv("GEMINI_API_KEY", ""),
+			TimeoutSeconds: getEnvAsInt("LLM_TIMEOUT_SECONDS", 60),
+			Mode:           getEnv("LLM_MODE", "live"),
+			FixturesDir:    getEnv("LLM_FIXTURES_DIR", "testdata/llm-fixtures"),
+			ReplaySpeed:    getEnvAsFloat("LLM_REPLAY_SPEED", 1),
+			Fallbacks:      getEnvAsSlice("LLM_FALLBACKS", nil),
+			Breaker: LLMBreakerConfig{
+				FailureThreshold:  getEnvAsInt("LLM_BREAKER_FAILURES", 5),
+				CooldownSeconds:   getEnvAsInt("LLM_BREAKER_COOLDOWN_SECONDS", 30),
+				MaxLatencyMs:      getEnvAsInt("LLM_BREAKER_MAX_LATENCY_MS", 0),
+				RetryRatio:        getEnvAsFloat("LLM_RETRY_BUDGET_RATIO", 0.2),
+				RetryMinPerSecond: getEnvAsFloat("LLM_RETRY_BUDGET_MIN_PER_SECOND", 1),
+			},
+			Agent: LLMAgentConfig{
+				Enabled:  getEnvAsBool("LLM_AGENT_ENABLED", false),
+				MaxSteps: getEnvAsInt("LLM_AGENT_MAX_STEPS", 5),
+			},
+			Memory: LLMMemoryConfig{
+				RecentMessages: getEnvAsInt("LLM_MEMORY_RECENT_MESSAGES", 8),
+				TokenBudget:    getEnvAsInt("LLM_MEMORY_TOKEN_BUDGET", 1500),
+				RecallLimit:    getEnvAsInt("LLM_MEMORY_RECALL_LIMIT", 3),
+			},
+			OpenAI: OpenAIConfig{
+				BaseURL:             getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+				APIKey:              getEnv("OPENAI_API_KEY", ""),
+				ChatModel:           getEnv("OPENAI_CHAT_MODEL", "gpt-4o-mini"),
+				EmbeddingModel:      getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
+				EmbeddingDimensions: getEnvAsInt("OPENAI_EMBEDDING_DIMENSIONS", 768),
+			},
+		},
+		Usage: UsageConfig{
+			QuotasEnabled: getEnvAsBool("USAGE_QUOTAS_ENABLED", true),
+			Free: PlanQuotaConfig{
+				DailyTokens:   int64(getEnvAsInt("USAGE_FREE_DAILY_TOKENS", 50_000)),
+				MonthlyTokens: int64(getEnvAsInt("USAGE_FREE_MONTHLY_TOKENS", 1_000_000)),
+			},
+			Premium: PlanQuotaConfig{
+				DailyTokens:   int64(getEnvAsInt("USAGE_PREMIUM_DAILY_TOKENS", 1_000_000)),
+				MonthlyTokens: int64(getEnvAsInt("USAGE_PREMIUM_MONTHLY_TOKENS", 20_000_000)),
+			},
+		},
 	}
+	cfg.Email = EmailConfig{
+		Store:       getEnv("EMAIL_STORE", "postgres"),
+		FromEmail:   getEnv("FROM_EMAIL", "no-reply@loci.app"),
+		FromName:    getEnv("FROM_NAME", "loci"),
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+		FileDir:     getEnv("EMAIL_FILE_DIR", "tmp/emails"),
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvAsInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+		},
+		MaxAttempts:         getEnvAsInt("EMAIL_MAX_ATTEMPTS", 8),
+		PollIntervalSeconds: getEnvAsInt("EMAIL_POLL_INTERVAL_SECONDS", 5),
+	}
+	cfg.Email.Transport = getEnv("EMAIL_TRANSPORT", "log")
+	if cfg.Email.SMTP.Host != "" {
+		cfg.Email.Transport = getEnv("EMAIL_TRANSPORT", "smtp")
+	}
+
+	cfg.Gazetteer = GazetteerConfig{
+		Enabled:       getEnvAsBool("CITY_GAZETTEER_ENABLED", true),
+		GeoNamesFile:  getEnv("CITY_GAZETTEER_GEONAMES_FILE", ""),
+		MinPopulation: getEnvAsInt("CITY_GAZETTEER_MIN_POPULATION", 15000),
+	}
+
+	// Embeddings follow the chat provider unless set explicitly.
+	cfg.LLM.EmbeddingProvider = getEnv("LLM_EMBEDDING_PROVIDER", cfg.LLM.Provider)
 
 	return cfg, nil
 }
@@ -104,6 +328,29 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice splits a comma-separated variable, dropping empty entries.
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, v := range strings.Split(valueStr, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
 	if value, err := strconv.ParseBool(valueStr); err == nil {
@@ -0,0 +1,72 @@
+-- +goose Up
+-- Review moderation state, reports and per-aspect ratings on top of 0006_create_reviews
+
+-- Keep the most recent review when a user reviewed the same POI more than once,
+-- so the one-review-per-user-per-POI constraint can be added. The older ones, with
+-- their replies and helpful votes, move to archive tables that Down restores from.
+CREATE TABLE reviews_duplicates (LIKE reviews INCLUDING DEFAULTS);
+CREATE TABLE review_replies_duplicates (LIKE review_replies INCLUDING DEFAULTS);
+CREATE TABLE review_helpfuls_duplicates (LIKE review_helpfuls INCLUDING DEFAULTS);
+
+INSERT INTO reviews_duplicates
+SELECT r.*
+FROM reviews r
+WHERE EXISTS (
+    SELECT 1 FROM reviews newer
+    WHERE newer.user_id = r.user_id
+      AND newer.poi_id = r.poi_id
+      AND (r.created_at, r.id) < (newer.created_at, newer.id)
+);
+
+INSERT INTO review_replies_duplicates
+SELECT rr.* FROM review_replies rr WHERE rr.review_id IN (SELECT id FROM reviews_duplicates);
+
+INSERT INTO review_helpfuls_duplicates
+SELECT rh.* FROM review_helpfuls rh WHERE rh.review_id IN (SELECT id FROM reviews_duplicates);
+
+DELETE FROM reviews WHERE id IN (SELECT id FROM reviews_duplicates);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_user_poi_unique ON reviews(user_id, poi_id);
+
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('pending', 'published', 'hidden', 'flagged')),
+    ADD COLUMN IF NOT EXISTS report_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS language VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS aspects JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+UPDATE reviews SET status = 'hidden' WHERE is_published = FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_poi_status_created ON reviews(poi_id, status, created_at DESC);
+
+-- One report per user per review; moderation acts on the distinct reporter count
+CREATE TABLE IF NOT EXISTS review_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL CHECK (reason IN ('spam', 'inappropriate', 'fake', 'offensive', 'other')),
+    details TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_reports_review_id ON review_reports(review_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS review_reports;
+DROP INDEX IF EXISTS idx_reviews_poi_status_created;
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS aspects,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS report_count,
+    DROP COLUMN IF EXISTS status;
+DROP INDEX IF EXISTS idx_reviews_user_poi_unique;
+
+INSERT INTO reviews SELECT * FROM reviews_duplicates;
+INSERT INTO review_replies SELECT * FROM review_replies_duplicates;
+INSERT INTO review_helpfuls SELECT * FROM review_helpfuls_duplicates;
+DROP TABLE IF EXISTS review_helpfuls_duplicates;
+DROP TABLE IF EXISTS review_replies_duplicates;
+DROP TABLE IF EXISTS reviews_duplicates;
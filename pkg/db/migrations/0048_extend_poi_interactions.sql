@@ -0,0 +1,52 @@
+-- +goose Up
+-- RecordInteraction accepts events from clients that may not share their location,
+-- so coordinates and distance become optional and the event carries its city and context.
+ALTER TABLE poi_interactions
+    ALTER COLUMN user_latitude DROP NOT NULL,
+    ALTER COLUMN user_longitude DROP NOT NULL,
+    ALTER COLUMN poi_latitude DROP NOT NULL,
+    ALTER COLUMN poi_longitude DROP NOT NULL,
+    ALTER COLUMN distance DROP NOT NULL,
+    ALTER COLUMN poi_category SET DEFAULT '',
+    ADD COLUMN IF NOT EXISTS entity_type VARCHAR(50) NOT NULL DEFAULT 'poi',
+    ADD COLUMN IF NOT EXISTS city_id UUID REFERENCES cities(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS city_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS session_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS source_page VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS device_type VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE poi_interactions DROP CONSTRAINT IF EXISTS poi_interactions_interaction_type_check;
+ALTER TABLE poi_interactions ADD CONSTRAINT poi_interactions_interaction_type_check
+    CHECK (interaction_type IN ('view', 'click', 'favorite', 'unfavorite', 'booking_attempt'));
+
+CREATE INDEX IF NOT EXISTS idx_poi_interactions_user_city ON poi_interactions(user_id, lower(city_name), timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_poi_interactions_user_poi ON poi_interactions(user_id, poi_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_poi_interactions_user_poi;
+DROP INDEX IF EXISTS idx_poi_interactions_user_city;
+
+DELETE FROM poi_interactions WHERE interaction_type NOT IN ('view', 'click', 'favorite');
+ALTER TABLE poi_interactions DROP CONSTRAINT IF EXISTS poi_interactions_interaction_type_check;
+ALTER TABLE poi_interactions ADD CONSTRAINT poi_interactions_interaction_type_check
+    CHECK (interaction_type IN ('view', 'click', 'favorite'));
+
+DELETE FROM poi_interactions
+WHERE user_latitude IS NULL OR user_longitude IS NULL
+   OR poi_latitude IS NULL OR poi_longitude IS NULL OR distance IS NULL;
+
+ALTER TABLE poi_interactions
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS device_type,
+    DROP COLUMN IF EXISTS source_page,
+    DROP COLUMN IF EXISTS session_id,
+    DROP COLUMN IF EXISTS city_name,
+    DROP COLUMN IF EXISTS city_id,
+    DROP COLUMN IF EXISTS entity_type,
+    ALTER COLUMN poi_category DROP DEFAULT,
+    ALTER COLUMN distance SET NOT NULL,
+    ALTER COLUMN poi_longitude SET NOT NULL,
+    ALTER COLUMN poi_latitude SET NOT NULL,
+    ALTER COLUMN user_longitude SET NOT NULL,
+    ALTER COLUMN user_latitude SET NOT NULL;
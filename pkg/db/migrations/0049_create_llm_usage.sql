@@ -0,0 +1,24 @@
+-- +goose Up
+-- Ledger of tokens consumed per LLM call, used for cost reporting and plan quotas.
+-- Kept apart from llm_interactions because one interaction can span several calls
+-- (fallbacks, enrichment workers) and usage must be summed cheaply per user and window.
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL DEFAULT 'google',
+    model_name TEXT NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0 CHECK (prompt_tokens >= 0),
+    completion_tokens INTEGER NOT NULL DEFAULT 0 CHECK (completion_tokens >= 0),
+    total_tokens INTEGER NOT NULL DEFAULT 0 CHECK (total_tokens >= 0),
+    cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    estimated BOOLEAN NOT NULL DEFAULT FALSE, -- TRUE when the provider reported no token counts
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_user_created ON llm_usage(user_id, created_at DESC);
+
+COMMENT ON TABLE llm_usage IS 'Per-call LLM token usage and estimated cost, summed for plan quotas';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_llm_usage_user_created;
+DROP TABLE IF EXISTS llm_usage;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Token buckets shared by all API replicas for per-user and per-IP rate limiting.
+-- Rows are rewritten on every request, so the table is UNLOGGED: losing it on a crash
+-- only resets limits.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY, -- "user:<uuid>" or "ip:<address>"
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL -- when the bucket is full again and can be swept
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_rate_limit_buckets_expires_at;
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Refresh sessions are grouped into families: every refresh rotates the session into a
+-- new row of the same family and marks the old row rotated. Presenting a rotated token
+-- again means it leaked, and the whole family is revoked.
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS family_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    ADD COLUMN IF NOT EXISTS access_token_id TEXT, -- jti of the access token issued with the session
+    ADD COLUMN IF NOT EXISTS access_expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_family_id ON user_sessions(family_id);
+
+-- Access tokens revoked before their expiry. API replicas mirror the unexpired rows in
+-- memory and reject matching jti claims.
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL, -- when the token expires and the row can be swept
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_revoked_at ON revoked_access_tokens(revoked_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_revoked_access_tokens_expires_at;
+DROP INDEX IF EXISTS idx_revoked_access_tokens_revoked_at;
+DROP TABLE IF EXISTS revoked_access_tokens;
+DROP INDEX IF EXISTS idx_user_sessions_family_id;
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS rotated_at,
+    DROP COLUMN IF EXISTS access_expires_at,
+    DROP COLUMN IF EXISTS access_token_id,
+    DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Email change tokens carry the address the user asked to move to. The address is only
+-- written to users once the token sent there is confirmed.
+ALTER TABLE user_tokens
+    ADD COLUMN IF NOT EXISTS new_email TEXT;
+
+-- +goose Down
+ALTER TABLE user_tokens
+    DROP COLUMN IF EXISTS new_email;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- One-time codes handed to native apps at the end of an OAuth login. The app redeems a
+-- code for loci tokens with the PKCE verifier matching code_challenge, so an app that
+-- intercepts the redirect cannot use it.
+CREATE TABLE IF NOT EXISTS oauth_login_codes (
+    code_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_challenge TEXT NOT NULL, -- base64url SHA-256 of the app's code verifier
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_login_codes_expires_at ON oauth_login_codes(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS oauth_login_codes;
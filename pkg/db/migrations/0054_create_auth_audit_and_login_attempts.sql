@@ -0,0 +1,29 @@
+-- +goose Up
+-- Security-relevant auth events. Rows outlive the account they belong to so the trail
+-- survives a deletion; email keeps failed logins for unknown addresses searchable.
+CREATE TABLE IF NOT EXISTS auth_audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    email TEXT,
+    event_type TEXT NOT NULL, -- login_succeeded, login_failed, login_locked, token_refreshed, logout, password_changed
+    client_ip TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_created_at ON auth_audit_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_user_id ON auth_audit_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_client_ip ON auth_audit_events(client_ip, created_at DESC);
+
+-- Failed login counters per account ("account:<email>") and per client IP ("ip:<addr>").
+-- A key is locked out once its failures reach the policy threshold.
+CREATE TABLE IF NOT EXISTS auth_login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS auth_login_attempts;
+DROP TABLE IF EXISTS auth_audit_events;
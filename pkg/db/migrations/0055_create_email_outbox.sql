@@ -0,0 +1,26 @@
+-- +goose Up
+-- Transactional email waiting to be delivered by the email worker. Rows are claimed
+-- with FOR UPDATE SKIP LOCKED; next_attempt_at doubles as the claim lease and the
+-- retry backoff. Rows keep the template and its data, which holds the tokens of
+-- verification and reset links, and are rendered at send time; data is cleared once a
+-- row is sent or abandoned. Sent rows are purged after a retention period.
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    template TEXT NOT NULL,
+    locale TEXT NOT NULL DEFAULT '',
+    to_address TEXT NOT NULL,
+    to_name TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_pending ON email_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_email_outbox_sent_at ON email_outbox(sent_at) WHERE status = 'sent';
+
+-- +goose Down
+DROP TABLE IF EXISTS email_outbox;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Embeddings of chat messages that have been folded into the session's rolling summary,
+-- so the turns relevant to a new message can be recalled verbatim.
+CREATE TABLE IF NOT EXISTS chat_message_embeddings (
+    message_id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES chat_sessions(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    content TEXT NOT NULL,
+    message_type TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    embedding VECTOR(768) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_message_embeddings_session ON chat_message_embeddings(session_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS chat_message_embeddings;
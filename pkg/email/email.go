@@ -0,0 +1,109 @@
+// Package email delivers transactional email through an outbox. Callers queue a
+// template and its data in the outbox, which is a database table in production, and a
+// Worker renders and sends queued messages through a pluggable Transport, retrying
+// failures with exponential backoff. Slow or failing mail servers therefore never hold
+// up a request.
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Address is an email recipient.
+type Address struct {
+	Email string
+	Name  string
+}
+
+// Message is a rendered email with HTML and plain text alternatives.
+type Message struct {
+	To      Address
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Transport delivers a message.
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// QueuedMessage is an email waiting in the outbox. It holds the template and its data
+// rather than the rendered bodies, and stores clear Data once the message is sent or
+// abandoned, so the tokens in verification and reset links do not outlive delivery.
+// Attempts counts delivery attempts including the current one.
+type QueuedMessage struct {
+	ID        int64
+	Template  string
+	Locale    string
+	To        Address
+	Data      map[string]any
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// Store persists the outbox.
+type Store interface {
+	// Enqueue adds a message that is due immediately.
+	Enqueue(ctx context.Context, msg QueuedMessage) (int64, error)
+	// Claim returns up to limit due messages and hides them from other claims for
+	// lease, so a worker that dies mid-send does not lose them.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]QueuedMessage, error)
+	// MarkSent records delivery and clears the message data.
+	MarkSent(ctx context.Context, id int64) error
+	// MarkFailed records a failed attempt. The message is retried at retryAt, or
+	// abandoned and its data cleared when retryAt is nil.
+	MarkFailed(ctx context.Context, id int64, lastErr string, retryAt *time.Time) error
+	// DeleteSentBefore removes messages delivered before the given time.
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Outbox queues templated email for the Worker.
+type Outbox struct {
+	store    Store
+	renderer *Renderer
+	// wake nudges a worker in this process to send right away instead of at its next
+	// poll.
+	wake chan struct{}
+}
+
+// NewOutbox creates an outbox that renders with renderer and queues in store.
+func NewOutbox(store Store, renderer *Renderer) *Outbox {
+	return &Outbox{
+		store:    store,
+		renderer: renderer,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Send queues template in locale for to. data is available to the template; "Name"
+// defaults to the recipient's name. The template is rendered once here so a bad
+// template fails the caller, and again by the Worker when it sends.
+func (o *Outbox) Send(ctx context.Context, to Address, locale, template string, data map[string]any) error {
+	if to.Email == "" {
+		return errors.New("email recipient is required")
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = to.Name
+	}
+
+	if _, err := o.renderer.Render(template, locale, data); err != nil {
+		return err
+	}
+
+	msg := QueuedMessage{Template: template, Locale: locale, To: to, Data: data}
+	if _, err := o.store.Enqueue(ctx, msg); err != nil {
+		return fmt.Errorf("failed to queue %s email: %w", template, err)
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package email
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	QueuedMessage
+	status      string
+	nextAttempt time.Time
+	lastError   string
+	sentAt      time.Time
+}
+
+const (
+	statusPending = "pending"
+	statusSent    = "sent"
+	statusFailed  = "failed"
+)
+
+// MemoryStore keeps the outbox in process. It suits tests and single-replica
+// development; queued mail is lost on restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []*memoryEntry
+	nextID  int64
+	now     func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now}
+}
+
+func (m *MemoryStore) Enqueue(_ context.Context, msg QueuedMessage) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	now := m.now()
+	msg.ID, msg.Attempts, msg.CreatedAt = m.nextID, 0, now
+	m.entries = append(m.entries, &memoryEntry{
+		QueuedMessage: msg,
+		status:        statusPending,
+		nextAttempt:   now,
+	})
+	return m.nextID, nil
+}
+
+func (m *MemoryStore) Claim(_ context.Context, limit int, lease time.Duration) ([]QueuedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var claimed []QueuedMessage
+	for _, e := range m.entries {
+		if len(claimed) >= limit {
+			break
+		}
+		if e.status != statusPending || e.nextAttempt.After(now) {
+			continue
+		}
+		e.Attempts++
+		e.nextAttempt = now.Add(lease)
+		claimed = append(claimed, e.QueuedMessage)
+	}
+	return claimed, nil
+}
+
+func (m *MemoryStore) MarkSent(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.find(id); e != nil {
+		e.status = statusSent
+		e.sentAt = m.now()
+		e.Data = nil
+	}
+	return nil
+}
+
+func (m *MemoryStore) MarkFailed(_ context.Context, id int64, lastErr string, retryAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.find(id)
+	if e == nil {
+		return nil
+	}
+	e.lastError = lastErr
+	if retryAt == nil {
+		e.status = statusFailed
+		e.Data = nil
+		return nil
+	}
+	e.nextAttempt = *retryAt
+	return nil
+}
+
+func (m *MemoryStore) DeleteSentBefore(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.entries[:0]
+	var deleted int64
+	for _, e := range m.entries {
+		if e.status == statusSent && e.sentAt.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	m.entries = kept
+	return deleted, nil
+}
+
+// Pending returns the number of messages still waiting to be sent.
+func (m *MemoryStore) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, e := range m.entries {
+		if e.status == statusPending {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *MemoryStore) find(id int64) *memoryEntry {
+	for _, e := range m.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
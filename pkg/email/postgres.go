@@ -0,0 +1,106 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps the outbox in the email_outbox table, so queued mail survives
+// restarts and any replica's worker can send it. Claims use FOR UPDATE SKIP LOCKED, so
+// concurrent workers never pick the same message.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) Enqueue(ctx context.Context, msg QueuedMessage) (int64, error) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode outbox email data: %w", err)
+	}
+	var id int64
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO email_outbox (template, locale, to_address, to_name, data)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		msg.Template, msg.Locale, msg.To.Email, msg.To.Name, data,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert outbox email: %w", err)
+	}
+	return id, nil
+}
+
+func (s *PostgresStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]QueuedMessage, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE email_outbox
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, template, locale, to_address, to_name, data, attempts, created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox emails: %w", err)
+	}
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (QueuedMessage, error) {
+		var m QueuedMessage
+		var data []byte
+		if err := row.Scan(&m.ID, &m.Template, &m.Locale, &m.To.Email, &m.To.Name, &data, &m.Attempts, &m.CreatedAt); err != nil {
+			return m, err
+		}
+		return m, json.Unmarshal(data, &m.Data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan outbox emails: %w", err)
+	}
+	return messages, nil
+}
+
+func (s *PostgresStore) MarkSent(ctx context.Context, id int64) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE email_outbox
+		SET status = 'sent', sent_at = now(), last_error = NULL, data = '{}'::jsonb
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox email sent: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) MarkFailed(ctx context.Context, id int64, lastErr string, retryAt *time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE email_outbox
+		SET status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE status END,
+		    next_attempt_at = COALESCE($3, next_attempt_at),
+		    data = CASE WHEN $3::timestamptz IS NULL THEN '{}'::jsonb ELSE data END,
+		    last_error = $2
+		WHERE id = $1`, id, lastErr, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox email failed: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM email_outbox WHERE status = 'sent' AND sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox emails: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,144 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DefaultLocale is used for locales without a catalog and for keys a catalog lacks.
+const DefaultLocale = "en"
+
+//go:embed templates/*.tmpl locales/*.json
+var files embed.FS
+
+// Renderer renders the embedded email templates. Every email NAME has an HTML template
+// templates/NAME.html.tmpl and a text template templates/NAME.txt.tmpl; shared
+// partials live in templates/_*.tmpl. User-facing strings come from the locales/*.json
+// catalogs through the t function, and the subject is the catalog entry NAME.subject.
+type Renderer struct {
+	html     *htmltemplate.Template
+	text     *texttemplate.Template
+	catalogs map[string]map[string]string
+}
+
+// NewRenderer parses the embedded templates and locale catalogs.
+func NewRenderer() (*Renderer, error) {
+	catalogs, err := loadCatalogs()
+	if err != nil {
+		return nil, err
+	}
+
+	// t is bound to the recipient's locale per render; this placeholder lets the
+	// templates parse.
+	placeholder := func(key string, _ ...any) string { return key }
+
+	html, err := htmltemplate.New("email").
+		Funcs(htmltemplate.FuncMap{"t": placeholder}).
+		ParseFS(files, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html email templates: %w", err)
+	}
+	text, err := texttemplate.New("email").
+		Funcs(texttemplate.FuncMap{"t": placeholder}).
+		ParseFS(files, "templates/*.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text email templates: %w", err)
+	}
+
+	return &Renderer{html: html, text: text, catalogs: catalogs}, nil
+}
+
+func loadCatalogs() (map[string]map[string]string, error) {
+	paths, err := fs.Glob(files, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	catalogs := make(map[string]map[string]string, len(paths))
+	for _, p := range paths {
+		raw, err := files.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to parse email catalog %s: %w", p, err)
+		}
+		catalogs[strings.TrimSuffix(path.Base(p), ".json")] = catalog
+	}
+	if _, ok := catalogs[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("email catalog %q is missing", DefaultLocale)
+	}
+	return catalogs, nil
+}
+
+// Render renders the email name for locale. Locales fall back from "pt-BR" to "pt" to
+// DefaultLocale.
+func (r *Renderer) Render(name, locale string, data map[string]any) (Message, error) {
+	translate := r.translator(locale)
+
+	html, err := r.html.Clone()
+	if err != nil {
+		return Message{}, err
+	}
+	var htmlBody bytes.Buffer
+	if err := html.Funcs(htmltemplate.FuncMap{"t": translate}).ExecuteTemplate(&htmlBody, name+".html.tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s html email: %w", name, err)
+	}
+
+	text, err := r.text.Clone()
+	if err != nil {
+		return Message{}, err
+	}
+	var textBody bytes.Buffer
+	if err := text.Funcs(texttemplate.FuncMap{"t": translate}).ExecuteTemplate(&textBody, name+".txt.tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s text email: %w", name, err)
+	}
+
+	return Message{
+		Subject: translate(name + ".subject"),
+		HTML:    htmlBody.String(),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+	}, nil
+}
+
+// translator returns the t function for locale: it looks key up in the most specific
+// catalog that has it and formats args into the result like fmt.Sprintf.
+func (r *Renderer) translator(locale string) func(key string, args ...any) string {
+	var chain []map[string]string
+	for _, candidate := range localeChain(locale) {
+		if catalog, ok := r.catalogs[candidate]; ok {
+			chain = append(chain, catalog)
+		}
+	}
+	return func(key string, args ...any) string {
+		for _, catalog := range chain {
+			if msg, ok := catalog[key]; ok {
+				if len(args) > 0 {
+					return fmt.Sprintf(msg, args...)
+				}
+				return msg
+			}
+		}
+		return key
+	}
+}
+
+// localeChain returns the catalogs to try for locale, most specific first.
+func localeChain(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	var chain []string
+	if locale != "" {
+		chain = append(chain, locale)
+		if lang, _, ok := strings.Cut(locale, "-"); ok {
+			chain = append(chain, lang)
+		}
+	}
+	return append(chain, DefaultLocale)
+}
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var templateData = map[string]map[string]any{
+	"verification":              {"Link": "https://loci.test/verify-email?token=abc"},
+	"password_reset":            {"Link": "https://loci.test/reset-password?token=abc"},
+	"welcome":                   {"AppURL": "https://loci.test/dashboard"},
+	"email_change_confirmation": {"Link": "https://loci.test/confirm-email-change?token=abc", "NewEmail": "new@example.com"},
+	"email_change_notice":       {"NewEmail": "new@example.com"},
+	"new_device_login":          {"UserAgent": "Firefox", "ClientIP": "192.0.2.1", "ResetLink": "https://loci.test/forgot-password"},
+}
+
+func TestRenderer_RendersEveryTemplateInEveryLocale(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	for locale, catalog := range r.catalogs {
+		for name, data := range templateData {
+			data["Name"] = "Ana"
+			msg, err := r.Render(name, locale, data)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", locale, name, err)
+			}
+			if msg.Subject != catalog[name+".subject"] {
+				t.Errorf("%s/%s: unexpected subject %q", locale, name, msg.Subject)
+			}
+			for _, body := range []string{msg.HTML, msg.Text} {
+				if !strings.Contains(body, "Ana") {
+					t.Errorf("%s/%s: body does not greet the recipient", locale, name)
+				}
+				// An untranslated key renders as itself.
+				if strings.Contains(body, name+".") || strings.Contains(body, "common.") {
+					t.Errorf("%s/%s: body has an untranslated key:\n%s", locale, name, body)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderer_CatalogsHaveTheSameKeys(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	want := slices.Sorted(maps.Keys(r.catalogs[DefaultLocale]))
+	for locale, catalog := range r.catalogs {
+		if got := slices.Sorted(maps.Keys(catalog)); !slices.Equal(got, want) {
+			t.Errorf("catalog %s keys differ from %s:\n got %v\nwant %v", locale, DefaultLocale, got, want)
+		}
+	}
+}
+
+func TestRenderer_LocaleFallback(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	data := map[string]any{"Name": "Ana", "Link": "https://loci.test"}
+	for locale, want := range map[string]string{
+		"pt-BR": r.catalogs["pt"]["verification.subject"],
+		"pt_PT": r.catalogs["pt"]["verification.subject"],
+		"de":    r.catalogs["en"]["verification.subject"],
+		"":      r.catalogs["en"]["verification.subject"],
+	} {
+		msg, err := r.Render("verification", locale, data)
+		if err != nil {
+			t.Fatalf("Render(%q): %v", locale, err)
+		}
+		if msg.Subject != want {
+			t.Errorf("Render(%q): subject %q, want %q", locale, msg.Subject, want)
+		}
+	}
+}
+
+func TestRenderer_EscapesHTML(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	msg, err := r.Render("email_change_notice", "en", map[string]any{
+		"Name":     "<b>Ana</b>",
+		"NewEmail": `"><script>alert(1)</script>@example.com`,
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(msg.HTML, "<script>") || strings.Contains(msg.HTML, "<b>Ana") {
+		t.Fatalf("html body is not escaped:\n%s", msg.HTML)
+	}
+	if !strings.Contains(msg.Text, "<b>Ana</b>") {
+		t.Fatalf("text body should be verbatim:\n%s", msg.Text)
+	}
+}
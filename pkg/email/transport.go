@@ -0,0 +1,198 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPConfig configures SMTPTransport.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     Address
+}
+
+// SMTPTransport sends mail through an SMTP relay, authenticating with PLAIN when a
+// username is set.
+type SMTPTransport struct {
+	cfg SMTPConfig
+}
+
+var _ Transport = (*SMTPTransport)(nil)
+
+func NewSMTPTransport(cfg SMTPConfig) *SMTPTransport {
+	return &SMTPTransport{cfg: cfg}
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg Message) error {
+	raw, err := buildMIME(t.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if t.cfg.Username != "" {
+		auth = smtp.PlainAuth("", t.cfg.Username, t.cfg.Password, t.cfg.Host)
+	}
+	addr := net.JoinHostPort(t.cfg.Host, fmt.Sprint(t.cfg.Port))
+
+	// smtp.SendMail takes no context; run it aside so a hung relay cannot outlive ctx.
+	errc := make(chan error, 1)
+	go func() {
+		errc <- smtp.SendMail(addr, auth, t.cfg.From.Email, []string{msg.To.Email}, raw)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FileTransport writes every message as an .eml file into a directory, or only logs it
+// when the directory is empty. It is meant for development.
+type FileTransport struct {
+	dir    string
+	from   Address
+	logger *slog.Logger
+}
+
+var _ Transport = (*FileTransport)(nil)
+
+func NewFileTransport(dir string, from Address, logger *slog.Logger) (*FileTransport, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create email directory: %w", err)
+		}
+	}
+	return &FileTransport{dir: dir, from: from, logger: logger}, nil
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg Message) error {
+	if t.dir == "" {
+		t.logger.InfoContext(ctx, "email not sent, no transport configured",
+			slog.String("to", msg.To.Email), slog.String("subject", msg.Subject))
+		return nil
+	}
+
+	now := time.Now()
+	raw, err := buildMIME(t.from, msg, now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), randomHex(4))
+	file := filepath.Join(t.dir, name)
+	if err := os.WriteFile(file, raw, 0o640); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	t.logger.InfoContext(ctx, "email written", slog.String("to", msg.To.Email), slog.String("file", file))
+	return nil
+}
+
+// MemoryTransport records messages instead of sending them, for tests.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+var _ Transport = (*MemoryTransport)(nil)
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(_ context.Context, msg Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+// buildMIME encodes msg as a multipart/alternative message with quoted-printable text
+// and HTML parts.
+func buildMIME(from Address, msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	var out bytes.Buffer
+	for _, h := range [][2]string{
+		{"From", formatAddress(from)},
+		{"To", formatAddress(msg.To)},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", randomHex(16), messageIDHost(from.Email))},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `multipart/alternative; boundary="` + body.Boundary() + `"`},
+	} {
+		fmt.Fprintf(&out, "%s: %s\r\n", h[0], h[1])
+	}
+	out.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
+
+func formatAddress(a Address) string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+func messageIDHost(from string) string {
+	if _, host, ok := strings.Cut(from, "@"); ok && host != "" {
+		return host
+	}
+	return "localhost"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
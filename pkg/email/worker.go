@@ -0,0 +1,241 @@
+package email
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// WorkerOptions tunes a Worker. Zero fields take the defaults from DefaultWorkerOptions.
+type WorkerOptions struct {
+	// Interval is how often the outbox is polled when nothing wakes the worker.
+	Interval time.Duration
+	// Lease hides a claimed message from other workers while it is being sent.
+	Lease       time.Duration
+	BatchSize   int
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles with every further
+	// attempt up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// SendTimeout bounds a single delivery.
+	SendTimeout time.Duration
+	// Retention is how long sent messages are kept before they are purged.
+	Retention time.Duration
+}
+
+// DefaultWorkerOptions retries a message 8 times over roughly four hours.
+func DefaultWorkerOptions() WorkerOptions {
+	return WorkerOptions{
+		Interval:    5 * time.Second,
+		Lease:       2 * time.Minute,
+		BatchSize:   20,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+		SendTimeout: 30 * time.Second,
+		Retention:   7 * 24 * time.Hour,
+	}
+}
+
+func (o WorkerOptions) withDefaults() WorkerOptions {
+	def := DefaultWorkerOptions()
+	if o.Interval <= 0 {
+		o.Interval = def.Interval
+	}
+	if o.Lease <= 0 {
+		o.Lease = def.Lease
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = def.BatchSize
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = def.MaxAttempts
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = def.BaseBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = def.MaxBackoff
+	}
+	if o.SendTimeout <= 0 {
+		o.SendTimeout = def.SendTimeout
+	}
+	if o.Retention <= 0 {
+		o.Retention = def.Retention
+	}
+	return o
+}
+
+// backoff returns the delay before retrying a message that failed its attempts-th
+// delivery.
+func (o WorkerOptions) backoff(attempts int) time.Duration {
+	exp := attempts - 1
+	if exp > 30 {
+		exp = 30
+	}
+	delay := time.Duration(float64(o.BaseBackoff) * math.Pow(2, float64(exp)))
+	if delay > o.MaxBackoff {
+		delay = o.MaxBackoff
+	}
+	return delay
+}
+
+// purgeEvery is how many polls pass between purges of sent messages.
+const purgeEvery = 720
+
+// Worker delivers the outbox through a Transport. Run one per replica; the Store keeps
+// concurrent workers from sending the same message.
+type Worker struct {
+	store     Store
+	renderer  *Renderer
+	transport Transport
+	logger    *slog.Logger
+	opts      WorkerOptions
+	wake      <-chan struct{}
+	polls     int
+	now       func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWorker creates a worker that sends outbox's messages through transport.
+func NewWorker(outbox *Outbox, transport Transport, logger *slog.Logger, opts WorkerOptions) *Worker {
+	return &Worker{
+		store:     outbox.store,
+		renderer:  outbox.renderer,
+		transport: transport,
+		logger:    logger,
+		opts:      opts.withDefaults(),
+		wake:      outbox.wake,
+		now:       time.Now,
+	}
+}
+
+// Start sends queued mail in the background until Close is called.
+func (w *Worker) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run(w.stop, w.done)
+}
+
+func (w *Worker) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		w.poll(stop)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// poll runs one RunOnce, cancelled early when stop closes.
+func (w *Worker) poll(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+		w.logger.Warn("failed to process email outbox", slog.Any("error", err))
+	}
+
+	w.polls++
+	if w.polls%purgeEvery == 0 {
+		if n, err := w.store.DeleteSentBefore(ctx, w.now().Add(-w.opts.Retention)); err != nil {
+			w.logger.Warn("failed to purge sent emails", slog.Any("error", err))
+		} else if n > 0 {
+			w.logger.Debug("purged sent emails", slog.Int64("deleted", n))
+		}
+	}
+}
+
+// Close stops the worker, interrupting any delivery in progress. Interrupted messages
+// are retried once their lease expires.
+func (w *Worker) Close() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+// RunOnce sends the messages that are due, batch by batch, until none are left. It
+// returns how many were delivered.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		batch, err := w.store.Claim(ctx, w.opts.BatchSize, w.opts.Lease)
+		if err != nil {
+			return sent, err
+		}
+		for _, msg := range batch {
+			if w.deliver(ctx, msg) {
+				sent++
+			}
+		}
+		if len(batch) < w.opts.BatchSize || ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+	}
+}
+
+func (w *Worker) deliver(ctx context.Context, msg QueuedMessage) bool {
+	logger := w.logger.With(
+		slog.Int64("email_id", msg.ID),
+		slog.String("template", msg.Template),
+		slog.Int("attempt", msg.Attempts),
+	)
+
+	rendered, err := w.renderer.Render(msg.Template, msg.Locale, msg.Data)
+	if err != nil {
+		// Retrying cannot fix a template that does not render.
+		logger.Error("failed to render email, giving up", slog.Any("error", err))
+		if err := w.store.MarkFailed(ctx, msg.ID, err.Error(), nil); err != nil {
+			logger.Warn("failed to record email failure", slog.Any("error", err))
+		}
+		return false
+	}
+	rendered.To = msg.To
+
+	sendCtx, cancel := context.WithTimeout(ctx, w.opts.SendTimeout)
+	err = w.transport.Send(sendCtx, rendered)
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down; the lease returns the message to the queue.
+		return false
+	}
+
+	if err == nil {
+		if err := w.store.MarkSent(ctx, msg.ID); err != nil {
+			logger.Warn("failed to mark email sent", slog.Any("error", err))
+		}
+		return true
+	}
+
+	var retryAt *time.Time
+	if msg.Attempts < w.opts.MaxAttempts {
+		at := w.now().Add(w.opts.backoff(msg.Attempts))
+		retryAt = &at
+		logger.Warn("failed to send email, will retry", slog.Time("retry_at", at), slog.Any("error", err))
+	} else {
+		logger.Error("failed to send email, giving up", slog.Any("error", err))
+	}
+	if err := w.store.MarkFailed(ctx, msg.ID, err.Error(), retryAt); err != nil {
+		logger.Warn("failed to record email failure", slog.Any("error", err))
+	}
+	return false
+}
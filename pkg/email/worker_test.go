@@ -0,0 +1,153 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyTransport fails the first failures sends.
+type flakyTransport struct {
+	MemoryTransport
+	mu       sync.Mutex
+	failures int
+}
+
+func (t *flakyTransport) Send(ctx context.Context, msg Message) error {
+	t.mu.Lock()
+	if t.failures > 0 {
+		t.failures--
+		t.mu.Unlock()
+		return errors.New("connection refused")
+	}
+	t.mu.Unlock()
+	return t.MemoryTransport.Send(ctx, msg)
+}
+
+// newTestOutbox returns an outbox on a MemoryStore whose clock is the returned time.
+func newTestOutbox(t *testing.T) (*Outbox, *MemoryStore, *time.Time) {
+	t.Helper()
+	renderer, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return NewOutbox(store, renderer), store, &now
+}
+
+func TestWorker_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	outbox, store, now := newTestOutbox(t)
+	transport := &flakyTransport{failures: 2}
+	worker := NewWorker(outbox, transport, slog.New(slog.NewTextHandler(io.Discard, nil)), WorkerOptions{
+		BaseBackoff: time.Minute,
+		MaxAttempts: 5,
+	})
+	worker.now = store.now
+
+	if err := outbox.Send(ctx, Address{Email: "ana@example.com", Name: "Ana"}, "pt-BR", "welcome", map[string]any{"AppURL": "https://loci.test"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if sent, err := worker.RunOnce(ctx); err != nil || sent != 0 {
+			t.Fatalf("attempt %d: sent %d, err %v", attempt, sent, err)
+		}
+		// Nothing is due until the backoff has passed.
+		if sent, _ := worker.RunOnce(ctx); sent != 0 {
+			t.Fatalf("attempt %d: retried before the backoff", attempt)
+		}
+		entry := store.entries[0]
+		if wait := entry.nextAttempt.Sub(*now); wait < worker.opts.backoff(attempt) {
+			t.Fatalf("attempt %d: retry in %s, want at least %s", attempt, wait, worker.opts.backoff(attempt))
+		}
+		if entry.lastError != "connection refused" {
+			t.Fatalf("attempt %d: last error %q", attempt, entry.lastError)
+		}
+		*now = entry.nextAttempt
+	}
+
+	if sent, err := worker.RunOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("third attempt: sent %d, err %v", sent, err)
+	}
+	messages := transport.Messages()
+	if len(messages) != 1 || messages[0].To.Email != "ana@example.com" || !strings.Contains(messages[0].Text, "Ana") {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+	if store.Pending() != 0 {
+		t.Fatalf("expected the outbox to be empty")
+	}
+	if store.entries[0].Data != nil {
+		t.Fatalf("expected the data of a sent message to be cleared")
+	}
+
+	*now = now.Add(8 * 24 * time.Hour)
+	if n, _ := store.DeleteSentBefore(ctx, now.Add(-7*24*time.Hour)); n != 1 {
+		t.Fatalf("expected the sent message to be purged, deleted %d", n)
+	}
+}
+
+func TestWorker_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	outbox, store, now := newTestOutbox(t)
+	worker := NewWorker(outbox, &flakyTransport{failures: 10}, slog.New(slog.NewTextHandler(io.Discard, nil)), WorkerOptions{
+		MaxAttempts: 3,
+	})
+	worker.now = store.now
+	if err := outbox.Send(ctx, Address{Email: "ana@example.com"}, "en", "verification", map[string]any{"Link": "https://loci.test"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	for range 3 {
+		if _, err := worker.RunOnce(ctx); err != nil {
+			t.Fatalf("RunOnce: %v", err)
+		}
+		*now = now.Add(worker.opts.MaxBackoff)
+	}
+	if store.entries[0].status != statusFailed || store.entries[0].Attempts != 3 {
+		t.Fatalf("expected the message to be abandoned after 3 attempts, got %+v", store.entries[0])
+	}
+	if store.entries[0].Data != nil {
+		t.Fatalf("expected the data of an abandoned message to be cleared")
+	}
+	if sent, _ := worker.RunOnce(ctx); sent != 0 || store.entries[0].Attempts != 3 {
+		t.Fatalf("an abandoned message must not be retried")
+	}
+}
+
+func TestWorker_BackoffIsCapped(t *testing.T) {
+	opts := WorkerOptions{BaseBackoff: time.Second, MaxBackoff: time.Minute}.withDefaults()
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 10: time.Minute, 100: time.Minute} {
+		if got := opts.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestWorker_StartSendsQueuedMail(t *testing.T) {
+	ctx := context.Background()
+	outbox, _, _ := newTestOutbox(t)
+	transport := NewMemoryTransport()
+	worker := NewWorker(outbox, transport, slog.New(slog.NewTextHandler(io.Discard, nil)), WorkerOptions{Interval: time.Hour})
+	worker.Start()
+	defer worker.Close()
+
+	// Send wakes the worker, so delivery does not wait for the hourly poll.
+	if err := outbox.Send(ctx, Address{Email: "ana@example.com"}, "en", "verification", map[string]any{"Link": "https://loci.test"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(transport.Messages()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("the worker did not send the queued message")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
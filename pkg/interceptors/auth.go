@@ -3,11 +3,14 @@ package interceptors
 import (
 	"context"
 	"errors"
+	"net/http"
 	"strings"
 	"time"
 
 	"connectrpc.com/connect"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
 )
 
 // Claims represents the JWT claims for authenticated users
@@ -16,6 +19,8 @@ type Claims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// TokenUse must be jwtkeys.TokenUseAccess; refresh tokens are rejected.
+	TokenUse string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
@@ -27,16 +32,23 @@ const (
 	claimsKey contextKey = "claims"
 )
 
+// RevocationChecker reports access tokens revoked before their expiry, by jti.
+type RevocationChecker interface {
+	IsRevoked(jti string) bool
+}
+
 // AuthInterceptor handles JWT authentication for Connect RPC
 type AuthInterceptor struct {
-	jwtSecret          []byte
+	keys               *jwtkeys.KeySet
+	revocations        RevocationChecker
 	optionalProcedures map[string]struct{}
 }
 
 var _ connect.Interceptor = (*AuthInterceptor)(nil)
 
-// NewAuthInterceptor creates a new JWT authentication interceptor
-func NewAuthInterceptor(jwtSecret []byte, optionalProcedures ...string) *AuthInterceptor {
+// NewAuthInterceptor creates a new JWT authentication interceptor that accepts tokens
+// signed by any key in keys.
+func NewAuthInterceptor(keys *jwtkeys.KeySet, optionalProcedures ...string) *AuthInterceptor {
 	procedureMap := make(map[string]struct{}, len(optionalProcedures))
 	for _, procedure := range optionalProcedures {
 		if procedure != "" {
@@ -45,11 +57,21 @@ func NewAuthInterceptor(jwtSecret []byte, optionalProcedures ...string) *AuthInt
 	}
 
 	return &AuthInterceptor{
-		jwtSecret:          jwtSecret,
+		keys:               keys,
 		optionalProcedures: procedureMap,
 	}
 }
 
+// WithRevocations makes the interceptor reject revoked access tokens.
+func (a *AuthInterceptor) WithRevocations(revocations RevocationChecker) *AuthInterceptor {
+	a.revocations = revocations
+	return a
+}
+
+func (a *AuthInterceptor) isRevoked(claims *Claims) bool {
+	return a.revocations != nil && a.revocations.IsRevoked(claims.ID)
+}
+
 // UnaryInterceptor returns a Connect unary interceptor that validates JWT tokens
 func (a *AuthInterceptor) UnaryInterceptor() connect.UnaryInterceptorFunc {
 	return func(next connect.UnaryFunc) connect.UnaryFunc {
@@ -77,51 +99,12 @@ func (a *AuthInterceptor) UnaryInterceptor() connect.UnaryInterceptorFunc {
 				)
 			}
 
-			// Expected format: "Bearer <token>"
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				return nil, connect.NewError(
-					connect.CodeUnauthenticated,
-					errors.New("invalid authorization header format, expected 'Bearer <token>'"),
-				)
-			}
-
-			tokenString := parts[1]
-
-			// Parse and validate JWT
-			claims := &Claims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				// Validate signing method
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, errors.New("unexpected signing method")
-				}
-				return a.jwtSecret, nil
-			})
+			claims, err := a.authenticate(authHeader)
 			if err != nil {
-				return nil, connect.NewError(
-					connect.CodeUnauthenticated,
-					errors.New("invalid token: "+err.Error()),
-				)
-			}
-
-			if !token.Valid {
-				return nil, connect.NewError(
-					connect.CodeUnauthenticated,
-					errors.New("token is not valid"),
-				)
-			}
-
-			// Check token expiration
-			if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
-				return nil, connect.NewError(
-					connect.CodeUnauthenticated,
-					errors.New("token has expired"),
-				)
+				return nil, err
 			}
 
-			// Add claims to context
-			ctx = context.WithValue(ctx, claimsKey, claims)
-			ctx = context.WithValue(ctx, UserIDKey, claims.UserID) // Backward compatibility
+			ctx = ContextWithClaims(ctx, claims)
 
 			return next(ctx, req)
 		}
@@ -144,16 +127,10 @@ func (a *AuthInterceptor) OptionalAuthInterceptor() connect.UnaryInterceptorFunc
 				parts := strings.SplitN(authHeader, " ", 2)
 				if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
 					claims := &Claims{}
-					token, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
-						if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-							return nil, errors.New("unexpected signing method")
-						}
-						return a.jwtSecret, nil
-					})
-
-					if err == nil && token.Valid && (claims.ExpiresAt == nil || claims.ExpiresAt.After(time.Now())) {
-						ctx = context.WithValue(ctx, claimsKey, claims)
-						ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+					token, err := a.keys.Parse(parts[1], claims)
+
+					if err == nil && token.Valid && claims.TokenUse == jwtkeys.TokenUseAccess && (claims.ExpiresAt == nil || claims.ExpiresAt.After(time.Now())) && !a.isRevoked(claims) {
+						ctx = ContextWithClaims(ctx, claims)
 					}
 				}
 			}
@@ -163,6 +140,12 @@ func (a *AuthInterceptor) OptionalAuthInterceptor() connect.UnaryInterceptorFunc
 	}
 }
 
+// ContextWithClaims returns a copy of ctx carrying the authenticated caller's claims.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = context.WithValue(ctx, claimsKey, claims)
+	return context.WithValue(ctx, UserIDKey, claims.UserID) // Backward compatibility
+}
+
 // GetClaimsFromContext retrieves the JWT claims from the context
 func GetClaimsFromContext(ctx context.Context) (*Claims, error) {
 	claims, ok := ctx.Value(claimsKey).(*Claims)
@@ -187,48 +170,6 @@ func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	return userID, ok
 }
 
-// NewRoleAuthInterceptor checks if the user has the required role
-func NewRoleAuthInterceptor(requiredRoles ...string) connect.UnaryInterceptorFunc {
-	roleMap := make(map[string]bool)
-	for _, role := range requiredRoles {
-		roleMap[role] = true
-	}
-
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(
-			ctx context.Context,
-			req connect.AnyRequest,
-		) (connect.AnyResponse, error) {
-			if req.Spec().IsClient {
-				return next(ctx, req)
-			}
-
-			claims, err := GetClaimsFromContext(ctx)
-			if err != nil {
-				return nil, connect.NewError(
-					connect.CodeUnauthenticated,
-					errors.New("authentication required"),
-				)
-			}
-
-			// Admin always has access
-			if claims.Role == "admin" {
-				return next(ctx, req)
-			}
-
-			// Check if user has required role
-			if !roleMap[claims.Role] {
-				return nil, connect.NewError(
-					connect.CodePermissionDenied,
-					errors.New("insufficient permissions"),
-				)
-			}
-
-			return next(ctx, req)
-		}
-	}
-}
-
 // WrapUnary implements connect.Interceptor.
 func (a *AuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 	return a.UnaryInterceptor()(next)
@@ -262,52 +203,89 @@ func (a *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc
 			)
 		}
 
-		// Expected format: "Bearer <token>"
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			return connect.NewError(
-				connect.CodeUnauthenticated,
-				errors.New("invalid authorization header format, expected 'Bearer <token>'"),
-			)
+		claims, err := a.authenticate(authHeader)
+		if err != nil {
+			return err
 		}
 
-		tokenString := parts[1]
+		ctx = ContextWithClaims(ctx, claims)
 
-		// Parse and validate JWT
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return a.jwtSecret, nil
-		})
-		if err != nil {
-			return connect.NewError(
-				connect.CodeUnauthenticated,
-				errors.New("invalid token: "+err.Error()),
-			)
-		}
+		return next(ctx, conn)
+	}
+}
 
-		if !token.Valid {
-			return connect.NewError(
-				connect.CodeUnauthenticated,
-				errors.New("token is not valid"),
-			)
-		}
+// authenticate validates a "Bearer <token>" Authorization header value.
+func (a *AuthInterceptor) authenticate(authHeader string) (*Claims, *connect.Error) {
+	// Expected format: "Bearer <token>"
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("invalid authorization header format, expected 'Bearer <token>'"),
+		)
+	}
 
-		// Check token expiration
-		if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
-			return connect.NewError(
-				connect.CodeUnauthenticated,
-				errors.New("token has expired"),
-			)
-		}
+	tokenString := parts[1]
 
-		// Add claims to context
-		ctx = context.WithValue(ctx, claimsKey, claims)
-		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	// Parse and validate JWT
+	claims := &Claims{}
+	token, err := a.keys.Parse(tokenString, claims)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("invalid token: "+err.Error()),
+		)
+	}
 
-		return next(ctx, conn)
+	if !token.Valid {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("token is not valid"),
+		)
+	}
+
+	// Refresh tokens are signed by the same keys but only buy new tokens.
+	if claims.TokenUse != jwtkeys.TokenUseAccess {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("not an access token"),
+		)
+	}
+
+	// Check token expiration
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("token has expired"),
+		)
 	}
+
+	if a.isRevoked(claims) {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("token has been revoked"),
+		)
+	}
+
+	return claims, nil
+}
+
+// HTTPMiddleware applies the same JWT authentication to plain HTTP handlers, for
+// endpoints that are not Connect procedures.
+func (a *AuthInterceptor) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			http.Error(w, "missing authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := a.authenticate(authHeader)
+		if err != nil {
+			http.Error(w, err.Message(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+	})
 }
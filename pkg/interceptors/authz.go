@@ -0,0 +1,302 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Role is the caller role carried in the access token's role claim.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RolePremium   Role = "premium"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// ParseRole maps a stored role to a Role. Unknown roles, including the legacy
+// "member", are plain users.
+func ParseRole(role string) Role {
+	switch r := Role(strings.ToLower(strings.TrimSpace(role))); r {
+	case RolePremium, RoleModerator, RoleAdmin:
+		return r
+	default:
+		return RoleUser
+	}
+}
+
+// grants reports whether r includes required: admins can do everything moderators and
+// premium users can, and every role includes RoleUser.
+func (r Role) grants(required Role) bool {
+	switch r {
+	case RoleAdmin:
+		return true
+	case RoleModerator, RolePremium:
+		return required == r || required == RoleUser
+	default:
+		return required == RoleUser
+	}
+}
+
+// HasRole reports whether the authenticated caller holds role.
+func HasRole(ctx context.Context, role Role) bool {
+	claims, err := GetClaimsFromContext(ctx)
+	return err == nil && ParseRole(claims.Role).grants(role)
+}
+
+// Policy is the authorization rule of a procedure.
+type Policy struct {
+	// Public procedures accept anonymous callers. A token that is sent is still
+	// verified, so handlers can tell who is calling.
+	Public bool
+	// AnyOf lists the roles allowed to call the procedure; holding one suffices.
+	// Empty allows every authenticated caller.
+	AnyOf []Role
+}
+
+// Public allows anonymous callers.
+func Public() Policy {
+	return Policy{Public: true}
+}
+
+// Authenticated allows every signed-in caller.
+func Authenticated() Policy {
+	return Policy{}
+}
+
+// RequireRole allows callers holding any of roles.
+func RequireRole(roles ...Role) Policy {
+	return Policy{AnyOf: roles}
+}
+
+func (p Policy) String() string {
+	switch {
+	case p.Public:
+		return "public"
+	case len(p.AnyOf) == 0:
+		return "authenticated"
+	}
+	roles := make([]string, len(p.AnyOf))
+	for i, r := range p.AnyOf {
+		roles[i] = string(r)
+	}
+	return "role:" + strings.Join(roles, "|")
+}
+
+// Policies maps every Connect procedure to its Policy.
+type Policies map[string]Policy
+
+// PublicProcedures returns the procedures that accept anonymous callers, for
+// NewAuthInterceptor.
+func (p Policies) PublicProcedures() []string {
+	var procedures []string
+	for procedure, policy := range p {
+		if policy.Public {
+			procedures = append(procedures, procedure)
+		}
+	}
+	slices.Sort(procedures)
+	return procedures
+}
+
+// Verify returns an error naming every procedure without a policy. Call it at startup
+// with the procedures of all registered services, so a new RPC cannot ship without
+// an explicit decision about who may call it.
+func (p Policies) Verify(procedures []string) error {
+	var missing []string
+	for _, procedure := range procedures {
+		if _, ok := p[procedure]; !ok {
+			missing = append(missing, procedure)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("procedures without an authorization policy: %s", strings.Join(missing, ", "))
+}
+
+// ServiceProcedures lists the procedures of the Connect service mounted at path, e.g.
+// "/loci.auth.AuthService/", using its registered protobuf descriptor.
+func ServiceProcedures(path string) ([]string, error) {
+	name := protoreflect.FullName(strings.Trim(path, "/"))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", name, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", name)
+	}
+	methods := service.Methods()
+	procedures := make([]string, 0, methods.Len())
+	for i := range methods.Len() {
+		procedures = append(procedures, "/"+string(name)+"/"+string(methods.Get(i).Name()))
+	}
+	return procedures, nil
+}
+
+// AuthzInterceptor enforces Policies on unary and streaming calls. It must run after
+// AuthInterceptor, which puts the caller's claims in the context. Procedures without a
+// policy are denied.
+type AuthzInterceptor struct {
+	policies Policies
+	logger   *slog.Logger
+}
+
+var _ connect.Interceptor = (*AuthzInterceptor)(nil)
+
+// NewAuthzInterceptor creates an interceptor enforcing policies.
+func NewAuthzInterceptor(policies Policies, logger *slog.Logger) *AuthzInterceptor {
+	return &AuthzInterceptor{
+		policies: policies,
+		logger:   logger,
+	}
+}
+
+// Authorize checks policy for the caller in ctx.
+func (a *AuthzInterceptor) Authorize(ctx context.Context, policy Policy) *connect.Error {
+	if policy.Public {
+		return nil
+	}
+	claims, err := GetClaimsFromContext(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+	if len(policy.AnyOf) == 0 {
+		return nil
+	}
+
+	role := ParseRole(claims.Role)
+	for _, required := range policy.AnyOf {
+		if role.grants(required) {
+			return nil
+		}
+	}
+	return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("requires %s", policy))
+}
+
+func (a *AuthzInterceptor) authorizeProcedure(ctx context.Context, procedure string) *connect.Error {
+	policy, ok := a.policies[procedure]
+	if !ok {
+		a.logger.ErrorContext(ctx, "no authorization policy for procedure", slog.String("procedure", procedure))
+		return connect.NewError(connect.CodePermissionDenied, errors.New("procedure has no authorization policy"))
+	}
+	return a.Authorize(ctx, policy)
+}
+
+func (a *AuthzInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		if err := a.authorizeProcedure(ctx, req.Spec().Procedure); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (a *AuthzInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (a *AuthzInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if conn.Spec().IsClient {
+			return next(ctx, conn)
+		}
+		if err := a.authorizeProcedure(ctx, conn.Spec().Procedure); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+// HTTPMiddleware enforces policy on a plain HTTP handler behind
+// AuthInterceptor.HTTPMiddleware.
+func (a *AuthzInterceptor) HTTPMiddleware(policy Policy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.Authorize(r.Context(), policy); err != nil {
+			status := http.StatusForbidden
+			if err.Code() == connect.CodeUnauthenticated {
+				status = http.StatusUnauthorized
+			}
+			http.Error(w, err.Message(), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AuthorizeUser returns the user a request acts on: requested when it is set and
+// otherwise the caller. Acting on another user needs one of the override roles.
+func AuthorizeUser(ctx context.Context, requested string, override ...Role) (uuid.UUID, error) {
+	callerID, err := callerID(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if requested == "" || requested == callerID.String() {
+		return callerID, nil
+	}
+	if !hasAnyRole(ctx, override) {
+		return uuid.Nil, connect.NewError(connect.CodePermissionDenied, errors.New("user_id does not match authenticated user"))
+	}
+	userID, err := uuid.Parse(requested)
+	if err != nil {
+		return uuid.Nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid user_id"))
+	}
+	return userID, nil
+}
+
+// RequireOwner allows the caller when they own a resource owned by ownerID or hold one
+// of the override roles, e.g. moderators acting on any review.
+func RequireOwner(ctx context.Context, ownerID uuid.UUID, override ...Role) error {
+	callerID, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+	if callerID == ownerID || hasAnyRole(ctx, override) {
+		return nil
+	}
+	return connect.NewError(connect.CodePermissionDenied, errors.New("caller does not own the resource"))
+}
+
+// OptionalUserID returns the caller on procedures that also serve anonymous callers. It
+// reports false for anonymous callers and for tokens whose user ID is not a UUID.
+func OptionalUserID(ctx context.Context) (uuid.UUID, bool) {
+	userID, err := callerID(ctx)
+	return userID, err == nil
+}
+
+func callerID(ctx context.Context) (uuid.UUID, error) {
+	userIDStr, ok := GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		return uuid.Nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid user ID in token"))
+	}
+	return userID, nil
+}
+
+func hasAnyRole(ctx context.Context, roles []Role) bool {
+	for _, role := range roles {
+		if HasRole(ctx, role) {
+			return true
+		}
+	}
+	return false
+}
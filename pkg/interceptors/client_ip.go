@@ -0,0 +1,97 @@
+package interceptors
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// TrustedProxies are the networks of the reverse proxies in front of the server.
+// Forwarding headers are only believed when a trusted proxy sent them.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses CIDRs such as "10.0.0.0/8"; a bare address stands for
+// itself alone.
+func ParseTrustedProxies(cidrs []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that made a request, without the port.
+// The connecting peer is the client unless it is one of proxies. Then X-Forwarded-For
+// is read from the right, where each proxy appends the address it was connected from,
+// and the first hop that is not a trusted proxy is the client. Hops to its left were
+// sent by the client itself and are never used. X-Real-IP is read only when a trusted
+// proxy sent no X-Forwarded-For.
+func ClientIP(header http.Header, peer connect.Peer, proxies TrustedProxies) string {
+	host := peer.Addr
+	if h, _, err := net.SplitHostPort(peer.Addr); err == nil {
+		host = h
+	}
+	client, err := netip.ParseAddr(host)
+	if err != nil || !proxies.contains(client) {
+		return host
+	}
+
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+		return host
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A garbled hop cannot be trusted; the last proxy that passed it on is
+			// the best known client.
+			break
+		}
+		client = hop.Unmap()
+		if !proxies.contains(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// RequestClientIP is ClientIP for a plain HTTP request.
+func RequestClientIP(r *http.Request, proxies TrustedProxies) string {
+	return ClientIP(r.Header, connect.Peer{Addr: r.RemoteAddr}, proxies)
+}
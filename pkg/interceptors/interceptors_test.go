@@ -2,11 +2,28 @@ package interceptors
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
-	"golang.org/x/time/rate"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/jwtkeys"
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
+	"github.com/FACorreiaa/loci-connect-api/pkg/ratelimit"
 )
 
 func TestRequestIDInterceptor_GeneratesID(t *testing.T) {
@@ -25,15 +42,579 @@ func TestRequestIDInterceptor_GeneratesID(t *testing.T) {
 	}
 }
 
+type stubPlanResolver map[string]string
+
+func (s stubPlanResolver) PlanForUser(_ context.Context, userID string) (string, error) {
+	return s[userID], nil
+}
+
 func TestRateLimitInterceptor_ExceedsLimit(t *testing.T) {
-	limiter := rate.NewLimiter(0, 0)
-	interceptor := NewRateLimitInterceptor(limiter)
+	interceptor := NewRateLimitInterceptor(RateLimitOptions{
+		Store:     ratelimit.NewMemoryStore(),
+		Anonymous: ratelimit.Limit{Rate: 1, Burst: 2},
+		Logger:    slog.New(slog.DiscardHandler),
+	})
 	handler := interceptor.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
 		return connect.NewResponse(&emptypb.Empty{}), nil
 	})
 	req := connect.NewRequest(&emptypb.Empty{})
+	for range 2 {
+		resp, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		if resp.Header().Get(RateLimitLimitHeader) != "2" {
+			t.Fatalf("expected RateLimit-Limit 2, got %q", resp.Header().Get(RateLimitLimitHeader))
+		}
+	}
 	_, err := handler(context.Background(), req)
-	if err == nil || connect.CodeOf(err) != connect.CodeResourceExhausted {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted {
+		t.Fatalf("expected resource exhausted, got %v", err)
+	}
+	if got := connectErr.Meta().Get(RetryAfterHeader); got != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", got)
+	}
+	if got := connectErr.Meta().Get(RateLimitRemainingHeader); got != "0" {
+		t.Fatalf("expected RateLimit-Remaining 0, got %q", got)
+	}
+}
+
+func TestRateLimitInterceptor_KeysByUserPlanAndWeight(t *testing.T) {
+	interceptor := NewRateLimitInterceptor(RateLimitOptions{
+		Store: ratelimit.NewMemoryStore(),
+		Plans: map[string]ratelimit.Limit{
+			"free":            {Rate: 1, Burst: 10},
+			"premium_monthly": {Rate: 1, Burst: 100},
+		},
+		DefaultPlan: "free",
+		Resolver:    stubPlanResolver{"premium-user": "premium_monthly"},
+		Weights:     map[string]float64{"": 5},
+		Logger:      slog.New(slog.DiscardHandler),
+	})
+	handler := interceptor.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+	call := func(userID string) error {
+		ctx := context.WithValue(context.Background(), UserIDKey, userID)
+		_, err := handler(ctx, connect.NewRequest(&emptypb.Empty{}))
+		return err
+	}
+
+	// Each call costs 5 tokens: a free user gets two, and does not drain anyone else.
+	for range 2 {
+		if err := call("free-user"); err != nil {
+			t.Fatalf("free user rejected early: %v", err)
+		}
+	}
+	if err := call("free-user"); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("expected free user to be limited, got %v", err)
+	}
+	for range 20 {
+		if err := call("premium-user"); err != nil {
+			t.Fatalf("premium user rejected early: %v", err)
+		}
+	}
+}
+
+type stubQuotaChecker struct {
+	status QuotaStatus
+	err    error
+}
+
+func (s stubQuotaChecker) CheckQuota(context.Context, string) (QuotaStatus, error) {
+	return s.status, s.err
+}
+
+func TestClientIP_IgnoresSpoofedForwardedFor(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.7"})
+	if err != nil {
+		t.Fatalf("parse trusted proxies: %v", err)
+	}
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{name: "direct client ignores headers", peer: "198.51.100.4:5000", forwarded: []string{"203.0.113.1"}, realIP: "203.0.113.2", want: "198.51.100.4"},
+		{name: "proxy appends client", peer: "10.0.0.2:443", forwarded: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "spoofed leftmost entry", peer: "10.0.0.2:443", forwarded: []string{"203.0.113.99, 198.51.100.4"}, want: "198.51.100.4"},
+		{name: "chain of proxies", peer: "10.0.0.2:443", forwarded: []string{"203.0.113.99, 198.51.100.4, 192.0.2.7", "10.1.1.1"}, want: "198.51.100.4"},
+		{name: "garbled hop", peer: "10.0.0.2:443", forwarded: []string{"198.51.100.4, not-an-ip"}, want: "10.0.0.2"},
+		{name: "real ip from proxy", peer: "10.0.0.2:443", realIP: "198.51.100.4", want: "198.51.100.4"},
+		{name: "only proxies", peer: "10.0.0.2:443", forwarded: []string{"10.0.0.3"}, want: "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tt.forwarded {
+				header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := ClientIP(header, connect.Peer{Addr: tt.peer}, proxies); got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatalf("expected invalid CIDR to be rejected")
+	}
+}
+
+func TestRateLimitInterceptor_RotatingForwardedForSharesBucket(t *testing.T) {
+	proxies, _ := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	interceptor := NewRateLimitInterceptor(RateLimitOptions{
+		Store:          ratelimit.NewMemoryStore(),
+		Anonymous:      ratelimit.Limit{Rate: 1, Burst: 2},
+		TrustedProxies: proxies,
+		Logger:         slog.New(slog.DiscardHandler),
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/test.Service/Call", connect.NewUnaryHandler("/test.Service/Call",
+		func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		}, connect.WithInterceptors(interceptor)))
+
+	// The request comes from a trusted proxy, which appended the real client last.
+	var codes []int
+	for i := range 3 {
+		req := httptest.NewRequest(http.MethodPost, "/test.Service/Call", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d, 198.51.100.4", i))
+		req.RemoteAddr = "10.0.0.2:443"
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("expected the third call to be limited, got %v", codes)
+	}
+}
+
+func TestRateLimitInterceptor_HTTPMiddlewareLimitsPerIPAndTarget(t *testing.T) {
+	interceptor := NewRateLimitInterceptor(RateLimitOptions{
+		Store:  ratelimit.NewMemoryStore(),
+		Logger: slog.New(slog.DiscardHandler),
+	})
+	var bodies []string
+	handler := interceptor.HTTPMiddleware(HTTPRateLimit{
+		Name:      "password_forgot",
+		PerIP:     ratelimit.Limit{Rate: 0.001, Burst: 3},
+		PerTarget: ratelimit.Limit{Rate: 0.001, Burst: 2},
+		Target:    JSONBodyField("email", 1<<10),
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+
+	call := func(ip, email string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/password/forgot",
+			strings.NewReader(`{"email": "`+email+`"}`))
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// One address is limited across IPs, matched case-insensitively.
+	codes := []int{call("198.51.100.1", "a@example.com"), call("198.51.100.2", "A@example.com"), call("198.51.100.3", "a@example.com")}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("expected the third request for one address to be limited, got %v", codes)
+	}
+	if bodies[0] != `{"email": "a@example.com"}` {
+		t.Fatalf("expected the handler to read the whole body, got %q", bodies[0])
+	}
+
+	// One IP is limited across addresses.
+	codes = []int{call("192.0.2.1", "b@example.com"), call("192.0.2.1", "c@example.com"), call("192.0.2.1", "d@example.com"), call("192.0.2.1", "e@example.com")}
+	if codes[2] != http.StatusOK || codes[3] != http.StatusTooManyRequests {
+		t.Fatalf("expected the fourth request from one IP to be limited, got %v", codes)
+	}
+}
+
+func TestQuotaInterceptor_RejectsWhenExceeded(t *testing.T) {
+	reset := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	checker := stubQuotaChecker{status: QuotaStatus{
+		Plan: "free", DailyLimit: 1000, DailyUsed: 1200, DailyResetAt: reset,
+		MonthlyLimit: 10000, MonthlyUsed: 4000,
+	}}
+	interceptor := NewQuotaInterceptor(checker, slog.New(slog.DiscardHandler))
+	called := false
+	handler := interceptor.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		called = true
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+
+	ctx := context.WithValue(context.Background(), UserIDKey, "user-1")
+	_, err := handler(ctx, connect.NewRequest(&emptypb.Empty{}))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted {
 		t.Fatalf("expected resource exhausted, got %v", err)
 	}
+	if called {
+		t.Fatalf("handler must not run once the quota is exceeded")
+	}
+	if got := connectErr.Meta().Get(QuotaDailyRemainingTrailer); got != "0" {
+		t.Fatalf("expected daily remaining 0, got %q", got)
+	}
+	if got := connectErr.Meta().Get(QuotaMonthlyRemainingTrailer); got != "6000" {
+		t.Fatalf("expected monthly remaining 6000, got %q", got)
+	}
+	if got := connectErr.Meta().Get(QuotaResetTrailer); got != "2026-03-15T00:00:00Z" {
+		t.Fatalf("unexpected reset trailer %q", got)
+	}
+}
+
+func TestQuotaInterceptor_SetsTrailersAndFailsOpen(t *testing.T) {
+	next := func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	}
+	ctx := context.WithValue(context.Background(), UserIDKey, "user-1")
+
+	ok := NewQuotaInterceptor(stubQuotaChecker{status: QuotaStatus{Plan: "free", DailyLimit: 1000, DailyUsed: 250}}, slog.New(slog.DiscardHandler))
+	resp, err := ok.WrapUnary(next)(ctx, connect.NewRequest(&emptypb.Empty{}))
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if got := resp.Trailer().Get(QuotaDailyRemainingTrailer); got != "750" {
+		t.Fatalf("expected daily remaining 750, got %q", got)
+	}
+
+	failing := NewQuotaInterceptor(stubQuotaChecker{err: errors.New("db down")}, slog.New(slog.DiscardHandler))
+	if _, err := failing.WrapUnary(next)(ctx, connect.NewRequest(&emptypb.Empty{})); err != nil {
+		t.Fatalf("quota lookup failures must not block requests: %v", err)
+	}
+
+	unmetered := NewQuotaInterceptor(stubQuotaChecker{status: QuotaStatus{DailyLimit: 1, DailyUsed: 1}}, slog.New(slog.DiscardHandler), "/loci.chat.ChatService/StartChat")
+	if _, err := unmetered.WrapUnary(next)(ctx, connect.NewRequest(&emptypb.Empty{})); err != nil {
+		t.Fatalf("procedures outside the metered set must pass: %v", err)
+	}
+}
+
+func TestTracingInterceptor_ContinuesInboundTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := observability.SetupTracing(context.Background(), observability.TracingOptions{
+		SampleRatio: 0,
+		Exporter:    exporter,
+	})
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	interceptor := NewTracingInterceptor(otel.Tracer("test"))
+	handler := interceptor.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+
+	req := connect.NewRequest(&emptypb.Empty{})
+	req.Header().Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	// The ratio sampler would drop a new trace; the span is only exported because the
+	// caller marked its trace as sampled.
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected one exported span, got %d", len(spans))
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != traceID {
+		t.Fatalf("expected trace %s, got %s", traceID, got)
+	}
+	if got := spans[0].Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Fatalf("expected parent span 00f067aa0ba902b7, got %s", got)
+	}
+}
+
+func TestAuthInterceptor_VerifiesSigningKeys(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signingKey, err := jwtkeys.NewPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("new private key: %v", err)
+	}
+	keys, err := jwtkeys.NewKeySet(signingKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	token, err := keys.Sign(&Claims{
+		UserID:           "user-1",
+		TokenUse:         jwtkeys.TokenUseAccess,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	handler := NewAuthInterceptor(keys).WrapUnary(func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		if userID, _ := GetUserIDFromContext(ctx); userID != "user-1" {
+			t.Fatalf("expected user-1 in context, got %q", userID)
+		}
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+
+	req := connect.NewRequest(&emptypb.Empty{})
+	req.Header().Set("Authorization", "Bearer "+token)
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("expected EdDSA token to be accepted, got %v", err)
+	}
+
+	hs256, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: "user-1"}).SignedString([]byte("changeme"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	req = connect.NewRequest(&emptypb.Empty{})
+	req.Header().Set("Authorization", "Bearer "+hs256)
+	if _, err := handler(context.Background(), req); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected HS256 token to be rejected, got %v", err)
+	}
+}
+
+type revokedSet map[string]bool
+
+func (r revokedSet) IsRevoked(jti string) bool { return r[jti] }
+
+func TestAuthInterceptor_RejectsRevokedTokens(t *testing.T) {
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, err := jwtkeys.NewKeySet(hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	sign := func(jti string) string {
+		token, err := keys.Sign(&Claims{
+			UserID:   "user-1",
+			TokenUse: jwtkeys.TokenUseAccess,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        jti,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+
+	auth := NewAuthInterceptor(keys).WithRevocations(revokedSet{"revoked": true})
+	handler := auth.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+
+	req := connect.NewRequest(&emptypb.Empty{})
+	req.Header().Set("Authorization", "Bearer "+sign("live"))
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("expected live token to be accepted, got %v", err)
+	}
+
+	req = connect.NewRequest(&emptypb.Empty{})
+	req.Header().Set("Authorization", "Bearer "+sign("revoked"))
+	if _, err := handler(context.Background(), req); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestAuthInterceptor_RejectsRefreshTokens(t *testing.T) {
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, err := jwtkeys.NewKeySet(hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	auth := NewAuthInterceptor(keys)
+	handler := auth.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+	optional := auth.OptionalAuthInterceptor()(func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		if _, err := GetClaimsFromContext(ctx); err == nil {
+			t.Fatalf("expected no claims in context")
+		}
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+
+	for _, use := range []string{jwtkeys.TokenUseRefresh, ""} {
+		token, err := keys.Sign(&Claims{
+			UserID:           "user-1",
+			TokenUse:         use,
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		req := connect.NewRequest(&emptypb.Empty{})
+		req.Header().Set("Authorization", "Bearer "+token)
+		if _, err := handler(context.Background(), req); connect.CodeOf(err) != connect.CodeUnauthenticated {
+			t.Fatalf("expected token_use %q to be rejected, got %v", use, err)
+		}
+		if _, err := optional(context.Background(), req); err != nil {
+			t.Fatalf("optional auth: %v", err)
+		}
+	}
+}
+
+func TestAuthzInterceptor_EnforcesPoliciesOnUnaryAndStreams(t *testing.T) {
+	const (
+		publicProcedure    = "/test.Service/Public"
+		userProcedure      = "/test.Service/User"
+		premiumProcedure   = "/test.Service/Premium"
+		moderatorProcedure = "/test.Service/Moderate"
+		adminStream        = "/test.Service/AdminStream"
+		unlistedProcedure  = "/test.Service/Unlisted"
+	)
+	policies := Policies{
+		publicProcedure:    Public(),
+		userProcedure:      Authenticated(),
+		premiumProcedure:   RequireRole(RolePremium),
+		moderatorProcedure: RequireRole(RoleModerator),
+		adminStream:        RequireRole(RoleAdmin),
+	}
+
+	hmacKey, _ := jwtkeys.NewHMACKey([]byte("secret"))
+	keys, err := jwtkeys.NewKeySet(hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	tokenFor := func(userID, role string) string {
+		token, err := keys.Sign(&Claims{
+			UserID:           userID,
+			Role:             role,
+			TokenUse:         jwtkeys.TokenUseAccess,
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+
+	authz := NewAuthzInterceptor(policies, slog.New(slog.DiscardHandler))
+	opts := connect.WithInterceptors(NewAuthInterceptor(keys, policies.PublicProcedures()...), authz)
+	unary := func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	}
+	mux := http.NewServeMux()
+	for _, procedure := range []string{publicProcedure, userProcedure, premiumProcedure, moderatorProcedure, unlistedProcedure} {
+		mux.Handle(procedure, connect.NewUnaryHandler(procedure, unary, opts))
+	}
+	mux.Handle(adminStream, connect.NewServerStreamHandler(adminStream,
+		func(_ context.Context, _ *connect.Request[emptypb.Empty], stream *connect.ServerStream[emptypb.Empty]) error {
+			return stream.Send(&emptypb.Empty{})
+		}, opts))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	call := func(procedure, token string) connect.Code {
+		req := connect.NewRequest(&emptypb.Empty{})
+		if token != "" {
+			req.Header().Set("Authorization", "Bearer "+token)
+		}
+		if procedure == adminStream {
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+procedure)
+			stream, err := client.CallServerStream(context.Background(), req)
+			if err != nil {
+				return codeOf(err)
+			}
+			defer stream.Close()
+			for stream.Receive() {
+			}
+			return codeOf(stream.Err())
+		}
+		client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+procedure)
+		_, err := client.CallUnary(context.Background(), req)
+		return codeOf(err)
+	}
+
+	for _, tc := range []struct {
+		procedure string
+		token     string
+		want      connect.Code
+	}{
+		{publicProcedure, "", codeOK},
+		{userProcedure, "", connect.CodeUnauthenticated},
+		{userProcedure, tokenFor("u1", "member"), codeOK},
+		{premiumProcedure, tokenFor("u1", "user"), connect.CodePermissionDenied},
+		{premiumProcedure, tokenFor("u1", "premium"), codeOK},
+		{moderatorProcedure, tokenFor("u1", "premium"), connect.CodePermissionDenied},
+		{moderatorProcedure, tokenFor("u1", "moderator"), codeOK},
+		{moderatorProcedure, tokenFor("u1", "admin"), codeOK},
+		{adminStream, "", connect.CodeUnauthenticated},
+		{adminStream, tokenFor("u1", "moderator"), connect.CodePermissionDenied},
+		{adminStream, tokenFor("u1", "admin"), codeOK},
+		{unlistedProcedure, tokenFor("u1", "admin"), connect.CodePermissionDenied},
+	} {
+		if got := call(tc.procedure, tc.token); got != tc.want {
+			t.Errorf("%s with token %q: got %v, want %v", tc.procedure, tc.token, got, tc.want)
+		}
+	}
+}
+
+func TestPolicies_VerifyAndServiceProcedures(t *testing.T) {
+	policies := Policies{"/a.S/One": Public(), "/a.S/Two": Authenticated()}
+	if err := policies.Verify([]string{"/a.S/One", "/a.S/Two"}); err != nil {
+		t.Fatalf("expected full coverage, got %v", err)
+	}
+	err := policies.Verify([]string{"/a.S/One", "/a.S/Three"})
+	if err == nil || err.Error() != "procedures without an authorization policy: /a.S/Three" {
+		t.Fatalf("expected /a.S/Three to be reported, got %v", err)
+	}
+
+	if _, err := ServiceProcedures("/no.such.Service/"); err == nil {
+		t.Fatalf("expected an unknown service to fail")
+	}
+}
+
+func TestAuthorizeUserRequireOwnerAndOptionalUserID(t *testing.T) {
+	caller, other := uuid.New(), uuid.New()
+	userCtx := ContextWithClaims(context.Background(), &Claims{UserID: caller.String(), Role: "user"})
+	adminCtx := ContextWithClaims(context.Background(), &Claims{UserID: caller.String(), Role: "admin"})
+
+	if got, err := AuthorizeUser(userCtx, ""); err != nil || got != caller {
+		t.Fatalf("expected the caller, got %v, %v", got, err)
+	}
+	if _, err := AuthorizeUser(userCtx, other.String(), RoleAdmin); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("expected users to be denied other users, got %v", err)
+	}
+	if got, err := AuthorizeUser(adminCtx, other.String(), RoleAdmin); err != nil || got != other {
+		t.Fatalf("expected admins to act on other users, got %v, %v", got, err)
+	}
+	if _, err := AuthorizeUser(context.Background(), ""); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected anonymous callers to be rejected, got %v", err)
+	}
+
+	if err := RequireOwner(userCtx, caller); err != nil {
+		t.Fatalf("expected owners to pass, got %v", err)
+	}
+	if err := RequireOwner(userCtx, other, RoleModerator); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("expected non-owners to be denied, got %v", err)
+	}
+	if err := RequireOwner(adminCtx, other, RoleModerator); err != nil {
+		t.Fatalf("admins include the moderator role, got %v", err)
+	}
+
+	if got, ok := OptionalUserID(userCtx); !ok || got != caller {
+		t.Fatalf("expected the caller, got %v, %v", got, ok)
+	}
+	if got, ok := OptionalUserID(context.Background()); ok || got != uuid.Nil {
+		t.Fatalf("expected no caller for anonymous requests, got %v, %v", got, ok)
+	}
+	if _, ok := OptionalUserID(context.WithValue(context.Background(), UserIDKey, "not-a-uuid")); ok {
+		t.Fatal("expected malformed user IDs to be treated as anonymous")
+	}
+}
+
+// codeOK is the code codeOf reports for successful calls.
+const codeOK = connect.Code(0)
+
+func codeOf(err error) connect.Code {
+	if err == nil {
+		return codeOK
+	}
+	return connect.CodeOf(err)
 }
@@ -7,62 +7,116 @@ import (
 
 	"connectrpc.com/connect"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/observability"
 )
 
+// LoggingInterceptor logs unary RPCs with payload sizes and server streams with their
+// message counts and latency to the first message.
+type LoggingInterceptor struct {
+	logger *slog.Logger
+}
+
+var _ connect.Interceptor = (*LoggingInterceptor)(nil)
+
 // NewLoggingInterceptor creates a new logging interceptor with payload size tracking
-func NewLoggingInterceptor(logger *slog.Logger) connect.UnaryInterceptorFunc {
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-			start := time.Now()
-
-			// Calculate request payload size
-			requestSize := 0
-			if msg, ok := req.Any().(proto.Message); ok {
-				requestSize = proto.Size(msg)
+func NewLoggingInterceptor(logger *slog.Logger) *LoggingInterceptor {
+	return &LoggingInterceptor{logger: logger}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *LoggingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	logger := i.logger
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		start := time.Now()
+
+		// Calculate request payload size
+		requestSize := 0
+		if msg, ok := req.Any().(proto.Message); ok {
+			requestSize = proto.Size(msg)
+		}
+
+		logger.InfoContext(ctx, "RPC started", appendLoggerFields(ctx,
+			"procedure", req.Spec().Procedure,
+			"peer", req.Peer().Addr,
+			"request_size_bytes", requestSize,
+		)...)
+
+		resp, err := next(ctx, req)
+
+		duration := time.Since(start)
+
+		// Calculate response payload size
+		responseSize := 0
+		if resp != nil {
+			// Safely access resp.Any() - it can be nil even if resp is not nil
+			if anyResp := resp.Any(); anyResp != nil {
+				if msg, ok := anyResp.(proto.Message); ok {
+					responseSize = proto.Size(msg)
+				}
 			}
+		}
 
-			logger.Info("RPC started", appendLoggerFields(ctx,
+		if err != nil {
+			logger.ErrorContext(ctx, "RPC failed", appendLoggerFields(ctx,
+				"procedure", req.Spec().Procedure,
+				"duration", duration.String(),
+				"duration_ms", duration.Milliseconds(),
+				"request_size_bytes", requestSize,
+				"response_size_bytes", responseSize,
+				"error", err,
+			)...)
+		} else {
+			logger.InfoContext(ctx, "RPC completed", appendLoggerFields(ctx,
 				"procedure", req.Spec().Procedure,
-				"peer", req.Peer().Addr,
+				"duration", duration.String(),
+				"duration_ms", duration.Milliseconds(),
 				"request_size_bytes", requestSize,
+				"response_size_bytes", responseSize,
 			)...)
+		}
 
-			resp, err := next(ctx, req)
+		return resp, err
+	}
+}
 
-			duration := time.Since(start)
+// WrapStreamingClient implements connect.Interceptor.
+func (i *LoggingInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
 
-			// Calculate response payload size
-			responseSize := 0
-			if resp != nil {
-				// Safely access resp.Any() - it can be nil even if resp is not nil
-				if anyResp := resp.Any(); anyResp != nil {
-					if msg, ok := anyResp.(proto.Message); ok {
-						responseSize = proto.Size(msg)
-					}
-				}
-			}
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *LoggingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	logger := i.logger
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		logger.InfoContext(ctx, "Stream started", appendLoggerFields(ctx,
+			"procedure", conn.Spec().Procedure,
+			"peer", conn.Peer().Addr,
+		)...)
 
-			if err != nil {
-				logger.Error("RPC failed", appendLoggerFields(ctx,
-					"procedure", req.Spec().Procedure,
-					"duration", duration.String(),
-					"duration_ms", duration.Milliseconds(),
-					"request_size_bytes", requestSize,
-					"response_size_bytes", responseSize,
-					"error", err,
-				)...)
-			} else {
-				logger.Info("RPC completed", appendLoggerFields(ctx,
-					"procedure", req.Spec().Procedure,
-					"duration", duration.String(),
-					"duration_ms", duration.Milliseconds(),
-					"request_size_bytes", requestSize,
-					"response_size_bytes", responseSize,
-				)...)
-			}
+		observed := observability.ObserveStream(conn)
+		err := next(ctx, observed)
+		stats := observed.Stats()
+
+		fields := appendLoggerFields(ctx,
+			"procedure", conn.Spec().Procedure,
+			"code", observability.Code(err),
+			"duration", stats.Duration.String(),
+			"duration_ms", stats.Duration.Milliseconds(),
+			"messages_sent", stats.MessagesSent,
+			"messages_received", stats.MessagesReceived,
+			"bytes_out", stats.BytesOut,
+		)
+		if stats.MessagesSent > 0 {
+			fields = append(fields, "time_to_first_message_ms", stats.TimeToFirstMessage.Milliseconds())
+		}
 
-			return resp, err
+		if err != nil {
+			logger.ErrorContext(ctx, "Stream failed", append(fields, "error", err)...)
+		} else {
+			logger.InfoContext(ctx, "Stream completed", fields...)
 		}
+		return err
 	}
 }
 
@@ -0,0 +1,185 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// Trailers describing the caller's remaining quota, sent on success and on
+// ResourceExhausted.
+const (
+	QuotaPlanTrailer             = "X-Quota-Plan"
+	QuotaDailyLimitTrailer       = "X-Quota-Daily-Limit"
+	QuotaDailyRemainingTrailer   = "X-Quota-Daily-Remaining"
+	QuotaMonthlyLimitTrailer     = "X-Quota-Monthly-Limit"
+	QuotaMonthlyRemainingTrailer = "X-Quota-Monthly-Remaining"
+	QuotaResetTrailer            = "X-Quota-Reset"
+)
+
+// QuotaStatus is a user's token consumption against their plan. A zero limit means
+// the window is not capped.
+type QuotaStatus struct {
+	Plan           string
+	DailyLimit     int64
+	DailyUsed      int64
+	DailyResetAt   time.Time
+	MonthlyLimit   int64
+	MonthlyUsed    int64
+	MonthlyResetAt time.Time
+}
+
+func (q QuotaStatus) DailyRemaining() int64 {
+	return max(0, q.DailyLimit-q.DailyUsed)
+}
+
+func (q QuotaStatus) MonthlyRemaining() int64 {
+	return max(0, q.MonthlyLimit-q.MonthlyUsed)
+}
+
+func (q QuotaStatus) dailyExceeded() bool {
+	return q.DailyLimit > 0 && q.DailyUsed >= q.DailyLimit
+}
+
+func (q QuotaStatus) monthlyExceeded() bool {
+	return q.MonthlyLimit > 0 && q.MonthlyUsed >= q.MonthlyLimit
+}
+
+// Exceeded reports whether any capped window is used up.
+func (q QuotaStatus) Exceeded() bool {
+	return q.dailyExceeded() || q.monthlyExceeded()
+}
+
+// resetAt is when the caller can next make a request: the end of the longest window
+// that is exhausted, or the daily reset when nothing is.
+func (q QuotaStatus) resetAt() time.Time {
+	if q.monthlyExceeded() {
+		return q.MonthlyResetAt
+	}
+	return q.DailyResetAt
+}
+
+// QuotaChecker reports the current quota of a user.
+type QuotaChecker interface {
+	CheckQuota(ctx context.Context, userID string) (QuotaStatus, error)
+}
+
+// QuotaInterceptor rejects calls to the metered procedures once the authenticated
+// user has used up their plan's token quota. It must run after AuthInterceptor.
+// Calls without a user are let through; authentication is not this interceptor's job.
+type QuotaInterceptor struct {
+	checker    QuotaChecker
+	logger     *slog.Logger
+	procedures map[string]struct{}
+}
+
+var _ connect.Interceptor = (*QuotaInterceptor)(nil)
+
+// NewQuotaInterceptor meters the given procedures, or every procedure when none are
+// given.
+func NewQuotaInterceptor(checker QuotaChecker, logger *slog.Logger, procedures ...string) *QuotaInterceptor {
+	procedureMap := make(map[string]struct{}, len(procedures))
+	for _, procedure := range procedures {
+		if procedure != "" {
+			procedureMap[procedure] = struct{}{}
+		}
+	}
+	return &QuotaInterceptor{
+		checker:    checker,
+		logger:     logger,
+		procedures: procedureMap,
+	}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *QuotaInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		status, ok, err := i.check(ctx, req.Spec().Procedure)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next(ctx, req)
+		if ok && err == nil && resp != nil {
+			setQuotaTrailers(resp.Trailer(), status)
+		}
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *QuotaInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *QuotaInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		status, ok, err := i.check(ctx, conn.Spec().Procedure)
+		if err != nil {
+			return err
+		}
+		if ok {
+			// The stream's own usage is only known once it ends, so the trailers carry the
+			// quota as it stood when the stream was admitted.
+			setQuotaTrailers(conn.ResponseTrailer(), status)
+		}
+		return next(ctx, conn)
+	}
+}
+
+// check returns the caller's quota when the procedure is metered and a user is known,
+// or a ResourceExhausted error when the quota is used up. Lookup failures fail open so
+// a metering outage doesn't take chat down with it.
+func (i *QuotaInterceptor) check(ctx context.Context, procedure string) (QuotaStatus, bool, error) {
+	if len(i.procedures) > 0 {
+		if _, metered := i.procedures[procedure]; !metered {
+			return QuotaStatus{}, false, nil
+		}
+	}
+	userID, ok := GetUserIDFromContext(ctx)
+	if !ok || userID == "" {
+		return QuotaStatus{}, false, nil
+	}
+
+	status, err := i.checker.CheckQuota(ctx, userID)
+	if err != nil {
+		i.logger.WarnContext(ctx, "quota check failed; allowing request",
+			slog.String("procedure", procedure),
+			slog.String("user_id", userID),
+			slog.Any("error", err))
+		return QuotaStatus{}, false, nil
+	}
+
+	if status.Exceeded() {
+		connectErr := connect.NewError(
+			connect.CodeResourceExhausted,
+			fmt.Errorf("%s plan token quota exceeded; resets at %s", status.Plan, status.resetAt().Format(time.RFC3339)),
+		)
+		setQuotaTrailers(connectErr.Meta(), status)
+		return status, false, connectErr
+	}
+	return status, true, nil
+}
+
+func setQuotaTrailers(h http.Header, status QuotaStatus) {
+	h.Set(QuotaPlanTrailer, status.Plan)
+	if status.DailyLimit > 0 {
+		h.Set(QuotaDailyLimitTrailer, strconv.FormatInt(status.DailyLimit, 10))
+		h.Set(QuotaDailyRemainingTrailer, strconv.FormatInt(status.DailyRemaining(), 10))
+	}
+	if status.MonthlyLimit > 0 {
+		h.Set(QuotaMonthlyLimitTrailer, strconv.FormatInt(status.MonthlyLimit, 10))
+		h.Set(QuotaMonthlyRemainingTrailer, strconv.FormatInt(status.MonthlyRemaining(), 10))
+	}
+	if !status.resetAt().IsZero() {
+		h.Set(QuotaResetTrailer, status.resetAt().UTC().Format(time.RFC3339))
+	}
+}
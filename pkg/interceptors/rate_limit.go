@@ -1,52 +1,293 @@
 package interceptors
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"connectrpc.com/connect"
-	"golang.org/x/time/rate"
+
+	"github.com/FACorreiaa/loci-connect-api/pkg/ratelimit"
+)
+
+// Rate limit response headers, following the IETF RateLimit header fields draft.
+const (
+	RetryAfterHeader         = "Retry-After"
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
 )
 
-// RateLimitInterceptor enforces a global rate limit for incoming RPCs.
+// planCacheTTL bounds how long a plan change takes to affect rate limits.
+const planCacheTTL = time.Minute
+
+// PlanResolver returns the subscription plan of a user.
+type PlanResolver interface {
+	PlanForUser(ctx context.Context, userID string) (string, error)
+}
+
+// RateLimitOptions configures RateLimitInterceptor.
+type RateLimitOptions struct {
+	Store ratelimit.Store
+	// Anonymous applies to callers without a user, keyed by client IP.
+	Anonymous ratelimit.Limit
+	// Plans maps a subscription plan to its limit. Users whose plan is missing, or
+	// cannot be resolved, get DefaultPlan's limit.
+	Plans       map[string]ratelimit.Limit
+	DefaultPlan string
+	Resolver    PlanResolver
+	// Weights is the token cost of each procedure. Unlisted procedures cost 1.
+	Weights map[string]float64
+	// TrustedProxies are the proxies whose forwarding headers name the client that
+	// anonymous callers are keyed by; see ClientIP.
+	TrustedProxies TrustedProxies
+	Logger         *slog.Logger
+}
+
+// RateLimitInterceptor enforces token-bucket limits per user, or per client IP for
+// anonymous callers. It must run after AuthInterceptor so the user is known.
 type RateLimitInterceptor struct {
-	limiter *rate.Limiter
+	opts RateLimitOptions
+	now  func() time.Time
+
+	mu    sync.Mutex
+	plans map[string]cachedPlan
 }
 
-// NewRateLimitInterceptor creates a rate limiting interceptor.
-func NewRateLimitInterceptor(limiter *rate.Limiter) *RateLimitInterceptor {
-	return &RateLimitInterceptor{limiter: limiter}
+type cachedPlan struct {
+	plan    string
+	expires time.Time
+}
+
+var _ connect.Interceptor = (*RateLimitInterceptor)(nil)
+
+// NewRateLimitInterceptor creates a keyed rate limiting interceptor.
+func NewRateLimitInterceptor(opts RateLimitOptions) *RateLimitInterceptor {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &RateLimitInterceptor{
+		opts:  opts,
+		now:   time.Now,
+		plans: make(map[string]cachedPlan),
+	}
 }
 
 // WrapUnary implements connect.Interceptor.
 func (i *RateLimitInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-		if i == nil || i.limiter == nil || i.limiter.Allow() {
+		if req.Spec().IsClient {
 			return next(ctx, req)
 		}
-		return nil, connect.NewError(
-			connect.CodeResourceExhausted,
-			errors.New("rate limit exceeded"),
-		)
+		res, limited, err := i.take(ctx, req.Spec().Procedure, req.Header(), req.Peer())
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next(ctx, req)
+		if limited && err == nil && resp != nil {
+			setRateLimitHeaders(resp.Header(), res)
+		}
+		return resp, err
 	}
 }
 
 // WrapStreamingClient implements connect.Interceptor.
 func (i *RateLimitInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
-	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
-		return next(ctx, spec)
-	}
+	return next
 }
 
 // WrapStreamingHandler implements connect.Interceptor.
 func (i *RateLimitInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
 	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
-		if i == nil || i.limiter == nil || i.limiter.Allow() {
-			return next(ctx, conn)
+		res, limited, err := i.take(ctx, conn.Spec().Procedure, conn.RequestHeader(), conn.Peer())
+		if err != nil {
+			return err
 		}
-		return connect.NewError(
-			connect.CodeResourceExhausted,
-			errors.New("rate limit exceeded"),
-		)
+		if limited {
+			setRateLimitHeaders(conn.ResponseHeader(), res)
+		}
+		return next(ctx, conn)
+	}
+}
+
+// take charges the caller for procedure. It reports whether a limit applied, and
+// returns ResourceExhausted when the bucket is empty. Store failures fail open.
+func (i *RateLimitInterceptor) take(ctx context.Context, procedure string, header http.Header, peer connect.Peer) (ratelimit.Result, bool, error) {
+	key, limit := i.limitFor(ctx, header, peer)
+	if limit.Unlimited() {
+		return ratelimit.Result{}, false, nil
+	}
+
+	res, err := i.opts.Store.Take(ctx, key, i.weight(procedure), limit)
+	if err != nil {
+		i.opts.Logger.WarnContext(ctx, "rate limit store failed; allowing request",
+			slog.String("procedure", procedure),
+			slog.Any("error", err))
+		return ratelimit.Result{}, false, nil
+	}
+	if !res.Allowed {
+		connectErr := connect.NewError(connect.CodeResourceExhausted, errors.New("rate limit exceeded"))
+		setRateLimitHeaders(connectErr.Meta(), res)
+		connectErr.Meta().Set(RetryAfterHeader, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+		setRateLimitPolicy(connectErr.Meta(), limit)
+		return res, true, connectErr
+	}
+	return res, true, nil
+}
+
+func (i *RateLimitInterceptor) limitFor(ctx context.Context, header http.Header, peer connect.Peer) (string, ratelimit.Limit) {
+	userID, ok := GetUserIDFromContext(ctx)
+	if !ok || userID == "" {
+		return "ip:" + i.clientIP(header, peer), i.opts.Anonymous
+	}
+	if limit, ok := i.opts.Plans[i.planFor(ctx, userID)]; ok {
+		return "user:" + userID, limit
+	}
+	return "user:" + userID, i.opts.Plans[i.opts.DefaultPlan]
+}
+
+func (i *RateLimitInterceptor) planFor(ctx context.Context, userID string) string {
+	if i.opts.Resolver == nil {
+		return i.opts.DefaultPlan
+	}
+
+	now := i.now()
+	i.mu.Lock()
+	cached, ok := i.plans[userID]
+	i.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.plan
 	}
+
+	plan, err := i.opts.Resolver.PlanForUser(ctx, userID)
+	if err != nil {
+		i.opts.Logger.WarnContext(ctx, "failed to resolve plan for rate limiting",
+			slog.String("user_id", userID),
+			slog.Any("error", err))
+		return i.opts.DefaultPlan
+	}
+
+	i.mu.Lock()
+	for id, c := range i.plans {
+		if !now.Before(c.expires) {
+			delete(i.plans, id)
+		}
+	}
+	i.plans[userID] = cachedPlan{plan: plan, expires: now.Add(planCacheTTL)}
+	i.mu.Unlock()
+	return plan
+}
+
+func (i *RateLimitInterceptor) weight(procedure string) float64 {
+	if w, ok := i.opts.Weights[procedure]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func (i *RateLimitInterceptor) clientIP(header http.Header, peer connect.Peer) string {
+	return ClientIP(header, peer, i.opts.TrustedProxies)
+}
+
+// HTTPRateLimit limits a plain HTTP endpoint, which the Connect interceptor chain does
+// not cover, per client IP and optionally per target, the account a request is about.
+type HTTPRateLimit struct {
+	// Name keys the endpoint's buckets, e.g. "password_forgot".
+	Name  string
+	PerIP ratelimit.Limit
+	// PerTarget limits requests about one target from any number of IPs. Target returns
+	// the target of a request, or "" when it has none.
+	PerTarget ratelimit.Limit
+	Target    func(r *http.Request) string
+}
+
+type httpBucket struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+// HTTPMiddleware applies limit to next with the interceptor's store and trusted
+// proxies. Rejected requests get 429 with Retry-After; store failures fail open.
+func (i *RateLimitInterceptor) HTTPMiddleware(limit HTTPRateLimit, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		buckets := []httpBucket{{"http:" + limit.Name + ":ip:" + RequestClientIP(r, i.opts.TrustedProxies), limit.PerIP}}
+		if limit.Target != nil {
+			if target := limit.Target(r); target != "" {
+				buckets = append(buckets, httpBucket{"http:" + limit.Name + ":target:" + target, limit.PerTarget})
+			}
+		}
+
+		for _, b := range buckets {
+			if b.limit.Unlimited() {
+				continue
+			}
+			res, err := i.opts.Store.Take(ctx, b.key, 1, b.limit)
+			if err != nil {
+				i.opts.Logger.WarnContext(ctx, "rate limit store failed; allowing request",
+					slog.String("endpoint", limit.Name),
+					slog.Any("error", err))
+				continue
+			}
+			setRateLimitHeaders(w.Header(), res)
+			if !res.Allowed {
+				w.Header().Set(RetryAfterHeader, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				setRateLimitPolicy(w.Header(), b.limit)
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// JSONBodyField returns an HTTPRateLimit.Target reading field from a JSON request body
+// of at most maxBytes, lowercased. The body is left for the handler to read.
+func JSONBodyField(field string, maxBytes int64) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if r.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBytes))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if err != nil {
+			return ""
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) != nil {
+			return ""
+		}
+		var value string
+		if json.Unmarshal(fields[field], &value) != nil {
+			return ""
+		}
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+func setRateLimitHeaders(h http.Header, res ratelimit.Result) {
+	h.Set(RateLimitLimitHeader, strconv.FormatFloat(res.Limit, 'f', -1, 64))
+	h.Set(RateLimitRemainingHeader, strconv.FormatFloat(res.Remaining, 'f', -1, 64))
+	h.Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+func setRateLimitPolicy(h http.Header, limit ratelimit.Limit) {
+	h.Set(RateLimitPolicyHeader, strconv.FormatFloat(limit.Burst, 'f', -1, 64)+";w="+strconv.Itoa(ceilSeconds(limit.Window())))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
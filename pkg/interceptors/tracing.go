@@ -8,10 +8,12 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// TracingInterceptor instruments RPCs with OpenTelemetry spans.
+// TracingInterceptor instruments RPCs with OpenTelemetry spans. Inbound W3C trace
+// context headers are honoured, so spans join the caller's trace.
 type TracingInterceptor struct {
 	tracer trace.Tracer
 }
@@ -27,6 +29,9 @@ func NewTracingInterceptor(tracer trace.Tracer) *TracingInterceptor {
 // WrapUnary implements connect.Interceptor.
 func (i *TracingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if !req.Spec().IsClient {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header()))
+		}
 		ctx, span := i.tracer.Start(ctx, req.Spec().Procedure, trace.WithSpanKind(trace.SpanKindServer))
 		serviceName := serviceFromProcedure(req.Spec().Procedure)
 		span.SetAttributes(
@@ -57,6 +62,7 @@ func (i *TracingInterceptor) WrapStreamingClient(next connect.StreamingClientFun
 // WrapStreamingHandler implements connect.Interceptor.
 func (i *TracingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
 	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
 		ctx, span := i.tracer.Start(ctx, conn.Spec().Procedure, trace.WithSpanKind(trace.SpanKindServer))
 		serviceName := serviceFromProcedure(conn.Spec().Procedure)
 		span.SetAttributes(
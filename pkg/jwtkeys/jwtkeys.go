@@ -0,0 +1,367 @@
+// Package jwtkeys holds the keys used to sign and verify JWTs. One key signs new
+// tokens; any number of additional keys stay valid for verification so keys can be
+// rotated without logging users out. Asymmetric public keys are published as a JWKS
+// so other services can verify tokens without a shared secret.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Values of the token_use claim. Access and refresh tokens may be signed by the same
+// keys, so verifiers check the claim to accept only the kind they expect.
+const (
+	TokenUseAccess  = "access"
+	TokenUseRefresh = "refresh"
+)
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing or verification.
+const minRSABits = 2048
+
+// Key is a single signing or verification key.
+type Key struct {
+	// ID is written to the kid header of tokens signed with the key. Asymmetric keys
+	// default to their RFC 7638 thumbprint; HMAC keys have no ID so tokens issued
+	// before key IDs existed still verify.
+	ID        string
+	Algorithm string
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// NewHMACKey returns an HS256 key for the shared secret.
+func NewHMACKey(secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("hmac secret is empty")
+	}
+	return &Key{Algorithm: AlgHS256, secret: secret}, nil
+}
+
+// NewPrivateKey returns a signing key for an RSA or Ed25519 private key.
+func NewPrivateKey(private crypto.Signer) (*Key, error) {
+	key, err := NewPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.private = private
+	return key, nil
+}
+
+// NewPublicKey returns a verification-only key for an RSA or Ed25519 public key.
+func NewPublicKey(public crypto.PublicKey) (*Key, error) {
+	var alg string
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa key is %d bits, at least %d required", pub.N.BitLen(), minRSABits)
+		}
+		alg = AlgRS256
+	case ed25519.PublicKey:
+		alg = AlgEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+	key := &Key{Algorithm: alg, public: public}
+	key.ID = key.thumbprint()
+	return key, nil
+}
+
+// LoadPrivateKeyFile reads a PEM encoded PKCS#8 or PKCS#1 private key.
+func LoadPrivateKeyFile(path string) (*Key, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T in %s", parsed, path)
+	}
+	return NewPrivateKey(signer)
+}
+
+// LoadPublicKeyFile reads a PEM encoded PKIX public key, or the public half of a
+// private key file.
+func LoadPublicKeyFile(path string) (*Key, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(block.Type, "PRIVATE KEY") {
+		key, err := LoadPrivateKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key.private = nil
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	return NewPublicKey(parsed)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	return block, nil
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// signingKey returns the material jwt signs with.
+func (k *Key) signingKey() any {
+	if k.secret != nil {
+		return k.secret
+	}
+	return k.private
+}
+
+// verificationKey returns the material jwt verifies with.
+func (k *Key) verificationKey() any {
+	if k.secret != nil {
+		return k.secret
+	}
+	return k.public
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of an asymmetric key.
+func (k *Key) thumbprint() string {
+	var canonical string
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, b64(big.NewInt(int64(pub.E)).Bytes()), b64(pub.N.Bytes()))
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, b64(pub))
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return b64(sum[:])
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// KeySet signs with one key and verifies with every key it holds.
+type KeySet struct {
+	signing *Key
+	// byID holds keys that carry a kid; legacy is the HMAC key used for tokens
+	// without one.
+	byID   map[string]*Key
+	legacy *Key
+	algs   []string
+}
+
+// NewKeySet builds a key set. The signing key is always valid for verification;
+// verification keys are typically keys that were rotated out but whose tokens have
+// not expired yet.
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil {
+		return nil, errors.New("signing key is required")
+	}
+	if signing.secret == nil && signing.private == nil {
+		return nil, fmt.Errorf("key %s cannot sign", signing.ID)
+	}
+
+	ks := &KeySet{signing: signing, byID: make(map[string]*Key)}
+	seenAlg := make(map[string]bool)
+	for _, key := range append([]*Key{signing}, verification...) {
+		if key == nil {
+			continue
+		}
+		if key.ID == "" {
+			if ks.legacy != nil {
+				return nil, errors.New("only one key without a key id is allowed")
+			}
+			ks.legacy = key
+		} else if _, dup := ks.byID[key.ID]; !dup {
+			ks.byID[key.ID] = key
+		}
+		if !seenAlg[key.Algorithm] {
+			seenAlg[key.Algorithm] = true
+			ks.algs = append(ks.algs, key.Algorithm)
+		}
+	}
+	return ks, nil
+}
+
+// Sign returns a signed token for claims, with the signing key's kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method(), claims)
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+	return token.SignedString(ks.signing.signingKey())
+}
+
+// Keyfunc resolves the verification key for a token from its kid header. The key's
+// algorithm must match the token's, so a public key can never be used as an HMAC
+// secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	var key *Key
+	if kid, _ := token.Header["kid"].(string); kid != "" {
+		key = ks.byID[kid]
+		if key == nil {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+	} else {
+		key = ks.legacy
+		if key == nil {
+			return nil, errors.New("token has no key id")
+		}
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.verificationKey(), nil
+}
+
+// Parse verifies tokenString and decodes it into claims.
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.Keyfunc, jwt.WithValidMethods(ks.algs))
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set. HMAC keys are secret and never included.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	// Publish the signing key first, then the rest in a stable order.
+	keys := []*Key{ks.signing}
+	for _, key := range ks.byID {
+		if key != ks.signing {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys[1:], func(a, b *Key) int { return strings.Compare(a.ID, b.ID) })
+	for _, key := range keys {
+		jwk := JWK{Use: "sig", KeyID: key.ID, Algorithm: key.Algorithm}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = b64(pub.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = b64(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Options selects the keys for Load.
+type Options struct {
+	// SigningKeyFile is a PEM private key to sign with. When empty, tokens are signed
+	// with HMACSecret using HS256.
+	SigningKeyFile string
+	// VerificationKeyFiles are PEM keys of previous signing keys that must keep
+	// verifying until their tokens expire.
+	VerificationKeyFiles []string
+	HMACSecret           []byte
+	// AcceptHMAC keeps HS256 tokens signed with HMACSecret valid after switching to
+	// a signing key file, so sessions survive the migration.
+	AcceptHMAC bool
+}
+
+// Load builds a key set from files and the legacy HMAC secret.
+func Load(opts Options) (*KeySet, error) {
+	var verification []*Key
+	for _, path := range opts.VerificationKeyFiles {
+		if path == "" {
+			continue
+		}
+		key, err := LoadPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verification = append(verification, key)
+	}
+
+	if opts.SigningKeyFile == "" {
+		hmacKey, err := NewHMACKey(opts.HMACSecret)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySet(hmacKey, verification...)
+	}
+
+	signing, err := LoadPrivateKeyFile(opts.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AcceptHMAC {
+		hmacKey, err := NewHMACKey(opts.HMACSecret)
+		if err != nil {
+			return nil, err
+		}
+		verification = append(verification, hmacKey)
+	}
+	return NewKeySet(signing, verification...)
+}
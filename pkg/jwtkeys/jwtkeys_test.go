@@ -0,0 +1,228 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func mustEd25519Key(t *testing.T) *Key {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	key, err := NewPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("new private key: %v", err)
+	}
+	return key
+}
+
+func mustRSAKey(t *testing.T) *Key {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	key, err := NewPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("new private key: %v", err)
+	}
+	return key
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	for name, key := range map[string]*Key{"EdDSA": mustEd25519Key(t), "RS256": mustRSAKey(t)} {
+		t.Run(name, func(t *testing.T) {
+			ks, err := NewKeySet(key)
+			if err != nil {
+				t.Fatalf("new key set: %v", err)
+			}
+			signed, err := ks.Sign(newClaims())
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			var claims jwt.RegisteredClaims
+			token, err := ks.Parse(signed, &claims)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if token.Header["kid"] != key.ID || token.Method.Alg() != name {
+				t.Fatalf("unexpected header %v", token.Header)
+			}
+			if claims.Subject != "user-1" {
+				t.Fatalf("unexpected subject %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := mustEd25519Key(t)
+	oldSet, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	issued, err := oldSet.Sign(newClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	retired, err := NewPublicKey(oldKey.public)
+	if err != nil {
+		t.Fatalf("new public key: %v", err)
+	}
+	rotated, err := NewKeySet(mustRSAKey(t), retired)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := rotated.Parse(issued, &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("expected token from the retired key to verify, got %v", err)
+	}
+
+	withoutOld, err := NewKeySet(mustRSAKey(t))
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := withoutOld.Parse(issued, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatalf("expected a token with an unknown kid to be rejected")
+	}
+}
+
+func TestKeySet_HMACMigration(t *testing.T) {
+	secret := []byte("legacy-secret")
+	hmacKey, err := NewHMACKey(secret)
+	if err != nil {
+		t.Fatalf("new hmac key: %v", err)
+	}
+	legacySet, err := NewKeySet(hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	legacy, err := legacySet.Sign(newClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	migrated, err := NewKeySet(mustEd25519Key(t), hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := migrated.Parse(legacy, &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("expected legacy HS256 token to verify during migration, got %v", err)
+	}
+
+	strict, err := NewKeySet(mustEd25519Key(t))
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+	if _, err := strict.Parse(legacy, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatalf("expected HS256 token to be rejected once HMAC is no longer accepted")
+	}
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	key := mustEd25519Key(t)
+	ks, err := NewKeySet(key, &Key{Algorithm: AlgHS256, secret: []byte("s")})
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+
+	// An HS256 token claiming the EdDSA key's kid must not be checked as HMAC.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims())
+	forged.Header["kid"] = key.ID
+	signed, err := forged.SignedString([]byte("s"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := ks.Parse(signed, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatalf("expected algorithm mismatch to be rejected")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	signing := mustEd25519Key(t)
+	hmacKey, _ := NewHMACKey([]byte("secret"))
+	retired := mustRSAKey(t)
+	ks, err := NewKeySet(signing, retired, hmacKey)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+
+	set := ks.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected the two public keys, got %+v", set.Keys)
+	}
+	if k := set.Keys[0]; k.KeyID != signing.ID || k.KeyType != "OKP" || k.Curve != "Ed25519" || k.X == "" {
+		t.Fatalf("unexpected signing jwk %+v", k)
+	}
+	if k := set.Keys[1]; k.KeyID != retired.ID || k.KeyType != "RSA" || k.Algorithm != AlgRS256 || k.E != "AQAB" {
+		t.Fatalf("unexpected retired jwk %+v", k)
+	}
+}
+
+func TestLoad_FromFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	if err != nil {
+		t.Fatalf("marshal ed25519 key: %v", err)
+	}
+	signingPath := writePEM(t, dir, "signing.pem", "PRIVATE KEY", edDER)
+
+	rsaPriv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPubDER, err := x509.MarshalPKIXPublicKey(&rsaPriv.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal rsa key: %v", err)
+	}
+	retiredPath := writePEM(t, dir, "retired.pub.pem", "PUBLIC KEY", rsaPubDER)
+
+	ks, err := Load(Options{
+		SigningKeyFile:       signingPath,
+		VerificationKeyFiles: []string{retiredPath},
+		HMACSecret:           []byte("secret"),
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if ks.signing.Algorithm != AlgEdDSA {
+		t.Fatalf("expected EdDSA signing key, got %s", ks.signing.Algorithm)
+	}
+	if ks.legacy != nil {
+		t.Fatalf("expected HS256 to be rejected unless AcceptHMAC is set")
+	}
+	if len(ks.JWKS().Keys) != 2 {
+		t.Fatalf("expected both keys in the jwks")
+	}
+
+	if _, err := Load(Options{}); err == nil {
+		t.Fatalf("expected an error without a signing key or secret")
+	}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
@@ -37,40 +37,170 @@ var (
 		},
 		[]string{"procedure"},
 	)
+
+	// StreamTimeToFirstMessage tracks how long a server stream takes to send its first message
+	StreamTimeToFirstMessage = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "loci_rpc_stream_time_to_first_message_seconds",
+			Help:    "Time from the start of a server stream to its first message in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32},
+		},
+		[]string{"procedure"},
+	)
+
+	// StreamMessagesSent tracks how many messages each server stream sends
+	StreamMessagesSent = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "loci_rpc_stream_messages_sent",
+			Help:    "Messages sent per server stream",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"procedure"},
+	)
+
+	// StreamBytesOut tracks bytes sent on server streams
+	StreamBytesOut = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "loci_rpc_stream_bytes_out_total",
+			Help: "Protobuf bytes sent on server streams",
+		},
+		[]string{"procedure"},
+	)
+
+	// LLMTimeToFirstToken tracks LLM latency until the first streamed token
+	LLMTimeToFirstToken = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "loci_llm_time_to_first_token_seconds",
+			Help:    "Time from an LLM streaming call to its first text chunk in seconds, by chat domain",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32},
+		},
+		[]string{"domain"},
+	)
+
+	// LLMStreamChunks tracks how many text chunks an LLM stream yields
+	LLMStreamChunks = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "loci_llm_stream_chunks",
+			Help:    "Text chunks per LLM streaming call, by chat domain",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"domain"},
+	)
+
+	// LLMStructuredOutput counts structured LLM responses by outcome: valid on the first
+	// try, invalid_json or schema_violation per rejected attempt, then repaired or failed
+	LLMStructuredOutput = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "loci_llm_structured_output_total",
+			Help: "Structured LLM response attempts by response type and outcome",
+		},
+		[]string{"response_type", "outcome"},
+	)
+
+	// LLMToolCalls counts the tools the chat agent ran, by tool and outcome (ok or error)
+	LLMToolCalls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "loci_llm_tool_calls_total",
+			Help: "Tool calls made by the LLM agent by tool and outcome",
+		},
+		[]string{"tool", "outcome"},
+	)
+
+	// CityExtractions counts how the city of a chat message was found: by the gazetteer,
+	// by the gazetteer finding none, or by the LLM when the gazetteer was unsure
+	CityExtractions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "loci_city_extractions_total",
+			Help: "City extractions from chat messages by source",
+		},
+		[]string{"source"},
+	)
+
+	// ChatIntentClassifications counts the intents of continued chat messages by the
+	// classifier layer that settled them (keyword, embedding or llm), or unsure
+	ChatIntentClassifications = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "loci_chat_intent_classifications_total",
+			Help: "Intent classifications of chat messages by layer and intent",
+		},
+		[]string{"layer", "intent"},
+	)
+
+	// POIInteractionsDropped counts buffered POI interactions discarded because their
+	// batch insert kept failing while the retry buffer was full, or failed on shutdown
+	POIInteractionsDropped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "loci_poi_interactions_dropped_total",
+			Help: "Buffered POI interactions dropped after failed batch inserts",
+		},
+	)
 )
 
+// MetricsInterceptor collects Prometheus metrics for unary RPCs and server streams.
+type MetricsInterceptor struct{}
+
+var _ connect.Interceptor = (*MetricsInterceptor)(nil)
+
 // NewMetricsInterceptor creates an interceptor that collects Prometheus metrics
-func NewMetricsInterceptor() connect.UnaryInterceptorFunc {
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-			procedure := req.Spec().Procedure
-
-			// Track active requests
-			ActiveRequests.WithLabelValues(procedure).Inc()
-			defer ActiveRequests.WithLabelValues(procedure).Dec()
-
-			// Track duration
-			start := time.Now()
-			defer func() {
-				duration := time.Since(start).Seconds()
-				RequestDuration.WithLabelValues(procedure).Observe(duration)
-			}()
-
-			// Execute request
-			resp, err := next(ctx, req)
-
-			// Track total requests with status code
-			code := "ok"
-			if err != nil {
-				if connectErr, ok := err.(*connect.Error); ok {
-					code = connectErr.Code().String()
-				} else {
-					code = "unknown"
-				}
-			}
-			RequestsTotal.WithLabelValues(procedure, code).Inc()
-
-			return resp, err
+func NewMetricsInterceptor() *MetricsInterceptor {
+	return &MetricsInterceptor{}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (m *MetricsInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
 		}
+		procedure := req.Spec().Procedure
+
+		// Track active requests
+		ActiveRequests.WithLabelValues(procedure).Inc()
+		defer ActiveRequests.WithLabelValues(procedure).Dec()
+
+		// Track duration
+		start := time.Now()
+		defer func() {
+			duration := time.Since(start).Seconds()
+			RequestDuration.WithLabelValues(procedure).Observe(duration)
+		}()
+
+		// Execute request
+		resp, err := next(ctx, req)
+
+		// Track total requests with status code
+		RequestsTotal.WithLabelValues(procedure, Code(err)).Inc()
+
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (m *MetricsInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor. Streams are counted in the same
+// request, duration and active series as unary calls, plus the stream histograms.
+func (m *MetricsInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		procedure := conn.Spec().Procedure
+
+		ActiveRequests.WithLabelValues(procedure).Inc()
+		defer ActiveRequests.WithLabelValues(procedure).Dec()
+
+		observed := ObserveStream(conn)
+		err := next(ctx, observed)
+
+		stats := observed.Stats()
+		RequestsTotal.WithLabelValues(procedure, Code(err)).Inc()
+		RequestDuration.WithLabelValues(procedure).Observe(stats.Duration.Seconds())
+		if stats.MessagesSent > 0 {
+			StreamTimeToFirstMessage.WithLabelValues(procedure).Observe(stats.TimeToFirstMessage.Seconds())
+		}
+		StreamMessagesSent.WithLabelValues(procedure).Observe(float64(stats.MessagesSent))
+		StreamBytesOut.WithLabelValues(procedure).Add(float64(stats.BytesOut))
+
+		return err
 	}
 }
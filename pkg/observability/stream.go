@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamStats summarises a server stream once it has ended.
+type StreamStats struct {
+	Duration time.Duration
+	// TimeToFirstMessage is zero when the stream sent nothing.
+	TimeToFirstMessage time.Duration
+	MessagesSent       int
+	MessagesReceived   int
+	BytesOut           int
+}
+
+// ObservedStream wraps a streaming handler connection to count what flows through it.
+// It is safe for the concurrent Send and Receive calls connect allows.
+type ObservedStream struct {
+	connect.StreamingHandlerConn
+
+	start time.Time
+
+	mu       sync.Mutex
+	first    time.Time
+	sent     int
+	received int
+	bytesOut int
+}
+
+// ObserveStream starts observing conn.
+func ObserveStream(conn connect.StreamingHandlerConn) *ObservedStream {
+	return &ObservedStream{StreamingHandlerConn: conn, start: time.Now()}
+}
+
+func (s *ObservedStream) Send(msg any) error {
+	err := s.StreamingHandlerConn.Send(msg)
+	if err != nil {
+		return err
+	}
+	size := 0
+	if m, ok := msg.(proto.Message); ok {
+		size = proto.Size(m)
+	}
+	s.mu.Lock()
+	if s.sent == 0 {
+		s.first = time.Now()
+	}
+	s.sent++
+	s.bytesOut += size
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *ObservedStream) Receive(msg any) error {
+	err := s.StreamingHandlerConn.Receive(msg)
+	if err == nil {
+		s.mu.Lock()
+		s.received++
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// Stats returns the counters so far; call it once the handler has returned.
+func (s *ObservedStream) Stats() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := StreamStats{
+		Duration:         time.Since(s.start),
+		MessagesSent:     s.sent,
+		MessagesReceived: s.received,
+		BytesOut:         s.bytesOut,
+	}
+	if !s.first.IsZero() {
+		stats.TimeToFirstMessage = s.first.Sub(s.start)
+	}
+	return stats
+}
+
+// Code returns the terminal status label of an RPC: "ok" on success, otherwise the
+// Connect code name.
+func Code(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return connectErr.Code().String()
+	}
+	return "unknown"
+}
@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// fakeStreamConn accepts every Send; Receive is unused by server streams.
+type fakeStreamConn struct {
+	connect.StreamingHandlerConn
+	procedure string
+}
+
+func (f *fakeStreamConn) Spec() connect.Spec {
+	return connect.Spec{Procedure: f.procedure, StreamType: connect.StreamTypeServer}
+}
+func (f *fakeStreamConn) Send(any) error              { return nil }
+func (f *fakeStreamConn) ResponseHeader() http.Header { return http.Header{} }
+
+func TestMetricsInterceptor_StreamingHandler(t *testing.T) {
+	const procedure = "/test.Service/Stream"
+	handler := NewMetricsInterceptor().WrapStreamingHandler(func(_ context.Context, conn connect.StreamingHandlerConn) error {
+		for _, s := range []string{"one", "two", "three"} {
+			if err := conn.Send(wrapperspb.String(s)); err != nil {
+				return err
+			}
+		}
+		return connect.NewError(connect.CodeUnavailable, errors.New("llm down"))
+	})
+
+	err := handler(context.Background(), &fakeStreamConn{procedure: procedure})
+	if connect.CodeOf(err) != connect.CodeUnavailable {
+		t.Fatalf("expected the handler error to pass through, got %v", err)
+	}
+	if got := metricValue(t, RequestsTotal.WithLabelValues(procedure, "unavailable")).GetCounter().GetValue(); got != 1 {
+		t.Fatalf("expected one unavailable stream, got %v", got)
+	}
+	if got := metricValue(t, StreamBytesOut.WithLabelValues(procedure)).GetCounter().GetValue(); got != 17 {
+		t.Fatalf("expected 17 bytes out, got %v", got)
+	}
+	ttfm := StreamTimeToFirstMessage.WithLabelValues(procedure).(prometheus.Metric)
+	if got := metricValue(t, ttfm).GetHistogram().GetSampleCount(); got != 1 {
+		t.Fatalf("expected one time-to-first-message observation, got %d", got)
+	}
+	sent := StreamMessagesSent.WithLabelValues(procedure).(prometheus.Metric)
+	if got := metricValue(t, sent).GetHistogram().GetSampleSum(); got != 3 {
+		t.Fatalf("expected 3 messages sent, got %v", got)
+	}
+}
+
+func TestObservedStream_Stats(t *testing.T) {
+	observed := ObserveStream(&fakeStreamConn{})
+	if stats := observed.Stats(); stats.MessagesSent != 0 || stats.TimeToFirstMessage != 0 {
+		t.Fatalf("expected empty stats, got %+v", stats)
+	}
+	_ = observed.Send(wrapperspb.String("hi"))
+	_ = observed.Send(wrapperspb.String("there"))
+	stats := observed.Stats()
+	if stats.MessagesSent != 2 || stats.BytesOut != 4+7 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func metricValue(t *testing.T, m prometheus.Metric) *dto.Metric {
+	t.Helper()
+	var out dto.Metric
+	if err := m.Write(&out); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	return &out
+}
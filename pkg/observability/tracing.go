@@ -0,0 +1,103 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingOptions configures the global tracer provider.
+type TracingOptions struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Endpoint is the OTLP/HTTP collector URL, e.g. "http://otel-collector:4318".
+	// Plain http endpoints are used without TLS.
+	Endpoint string
+	// SampleRatio is the fraction of new traces to record. Spans whose caller already
+	// sampled (or dropped) the trace follow the caller's decision.
+	SampleRatio float64
+	// Exporter replaces the OTLP exporter, e.g. with an in-memory exporter in tests.
+	// Spans are then exported synchronously as they end.
+	Exporter sdktrace.SpanExporter
+}
+
+// SetupTracing installs a global tracer provider and the W3C trace context and baggage
+// propagators. The returned function flushes pending spans and must be called on
+// shutdown.
+func SetupTracing(ctx context.Context, opts TracingOptions) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.ServiceVersion),
+		semconv.DeploymentEnvironmentName(opts.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	ratio := opts.SampleRatio
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("trace sample ratio must be between 0 and 1, got %v", ratio)
+	}
+
+	var exportOpt sdktrace.TracerProviderOption
+	if opts.Exporter != nil {
+		exportOpt = sdktrace.WithSyncer(opts.Exporter)
+	} else {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		exportOpt = sdktrace.WithBatcher(exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		exportOpt,
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// TraceHandler adds the trace_id and span_id of the active span to every record logged
+// with a context, so logs can be joined with their trace.
+type TraceHandler struct {
+	slog.Handler
+}
+
+// NewTraceHandler wraps h with trace correlation.
+func NewTraceHandler(h slog.Handler) *TraceHandler {
+	return &TraceHandler{Handler: h}
+}
+
+func (h *TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TraceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return &TraceHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,85 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestSetupTracing_ExportsSpansWithResource(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := SetupTracing(context.Background(), TracingOptions{
+		ServiceName:    "loci-test",
+		ServiceVersion: "1.2.3",
+		Environment:    "test",
+		SampleRatio:    1,
+		Exporter:       exporter,
+	})
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected one exported span, got %d", len(spans))
+	}
+	var service string
+	for _, kv := range spans[0].Resource.Attributes() {
+		if kv.Key == semconv.ServiceNameKey {
+			service = kv.Value.AsString()
+		}
+	}
+	if service != "loci-test" {
+		t.Fatalf("expected service.name loci-test, got %q", service)
+	}
+}
+
+func TestSetupTracing_RejectsInvalidRatio(t *testing.T) {
+	_, err := SetupTracing(context.Background(), TracingOptions{SampleRatio: 1.5, Exporter: tracetest.NewInMemoryExporter()})
+	if err == nil {
+		t.Fatalf("expected an error for a ratio above 1")
+	}
+}
+
+func TestTraceHandler_AddsTraceIDs(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := SetupTracing(context.Background(), TracingOptions{SampleRatio: 1, Exporter: exporter})
+	if err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "op")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode log record: %v", err)
+	}
+	if record["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Fatalf("expected trace_id %s, got %v", span.SpanContext().TraceID(), record["trace_id"])
+	}
+	if record["span_id"] != span.SpanContext().SpanID().String() {
+		t.Fatalf("expected span_id %s, got %v", span.SpanContext().SpanID(), record["span_id"])
+	}
+
+	buf.Reset()
+	logger.Info("outside span")
+	if bytes.Contains(buf.Bytes(), []byte("trace_id")) {
+		t.Fatalf("expected no trace_id outside a span, got %s", buf.String())
+	}
+}
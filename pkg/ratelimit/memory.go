@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many Takes pass between scans for idle buckets.
+const sweepEvery = 4096
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled; past that point it can be dropped
+	// because a fresh bucket is equivalent.
+	full time.Time
+}
+
+// MemoryStore keeps buckets in process. Limits are per replica.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+	now     func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, cost float64, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.takes++
+	if m.takes%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.Burst, last: now}
+		m.buckets[key] = b
+	}
+	tokens, res := take(b.tokens, now.Sub(b.last), cost, limit)
+	b.tokens, b.last, b.full = tokens, now, now.Add(res.Reset)
+	return res, nil
+}
+
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every replica
+// enforces the same limits. Each Take locks its bucket row for one short
+// transaction, and the database clock is used so replica clock skew doesn't leak
+// tokens.
+type PostgresStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore creates the store and, when sweepInterval is positive, a background
+// sweeper that deletes buckets which have refilled. Call Close to stop it.
+func NewPostgresStore(pool *pgxpool.Pool, logger *slog.Logger, sweepInterval time.Duration) *PostgresStore {
+	s := &PostgresStore{
+		pool:   pool,
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+	if sweepInterval > 0 {
+		s.wg.Add(1)
+		go s.sweepLoop(sweepInterval)
+	}
+	return s
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, cost float64, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	var res Result
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var (
+			tokens    float64
+			updatedAt time.Time
+			now       time.Time
+		)
+		// The no-op update on conflict locks an existing row and returns it unchanged.
+		err := tx.QueryRow(ctx, `
+			INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+			VALUES ($1, $2, now(), now())
+			ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+			RETURNING tokens, updated_at, now()`,
+			key, limit.Burst,
+		).Scan(&tokens, &updatedAt, &now)
+		if err != nil {
+			return fmt.Errorf("failed to lock rate limit bucket: %w", err)
+		}
+
+		tokens, res = take(tokens, now.Sub(updatedAt), cost, limit)
+
+		_, err = tx.Exec(ctx, `
+			UPDATE rate_limit_buckets
+			SET tokens = $2, updated_at = $3, expires_at = $4
+			WHERE key = $1`,
+			key, tokens, now, now.Add(res.Reset),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update rate limit bucket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// Sweep deletes buckets that have refilled; they are equivalent to absent ones.
+func (s *PostgresStore) Sweep(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at < now()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep rate limit buckets: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *PostgresStore) sweepLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if n, err := s.Sweep(ctx); err != nil {
+				s.logger.Warn("rate limit sweep failed", slog.Any("error", err))
+			} else if n > 0 {
+				s.logger.Debug("swept rate limit buckets", slog.Int64("deleted", n))
+			}
+			cancel()
+		}
+	}
+}
+
+// Close stops the sweeper.
+func (s *PostgresStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+	s.wg.Wait()
+}
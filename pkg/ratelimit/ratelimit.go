@@ -0,0 +1,71 @@
+// Package ratelimit implements keyed token buckets behind a pluggable store, so the
+// same limits can be held in process or shared across replicas through Postgres.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second up to Burst tokens.
+// A Limit with a non-positive Rate does not restrict anything.
+type Limit struct {
+	Rate  float64
+	Burst float64
+}
+
+// Unlimited reports whether l lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Window is the time an empty bucket takes to refill completely.
+func (l Limit) Window() time.Duration {
+	if l.Unlimited() {
+		return 0
+	}
+	return time.Duration(l.Burst / l.Rate * float64(time.Second))
+}
+
+// Result describes the bucket after a Take.
+type Result struct {
+	Allowed bool
+	Limit   float64
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining float64
+	// RetryAfter is how long until the rejected request would fit. Zero when allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store takes cost tokens from the bucket identified by key.
+type Store interface {
+	Take(ctx context.Context, key string, cost float64, limit Limit) (Result, error)
+}
+
+// take applies the token bucket algorithm to a bucket that held tokens elapsed ago and
+// returns the new token count. Costs above the burst are clamped so a heavy procedure
+// can still be called with a full bucket.
+func take(tokens float64, elapsed time.Duration, cost float64, limit Limit) (float64, Result) {
+	if elapsed > 0 {
+		tokens = math.Min(limit.Burst, tokens+elapsed.Seconds()*limit.Rate)
+	}
+	cost = math.Min(cost, limit.Burst)
+
+	res := Result{Limit: limit.Burst}
+	if tokens >= cost {
+		tokens -= cost
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((cost - tokens) / limit.Rate)
+	}
+	res.Remaining = math.Floor(tokens)
+	res.Reset = seconds((limit.Burst - tokens) / limit.Rate)
+	return tokens, res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
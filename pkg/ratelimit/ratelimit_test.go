@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_RefillsOverTime(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 4}
+	ctx := context.Background()
+
+	res, err := store.Take(ctx, "user:a", 4, limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	res, err = store.Take(ctx, "user:a", 3, limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 1500*time.Millisecond, res.RetryAfter)
+
+	res, err = store.Take(ctx, "user:b", 1, limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "buckets are independent per key")
+
+	now = now.Add(1500 * time.Millisecond)
+	res, err = store.Take(ctx, "user:a", 3, limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestTake_ClampsCostToBurst(t *testing.T) {
+	tokens, res := take(5, 0, 50, Limit{Rate: 1, Burst: 5})
+	assert.True(t, res.Allowed, "a full bucket admits a request costlier than the burst")
+	assert.Zero(t, tokens)
+}
+
+func TestMemoryStore_Unlimited(t *testing.T) {
+	res, err := NewMemoryStore().Take(context.Background(), "ip:1.2.3.4", 1, Limit{})
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}